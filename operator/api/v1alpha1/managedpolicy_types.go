@@ -1,6 +1,10 @@
 package v1alpha1
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -18,6 +22,42 @@ const (
 	PolicyTypeGatewayTLSRoute   PolicyType = "GATEWAY_TLSROUTE"
 )
 
+// DriftMode defines how changes made to deployed resources outside of Policy Hub are handled
+// +kubebuilder:validation:Enum=Revert;Report
+type DriftMode string
+
+const (
+	// DriftModeRevert re-applies the policy content over out-of-band changes
+	DriftModeRevert DriftMode = "Revert"
+	// DriftModeReport keeps out-of-band changes and reports them
+	DriftModeReport DriftMode = "Report"
+)
+
+// PolicyMode defines whether a policy is enforced or only audited
+// +kubebuilder:validation:Enum=audit;enforce
+type PolicyMode string
+
+const (
+	// PolicyModeAudit deploys Tetragon policies with Post actions only,
+	// reporting what they would block. Cilium has no per-policy audit mode, so
+	// network policies can only be deployed in audit mode while the Cilium
+	// agents run with the cluster-wide policy-audit-mode option, which audits
+	// every network policy.
+	PolicyModeAudit PolicyMode = "audit"
+	// PolicyModeEnforce deploys policies as written
+	PolicyModeEnforce PolicyMode = "enforce"
+)
+
+// ManagedPolicyFinalizer holds a ManagedPolicy until its deployed resources are
+// deleted. Cluster-scoped resources cannot be garbage collected through an
+// owner reference to the namespaced ManagedPolicy.
+const ManagedPolicyFinalizer = "policyhub.io/cleanup"
+
+// RollbackAnnotation requests a rollback to a recorded revision of a
+// ManagedPolicy. Its value is the policy version to redeploy; the operator
+// removes it once the rollback is done.
+const RollbackAnnotation = "policyhub.io/rollback-to-version"
+
 // ManagedPolicySpec defines the desired state of ManagedPolicy
 type ManagedPolicySpec struct {
 	// PolicyID is the unique identifier from the SaaS platform
@@ -40,11 +80,32 @@ type ManagedPolicySpec struct {
 	// +kubebuilder:validation:Required
 	Content string `json:"content"`
 
+	// ContentHash is the SHA-256 of Content, hex encoded. Content that changes
+	// without a new Version is deployed again.
+	// +kubebuilder:validation:Pattern=`^[a-f0-9]{64}$`
+	// +optional
+	ContentHash string `json:"contentHash,omitempty"`
+
+	// Signature is the base64-encoded Ed25519 signature of the policy by the
+	// SaaS platform, verified when policy signing is configured
+	// +optional
+	Signature string `json:"signature,omitempty"`
+
+	// SignatureKeyID is the ID of the trusted key the policy was signed with
+	// +optional
+	SignatureKeyID string `json:"signatureKeyId,omitempty"`
+
 	// TargetNamespaces specifies where to deploy the policy
 	// Empty means cluster-wide or default namespace based on policy type
 	// +optional
 	TargetNamespaces []string `json:"targetNamespaces,omitempty"`
 
+	// NamespaceSelector adds the namespaces whose labels match to
+	// TargetNamespaces. Namespaces created or labeled later are deployed to,
+	// and namespaces that stop matching are removed from.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
 	// Version is the policy version from the SaaS platform
 	// +kubebuilder:validation:Minimum=1
 	Version int `json:"version"`
@@ -53,8 +114,62 @@ type ManagedPolicySpec struct {
 	// +kubebuilder:default=false
 	// +optional
 	Paused bool `json:"paused,omitempty"`
+
+	// DriftMode controls whether changes made to the deployed resources
+	// outside Policy Hub are reverted or only reported
+	// +kubebuilder:default=Revert
+	// +optional
+	DriftMode DriftMode `json:"driftMode,omitempty"`
+
+	// Mode deploys the policy in audit mode, reporting what it would block,
+	// or enforces it. Tetragon policies can be audited, and network policies
+	// while Cilium runs with policy-audit-mode enabled. Changing from audit to
+	// enforce promotes the deployed version.
+	// +kubebuilder:default=enforce
+	// +optional
+	Mode PolicyMode `json:"mode,omitempty"`
+
+	// Rollout stages deployment across the target namespaces and rolls back
+	// when blocked flows increase. Unset deploys to all namespaces at once.
+	// +optional
+	Rollout *RolloutStrategy `json:"rollout,omitempty"`
+
+	// RevisionHistoryLimit is the number of deployed revisions kept in
+	// ConfigMaps for rollback
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
 }
 
+// RolloutStrategy deploys a policy to canary namespaces first, watches blocked
+// flows for the bake time, then deploys to the remaining namespaces
+type RolloutStrategy struct {
+	// CanaryNamespaces are deployed first and must be target namespaces.
+	// Empty means the first target namespace.
+	// +optional
+	CanaryNamespaces []string `json:"canaryNamespaces,omitempty"`
+
+	// BakeTime is how long blocked flows are watched after each step
+	// +kubebuilder:default="5m"
+	// +optional
+	BakeTime metav1.Duration `json:"bakeTime,omitempty"`
+
+	// BlockedFlowThreshold is the increase in blocked flows per minute, over
+	// the rate before the step, at which the rollout is rolled back
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	BlockedFlowThreshold int `json:"blockedFlowThreshold,omitempty"`
+}
+
+// Defaults of RolloutStrategy, matching its CRD defaults
+const (
+	DefaultRolloutBakeTime             = 5 * time.Minute
+	DefaultRolloutBlockedFlowThreshold = 10
+)
+
 // DeployedResource represents a Kubernetes resource created by the policy
 type DeployedResource struct {
 	// APIVersion of the resource
@@ -76,17 +191,116 @@ type DeployedResource struct {
 }
 
 // ManagedPolicyPhase represents the deployment phase
-// +kubebuilder:validation:Enum=Pending;Deploying;Deployed;Failed;Deleting
+// +kubebuilder:validation:Enum=Pending;Deploying;Deployed;PartiallyDeployed;Failed;Deleting
 type ManagedPolicyPhase string
 
 const (
 	ManagedPolicyPhasePending   ManagedPolicyPhase = "Pending"
 	ManagedPolicyPhaseDeploying ManagedPolicyPhase = "Deploying"
 	ManagedPolicyPhaseDeployed  ManagedPolicyPhase = "Deployed"
-	ManagedPolicyPhaseFailed    ManagedPolicyPhase = "Failed"
-	ManagedPolicyPhaseDeleting  ManagedPolicyPhase = "Deleting"
+	// ManagedPolicyPhasePartiallyDeployed means some targets failed to deploy
+	// and are retried; Status.Targets tells which
+	ManagedPolicyPhasePartiallyDeployed ManagedPolicyPhase = "PartiallyDeployed"
+	ManagedPolicyPhaseFailed            ManagedPolicyPhase = "Failed"
+	ManagedPolicyPhaseDeleting          ManagedPolicyPhase = "Deleting"
+)
+
+// RolloutPhase represents the state of a staged rollout
+// +kubebuilder:validation:Enum=Baking;Completed;RolledBack
+type RolloutPhase string
+
+const (
+	// RolloutPhaseBaking means a step is deployed and blocked flows are being watched
+	RolloutPhaseBaking RolloutPhase = "Baking"
+	// RolloutPhaseCompleted means every target namespace runs the new version
+	RolloutPhaseCompleted RolloutPhase = "Completed"
+	// RolloutPhaseRolledBack means the previous version was restored
+	RolloutPhaseRolledBack RolloutPhase = "RolledBack"
 )
 
+// RolloutStatus tracks a staged rollout
+type RolloutStatus struct {
+	// Version is the policy version being rolled out
+	Version int `json:"version"`
+
+	// Phase of the rollout
+	Phase RolloutPhase `json:"phase"`
+
+	// Step is the index of the current step, 0 being the canary step
+	Step int `json:"step"`
+
+	// Namespaces already running Version
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// StepStarted is when the current step was deployed
+	// +optional
+	StepStarted *metav1.Time `json:"stepStarted,omitempty"`
+
+	// BaselineBlockedFlows is the number of blocked flows in Namespaces during
+	// the bake time before the current step
+	// +optional
+	BaselineBlockedFlows int64 `json:"baselineBlockedFlows,omitempty"`
+
+	// ObservedBlockedFlows is the number of blocked flows in Namespaces during
+	// the bake time of the last completed step
+	// +optional
+	ObservedBlockedFlows int64 `json:"observedBlockedFlows,omitempty"`
+
+	// PreviousVersion is the version deployed before the rollout
+	// +optional
+	PreviousVersion int `json:"previousVersion,omitempty"`
+
+	// PreviousContent holds the resources of PreviousVersion as they were
+	// deployed, restored on rollback
+	// +optional
+	PreviousContent string `json:"previousContent,omitempty"`
+
+	// Message describes the last rollout transition
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// TargetStatus is the deployment state of one resource in one target namespace
+type TargetStatus struct {
+	// Namespace of the resource, empty for cluster-scoped resources
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Resource is the kind and name of the resource, e.g. CiliumNetworkPolicy/api
+	Resource string `json:"resource"`
+
+	// Version is the policy version the resource runs, 0 if unknown
+	// +optional
+	Version int `json:"version,omitempty"`
+
+	// ObservedGeneration is the ManagedPolicy generation last applied to the resource
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Error is why the last apply failed
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// RollbackStatus records a rollback requested with RollbackAnnotation
+type RollbackStatus struct {
+	// FromVersion is the spec version that was rolled back. It is not
+	// deployed again; a newer version is.
+	FromVersion int `json:"fromVersion"`
+
+	// ToVersion is the revision that was redeployed
+	ToVersion int `json:"toVersion"`
+
+	// ContentHash is the SHA-256 of the redeployed content
+	// +optional
+	ContentHash string `json:"contentHash,omitempty"`
+
+	// RolledBackAt is when the revision was redeployed
+	// +optional
+	RolledBackAt *metav1.Time `json:"rolledBackAt,omitempty"`
+}
+
 // ManagedPolicyStatus defines the observed state of ManagedPolicy
 type ManagedPolicyStatus struct {
 	// Phase represents the current deployment phase
@@ -95,9 +309,21 @@ type ManagedPolicyStatus struct {
 	// DeployedVersion is the currently deployed version
 	DeployedVersion int `json:"deployedVersion,omitempty"`
 
+	// DeployedContentHash is the content hash of the deployed version
+	// +optional
+	DeployedContentHash string `json:"deployedContentHash,omitempty"`
+
 	// DeployedResources lists the Kubernetes resources created
 	DeployedResources []DeployedResource `json:"deployedResources,omitempty"`
 
+	// Namespaces are the target namespaces resolved at the last deployment
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Targets is the deployment state of each resource in each target
+	// +optional
+	Targets []TargetStatus `json:"targets,omitempty"`
+
 	// LastDeployed is when the policy was last successfully deployed
 	LastDeployed *metav1.Time `json:"lastDeployed,omitempty"`
 
@@ -109,6 +335,22 @@ type ManagedPolicyStatus struct {
 
 	// ObservedGeneration is the generation observed by the controller
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Rollout tracks the staged rollout of the latest version
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+
+	// Mode is the mode the deployed resources run in
+	// +optional
+	Mode PolicyMode `json:"mode,omitempty"`
+
+	// AuditStarted is when the policy was deployed in audit mode
+	// +optional
+	AuditStarted *metav1.Time `json:"auditStarted,omitempty"`
+
+	// Rollback is set while a rolled back revision is deployed
+	// +optional
+	Rollback *RollbackStatus `json:"rollback,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -117,6 +359,7 @@ type ManagedPolicyStatus struct {
 // +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.policyType`
 // +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
 // +kubebuilder:printcolumn:name="Version",type=integer,JSONPath=`.status.deployedVersion`
+// +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.status.mode`
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // ManagedPolicy is the Schema for the managedpolicies API
@@ -142,9 +385,27 @@ func (m *ManagedPolicy) IsDeployed() bool {
 	return m.Status.Phase == ManagedPolicyPhaseDeployed
 }
 
+// IsAudited returns true if the policy is to be deployed in audit mode
+func (m *ManagedPolicy) IsAudited() bool {
+	return m.Spec.Mode == PolicyModeAudit
+}
+
 // NeedsUpdate returns true if the spec version differs from deployed version
+// or the content changed
 func (m *ManagedPolicy) NeedsUpdate() bool {
-	return m.Spec.Version != m.Status.DeployedVersion
+	return m.Spec.Version != m.Status.DeployedVersion || m.ContentChanged()
+}
+
+// ContentChanged returns true if the hash of the content differs from the
+// deployed one. The hash is computed from Content, not taken from ContentHash,
+// so content edited in the cluster is noticed. Policies deployed before content
+// hashes were recorded never changed.
+func (m *ManagedPolicy) ContentChanged() bool {
+	if m.Status.DeployedContentHash == "" {
+		return false
+	}
+	sum := sha256.Sum256([]byte(m.Spec.Content))
+	return hex.EncodeToString(sum[:]) != m.Status.DeployedContentHash
 }
 
 func init() {
@@ -8,9 +8,10 @@ import (
 // PolicyHubConfigSpec defines the desired state of PolicyHubConfig
 type PolicyHubConfigSpec struct {
 	// SaaSEndpoint is the URL of the Policy Hub SaaS platform
-	// +kubebuilder:validation:Required
+	// Required unless policies are synced from a directory
 	// +kubebuilder:validation:Pattern=`^https?://`
-	SaaSEndpoint string `json:"saasEndpoint"`
+	// +optional
+	SaaSEndpoint string `json:"saasEndpoint,omitempty"`
 
 	// ClusterID is the unique identifier for this cluster in the SaaS platform
 	// Set this if you already have a cluster created in Policy Hub (legacy mode)
@@ -69,6 +70,16 @@ type PolicyHubConfigSpec struct {
 	// Environment is the cluster's environment (DEVELOPMENT, STAGING, PRODUCTION, TESTING)
 	// +optional
 	Environment string `json:"environment,omitempty"`
+
+	// PolicySigning requires policies to be signed by a trusted key before
+	// they are deployed
+	// +optional
+	PolicySigning *PolicySigningSpec `json:"policySigning,omitempty"`
+
+	// PolicySource is where policies are synced from. Unset syncs from the
+	// SaaS platform.
+	// +optional
+	PolicySource *PolicySourceSpec `json:"policySource,omitempty"`
 }
 
 // SecretKeySelector selects a key of a Secret
@@ -110,6 +121,59 @@ type FlowCollectionSpec struct {
 	FlushInterval metav1.Duration `json:"flushInterval,omitempty"`
 }
 
+// PolicySigningSpec configures policy signature verification
+type PolicySigningSpec struct {
+	// TrustedKeys are the keys policies may be signed with. A policy that is
+	// unsigned, or not signed by one of them, is not deployed. This includes
+	// the Gateway API routes synced from the SaaS platform.
+	// +kubebuilder:validation:MinItems=1
+	TrustedKeys []TrustedKey `json:"trustedKeys"`
+}
+
+// TrustedKey is an Ed25519 public key trusted to sign policies
+type TrustedKey struct {
+	// ID identifies the key in policy signatures
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	ID string `json:"id"`
+
+	// PublicKey is the PEM-encoded (PKIX) or base64-encoded raw Ed25519 public key
+	// +kubebuilder:validation:Required
+	PublicKey string `json:"publicKey"`
+}
+
+// PolicySourceType is where policies are synced from
+// +kubebuilder:validation:Enum=SaaS;Directory
+type PolicySourceType string
+
+const (
+	// PolicySourceSaaS syncs policies from the SaaS platform
+	PolicySourceSaaS PolicySourceType = "SaaS"
+	// PolicySourceDirectory syncs policies from a directory, such as a Git
+	// checkout, without contacting the SaaS platform
+	PolicySourceDirectory PolicySourceType = "Directory"
+)
+
+// PolicySourceSpec configures where policies are synced from
+type PolicySourceSpec struct {
+	// Type is where policies are synced from
+	// +kubebuilder:default=SaaS
+	Type PolicySourceType `json:"type"`
+
+	// Path is the directory policies are read from, one subdirectory per
+	// policy. Required for the Directory type. A directory without policies
+	// is refused unless it is a Git checkout or holds an .allow-empty file.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// ReportConfigMap is the ConfigMap, in the namespace of the
+	// PolicyHubConfig, the status of policies is written to instead of being
+	// reported to the SaaS platform
+	// +kubebuilder:default="policy-hub-report"
+	// +optional
+	ReportConfigMap string `json:"reportConfigMap,omitempty"`
+}
+
 // PolicyHubConfigStatus defines the observed state of PolicyHubConfig
 type PolicyHubConfigStatus struct {
 	// Phase represents the current phase of the operator
@@ -137,6 +201,10 @@ type PolicyHubConfigStatus struct {
 	// ManagedPolicies is the count of policies being managed
 	ManagedPolicies int `json:"managedPolicies,omitempty"`
 
+	// LastSyncSummary counts the changes made by the last policy sync
+	// +optional
+	LastSyncSummary *SyncSummary `json:"lastSyncSummary,omitempty"`
+
 	// Conditions represent the latest available observations
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 
@@ -144,6 +212,30 @@ type PolicyHubConfigStatus struct {
 	Message string `json:"message,omitempty"`
 }
 
+// SyncSummary counts the ManagedPolicies a policy sync changed
+type SyncSummary struct {
+	// Added is the number of policies created
+	Added int `json:"added"`
+
+	// Updated is the number of policies whose spec changed
+	Updated int `json:"updated"`
+
+	// Removed is the number of policies deleted or undeployed
+	Removed int `json:"removed"`
+
+	// Unchanged is the number of policies already up to date
+	Unchanged int `json:"unchanged"`
+
+	// Failed is the number of policies that could not be created or updated
+	// +optional
+	Failed int `json:"failed,omitempty"`
+
+	// NotModified is true if the SaaS platform reported no changes since the
+	// previous sync, which then only checked the policies in the cluster
+	// +optional
+	NotModified bool `json:"notModified,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Namespaced,shortName=phc
@@ -170,6 +262,12 @@ type PolicyHubConfigList struct {
 	Items           []PolicyHubConfig `json:"items"`
 }
 
+// Offline returns true if policies are synced from a directory rather than
+// the SaaS platform
+func (c *PolicyHubConfig) Offline() bool {
+	return c.Spec.PolicySource != nil && c.Spec.PolicySource.Type == PolicySourceDirectory
+}
+
 // GetSecretRef returns a LocalObjectReference for the API token secret
 func (s *SecretKeySelector) GetSecretRef() corev1.LocalObjectReference {
 	return corev1.LocalObjectReference{Name: s.Name}
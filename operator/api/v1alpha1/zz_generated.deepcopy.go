@@ -107,6 +107,21 @@ func (in *ManagedPolicySpec) DeepCopyInto(out *ManagedPolicySpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RevisionHistoryLimit != nil {
+		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedPolicySpec.
@@ -127,6 +142,16 @@ func (in *ManagedPolicyStatus) DeepCopyInto(out *ManagedPolicyStatus) {
 		*out = make([]DeployedResource, len(*in))
 		copy(*out, *in)
 	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]TargetStatus, len(*in))
+		copy(*out, *in)
+	}
 	if in.LastDeployed != nil {
 		in, out := &in.LastDeployed, &out.LastDeployed
 		*out = (*in).DeepCopy()
@@ -138,6 +163,20 @@ func (in *ManagedPolicyStatus) DeepCopyInto(out *ManagedPolicyStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AuditStarted != nil {
+		in, out := &in.AuditStarted, &out.AuditStarted
+		*out = (*in).DeepCopy()
+	}
+	if in.Rollback != nil {
+		in, out := &in.Rollback, &out.Rollback
+		*out = new(RollbackStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedPolicyStatus.
@@ -234,6 +273,16 @@ func (in *PolicyHubConfigSpec) DeepCopyInto(out *PolicyHubConfigSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.PolicySigning != nil {
+		in, out := &in.PolicySigning, &out.PolicySigning
+		*out = new(PolicySigningSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PolicySource != nil {
+		in, out := &in.PolicySource, &out.PolicySource
+		*out = new(PolicySourceSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyHubConfigSpec.
@@ -257,6 +306,11 @@ func (in *PolicyHubConfigStatus) DeepCopyInto(out *PolicyHubConfigStatus) {
 		in, out := &in.LastSync, &out.LastSync
 		*out = (*in).DeepCopy()
 	}
+	if in.LastSyncSummary != nil {
+		in, out := &in.LastSyncSummary, &out.LastSyncSummary
+		*out = new(SyncSummary)
+		**out = **in
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -276,6 +330,105 @@ func (in *PolicyHubConfigStatus) DeepCopy() *PolicyHubConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicySigningSpec) DeepCopyInto(out *PolicySigningSpec) {
+	*out = *in
+	if in.TrustedKeys != nil {
+		in, out := &in.TrustedKeys, &out.TrustedKeys
+		*out = make([]TrustedKey, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicySigningSpec.
+func (in *PolicySigningSpec) DeepCopy() *PolicySigningSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicySigningSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicySourceSpec) DeepCopyInto(out *PolicySourceSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicySourceSpec.
+func (in *PolicySourceSpec) DeepCopy() *PolicySourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicySourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollbackStatus) DeepCopyInto(out *RollbackStatus) {
+	*out = *in
+	if in.RolledBackAt != nil {
+		in, out := &in.RolledBackAt, &out.RolledBackAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollbackStatus.
+func (in *RollbackStatus) DeepCopy() *RollbackStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RollbackStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStatus) DeepCopyInto(out *RolloutStatus) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StepStarted != nil {
+		in, out := &in.StepStarted, &out.StepStarted
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStatus.
+func (in *RolloutStatus) DeepCopy() *RolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStrategy) DeepCopyInto(out *RolloutStrategy) {
+	*out = *in
+	if in.CanaryNamespaces != nil {
+		in, out := &in.CanaryNamespaces, &out.CanaryNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.BakeTime = in.BakeTime
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStrategy.
+func (in *RolloutStrategy) DeepCopy() *RolloutStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretKeySelector) DeepCopyInto(out *SecretKeySelector) {
 	*out = *in
@@ -290,3 +443,48 @@ func (in *SecretKeySelector) DeepCopy() *SecretKeySelector {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncSummary) DeepCopyInto(out *SyncSummary) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncSummary.
+func (in *SyncSummary) DeepCopy() *SyncSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetStatus) DeepCopyInto(out *TargetStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetStatus.
+func (in *TargetStatus) DeepCopy() *TargetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrustedKey) DeepCopyInto(out *TrustedKey) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrustedKey.
+func (in *TrustedKey) DeepCopy() *TrustedKey {
+	if in == nil {
+		return nil
+	}
+	out := new(TrustedKey)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
@@ -70,6 +71,8 @@ type Config struct {
 	SaaSEndpoint      string
 	SaaSAPIKey        string
 	AggregationWindow time.Duration
+	OutboxPath        string
+	EventStream       bool
 
 	// Node information
 	NodeName    string
@@ -85,8 +88,10 @@ type Config struct {
 	QueryAPIKey  string
 
 	// Simulation configuration
-	SimulationEnabled      bool
-	SimulationPollInterval time.Duration
+	SimulationEnabled       bool
+	SimulationPollInterval  time.Duration
+	SimulationMaxConcurrent int
+	SimulationQueueSize     int
 
 	// Validation configuration
 	ValidationEnabled      bool
@@ -197,6 +202,37 @@ func main() {
 	// Start buffer flush worker
 	go buffer.StartFlushWorker(ctx)
 
+	// Create the SaaS client shared by the telemetry senders. Submissions the
+	// SaaS platform does not receive are kept in the outbox until it does.
+	var saasClient, outboxClient *saas.Client
+	var outbox *saas.Outbox
+	if cfg.SaaSEnabled && cfg.SaaSEndpoint != "" {
+		saasClient = saas.NewClient(cfg.SaaSEndpoint, cfg.SaaSAPIKey, cfg.ClusterID, log)
+		// Set node name for multi-node simulation aggregation
+		saasClient.SetNodeName(cfg.NodeName)
+
+		outboxPath := cfg.OutboxPath
+		if outboxPath == "" {
+			outboxPath = filepath.Join(cfg.StoragePath, "outbox")
+		}
+		if ob, err := saas.NewOutbox(outboxPath, log); err != nil {
+			log.Error(err, "Failed to open outbox, SaaS submissions are not retried", "path", outboxPath)
+		} else {
+			outbox = ob
+			saasClient.SetOutbox(outbox)
+			outboxClient = saasClient
+			go outbox.Start(ctx)
+		}
+	}
+
+	// Receive change notifications instead of waiting for the next poll
+	var events *saas.EventStream
+	if saasClient != nil && cfg.EventStream {
+		events = saas.NewEventStream(log)
+		events.SetClient(saasClient)
+		go events.Start(ctx)
+	}
+
 	// Initialize SaaS sender for aggregated telemetry
 	var saasSender *aggregator.SaaSSender
 	if cfg.SaaSEnabled && cfg.SaaSEndpoint != "" {
@@ -209,6 +245,7 @@ func main() {
 			RetryInterval: 5 * time.Second,
 			Timeout:       30 * time.Second,
 			NodeName:      cfg.NodeName,
+			Client:        outboxClient,
 			Logger:        log,
 		})
 
@@ -226,14 +263,18 @@ func main() {
 	// Initialize and start simulation worker
 	var simWorker *simulation.Worker
 	if cfg.SimulationEnabled && cfg.SaaSEnabled && cfg.SaaSEndpoint != "" {
-		// Create SaaS client for simulation
-		saasClient := saas.NewClient(cfg.SaaSEndpoint, cfg.SaaSAPIKey, cfg.ClusterID, log)
-		// Set node name for multi-node simulation aggregation
-		saasClient.SetNodeName(cfg.NodeName)
+		// Load the deployed policy set for what-if simulations of policy changes
+		var policySource simulation.PolicySetSource
+		if k8sClient, err := newKubernetesClient(log); err != nil {
+			log.Error(err, "Failed to create Kubernetes client, policy set simulations disabled")
+		} else {
+			policySource = validation.NewPolicyMatcher(k8sClient, log)
+		}
 
 		// Create simulation engine
 		simEngine := simulation.NewEngine(simulation.EngineConfig{
 			StorageManager: storageMgr,
+			PolicySource:   policySource,
 			Logger:         log,
 		})
 
@@ -241,8 +282,11 @@ func main() {
 		simWorker = simulation.NewWorker(simulation.WorkerConfig{
 			Engine:       simEngine,
 			SaaSClient:   saasClient,
-			PollInterval: cfg.SimulationPollInterval,
-			Logger:       log,
+			PollInterval:  cfg.SimulationPollInterval,
+			MaxConcurrent: cfg.SimulationMaxConcurrent,
+			QueueSize:     cfg.SimulationQueueSize,
+			Events:        events,
+			Logger:        log,
 		})
 
 		if err := simWorker.Start(ctx); err != nil {
@@ -250,6 +294,7 @@ func main() {
 		} else {
 			log.Info("Simulation worker enabled",
 				"pollInterval", cfg.SimulationPollInterval,
+				"maxConcurrent", cfg.SimulationMaxConcurrent,
 			)
 		}
 	} else {
@@ -260,48 +305,32 @@ func main() {
 	var validationAgent *validation.Agent
 	if cfg.ValidationEnabled && cfg.SaaSEnabled && cfg.SaaSEndpoint != "" {
 		// Create Kubernetes client for fetching policies
-		k8sConfig, err := rest.InClusterConfig()
+		k8sClient, err := newKubernetesClient(log)
 		if err != nil {
-			log.Error(err, "Failed to get in-cluster config for validation agent")
+			log.Error(err, "Failed to create Kubernetes client for validation agent")
 		} else {
-			// Create scheme with core K8s types, Cilium, and Gateway API CRDs registered
-			scheme := runtime.NewScheme()
-			if err := clientgoscheme.AddToScheme(scheme); err != nil {
-				log.Error(err, "Failed to register core Kubernetes types in scheme")
-			}
-			if err := ciliumv2.AddToScheme(scheme); err != nil {
-				log.Error(err, "Failed to register Cilium types in scheme")
-			}
-			if err := gatewayv1.Install(scheme); err != nil {
-				log.Error(err, "Failed to register Gateway API types in scheme")
-			}
-
-			k8sClient, err := client.New(k8sConfig, client.Options{Scheme: scheme})
-			if err != nil {
-				log.Error(err, "Failed to create Kubernetes client for validation agent")
+			// Create validation agent
+			validationAgent = validation.NewAgent(validation.AgentOptions{
+				Client:          k8sClient,
+				SaaSEndpoint:    cfg.SaaSEndpoint,
+				APIKey:          cfg.SaaSAPIKey,
+				ClusterID:       cfg.ClusterID,
+				FlushInterval:   cfg.ValidationFlushInterval,
+				PolicyRefresh:   cfg.ValidationPolicyRefresh,
+				EventBufferSize: cfg.ValidationEventBuffer,
+				EventSampleRate: cfg.ValidationSampleRate,
+				Logger:          log,
+				SaaSClient:      outboxClient,
+			})
+
+			if err := validationAgent.Start(ctx); err != nil {
+				log.Error(err, "Failed to start validation agent")
 			} else {
-				// Create validation agent
-				validationAgent = validation.NewAgent(validation.AgentOptions{
-					Client:          k8sClient,
-					SaaSEndpoint:    cfg.SaaSEndpoint,
-					APIKey:          cfg.SaaSAPIKey,
-					ClusterID:       cfg.ClusterID,
-					FlushInterval:   cfg.ValidationFlushInterval,
-					PolicyRefresh:   cfg.ValidationPolicyRefresh,
-					EventBufferSize: cfg.ValidationEventBuffer,
-					EventSampleRate: cfg.ValidationSampleRate,
-					Logger:          log,
-				})
-
-				if err := validationAgent.Start(ctx); err != nil {
-					log.Error(err, "Failed to start validation agent")
-				} else {
-					log.Info("Validation agent enabled",
-						"flushInterval", cfg.ValidationFlushInterval,
-						"policyRefresh", cfg.ValidationPolicyRefresh,
-						"sampleRate", cfg.ValidationSampleRate,
-					)
-				}
+				log.Info("Validation agent enabled",
+					"flushInterval", cfg.ValidationFlushInterval,
+					"policyRefresh", cfg.ValidationPolicyRefresh,
+					"sampleRate", cfg.ValidationSampleRate,
+				)
 			}
 		}
 	} else {
@@ -318,6 +347,7 @@ func main() {
 			MaxEvents:  cfg.ValidationEventBuffer,
 			SampleRate: cfg.ValidationSampleRate,
 			Logger:     log,
+			SaaSClient: outboxClient,
 		})
 
 		// Start the process validation reporter flush loop
@@ -402,7 +432,7 @@ func main() {
 	go startHealthServer(cfg.HealthPort, buffer, storageMgr, log)
 
 	// Start metrics server
-	go startMetricsServer(cfg.MetricsPort, buffer, storageMgr, saasSender, queryServer, simWorker, validationAgent, log)
+	go startMetricsServer(cfg.MetricsPort, buffer, storageMgr, saasSender, outbox, queryServer, simWorker, validationAgent, log)
 
 	// Wait for shutdown
 	<-ctx.Done()
@@ -434,6 +464,28 @@ func main() {
 	log.Info("Collector stopped")
 }
 
+// newKubernetesClient creates an in-cluster client with core Kubernetes, Cilium
+// and Gateway API types registered.
+func newKubernetesClient(log logr.Logger) (client.Client, error) {
+	k8sConfig, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get in-cluster config: %w", err)
+	}
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		log.Error(err, "Failed to register core Kubernetes types in scheme")
+	}
+	if err := ciliumv2.AddToScheme(scheme); err != nil {
+		log.Error(err, "Failed to register Cilium types in scheme")
+	}
+	if err := gatewayv1.Install(scheme); err != nil {
+		log.Error(err, "Failed to register Gateway API types in scheme")
+	}
+
+	return client.New(k8sConfig, client.Options{Scheme: scheme})
+}
+
 func parseFlags() *Config {
 	cfg := &Config{}
 
@@ -459,6 +511,8 @@ func parseFlags() *Config {
 	flag.StringVar(&cfg.SaaSEndpoint, "saas-endpoint", getEnv("SAAS_ENDPOINT", ""), "SaaS API endpoint")
 	flag.StringVar(&cfg.SaaSAPIKey, "saas-api-key", getEnv("SAAS_API_KEY", ""), "SaaS API key")
 	flag.DurationVar(&cfg.AggregationWindow, "aggregation-window", getEnvDuration("AGGREGATION_WINDOW", time.Minute), "Aggregation window for SaaS sync")
+	flag.StringVar(&cfg.OutboxPath, "outbox-path", getEnv("OUTBOX_PATH", ""), "Path for SaaS submissions waiting to be delivered (default: outbox under the storage path)")
+	flag.BoolVar(&cfg.EventStream, "event-stream", getEnvBool("EVENT_STREAM", true), "Receive change notifications from SaaS instead of waiting for the next poll")
 
 	// Node info flags
 	flag.StringVar(&cfg.NodeName, "node-name", getEnv("NODE_NAME", ""), "Node name (from downward API)")
@@ -476,6 +530,8 @@ func parseFlags() *Config {
 	// Simulation flags
 	flag.BoolVar(&cfg.SimulationEnabled, "simulation-enabled", getEnvBool("SIMULATION_ENABLED", true), "Enable simulation worker")
 	flag.DurationVar(&cfg.SimulationPollInterval, "simulation-poll-interval", getEnvDuration("SIMULATION_POLL_INTERVAL", 30*time.Second), "Simulation poll interval")
+	flag.IntVar(&cfg.SimulationMaxConcurrent, "simulation-max-concurrent", getEnvInt("SIMULATION_MAX_CONCURRENT", 1), "Simulations run at once on this node")
+	flag.IntVar(&cfg.SimulationQueueSize, "simulation-queue-size", getEnvInt("SIMULATION_QUEUE_SIZE", 10), "Pending simulations queued on this node")
 
 	// Validation flags
 	flag.BoolVar(&cfg.ValidationEnabled, "validation-enabled", getEnvBool("VALIDATION_ENABLED", true), "Enable validation agent")
@@ -619,7 +675,7 @@ func startHealthServer(port int, buffer *collector.RingBuffer, storageMgr *stora
 	}
 }
 
-func startMetricsServer(port int, buffer *collector.RingBuffer, storageMgr *storage.Manager, saasSender *aggregator.SaaSSender, queryServer *query.Server, simWorker *simulation.Worker, validationAgent *validation.Agent, log logr.Logger) {
+func startMetricsServer(port int, buffer *collector.RingBuffer, storageMgr *storage.Manager, saasSender *aggregator.SaaSSender, outbox *saas.Outbox, queryServer *query.Server, simWorker *simulation.Worker, validationAgent *validation.Agent, log logr.Logger) {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
@@ -707,6 +763,26 @@ func startMetricsServer(port int, buffer *collector.RingBuffer, storageMgr *stor
 			}
 		}
 
+		// Outbox metrics
+		if outbox != nil {
+			outboxStats := outbox.Stats()
+			fmt.Fprintf(w, "# HELP policyhub_collector_outbox_depth SaaS submissions queued in the outbox\n")
+			fmt.Fprintf(w, "# TYPE policyhub_collector_outbox_depth gauge\n")
+			fmt.Fprintf(w, "policyhub_collector_outbox_depth %d\n", outboxStats.Depth)
+
+			fmt.Fprintf(w, "# HELP policyhub_collector_outbox_oldest_age_seconds Age of the oldest queued SaaS submission\n")
+			fmt.Fprintf(w, "# TYPE policyhub_collector_outbox_oldest_age_seconds gauge\n")
+			fmt.Fprintf(w, "policyhub_collector_outbox_oldest_age_seconds %.0f\n", outboxStats.OldestAge.Seconds())
+
+			fmt.Fprintf(w, "# HELP policyhub_collector_outbox_delivered_total Queued SaaS submissions delivered\n")
+			fmt.Fprintf(w, "# TYPE policyhub_collector_outbox_delivered_total counter\n")
+			fmt.Fprintf(w, "policyhub_collector_outbox_delivered_total %d\n", outboxStats.Delivered)
+
+			fmt.Fprintf(w, "# HELP policyhub_collector_outbox_dropped_total Queued SaaS submissions dropped\n")
+			fmt.Fprintf(w, "# TYPE policyhub_collector_outbox_dropped_total counter\n")
+			fmt.Fprintf(w, "policyhub_collector_outbox_dropped_total %d\n", outboxStats.Dropped)
+		}
+
 		// Query server metrics
 		if queryServer != nil {
 			queryStats := queryServer.GetStats()
@@ -742,6 +818,18 @@ func startMetricsServer(port int, buffer *collector.RingBuffer, storageMgr *stor
 			fmt.Fprintf(w, "# TYPE policyhub_collector_simulation_errors_total counter\n")
 			fmt.Fprintf(w, "policyhub_collector_simulation_errors_total %d\n", simStats.TotalErrors)
 
+			fmt.Fprintf(w, "# HELP policyhub_collector_simulation_cancelled_total Total simulations cancelled\n")
+			fmt.Fprintf(w, "# TYPE policyhub_collector_simulation_cancelled_total counter\n")
+			fmt.Fprintf(w, "policyhub_collector_simulation_cancelled_total %d\n", simStats.TotalCancelled)
+
+			fmt.Fprintf(w, "# HELP policyhub_collector_simulation_queued Simulations waiting in the queue\n")
+			fmt.Fprintf(w, "# TYPE policyhub_collector_simulation_queued gauge\n")
+			fmt.Fprintf(w, "policyhub_collector_simulation_queued %d\n", simStats.Queued)
+
+			fmt.Fprintf(w, "# HELP policyhub_collector_simulation_active Simulations currently running\n")
+			fmt.Fprintf(w, "# TYPE policyhub_collector_simulation_active gauge\n")
+			fmt.Fprintf(w, "policyhub_collector_simulation_active %d\n", simStats.Active)
+
 			fmt.Fprintf(w, "# HELP policyhub_collector_simulation_worker_running Simulation worker running (1=yes, 0=no)\n")
 			fmt.Fprintf(w, "# TYPE policyhub_collector_simulation_worker_running gauge\n")
 			if simStats.Running {
@@ -770,6 +858,10 @@ func startMetricsServer(port int, buffer *collector.RingBuffer, storageMgr *stor
 			fmt.Fprintf(w, "# TYPE policyhub_collector_validation_no_policy_total counter\n")
 			fmt.Fprintf(w, "policyhub_collector_validation_no_policy_total %d\n", valStats.TotalNoPolicy)
 
+			fmt.Fprintf(w, "# HELP policyhub_collector_validation_audited_total Total flows policies in audit mode would have blocked\n")
+			fmt.Fprintf(w, "# TYPE policyhub_collector_validation_audited_total counter\n")
+			fmt.Fprintf(w, "policyhub_collector_validation_audited_total %d\n", valStats.TotalAudited)
+
 			fmt.Fprintf(w, "# HELP policyhub_collector_validation_reports_sent_total Total validation reports sent to SaaS\n")
 			fmt.Fprintf(w, "# TYPE policyhub_collector_validation_reports_sent_total counter\n")
 			fmt.Fprintf(w, "policyhub_collector_validation_reports_sent_total %d\n", valStats.ReportsSent)
@@ -5,18 +5,24 @@ import (
 	"os"
 
 	ciliumv2 "github.com/cilium/cilium/pkg/k8s/apis/cilium.io/v2"
+	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
 	"github.com/policy-hub/operator/internal/controller"
+	"github.com/policy-hub/operator/internal/policy"
+	"github.com/policy-hub/operator/internal/saas"
 	"github.com/policy-hub/operator/internal/sync"
+	"github.com/policy-hub/operator/internal/webhook"
 )
 
 var (
@@ -35,12 +41,28 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var enableWebhooks bool
+	var webhookPort int
+	var webhookCertDir string
+	var outboxDir string
+	var enableEventStream bool
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Serve the admission webhooks validating ManagedPolicy and PolicyHubConfig resources. "+
+			"Requires a TLS certificate in the webhook certificate directory.")
+	flag.IntVar(&webhookPort, "webhook-port", 9443, "The port the webhook server binds to.")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "", "The directory containing the webhook TLS certificate (tls.crt and tls.key).")
+	flag.StringVar(&outboxDir, "outbox-dir", "/var/lib/policyhub/outbox",
+		"The directory where status reports the SaaS platform did not receive are kept until they are delivered. "+
+			"Mount a persistent volume to keep them across restarts. Empty disables the outbox.")
+	flag.BoolVar(&enableEventStream, "event-stream", true,
+		"Receive change notifications from the SaaS platform to sync within seconds. "+
+			"Policies are polled every sync interval while the event stream is unavailable.")
 
 	opts := zap.Options{
 		Development: true,
@@ -55,6 +77,10 @@ func main() {
 		Metrics: metricsserver.Options{
 			BindAddress: metricsAddr,
 		},
+		WebhookServer: ctrlwebhook.NewServer(ctrlwebhook.Options{
+			Port:    webhookPort,
+			CertDir: webhookCertDir,
+		}),
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "policy-hub-operator.policyhub.io",
@@ -67,6 +93,31 @@ func main() {
 	// Create shared reconciler
 	syncReconciler := sync.NewReconciler(mgr.GetClient(), ctrl.Log)
 
+	// Queue SaaS submissions on disk during outages
+	if outboxDir != "" {
+		outbox, err := saas.NewOutbox(outboxDir, ctrl.Log)
+		if err != nil {
+			setupLog.Error(err, "unable to open outbox, SaaS submissions are not retried", "dir", outboxDir)
+		} else {
+			syncReconciler.SetOutbox(outbox)
+			if err := mgr.Add(outbox); err != nil {
+				setupLog.Error(err, "unable to add outbox")
+				os.Exit(1)
+			}
+			registerOutboxMetrics(outbox)
+		}
+	}
+
+	// Sync when the SaaS platform notifies of changes instead of waiting for the next poll
+	if enableEventStream {
+		events := saas.NewEventStream(ctrl.Log)
+		syncReconciler.SetEventStream(events)
+		if err := mgr.Add(events); err != nil {
+			setupLog.Error(err, "unable to add event stream")
+			os.Exit(1)
+		}
+	}
+
 	// Set up PolicyHubConfig controller
 	if err = (&controller.PolicyHubConfigReconciler{
 		Client:     mgr.GetClient(),
@@ -89,6 +140,20 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Set up admission webhooks
+	if enableWebhooks {
+		webhookLog := ctrl.Log.WithName("webhooks")
+		deployer := policy.NewDeployer(mgr.GetClient(), ctrl.Log)
+		if err = webhook.SetupManagedPolicyWebhookWithManager(mgr, deployer, webhookLog.WithName("ManagedPolicy")); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ManagedPolicy")
+			os.Exit(1)
+		}
+		if err = webhook.SetupPolicyHubConfigWebhookWithManager(mgr, webhookLog.WithName("PolicyHubConfig")); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "PolicyHubConfig")
+			os.Exit(1)
+		}
+	}
+
 	// Add health checks
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
@@ -105,3 +170,25 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// registerOutboxMetrics exposes the outbox statistics on the metrics endpoint
+func registerOutboxMetrics(outbox *saas.Outbox) {
+	ctrlmetrics.Registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "policyhub_operator_outbox_depth",
+			Help: "Number of SaaS submissions queued in the outbox",
+		}, func() float64 { return float64(outbox.Stats().Depth) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "policyhub_operator_outbox_oldest_age_seconds",
+			Help: "Age of the oldest SaaS submission queued in the outbox",
+		}, func() float64 { return outbox.Stats().OldestAge.Seconds() }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "policyhub_operator_outbox_delivered_total",
+			Help: "Number of queued SaaS submissions delivered",
+		}, func() float64 { return float64(outbox.Stats().Delivered) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "policyhub_operator_outbox_dropped_total",
+			Help: "Number of queued SaaS submissions dropped because they were rejected or the outbox was full",
+		}, func() float64 { return float64(outbox.Stats().Dropped) }),
+	)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-logr/logr"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -185,6 +186,38 @@ func TestManagedPolicyReconciler_Reconcile_Deleting(t *testing.T) {
 	}
 }
 
+func TestMapDeployedResource(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        []reconcile.Request
+	}{
+		{
+			name:        "deployed resource",
+			annotations: map[string]string{"policyhub.io/managed-policy": "policy-hub-system/api-policy"},
+			want:        []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "policy-hub-system", Name: "api-policy"}}},
+		},
+		{
+			name: "missing annotation",
+		},
+		{
+			name:        "malformed annotation",
+			annotations: map[string]string{"policyhub.io/managed-policy": "api-policy"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{}
+			obj.SetAnnotations(tt.annotations)
+			got := mapDeployedResource(context.Background(), obj)
+			if len(got) != len(tt.want) || (len(got) == 1 && got[0] != tt.want[0]) {
+				t.Errorf("mapDeployedResource() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 // --- PolicyHubConfigReconciler Tests ---
 
 func TestPolicyHubConfigReconciler_Reconcile_NotFound(t *testing.T) {
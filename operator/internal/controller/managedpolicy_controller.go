@@ -2,14 +2,23 @@ package controller
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+	"github.com/policy-hub/operator/internal/policy"
 	"github.com/policy-hub/operator/internal/sync"
 )
 
@@ -24,6 +33,7 @@ type ManagedPolicyReconciler struct {
 // +kubebuilder:rbac:groups=policyhub.io,resources=managedpolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=policyhub.io,resources=managedpolicies/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=policyhub.io,resources=managedpolicies/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile handles ManagedPolicy reconciliation
 func (r *ManagedPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -44,21 +54,79 @@ func (r *ManagedPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	// Handle deletion
 	if !mp.DeletionTimestamp.IsZero() {
 		log.Info("ManagedPolicy being deleted")
+		if err := r.Reconciler.FinalizePolicy(ctx, mp); err != nil {
+			log.Error(err, "Failed to finalize policy")
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
 		return ctrl.Result{}, nil
 	}
 
+	if err := r.Reconciler.EnsureFinalizer(ctx, mp); err != nil {
+		log.Error(err, "Failed to add finalizer")
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
 	// Reconcile the policy
 	if err := r.Reconciler.ReconcilePolicy(ctx, mp); err != nil {
 		log.Error(err, "Failed to reconcile policy")
 		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
 	}
 
+	// Come back when the current rollout step has baked
+	if after := r.Reconciler.RolloutRequeueAfter(mp); after > 0 {
+		return ctrl.Result{RequeueAfter: after}, nil
+	}
+
 	return ctrl.Result{}, nil
 }
 
-// SetupWithManager sets up the controller with the Manager
+// SetupWithManager sets up the controller with the Manager. Changes to
+// deployed resources of the kinds installed in the cluster trigger a
+// reconcile of their ManagedPolicy, which detects drift. Namespaces that are
+// created, relabeled or deleted trigger a reconcile of the policies with a
+// namespace selector.
 func (r *ManagedPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&policyv1alpha1.ManagedPolicy{}).
-		Complete(r)
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.mapNamespace), builder.WithPredicates(predicate.LabelChangedPredicate{}))
+
+	managed := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetLabels()[policy.ManagedByLabel] == "policy-hub-operator"
+	})
+	for _, gvk := range policy.ManagedKinds {
+		if _, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+			r.Log.V(1).Info("Not watching deployed resources, kind not installed", "kind", gvk.Kind, "error", err.Error())
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		b = b.Watches(obj, handler.EnqueueRequestsFromMapFunc(mapDeployedResource), builder.WithPredicates(managed))
+	}
+
+	return b.Complete(r)
+}
+
+// mapDeployedResource maps a deployed resource to the ManagedPolicy it was deployed from
+func mapDeployedResource(ctx context.Context, obj client.Object) []reconcile.Request {
+	namespace, name, ok := strings.Cut(obj.GetAnnotations()[policy.ManagedPolicyAnnotation], "/")
+	if !ok || name == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+}
+
+// mapNamespace maps a namespace to the ManagedPolicies with a namespace selector
+func (r *ManagedPolicyReconciler) mapNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+	list := &policyv1alpha1.ManagedPolicyList{}
+	if err := r.List(ctx, list); err != nil {
+		r.Log.Error(err, "Failed to list ManagedPolicies for namespace", "namespace", obj.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, mp := range list.Items {
+		if mp.Spec.NamespaceSelector != nil {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: mp.Namespace, Name: mp.Name}})
+		}
+	}
+	return requests
 }
@@ -12,6 +12,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+	"github.com/policy-hub/operator/internal/saas"
 	"github.com/policy-hub/operator/internal/sync"
 	"github.com/policy-hub/operator/internal/telemetry/aggregator"
 	"github.com/policy-hub/operator/internal/telemetry/collector"
@@ -39,6 +40,7 @@ type PolicyHubConfigReconciler struct {
 
 	// Validation agent
 	validationAgent   *validation.Agent
+	validationMu      stdsync.Mutex // Protects validationAgent, read by the Hubble event handler
 }
 
 // +kubebuilder:rbac:groups=policyhub.io,resources=policyhubconfigs,verbs=get;list;watch;create;update;patch;delete
@@ -49,6 +51,7 @@ type PolicyHubConfigReconciler struct {
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
 // +kubebuilder:rbac:groups=cilium.io,resources=ciliumnetworkpolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cilium.io,resources=ciliumclusterwidenetworkpolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cilium.io,resources=tracingpolicies,verbs=get;list;watch;create;update;patch;delete
@@ -124,17 +127,33 @@ func (r *PolicyHubConfigReconciler) startBackgroundTasks() {
 	syncInterval := r.Reconciler.GetSyncInterval()
 	r.syncTicker = time.NewTicker(syncInterval)
 
+	// Sync as soon as the SaaS platform notifies of changes. Interval polling
+	// remains the fallback while the event stream is disconnected.
+	events := r.Reconciler.GetEventStream()
+	var policyEvents, gatewayEvents <-chan struct{}
+	if events != nil {
+		policyEvents = events.Subscribe(saas.EventPolicies)
+		gatewayEvents = events.Subscribe(saas.EventGatewayAPI)
+	}
+
 	go func() {
 		for {
 			select {
 			case <-r.syncTicker.C:
-				// Update lastReconcileSync to coordinate with controller's Reconcile
 				r.syncMu.Lock()
-				r.lastReconcileSync = time.Now()
+				recent := time.Since(r.lastReconcileSync) < saas.StreamResyncInterval
 				r.syncMu.Unlock()
-
-				if err := r.Reconciler.SyncPolicies(bgCtx); err != nil {
-					r.Log.Error(err, "Background sync failed")
+				if events.Connected() && recent {
+					continue
+				}
+				r.backgroundSync(bgCtx)
+			case <-policyEvents:
+				r.Log.V(1).Info("Policies changed in SaaS, syncing")
+				r.backgroundSync(bgCtx)
+			case <-gatewayEvents:
+				r.Log.V(1).Info("Gateway API resources changed in SaaS, syncing")
+				if err := r.Reconciler.SyncGatewayAPIResources(bgCtx); err != nil {
+					r.Log.Error(err, "Gateway API resource sync failed")
 				}
 			case <-r.stopChan:
 				r.syncTicker.Stop()
@@ -170,6 +189,12 @@ func (r *PolicyHubConfigReconciler) startBackgroundTasks() {
 		"syncInterval", syncInterval,
 		"heartbeatInterval", hbInterval)
 
+	// Telemetry and validation results are only sent to the SaaS platform
+	if r.Reconciler.IsOffline() {
+		r.Log.Info("Syncing policies from a directory, telemetry and validation are disabled")
+		return
+	}
+
 	// Start telemetry collection if enabled
 	r.startTelemetryCollection(bgCtx)
 
@@ -177,6 +202,18 @@ func (r *PolicyHubConfigReconciler) startBackgroundTasks() {
 	r.startValidationAgent(bgCtx)
 }
 
+// backgroundSync syncs policies outside of Reconcile
+func (r *PolicyHubConfigReconciler) backgroundSync(ctx context.Context) {
+	// Update lastReconcileSync to coordinate with controller's Reconcile
+	r.syncMu.Lock()
+	r.lastReconcileSync = time.Now()
+	r.syncMu.Unlock()
+
+	if err := r.Reconciler.SyncPolicies(ctx); err != nil {
+		r.Log.Error(err, "Background sync failed")
+	}
+}
+
 // startTelemetryCollection starts Hubble flow collection and SaaS sending
 func (r *PolicyHubConfigReconciler) startTelemetryCollection(ctx context.Context) {
 	r.telemetryMu.Lock()
@@ -238,6 +275,7 @@ func (r *PolicyHubConfigReconciler) startTelemetryCollection(ctx context.Context
 		ClusterID:    clusterID,
 		SendInterval: sendInterval,
 		NodeName:     nodeName,
+		Client:       r.Reconciler.GetOutboxClient(),
 		Logger:       r.Log,
 	})
 
@@ -248,9 +286,13 @@ func (r *PolicyHubConfigReconciler) startTelemetryCollection(ctx context.Context
 		Logger:   r.Log,
 	})
 
-	// Set event handler to forward events to SaaS sender
+	// Set event handler to forward events to SaaS sender and the validation
+	// agent, which counts blocked flows for staged rollouts
 	r.hubbleClient.SetEventHandler(func(event *models.TelemetryEvent) {
 		r.saasSender.AddEvent(event)
+		if agent := r.runningValidationAgent(); agent != nil {
+			agent.ProcessEvent(event)
+		}
 	})
 
 	// Start SaaS sender in background
@@ -308,7 +350,7 @@ func (r *PolicyHubConfigReconciler) startTelemetryCollection(ctx context.Context
 // startValidationAgent starts the validation agent for Gateway API and policy validation
 func (r *PolicyHubConfigReconciler) startValidationAgent(ctx context.Context) {
 	// Skip if validation agent already running
-	if r.validationAgent != nil && r.validationAgent.IsRunning() {
+	if r.runningValidationAgent() != nil {
 		return
 	}
 
@@ -331,7 +373,7 @@ func (r *PolicyHubConfigReconciler) startValidationAgent(ctx context.Context) {
 		"clusterID", clusterID)
 
 	// Create validation agent
-	r.validationAgent = validation.NewAgent(validation.AgentOptions{
+	agent := validation.NewAgent(validation.AgentOptions{
 		Client:          r.Client,
 		SaaSEndpoint:    endpoint,
 		APIKey:          apiToken,
@@ -341,17 +383,35 @@ func (r *PolicyHubConfigReconciler) startValidationAgent(ctx context.Context) {
 		EventBufferSize: 1000,
 		EventSampleRate: 10, // Sample 1 in 10 flow events
 		Logger:          r.Log,
+		SaaSClient:      r.Reconciler.GetOutboxClient(),
 	})
 
 	// Start the agent
-	if err := r.validationAgent.Start(ctx); err != nil {
+	if err := agent.Start(ctx); err != nil {
 		r.Log.Error(err, "Failed to start validation agent")
 		return
 	}
 
+	r.validationMu.Lock()
+	r.validationAgent = agent
+	r.validationMu.Unlock()
+
+	// Staged rollouts and audit mode promotions use its flow counts
+	r.Reconciler.SetFlowCounter(agent)
+
 	r.Log.Info("Validation agent started")
 }
 
+// runningValidationAgent returns the validation agent if it is running
+func (r *PolicyHubConfigReconciler) runningValidationAgent() *validation.Agent {
+	r.validationMu.Lock()
+	defer r.validationMu.Unlock()
+	if r.validationAgent == nil || !r.validationAgent.IsRunning() {
+		return nil
+	}
+	return r.validationAgent
+}
+
 // SetupWithManager sets up the controller with the Manager
 func (r *PolicyHubConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -3,20 +3,41 @@ package policy
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 
 	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
 )
 
+// FieldManager is the field manager deployed resources are applied with
+const FieldManager = "policy-hub-operator"
+
+// ManagedPolicyAnnotation references the ManagedPolicy a resource was deployed from, as namespace/name
+const ManagedPolicyAnnotation = "policyhub.io/managed-policy"
+
+// ManagedByLabel marks resources deployed by the operator
+const ManagedByLabel = "app.kubernetes.io/managed-by"
+
+// ManagedKinds are the resource kinds policies are deployed as
+var ManagedKinds = []schema.GroupVersionKind{
+	{Group: "cilium.io", Version: "v2", Kind: "CiliumNetworkPolicy"},
+	{Group: "cilium.io", Version: "v2", Kind: "CiliumClusterwideNetworkPolicy"},
+	{Group: "cilium.io", Version: "v1alpha1", Kind: "TracingPolicy"},
+	{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "HTTPRoute"},
+	{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "GRPCRoute"},
+	{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Kind: "TCPRoute"},
+}
+
 // Deployer handles deploying policies to Kubernetes
 type Deployer struct {
 	client client.Client
@@ -33,50 +54,100 @@ func NewDeployer(c client.Client, log logr.Logger) *Deployer {
 
 // DeployResult contains the result of a deployment operation
 type DeployResult struct {
-	Success           bool
+	Success bool
+	// DeployedResources lists the applied resources. When Success is false
+	// the resources of the other targets may have been applied.
 	DeployedResources []policyv1alpha1.DeployedResource
-	Error             error
+	// Targets is the outcome of each resource the policy deploys, empty when
+	// the content could not be parsed
+	Targets []TargetResult
+	// Namespaces the namespaced resources were deployed to
+	Namespaces []string
+	Error      error
+}
+
+// TargetResult is the outcome of applying one resource to one target
+type TargetResult struct {
+	Resource policyv1alpha1.DeployedResource
+	Error    error
+}
+
+// Partial returns true if some targets were deployed and others failed
+func (r DeployResult) Partial() bool {
+	return !r.Success && len(r.DeployedResources) > 0
 }
 
 // Deploy deploys a policy to the cluster
 func (d *Deployer) Deploy(ctx context.Context, policy *policyv1alpha1.ManagedPolicy) DeployResult {
+	namespaces, err := d.ResolveNamespaces(ctx, policy)
+	if err != nil {
+		return DeployResult{Error: err}
+	}
+	return d.DeployToNamespaces(ctx, policy, namespaces)
+}
+
+// DeployToNamespaces deploys a policy's namespaced resources that do not set
+// a namespace to the given namespaces only. Cluster-scoped resources and
+// resources with a namespace are always deployed. A resource that fails to
+// apply does not stop the others from being applied.
+func (d *Deployer) DeployToNamespaces(ctx context.Context, policy *policyv1alpha1.ManagedPolicy, namespaces []string) DeployResult {
 	d.log.Info("Deploying policy",
 		"name", policy.Spec.Name,
 		"type", policy.Spec.PolicyType,
-		"version", policy.Spec.Version)
+		"version", policy.Spec.Version,
+		"namespaces", namespaces)
 
 	// Parse the policy content
-	resources, err := d.parseContent(policy.Spec.Content)
+	resources, err := d.desiredResources(ctx, policy, namespaces)
 	if err != nil {
 		return DeployResult{
 			Success: false,
-			Error:   fmt.Errorf("failed to parse policy content: %w", err),
-		}
-	}
-
-	if len(resources) == 0 {
-		return DeployResult{
-			Success: false,
-			Error:   fmt.Errorf("no resources found in policy content"),
+			Error:   err,
 		}
 	}
 
 	// Deploy each resource
 	var deployedResources []policyv1alpha1.DeployedResource
+	var targets []TargetResult
+	var errs []error
 	for _, resource := range resources {
-		// Set ownership and labels
-		d.setMetadata(resource, policy)
-
 		// Apply the resource
-		deployed, err := d.applyResource(ctx, resource, policy)
+		deployed, err := d.applyResource(ctx, resource)
 		if err != nil {
-			return DeployResult{
-				Success: false,
-				Error:   fmt.Errorf("failed to deploy resource %s/%s: %w", resource.GetKind(), resource.GetName(), err),
+			if ns := resource.GetNamespace(); ns != "" {
+				err = fmt.Errorf("failed to deploy resource %s/%s in %s: %w", resource.GetKind(), resource.GetName(), ns, err)
+			} else {
+				err = fmt.Errorf("failed to deploy resource %s/%s: %w", resource.GetKind(), resource.GetName(), err)
 			}
+			errs = append(errs, err)
+			targets = append(targets, TargetResult{
+				Resource: policyv1alpha1.DeployedResource{
+					APIVersion: resource.GetAPIVersion(),
+					Kind:       resource.GetKind(),
+					Name:       resource.GetName(),
+					Namespace:  resource.GetNamespace(),
+				},
+				Error: err,
+			})
+			continue
 		}
 
 		deployedResources = append(deployedResources, *deployed)
+		targets = append(targets, TargetResult{Resource: *deployed})
+	}
+
+	if len(errs) > 0 {
+		d.log.Info("Failed to deploy policy to some targets",
+			"name", policy.Spec.Name,
+			"deployed", len(deployedResources),
+			"failed", len(errs))
+		return DeployResult{
+			Success:           false,
+			DeployedResources: deployedResources,
+			Targets:           targets,
+			Namespaces:        namespaces,
+			Error:             utilerrors.NewAggregate(errs),
+		}
 	}
 
 	d.log.Info("Successfully deployed policy",
@@ -86,6 +157,8 @@ func (d *Deployer) Deploy(ctx context.Context, policy *policyv1alpha1.ManagedPol
 	return DeployResult{
 		Success:           true,
 		DeployedResources: deployedResources,
+		Targets:           targets,
+		Namespaces:        namespaces,
 	}
 }
 
@@ -95,7 +168,17 @@ func (d *Deployer) Delete(ctx context.Context, policy *policyv1alpha1.ManagedPol
 		"name", policy.Spec.Name,
 		"resourceCount", len(policy.Status.DeployedResources))
 
-	for _, res := range policy.Status.DeployedResources {
+	_, err := d.DeleteResources(ctx, policy.Status.DeployedResources)
+	return err
+}
+
+// DeleteResources deletes deployed resources, ignoring those already gone.
+// Every resource is attempted; those that could not be deleted are returned
+// with the aggregated errors.
+func (d *Deployer) DeleteResources(ctx context.Context, resources []policyv1alpha1.DeployedResource) ([]policyv1alpha1.DeployedResource, error) {
+	var remaining []policyv1alpha1.DeployedResource
+	var errs []error
+	for _, res := range resources {
 		gvk := schema.FromAPIVersionAndKind(res.APIVersion, res.Kind)
 
 		obj := &unstructured.Unstructured{}
@@ -103,10 +186,10 @@ func (d *Deployer) Delete(ctx context.Context, policy *policyv1alpha1.ManagedPol
 		obj.SetName(res.Name)
 		obj.SetNamespace(res.Namespace)
 
-		if err := d.client.Delete(ctx, obj); err != nil {
-			if !errors.IsNotFound(err) {
-				return fmt.Errorf("failed to delete %s/%s: %w", res.Kind, res.Name, err)
-			}
+		if err := d.client.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+			remaining = append(remaining, res)
+			errs = append(errs, fmt.Errorf("failed to delete %s/%s: %w", res.Kind, res.Name, err))
+			continue
 		}
 
 		d.log.V(1).Info("Deleted resource",
@@ -115,7 +198,84 @@ func (d *Deployer) Delete(ctx context.Context, policy *policyv1alpha1.ManagedPol
 			"namespace", res.Namespace)
 	}
 
-	return nil
+	return remaining, utilerrors.NewAggregate(errs)
+}
+
+// TargetNamespaces returns the namespaces a policy's namespaced resources
+// are deployed to: its target namespaces, or its own namespace. Namespaces
+// matching its namespace selector are resolved by ResolveNamespaces.
+func TargetNamespaces(policy *policyv1alpha1.ManagedPolicy) []string {
+	if len(policy.Spec.TargetNamespaces) > 0 || policy.Spec.NamespaceSelector != nil {
+		return policy.Spec.TargetNamespaces
+	}
+	return []string{policy.Namespace}
+}
+
+// ResolveNamespaces returns the target namespaces of a policy followed by the
+// active namespaces matching its namespace selector, in name order
+func (d *Deployer) ResolveNamespaces(ctx context.Context, policy *policyv1alpha1.ManagedPolicy) ([]string, error) {
+	namespaces := TargetNamespaces(policy)
+	if policy.Spec.NamespaceSelector == nil {
+		return namespaces, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(policy.Spec.NamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespace selector: %w", err)
+	}
+	list := &corev1.NamespaceList{}
+	if err := d.client.List(ctx, list, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	seen := make(map[string]bool, len(namespaces))
+	for _, ns := range namespaces {
+		seen[ns] = true
+	}
+	var matched []string
+	for _, ns := range list.Items {
+		// Resources cannot be created in a terminating namespace
+		if seen[ns.Name] || ns.Status.Phase == corev1.NamespaceTerminating {
+			continue
+		}
+		matched = append(matched, ns.Name)
+	}
+	sort.Strings(matched)
+	return append(append([]string{}, namespaces...), matched...), nil
+}
+
+// desiredResources parses a policy's content into the resources to deploy,
+// with tracking metadata set and adapted to the policy's mode. Namespaced resources without a namespace are
+// cloned into each of the namespaces.
+func (d *Deployer) desiredResources(ctx context.Context, policy *policyv1alpha1.ManagedPolicy, namespaces []string) ([]*unstructured.Unstructured, error) {
+	parsed, err := d.parseContent(policy.Spec.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse policy content: %w", err)
+	}
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("no resources found in policy content")
+	}
+	if err := d.validateMode(ctx, parsed, policy); err != nil {
+		return nil, err
+	}
+
+	var resources []*unstructured.Unstructured
+	for _, resource := range parsed {
+		// Set ownership and labels
+		d.setMetadata(resource, policy)
+		applyMode(resource, policy)
+
+		if resource.GetNamespace() != "" || d.isClusterScoped(resource.GroupVersionKind()) {
+			resources = append(resources, resource)
+			continue
+		}
+		for _, ns := range namespaces {
+			clone := resource.DeepCopy()
+			clone.SetNamespace(ns)
+			resources = append(resources, clone)
+		}
+	}
+	return resources, nil
 }
 
 // parseContent parses YAML content into unstructured resources
@@ -152,7 +312,7 @@ func (d *Deployer) setMetadata(resource *unstructured.Unstructured, policy *poli
 	if labels == nil {
 		labels = make(map[string]string)
 	}
-	labels["app.kubernetes.io/managed-by"] = "policy-hub-operator"
+	labels[ManagedByLabel] = "policy-hub-operator"
 	labels["policyhub.io/policy-id"] = policy.Spec.PolicyID
 	labels["policyhub.io/policy-name"] = policy.Spec.Name
 	resource.SetLabels(labels)
@@ -162,56 +322,23 @@ func (d *Deployer) setMetadata(resource *unstructured.Unstructured, policy *poli
 		annotations = make(map[string]string)
 	}
 	annotations["policyhub.io/version"] = fmt.Sprintf("%d", policy.Spec.Version)
-	annotations["policyhub.io/managed-policy"] = fmt.Sprintf("%s/%s", policy.Namespace, policy.Name)
+	annotations[ManagedPolicyAnnotation] = fmt.Sprintf("%s/%s", policy.Namespace, policy.Name)
 	resource.SetAnnotations(annotations)
 }
 
-// applyResource creates or updates a resource
-func (d *Deployer) applyResource(ctx context.Context, resource *unstructured.Unstructured, policy *policyv1alpha1.ManagedPolicy) (*policyv1alpha1.DeployedResource, error) {
+// applyResource server-side applies a resource, taking ownership of the
+// fields it sets from any other field manager
+func (d *Deployer) applyResource(ctx context.Context, resource *unstructured.Unstructured) (*policyv1alpha1.DeployedResource, error) {
 	gvk := resource.GroupVersionKind()
 
-	// Handle namespace for namespaced resources
-	if resource.GetNamespace() == "" && !d.isClusterScoped(gvk) {
-		// Use target namespaces or default to policy namespace
-		if len(policy.Spec.TargetNamespaces) > 0 {
-			resource.SetNamespace(policy.Spec.TargetNamespaces[0])
-		} else {
-			resource.SetNamespace(policy.Namespace)
-		}
-	}
-
-	// Try to get existing resource
-	existing := &unstructured.Unstructured{}
-	existing.SetGroupVersionKind(gvk)
-	err := d.client.Get(ctx, types.NamespacedName{
-		Name:      resource.GetName(),
-		Namespace: resource.GetNamespace(),
-	}, existing)
-
-	if err != nil {
-		if errors.IsNotFound(err) {
-			// Create new resource
-			if err := d.client.Create(ctx, resource); err != nil {
-				return nil, fmt.Errorf("failed to create: %w", err)
-			}
-			d.log.V(1).Info("Created resource",
-				"kind", gvk.Kind,
-				"name", resource.GetName(),
-				"namespace", resource.GetNamespace())
-		} else {
-			return nil, fmt.Errorf("failed to get existing resource: %w", err)
-		}
-	} else {
-		// Update existing resource
-		resource.SetResourceVersion(existing.GetResourceVersion())
-		if err := d.client.Update(ctx, resource); err != nil {
-			return nil, fmt.Errorf("failed to update: %w", err)
-		}
-		d.log.V(1).Info("Updated resource",
-			"kind", gvk.Kind,
-			"name", resource.GetName(),
-			"namespace", resource.GetNamespace())
+	if err := d.client.Apply(ctx, client.ApplyConfigurationFromUnstructured(resource),
+		client.FieldOwner(FieldManager), client.ForceOwnership); err != nil {
+		return nil, fmt.Errorf("failed to apply: %w", err)
 	}
+	d.log.V(1).Info("Applied resource",
+		"kind", gvk.Kind,
+		"name", resource.GetName(),
+		"namespace", resource.GetNamespace())
 
 	return &policyv1alpha1.DeployedResource{
 		APIVersion: resource.GetAPIVersion(),
@@ -240,7 +367,7 @@ func (d *Deployer) isClusterScoped(gvk schema.GroupVersionKind) bool {
 }
 
 // ValidatePolicy validates that a policy can be deployed
-func (d *Deployer) ValidatePolicy(policy *policyv1alpha1.ManagedPolicy) error {
+func (d *Deployer) ValidatePolicy(ctx context.Context, policy *policyv1alpha1.ManagedPolicy) error {
 	if policy.Spec.Content == "" {
 		return fmt.Errorf("policy content is empty")
 	}
@@ -272,7 +399,7 @@ func (d *Deployer) ValidatePolicy(policy *policyv1alpha1.ManagedPolicy) error {
 		return err
 	}
 
-	return nil
+	return d.validateMode(ctx, resources, policy)
 }
 
 // validatePolicyType ensures resources match the declared policy type
@@ -325,16 +452,7 @@ func (d *Deployer) GetDeployedResources(ctx context.Context, policyID string) ([
 	}
 
 	// Check common resource types
-	resourceTypes := []schema.GroupVersionKind{
-		{Group: "cilium.io", Version: "v2", Kind: "CiliumNetworkPolicy"},
-		{Group: "cilium.io", Version: "v2", Kind: "CiliumClusterwideNetworkPolicy"},
-		{Group: "cilium.io", Version: "v1alpha1", Kind: "TracingPolicy"},
-		{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "HTTPRoute"},
-		{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "GRPCRoute"},
-		{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Kind: "TCPRoute"},
-	}
-
-	for _, gvk := range resourceTypes {
+	for _, gvk := range ManagedKinds {
 		list := &unstructured.UnstructuredList{}
 		list.SetGroupVersionKind(gvk)
 
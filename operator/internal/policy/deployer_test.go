@@ -0,0 +1,135 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// newNamespaceTestDeployer returns a deployer backed by a fake client holding
+// the given namespaces, where applying to failNamespace fails
+func newNamespaceTestDeployer(failNamespace string, namespaces ...*corev1.Namespace) *Deployer {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(ManagedKinds[0], meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), meta.RESTScopeRoot)
+
+	b := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithRESTMapper(mapper)
+	for _, ns := range namespaces {
+		b = b.WithObjects(ns)
+	}
+	c := b.WithInterceptorFuncs(interceptor.Funcs{
+		Apply: func(ctx context.Context, c client.WithWatch, obj runtime.ApplyConfiguration, opts ...client.ApplyOption) error {
+			if o, ok := obj.(interface{ GetNamespace() string }); ok && o.GetNamespace() == failNamespace {
+				return fmt.Errorf("admission webhook denied the request")
+			}
+			return c.Apply(ctx, obj, opts...)
+		},
+	}).Build()
+	return NewDeployer(c, logr.Discard())
+}
+
+func testNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func TestResolveNamespaces(t *testing.T) {
+	terminating := testNamespace("payments-old", map[string]string{"team": "payments"})
+	terminating.Status.Phase = corev1.NamespaceTerminating
+	d := newNamespaceTestDeployer("",
+		testNamespace("payments-b", map[string]string{"team": "payments"}),
+		testNamespace("payments-a", map[string]string{"team": "payments"}),
+		testNamespace("default", map[string]string{"team": "payments"}),
+		testNamespace("search", map[string]string{"team": "search"}),
+		terminating,
+	)
+
+	tests := []struct {
+		name     string
+		targets  []string
+		selector *metav1.LabelSelector
+		want     string
+	}{
+		{
+			name: "no selector or targets",
+			want: "policy-hub-system",
+		},
+		{
+			name:    "targets only",
+			targets: []string{"default", "backend"},
+			want:    "default,backend",
+		},
+		{
+			name:     "selector only",
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+			want:     "default,payments-a,payments-b",
+		},
+		{
+			name:     "targets and selector",
+			targets:  []string{"default", "backend"},
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+			want:     "default,backend,payments-a,payments-b",
+		},
+		{
+			name:     "selector matching nothing",
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "billing"}},
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mp := newDriftTestPolicy()
+			mp.Spec.TargetNamespaces = tt.targets
+			mp.Spec.NamespaceSelector = tt.selector
+
+			namespaces, err := d.ResolveNamespaces(context.Background(), mp)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if got := strings.Join(namespaces, ","); got != tt.want {
+				t.Errorf("Expected namespaces %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDeployToNamespaces_PartialFailure(t *testing.T) {
+	d := newNamespaceTestDeployer("frontend")
+	mp := newDriftTestPolicy()
+
+	result := d.DeployToNamespaces(context.Background(), mp, []string{"default", "frontend", "backend"})
+	if result.Success || !result.Partial() {
+		t.Fatalf("Expected a partial deployment, got success=%v error=%v", result.Success, result.Error)
+	}
+	if !strings.Contains(result.Error.Error(), "failed to deploy resource CiliumNetworkPolicy/api in frontend") {
+		t.Errorf("Expected the error to name the failed target, got: %v", result.Error)
+	}
+
+	var deployed []string
+	for _, res := range result.DeployedResources {
+		deployed = append(deployed, res.Namespace)
+	}
+	if got := strings.Join(deployed, ","); got != "default,backend" {
+		t.Errorf("Expected the other targets to be deployed, got %s", got)
+	}
+	if len(result.Targets) != 3 || result.Targets[1].Resource.Namespace != "frontend" || result.Targets[1].Error == nil {
+		t.Errorf("Expected a result per target with frontend failed, got %+v", result.Targets)
+	}
+
+	// Failing every target is not a partial deployment
+	result = d.DeployToNamespaces(context.Background(), mp, []string{"frontend"})
+	if result.Success || result.Partial() {
+		t.Errorf("Expected the deployment to fail, got success=%v partial=%v", result.Success, result.Partial())
+	}
+}
@@ -0,0 +1,168 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+)
+
+// Drift describes a deployed resource whose live state no longer matches the
+// policy content
+type Drift struct {
+	Resource policyv1alpha1.DeployedResource
+	// Field is the first field found to differ, e.g. "spec.egress[0].toPorts",
+	// or empty when the resource was deleted
+	Field string
+}
+
+// String describes the drift on one line
+func (d Drift) String() string {
+	name := d.Resource.Kind + "/" + d.Resource.Name
+	if d.Resource.Namespace != "" {
+		name = d.Resource.Kind + "/" + d.Resource.Namespace + "/" + d.Resource.Name
+	}
+	if d.Field == "" {
+		return name + " was deleted"
+	}
+	return fmt.Sprintf("%s: %s was modified", name, d.Field)
+}
+
+// DetectDrift compares the live resources of a policy with its content.
+//
+// A resource has drifted when it is missing, or when a field the content sets
+// was changed or removed. Fields the content does not set are ignored, since
+// the API server and admission controllers default them; only changes to
+// list lengths reveal fields added outside the content.
+func (d *Deployer) DetectDrift(ctx context.Context, policy *policyv1alpha1.ManagedPolicy) ([]Drift, error) {
+	namespaces, err := d.ResolveNamespaces(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := d.desiredResources(ctx, policy, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []Drift
+	for _, desired := range resources {
+		ref := policyv1alpha1.DeployedResource{
+			APIVersion: desired.GetAPIVersion(),
+			Kind:       desired.GetKind(),
+			Name:       desired.GetName(),
+			Namespace:  desired.GetNamespace(),
+		}
+
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(desired.GroupVersionKind())
+		err := d.client.Get(ctx, types.NamespacedName{Name: desired.GetName(), Namespace: desired.GetNamespace()}, live)
+		if errors.IsNotFound(err) {
+			drifts = append(drifts, Drift{Resource: ref})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s/%s: %w", desired.GetKind(), desired.GetName(), err)
+		}
+
+		ref.UID = string(live.GetUID())
+		if field, ok := driftedField(desired.Object, live.Object); ok {
+			drifts = append(drifts, Drift{Resource: ref, Field: field})
+		}
+	}
+	return drifts, nil
+}
+
+// driftedField returns the first field of the desired object that differs in
+// the live object. Of the metadata only labels and annotations are compared.
+func driftedField(desired, live map[string]interface{}) (string, bool) {
+	for _, key := range sortedKeys(desired) {
+		switch key {
+		case "apiVersion", "kind", "status":
+			continue
+		case "metadata":
+			meta, _ := desired[key].(map[string]interface{})
+			liveMeta, _ := live[key].(map[string]interface{})
+			for _, metaKey := range []string{"labels", "annotations"} {
+				if value, ok := meta[metaKey]; ok {
+					if field, ok := fieldDiff(value, liveMeta[metaKey], "metadata."+metaKey); ok {
+						return field, true
+					}
+				}
+			}
+			continue
+		}
+		if field, ok := fieldDiff(desired[key], live[key], key); ok {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+// fieldDiff returns the path of the first value of desired that live does not
+// have. Maps in live may hold more keys; lists must have the same length.
+func fieldDiff(desired, live interface{}, path string) (string, bool) {
+	switch want := desired.(type) {
+	case map[string]interface{}:
+		got, ok := live.(map[string]interface{})
+		if !ok {
+			return path, true
+		}
+		for _, key := range sortedKeys(want) {
+			if field, ok := fieldDiff(want[key], got[key], path+"."+key); ok {
+				return field, true
+			}
+		}
+		return "", false
+	case []interface{}:
+		got, ok := live.([]interface{})
+		if !ok || len(got) != len(want) {
+			return path, true
+		}
+		for i := range want {
+			if field, ok := fieldDiff(want[i], got[i], fmt.Sprintf("%s[%d]", path, i)); ok {
+				return field, true
+			}
+		}
+		return "", false
+	default:
+		if wantNum, ok := toFloat(desired); ok {
+			if gotNum, ok := toFloat(live); ok && wantNum == gotNum {
+				return "", false
+			}
+			return path, true
+		}
+		if !reflect.DeepEqual(desired, live) {
+			return path, true
+		}
+		return "", false
+	}
+}
+
+// toFloat converts the number types JSON and YAML decoding produce
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
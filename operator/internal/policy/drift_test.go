@@ -0,0 +1,228 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+)
+
+const driftTestContent = `apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: api
+spec:
+  endpointSelector:
+    matchLabels:
+      app: api
+  egress:
+  - toPorts:
+    - ports:
+      - port: "53"
+        protocol: UDP
+`
+
+// newTestDeployer returns a deployer backed by a fake client that knows the managed kinds
+func newTestDeployer() (*Deployer, client.Client) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for _, gvk := range ManagedKinds {
+		scope := meta.RESTScopeNamespace
+		if gvk.Kind == "CiliumClusterwideNetworkPolicy" || gvk.Kind == "TracingPolicy" {
+			scope = meta.RESTScopeRoot
+		}
+		mapper.Add(gvk, scope)
+	}
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithRESTMapper(mapper).Build()
+	return NewDeployer(c, logr.Discard()), c
+}
+
+func newDriftTestPolicy() *policyv1alpha1.ManagedPolicy {
+	return &policyv1alpha1.ManagedPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-policy", Namespace: "policy-hub-system"},
+		Spec: policyv1alpha1.ManagedPolicySpec{
+			PolicyID:         "policy-1",
+			Name:             "API Policy",
+			PolicyType:       policyv1alpha1.PolicyTypeCiliumNetwork,
+			Content:          driftTestContent,
+			TargetNamespaces: []string{"default"},
+			Version:          1,
+		},
+	}
+}
+
+func getLive(t *testing.T, c client.Client) *unstructured.Unstructured {
+	t.Helper()
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(ManagedKinds[0])
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "api"}, live); err != nil {
+		t.Fatalf("Failed to get deployed resource: %v", err)
+	}
+	return live
+}
+
+func TestDeploy_ServerSideApply(t *testing.T) {
+	d, c := newTestDeployer()
+	mp := newDriftTestPolicy()
+
+	result := d.Deploy(context.Background(), mp)
+	if !result.Success {
+		t.Fatalf("Expected deploy to succeed, got: %v", result.Error)
+	}
+	if len(result.DeployedResources) != 1 || result.DeployedResources[0].Namespace != "default" {
+		t.Fatalf("Unexpected deployed resources: %+v", result.DeployedResources)
+	}
+
+	live := getLive(t, c)
+	if live.GetLabels()[ManagedByLabel] != "policy-hub-operator" {
+		t.Errorf("Expected managed-by label, got %v", live.GetLabels())
+	}
+	if live.GetAnnotations()[ManagedPolicyAnnotation] != "policy-hub-system/api-policy" {
+		t.Errorf("Expected managed-policy annotation, got %v", live.GetAnnotations())
+	}
+
+	// Re-applying takes over fields changed by another manager
+	_ = unstructured.SetNestedStringMap(live.Object, map[string]string{"app": "web"}, "spec", "endpointSelector", "matchLabels")
+	if err := c.Update(context.Background(), live, client.FieldOwner("kubectl-edit")); err != nil {
+		t.Fatalf("Failed to update: %v", err)
+	}
+	mp.Spec.Version = 2
+	if result := d.Deploy(context.Background(), mp); !result.Success {
+		t.Fatalf("Expected redeploy to succeed, got: %v", result.Error)
+	}
+	live = getLive(t, c)
+	if got := live.GetAnnotations()["policyhub.io/version"]; got != "2" {
+		t.Errorf("Expected version annotation 2, got %q", got)
+	}
+	if got, _, _ := unstructured.NestedString(live.Object, "spec", "endpointSelector", "matchLabels", "app"); got != "api" {
+		t.Errorf("Expected endpoint selector to be restored, got app=%q", got)
+	}
+}
+
+func TestDetectDrift(t *testing.T) {
+	tests := []struct {
+		name      string
+		mutate    func(t *testing.T, c client.Client, live *unstructured.Unstructured)
+		wantField string
+		wantDrift bool
+	}{
+		{
+			name: "no drift",
+		},
+		{
+			name: "defaulted fields are ignored",
+			mutate: func(t *testing.T, c client.Client, live *unstructured.Unstructured) {
+				_ = unstructured.SetNestedField(live.Object, "v1", "metadata", "labels", "extra")
+				_ = unstructured.SetNestedField(live.Object, map[string]interface{}{"ingress": true}, "spec", "enableDefaultDeny")
+				if err := c.Update(context.Background(), live); err != nil {
+					t.Fatalf("Failed to update: %v", err)
+				}
+			},
+		},
+		{
+			name: "modified field",
+			mutate: func(t *testing.T, c client.Client, live *unstructured.Unstructured) {
+				_ = unstructured.SetNestedStringMap(live.Object, map[string]string{"app": "web"}, "spec", "endpointSelector", "matchLabels")
+				if err := c.Update(context.Background(), live); err != nil {
+					t.Fatalf("Failed to update: %v", err)
+				}
+			},
+			wantField: "spec.endpointSelector.matchLabels.app",
+			wantDrift: true,
+		},
+		{
+			name: "added rule",
+			mutate: func(t *testing.T, c client.Client, live *unstructured.Unstructured) {
+				egress, _, _ := unstructured.NestedSlice(live.Object, "spec", "egress")
+				egress = append(egress, map[string]interface{}{"toEntities": []interface{}{"world"}})
+				_ = unstructured.SetNestedSlice(live.Object, egress, "spec", "egress")
+				if err := c.Update(context.Background(), live); err != nil {
+					t.Fatalf("Failed to update: %v", err)
+				}
+			},
+			wantField: "spec.egress",
+			wantDrift: true,
+		},
+		{
+			name: "removed tracking label",
+			mutate: func(t *testing.T, c client.Client, live *unstructured.Unstructured) {
+				labels := live.GetLabels()
+				delete(labels, "policyhub.io/policy-id")
+				live.SetLabels(labels)
+				if err := c.Update(context.Background(), live); err != nil {
+					t.Fatalf("Failed to update: %v", err)
+				}
+			},
+			wantField: "metadata.labels.policyhub.io/policy-id",
+			wantDrift: true,
+		},
+		{
+			name: "deleted resource",
+			mutate: func(t *testing.T, c client.Client, live *unstructured.Unstructured) {
+				if err := c.Delete(context.Background(), live); err != nil {
+					t.Fatalf("Failed to delete: %v", err)
+				}
+			},
+			wantDrift: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, c := newTestDeployer()
+			mp := newDriftTestPolicy()
+			if result := d.Deploy(context.Background(), mp); !result.Success {
+				t.Fatalf("Expected deploy to succeed, got: %v", result.Error)
+			}
+			if tt.mutate != nil {
+				tt.mutate(t, c, getLive(t, c))
+			}
+
+			drifts, err := d.DetectDrift(context.Background(), mp)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if !tt.wantDrift {
+				if len(drifts) != 0 {
+					t.Errorf("Expected no drift, got %v", drifts)
+				}
+				return
+			}
+			if len(drifts) != 1 {
+				t.Fatalf("Expected 1 drift, got %v", drifts)
+			}
+			if drifts[0].Field != tt.wantField {
+				t.Errorf("Expected drifted field %q, got %q", tt.wantField, drifts[0].Field)
+			}
+			if drifts[0].Resource.Kind != "CiliumNetworkPolicy" || drifts[0].Resource.Namespace != "default" {
+				t.Errorf("Unexpected drifted resource: %+v", drifts[0].Resource)
+			}
+
+			// Re-applying the content reverts the drift
+			if result := d.Deploy(context.Background(), mp); !result.Success {
+				t.Fatalf("Expected redeploy to succeed, got: %v", result.Error)
+			}
+			if drifts, _ := d.DetectDrift(context.Background(), mp); len(drifts) != 0 {
+				t.Errorf("Expected drift to be reverted, got %v", drifts)
+			}
+		})
+	}
+}
+
+func TestFieldDiff_Numbers(t *testing.T) {
+	// YAML content decodes numbers as float64, the API returns int64
+	desired := map[string]interface{}{"port": float64(53)}
+	if field, ok := fieldDiff(desired, map[string]interface{}{"port": int64(53)}, "spec"); ok {
+		t.Errorf("Expected equal numbers to match, got drift at %s", field)
+	}
+	if field, ok := fieldDiff(desired, map[string]interface{}{"port": int64(54)}, "spec"); !ok || field != "spec.port" {
+		t.Errorf("Expected drift at spec.port, got %q", field)
+	}
+}
@@ -0,0 +1,208 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Label prefixes and keys Cilium adds to endpoint selectors.
+const (
+	namespaceLabel       = "io.kubernetes.pod.namespace"
+	namespaceLabelPrefix = "io.kubernetes.pod.namespace.labels."
+	ciliumLabelPrefix    = "io.cilium."
+)
+
+// ciliumPolicy is a CiliumNetworkPolicy or CiliumClusterwideNetworkPolicy.
+// Only the fields the linter inspects are modeled.
+type ciliumPolicy struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Spec  *ciliumRule  `json:"spec"`
+	Specs []ciliumRule `json:"specs"`
+}
+
+// ciliumRule is a single policy rule: the endpoints it selects and the
+// traffic it allows or denies in each direction.
+type ciliumRule struct {
+	EndpointSelector  *metav1.LabelSelector `json:"endpointSelector"`
+	NodeSelector      *metav1.LabelSelector `json:"nodeSelector"`
+	Ingress           []peerRule            `json:"ingress"`
+	IngressDeny       []peerRule            `json:"ingressDeny"`
+	Egress            []peerRule            `json:"egress"`
+	EgressDeny        []peerRule            `json:"egressDeny"`
+	EnableDefaultDeny *struct {
+		Ingress *bool `json:"ingress"`
+		Egress  *bool `json:"egress"`
+	} `json:"enableDefaultDeny"`
+}
+
+// peerRule is an ingress or egress rule. Ingress rules use the from* peer
+// fields and egress rules the to* fields.
+type peerRule struct {
+	FromEndpoints []metav1.LabelSelector `json:"fromEndpoints"`
+	FromEntities  []string               `json:"fromEntities"`
+	FromCIDR      []string               `json:"fromCIDR"`
+	FromCIDRSet   []cidrRule             `json:"fromCIDRSet"`
+	FromRequires  []metav1.LabelSelector `json:"fromRequires"`
+
+	ToEndpoints []metav1.LabelSelector `json:"toEndpoints"`
+	ToEntities  []string               `json:"toEntities"`
+	ToCIDR      []string               `json:"toCIDR"`
+	ToCIDRSet   []cidrRule             `json:"toCIDRSet"`
+	ToFQDNs     []fqdnSelector         `json:"toFQDNs"`
+	ToServices  []interface{}          `json:"toServices"`
+	ToRequires  []metav1.LabelSelector `json:"toRequires"`
+
+	ToPorts []portRule `json:"toPorts"`
+}
+
+type cidrRule struct {
+	CIDR string `json:"cidr"`
+}
+
+type fqdnSelector struct {
+	MatchName    string `json:"matchName"`
+	MatchPattern string `json:"matchPattern"`
+}
+
+type portRule struct {
+	Ports []portProtocol         `json:"ports"`
+	Rules map[string]interface{} `json:"rules"`
+}
+
+type portProtocol struct {
+	Port     string `json:"port"`
+	EndPort  int    `json:"endPort"`
+	Protocol string `json:"protocol"`
+}
+
+// clusterwide reports whether the policy applies across namespaces.
+func (p *ciliumPolicy) clusterwide() bool {
+	return p.Kind == "CiliumClusterwideNetworkPolicy"
+}
+
+// endpoints returns the endpoint selectors of a rule's peers.
+func (r *peerRule) endpoints() []metav1.LabelSelector {
+	return append(append([]metav1.LabelSelector{}, r.FromEndpoints...), r.ToEndpoints...)
+}
+
+// entities returns the entities of a rule's peers.
+func (r *peerRule) entities() []string {
+	return append(append([]string{}, r.FromEntities...), r.ToEntities...)
+}
+
+// cidrs returns the CIDRs of a rule's peers.
+func (r *peerRule) cidrs() []string {
+	cidrs := append(append([]string{}, r.FromCIDR...), r.ToCIDR...)
+	for _, c := range append(append([]cidrRule{}, r.FromCIDRSet...), r.ToCIDRSet...) {
+		cidrs = append(cidrs, c.CIDR)
+	}
+	return cidrs
+}
+
+// peerKeys returns a canonical key per peer of a rule. A rule without peers
+// applies to all peers and returns nil.
+func (r *peerRule) peerKeys() []string {
+	var keys []string
+	for _, sel := range r.endpoints() {
+		keys = append(keys, "endpoints:"+selectorKey(&sel))
+	}
+	for _, entity := range r.entities() {
+		keys = append(keys, "entity:"+strings.ToLower(entity))
+	}
+	for _, cidr := range r.cidrs() {
+		keys = append(keys, "cidr:"+cidr)
+	}
+	for _, fqdn := range r.ToFQDNs {
+		keys = append(keys, "fqdn:"+strings.ToLower(fqdn.MatchName+fqdn.MatchPattern))
+	}
+	for _, svc := range r.ToServices {
+		keys = append(keys, fmt.Sprintf("service:%v", svc))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// hasRequires reports whether a rule narrows its peers with requirements,
+// which the coverage checks do not model.
+func (r *peerRule) hasRequires() bool {
+	return len(r.FromRequires) > 0 || len(r.ToRequires) > 0
+}
+
+// selectorKey renders a label selector canonically.
+func selectorKey(sel *metav1.LabelSelector) string {
+	s, err := metav1.LabelSelectorAsSelector(normalizeSelector(sel))
+	if err != nil {
+		return fmt.Sprintf("%v", sel)
+	}
+	return s.String()
+}
+
+// normalizeSelector strips Cilium's source prefixes ("k8s:", "any:") from the
+// keys of a selector.
+func normalizeSelector(sel *metav1.LabelSelector) *metav1.LabelSelector {
+	out := &metav1.LabelSelector{}
+	if len(sel.MatchLabels) > 0 {
+		out.MatchLabels = make(map[string]string, len(sel.MatchLabels))
+		for k, v := range sel.MatchLabels {
+			out.MatchLabels[stripSource(k)] = v
+		}
+	}
+	for _, expr := range sel.MatchExpressions {
+		expr.Key = stripSource(expr.Key)
+		out.MatchExpressions = append(out.MatchExpressions, expr)
+	}
+	return out
+}
+
+// stripSource removes the source prefix of a Cilium label key.
+func stripSource(key string) string {
+	for _, prefix := range []string{"k8s:", "any:"} {
+		if strings.HasPrefix(key, prefix) {
+			return strings.TrimPrefix(key, prefix)
+		}
+	}
+	return key
+}
+
+// podSelector converts an endpoint selector to a pod label selector and the
+// namespace it is restricted to, if any. It returns ok=false for selectors on
+// labels pods do not carry, such as namespace labels or reserved identities.
+func podSelector(sel *metav1.LabelSelector) (selector labels.Selector, namespace string, ok bool) {
+	normalized := normalizeSelector(sel)
+	if ns, found := normalized.MatchLabels[namespaceLabel]; found {
+		namespace = ns
+		delete(normalized.MatchLabels, namespaceLabel)
+	}
+	for key := range normalized.MatchLabels {
+		if !podLabel(key) {
+			return nil, "", false
+		}
+	}
+	for _, expr := range normalized.MatchExpressions {
+		if !podLabel(expr.Key) {
+			return nil, "", false
+		}
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(normalized)
+	if err != nil {
+		return nil, "", false
+	}
+	return selector, namespace, true
+}
+
+// podLabel reports whether a selector key is a pod label.
+func podLabel(key string) bool {
+	return !strings.HasPrefix(key, "reserved:") &&
+		!strings.HasPrefix(key, ciliumLabelPrefix) &&
+		!strings.HasPrefix(key, namespaceLabelPrefix) &&
+		key != namespaceLabel
+}
@@ -0,0 +1,229 @@
+// Package lint statically analyzes ManagedPolicy content for rules that are
+// redundant, too broad or likely to break the cluster, before it is deployed.
+package lint
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+)
+
+// ConditionType is the ManagedPolicy condition reporting lint findings.
+const ConditionType = "LintPassed"
+
+// maxConditionFindings is the number of findings listed in the condition
+// message.
+const maxConditionFindings = 5
+
+// Severity ranks a finding.
+type Severity string
+
+const (
+	// SeverityError marks policies that will not behave as written
+	SeverityError Severity = "Error"
+	// SeverityWarning marks rules that are likely mistakes
+	SeverityWarning Severity = "Warning"
+	// SeverityInfo marks rules that can be cleaned up
+	SeverityInfo Severity = "Info"
+)
+
+// Check identifiers.
+const (
+	CheckRedundantRule     = "redundant-rule"
+	CheckShadowedRule      = "shadowed-rule"
+	CheckUnmatchedSelector = "unmatched-selector"
+	CheckBroadRule         = "broad-rule"
+	CheckMissingDNSEgress  = "missing-dns-egress"
+	CheckKubeDNSIsolation  = "kube-dns-isolation"
+)
+
+// Finding is a single lint result.
+type Finding struct {
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	// Resource is the kind and name of the policy document, e.g.
+	// "CiliumNetworkPolicy/backend"
+	Resource string `json:"resource"`
+	// Path locates the rule in the document, e.g. "spec.egress[1]"
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// String renders a finding on one line.
+func (f Finding) String() string {
+	if f.Path == "" {
+		return fmt.Sprintf("%s: %s (%s)", f.Resource, f.Message, f.Check)
+	}
+	return fmt.Sprintf("%s %s: %s (%s)", f.Resource, f.Path, f.Message, f.Check)
+}
+
+// Linter analyzes policy content, using the cluster's pods to check
+// selectors.
+type Linter struct {
+	reader client.Reader
+	log    logr.Logger
+}
+
+// NewLinter creates a linter. A nil reader disables the checks that need the
+// cluster.
+func NewLinter(reader client.Reader, log logr.Logger) *Linter {
+	return &Linter{
+		reader: reader,
+		log:    log.WithName("policy-linter"),
+	}
+}
+
+// Lint analyzes the Cilium network policies of a ManagedPolicy. Other policy
+// types and documents that do not parse are skipped; ValidatePolicy reports
+// the latter.
+func (l *Linter) Lint(ctx context.Context, mp *policyv1alpha1.ManagedPolicy) []Finding {
+	switch mp.Spec.PolicyType {
+	case policyv1alpha1.PolicyTypeCiliumNetwork, policyv1alpha1.PolicyTypeCiliumClusterwide:
+	default:
+		return nil
+	}
+
+	// Namespaced documents without a namespace are deployed to every target
+	// namespace; selectors are checked in the first one or the policy's own
+	defaultNamespace := mp.Namespace
+	if len(mp.Spec.TargetNamespaces) > 0 {
+		defaultNamespace = mp.Spec.TargetNamespaces[0]
+	}
+
+	var findings []Finding
+	for _, doc := range strings.Split(mp.Spec.Content, "---") {
+		doc = strings.TrimSpace(doc)
+		if doc == "" {
+			continue
+		}
+		policy := &ciliumPolicy{}
+		if err := yaml.Unmarshal([]byte(doc), policy); err != nil {
+			continue
+		}
+		if policy.Kind != "CiliumNetworkPolicy" && policy.Kind != "CiliumClusterwideNetworkPolicy" {
+			continue
+		}
+		if policy.Metadata.Namespace == "" && !policy.clusterwide() {
+			policy.Metadata.Namespace = defaultNamespace
+		}
+		findings = append(findings, l.lintPolicy(ctx, policy)...)
+	}
+	return findings
+}
+
+// lintPolicy runs every check against a Cilium policy document.
+func (l *Linter) lintPolicy(ctx context.Context, policy *ciliumPolicy) []Finding {
+	resource := policy.Kind + "/" + policy.Metadata.Name
+
+	type pathRule struct {
+		path string
+		rule *ciliumRule
+	}
+	var rules []pathRule
+	if policy.Spec != nil {
+		rules = append(rules, pathRule{"spec", policy.Spec})
+	}
+	for i := range policy.Specs {
+		rules = append(rules, pathRule{fmt.Sprintf("specs[%d]", i), &policy.Specs[i]})
+	}
+
+	var findings []Finding
+	for _, r := range rules {
+		ruleFindings := checkRule(policy, r.rule, r.path)
+		if l.reader != nil {
+			ruleFindings = append(ruleFindings, l.checkSelectors(ctx, policy, r.rule, r.path)...)
+		}
+		for _, f := range ruleFindings {
+			f.Resource = resource
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// checkRule runs the checks that only need the policy itself.
+func checkRule(policy *ciliumPolicy, rule *ciliumRule, path string) []Finding {
+	var findings []Finding
+	findings = append(findings, checkCoverage(rule, "ingress", path)...)
+	findings = append(findings, checkCoverage(rule, "egress", path)...)
+	findings = append(findings, checkBroadRules(rule, path)...)
+	findings = append(findings, checkDNSEgress(rule, path)...)
+	findings = append(findings, checkKubeDNSIsolation(policy, rule, path)...)
+	return findings
+}
+
+// Counts returns the number of findings of each severity.
+func Counts(findings []Finding) map[Severity]int {
+	counts := make(map[Severity]int)
+	for _, f := range findings {
+		counts[f.Severity]++
+	}
+	return counts
+}
+
+// Condition summarizes findings as a ManagedPolicy condition. The condition
+// is False when there are errors or warnings; informational findings are
+// listed but do not fail it.
+func Condition(findings []Finding, generation int64) metav1.Condition {
+	cond := metav1.Condition{
+		Type:               ConditionType,
+		Status:             metav1.ConditionTrue,
+		Reason:             "NoFindings",
+		Message:            "No lint findings",
+		ObservedGeneration: generation,
+		LastTransitionTime: metav1.Now(),
+	}
+	if len(findings) == 0 {
+		return cond
+	}
+
+	counts := Counts(findings)
+	switch {
+	case counts[SeverityError] > 0:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "LintErrors"
+	case counts[SeverityWarning] > 0:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "LintWarnings"
+	default:
+		cond.Reason = "LintInfo"
+	}
+
+	// List the most severe findings first
+	sorted := append([]Finding{}, findings...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return severityRank(sorted[i].Severity) < severityRank(sorted[j].Severity)
+	})
+
+	lines := []string{fmt.Sprintf("%d error(s), %d warning(s), %d info",
+		counts[SeverityError], counts[SeverityWarning], counts[SeverityInfo])}
+	for i, f := range sorted {
+		if i == maxConditionFindings {
+			lines = append(lines, fmt.Sprintf("and %d more", len(findings)-i))
+			break
+		}
+		lines = append(lines, string(f.Severity)+": "+f.String())
+	}
+	cond.Message = strings.Join(lines, "; ")
+	return cond
+}
+
+// severityRank orders severities from most to least severe.
+func severityRank(s Severity) int {
+	switch s {
+	case SeverityError:
+		return 0
+	case SeverityWarning:
+		return 1
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,424 @@
+package lint
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+)
+
+func managedPolicy(policyType policyv1alpha1.PolicyType, content string) *policyv1alpha1.ManagedPolicy {
+	return &policyv1alpha1.ManagedPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-policy", Namespace: "default"},
+		Spec: policyv1alpha1.ManagedPolicySpec{
+			PolicyID:   "policy-1",
+			PolicyType: policyType,
+			Content:    content,
+		},
+	}
+}
+
+// findingKeys renders findings as "check@path" for comparison.
+func findingKeys(findings []Finding) []string {
+	var keys []string
+	for _, f := range findings {
+		keys = append(keys, f.Check+"@"+f.Path)
+	}
+	return keys
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name       string
+		policyType policyv1alpha1.PolicyType
+		content    string
+		want       []string
+	}{
+		{
+			name:       "clean policy",
+			policyType: policyv1alpha1.PolicyTypeCiliumNetwork,
+			content: `apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: api
+spec:
+  endpointSelector:
+    matchLabels:
+      app: api
+  ingress:
+  - fromEndpoints:
+    - matchLabels:
+        app: web
+    toPorts:
+    - ports:
+      - port: "8080"
+        protocol: TCP
+`,
+		},
+		{
+			name:       "duplicate rule",
+			policyType: policyv1alpha1.PolicyTypeCiliumNetwork,
+			content: `kind: CiliumNetworkPolicy
+metadata:
+  name: api
+spec:
+  endpointSelector:
+    matchLabels:
+      app: api
+  ingress:
+  - fromEndpoints:
+    - matchLabels:
+        app: web
+  - fromEndpoints:
+    - matchLabels:
+        k8s:app: web
+`,
+			want: []string{"redundant-rule@spec.ingress[1]"},
+		},
+		{
+			name:       "rule covered by broader port range",
+			policyType: policyv1alpha1.PolicyTypeCiliumNetwork,
+			content: `kind: CiliumNetworkPolicy
+metadata:
+  name: api
+spec:
+  endpointSelector:
+    matchLabels:
+      app: api
+  ingress:
+  - fromEndpoints:
+    - matchLabels:
+        app: web
+    toPorts:
+    - ports:
+      - port: "8080"
+        protocol: TCP
+  - fromEndpoints:
+    - {}
+    toPorts:
+    - ports:
+      - port: "8000"
+        endPort: 9000
+`,
+			want: []string{"shadowed-rule@spec.ingress[0]"},
+		},
+		{
+			name:       "l7 rule is not covered by a different l7 rule",
+			policyType: policyv1alpha1.PolicyTypeCiliumNetwork,
+			content: `kind: CiliumNetworkPolicy
+metadata:
+  name: api
+spec:
+  endpointSelector:
+    matchLabels:
+      app: api
+  ingress:
+  - fromEndpoints:
+    - matchLabels:
+        app: web
+    toPorts:
+    - ports:
+      - port: "8080"
+      rules:
+        http:
+        - method: GET
+  - fromEndpoints:
+    - matchLabels:
+        app: web
+    toPorts:
+    - ports:
+      - port: "8080"
+      rules:
+        http:
+        - method: POST
+`,
+		},
+		{
+			name:       "allow rule shadowed by deny rule",
+			policyType: policyv1alpha1.PolicyTypeCiliumNetwork,
+			content: `kind: CiliumNetworkPolicy
+metadata:
+  name: api
+spec:
+  endpointSelector:
+    matchLabels:
+      app: api
+  egress:
+  - toCIDR:
+    - 10.0.0.0/8
+  egressDeny:
+  - toCIDR:
+    - 10.0.0.0/8
+`,
+			want: []string{"shadowed-rule@spec.egress[0]"},
+		},
+		{
+			name:       "broad rules",
+			policyType: policyv1alpha1.PolicyTypeCiliumNetwork,
+			content: `kind: CiliumNetworkPolicy
+metadata:
+  name: api
+spec:
+  endpointSelector:
+    matchLabels:
+      app: api
+  ingress:
+  - fromEntities:
+    - world
+  egress:
+  - toCIDRSet:
+    - cidr: 0.0.0.0/0
+`,
+			want: []string{"broad-rule@spec.ingress[0]", "broad-rule@spec.egress[0]"},
+		},
+		{
+			name:       "toFQDNs without dns egress",
+			policyType: policyv1alpha1.PolicyTypeCiliumNetwork,
+			content: `kind: CiliumNetworkPolicy
+metadata:
+  name: api
+spec:
+  endpointSelector:
+    matchLabels:
+      app: api
+  egress:
+  - toEndpoints:
+    - matchLabels:
+        io.kubernetes.pod.namespace: kube-system
+        k8s-app: kube-dns
+    toPorts:
+    - ports:
+      - port: "53"
+        protocol: UDP
+  - toFQDNs:
+    - matchName: api.example.com
+`,
+			want: []string{"missing-dns-egress@spec.egress[1]"},
+		},
+		{
+			name:       "toFQDNs with dns egress",
+			policyType: policyv1alpha1.PolicyTypeCiliumNetwork,
+			content: `kind: CiliumNetworkPolicy
+metadata:
+  name: api
+spec:
+  endpointSelector:
+    matchLabels:
+      app: api
+  egress:
+  - toEndpoints:
+    - matchLabels:
+        io.kubernetes.pod.namespace: kube-system
+        k8s-app: kube-dns
+    toPorts:
+    - ports:
+      - port: "53"
+        protocol: ANY
+      rules:
+        dns:
+        - matchPattern: "*"
+  - toFQDNs:
+    - matchName: api.example.com
+`,
+		},
+		{
+			name:       "clusterwide policy isolating kube-dns",
+			policyType: policyv1alpha1.PolicyTypeCiliumClusterwide,
+			content: `kind: CiliumClusterwideNetworkPolicy
+metadata:
+  name: lockdown
+spec:
+  endpointSelector:
+    matchExpressions:
+    - key: k8s-app
+      operator: Exists
+  ingress:
+  - fromEntities:
+    - cluster
+    toPorts:
+    - ports:
+      - port: "443"
+`,
+			want: []string{"kube-dns-isolation@spec.endpointSelector"},
+		},
+		{
+			name:       "kube-dns policy allowing dns",
+			policyType: policyv1alpha1.PolicyTypeCiliumNetwork,
+			content: `kind: CiliumNetworkPolicy
+metadata:
+  name: kube-dns
+  namespace: kube-system
+spec:
+  endpointSelector:
+    matchLabels:
+      k8s-app: kube-dns
+  ingress:
+  - fromEntities:
+    - cluster
+    toPorts:
+    - ports:
+      - port: "53"
+`,
+		},
+		{
+			name:       "namespaced policy outside kube-system",
+			policyType: policyv1alpha1.PolicyTypeCiliumNetwork,
+			content: `kind: CiliumNetworkPolicy
+metadata:
+  name: lockdown
+spec:
+  endpointSelector: {}
+  ingress:
+  - fromEndpoints:
+    - {}
+`,
+		},
+		{
+			name:       "multiple documents and specs",
+			policyType: policyv1alpha1.PolicyTypeCiliumNetwork,
+			content: `kind: ConfigMap
+metadata:
+  name: ignored
+---
+kind: CiliumNetworkPolicy
+metadata:
+  name: api
+specs:
+- endpointSelector:
+    matchLabels:
+      app: api
+  egress:
+  - toEntities:
+    - all
+`,
+			want: []string{"broad-rule@specs[0].egress[0]"},
+		},
+		{
+			name:       "other policy types are skipped",
+			policyType: policyv1alpha1.PolicyTypeTetragon,
+			content: `kind: CiliumNetworkPolicy
+metadata:
+  name: api
+spec:
+  egress:
+  - toEntities:
+    - world
+`,
+		},
+	}
+
+	linter := NewLinter(nil, logr.Discard())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findingKeys(linter.Lint(context.Background(), managedPolicy(tt.policyType, tt.content)))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLint_Selectors(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-0", Namespace: "default", Labels: map[string]string{"app": "api"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "frontend", Labels: map[string]string{"app": "web"}}},
+	).Build()
+
+	content := `kind: CiliumNetworkPolicy
+metadata:
+  name: api
+spec:
+  endpointSelector:
+    matchLabels:
+      app: api
+  ingress:
+  - fromEndpoints:
+    - matchLabels:
+        k8s:io.kubernetes.pod.namespace: frontend
+        app: web
+    - matchLabels:
+        app: web
+    - matchLabels:
+        io.cilium.k8s.policy.serviceaccount: web
+  egress:
+  - toEndpoints:
+    - matchLabels:
+        app: db
+`
+	findings := NewLinter(reader, logr.Discard()).Lint(context.Background(), managedPolicy(policyv1alpha1.PolicyTypeCiliumNetwork, content))
+
+	want := []string{
+		"unmatched-selector@spec.ingress[0].endpoints[1]",
+		"unmatched-selector@spec.egress[0].endpoints[0]",
+	}
+	if got := findingKeys(findings); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Lint() = %v, want %v", got, want)
+	}
+	if !strings.Contains(findings[0].Message, "namespace default") {
+		t.Errorf("Expected message to name the namespace, got %q", findings[0].Message)
+	}
+	if findings[0].Resource != "CiliumNetworkPolicy/api" {
+		t.Errorf("Expected resource CiliumNetworkPolicy/api, got %q", findings[0].Resource)
+	}
+}
+
+func TestCondition(t *testing.T) {
+	info := Finding{Check: CheckRedundantRule, Severity: SeverityInfo, Resource: "CiliumNetworkPolicy/api", Message: "duplicate"}
+	warning := Finding{Check: CheckBroadRule, Severity: SeverityWarning, Resource: "CiliumNetworkPolicy/api", Message: "broad"}
+	failure := Finding{Check: CheckMissingDNSEgress, Severity: SeverityError, Resource: "CiliumNetworkPolicy/api", Message: "no dns"}
+
+	tests := []struct {
+		name       string
+		findings   []Finding
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{name: "no findings", wantStatus: metav1.ConditionTrue, wantReason: "NoFindings"},
+		{name: "info only", findings: []Finding{info}, wantStatus: metav1.ConditionTrue, wantReason: "LintInfo"},
+		{name: "warnings", findings: []Finding{info, warning}, wantStatus: metav1.ConditionFalse, wantReason: "LintWarnings"},
+		{name: "errors", findings: []Finding{info, warning, failure}, wantStatus: metav1.ConditionFalse, wantReason: "LintErrors"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond := Condition(tt.findings, 3)
+			if cond.Type != ConditionType || cond.ObservedGeneration != 3 {
+				t.Errorf("Unexpected condition type or generation: %+v", cond)
+			}
+			if cond.Status != tt.wantStatus || cond.Reason != tt.wantReason {
+				t.Errorf("Condition() = %s/%s, want %s/%s", cond.Status, cond.Reason, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+
+	t.Run("lists most severe findings first", func(t *testing.T) {
+		cond := Condition([]Finding{info, warning, failure}, 1)
+		want := "1 error(s), 1 warning(s), 1 info; Error: CiliumNetworkPolicy/api: no dns (missing-dns-egress); " +
+			"Warning: CiliumNetworkPolicy/api: broad (broad-rule); Info: CiliumNetworkPolicy/api: duplicate (redundant-rule)"
+		if cond.Message != want {
+			t.Errorf("Message = %q, want %q", cond.Message, want)
+		}
+	})
+
+	t.Run("truncates long lists", func(t *testing.T) {
+		findings := make([]Finding, maxConditionFindings+2)
+		for i := range findings {
+			findings[i] = warning
+		}
+		cond := Condition(findings, 1)
+		if !strings.HasSuffix(cond.Message, "; and 2 more") {
+			t.Errorf("Expected truncated message, got %q", cond.Message)
+		}
+	})
+}
@@ -0,0 +1,398 @@
+package lint
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// kubeDNSLabels are the labels of the cluster DNS pods (kube-dns and CoreDNS).
+var kubeDNSLabels = labels.Set{"k8s-app": "kube-dns"}
+
+// kubeDNSNamespace is the namespace of the cluster DNS pods.
+const kubeDNSNamespace = "kube-system"
+
+// checkCoverage flags allow rules of a direction that another rule makes
+// useless: rules identical to an earlier one, rules whose traffic an earlier
+// or broader allow rule already allows, and rules whose traffic a deny rule
+// always drops. Rules with requirements are not compared.
+func checkCoverage(rule *ciliumRule, direction, path string) []Finding {
+	allow, deny := rule.Ingress, rule.IngressDeny
+	if direction == "egress" {
+		allow, deny = rule.Egress, rule.EgressDeny
+	}
+
+	var findings []Finding
+	for i := range allow {
+		b := &allow[i]
+		if b.hasRequires() {
+			continue
+		}
+		rulePath := fmt.Sprintf("%s.%s[%d]", path, direction, i)
+
+		if j, ok := coveredBy(b, deny); ok {
+			findings = append(findings, Finding{
+				Check:    CheckShadowedRule,
+				Severity: SeverityWarning,
+				Path:     rulePath,
+				Message:  fmt.Sprintf("traffic allowed by this rule is always denied by %s.%sDeny[%d]", path, direction, j),
+			})
+			continue
+		}
+
+		for j := range allow {
+			a := &allow[j]
+			if j == i || a.hasRequires() || !covers(a, b) {
+				continue
+			}
+			if covers(b, a) {
+				// Identical rules: only flag the later one
+				if j < i {
+					findings = append(findings, Finding{
+						Check:    CheckRedundantRule,
+						Severity: SeverityInfo,
+						Path:     rulePath,
+						Message:  fmt.Sprintf("rule duplicates %s.%s[%d]", path, direction, j),
+					})
+					break
+				}
+				continue
+			}
+			findings = append(findings, Finding{
+				Check:    CheckShadowedRule,
+				Severity: SeverityInfo,
+				Path:     rulePath,
+				Message:  fmt.Sprintf("traffic allowed by this rule is already allowed by %s.%s[%d]", path, direction, j),
+			})
+			break
+		}
+	}
+	return findings
+}
+
+// coveredBy returns the index of the first rule covering r.
+func coveredBy(r *peerRule, rules []peerRule) (int, bool) {
+	for i := range rules {
+		if !rules[i].hasRequires() && covers(&rules[i], r) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// covers reports whether every flow matching rule b also matches rule a.
+func covers(a, b *peerRule) bool {
+	return peersCover(a, b) && portsCover(a, b)
+}
+
+// peersCover reports whether the peers of a include those of b. A rule
+// without peers, or with the "all" entity, applies to every peer; an empty
+// endpoint selector covers every endpoint peer.
+func peersCover(a, b *peerRule) bool {
+	aKeys := a.peerKeys()
+	if len(aKeys) == 0 {
+		return true
+	}
+	have := make(map[string]bool, len(aKeys))
+	for _, k := range aKeys {
+		have[k] = true
+	}
+	if have["entity:all"] {
+		return true
+	}
+
+	bKeys := b.peerKeys()
+	if len(bKeys) == 0 {
+		return false
+	}
+	for _, k := range bKeys {
+		if have[k] {
+			continue
+		}
+		if strings.HasPrefix(k, "endpoints:") && have["endpoints:"] {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// portsCover reports whether the ports of a include those of b, with the
+// same L7 rules. A rule without ports applies to all ports.
+func portsCover(a, b *peerRule) bool {
+	if len(a.ToPorts) == 0 {
+		return true
+	}
+	if len(b.ToPorts) == 0 {
+		return false
+	}
+	for _, bp := range b.ToPorts {
+		for _, port := range bp.Ports {
+			if !portCovered(port, bp.Rules, a.ToPorts) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// portCovered reports whether a port, with its L7 rules, is covered by one of
+// the port rules. L7 rules only cover identical L7 rules, and L4 rules only
+// cover L4 rules since adding L7 rules restricts the traffic.
+func portCovered(port portProtocol, l7 map[string]interface{}, rules []portRule) bool {
+	low, high, ok := portRange(port)
+	if !ok {
+		return false
+	}
+	for _, rule := range rules {
+		if !reflect.DeepEqual(rule.Rules, l7) {
+			continue
+		}
+		if len(rule.Ports) == 0 {
+			return true
+		}
+		for _, p := range rule.Ports {
+			pLow, pHigh, ok := portRange(p)
+			if !ok || !protocolCovers(p.Protocol, port.Protocol) {
+				continue
+			}
+			if pLow <= low && high <= pHigh {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// portRange returns the range of ports of a port entry. Port 0 means any
+// port; named ports are only equal to themselves and are not compared.
+func portRange(p portProtocol) (int, int, bool) {
+	if p.Port == "" || p.Port == "0" {
+		return 0, 65535, true
+	}
+	n, err := strconv.Atoi(p.Port)
+	if err != nil {
+		return 0, 0, false
+	}
+	if p.EndPort > n {
+		return n, p.EndPort, true
+	}
+	return n, n, true
+}
+
+// protocolCovers reports whether protocol a includes protocol b.
+func protocolCovers(a, b string) bool {
+	a, b = strings.ToUpper(a), strings.ToUpper(b)
+	return a == "" || a == "ANY" || a == b
+}
+
+// checkBroadRules flags allow rules open to the whole internet.
+func checkBroadRules(rule *ciliumRule, path string) []Finding {
+	var findings []Finding
+	for _, direction := range []string{"ingress", "egress"} {
+		rules := rule.Ingress
+		if direction == "egress" {
+			rules = rule.Egress
+		}
+		for i := range rules {
+			rulePath := fmt.Sprintf("%s.%s[%d]", path, direction, i)
+			for _, entity := range rules[i].entities() {
+				switch strings.ToLower(entity) {
+				case "world", "all":
+					findings = append(findings, Finding{
+						Check:    CheckBroadRule,
+						Severity: SeverityWarning,
+						Path:     rulePath,
+						Message:  fmt.Sprintf("rule allows %s traffic with entity %q", direction, entity),
+					})
+				}
+			}
+			for _, cidr := range rules[i].cidrs() {
+				if cidr == "0.0.0.0/0" || cidr == "::/0" {
+					findings = append(findings, Finding{
+						Check:    CheckBroadRule,
+						Severity: SeverityWarning,
+						Path:     rulePath,
+						Message:  fmt.Sprintf("rule allows %s traffic with CIDR %s", direction, cidr),
+					})
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// checkDNSEgress flags toFQDNs rules without an egress rule sending DNS
+// through Cilium's DNS proxy. Cilium learns the IPs behind FQDNs from the
+// DNS responses it proxies, so without such a rule toFQDNs never matches.
+func checkDNSEgress(rule *ciliumRule, path string) []Finding {
+	fqdnRule := -1
+	for i := range rule.Egress {
+		if len(rule.Egress[i].ToFQDNs) > 0 {
+			fqdnRule = i
+			break
+		}
+	}
+	if fqdnRule < 0 {
+		return nil
+	}
+
+	for i := range rule.Egress {
+		for _, pr := range rule.Egress[i].ToPorts {
+			if _, ok := pr.Rules["dns"]; !ok {
+				continue
+			}
+			for _, p := range pr.Ports {
+				if low, high, ok := portRange(p); ok && low <= 53 && 53 <= high {
+					return nil
+				}
+			}
+		}
+	}
+
+	return []Finding{{
+		Check:    CheckMissingDNSEgress,
+		Severity: SeverityError,
+		Path:     fmt.Sprintf("%s.egress[%d]", path, fqdnRule),
+		Message:  "toFQDNs requires an egress rule allowing port 53 with DNS rules so Cilium can observe the lookups",
+	}}
+}
+
+// checkKubeDNSIsolation flags rules that select the cluster DNS pods and
+// enforce ingress on them without allowing DNS queries, which breaks name
+// resolution for the whole cluster.
+func checkKubeDNSIsolation(policy *ciliumPolicy, rule *ciliumRule, path string) []Finding {
+	if rule.EndpointSelector == nil || !selectsKubeDNS(policy, rule) {
+		return nil
+	}
+	if !ingressEnforced(rule) {
+		return nil
+	}
+
+	dnsPort := peerRule{ToPorts: []portRule{{Ports: []portProtocol{{Port: "53", Protocol: "UDP"}}}}}
+	for i := range rule.IngressDeny {
+		if len(rule.IngressDeny[i].peerKeys()) == 0 && portsCover(&rule.IngressDeny[i], &dnsPort) {
+			return []Finding{{
+				Check:    CheckKubeDNSIsolation,
+				Severity: SeverityError,
+				Path:     fmt.Sprintf("%s.ingressDeny[%d]", path, i),
+				Message:  "rule denies DNS queries to kube-dns from every peer",
+			}}
+		}
+	}
+	for i := range rule.Ingress {
+		if portsCover(&rule.Ingress[i], &dnsPort) {
+			return nil
+		}
+	}
+
+	return []Finding{{
+		Check:    CheckKubeDNSIsolation,
+		Severity: SeverityError,
+		Path:     path + ".endpointSelector",
+		Message:  "policy selects kube-dns and enforces ingress without allowing DNS on port 53",
+	}}
+}
+
+// selectsKubeDNS reports whether a rule's endpoint selector matches the
+// cluster DNS pods.
+func selectsKubeDNS(policy *ciliumPolicy, rule *ciliumRule) bool {
+	if !policy.clusterwide() && policy.Metadata.Namespace != kubeDNSNamespace {
+		return false
+	}
+	selector, namespace, ok := podSelector(rule.EndpointSelector)
+	if !ok || (namespace != "" && namespace != kubeDNSNamespace) {
+		return false
+	}
+	return selector.Matches(kubeDNSLabels)
+}
+
+// ingressEnforced reports whether a rule puts its endpoints in ingress
+// default deny.
+func ingressEnforced(rule *ciliumRule) bool {
+	if rule.EnableDefaultDeny != nil && rule.EnableDefaultDeny.Ingress != nil {
+		return *rule.EnableDefaultDeny.Ingress
+	}
+	return len(rule.Ingress) > 0 || len(rule.IngressDeny) > 0
+}
+
+// checkSelectors flags endpoint selectors that match no pod in the cluster.
+// Empty selectors and selectors on labels pods do not carry are not checked.
+func (l *Linter) checkSelectors(ctx context.Context, policy *ciliumPolicy, rule *ciliumRule, path string) []Finding {
+	namespace := policy.Metadata.Namespace
+	if policy.clusterwide() {
+		namespace = ""
+	}
+
+	var findings []Finding
+	if rule.EndpointSelector != nil {
+		if f, ok := l.checkSelector(ctx, rule.EndpointSelector, namespace, path+".endpointSelector"); ok {
+			findings = append(findings, f)
+		}
+	}
+	for _, direction := range []string{"ingress", "ingressDeny", "egress", "egressDeny"} {
+		var rules []peerRule
+		switch direction {
+		case "ingress":
+			rules = rule.Ingress
+		case "ingressDeny":
+			rules = rule.IngressDeny
+		case "egress":
+			rules = rule.Egress
+		case "egressDeny":
+			rules = rule.EgressDeny
+		}
+		for i := range rules {
+			for j, sel := range rules[i].endpoints() {
+				selPath := fmt.Sprintf("%s.%s[%d].endpoints[%d]", path, direction, i, j)
+				if f, ok := l.checkSelector(ctx, &sel, namespace, selPath); ok {
+					findings = append(findings, f)
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// checkSelector returns a finding if a selector matches no pod in namespace
+// (all namespaces when empty), unless the selector names its own namespace.
+func (l *Linter) checkSelector(ctx context.Context, sel *metav1.LabelSelector, namespace, path string) (Finding, bool) {
+	selector, selNamespace, ok := podSelector(sel)
+	if !ok || (selector.Empty() && selNamespace == "") {
+		return Finding{}, false
+	}
+	if selNamespace != "" {
+		namespace = selNamespace
+	}
+
+	pods := &corev1.PodList{}
+	opts := []client.ListOption{client.MatchingLabelsSelector{Selector: selector}, client.Limit(1)}
+	if namespace != "" {
+		opts = append(opts, client.InNamespace(namespace))
+	}
+	if err := l.reader.List(ctx, pods, opts...); err != nil {
+		l.log.V(1).Info("Failed to list pods for selector check", "selector", selector.String(), "error", err.Error())
+		return Finding{}, false
+	}
+	if len(pods.Items) > 0 {
+		return Finding{}, false
+	}
+
+	where := "the cluster"
+	if namespace != "" {
+		where = "namespace " + namespace
+	}
+	return Finding{
+		Check:    CheckUnmatchedSelector,
+		Severity: SeverityWarning,
+		Path:     path,
+		Message:  fmt.Sprintf("selector %q matches no pod in %s", selector.String(), where),
+	}, true
+}
@@ -0,0 +1,121 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+)
+
+// ErrAuditModeUnsupported is returned for network policies in audit mode
+// when Cilium does not audit them. Cilium has no per-policy audit mode, only
+// the per-endpoint PolicyAuditMode option and the cluster-wide
+// policy-audit-mode option, so a network policy deployed in audit mode would
+// drop traffic unless the latter is enabled.
+var ErrAuditModeUnsupported = errors.New("audit mode is not supported for network policies")
+
+// CiliumConfig is the ConfigMap the Cilium agents are configured with. When
+// its policy-audit-mode option is enabled, with the policyAuditMode value of
+// the Cilium Helm chart, Cilium reports the flows network policies would drop
+// with the AUDIT verdict instead of dropping them.
+var CiliumConfig = types.NamespacedName{Namespace: "kube-system", Name: "cilium-config"}
+
+// ciliumAuditModeKey is the CiliumConfig key of the policy-audit-mode option
+const ciliumAuditModeKey = "policy-audit-mode"
+
+// unauditableKinds are the kinds that cannot be deployed in audit mode
+// unless Cilium audits every network policy
+var unauditableKinds = map[string]bool{
+	"CiliumNetworkPolicy":            true,
+	"CiliumClusterwideNetworkPolicy": true,
+	"NetworkPolicy":                  true,
+}
+
+// tracingHooks are the TracingPolicy spec fields holding hooks with selectors
+var tracingHooks = []string{"kprobes", "tracepoints", "uprobes", "lsmhooks", "usdts"}
+
+// enforcingActions are the Tetragon actions that act on the traced process.
+// In audit mode they are replaced by Post, which only reports the event.
+var enforcingActions = map[string]bool{
+	"sigkill":        true,
+	"signal":         true,
+	"override":       true,
+	"notifyenforcer": true,
+}
+
+// CiliumAuditMode returns true if the Cilium agents run with the cluster-wide
+// policy-audit-mode option, so network policies only report what they would
+// drop. Every network policy is audited then, including enforced ones.
+func (d *Deployer) CiliumAuditMode(ctx context.Context) bool {
+	if d.client == nil {
+		return false
+	}
+	cm := &corev1.ConfigMap{}
+	if err := d.client.Get(ctx, CiliumConfig, cm); err != nil {
+		d.log.V(1).Info("Failed to read the Cilium configuration, assuming policies are enforced",
+			"configMap", CiliumConfig.String(), "error", err.Error())
+		return false
+	}
+	enabled, _ := strconv.ParseBool(cm.Data[ciliumAuditModeKey])
+	return enabled
+}
+
+// validateMode refuses to deploy resources in audit mode that would enforce.
+// Network policies can only be audited while Cilium runs in policy audit mode.
+func (d *Deployer) validateMode(ctx context.Context, resources []*unstructured.Unstructured, policy *policyv1alpha1.ManagedPolicy) error {
+	if !policy.IsAudited() {
+		return nil
+	}
+	for _, resource := range resources {
+		if unauditableKinds[resource.GetKind()] && !d.CiliumAuditMode(ctx) {
+			return fmt.Errorf("%w: %s %s would be enforced; enable the Cilium policy-audit-mode option in %s or deploy it in enforce mode",
+				ErrAuditModeUnsupported, resource.GetKind(), resource.GetName(), CiliumConfig)
+		}
+	}
+	return nil
+}
+
+// applyMode adapts a resource to be deployed in the policy's mode. Enforced
+// resources are deployed as written. Tetragon policies are audited by their
+// actions; network policies are deployed as written and audited by Cilium,
+// and refused by validateMode when Cilium enforces them.
+func applyMode(resource *unstructured.Unstructured, policy *policyv1alpha1.ManagedPolicy) {
+	if !policy.IsAudited() {
+		return
+	}
+
+	switch resource.GetKind() {
+	case "TracingPolicy", "TracingPolicyNamespaced":
+		spec, _ := resource.Object["spec"].(map[string]interface{})
+		for _, field := range tracingHooks {
+			hooks, _ := spec[field].([]interface{})
+			for _, hook := range hooks {
+				hook, _ := hook.(map[string]interface{})
+				selectors, _ := hook["selectors"].([]interface{})
+				for _, selector := range selectors {
+					selector, _ := selector.(map[string]interface{})
+					auditActions(selector)
+				}
+			}
+		}
+	}
+}
+
+// auditActions replaces the enforcing actions of a Tetragon selector with Post
+func auditActions(selector map[string]interface{}) {
+	actions, _ := selector["matchActions"].([]interface{})
+	for i, action := range actions {
+		action, _ := action.(map[string]interface{})
+		name, _ := action["action"].(string)
+		if enforcingActions[strings.ToLower(name)] {
+			actions[i] = map[string]interface{}{"action": "Post"}
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+)
+
+const tracingPolicyContent = `apiVersion: cilium.io/v1alpha1
+kind: TracingPolicy
+metadata:
+  name: block-shell
+spec:
+  kprobes:
+  - call: sys_execve
+    selectors:
+    - matchBinaries:
+      - operator: In
+        values: ["/bin/sh"]
+      matchActions:
+      - action: Sigkill
+      - action: Post
+`
+
+func TestApplyMode(t *testing.T) {
+	d := NewDeployer(nil, logr.Discard())
+
+	tests := []struct {
+		name        string
+		content     string
+		mode        policyv1alpha1.PolicyMode
+		wantActions []string
+	}{
+		{
+			name:        "tetragon policy in audit mode",
+			content:     tracingPolicyContent,
+			mode:        policyv1alpha1.PolicyModeAudit,
+			wantActions: []string{"Post", "Post"},
+		},
+		{
+			name:        "tetragon policy enforced",
+			content:     tracingPolicyContent,
+			mode:        policyv1alpha1.PolicyModeEnforce,
+			wantActions: []string{"Sigkill", "Post"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resources, err := d.parseContent(tt.content)
+			if err != nil || len(resources) != 1 {
+				t.Fatalf("Failed to parse content: %v", err)
+			}
+			resource := resources[0]
+			applyMode(resource, &policyv1alpha1.ManagedPolicy{Spec: policyv1alpha1.ManagedPolicySpec{Mode: tt.mode}})
+
+			kprobes, _, _ := unstructured.NestedSlice(resource.Object, "spec", "kprobes")
+			var actions []string
+			for _, kprobe := range kprobes {
+				selectors, _, _ := unstructured.NestedSlice(kprobe.(map[string]interface{}), "selectors")
+				for _, selector := range selectors {
+					matchActions, _, _ := unstructured.NestedSlice(selector.(map[string]interface{}), "matchActions")
+					for _, action := range matchActions {
+						actions = append(actions, action.(map[string]interface{})["action"].(string))
+					}
+				}
+			}
+			if len(actions) != len(tt.wantActions) {
+				t.Fatalf("Expected actions %v, got %v", tt.wantActions, actions)
+			}
+			for i := range actions {
+				if actions[i] != tt.wantActions[i] {
+					t.Errorf("Expected actions %v, got %v", tt.wantActions, actions)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestValidatePolicy_AuditMode(t *testing.T) {
+	d := NewDeployer(nil, logr.Discard())
+
+	tests := []struct {
+		name       string
+		policyType policyv1alpha1.PolicyType
+		content    string
+		mode       policyv1alpha1.PolicyMode
+		wantErr    bool
+	}{
+		{name: "cilium policy enforced", policyType: policyv1alpha1.PolicyTypeCiliumNetwork, content: driftTestContent, mode: policyv1alpha1.PolicyModeEnforce},
+		{name: "cilium policy in audit mode", policyType: policyv1alpha1.PolicyTypeCiliumNetwork, content: driftTestContent, mode: policyv1alpha1.PolicyModeAudit, wantErr: true},
+		{name: "tetragon policy in audit mode", policyType: policyv1alpha1.PolicyTypeTetragon, content: tracingPolicyContent, mode: policyv1alpha1.PolicyModeAudit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mp := &policyv1alpha1.ManagedPolicy{Spec: policyv1alpha1.ManagedPolicySpec{
+				PolicyType: tt.policyType,
+				Content:    tt.content,
+				Mode:       tt.mode,
+			}}
+			err := d.ValidatePolicy(context.Background(), mp)
+			if errors.Is(err, ErrAuditModeUnsupported) != tt.wantErr {
+				t.Errorf("ValidatePolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDeploy_AuditMode(t *testing.T) {
+	d, c := newTestDeployer()
+
+	// A network policy in audit mode would be enforced, so it is not deployed
+	mp := newDriftTestPolicy()
+	mp.Spec.Mode = policyv1alpha1.PolicyModeAudit
+	if result := d.Deploy(context.Background(), mp); result.Success || !errors.Is(result.Error, ErrAuditModeUnsupported) {
+		t.Fatalf("Expected deploy to fail with ErrAuditModeUnsupported, got: %v", result.Error)
+	}
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(ManagedKinds[0])
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "api"}, live); err == nil {
+		t.Error("Expected the network policy not to be deployed")
+	}
+
+	// A Tetragon policy in audit mode only reports
+	mp.Spec.PolicyType = policyv1alpha1.PolicyTypeTetragon
+	mp.Spec.Content = tracingPolicyContent
+	result := d.Deploy(context.Background(), mp)
+	if !result.Success || len(result.DeployedResources) != 1 {
+		t.Fatalf("Expected deploy to succeed, got: %v", result.Error)
+	}
+	deployed := result.DeployedResources[0]
+	tp := &unstructured.Unstructured{}
+	tp.SetAPIVersion(deployed.APIVersion)
+	tp.SetKind(deployed.Kind)
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: deployed.Namespace, Name: deployed.Name}, tp); err != nil {
+		t.Fatalf("Failed to get deployed resource: %v", err)
+	}
+	kprobes, _, _ := unstructured.NestedSlice(tp.Object, "spec", "kprobes")
+	selectors, _, _ := unstructured.NestedSlice(kprobes[0].(map[string]interface{}), "selectors")
+	actions, _, _ := unstructured.NestedSlice(selectors[0].(map[string]interface{}), "matchActions")
+	if action := actions[0].(map[string]interface{})["action"]; action != "Post" {
+		t.Errorf("Expected the Sigkill action to be deployed as Post, got %v", action)
+	}
+	if drifts, err := d.DetectDrift(context.Background(), mp); err != nil || len(drifts) != 0 {
+		t.Errorf("Expected no drift in audit mode, got %v: %v", drifts, err)
+	}
+}
+
+func TestDeploy_CiliumAuditMode(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(ManagedKinds[0], meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+	ciliumConfig := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: CiliumConfig.Namespace, Name: CiliumConfig.Name},
+		Data:       map[string]string{"policy-audit-mode": "true"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(ciliumConfig).Build()
+	d := NewDeployer(c, logr.Discard())
+	ctx := context.Background()
+
+	// Cilium audits every network policy, so they are deployed as written
+	mp := newDriftTestPolicy()
+	mp.Spec.Mode = policyv1alpha1.PolicyModeAudit
+	if !d.CiliumAuditMode(ctx) {
+		t.Fatal("Expected Cilium policy audit mode to be enabled")
+	}
+	if err := d.ValidatePolicy(ctx, mp); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if result := d.Deploy(ctx, mp); !result.Success {
+		t.Fatalf("Expected deploy to succeed, got: %v", result.Error)
+	}
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(ManagedKinds[0])
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "api"}, live); err != nil {
+		t.Fatalf("Expected the network policy to be deployed, got: %v", err)
+	}
+	if egress, _, _ := unstructured.NestedSlice(live.Object, "spec", "egress"); len(egress) != 1 {
+		t.Errorf("Expected the network policy deployed as written, got egress %v", egress)
+	}
+
+	// Once Cilium enforces again, the policy cannot be audited
+	ciliumConfig.Data["policy-audit-mode"] = "false"
+	if err := c.Update(ctx, ciliumConfig); err != nil {
+		t.Fatalf("Failed to update the Cilium configuration: %v", err)
+	}
+	if err := d.ValidatePolicy(ctx, mp); !errors.Is(err, ErrAuditModeUnsupported) {
+		t.Errorf("Expected ErrAuditModeUnsupported, got: %v", err)
+	}
+}
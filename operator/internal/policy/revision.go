@@ -0,0 +1,229 @@
+package policy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+)
+
+// RevisionOfLabel is set on revision ConfigMaps to the name of their ManagedPolicy
+const RevisionOfLabel = "policyhub.io/revision-of"
+
+// RevisionLabel is set on revision ConfigMaps to the policy version they hold
+const RevisionLabel = "policyhub.io/revision"
+
+// DefaultRevisionHistoryLimit is the number of revisions kept when a policy does not set one
+const DefaultRevisionHistoryLimit = 10
+
+// RevisionOutcome is how deploying a revision ended
+type RevisionOutcome string
+
+const (
+	RevisionDeployed          RevisionOutcome = "Deployed"
+	RevisionPartiallyDeployed RevisionOutcome = "PartiallyDeployed"
+	RevisionFailed            RevisionOutcome = "Failed"
+	RevisionRolledBack        RevisionOutcome = "RolledBack"
+)
+
+// Revision is a version of a policy's content as it was deployed
+type Revision struct {
+	Version     int
+	ContentHash string
+	Content     string
+	DeployedAt  time.Time
+	Outcome     RevisionOutcome
+	// Spec is the policy spec the revision was deployed with, without its
+	// content, so its signature can be verified again before a rollback. It is
+	// nil for revisions recorded before specs were.
+	Spec *policyv1alpha1.ManagedPolicySpec
+}
+
+// ContentHash returns the SHA-256 of policy content, hex encoded
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// RevisionName returns the name of the ConfigMap holding a policy version
+func RevisionName(policy *policyv1alpha1.ManagedPolicy, version int) string {
+	return fmt.Sprintf("%s-rev-%d", policy.Name, version)
+}
+
+// RecordRevision stores the policy's current version, content and spec with
+// the outcome of deploying it, then deletes the oldest revisions over the
+// policy's revision history limit
+func (d *Deployer) RecordRevision(ctx context.Context, policy *policyv1alpha1.ManagedPolicy, outcome RevisionOutcome) error {
+	spec := policy.Spec.DeepCopy()
+	spec.Content = ""
+	return d.recordRevision(ctx, policy, Revision{
+		Version:     policy.Spec.Version,
+		ContentHash: ContentHash(policy.Spec.Content),
+		Content:     policy.Spec.Content,
+		DeployedAt:  time.Now(),
+		Outcome:     outcome,
+		Spec:        spec,
+	})
+}
+
+// SetRevisionOutcome changes the outcome of a recorded revision, if there is one
+func (d *Deployer) SetRevisionOutcome(ctx context.Context, policy *policyv1alpha1.ManagedPolicy, version int, outcome RevisionOutcome) error {
+	rev, err := d.GetRevision(ctx, policy, version)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	rev.Outcome = outcome
+	return d.recordRevision(ctx, policy, *rev)
+}
+
+func (d *Deployer) recordRevision(ctx context.Context, policy *policyv1alpha1.ManagedPolicy, rev Revision) error {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      RevisionName(policy, rev.Version),
+			Namespace: policy.Namespace,
+			Labels: map[string]string{
+				ManagedByLabel:           "policy-hub-operator",
+				RevisionOfLabel:          policy.Name,
+				RevisionLabel:            strconv.Itoa(rev.Version),
+				"policyhub.io/policy-id": policy.Spec.PolicyID,
+			},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: policyv1alpha1.GroupVersion.String(),
+				Kind:       "ManagedPolicy",
+				Name:       policy.Name,
+				UID:        policy.UID,
+			}},
+		},
+		Data: map[string]string{
+			"version":     strconv.Itoa(rev.Version),
+			"contentHash": rev.ContentHash,
+			"content":     rev.Content,
+			"deployedAt":  rev.DeployedAt.UTC().Format(time.RFC3339),
+			"outcome":     string(rev.Outcome),
+		},
+	}
+	if rev.Spec != nil {
+		spec, err := json.Marshal(rev.Spec)
+		if err != nil {
+			return fmt.Errorf("failed to encode revision %d spec: %w", rev.Version, err)
+		}
+		cm.Data["spec"] = string(spec)
+	}
+
+	existing := &corev1.ConfigMap{}
+	err := d.client.Get(ctx, client.ObjectKeyFromObject(cm), existing)
+	switch {
+	case errors.IsNotFound(err):
+		if err := d.client.Create(ctx, cm); err != nil {
+			return fmt.Errorf("failed to create revision %d: %w", rev.Version, err)
+		}
+	case err != nil:
+		return fmt.Errorf("failed to get revision %d: %w", rev.Version, err)
+	default:
+		existing.Labels = cm.Labels
+		existing.OwnerReferences = cm.OwnerReferences
+		existing.Data = cm.Data
+		if err := d.client.Update(ctx, existing); err != nil {
+			return fmt.Errorf("failed to update revision %d: %w", rev.Version, err)
+		}
+	}
+
+	return d.pruneRevisions(ctx, policy)
+}
+
+// GetRevision returns a recorded revision of a policy. The error is NotFound
+// if the version was not recorded or was pruned.
+func (d *Deployer) GetRevision(ctx context.Context, policy *policyv1alpha1.ManagedPolicy, version int) (*Revision, error) {
+	cm := &corev1.ConfigMap{}
+	if err := d.client.Get(ctx, types.NamespacedName{Name: RevisionName(policy, version), Namespace: policy.Namespace}, cm); err != nil {
+		return nil, err
+	}
+	return revisionFromConfigMap(cm)
+}
+
+// ListRevisions returns the recorded revisions of a policy, newest first
+func (d *Deployer) ListRevisions(ctx context.Context, policy *policyv1alpha1.ManagedPolicy) ([]Revision, error) {
+	list := &corev1.ConfigMapList{}
+	if err := d.client.List(ctx, list, client.InNamespace(policy.Namespace), client.MatchingLabels{RevisionOfLabel: policy.Name}); err != nil {
+		return nil, fmt.Errorf("failed to list revisions: %w", err)
+	}
+
+	revisions := make([]Revision, 0, len(list.Items))
+	for i := range list.Items {
+		rev, err := revisionFromConfigMap(&list.Items[i])
+		if err != nil {
+			d.log.V(1).Info("Skipping invalid revision", "configMap", list.Items[i].Name, "error", err.Error())
+			continue
+		}
+		revisions = append(revisions, *rev)
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Version > revisions[j].Version
+	})
+	return revisions, nil
+}
+
+// pruneRevisions deletes the oldest revisions over the revision history
+// limit, keeping the deployed version
+func (d *Deployer) pruneRevisions(ctx context.Context, policy *policyv1alpha1.ManagedPolicy) error {
+	limit := DefaultRevisionHistoryLimit
+	if policy.Spec.RevisionHistoryLimit != nil {
+		limit = int(*policy.Spec.RevisionHistoryLimit)
+	}
+
+	revisions, err := d.ListRevisions(ctx, policy)
+	if err != nil {
+		return err
+	}
+	kept := 0
+	for _, rev := range revisions {
+		if kept < limit || rev.Version == policy.Status.DeployedVersion {
+			kept++
+			continue
+		}
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: RevisionName(policy, rev.Version), Namespace: policy.Namespace}}
+		if err := d.client.Delete(ctx, cm); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete revision %d: %w", rev.Version, err)
+		}
+		d.log.V(1).Info("Pruned revision", "policy", policy.Name, "version", rev.Version)
+	}
+	return nil
+}
+
+func revisionFromConfigMap(cm *corev1.ConfigMap) (*Revision, error) {
+	version, err := strconv.Atoi(cm.Data["version"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid revision version %q", cm.Data["version"])
+	}
+	rev := &Revision{
+		Version:     version,
+		ContentHash: cm.Data["contentHash"],
+		Content:     cm.Data["content"],
+		Outcome:     RevisionOutcome(cm.Data["outcome"]),
+	}
+	if deployedAt, err := time.Parse(time.RFC3339, cm.Data["deployedAt"]); err == nil {
+		rev.DeployedAt = deployedAt
+	}
+	if spec, ok := cm.Data["spec"]; ok {
+		rev.Spec = &policyv1alpha1.ManagedPolicySpec{}
+		if err := json.Unmarshal([]byte(spec), rev.Spec); err != nil {
+			return nil, fmt.Errorf("invalid revision spec: %w", err)
+		}
+	}
+	return rev, nil
+}
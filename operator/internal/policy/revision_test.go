@@ -0,0 +1,100 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRevisionHistory(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+	d := NewDeployer(c, logr.Discard())
+	ctx := context.Background()
+
+	limit := int32(2)
+	mp := newDriftTestPolicy()
+	mp.Spec.RevisionHistoryLimit = &limit
+
+	for version := 1; version <= 3; version++ {
+		mp.Spec.Version = version
+		mp.Spec.Content = fmt.Sprintf("%s# version %d\n", driftTestContent, version)
+		mp.Status.DeployedVersion = version
+		if err := d.RecordRevision(ctx, mp, RevisionDeployed); err != nil {
+			t.Fatalf("Failed to record revision %d: %v", version, err)
+		}
+	}
+
+	revisions, err := d.ListRevisions(ctx, mp)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Version != 3 || revisions[1].Version != 2 {
+		t.Fatalf("Expected revisions 3 and 2, got %+v", revisions)
+	}
+	if _, err := d.GetRevision(ctx, mp, 1); !errors.IsNotFound(err) {
+		t.Errorf("Expected revision 1 to be pruned, got: %v", err)
+	}
+
+	rev, err := d.GetRevision(ctx, mp, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if rev.Content != driftTestContent+"# version 2\n" || rev.ContentHash != ContentHash(rev.Content) {
+		t.Errorf("Expected the content of version 2 and its hash, got %+v", rev)
+	}
+	if rev.Outcome != RevisionDeployed || rev.DeployedAt.IsZero() {
+		t.Errorf("Expected a deployed revision with a deploy time, got %+v", rev)
+	}
+	if rev.Spec == nil || rev.Spec.Version != 2 || rev.Spec.PolicyID != mp.Spec.PolicyID || rev.Spec.Content != "" {
+		t.Errorf("Expected the spec of version 2 without its content, got %+v", rev.Spec)
+	}
+
+	if err := d.SetRevisionOutcome(ctx, mp, 3, RevisionRolledBack); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if rev, _ := d.GetRevision(ctx, mp, 3); rev == nil || rev.Outcome != RevisionRolledBack || rev.Spec == nil {
+		t.Errorf("Expected revision 3 to be rolled back with its spec, got %+v", rev)
+	}
+	if err := d.SetRevisionOutcome(ctx, mp, 1, RevisionRolledBack); err != nil {
+		t.Errorf("Expected a missing revision to be ignored, got: %v", err)
+	}
+}
+
+func TestPruneRevisions_KeepsDeployedVersion(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+	d := NewDeployer(c, logr.Discard())
+	ctx := context.Background()
+
+	limit := int32(1)
+	mp := newDriftTestPolicy()
+	mp.Spec.RevisionHistoryLimit = &limit
+	mp.Status.DeployedVersion = 1
+
+	for version := 1; version <= 3; version++ {
+		mp.Spec.Version = version
+		outcome := RevisionFailed
+		if version == 1 {
+			outcome = RevisionDeployed
+		}
+		if err := d.RecordRevision(ctx, mp, outcome); err != nil {
+			t.Fatalf("Failed to record revision %d: %v", version, err)
+		}
+	}
+
+	revisions, err := d.ListRevisions(ctx, mp)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	var versions []int
+	for _, rev := range revisions {
+		versions = append(versions, rev.Version)
+	}
+	if len(versions) != 2 || versions[0] != 3 || versions[1] != 1 {
+		t.Errorf("Expected the newest and the deployed revision, got %v", versions)
+	}
+}
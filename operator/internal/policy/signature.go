@@ -0,0 +1,158 @@
+package policy
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+)
+
+// Errors returned by VerifySignature for a policy that must not be deployed
+var (
+	ErrUnsigned          = errors.New("policy is not signed")
+	ErrUnknownKey        = errors.New("policy is signed with an untrusted key")
+	ErrSignatureMismatch = errors.New("policy signature does not match its content")
+)
+
+// signaturePayloadVersion prefixes the signed payload so its format can change
+const signaturePayloadVersion = "policyhub.io/signature/v2"
+
+// signedSpec is the canonical encoding of every field of a policy that
+// affects what is deployed and where. Its field order is fixed and its field
+// names are those of the SaaS API.
+type signedSpec struct {
+	PolicyID          string                `json:"policyId"`
+	Version           int                   `json:"version"`
+	Type              string                `json:"type"`
+	ContentHash       string                `json:"contentHash"`
+	TargetNamespaces  []string              `json:"targetNamespaces"`
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector"`
+	Mode              string                `json:"mode"`
+	DriftMode         string                `json:"driftMode"`
+	Rollout           *signedRollout        `json:"rollout"`
+}
+
+type signedRollout struct {
+	CanaryNamespaces     []string `json:"canaryNamespaces"`
+	BakeTimeSeconds      int64    `json:"bakeTimeSeconds"`
+	BlockedFlowThreshold int      `json:"blockedFlowThreshold"`
+}
+
+// SignedPayload returns the bytes the SaaS platform signs for a policy: the
+// payload version, then a JSON object of the policy ID, version, type,
+// content hash, target namespaces, namespace selector, mode, drift mode and
+// rollout strategy. Namespace lists are sorted, and unset modes and rollout
+// settings are replaced by their defaults, so the SaaS platform and the
+// operator encode the same policy the same way. A signature therefore cannot
+// be replayed as another policy or version, nor with other targets or modes.
+func SignedPayload(spec *policyv1alpha1.ManagedPolicySpec) []byte {
+	signed := signedSpec{
+		PolicyID:          spec.PolicyID,
+		Version:           spec.Version,
+		Type:              string(spec.PolicyType),
+		ContentHash:       ContentHash(spec.Content),
+		TargetNamespaces:  sortedNamespaces(spec.TargetNamespaces),
+		NamespaceSelector: spec.NamespaceSelector,
+		Mode:              string(policyv1alpha1.PolicyModeEnforce),
+		DriftMode:         string(policyv1alpha1.DriftModeRevert),
+	}
+	if spec.Mode == policyv1alpha1.PolicyModeAudit {
+		signed.Mode = string(policyv1alpha1.PolicyModeAudit)
+	}
+	if spec.DriftMode == policyv1alpha1.DriftModeReport {
+		signed.DriftMode = string(policyv1alpha1.DriftModeReport)
+	}
+	if rollout := spec.Rollout; rollout != nil {
+		signed.Rollout = &signedRollout{
+			CanaryNamespaces:     sortedNamespaces(rollout.CanaryNamespaces),
+			BakeTimeSeconds:      int64(rollout.BakeTime.Duration / time.Second),
+			BlockedFlowThreshold: rollout.BlockedFlowThreshold,
+		}
+		if signed.Rollout.BakeTimeSeconds <= 0 {
+			signed.Rollout.BakeTimeSeconds = int64(policyv1alpha1.DefaultRolloutBakeTime / time.Second)
+		}
+		if signed.Rollout.BlockedFlowThreshold <= 0 {
+			signed.Rollout.BlockedFlowThreshold = policyv1alpha1.DefaultRolloutBlockedFlowThreshold
+		}
+	}
+
+	// The struct holds only strings, numbers, slices and string maps, which
+	// always encode
+	encoded, _ := json.Marshal(signed)
+	return fmt.Appendf(nil, "%s\n%s\n", signaturePayloadVersion, encoded)
+}
+
+func sortedNamespaces(namespaces []string) []string {
+	sorted := append([]string{}, namespaces...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+// ParsePublicKey parses an Ed25519 public key, either PEM-encoded (PKIX) or
+// the base64-encoded raw 32-byte key
+func ParsePublicKey(key string) (ed25519.PublicKey, error) {
+	key = strings.TrimSpace(key)
+	if block, _ := pem.Decode([]byte(key)); block != nil {
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PEM public key: %w", err)
+		}
+		publicKey, ok := parsed.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public key is %T, not Ed25519", parsed)
+		}
+		return publicKey, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("public key is neither PEM nor base64: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key is %d bytes, want %d", len(raw), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// VerifySignature verifies the signature of a policy with the trusted keys.
+// A policy without a key ID may be signed with any of them. It returns the
+// ID of the key that verified the signature.
+func VerifySignature(policy *policyv1alpha1.ManagedPolicy, keys []policyv1alpha1.TrustedKey) (string, error) {
+	if policy.Spec.Signature == "" {
+		return "", ErrUnsigned
+	}
+	signature, err := base64.StdEncoding.DecodeString(policy.Spec.Signature)
+	if err != nil {
+		return "", fmt.Errorf("%w: signature is not base64", ErrSignatureMismatch)
+	}
+
+	payload := SignedPayload(&policy.Spec)
+	found := false
+	for _, key := range keys {
+		if policy.Spec.SignatureKeyID != "" && key.ID != policy.Spec.SignatureKeyID {
+			continue
+		}
+		found = true
+		publicKey, err := ParsePublicKey(key.PublicKey)
+		if err != nil {
+			return "", fmt.Errorf("trusted key %s: %w", key.ID, err)
+		}
+		if ed25519.Verify(publicKey, payload, signature) {
+			return key.ID, nil
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKey, policy.Spec.SignatureKeyID)
+	}
+	return "", ErrSignatureMismatch
+}
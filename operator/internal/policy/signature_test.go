@@ -0,0 +1,190 @@
+package policy
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+)
+
+func TestParsePublicKey(t *testing.T) {
+	publicKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)).Public().(ed25519.PublicKey)
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "raw base64", key: base64.StdEncoding.EncodeToString(publicKey)},
+		{name: "PEM", key: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))},
+		{name: "wrong length", key: base64.StdEncoding.EncodeToString(publicKey[:16]), wantErr: true},
+		{name: "not base64", key: "not a key", wantErr: true},
+		{name: "invalid PEM", key: "-----BEGIN PUBLIC KEY-----\nAAAA\n-----END PUBLIC KEY-----\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePublicKey(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePublicKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(publicKey) {
+				t.Errorf("ParsePublicKey() = %x, want %x", got, publicKey)
+			}
+		})
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	signingKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	otherKey := ed25519.NewKeyFromSeed([]byte("0123456789abcdef0123456789abcdef"))
+	keys := []policyv1alpha1.TrustedKey{
+		{ID: "other", PublicKey: base64.StdEncoding.EncodeToString(otherKey.Public().(ed25519.PublicKey))},
+		{ID: "saas", PublicKey: base64.StdEncoding.EncodeToString(signingKey.Public().(ed25519.PublicKey))},
+	}
+
+	signed := func(key ed25519.PrivateKey, keyID string, mutate func(*policyv1alpha1.ManagedPolicy)) *policyv1alpha1.ManagedPolicy {
+		mp := newDriftTestPolicy()
+		mp.Spec.Version = 2
+		mp.Spec.TargetNamespaces = []string{"payments", "default"}
+		mp.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}}
+		mp.Spec.Rollout = &policyv1alpha1.RolloutStrategy{CanaryNamespaces: []string{"default"}}
+		payload := SignedPayload(&mp.Spec)
+		mp.Spec.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload))
+		mp.Spec.SignatureKeyID = keyID
+		if mutate != nil {
+			mutate(mp)
+		}
+		return mp
+	}
+
+	tests := []struct {
+		name      string
+		policy    *policyv1alpha1.ManagedPolicy
+		wantKeyID string
+		wantErr   error
+	}{
+		{
+			name:      "signed with the key ID",
+			policy:    signed(signingKey, "saas", nil),
+			wantKeyID: "saas",
+		},
+		{
+			name:      "signed without a key ID",
+			policy:    signed(signingKey, "", nil),
+			wantKeyID: "saas",
+		},
+		{
+			name:    "unsigned",
+			policy:  signed(signingKey, "saas", func(mp *policyv1alpha1.ManagedPolicy) { mp.Spec.Signature = "" }),
+			wantErr: ErrUnsigned,
+		},
+		{
+			name:    "untrusted key ID",
+			policy:  signed(signingKey, "unknown", nil),
+			wantErr: ErrUnknownKey,
+		},
+		{
+			name:    "signed with another key",
+			policy:  signed(otherKey, "saas", nil),
+			wantErr: ErrSignatureMismatch,
+		},
+		{
+			name:    "content changed",
+			policy:  signed(signingKey, "saas", func(mp *policyv1alpha1.ManagedPolicy) { mp.Spec.Content += "# tampered\n" }),
+			wantErr: ErrSignatureMismatch,
+		},
+		{
+			name:    "signature replayed for another version",
+			policy:  signed(signingKey, "saas", func(mp *policyv1alpha1.ManagedPolicy) { mp.Spec.Version = 1 }),
+			wantErr: ErrSignatureMismatch,
+		},
+		{
+			name: "defaults filled in after signing",
+			policy: signed(signingKey, "saas", func(mp *policyv1alpha1.ManagedPolicy) {
+				mp.Spec.Mode = policyv1alpha1.PolicyModeEnforce
+				mp.Spec.DriftMode = policyv1alpha1.DriftModeRevert
+				mp.Spec.Rollout.BakeTime.Duration = policyv1alpha1.DefaultRolloutBakeTime
+				mp.Spec.Rollout.BlockedFlowThreshold = policyv1alpha1.DefaultRolloutBlockedFlowThreshold
+			}),
+			wantKeyID: "saas",
+		},
+		{
+			name: "target namespaces reordered",
+			policy: signed(signingKey, "saas", func(mp *policyv1alpha1.ManagedPolicy) {
+				mp.Spec.TargetNamespaces = []string{"default", "payments"}
+			}),
+			wantKeyID: "saas",
+		},
+		{
+			name: "target namespace added",
+			policy: signed(signingKey, "saas", func(mp *policyv1alpha1.ManagedPolicy) {
+				mp.Spec.TargetNamespaces = append(mp.Spec.TargetNamespaces, "kube-system")
+			}),
+			wantErr: ErrSignatureMismatch,
+		},
+		{
+			name: "namespace selector changed",
+			policy: signed(signingKey, "saas", func(mp *policyv1alpha1.ManagedPolicy) {
+				mp.Spec.NamespaceSelector = &metav1.LabelSelector{}
+			}),
+			wantErr: ErrSignatureMismatch,
+		},
+		{
+			name: "policy type changed",
+			policy: signed(signingKey, "saas", func(mp *policyv1alpha1.ManagedPolicy) {
+				mp.Spec.PolicyType = policyv1alpha1.PolicyTypeCiliumClusterwide
+			}),
+			wantErr: ErrSignatureMismatch,
+		},
+		{
+			name:    "mode changed",
+			policy:  signed(signingKey, "saas", func(mp *policyv1alpha1.ManagedPolicy) { mp.Spec.Mode = policyv1alpha1.PolicyModeAudit }),
+			wantErr: ErrSignatureMismatch,
+		},
+		{
+			name:    "drift mode changed",
+			policy:  signed(signingKey, "saas", func(mp *policyv1alpha1.ManagedPolicy) { mp.Spec.DriftMode = policyv1alpha1.DriftModeReport }),
+			wantErr: ErrSignatureMismatch,
+		},
+		{
+			name:    "rollout removed",
+			policy:  signed(signingKey, "saas", func(mp *policyv1alpha1.ManagedPolicy) { mp.Spec.Rollout = nil }),
+			wantErr: ErrSignatureMismatch,
+		},
+		{
+			name: "rollout threshold raised",
+			policy: signed(signingKey, "saas", func(mp *policyv1alpha1.ManagedPolicy) {
+				mp.Spec.Rollout.BlockedFlowThreshold = 1000
+			}),
+			wantErr: ErrSignatureMismatch,
+		},
+		{
+			name:    "signature not base64",
+			policy:  signed(signingKey, "saas", func(mp *policyv1alpha1.ManagedPolicy) { mp.Spec.Signature = "%%%" }),
+			wantErr: ErrSignatureMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyID, err := VerifySignature(tt.policy, keys)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifySignature() error = %v, want %v", err, tt.wantErr)
+			}
+			if keyID != tt.wantKeyID {
+				t.Errorf("VerifySignature() key ID = %q, want %q", keyID, tt.wantKeyID)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+)
+
+// Snapshot reads the live state of deployed resources and returns it as
+// policy content that Restore can re-apply. Server-set metadata and status
+// are dropped; resources that no longer exist are skipped.
+func (d *Deployer) Snapshot(ctx context.Context, resources []policyv1alpha1.DeployedResource) (string, error) {
+	var docs []string
+	for _, res := range resources {
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(schema.FromAPIVersionAndKind(res.APIVersion, res.Kind))
+		err := d.client.Get(ctx, types.NamespacedName{Name: res.Name, Namespace: res.Namespace}, live)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to get %s/%s: %w", res.Kind, res.Name, err)
+		}
+
+		snapshot := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		for key, value := range live.Object {
+			if key != "metadata" && key != "status" {
+				snapshot.Object[key] = value
+			}
+		}
+		snapshot.SetName(live.GetName())
+		snapshot.SetNamespace(live.GetNamespace())
+		snapshot.SetLabels(live.GetLabels())
+		snapshot.SetAnnotations(live.GetAnnotations())
+
+		doc, err := yaml.Marshal(snapshot.Object)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal %s/%s: %w", res.Kind, res.Name, err)
+		}
+		docs = append(docs, string(doc))
+	}
+	return strings.Join(docs, "---\n"), nil
+}
+
+// Restore applies content taken by Snapshot and deletes the resources in
+// current that the content does not contain
+func (d *Deployer) Restore(ctx context.Context, content string, current []policyv1alpha1.DeployedResource) DeployResult {
+	resources, err := d.parseContent(content)
+	if err != nil {
+		return DeployResult{Error: fmt.Errorf("failed to parse snapshot: %w", err)}
+	}
+
+	var deployedResources []policyv1alpha1.DeployedResource
+	for _, resource := range resources {
+		deployed, err := d.applyResource(ctx, resource)
+		if err != nil {
+			return DeployResult{
+				Error: fmt.Errorf("failed to restore resource %s/%s: %w", resource.GetKind(), resource.GetName(), err),
+			}
+		}
+		deployedResources = append(deployedResources, *deployed)
+	}
+
+	pruned, err := d.Prune(ctx, current, deployedResources)
+	if err != nil {
+		return DeployResult{Error: err}
+	}
+
+	d.log.Info("Restored policy resources",
+		"restored", len(deployedResources),
+		"deleted", pruned)
+
+	return DeployResult{
+		Success:           true,
+		DeployedResources: deployedResources,
+	}
+}
+
+// Prune deletes the resources in current that are not in deployed and returns
+// how many were deleted
+func (d *Deployer) Prune(ctx context.Context, current, deployed []policyv1alpha1.DeployedResource) (int, error) {
+	keep := make(map[string]bool, len(deployed))
+	for _, res := range deployed {
+		keep[resourceKey(res)] = true
+	}
+
+	var stale []policyv1alpha1.DeployedResource
+	for _, res := range current {
+		if !keep[resourceKey(res)] {
+			stale = append(stale, res)
+		}
+	}
+	_, err := d.DeleteResources(ctx, stale)
+	return len(stale), err
+}
+
+// resourceKey identifies a deployed resource regardless of its UID
+func resourceKey(res policyv1alpha1.DeployedResource) string {
+	return res.APIVersion + "/" + res.Kind + "/" + res.Namespace + "/" + res.Name
+}
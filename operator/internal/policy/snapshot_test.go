@@ -0,0 +1,92 @@
+package policy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestDeployToNamespaces(t *testing.T) {
+	d, _ := newTestDeployer()
+	mp := newDriftTestPolicy()
+	mp.Spec.TargetNamespaces = []string{"default", "backend", "frontend"}
+
+	result := d.DeployToNamespaces(context.Background(), mp, []string{"backend"})
+	if !result.Success {
+		t.Fatalf("Expected deploy to succeed, got: %v", result.Error)
+	}
+	if len(result.DeployedResources) != 1 || result.DeployedResources[0].Namespace != "backend" {
+		t.Errorf("Expected a single resource in backend, got %+v", result.DeployedResources)
+	}
+
+	result = d.Deploy(context.Background(), mp)
+	if !result.Success {
+		t.Fatalf("Expected deploy to succeed, got: %v", result.Error)
+	}
+	var namespaces []string
+	for _, res := range result.DeployedResources {
+		namespaces = append(namespaces, res.Namespace)
+	}
+	if got := strings.Join(namespaces, ","); got != "default,backend,frontend" {
+		t.Errorf("Expected a resource per target namespace, got %s", got)
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	d, c := newTestDeployer()
+	mp := newDriftTestPolicy()
+
+	v1 := d.Deploy(context.Background(), mp)
+	if !v1.Success {
+		t.Fatalf("Expected deploy to succeed, got: %v", v1.Error)
+	}
+	snapshot, err := d.Snapshot(context.Background(), v1.DeployedResources)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	for _, field := range []string{"resourceVersion", "uid", "creationTimestamp", "managedFields"} {
+		if strings.Contains(snapshot, field) {
+			t.Errorf("Expected %s to be dropped from the snapshot, got:\n%s", field, snapshot)
+		}
+	}
+
+	// Version 2 changes the selector and adds a resource
+	mp.Spec.Version = 2
+	mp.Spec.Content = strings.Replace(driftTestContent, "app: api", "app: web", 1) + `---
+apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: extra
+spec:
+  endpointSelector: {}
+`
+	v2 := d.Deploy(context.Background(), mp)
+	if !v2.Success || len(v2.DeployedResources) != 2 {
+		t.Fatalf("Expected version 2 to deploy two resources, got %+v: %v", v2.DeployedResources, v2.Error)
+	}
+
+	restored := d.Restore(context.Background(), snapshot, v2.DeployedResources)
+	if !restored.Success {
+		t.Fatalf("Expected restore to succeed, got: %v", restored.Error)
+	}
+	if len(restored.DeployedResources) != 1 {
+		t.Errorf("Expected one restored resource, got %+v", restored.DeployedResources)
+	}
+
+	live := getLive(t, c)
+	if got, _, _ := unstructured.NestedString(live.Object, "spec", "endpointSelector", "matchLabels", "app"); got != "api" {
+		t.Errorf("Expected version 1 selector, got app=%q", got)
+	}
+	if got := live.GetAnnotations()["policyhub.io/version"]; got != "1" {
+		t.Errorf("Expected version annotation 1, got %q", got)
+	}
+	extra := &unstructured.Unstructured{}
+	extra.SetGroupVersionKind(ManagedKinds[0])
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "extra"}, extra); !errors.IsNotFound(err) {
+		t.Errorf("Expected the resource added in version 2 to be deleted, got: %v", err)
+	}
+}
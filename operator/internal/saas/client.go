@@ -19,6 +19,7 @@ type Client struct {
 	clusterID  string
 	nodeName   string // Node name for multi-node simulation aggregation
 	httpClient *http.Client
+	outbox     *Outbox // Keeps submissions that failed until they are delivered
 	log        logr.Logger
 }
 
@@ -73,6 +74,13 @@ func (c *Client) GetNodeName() string {
 	return c.nodeName
 }
 
+// SetOutbox makes submissions that fail with a retryable error queue in the
+// outbox, which the client then sends
+func (c *Client) SetOutbox(outbox *Outbox) {
+	c.outbox = outbox
+	outbox.setClient(c)
+}
+
 // BootstrapRequest is the request body for cluster bootstrap
 type BootstrapRequest struct {
 	ClusterName       string `json:"clusterName"`
@@ -244,16 +252,30 @@ func (c *Client) Heartbeat(ctx context.Context, req HeartbeatRequest) (*Heartbea
 
 // Policy represents a policy from the SaaS platform
 type Policy struct {
-	ID               string   `json:"id"`
-	Name             string   `json:"name"`
-	Description      string   `json:"description,omitempty"`
-	Type             string   `json:"type"`
-	Status           string   `json:"status"`
-	Content          string   `json:"content"`
-	TargetNamespaces []string `json:"targetNamespaces,omitempty"`
-	Version          int      `json:"version"`
-	LastUpdated      string   `json:"lastUpdated"`
-	Action           string   `json:"action"` // "DEPLOY" or "UNDEPLOY"
+	ID                string            `json:"id"`
+	Name              string            `json:"name"`
+	Description       string            `json:"description,omitempty"`
+	Type              string            `json:"type"`
+	Status            string            `json:"status"`
+	Content           string            `json:"content"`
+	ContentHash       string            `json:"contentHash,omitempty"` // SHA-256 of Content, hex encoded
+	TargetNamespaces  []string          `json:"targetNamespaces,omitempty"`
+	Version           int               `json:"version"`
+	LastUpdated       string            `json:"lastUpdated"`
+	Action            string            `json:"action"`              // "DEPLOY" or "UNDEPLOY"
+	DriftMode         string            `json:"driftMode,omitempty"` // "Revert" or "Report"
+	Rollout           *PolicyRollout    `json:"rollout,omitempty"`
+	Mode              string            `json:"mode,omitempty"`              // "audit" or "enforce"
+	NamespaceSelector map[string]string `json:"namespaceSelector,omitempty"` // Labels of namespaces deployed to in addition to TargetNamespaces
+	Signature         string            `json:"signature,omitempty"`         // Ed25519 signature of the policy, base64 encoded
+	SignatureKeyID    string            `json:"signatureKeyId,omitempty"`    // ID of the key the policy was signed with
+}
+
+// PolicyRollout is the staged rollout strategy of a policy
+type PolicyRollout struct {
+	CanaryNamespaces     []string `json:"canaryNamespaces,omitempty"`
+	BakeTimeSeconds      int      `json:"bakeTimeSeconds,omitempty"`
+	BlockedFlowThreshold int      `json:"blockedFlowThreshold,omitempty"` // Blocked flows per minute
 }
 
 // FetchPoliciesResponse is the response from fetching policies
@@ -262,11 +284,42 @@ type FetchPoliciesResponse struct {
 	Policies []Policy `json:"policies"`
 	Count    int      `json:"count"`
 	Error    string   `json:"error,omitempty"`
+
+	// ETag identifies this list of policies for FetchPoliciesIfChanged
+	ETag string `json:"-"`
+	// NotModified is true if the policies did not change since the ETag
+	// given to FetchPoliciesIfChanged; Policies is then empty
+	NotModified bool `json:"-"`
 }
 
 // FetchPolicies retrieves all policies for this cluster
 func (c *Client) FetchPolicies(ctx context.Context) (*FetchPoliciesResponse, error) {
-	resp, err := c.doRequest(ctx, "GET", "/api/operator/policies", nil)
+	return c.FetchPoliciesIfChanged(ctx, "")
+}
+
+// FetchPoliciesIfChanged retrieves all policies for this cluster unless they
+// did not change since the response with the given ETag
+func (c *Client) FetchPoliciesIfChanged(ctx context.Context, etag string) (*FetchPoliciesResponse, error) {
+	req, err := c.newRequest(ctx, "GET", "/api/operator/policies", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch policies: %w", err)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	httpResp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch policies: request failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	if etag != "" && httpResp.StatusCode == http.StatusNotModified {
+		c.log.V(1).Info("Policies not modified since last fetch")
+		return &FetchPoliciesResponse{Success: true, ETag: etag, NotModified: true}, nil
+	}
+
+	resp, err := readResponse(httpResp)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch policies: %w", err)
 	}
@@ -279,6 +332,7 @@ func (c *Client) FetchPolicies(ctx context.Context) (*FetchPoliciesResponse, err
 	if !result.Success {
 		return nil, fmt.Errorf("fetch policies failed: %s", result.Error)
 	}
+	result.ETag = httpResp.Header.Get("ETag")
 
 	c.log.V(1).Info("Fetched policies from SaaS platform", "count", result.Count)
 
@@ -287,10 +341,51 @@ func (c *Client) FetchPolicies(ctx context.Context) (*FetchPoliciesResponse, err
 
 // UpdatePolicyStatusRequest is the request body for updating policy status
 type UpdatePolicyStatusRequest struct {
-	Status            string             `json:"status"` // DEPLOYED or FAILED
+	Status            string             `json:"status"` // DEPLOYED, PARTIALLY_DEPLOYED, DRIFTED or FAILED
 	Error             string             `json:"error,omitempty"`
 	DeployedResources []DeployedResource `json:"deployedResources,omitempty"`
 	Version           int                `json:"version,omitempty"`
+	LintFindings      []LintFinding      `json:"lintFindings,omitempty"`
+	DriftedResources  []DeployedResource `json:"driftedResources,omitempty"` // Reverted, or left in place when Status is DRIFTED
+	Rollout           *RolloutProgress   `json:"rollout,omitempty"`
+	Mode              string             `json:"mode,omitempty"`         // Mode the deployed resources run in
+	AuditedFlows      int64              `json:"auditedFlows,omitempty"` // Flows that would have been dropped while auditing, reported on promotion
+	Rollback          *PolicyRollback    `json:"rollback,omitempty"`
+	Targets           []TargetStatus     `json:"targets,omitempty"`
+}
+
+// TargetStatus reports the deployment state of one resource in one target namespace
+type TargetStatus struct {
+	Namespace string `json:"namespace,omitempty"`
+	Resource  string `json:"resource"` // Kind/Name
+	Version   int    `json:"version,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// PolicyRollback reports a rollback to a recorded revision requested in the cluster
+type PolicyRollback struct {
+	FromVersion int    `json:"fromVersion"`
+	ToVersion   int    `json:"toVersion"`
+	ContentHash string `json:"contentHash"`
+}
+
+// RolloutProgress reports the state of a staged rollout
+type RolloutProgress struct {
+	Step                 int      `json:"step"`
+	Steps                int      `json:"steps"`
+	Namespaces           []string `json:"namespaces,omitempty"` // Namespaces running the new version
+	BaselineBlockedFlows int64    `json:"baselineBlockedFlows"`
+	ObservedBlockedFlows int64    `json:"observedBlockedFlows"`
+	RolledBackToVersion  int      `json:"rolledBackToVersion,omitempty"`
+}
+
+// LintFinding is a static analysis finding on the policy content
+type LintFinding struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"` // Error, Warning or Info
+	Resource string `json:"resource"`
+	Path     string `json:"path,omitempty"`
+	Message  string `json:"message"`
 }
 
 // DeployedResource represents a deployed Kubernetes resource
@@ -318,7 +413,7 @@ func (c *Client) UpdatePolicyStatus(ctx context.Context, policyID string, req Up
 	}
 
 	path := fmt.Sprintf("/api/operator/policies/%s/status", policyID)
-	resp, err := c.doRequest(ctx, "PATCH", path, body)
+	resp, err := c.Submit(ctx, "PATCH", path, "policy/"+policyID, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update policy status: %w", err)
 	}
@@ -364,6 +459,44 @@ func (c *Client) ReportUndeployStatus(ctx context.Context, policyID string, succ
 	return nil
 }
 
+// Security alert types
+const (
+	AlertPolicySignatureInvalid = "POLICY_SIGNATURE_INVALID"
+)
+
+// SecurityAlert reports a security event detected by the operator, such as a
+// policy that failed signature verification
+type SecurityAlert struct {
+	Type      string            `json:"type"`
+	Severity  string            `json:"severity"` // "warning" or "critical"
+	PolicyID  string            `json:"policyId,omitempty"`
+	Version   int               `json:"version,omitempty"`
+	Message   string            `json:"message"`
+	Details   map[string]string `json:"details,omitempty"`
+	Timestamp string            `json:"timestamp"`
+}
+
+// ReportSecurityAlert sends a security alert to the SaaS platform
+func (c *Client) ReportSecurityAlert(ctx context.Context, alert SecurityAlert) error {
+	if alert.Timestamp == "" {
+		alert.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to marshal security alert: %w", err)
+	}
+
+	if _, err := c.Submit(ctx, "POST", "/api/operator/alerts", "", body); err != nil {
+		return fmt.Errorf("failed to report security alert: %w", err)
+	}
+
+	c.log.Info("Reported security alert",
+		"type", alert.Type,
+		"policyId", alert.PolicyID)
+
+	return nil
+}
+
 // FlowRecord represents a network flow record
 type FlowRecord struct {
 	Timestamp    string            `json:"timestamp"`
@@ -412,7 +545,7 @@ func (c *Client) SubmitFlows(ctx context.Context, flows []FlowRecord) (*SubmitFl
 		return nil, fmt.Errorf("failed to marshal flows request: %w", err)
 	}
 
-	resp, err := c.doRequest(ctx, "POST", "/api/operator/flows", body)
+	resp, err := c.Submit(ctx, "POST", "/api/operator/flows", "", body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to submit flows: %w", err)
 	}
@@ -433,16 +566,80 @@ func (c *Client) SubmitFlows(ctx context.Context, flows []FlowRecord) (*SubmitFl
 	return &result, nil
 }
 
+// APIError is returned when the SaaS API responds with an error status
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// Submit sends a request the SaaS platform must eventually receive. With an
+// outbox, a request that fails with a retryable error, or that would overtake
+// a queued request with the same order key, is queued and the returned error
+// wraps ErrQueued.
+func (c *Client) Submit(ctx context.Context, method, path, orderKey string, body []byte) ([]byte, error) {
+	id := newIdempotencyKey()
+	if c.outbox == nil {
+		return c.send(ctx, method, path, body, id)
+	}
+
+	entry := OutboxEntry{ID: id, Method: method, Path: path, Body: body, OrderKey: orderKey}
+	if c.outbox.Pending(orderKey) {
+		if err := c.outbox.Enqueue(entry); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: earlier submissions for %s are queued", ErrQueued, orderKey)
+	}
+
+	resp, err := c.send(ctx, method, path, body, id)
+	if err == nil || !retryable(err) {
+		return resp, err
+	}
+	entry.Attempts = 1
+	entry.LastError = err.Error()
+	entry.NextAttempt = c.outbox.now().Add(retryBackoff(1))
+	if qerr := c.outbox.Enqueue(entry); qerr != nil {
+		c.log.Error(qerr, "Failed to queue submission", "path", path)
+		return nil, err
+	}
+	return nil, fmt.Errorf("%w: %v", ErrQueued, err)
+}
+
 // doRequest performs an HTTP request to the SaaS API
 func (c *Client) doRequest(ctx context.Context, method, path string, body []byte) ([]byte, error) {
-	url := c.endpoint + path
+	return c.send(ctx, method, path, body, "")
+}
+
+// send performs an HTTP request to the SaaS API, with an idempotency key if set
+func (c *Client) send(ctx context.Context, method, path string, body []byte, idempotencyKey string) ([]byte, error) {
+	req, err := c.newRequest(ctx, method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
 
+	return readResponse(resp)
+}
+
+// newRequest creates an authenticated request to the SaaS API
+func (c *Client) newRequest(ctx context.Context, method, path string, body []byte) (*http.Request, error) {
 	var reqBody io.Reader
 	if body != nil {
 		reqBody = bytes.NewReader(body)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -454,20 +651,18 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body []byte
 	if c.nodeName != "" {
 		req.Header.Set("X-Node-Name", c.nodeName)
 	}
+	return req, nil
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
+// readResponse returns the body of a successful response, or an APIError
+func readResponse(resp *http.Response) ([]byte, error) {
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}
 	}
 
 	return respBody, nil
@@ -556,25 +751,41 @@ type SubmitAggregatesResponse struct {
 
 // SimulationResult represents the result of a policy simulation
 type SimulationResult struct {
-	SimulationID       string                       `json:"simulationId"`
-	ClusterID          string                       `json:"clusterId"`
-	NodeName           string                       `json:"nodeName,omitempty"` // For multi-node aggregation
-	PolicyContent      string                       `json:"policyContent"`
-	PolicyType         string                       `json:"policyType"`
-	StartTime          time.Time                    `json:"startTime"`
-	EndTime            time.Time                    `json:"endTime"`
-	Namespaces         []string                     `json:"namespaces,omitempty"`
-	TotalFlowsAnalyzed int64                        `json:"totalFlowsAnalyzed"`
-	AllowedCount       int64                        `json:"allowedCount"`
-	DeniedCount        int64                        `json:"deniedCount"`
-	NoChangeCount      int64                        `json:"noChangeCount"`
-	WouldChangeCount   int64                        `json:"wouldChangeCount"`
-	BreakdownByNS      map[string]*NSImpact         `json:"breakdownByNamespace,omitempty"`
-	BreakdownByVerdict *SimVerdictBreakdown         `json:"breakdownByVerdict,omitempty"`
-	SampleFlows        []SimulatedFlow              `json:"sampleFlows,omitempty"`
-	Errors             []string                     `json:"errors,omitempty"`
-	SimulationTime     time.Time                    `json:"simulationTime"`
-	Duration           time.Duration                `json:"duration"`
+	SimulationID          string                       `json:"simulationId"`
+	ClusterID             string                       `json:"clusterId"`
+	NodeName              string                       `json:"nodeName,omitempty"` // For multi-node aggregation
+	PolicyContent         string                       `json:"policyContent"`
+	PolicyType            string                       `json:"policyType"`
+	StartTime             time.Time                    `json:"startTime"`
+	EndTime               time.Time                    `json:"endTime"`
+	Namespaces            []string                     `json:"namespaces,omitempty"`
+	TotalFlowsAnalyzed    int64                        `json:"totalFlowsAnalyzed"`
+	AllowedCount          int64                        `json:"allowedCount"`
+	DeniedCount           int64                        `json:"deniedCount"`
+	NoChangeCount         int64                        `json:"noChangeCount"`
+	WouldChangeCount      int64                        `json:"wouldChangeCount"`
+	BreakdownByNS         map[string]*NSImpact         `json:"breakdownByNamespace,omitempty"`
+	BreakdownByVerdict    *SimVerdictBreakdown         `json:"breakdownByVerdict,omitempty"`
+	SampleFlows           []SimulatedFlow              `json:"sampleFlows,omitempty"`
+	Connections           []SimulatedConnection        `json:"connections,omitempty"`
+	TotalConnections      int64                        `json:"totalConnections,omitempty"`
+	KilledCount           int64                        `json:"killedCount,omitempty"`  // Tetragon simulations
+	BlockedCount          int64                        `json:"blockedCount,omitempty"` // Tetragon simulations
+	BreakdownByBinary     map[string]*BinaryImpact     `json:"breakdownByBinary,omitempty"`
+	SampleProcesses       []SimulatedProcess           `json:"sampleProcesses,omitempty"`
+	RoutedCount           int64                        `json:"routedCount,omitempty"`   // Gateway route simulations
+	NotFoundCount         int64                        `json:"notFoundCount,omitempty"` // Gateway route simulations
+	BreakdownByRule       map[string]*RouteRuleImpact  `json:"breakdownByRule,omitempty"`
+	BreakdownByBackend    map[string]*BackendImpact    `json:"breakdownByBackend,omitempty"`
+	SampleRequests        []SimulatedRequest           `json:"sampleRequests,omitempty"`
+	BaseVersion           int                          `json:"baseVersion,omitempty"`   // Policy diff simulations
+	PolicyVersion         int                          `json:"policyVersion,omitempty"` // Policy diff simulations
+	VerdictDiffs          []SimulatedFlowDiff          `json:"verdictDiffs,omitempty"`
+	BreakdownByRuleChange map[string]*RuleChangeImpact `json:"breakdownByRuleChange,omitempty"`
+	Cancelled             bool                         `json:"cancelled,omitempty"` // Stopped before completion, counts are partial or empty
+	Errors                []string                     `json:"errors,omitempty"`
+	SimulationTime        time.Time                    `json:"simulationTime"`
+	Duration              time.Duration                `json:"duration"`
 }
 
 // NSImpact shows simulation impact per namespace
@@ -586,6 +797,65 @@ type NSImpact struct {
 	WouldDeny    int64  `json:"wouldDeny"`
 	WouldAllow   int64  `json:"wouldAllow"`
 	NoChange     int64  `json:"noChange"`
+	Killed       int64  `json:"killed,omitempty"`
+	Blocked      int64  `json:"blocked,omitempty"`
+}
+
+// BinaryImpact shows Tetragon simulation impact per binary
+type BinaryImpact struct {
+	Binary      string `json:"binary"`
+	TotalEvents int64  `json:"totalEvents"`
+	Killed      int64  `json:"killed"`
+	Blocked     int64  `json:"blocked"`
+	Audited     int64  `json:"audited"`
+}
+
+// SimulatedProcess represents a process event in Tetragon simulation results
+type SimulatedProcess struct {
+	Timestamp        time.Time `json:"timestamp"`
+	EventType        string    `json:"eventType"`
+	Namespace        string    `json:"namespace"`
+	PodName          string    `json:"podName,omitempty"`
+	Binary           string    `json:"binary"`
+	Arguments        string    `json:"arguments,omitempty"`
+	Syscall          string    `json:"syscall,omitempty"`
+	FilePath         string    `json:"filePath,omitempty"`
+	OriginalVerdict  string    `json:"originalVerdict"`
+	SimulatedVerdict string    `json:"simulatedVerdict"`
+	Action           string    `json:"action,omitempty"`
+	MatchedHook      string    `json:"matchedHook,omitempty"`
+	MatchReason      string    `json:"matchReason,omitempty"`
+}
+
+// RouteRuleImpact shows Gateway route simulation impact per rule
+type RouteRuleImpact struct {
+	Rule     string   `json:"rule"`
+	Backends []string `json:"backends,omitempty"`
+	Requests int64    `json:"requests"`
+}
+
+// BackendImpact shows Gateway route simulation impact per backend
+type BackendImpact struct {
+	Backend          string  `json:"backend"`
+	Requests         int64   `json:"requests"`
+	ExpectedRequests float64 `json:"expectedRequests"`
+}
+
+// SimulatedRequest represents a request in Gateway route simulation results
+type SimulatedRequest struct {
+	Timestamp    time.Time `json:"timestamp"`
+	SrcNamespace string    `json:"srcNamespace"`
+	SrcPodName   string    `json:"srcPodName,omitempty"`
+	DstNamespace string    `json:"dstNamespace"`
+	DstPodName   string    `json:"dstPodName,omitempty"`
+	Method       string    `json:"method,omitempty"`
+	Host         string    `json:"host,omitempty"`
+	Path         string    `json:"path"`
+	Outcome      string    `json:"outcome"`
+	StatusCode   int32     `json:"statusCode,omitempty"`
+	MatchedRule  string    `json:"matchedRule,omitempty"`
+	Backends     []string  `json:"backends,omitempty"`
+	MatchReason  string    `json:"matchReason,omitempty"`
 }
 
 // SimVerdictBreakdown shows verdict transition counts
@@ -612,6 +882,45 @@ type SimulatedFlow struct {
 	VerdictChanged   bool      `json:"verdictChanged"`
 	MatchedRule      string    `json:"matchedRule,omitempty"`
 	MatchReason      string    `json:"matchReason,omitempty"`
+	MatchedPolicy    string    `json:"matchedPolicy,omitempty"`
+}
+
+// SimulatedConnection aggregates the flows of one connection whose verdict
+// would change, with counts, first/last seen and sample flows
+type SimulatedConnection struct {
+	SrcNamespace     string          `json:"srcNamespace"`
+	SrcWorkload      string          `json:"srcWorkload"`
+	DstNamespace     string          `json:"dstNamespace"`
+	DstWorkload      string          `json:"dstWorkload"`
+	DstPort          uint32          `json:"dstPort"`
+	Protocol         string          `json:"protocol"`
+	L7               string          `json:"l7,omitempty"`
+	OriginalVerdict  string          `json:"originalVerdict"`
+	SimulatedVerdict string          `json:"simulatedVerdict"`
+	MatchedRule      string          `json:"matchedRule,omitempty"`
+	MatchReason      string          `json:"matchReason,omitempty"`
+	MatchedPolicy    string          `json:"matchedPolicy,omitempty"`
+	Flows            int64           `json:"flows"`
+	FirstSeen        time.Time       `json:"firstSeen"`
+	LastSeen         time.Time       `json:"lastSeen"`
+	Samples          []SimulatedFlow `json:"samples,omitempty"`
+}
+
+// SimulatedFlowDiff is a flow whose verdict differs between two versions of
+// a policy, with its simulation result under each
+type SimulatedFlowDiff struct {
+	Base   SimulatedFlow `json:"base"`
+	Target SimulatedFlow `json:"target"`
+}
+
+// RuleChangeImpact counts the flows whose verdict changes between a pair of
+// rules of two policy versions
+type RuleChangeImpact struct {
+	BaseRule     string `json:"baseRule"`
+	TargetRule   string `json:"targetRule"`
+	Flows        int64  `json:"flows"`
+	NewlyAllowed int64  `json:"newlyAllowed"`
+	NewlyDenied  int64  `json:"newlyDenied"`
 }
 
 // SubmitSimulationResultResponse is the response from submitting simulation results
@@ -637,7 +946,7 @@ func (c *Client) SubmitSimulationResult(ctx context.Context, result *SimulationR
 		return nil, fmt.Errorf("failed to marshal simulation result: %w", err)
 	}
 
-	resp, err := c.doRequest(ctx, "POST", "/api/operator/simulation/results", body)
+	resp, err := c.Submit(ctx, "POST", "/api/operator/simulation/results", "", body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to submit simulation result: %w", err)
 	}
@@ -677,6 +986,26 @@ type PendingSimulation struct {
 	IncludeDetails bool      `json:"includeDetails,omitempty"`
 	MaxDetails     int32     `json:"maxDetails,omitempty"`
 	RequestedAt    time.Time `json:"requestedAt"`
+
+	// PolicyChanges turns the request into a what-if simulation of a diff
+	// against the cluster's deployed policy set. PolicyContent is unused then.
+	PolicyChanges []SimulationPolicyChange `json:"policyChanges,omitempty"`
+
+	// BasePolicyContent turns the request into a diff between two versions
+	// of the policy: BasePolicyContent is the old version and PolicyContent
+	// the new one. Only flows whose verdict differs are reported.
+	BasePolicyContent string `json:"basePolicyContent,omitempty"`
+	BaseVersion       int    `json:"baseVersion,omitempty"`
+	PolicyVersion     int    `json:"policyVersion,omitempty"`
+}
+
+// SimulationPolicyChange is a single add/modify/delete entry of a policy set diff
+type SimulationPolicyChange struct {
+	Operation     string `json:"operation"`
+	Name          string `json:"name,omitempty"`
+	Namespace     string `json:"namespace,omitempty"`
+	PolicyType    string `json:"policyType,omitempty"`
+	PolicyContent string `json:"policyContent,omitempty"`
 }
 
 // FetchPendingSimulations retrieves pending simulation requests from SaaS
@@ -702,6 +1031,76 @@ func (c *Client) FetchPendingSimulations(ctx context.Context) (*FetchPendingSimu
 	return &result, nil
 }
 
+// Simulation states reported while a simulation is pending on a node
+const (
+	SimulationStatusQueued  = "QUEUED"
+	SimulationStatusRunning = "RUNNING"
+)
+
+// SimulationProgress reports how far a node has got with a simulation
+type SimulationProgress struct {
+	SimulationID   string    `json:"simulationId"`
+	ClusterID      string    `json:"clusterId"`
+	NodeName       string    `json:"nodeName,omitempty"`
+	Status         string    `json:"status"`                  // QUEUED or RUNNING
+	QueuePosition  int       `json:"queuePosition,omitempty"` // 1-based, while queued
+	StartedAt      time.Time `json:"startedAt,omitempty"`
+	FilesTotal     int       `json:"filesTotal"`
+	FilesScanned   int       `json:"filesScanned"`
+	FlowsEvaluated int64     `json:"flowsEvaluated"`
+	ETASeconds     int64     `json:"etaSeconds,omitempty"` // 0 until the first file is scanned
+}
+
+// ReportSimulationProgressResponse is the response from reporting simulation progress
+type ReportSimulationProgressResponse struct {
+	Success bool `json:"success"`
+	// Cancelled is set once the simulation has been cancelled in SaaS
+	Cancelled bool   `json:"cancelled"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ReportSimulationProgress sends the progress of a pending simulation to SaaS.
+// The response tells whether the simulation has been cancelled meanwhile.
+func (c *Client) ReportSimulationProgress(ctx context.Context, progress *SimulationProgress) (*ReportSimulationProgressResponse, error) {
+	if progress == nil {
+		return &ReportSimulationProgressResponse{Success: true}, nil
+	}
+
+	// Set cluster ID if not already set
+	if progress.ClusterID == "" {
+		progress.ClusterID = c.clusterID
+	}
+
+	body, err := json.Marshal(progress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal simulation progress: %w", err)
+	}
+
+	path := fmt.Sprintf("/api/operator/simulation/%s/progress", progress.SimulationID)
+	resp, err := c.doRequest(ctx, "POST", path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to report simulation progress: %w", err)
+	}
+
+	var response ReportSimulationProgressResponse
+	if err := json.Unmarshal(resp, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal simulation progress response: %w", err)
+	}
+
+	if !response.Success {
+		return nil, fmt.Errorf("report simulation progress failed: %s", response.Error)
+	}
+
+	c.log.V(1).Info("Reported simulation progress",
+		"simulationId", progress.SimulationID,
+		"status", progress.Status,
+		"filesScanned", progress.FilesScanned,
+		"filesTotal", progress.FilesTotal,
+		"cancelled", response.Cancelled)
+
+	return &response, nil
+}
+
 // SubmitAggregates sends aggregated telemetry to the SaaS platform
 func (c *Client) SubmitAggregates(ctx context.Context, aggregates *AggregatedTelemetry) (*SubmitAggregatesResponse, error) {
 	if aggregates == nil {
@@ -718,7 +1117,7 @@ func (c *Client) SubmitAggregates(ctx context.Context, aggregates *AggregatedTel
 		return nil, fmt.Errorf("failed to marshal aggregates: %w", err)
 	}
 
-	resp, err := c.doRequest(ctx, "POST", "/api/operator/telemetry/aggregates", body)
+	resp, err := c.Submit(ctx, "POST", "/api/operator/telemetry/aggregates", "aggregates", body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to submit aggregates: %w", err)
 	}
@@ -752,6 +1151,12 @@ type GatewayAPIResource struct {
 	Status      string                 `json:"status"`
 	SyncedAt    string                 `json:"syncedAt,omitempty"`
 	LastUpdated string                 `json:"lastUpdated"`
+	// Version, Signature and SignatureKeyID sign the resource as a policy of
+	// its route type, with the resource YAML as content and its namespace as
+	// the only target namespace, when policy signing is enabled
+	Version        int    `json:"version,omitempty"`
+	Signature      string `json:"signature,omitempty"`      // Ed25519 signature of the resource, base64 encoded
+	SignatureKeyID string `json:"signatureKeyId,omitempty"` // ID of the key the resource was signed with
 }
 
 // FetchGatewayAPIResponse is the response from fetching Gateway API resources
@@ -807,7 +1212,7 @@ func (c *Client) UpdateGatewayAPIStatus(ctx context.Context, resourceID string,
 	}
 
 	path := fmt.Sprintf("/api/operator/gateway-api/%s/status", resourceID)
-	resp, err := c.doRequest(ctx, "PATCH", path, body)
+	resp, err := c.Submit(ctx, "PATCH", path, "gateway-api/"+resourceID, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update Gateway API resource status: %w", err)
 	}
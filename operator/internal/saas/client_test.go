@@ -411,6 +411,33 @@ func TestClient_UpdatePolicyStatus_Failure(t *testing.T) {
 	}
 }
 
+func TestClient_ReportSecurityAlert(t *testing.T) {
+	var got SecurityAlert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/operator/alerts" || r.Method != "POST" {
+			t.Errorf("Request = %s %s, want POST /api/operator/alerts", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token", "cluster", logr.Discard())
+	err := client.ReportSecurityAlert(context.Background(), SecurityAlert{
+		Type:     AlertPolicySignatureInvalid,
+		Severity: "critical",
+		PolicyID: "policy-123",
+		Version:  2,
+		Message:  "policy signature does not match its content",
+	})
+	if err != nil {
+		t.Fatalf("ReportSecurityAlert() error = %v", err)
+	}
+	if got.Type != AlertPolicySignatureInvalid || got.PolicyID != "policy-123" || got.Timestamp == "" {
+		t.Errorf("alert = %+v, want a timestamped signature alert for policy-123", got)
+	}
+}
+
 func TestClient_SubmitFlows_Success(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/api/operator/flows" {
@@ -654,6 +681,41 @@ func TestClient_SubmitSimulationResult_Success(t *testing.T) {
 	}
 }
 
+func TestClient_ReportSimulationProgress(t *testing.T) {
+	var received SimulationProgress
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/operator/simulation/sim-123/progress" {
+			t.Errorf("Path = %s, want /api/operator/simulation/sim-123/progress", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+
+		resp := ReportSimulationProgressResponse{
+			Success:   true,
+			Cancelled: true,
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token", "cluster", logr.Discard())
+
+	resp, err := client.ReportSimulationProgress(context.Background(), &SimulationProgress{
+		SimulationID: "sim-123",
+		Status:       SimulationStatusRunning,
+		FilesTotal:   10,
+		FilesScanned: 4,
+	})
+	if err != nil {
+		t.Fatalf("ReportSimulationProgress() error = %v", err)
+	}
+	if !resp.Cancelled {
+		t.Error("ReportSimulationProgress() cancelled = false, want true")
+	}
+	if received.ClusterID != "cluster" || received.FilesScanned != 4 || received.Status != SimulationStatusRunning {
+		t.Errorf("received progress = %+v", received)
+	}
+}
+
 func TestClient_SubmitSimulationResult_Nil(t *testing.T) {
 	client := NewClient("https://api.example.com", "token", "cluster", logr.Discard())
 
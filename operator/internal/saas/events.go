@@ -0,0 +1,315 @@
+package saas
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// Event types sent on the event stream
+const (
+	EventPolicies    = "policies"
+	EventGatewayAPI  = "gateway-api"
+	EventSimulations = "simulations"
+	// EventResync is sent when the SaaS platform cannot replay the events
+	// after the resume token; every subscriber is notified
+	EventResync = "resync"
+)
+
+// StreamResyncInterval is how often subscribers still poll while the event
+// stream is connected, to recover from events that were lost
+const StreamResyncInterval = 10 * time.Minute
+
+const (
+	eventsPath = "/api/operator/events"
+
+	// eventStreamIdleTimeout closes a connection the SaaS platform stopped
+	// sending on, including keep-alive comments
+	eventStreamIdleTimeout = 90 * time.Second
+)
+
+// ErrEventsUnsupported is returned when the SaaS platform has no event stream
+var ErrEventsUnsupported = errors.New("event stream not supported by SaaS platform")
+
+// Event is a change notification from the SaaS platform
+type Event struct {
+	// ID is the resume token of the stream after this event
+	ID   string
+	Type string
+	Data string
+}
+
+// openEvents connects to the Server-Sent Events stream of change
+// notifications. The SaaS platform first replays the events after resumeToken.
+func (c *Client) openEvents(ctx context.Context, resumeToken string) (io.ReadCloser, error) {
+	req, err := c.newRequest(ctx, "GET", eventsPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if resumeToken != "" {
+		req.Header.Set("Last-Event-ID", resumeToken)
+	}
+
+	// The stream stays open, so the client timeout must not apply
+	httpClient := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNotImplemented:
+		resp.Body.Close()
+		return nil, ErrEventsUnsupported
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	case !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream"):
+		resp.Body.Close()
+		return nil, ErrEventsUnsupported
+	}
+	return resp.Body, nil
+}
+
+// readEvents parses a Server-Sent Events stream, calling handle for each
+// event, until the stream ends. activity is called for every line received.
+func readEvents(r io.Reader, activity func(), handle func(Event)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var event Event
+	var data []string
+	for scanner.Scan() {
+		activity()
+		line := scanner.Text()
+		if line == "" {
+			// A blank line dispatches the event
+			if event.Type != "" || len(data) > 0 {
+				if event.Type == "" {
+					event.Type = "message"
+				}
+				event.Data = strings.Join(data, "\n")
+				handle(event)
+			}
+			event = Event{ID: event.ID}
+			data = nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // Keep-alive comment
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			event.ID = value
+		case "event":
+			event.Type = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+// EventStream keeps a connection to the SaaS event stream and notifies
+// subscribers of changes, so they do not wait for their next poll. It
+// reconnects with exponential backoff, resuming after the last event it
+// received; while it is disconnected subscribers keep polling.
+type EventStream struct {
+	log logr.Logger
+
+	mu          sync.Mutex
+	client      *Client
+	resumeToken string
+	connected   bool
+	subscribers map[string][]chan struct{}
+	cancel      context.CancelFunc // Closes the current connection
+	clientSet   chan struct{}
+}
+
+// NewEventStream creates an event stream. It connects once it is given a client.
+func NewEventStream(log logr.Logger) *EventStream {
+	return &EventStream{
+		log:         log.WithName("saas-events"),
+		subscribers: make(map[string][]chan struct{}),
+		clientSet:   make(chan struct{}, 1),
+	}
+}
+
+// SetClient sets the client connecting to the event stream, reconnecting
+// if its endpoint or token changed
+func (s *EventStream) SetClient(c *Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := s.client
+	s.client = c
+	if previous != nil && c != nil && previous.endpoint == c.endpoint && previous.apiToken == c.apiToken {
+		return
+	}
+	if s.cancel != nil {
+		s.cancel()
+	}
+	select {
+	case s.clientSet <- struct{}{}:
+	default:
+	}
+}
+
+// Subscribe returns a channel notified when events of the given types, or a
+// resync, are received. Notifications are coalesced while the subscriber is busy.
+func (s *EventStream) Subscribe(eventTypes ...string) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, eventType := range eventTypes {
+		s.subscribers[eventType] = append(s.subscribers[eventType], ch)
+	}
+	s.subscribers[EventResync] = append(s.subscribers[EventResync], ch)
+	return ch
+}
+
+// Connected returns true if the event stream is connected
+func (s *EventStream) Connected() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connected
+}
+
+// Start connects to the event stream until the context is cancelled
+func (s *EventStream) Start(ctx context.Context) error {
+	attempts := 0
+	reconnect := false
+	for {
+		select {
+		case <-s.clientSet:
+		default:
+		}
+		s.mu.Lock()
+		c := s.client
+		connCtx, cancel := context.WithCancel(ctx)
+		s.cancel = cancel
+		s.mu.Unlock()
+
+		if c == nil {
+			select {
+			case <-ctx.Done():
+				cancel()
+				return nil
+			case <-s.clientSet:
+				cancel()
+				continue
+			}
+		}
+
+		err := s.connect(connCtx, c, reconnect)
+		cancel()
+		if ctx.Err() != nil {
+			return nil
+		}
+		reconnect = true
+
+		delay := minRetryBackoff
+		switch {
+		case err == nil:
+			// Connected, then closed by the SaaS platform or for a new client
+			attempts = 0
+		case errors.Is(err, ErrEventsUnsupported):
+			attempts++
+			delay = maxRetryBackoff
+			if attempts == 1 {
+				s.log.Info("SaaS platform has no event stream, polling for changes")
+			}
+		default:
+			attempts++
+			delay = retryBackoff(attempts)
+			s.log.V(1).Info("Event stream unavailable, polling for changes", "retryIn", delay, "error", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.clientSet:
+		case <-time.After(delay):
+		}
+	}
+}
+
+// connect reads events from one connection until it is closed. It returns
+// nil if the connection was established.
+func (s *EventStream) connect(ctx context.Context, c *Client, reconnect bool) error {
+	s.mu.Lock()
+	resumeToken := s.resumeToken
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	body, err := c.openEvents(ctx, resumeToken)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	s.setConnected(true)
+	defer s.setConnected(false)
+	s.log.Info("Connected to event stream", "resumed", resumeToken != "")
+
+	// Events sent while disconnected are only replayed after a resume token
+	if reconnect && resumeToken == "" {
+		s.notify(EventResync)
+	}
+
+	idle := time.AfterFunc(eventStreamIdleTimeout, cancel)
+	defer idle.Stop()
+	err = readEvents(body,
+		func() { idle.Reset(eventStreamIdleTimeout) },
+		func(event Event) {
+			s.log.V(1).Info("Received event", "type", event.Type, "id", event.ID)
+			if event.ID != "" {
+				s.mu.Lock()
+				s.resumeToken = event.ID
+				s.mu.Unlock()
+			}
+			s.notify(event.Type)
+		})
+	if ctx.Err() == nil && err != io.EOF {
+		s.log.V(1).Info("Event stream closed", "error", err.Error())
+	}
+	return nil
+}
+
+func (s *EventStream) setConnected(connected bool) {
+	s.mu.Lock()
+	s.connected = connected
+	s.mu.Unlock()
+}
+
+// notify notifies the subscribers of an event type; a resync notifies all
+func (s *EventStream) notify(eventType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ch := range s.subscribers[eventType] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package saas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+func TestReadEvents(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream string
+		want   []Event
+	}{
+		{
+			name:   "single event",
+			stream: "id: 1\nevent: policies\ndata: {\"policyId\":\"p1\"}\n\n",
+			want:   []Event{{ID: "1", Type: "policies", Data: `{"policyId":"p1"}`}},
+		},
+		{
+			name:   "keep-alive comments and multi-line data",
+			stream: ": ping\n\nid: 2\nevent: simulations\ndata: a\ndata: b\n\n: ping\n",
+			want:   []Event{{ID: "2", Type: "simulations", Data: "a\nb"}},
+		},
+		{
+			name:   "id carries over to the next event",
+			stream: "id: 3\nevent: policies\n\nevent: gateway-api\n\n",
+			want: []Event{
+				{ID: "3", Type: "policies"},
+				{ID: "3", Type: "gateway-api"},
+			},
+		},
+		{
+			name:   "data without a type",
+			stream: "data: hello\n\n",
+			want:   []Event{{Type: "message", Data: "hello"}},
+		},
+		{
+			name:   "incomplete event is not dispatched",
+			stream: "id: 4\nevent: policies\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []Event
+			err := readEvents(strings.NewReader(tt.stream), func() {}, func(e Event) {
+				got = append(got, e)
+			})
+			if err != io.EOF {
+				t.Errorf("readEvents() error = %v, want EOF", err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("events = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventStream_ConnectResumes(t *testing.T) {
+	var resumeTokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != eventsPath || r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("unexpected request %s %s", r.URL.Path, r.Header.Get("Accept"))
+		}
+		resumeTokens = append(resumeTokens, r.Header.Get("Last-Event-ID"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		if len(resumeTokens) == 1 {
+			fmt.Fprint(w, "id: 7\nevent: policies\ndata: {}\n\n")
+		} else {
+			fmt.Fprint(w, "id: 8\nevent: gateway-api\ndata: {}\n\n")
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token", "cluster", logr.Discard())
+	s := NewEventStream(logr.Discard())
+	policies := s.Subscribe(EventPolicies)
+	simulations := s.Subscribe(EventSimulations)
+	ctx := context.Background()
+
+	if err := s.connect(ctx, client, false); err != nil {
+		t.Fatalf("connect() error = %v", err)
+	}
+	select {
+	case <-policies:
+	default:
+		t.Error("Expected the policies subscriber to be notified")
+	}
+	select {
+	case <-simulations:
+		t.Error("Expected the simulations subscriber not to be notified")
+	default:
+	}
+
+	if err := s.connect(ctx, client, true); err != nil {
+		t.Fatalf("connect() error = %v", err)
+	}
+	if len(resumeTokens) != 2 || resumeTokens[0] != "" || resumeTokens[1] != "7" {
+		t.Errorf("resume tokens = %q, want [\"\" \"7\"]", resumeTokens)
+	}
+	if s.Connected() {
+		t.Error("Expected the stream to be disconnected after it closed")
+	}
+}
+
+func TestEventStream_ResyncNotifiesAll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: resync\ndata: {}\n\n")
+	}))
+	defer server.Close()
+
+	s := NewEventStream(logr.Discard())
+	subscribers := []<-chan struct{}{
+		s.Subscribe(EventPolicies),
+		s.Subscribe(EventGatewayAPI),
+		s.Subscribe(EventSimulations),
+	}
+	if err := s.connect(context.Background(), NewClient(server.URL, "token", "cluster", logr.Discard()), false); err != nil {
+		t.Fatalf("connect() error = %v", err)
+	}
+	for i, ch := range subscribers {
+		select {
+		case <-ch:
+		default:
+			t.Errorf("Expected subscriber %d to be notified", i)
+		}
+	}
+}
+
+func TestEventStream_Unsupported(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		wantErr error
+	}{
+		{
+			name:    "not found",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotFound) },
+			wantErr: ErrEventsUnsupported,
+		},
+		{
+			name: "not an event stream",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{}`))
+			},
+			wantErr: ErrEventsUnsupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			s := NewEventStream(logr.Discard())
+			err := s.connect(context.Background(), NewClient(server.URL, "token", "cluster", logr.Discard()), false)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("connect() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	s := NewEventStream(logr.Discard())
+	err := s.connect(context.Background(), NewClient(server.URL, "token", "cluster", logr.Discard()), false)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("connect() error = %v, want APIError with status 401", err)
+	}
+}
+
+func TestEventStream_SetClientReconnects(t *testing.T) {
+	connected := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		connected <- r.Header.Get("Authorization")
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	s := NewEventStream(logr.Discard())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Start(ctx)
+
+	waitFor := func(want string) {
+		t.Helper()
+		select {
+		case got := <-connected:
+			if got != want {
+				t.Errorf("Authorization = %s, want %s", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected a connection with %s", want)
+		}
+	}
+
+	s.SetClient(NewClient(server.URL, "token-1", "cluster", logr.Discard()))
+	waitFor("Bearer token-1")
+
+	// The same endpoint and token keep the connection
+	s.SetClient(NewClient(server.URL, "token-1", "cluster", logr.Discard()))
+	s.SetClient(NewClient(server.URL, "token-2", "cluster", logr.Discard()))
+	waitFor("Bearer token-2")
+	for i := 0; !s.Connected(); i++ {
+		if i == 50 {
+			t.Fatal("Expected the stream to be connected")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
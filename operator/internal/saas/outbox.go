@@ -0,0 +1,342 @@
+package saas
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// ErrQueued is wrapped by the error of a submission the SaaS platform did not
+// accept that was saved in the outbox to be sent again
+var ErrQueued = errors.New("queued in outbox for retry")
+
+const (
+	// maxOutboxEntries bounds the disk used during a long outage; the
+	// oldest entries are dropped beyond it
+	maxOutboxEntries = 10000
+
+	outboxFlushInterval = 5 * time.Second
+
+	// Outbox entries and event stream connections are retried with
+	// exponential backoff between these delays
+	minRetryBackoff = 5 * time.Second
+	maxRetryBackoff = 5 * time.Minute
+)
+
+// OutboxEntry is a submission waiting to be sent to the SaaS platform
+type OutboxEntry struct {
+	// ID is sent as the Idempotency-Key header of every attempt, so the
+	// SaaS platform can ignore a submission it already received
+	ID     string          `json:"id"`
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+	// OrderKey orders the entries of one subject, such as a policy. An
+	// entry is only sent once the earlier entries with its key were.
+	OrderKey    string    `json:"orderKey,omitempty"`
+	Created     time.Time `json:"created"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"nextAttempt"`
+	LastError   string    `json:"lastError,omitempty"`
+
+	seq uint64
+}
+
+// OutboxStats contains outbox statistics
+type OutboxStats struct {
+	Depth     int
+	Delivered int64
+	Dropped   int64
+	// OldestAge is how long the oldest queued entry has waited
+	OldestAge time.Duration
+}
+
+// Outbox keeps SaaS submissions on disk until they are delivered. Entries
+// are sent again with exponential backoff and survive restarts.
+type Outbox struct {
+	dir string
+	log logr.Logger
+	now func() time.Time
+
+	mu        sync.Mutex
+	entries   []*OutboxEntry // In the order they were queued
+	seq       uint64
+	client    *Client // Sends the entries; the last client given the outbox
+	delivered int64
+	dropped   int64
+	wake      chan struct{}
+}
+
+// NewOutbox opens the outbox in dir, creating it if needed, and loads the
+// entries queued before a restart
+func NewOutbox(dir string, log logr.Logger) (*Outbox, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+
+	o := &Outbox{
+		dir:  dir,
+		log:  log.WithName("saas-outbox"),
+		now:  time.Now,
+		wake: make(chan struct{}, 1),
+	}
+	if err := o.load(); err != nil {
+		return nil, err
+	}
+	if len(o.entries) > 0 {
+		o.log.Info("Loaded queued SaaS submissions", "entries", len(o.entries))
+	}
+	return o, nil
+}
+
+// load reads the entries in the outbox directory. Entries that cannot be
+// read are removed so they do not block the queue.
+func (o *Outbox) load() error {
+	files, err := os.ReadDir(o.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read outbox directory: %w", err)
+	}
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		path := filepath.Join(o.dir, name)
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, ".json"), 10, 64)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read outbox entry %s: %w", name, err)
+		}
+		entry := &OutboxEntry{}
+		if err := json.Unmarshal(data, entry); err != nil {
+			o.log.Error(err, "Removing unreadable outbox entry", "file", name)
+			_ = os.Remove(path)
+			continue
+		}
+		entry.seq = seq
+		o.entries = append(o.entries, entry)
+		if seq > o.seq {
+			o.seq = seq
+		}
+	}
+	sort.Slice(o.entries, func(i, j int) bool {
+		return o.entries[i].seq < o.entries[j].seq
+	})
+	return nil
+}
+
+// setClient sets the client sending the entries
+func (o *Outbox) setClient(c *Client) {
+	o.mu.Lock()
+	o.client = c
+	o.mu.Unlock()
+}
+
+// Enqueue saves an entry to be sent by the next flush
+func (o *Outbox) Enqueue(entry OutboxEntry) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if entry.ID == "" {
+		entry.ID = newIdempotencyKey()
+	}
+	if entry.Created.IsZero() {
+		entry.Created = o.now()
+	}
+	o.seq++
+	entry.seq = o.seq
+	if err := o.write(&entry); err != nil {
+		o.seq--
+		return err
+	}
+	o.entries = append(o.entries, &entry)
+
+	for len(o.entries) > maxOutboxEntries {
+		oldest := o.entries[0]
+		o.log.Info("Outbox full, dropping oldest submission", "path", oldest.Path, "created", oldest.Created)
+		o.removeLocked(oldest)
+		o.dropped++
+	}
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Pending returns true if entries with the order key are waiting to be sent
+func (o *Outbox) Pending(orderKey string) bool {
+	if orderKey == "" {
+		return false
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, entry := range o.entries {
+		if entry.OrderKey == orderKey {
+			return true
+		}
+	}
+	return false
+}
+
+// Stats returns outbox statistics
+func (o *Outbox) Stats() OutboxStats {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	stats := OutboxStats{
+		Depth:     len(o.entries),
+		Delivered: o.delivered,
+		Dropped:   o.dropped,
+	}
+	if len(o.entries) > 0 {
+		stats.OldestAge = o.now().Sub(o.entries[0].Created)
+	}
+	return stats
+}
+
+// Start flushes the outbox periodically, and when entries are queued, until
+// the context is cancelled
+func (o *Outbox) Start(ctx context.Context) error {
+	ticker := time.NewTicker(outboxFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		o.Flush(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-o.wake:
+		}
+	}
+}
+
+// Flush sends the entries that are due, in the order they were queued. An
+// entry that fails holds back the later entries with its order key.
+// Entries the SaaS platform rejects are dropped.
+func (o *Outbox) Flush(ctx context.Context) {
+	o.mu.Lock()
+	c := o.client
+	entries := append([]*OutboxEntry{}, o.entries...)
+	o.mu.Unlock()
+	if c == nil {
+		return
+	}
+
+	held := make(map[string]bool)
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+		if entry.OrderKey != "" && held[entry.OrderKey] {
+			continue
+		}
+		if o.now().Before(entry.NextAttempt) {
+			held[entry.OrderKey] = true
+			continue
+		}
+
+		_, err := c.send(ctx, entry.Method, entry.Path, entry.Body, entry.ID)
+		o.mu.Lock()
+		switch {
+		case err == nil:
+			o.removeLocked(entry)
+			o.delivered++
+			o.log.V(1).Info("Sent queued submission", "path", entry.Path, "attempts", entry.Attempts+1)
+		case !retryable(err):
+			o.removeLocked(entry)
+			o.dropped++
+			o.log.Error(err, "Dropping queued submission rejected by SaaS", "path", entry.Path)
+		default:
+			entry.Attempts++
+			entry.LastError = err.Error()
+			entry.NextAttempt = o.now().Add(retryBackoff(entry.Attempts))
+			if werr := o.write(entry); werr != nil {
+				o.log.Error(werr, "Failed to save outbox entry", "path", entry.Path)
+			}
+			held[entry.OrderKey] = true
+			o.log.V(1).Info("Queued submission failed, will retry",
+				"path", entry.Path,
+				"attempts", entry.Attempts,
+				"nextAttempt", entry.NextAttempt,
+				"error", err.Error())
+		}
+		o.mu.Unlock()
+	}
+}
+
+// write saves an entry, replacing the file atomically
+func (o *Outbox) write(entry *OutboxEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox entry: %w", err)
+	}
+	path := o.entryPath(entry)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write outbox entry: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write outbox entry: %w", err)
+	}
+	return nil
+}
+
+// removeLocked deletes an entry; the caller holds mu
+func (o *Outbox) removeLocked(entry *OutboxEntry) {
+	for i, e := range o.entries {
+		if e == entry {
+			o.entries = append(o.entries[:i], o.entries[i+1:]...)
+			break
+		}
+	}
+	if err := os.Remove(o.entryPath(entry)); err != nil && !os.IsNotExist(err) {
+		o.log.Error(err, "Failed to remove outbox entry", "path", entry.Path)
+	}
+}
+
+func (o *Outbox) entryPath(entry *OutboxEntry) string {
+	return filepath.Join(o.dir, fmt.Sprintf("%020d.json", entry.seq))
+}
+
+// retryBackoff returns the delay before the next attempt after the given
+// number of failed attempts
+func retryBackoff(attempts int) time.Duration {
+	backoff := minRetryBackoff
+	for i := 1; i < attempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxRetryBackoff)
+}
+
+// retryable returns true if a failed request may succeed when sent again:
+// the SaaS platform could not be reached, timed out, throttled or failed
+func retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == 408 || apiErr.StatusCode == 429 || apiErr.StatusCode >= 500
+	}
+	return true
+}
+
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
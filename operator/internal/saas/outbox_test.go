@@ -0,0 +1,198 @@
+package saas
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// outboxTestServer records the requests it receives and responds with the
+// status set by setStatus
+type outboxTestServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	status   int
+	bodies   []string
+	keys     []string
+	requests int
+}
+
+func newOutboxTestServer() *outboxTestServer {
+	s := &outboxTestServer{status: http.StatusOK}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests++
+		if s.status != http.StatusOK {
+			w.WriteHeader(s.status)
+			return
+		}
+		s.bodies = append(s.bodies, string(body))
+		s.keys = append(s.keys, r.Header.Get("Idempotency-Key"))
+		w.Write([]byte(`{"success":true}`))
+	}))
+	return s
+}
+
+func (s *outboxTestServer) setStatus(status int) {
+	s.mu.Lock()
+	s.status = status
+	s.mu.Unlock()
+}
+
+func TestClient_Submit_Outbox(t *testing.T) {
+	server := newOutboxTestServer()
+	defer server.Close()
+
+	dir := t.TempDir()
+	outbox, err := NewOutbox(dir, logr.Discard())
+	if err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+	client := NewClient(server.URL, "token", "cluster", logr.Discard())
+	client.SetOutbox(outbox)
+	ctx := context.Background()
+
+	// The SaaS platform is unavailable: the first submission is queued and the
+	// second queues behind it without being sent
+	server.setStatus(http.StatusServiceUnavailable)
+	if _, err := client.Submit(ctx, "PATCH", "/status", "policy/1", []byte(`"first"`)); !errors.Is(err, ErrQueued) {
+		t.Fatalf("Submit() error = %v, want ErrQueued", err)
+	}
+	server.setStatus(http.StatusOK)
+	if _, err := client.Submit(ctx, "PATCH", "/status", "policy/1", []byte(`"second"`)); !errors.Is(err, ErrQueued) {
+		t.Fatalf("Submit() error = %v, want ErrQueued", err)
+	}
+	if server.requests != 1 {
+		t.Errorf("requests = %d, want 1", server.requests)
+	}
+	if stats := outbox.Stats(); stats.Depth != 2 {
+		t.Errorf("Depth = %d, want 2", stats.Depth)
+	}
+
+	// A submission for another policy is sent right away
+	if _, err := client.Submit(ctx, "PATCH", "/status", "policy/2", []byte(`"other"`)); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	// The queued entries are replayed after a restart, once their backoff passed
+	replayed, err := NewOutbox(dir, logr.Discard())
+	if err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+	if stats := replayed.Stats(); stats.Depth != 2 {
+		t.Fatalf("Depth after restart = %d, want 2", stats.Depth)
+	}
+	client = NewClient(server.URL, "token", "cluster", logr.Discard())
+	client.SetOutbox(replayed)
+
+	replayed.Flush(ctx)
+	if stats := replayed.Stats(); stats.Depth != 2 {
+		t.Errorf("Depth before backoff passed = %d, want 2", stats.Depth)
+	}
+	replayed.now = func() time.Time { return time.Now().Add(minRetryBackoff) }
+	replayed.Flush(ctx)
+
+	stats := replayed.Stats()
+	if stats.Depth != 0 || stats.Delivered != 2 {
+		t.Errorf("Stats() = %+v, want depth 0 and 2 delivered", stats)
+	}
+	want := []string{`"other"`, `"first"`, `"second"`}
+	if len(server.bodies) != len(want) {
+		t.Fatalf("bodies = %v, want %v", server.bodies, want)
+	}
+	for i := range want {
+		if server.bodies[i] != want[i] {
+			t.Errorf("bodies[%d] = %s, want %s", i, server.bodies[i], want[i])
+		}
+	}
+	for _, key := range server.keys {
+		if key == "" {
+			t.Error("Expected an Idempotency-Key header on every request")
+		}
+	}
+}
+
+func TestClient_Submit_OutboxKeepsIdempotencyKey(t *testing.T) {
+	server := newOutboxTestServer()
+	defer server.Close()
+
+	var keys []string
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{}`))
+	})
+
+	outbox, err := NewOutbox(t.TempDir(), logr.Discard())
+	if err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+	client := NewClient(server.URL, "token", "cluster", logr.Discard())
+	client.SetOutbox(outbox)
+
+	if _, err := client.Submit(context.Background(), "POST", "/flows", "", []byte(`{}`)); !errors.Is(err, ErrQueued) {
+		t.Fatalf("Submit() error = %v, want ErrQueued", err)
+	}
+	outbox.now = func() time.Time { return time.Now().Add(time.Hour) }
+	outbox.Flush(context.Background())
+
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Errorf("Idempotency-Keys = %v, want the same key on both attempts", keys)
+	}
+}
+
+func TestClient_Submit_OutboxRejected(t *testing.T) {
+	server := newOutboxTestServer()
+	defer server.Close()
+	server.setStatus(http.StatusBadRequest)
+
+	outbox, err := NewOutbox(t.TempDir(), logr.Discard())
+	if err != nil {
+		t.Fatalf("NewOutbox() error = %v", err)
+	}
+	client := NewClient(server.URL, "token", "cluster", logr.Discard())
+	client.SetOutbox(outbox)
+
+	_, err = client.Submit(context.Background(), "POST", "/flows", "", []byte(`{}`))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || errors.Is(err, ErrQueued) {
+		t.Fatalf("Submit() error = %v, want an APIError that is not queued", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("StatusCode = %d, want 400", apiErr.StatusCode)
+	}
+	if stats := outbox.Stats(); stats.Depth != 0 {
+		t.Errorf("Depth = %d, want 0", stats.Depth)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 5 * time.Second},
+		{attempts: 2, want: 10 * time.Second},
+		{attempts: 4, want: 40 * time.Second},
+		{attempts: 20, want: 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := retryBackoff(tt.attempts); got != tt.want {
+			t.Errorf("retryBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
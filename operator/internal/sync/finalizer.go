@@ -0,0 +1,85 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+)
+
+// EnsureFinalizer adds ManagedPolicyFinalizer to a policy created without it
+func (r *Reconciler) EnsureFinalizer(ctx context.Context, mp *policyv1alpha1.ManagedPolicy) error {
+	if controllerutil.ContainsFinalizer(mp, policyv1alpha1.ManagedPolicyFinalizer) {
+		return nil
+	}
+	patch := client.MergeFrom(mp.DeepCopy())
+	controllerutil.AddFinalizer(mp, policyv1alpha1.ManagedPolicyFinalizer)
+	if err := r.client.Patch(ctx, mp, patch); err != nil {
+		return fmt.Errorf("failed to add finalizer: %w", err)
+	}
+	return nil
+}
+
+// FinalizePolicy deletes the deployed resources of a policy being deleted,
+// reports it undeployed to SaaS and removes ManagedPolicyFinalizer. Resources
+// that could not be deleted stay in the status and are retried on the next
+// call; the policy is not released until they are gone.
+func (r *Reconciler) FinalizePolicy(ctx context.Context, mp *policyv1alpha1.ManagedPolicy) error {
+	if !controllerutil.ContainsFinalizer(mp, policyv1alpha1.ManagedPolicyFinalizer) {
+		return nil
+	}
+	log := r.log.WithValues("policy", mp.Name, "policyId", mp.Spec.PolicyID)
+	log.Info("Deleting policy resources", "resources", len(mp.Status.DeployedResources))
+
+	remaining, err := r.deployer.DeleteResources(ctx, mp.Status.DeployedResources)
+	if err != nil {
+		message := fmt.Sprintf("failed to delete %d resources: %v", len(remaining), err)
+		log.Error(err, "Failed to delete policy resources", "remaining", len(remaining))
+
+		// Report the failure once rather than on every retry
+		if mp.Status.LastError != message {
+			if reportErr := r.policySource().ReportUndeployStatus(ctx, mp.Spec.PolicyID, false, message); reportErr != nil {
+				log.Error(reportErr, "Failed to report undeploy failure")
+			}
+		}
+		if statusErr := r.mutatePolicyStatus(ctx, mp, func(status *policyv1alpha1.ManagedPolicyStatus) {
+			status.Phase = policyv1alpha1.ManagedPolicyPhaseDeleting
+			status.DeployedResources = remaining
+			status.LastError = message
+		}); statusErr != nil {
+			log.Error(statusErr, "Failed to update policy status")
+		}
+		return fmt.Errorf("failed to delete policy resources: %w", err)
+	}
+
+	if err := r.policySource().ReportUndeployStatus(ctx, mp.Spec.PolicyID, true, ""); err != nil {
+		log.Error(err, "Failed to report undeploy status")
+	}
+
+	patch := client.MergeFrom(mp.DeepCopy())
+	controllerutil.RemoveFinalizer(mp, policyv1alpha1.ManagedPolicyFinalizer)
+	if err := r.client.Patch(ctx, mp, patch); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to remove finalizer: %w", err)
+	}
+
+	log.Info("Deleted policy resources, released ManagedPolicy")
+	return nil
+}
+
+// deleteRemovedPolicy deletes a ManagedPolicy whose policy no longer exists in
+// SaaS. Policies created before the finalizer have their resources deleted
+// here; otherwise the finalizer deletes them.
+func (r *Reconciler) deleteRemovedPolicy(ctx context.Context, mp *policyv1alpha1.ManagedPolicy) {
+	if !controllerutil.ContainsFinalizer(mp, policyv1alpha1.ManagedPolicyFinalizer) {
+		if err := r.deployer.Delete(ctx, mp); err != nil {
+			r.log.Error(err, "Failed to delete policy resources", "name", mp.Name)
+		}
+	}
+	if err := r.client.Delete(ctx, mp); err != nil && !errors.IsNotFound(err) {
+		r.log.Error(err, "Failed to delete ManagedPolicy", "name", mp.Name)
+	}
+}
@@ -0,0 +1,151 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+	"github.com/policy-hub/operator/internal/policy"
+	"github.com/policy-hub/operator/internal/saas"
+)
+
+func TestFinalizePolicy(t *testing.T) {
+	var reports []saas.UpdatePolicyStatusRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req saas.UpdatePolicyStatusRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		reports = append(reports, req)
+		json.NewEncoder(w).Encode(saas.UpdatePolicyStatusResponse{Success: true})
+	}))
+	defer server.Close()
+
+	ccnp := schema.GroupVersionKind{Group: "cilium.io", Version: "v2", Kind: "CiliumClusterwideNetworkPolicy"}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(ccnp, meta.RESTScopeRoot)
+	mapper.Add(policyv1alpha1.GroupVersion.WithKind("ManagedPolicy"), meta.RESTScopeNamespace)
+
+	mp := &policyv1alpha1.ManagedPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-policy", Namespace: "policy-hub-system"},
+		Spec: policyv1alpha1.ManagedPolicySpec{
+			PolicyID:   "policy-1",
+			Name:       "Test Policy",
+			PolicyType: policyv1alpha1.PolicyTypeCiliumClusterwide,
+			Content: `apiVersion: cilium.io/v2
+kind: CiliumClusterwideNetworkPolicy
+metadata:
+  name: cluster-a
+spec:
+  endpointSelector: {}
+---
+apiVersion: cilium.io/v2
+kind: CiliumClusterwideNetworkPolicy
+metadata:
+  name: cluster-b
+spec:
+  endpointSelector: {}
+`,
+			Version: 1,
+		},
+	}
+
+	// Deleting cluster-b fails until failDelete is cleared
+	failDelete := true
+	c := fake.NewClientBuilder().
+		WithScheme(testScheme()).
+		WithRESTMapper(mapper).
+		WithObjects(mp).
+		WithStatusSubresource(&policyv1alpha1.ManagedPolicy{}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+				if failDelete && obj.GetName() == "cluster-b" {
+					return fmt.Errorf("connection refused")
+				}
+				return c.Delete(ctx, obj, opts...)
+			},
+		}).
+		Build()
+
+	r := NewReconciler(c, testLogger())
+	r.deployer = policy.NewDeployer(c, testLogger())
+	r.saasClient = saas.NewClient(server.URL, "test-token", "cluster-id", testLogger())
+	ctx := context.Background()
+	key := client.ObjectKeyFromObject(mp)
+
+	getPolicy := func() *policyv1alpha1.ManagedPolicy {
+		t.Helper()
+		mp := &policyv1alpha1.ManagedPolicy{}
+		if err := c.Get(ctx, key, mp); err != nil {
+			t.Fatalf("Failed to get policy: %v", err)
+		}
+		return mp
+	}
+
+	if err := r.ReconcilePolicy(ctx, getPolicy()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if err := r.EnsureFinalizer(ctx, getPolicy()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !controllerutil.ContainsFinalizer(getPolicy(), policyv1alpha1.ManagedPolicyFinalizer) {
+		t.Fatal("Expected the finalizer to be added")
+	}
+	if err := c.Delete(ctx, getPolicy()); err != nil {
+		t.Fatalf("Failed to delete policy: %v", err)
+	}
+	reported := len(reports)
+
+	// The resource that failed to delete is kept and the policy is held
+	if err := r.FinalizePolicy(ctx, getPolicy()); err == nil {
+		t.Fatal("Expected an error when a resource cannot be deleted")
+	}
+	held := getPolicy()
+	if held.Status.Phase != policyv1alpha1.ManagedPolicyPhaseDeleting ||
+		len(held.Status.DeployedResources) != 1 || held.Status.DeployedResources[0].Name != "cluster-b" {
+		t.Errorf("Expected phase Deleting with cluster-b remaining, got %s %+v", held.Status.Phase, held.Status.DeployedResources)
+	}
+	if !controllerutil.ContainsFinalizer(held, policyv1alpha1.ManagedPolicyFinalizer) {
+		t.Error("Expected the finalizer to be kept")
+	}
+	if len(reports) != reported+1 || reports[reported].Status != "FAILED" {
+		t.Errorf("Expected a FAILED report, got %+v", reports[reported:])
+	}
+
+	// Retrying with the same failure does not report again
+	if err := r.FinalizePolicy(ctx, getPolicy()); err == nil {
+		t.Fatal("Expected an error when a resource cannot be deleted")
+	}
+	if len(reports) != reported+1 {
+		t.Errorf("Expected the failure to be reported once, got %+v", reports[reported:])
+	}
+
+	failDelete = false
+	if err := r.FinalizePolicy(ctx, getPolicy()); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if last := reports[len(reports)-1]; last.Status != "UNDEPLOYED" {
+		t.Errorf("Expected UNDEPLOYED report, got %+v", last)
+	}
+	if err := c.Get(ctx, key, &policyv1alpha1.ManagedPolicy{}); !errors.IsNotFound(err) {
+		t.Errorf("Expected the policy to be released, got: %v", err)
+	}
+	for _, name := range []string{"cluster-a", "cluster-b"} {
+		obj := &metav1.PartialObjectMetadata{}
+		obj.SetGroupVersionKind(ccnp)
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, obj); !errors.IsNotFound(err) {
+			t.Errorf("Expected %s to be deleted, got: %v", name, err)
+		}
+	}
+}
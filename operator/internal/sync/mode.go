@@ -0,0 +1,146 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+	"github.com/policy-hub/operator/internal/policy"
+	"github.com/policy-hub/operator/internal/saas"
+)
+
+// policyMode returns the mode of a SaaS policy, enforcing by default
+func policyMode(saasPolicy saas.Policy) policyv1alpha1.PolicyMode {
+	if policyv1alpha1.PolicyMode(saasPolicy.Mode) == policyv1alpha1.PolicyModeAudit {
+		return policyv1alpha1.PolicyModeAudit
+	}
+	return policyv1alpha1.PolicyModeEnforce
+}
+
+// modeChanged returns true if the deployed resources run in a different mode
+// than the spec asks for
+func modeChanged(mp *policyv1alpha1.ManagedPolicy) bool {
+	return mp.IsAudited() != (mp.Status.Mode == policyv1alpha1.PolicyModeAudit)
+}
+
+// auditRefused returns true if the policy is in audit mode but its resources
+// cannot be audited, such as network policies once Cilium stops auditing
+func (r *Reconciler) auditRefused(ctx context.Context, mp *policyv1alpha1.ManagedPolicy) bool {
+	return mp.IsAudited() && errors.Is(r.deployer.ValidatePolicy(ctx, mp), policy.ErrAuditModeUnsupported)
+}
+
+// setDeployedMode records the mode the policy's resources were deployed in.
+// AuditStarted is kept across versions deployed in audit mode.
+func setDeployedMode(status *policyv1alpha1.ManagedPolicyStatus, mp *policyv1alpha1.ManagedPolicy, now metav1.Time) {
+	if !mp.IsAudited() {
+		status.Mode = policyv1alpha1.PolicyModeEnforce
+		status.AuditStarted = nil
+		if existing := meta.FindStatusCondition(status.Conditions, ConditionTypeEnforced); existing != nil &&
+			existing.Status != metav1.ConditionTrue {
+			setCondition(&status.Conditions, metav1.Condition{
+				Type:               ConditionTypeEnforced,
+				Status:             metav1.ConditionTrue,
+				Reason:             "Enforced",
+				Message:            "Policy resources are enforced",
+				ObservedGeneration: mp.Generation,
+				LastTransitionTime: now,
+			})
+		}
+		return
+	}
+
+	if status.Mode != policyv1alpha1.PolicyModeAudit || status.AuditStarted == nil {
+		status.AuditStarted = &now
+	}
+	status.Mode = policyv1alpha1.PolicyModeAudit
+	if !meta.IsStatusConditionFalse(status.Conditions, ConditionTypeEnforced) {
+		setCondition(&status.Conditions, metav1.Condition{
+			Type:               ConditionTypeEnforced,
+			Status:             metav1.ConditionFalse,
+			Reason:             "AuditMode",
+			Message:            "Policy resources are deployed in audit mode: what they would block is reported, not blocked",
+			ObservedGeneration: mp.Generation,
+			LastTransitionTime: now,
+		})
+	}
+}
+
+// reconcileMode redeploys the deployed version of a policy whose mode changed,
+// promoting it from audit to enforce or moving it back to audit
+func (r *Reconciler) reconcileMode(ctx context.Context, mp *policyv1alpha1.ManagedPolicy) error {
+	log := r.log.WithValues("policy", mp.Name, "policyId", mp.Spec.PolicyID)
+	promoted := !mp.IsAudited()
+	log.Info("Policy mode changed, redeploying", "mode", mp.Spec.Mode, "version", mp.Spec.Version)
+
+	// The mode is signed with the policy, so a mode change must verify too
+	if verified, err := r.verifySignature(ctx, mp); !verified || err != nil {
+		return err
+	}
+
+	// Flows that would have been dropped while auditing are reported with the
+	// promotion
+	var auditedFlows int64
+	auditStarted := mp.Status.AuditStarted
+	if counter := r.flowCounter(); promoted && counter != nil && auditStarted != nil {
+		auditedFlows = counter.AuditedFlows(deployedNamespaces(mp), auditStarted.Time, time.Now())
+	}
+
+	result := r.deployer.Deploy(ctx, mp)
+	if !result.Success {
+		log.Error(result.Error, "Failed to redeploy policy in new mode")
+		if err := r.mutatePolicyStatus(ctx, mp, func(status *policyv1alpha1.ManagedPolicyStatus) {
+			status.LastError = fmt.Sprintf("failed to change mode to %s: %v", mp.Spec.Mode, result.Error)
+		}); err != nil {
+			log.Error(err, "Failed to update policy status")
+		}
+		return fmt.Errorf("failed to change policy mode: %w", result.Error)
+	}
+
+	now := metav1.Now()
+	if err := r.mutatePolicyStatus(ctx, mp, func(status *policyv1alpha1.ManagedPolicyStatus) {
+		status.DeployedResources = result.DeployedResources
+		status.Namespaces = result.Namespaces
+		status.Targets = targetStatuses(status.Targets, result.Targets, mp)
+		status.LastError = ""
+		status.LastDeployed = &now
+		status.ObservedGeneration = mp.Generation
+		setDeployedMode(status, mp, now)
+		if promoted {
+			message := "Promoted from audit to enforce"
+			if auditStarted != nil {
+				message = fmt.Sprintf("Promoted from audit to enforce after auditing since %s; %d flows would have been dropped",
+					auditStarted.UTC().Format(time.RFC3339), auditedFlows)
+			}
+			setCondition(&status.Conditions, metav1.Condition{
+				Type:               ConditionTypeEnforced,
+				Status:             metav1.ConditionTrue,
+				Reason:             "Promoted",
+				Message:            message,
+				ObservedGeneration: mp.Generation,
+				LastTransitionTime: now,
+			})
+		}
+	}); err != nil {
+		return err
+	}
+
+	_, err := r.policySource().UpdatePolicyStatus(ctx, mp.Spec.PolicyID, saas.UpdatePolicyStatusRequest{
+		Status:            "DEPLOYED",
+		DeployedResources: toSaaSResources(result.DeployedResources),
+		Version:           mp.Spec.Version,
+		Mode:              string(mp.Status.Mode),
+		AuditedFlows:      auditedFlows,
+		Targets:           toSaaSTargets(mp.Status.Targets),
+	})
+	if err != nil {
+		log.Error(err, "Failed to report mode change to SaaS")
+	}
+
+	log.Info("Changed policy mode", "mode", mp.Status.Mode, "auditedFlows", auditedFlows)
+	return nil
+}
@@ -0,0 +1,235 @@
+package sync
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+	"github.com/policy-hub/operator/internal/policy"
+	"github.com/policy-hub/operator/internal/saas"
+)
+
+func TestPolicyMode(t *testing.T) {
+	tests := []struct {
+		mode string
+		want policyv1alpha1.PolicyMode
+	}{
+		{mode: "audit", want: policyv1alpha1.PolicyModeAudit},
+		{mode: "enforce", want: policyv1alpha1.PolicyModeEnforce},
+		{mode: "", want: policyv1alpha1.PolicyModeEnforce},
+		{mode: "unknown", want: policyv1alpha1.PolicyModeEnforce},
+	}
+
+	for _, tt := range tests {
+		if got := policyMode(saas.Policy{Mode: tt.mode}); got != tt.want {
+			t.Errorf("policyMode(%q) = %q, want %q", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestReconcileMode(t *testing.T) {
+	var reports []saas.UpdatePolicyStatusRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req saas.UpdatePolicyStatusRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		reports = append(reports, req)
+		json.NewEncoder(w).Encode(saas.UpdatePolicyStatusResponse{Success: true})
+	}))
+	defer server.Close()
+
+	cnp := schema.GroupVersionKind{Group: "cilium.io", Version: "v2", Kind: "CiliumNetworkPolicy"}
+	tp := schema.GroupVersionKind{Group: "cilium.io", Version: "v1alpha1", Kind: "TracingPolicy"}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(cnp, meta.RESTScopeNamespace)
+	mapper.Add(tp, meta.RESTScopeNamespace)
+	mapper.Add(policyv1alpha1.GroupVersion.WithKind("ManagedPolicy"), meta.RESTScopeNamespace)
+
+	mp := &policyv1alpha1.ManagedPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-policy", Namespace: "policy-hub-system"},
+		Spec: policyv1alpha1.ManagedPolicySpec{
+			PolicyID:   "policy-1",
+			Name:       "Test Policy",
+			PolicyType: policyv1alpha1.PolicyTypeTetragon,
+			Content: `apiVersion: cilium.io/v1alpha1
+kind: TracingPolicy
+metadata:
+  name: block-shell
+spec:
+  kprobes:
+  - call: sys_execve
+    selectors:
+    - matchActions:
+      - action: Sigkill
+`,
+			Version: 1,
+			Mode:    policyv1alpha1.PolicyModeAudit,
+		},
+	}
+	networkPolicy := &policyv1alpha1.ManagedPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "network-policy", Namespace: "policy-hub-system", Generation: 1},
+		Spec: policyv1alpha1.ManagedPolicySpec{
+			PolicyID:   "policy-2",
+			Name:       "Network Policy",
+			PolicyType: policyv1alpha1.PolicyTypeCiliumNetwork,
+			Content: `apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: api
+spec:
+  endpointSelector: {}
+`,
+			Version: 1,
+		},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(testScheme()).
+		WithRESTMapper(mapper).
+		WithObjects(mp, networkPolicy).
+		WithStatusSubresource(&policyv1alpha1.ManagedPolicy{}).
+		Build()
+
+	r := NewReconciler(c, testLogger())
+	r.deployer = policy.NewDeployer(c, testLogger())
+	r.saasClient = saas.NewClient(server.URL, "test-token", "cluster-id", testLogger())
+	r.SetFlowCounter(&fakeFlowCounter{audited: 7})
+
+	deployedAction := func() string {
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(tp)
+		if err := c.Get(context.Background(), client.ObjectKey{Name: "block-shell", Namespace: "policy-hub-system"}, live); err != nil {
+			t.Fatalf("Failed to get deployed resource: %v", err)
+		}
+		kprobes, _, _ := unstructured.NestedSlice(live.Object, "spec", "kprobes")
+		selectors, _, _ := unstructured.NestedSlice(kprobes[0].(map[string]interface{}), "selectors")
+		actions, _, _ := unstructured.NestedSlice(selectors[0].(map[string]interface{}), "matchActions")
+		return actions[0].(map[string]interface{})["action"].(string)
+	}
+
+	// Deploy in audit mode
+	if err := r.ReconcilePolicy(context.Background(), mp); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if mp.Status.Mode != policyv1alpha1.PolicyModeAudit || mp.Status.AuditStarted == nil {
+		t.Errorf("Expected audit mode with a start time, got mode %q started %v", mp.Status.Mode, mp.Status.AuditStarted)
+	}
+	if cond := meta.FindStatusCondition(mp.Status.Conditions, ConditionTypeEnforced); cond == nil || cond.Status != metav1.ConditionFalse {
+		t.Errorf("Expected Enforced condition False, got %+v", cond)
+	}
+	if got := deployedAction(); got != "Post" {
+		t.Errorf("Expected deployed policy in audit mode, got action %q", got)
+	}
+	if last := reports[len(reports)-1]; last.Status != "DEPLOYED" || last.Mode != "audit" {
+		t.Errorf("Expected DEPLOYED report in audit mode, got %+v", last)
+	}
+
+	// Promote to enforce without a version bump
+	mp.Spec.Mode = policyv1alpha1.PolicyModeEnforce
+	if err := c.Update(context.Background(), mp); err != nil {
+		t.Fatalf("Failed to update policy: %v", err)
+	}
+	if err := r.ReconcilePolicy(context.Background(), mp); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if mp.Status.Mode != policyv1alpha1.PolicyModeEnforce || mp.Status.AuditStarted != nil {
+		t.Errorf("Expected enforce mode without a start time, got mode %q started %v", mp.Status.Mode, mp.Status.AuditStarted)
+	}
+	cond := meta.FindStatusCondition(mp.Status.Conditions, ConditionTypeEnforced)
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != "Promoted" {
+		t.Errorf("Expected Enforced condition True with reason Promoted, got %+v", cond)
+	}
+	if got := deployedAction(); got != "Sigkill" {
+		t.Errorf("Expected the enforcing action to be deployed, got %q", got)
+	}
+	last := reports[len(reports)-1]
+	if last.Status != "DEPLOYED" || last.Mode != "enforce" || last.AuditedFlows != 7 {
+		t.Errorf("Expected DEPLOYED report in enforce mode with 7 audited flows, got %+v", last)
+	}
+
+	// Nothing changes once promoted
+	reported := len(reports)
+	if err := r.ReconcilePolicy(context.Background(), mp); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(reports) != reported {
+		t.Errorf("Expected no further reports, got %+v", reports[reported:])
+	}
+
+	t.Run("network policy moved to audit fails", func(t *testing.T) {
+		if err := r.ReconcilePolicy(context.Background(), networkPolicy); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		networkPolicy.Spec.Mode = policyv1alpha1.PolicyModeAudit
+		networkPolicy.Generation++
+		if err := c.Update(context.Background(), networkPolicy); err != nil {
+			t.Fatalf("Failed to update policy: %v", err)
+		}
+		if err := r.ReconcilePolicy(context.Background(), networkPolicy); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if err := c.Get(context.Background(), client.ObjectKeyFromObject(networkPolicy), networkPolicy); err != nil {
+			t.Fatalf("Failed to get policy: %v", err)
+		}
+		if networkPolicy.Status.Phase != policyv1alpha1.ManagedPolicyPhaseFailed ||
+			networkPolicy.Status.Mode != policyv1alpha1.PolicyModeEnforce {
+			t.Errorf("Expected phase Failed with the deployed version enforced, got phase %s mode %q",
+				networkPolicy.Status.Phase, networkPolicy.Status.Mode)
+		}
+		if last := reports[len(reports)-1]; last.Status != "FAILED" || last.Mode == "audit" {
+			t.Errorf("Expected FAILED report, got %+v", last)
+		}
+	})
+
+	t.Run("network policy deployed in audit mode fails", func(t *testing.T) {
+		// As deployed before network policies were refused
+		if err := r.mutatePolicyStatus(context.Background(), networkPolicy, func(status *policyv1alpha1.ManagedPolicyStatus) {
+			status.Phase = policyv1alpha1.ManagedPolicyPhaseDeployed
+			status.Mode = policyv1alpha1.PolicyModeAudit
+		}); err != nil {
+			t.Fatalf("Failed to update status: %v", err)
+		}
+		if err := r.ReconcilePolicy(context.Background(), networkPolicy); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if err := c.Get(context.Background(), client.ObjectKeyFromObject(networkPolicy), networkPolicy); err != nil {
+			t.Fatalf("Failed to get policy: %v", err)
+		}
+		if networkPolicy.Status.Phase != policyv1alpha1.ManagedPolicyPhaseFailed {
+			t.Errorf("Expected phase Failed, got %s", networkPolicy.Status.Phase)
+		}
+	})
+
+	t.Run("mode change must be signed", func(t *testing.T) {
+		signingKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+		r.config = trustedKeyConfig(signingKey)
+		defer func() { r.config = nil }()
+
+		mp.Spec.Mode = policyv1alpha1.PolicyModeAudit
+		mp.Generation++
+		if err := c.Update(context.Background(), mp); err != nil {
+			t.Fatalf("Failed to update policy: %v", err)
+		}
+		if err := r.ReconcilePolicy(context.Background(), mp); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if err := c.Get(context.Background(), client.ObjectKeyFromObject(mp), mp); err != nil {
+			t.Fatalf("Failed to get policy: %v", err)
+		}
+		if mp.Status.Phase != policyv1alpha1.ManagedPolicyPhaseFailed || mp.Status.Mode != policyv1alpha1.PolicyModeEnforce {
+			t.Errorf("Expected phase Failed with the policy enforced, got phase %s mode %q", mp.Status.Phase, mp.Status.Mode)
+		}
+		if got := deployedAction(); got != "Sigkill" {
+			t.Errorf("Expected the unsigned mode change not to be deployed, got action %q", got)
+		}
+	})
+}
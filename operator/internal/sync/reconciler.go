@@ -3,19 +3,24 @@ package sync
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
 	"github.com/policy-hub/operator/internal/policy"
+	"github.com/policy-hub/operator/internal/policy/lint"
 	"github.com/policy-hub/operator/internal/saas"
 )
 
@@ -23,9 +28,13 @@ const (
 	OperatorVersion = "1.1.0"
 
 	// Condition types
-	ConditionTypeRegistered = "Registered"
-	ConditionTypeSynced     = "Synced"
-	ConditionTypeHealthy    = "Healthy"
+	ConditionTypeRegistered        = "Registered"
+	ConditionTypeSynced            = "Synced"
+	ConditionTypeHealthy           = "Healthy"
+	ConditionTypeDrifted           = "Drifted"
+	ConditionTypeRolledBack        = "RolledBack"
+	ConditionTypeEnforced          = "Enforced"
+	ConditionTypeSignatureVerified = "SignatureVerified"
 )
 
 // Reconciler handles synchronization between SaaS and cluster
@@ -33,6 +42,7 @@ type Reconciler struct {
 	client        client.Client
 	saasClient    *saas.Client
 	deployer      *policy.Deployer
+	linter        *lint.Linter
 	log           logr.Logger
 	config        *policyv1alpha1.PolicyHubConfig
 	operatorID    string
@@ -40,12 +50,24 @@ type Reconciler struct {
 	lastSync      time.Time
 	lastHeartbeat time.Time
 	statusMu      sync.Mutex // Serializes status updates to prevent conflicts
+
+	flowCounts FlowCounter // Checked by staged rollouts and audit mode promotions
+	countersMu sync.Mutex
+
+	outbox *saas.Outbox      // Queues status reports the SaaS platform did not receive
+	events *saas.EventStream // Notifies of changes in the SaaS platform
+
+	lastPolicies *saas.FetchPoliciesResponse // Reused while SaaS reports the policies not modified
+	syncMu       sync.Mutex                  // Serializes policy syncs, which share lastPolicies
+
+	source PolicySource // Replaces the SaaS client as policy source when set
 }
 
 // NewReconciler creates a new sync reconciler
 func NewReconciler(c client.Client, log logr.Logger) *Reconciler {
 	return &Reconciler{
 		client: c,
+		linter: lint.NewLinter(c, log),
 		log:    log.WithName("sync-reconciler"),
 	}
 }
@@ -53,6 +75,11 @@ func NewReconciler(c client.Client, log logr.Logger) *Reconciler {
 // Initialize sets up the reconciler with configuration
 func (r *Reconciler) Initialize(ctx context.Context, config *policyv1alpha1.PolicyHubConfig) error {
 	r.config = config
+	if config.Offline() {
+		return r.initializeOffline(config)
+	}
+	r.source = nil
+
 	r.log.Info("Initializing reconciler",
 		"saasEndpoint", config.Spec.SaaSEndpoint,
 		"clusterId", config.Spec.ClusterID,
@@ -95,11 +122,10 @@ func (r *Reconciler) Initialize(ctx context.Context, config *policyv1alpha1.Poli
 	}
 
 	// Create SaaS client
-	r.saasClient = saas.NewClient(
+	r.saasClient = r.newSaaSClient(
 		config.Spec.SaaSEndpoint,
 		apiToken,
 		clusterID,
-		r.log,
 	)
 
 	// Create policy deployer
@@ -108,6 +134,35 @@ func (r *Reconciler) Initialize(ctx context.Context, config *policyv1alpha1.Poli
 	return nil
 }
 
+// initializeOffline sets up the reconciler to sync policies from a directory
+// without contacting the SaaS platform
+func (r *Reconciler) initializeOffline(config *policyv1alpha1.PolicyHubConfig) error {
+	ps := config.Spec.PolicySource
+	if ps.Path == "" {
+		return fmt.Errorf("policySource.path is required for the %s policy source", ps.Type)
+	}
+	reportName := ps.ReportConfigMap
+	if reportName == "" {
+		reportName = "policy-hub-report"
+	}
+	report := types.NamespacedName{Namespace: config.Namespace, Name: reportName}
+
+	// Keep the source, and the report updates it serializes, across reconciles
+	if ds, ok := r.source.(*DirectorySource); !ok || ds.path != ps.Path || ds.report != report {
+		r.log.Info("Initializing reconciler with directory policy source",
+			"path", ps.Path,
+			"report", report)
+		r.source = NewDirectorySource(ps.Path, r.client, report, r.log)
+	}
+	r.saasClient = nil
+	r.registered = true // Nothing to register with
+
+	// Create policy deployer
+	r.deployer = policy.NewDeployer(r.client, r.log)
+
+	return nil
+}
+
 // initializeFromBootstrappedState sets up the reconciler after bootstrap has already completed
 func (r *Reconciler) initializeFromBootstrappedState(ctx context.Context, config *policyv1alpha1.PolicyHubConfig) error {
 	// Get the cluster token that was stored during bootstrap
@@ -117,11 +172,10 @@ func (r *Reconciler) initializeFromBootstrappedState(ctx context.Context, config
 	}
 
 	// Create SaaS client with the stored cluster token
-	r.saasClient = saas.NewClient(
+	r.saasClient = r.newSaaSClient(
 		config.Spec.SaaSEndpoint,
 		clusterToken,
 		config.Status.ClusterID,
-		r.log,
 	)
 
 	r.operatorID = config.Status.OperatorID
@@ -255,11 +309,10 @@ func (r *Reconciler) bootstrap(ctx context.Context, config *policyv1alpha1.Polic
 	}
 
 	// Now use the cluster token for the SaaS client
-	r.saasClient = saas.NewClient(
+	r.saasClient = r.newSaaSClient(
 		config.Spec.SaaSEndpoint,
 		resp.ClusterToken,
 		resp.Cluster.ID,
-		r.log,
 	)
 
 	r.operatorID = resp.Cluster.OperatorID
@@ -348,15 +401,31 @@ func (r *Reconciler) Register(ctx context.Context) error {
 
 // SyncPolicies synchronizes policies from the SaaS platform
 func (r *Reconciler) SyncPolicies(ctx context.Context) error {
+	// Syncs run from the config reconcile and from the background sync loop
+	r.syncMu.Lock()
+	defer r.syncMu.Unlock()
+
 	r.log.V(1).Info("Starting policy sync")
 
-	// Fetch policies from SaaS
-	resp, err := r.saasClient.FetchPolicies(ctx)
+	// Fetch policies from SaaS. When they did not change since the last
+	// fetch, the policies in the cluster are still checked against them.
+	etag := ""
+	if r.lastPolicies != nil {
+		etag = r.lastPolicies.ETag
+	}
+	resp, err := r.policySource().FetchPoliciesIfChanged(ctx, etag)
 	if err != nil {
 		return fmt.Errorf("failed to fetch policies: %w", err)
 	}
-
-	r.log.Info("Fetched policies from SaaS", "count", resp.Count)
+	notModified := resp.NotModified
+	if notModified {
+		resp = r.lastPolicies
+		r.log.V(1).Info("Policies not modified in SaaS", "count", resp.Count)
+	} else {
+		r.lastPolicies = resp
+		r.log.Info("Fetched policies from SaaS", "count", resp.Count)
+	}
+	summary := &policyv1alpha1.SyncSummary{NotModified: notModified}
 
 	// Get existing ManagedPolicies
 	existingPolicies := &policyv1alpha1.ManagedPolicyList{}
@@ -380,53 +449,134 @@ func (r *Reconciler) SyncPolicies(ctx context.Context) error {
 
 		// Handle UNDEPLOY action
 		if saasPolicy.Action == "UNDEPLOY" {
+			if found {
+				summary.Removed++
+			}
 			r.handleUndeploy(ctx, saasPolicy, existing)
 			continue
 		}
 
+		// Content that does not match the hash sent with it was corrupted
+		contentHash := policy.ContentHash(saasPolicy.Content)
+		if saasPolicy.ContentHash != "" && saasPolicy.ContentHash != contentHash {
+			r.log.Info("Skipping policy whose content does not match its hash",
+				"name", saasPolicy.Name,
+				"contentHash", saasPolicy.ContentHash)
+			summary.Failed++
+			_, _ = r.policySource().UpdatePolicyStatus(ctx, saasPolicy.ID, saas.UpdatePolicyStatusRequest{
+				Status:  "FAILED",
+				Error:   fmt.Sprintf("content does not match content hash %s", saasPolicy.ContentHash),
+				Version: saasPolicy.Version,
+			})
+			continue
+		}
+
 		if found {
-			// Check if update needed
-			if existing.Spec.Version < saasPolicy.Version {
+			existingHash := existing.Spec.ContentHash
+			if existingHash == "" {
+				existingHash = policy.ContentHash(existing.Spec.Content)
+			}
+
+			// Check if update needed. Content is compared by hash, so it is
+			// updated even without a version bump.
+			if existing.Spec.Version < saasPolicy.Version ||
+				(existing.Spec.Version == saasPolicy.Version && existingHash != contentHash) {
 				r.log.Info("Updating policy",
 					"name", saasPolicy.Name,
 					"oldVersion", existing.Spec.Version,
-					"newVersion", saasPolicy.Version)
+					"newVersion", saasPolicy.Version,
+					"contentHash", contentHash)
 
 				// Update the ManagedPolicy
 				existing.Spec.Content = saasPolicy.Content
+				existing.Spec.ContentHash = contentHash
 				existing.Spec.Version = saasPolicy.Version
 				existing.Spec.TargetNamespaces = saasPolicy.TargetNamespaces
 				existing.Spec.Description = saasPolicy.Description
+				existing.Spec.DriftMode = driftMode(saasPolicy)
+				existing.Spec.Rollout = rolloutStrategy(saasPolicy)
+				existing.Spec.Mode = policyMode(saasPolicy)
+				existing.Spec.NamespaceSelector = namespaceSelector(saasPolicy)
+				existing.Spec.Signature = saasPolicy.Signature
+				existing.Spec.SignatureKeyID = saasPolicy.SignatureKeyID
 
 				if err := r.client.Update(ctx, existing); err != nil {
 					r.log.Error(err, "Failed to update ManagedPolicy", "name", saasPolicy.Name)
+					r.reportRejectedPolicy(ctx, saasPolicy, err)
+					summary.Failed++
 					continue
 				}
+				summary.Updated++
+				continue
+			}
+
+			// Drift mode, rollout, mode, namespace selector and signature
+			// changes apply without a version bump
+			if existing.Spec.Version >= saasPolicy.Version && (existing.Spec.DriftMode != driftMode(saasPolicy) ||
+				!equality.Semantic.DeepEqual(existing.Spec.Rollout, rolloutStrategy(saasPolicy)) ||
+				existing.Spec.Mode != policyMode(saasPolicy) ||
+				!equality.Semantic.DeepEqual(existing.Spec.NamespaceSelector, namespaceSelector(saasPolicy)) ||
+				existing.Spec.Signature != saasPolicy.Signature ||
+				existing.Spec.SignatureKeyID != saasPolicy.SignatureKeyID) {
+				existing.Spec.DriftMode = driftMode(saasPolicy)
+				existing.Spec.Rollout = rolloutStrategy(saasPolicy)
+				existing.Spec.Mode = policyMode(saasPolicy)
+				existing.Spec.NamespaceSelector = namespaceSelector(saasPolicy)
+				existing.Spec.Signature = saasPolicy.Signature
+				existing.Spec.SignatureKeyID = saasPolicy.SignatureKeyID
+				existing.Spec.ContentHash = existingHash
+				if err := r.client.Update(ctx, existing); err != nil {
+					r.log.Error(err, "Failed to update ManagedPolicy drift mode and rollout", "name", saasPolicy.Name)
+					summary.Failed++
+					continue
+				}
+				summary.Updated++
+				continue
 			}
+
+			// Record the content hash of policies created before it was set
+			if existing.Spec.ContentHash == "" {
+				existing.Spec.ContentHash = existingHash
+				if err := r.client.Update(ctx, existing); err != nil {
+					r.log.Error(err, "Failed to record ManagedPolicy content hash", "name", saasPolicy.Name)
+				}
+			}
+			summary.Unchanged++
 		} else {
 			// Create new ManagedPolicy
 			r.log.Info("Creating new policy", "name", saasPolicy.Name)
 
 			mp := &policyv1alpha1.ManagedPolicy{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      sanitizeName(saasPolicy.Name),
-					Namespace: r.config.Namespace,
+					Name:       sanitizeName(saasPolicy.Name),
+					Namespace:  r.config.Namespace,
+					Finalizers: []string{policyv1alpha1.ManagedPolicyFinalizer},
 				},
 				Spec: policyv1alpha1.ManagedPolicySpec{
-					PolicyID:         saasPolicy.ID,
-					Name:             saasPolicy.Name,
-					Description:      saasPolicy.Description,
-					PolicyType:       policyv1alpha1.PolicyType(saasPolicy.Type),
-					Content:          saasPolicy.Content,
-					TargetNamespaces: saasPolicy.TargetNamespaces,
-					Version:          saasPolicy.Version,
+					PolicyID:          saasPolicy.ID,
+					Name:              saasPolicy.Name,
+					Description:       saasPolicy.Description,
+					PolicyType:        policyv1alpha1.PolicyType(saasPolicy.Type),
+					Content:           saasPolicy.Content,
+					ContentHash:       contentHash,
+					TargetNamespaces:  saasPolicy.TargetNamespaces,
+					Version:           saasPolicy.Version,
+					DriftMode:         driftMode(saasPolicy),
+					Rollout:           rolloutStrategy(saasPolicy),
+					Mode:              policyMode(saasPolicy),
+					NamespaceSelector: namespaceSelector(saasPolicy),
+					Signature:         saasPolicy.Signature,
+					SignatureKeyID:    saasPolicy.SignatureKeyID,
 				},
 			}
 
 			if err := r.client.Create(ctx, mp); err != nil {
 				r.log.Error(err, "Failed to create ManagedPolicy", "name", saasPolicy.Name)
+				r.reportRejectedPolicy(ctx, saasPolicy, err)
+				summary.Failed++
 				continue
 			}
+			summary.Added++
 		}
 	}
 
@@ -434,16 +584,8 @@ func (r *Reconciler) SyncPolicies(ctx context.Context) error {
 	for id, existing := range existingByID {
 		if !saasIDs[id] {
 			r.log.Info("Deleting removed policy", "name", existing.Name)
-
-			// Delete deployed resources first
-			if err := r.deployer.Delete(ctx, existing); err != nil {
-				r.log.Error(err, "Failed to delete policy resources", "name", existing.Name)
-			}
-
-			// Delete the ManagedPolicy
-			if err := r.client.Delete(ctx, existing); err != nil {
-				r.log.Error(err, "Failed to delete ManagedPolicy", "name", existing.Name)
-			}
+			r.deleteRemovedPolicy(ctx, existing)
+			summary.Removed++
 		}
 	}
 
@@ -455,20 +597,52 @@ func (r *Reconciler) SyncPolicies(ctx context.Context) error {
 		now := metav1.Now()
 		status.LastSync = &now
 		status.ManagedPolicies = resp.Count
+		status.LastSyncSummary = summary
 		setCondition(&status.Conditions, metav1.Condition{
-			Type:               ConditionTypeSynced,
-			Status:             metav1.ConditionTrue,
-			Reason:             "SyncSucceeded",
-			Message:            fmt.Sprintf("Synced %d policies", resp.Count),
+			Type:   ConditionTypeSynced,
+			Status: metav1.ConditionTrue,
+			Reason: "SyncSucceeded",
+			Message: fmt.Sprintf("Synced %d policies: %d added, %d updated, %d removed, %d unchanged",
+				resp.Count, summary.Added, summary.Updated, summary.Removed, summary.Unchanged),
 			LastTransitionTime: metav1.Now(),
 		})
 	}); err != nil {
 		r.log.Error(err, "Failed to update config status after sync")
 	}
 
+	if summary.Added+summary.Updated+summary.Removed+summary.Failed > 0 {
+		r.log.Info("Synced policies",
+			"added", summary.Added,
+			"updated", summary.Updated,
+			"removed", summary.Removed,
+			"unchanged", summary.Unchanged,
+			"failed", summary.Failed)
+	}
+
 	return nil
 }
 
+// driftMode returns the drift mode of a SaaS policy, reverting drift by default
+func driftMode(saasPolicy saas.Policy) policyv1alpha1.DriftMode {
+	if policyv1alpha1.DriftMode(saasPolicy.DriftMode) == policyv1alpha1.DriftModeReport {
+		return policyv1alpha1.DriftModeReport
+	}
+	return policyv1alpha1.DriftModeRevert
+}
+
+// reportRejectedPolicy reports a policy the admission webhook rejected as
+// failed to SaaS, since it never reaches ReconcilePolicy
+func (r *Reconciler) reportRejectedPolicy(ctx context.Context, saasPolicy saas.Policy, err error) {
+	if !errors.IsInvalid(err) {
+		return
+	}
+	_, _ = r.policySource().UpdatePolicyStatus(ctx, saasPolicy.ID, saas.UpdatePolicyStatusRequest{
+		Status:  "FAILED",
+		Error:   err.Error(),
+		Version: saasPolicy.Version,
+	})
+}
+
 // handleUndeploy removes a policy from the cluster and reports status back to SaaS
 func (r *Reconciler) handleUndeploy(ctx context.Context, saasPolicy saas.Policy, existing *policyv1alpha1.ManagedPolicy) {
 	log := r.log.WithValues("policy", saasPolicy.Name, "policyId", saasPolicy.ID)
@@ -477,16 +651,30 @@ func (r *Reconciler) handleUndeploy(ctx context.Context, saasPolicy saas.Policy,
 	if existing == nil {
 		// Policy doesn't exist locally, report success
 		log.Info("Policy not found locally, reporting as undeployed")
-		if err := r.saasClient.ReportUndeployStatus(ctx, saasPolicy.ID, true, ""); err != nil {
+		if err := r.policySource().ReportUndeployStatus(ctx, saasPolicy.ID, true, ""); err != nil {
 			log.Error(err, "Failed to report undeploy status")
 		}
 		return
 	}
 
+	// The finalizer deletes the deployed resources and reports the policy
+	// undeployed once they are gone
+	if controllerutil.ContainsFinalizer(existing, policyv1alpha1.ManagedPolicyFinalizer) {
+		if err := r.client.Delete(ctx, existing); err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to delete ManagedPolicy")
+			if reportErr := r.policySource().ReportUndeployStatus(ctx, saasPolicy.ID, false, err.Error()); reportErr != nil {
+				log.Error(reportErr, "Failed to report undeploy failure")
+			}
+			return
+		}
+		log.Info("Deleted ManagedPolicy, its finalizer undeploys the policy")
+		return
+	}
+
 	// Delete deployed resources from cluster
 	if err := r.deployer.Delete(ctx, existing); err != nil {
 		log.Error(err, "Failed to delete policy resources")
-		if reportErr := r.saasClient.ReportUndeployStatus(ctx, saasPolicy.ID, false, err.Error()); reportErr != nil {
+		if reportErr := r.policySource().ReportUndeployStatus(ctx, saasPolicy.ID, false, err.Error()); reportErr != nil {
 			log.Error(reportErr, "Failed to report undeploy failure")
 		}
 		return
@@ -495,7 +683,7 @@ func (r *Reconciler) handleUndeploy(ctx context.Context, saasPolicy saas.Policy,
 	// Delete the ManagedPolicy CRD
 	if err := r.client.Delete(ctx, existing); err != nil && !errors.IsNotFound(err) {
 		log.Error(err, "Failed to delete ManagedPolicy")
-		if reportErr := r.saasClient.ReportUndeployStatus(ctx, saasPolicy.ID, false, err.Error()); reportErr != nil {
+		if reportErr := r.policySource().ReportUndeployStatus(ctx, saasPolicy.ID, false, err.Error()); reportErr != nil {
 			log.Error(reportErr, "Failed to report undeploy failure")
 		}
 		return
@@ -503,7 +691,7 @@ func (r *Reconciler) handleUndeploy(ctx context.Context, saasPolicy saas.Policy,
 
 	// Report success
 	log.Info("Successfully undeployed policy")
-	if err := r.saasClient.ReportUndeployStatus(ctx, saasPolicy.ID, true, ""); err != nil {
+	if err := r.policySource().ReportUndeployStatus(ctx, saasPolicy.ID, true, ""); err != nil {
 		log.Error(err, "Failed to report undeploy success")
 	}
 }
@@ -518,19 +706,60 @@ func (r *Reconciler) ReconcilePolicy(ctx context.Context, mp *policyv1alpha1.Man
 		return nil
 	}
 
-	// Check if deployment needed
+	if _, ok := mp.Annotations[policyv1alpha1.RollbackAnnotation]; ok {
+		return r.reconcileRollbackRequest(ctx, mp)
+	}
+
+	// Check if deployment needed. A deployed policy is deployed again when
+	// its content changes without a new version, or namespaces start or stop
+	// matching its namespace selector.
+	retarget := false
 	if mp.Status.Phase == policyv1alpha1.ManagedPolicyPhaseDeployed &&
 		mp.Status.DeployedVersion == mp.Spec.Version {
-		log.V(1).Info("Policy already deployed at current version")
+		switch {
+		case r.auditRefused(ctx, mp):
+			// A network policy moved to audit mode, or deployed in audit mode
+			// while Cilium audited network policies, fails validation below
+			log.Info("Network policy cannot be audited, revalidating")
+		case mp.ContentChanged():
+			log.Info("Policy content changed without a new version, redeploying",
+				"contentHash", policy.ContentHash(mp.Spec.Content),
+				"deployedContentHash", mp.Status.DeployedContentHash)
+			retarget = true
+		case modeChanged(mp):
+			return r.reconcileMode(ctx, mp)
+		case r.namespacesChanged(ctx, mp):
+			log.Info("Namespaces matching the namespace selector changed, redeploying")
+			retarget = true
+		default:
+			log.V(1).Info("Policy already deployed at current version, checking for drift")
+			return r.reconcileDrift(ctx, mp)
+		}
+	}
+
+	// A rolled back version is not retried until a newer version arrives
+	if rs := mp.Status.Rollout; rs != nil && rs.Version == mp.Spec.Version &&
+		rs.Phase == policyv1alpha1.RolloutPhaseRolledBack {
+		log.V(1).Info("Policy version was rolled back, skipping", "version", mp.Spec.Version)
 		return nil
 	}
+	if rolledBack(mp) {
+		log.V(1).Info("Policy version was rolled back on request, skipping",
+			"version", mp.Spec.Version, "deployedVersion", mp.Status.DeployedVersion)
+		return nil
+	}
+
+	// Refuse policies that are not signed by a trusted key
+	if verified, err := r.verifySignature(ctx, mp); !verified || err != nil {
+		return err
+	}
 
 	// Validate policy
-	if err := r.deployer.ValidatePolicy(mp); err != nil {
+	if err := r.deployer.ValidatePolicy(ctx, mp); err != nil {
 		log.Error(err, "Policy validation failed")
 
 		// Report validation failure to SaaS
-		_, _ = r.saasClient.UpdatePolicyStatus(ctx, mp.Spec.PolicyID, saas.UpdatePolicyStatusRequest{
+		_, _ = r.policySource().UpdatePolicyStatus(ctx, mp.Spec.PolicyID, saas.UpdatePolicyStatusRequest{
 			Status:  "FAILED",
 			Error:   err.Error(),
 			Version: mp.Spec.Version,
@@ -539,15 +768,25 @@ func (r *Reconciler) ReconcilePolicy(ctx context.Context, mp *policyv1alpha1.Man
 		return r.updatePolicyStatus(ctx, mp, policyv1alpha1.ManagedPolicyPhaseFailed, err.Error())
 	}
 
+	// Lint policy content. Findings are reported but do not block deployment.
+	lintFindings := r.lintPolicy(ctx, mp)
+
+	// Namespaces added to a rolled out version, and content changed without a
+	// new version, are deployed to directly
+	if mp.Spec.Rollout != nil && !retarget {
+		return r.reconcileRollout(ctx, mp, lintFindings)
+	}
+
 	// Update status to deploying
 	if err := r.updatePolicyStatus(ctx, mp, policyv1alpha1.ManagedPolicyPhaseDeploying, ""); err != nil {
 		return err
 	}
 
 	// Report IN_PROGRESS to SaaS before starting deployment
-	_, err := r.saasClient.UpdatePolicyStatus(ctx, mp.Spec.PolicyID, saas.UpdatePolicyStatusRequest{
-		Status:  "IN_PROGRESS",
-		Version: mp.Spec.Version,
+	_, err := r.policySource().UpdatePolicyStatus(ctx, mp.Spec.PolicyID, saas.UpdatePolicyStatusRequest{
+		Status:       "IN_PROGRESS",
+		Version:      mp.Spec.Version,
+		LintFindings: lintFindings,
 	})
 	if err != nil {
 		log.Error(err, "Failed to report IN_PROGRESS status to SaaS")
@@ -556,33 +795,216 @@ func (r *Reconciler) ReconcilePolicy(ctx context.Context, mp *policyv1alpha1.Man
 
 	// Deploy the policy
 	result := r.deployer.Deploy(ctx, mp)
-	if !result.Success {
+	if !result.Success && !result.Partial() {
 		log.Error(result.Error, "Policy deployment failed")
 
 		// Report failure to SaaS
-		_, _ = r.saasClient.UpdatePolicyStatus(ctx, mp.Spec.PolicyID, saas.UpdatePolicyStatusRequest{
-			Status:  "FAILED",
-			Error:   result.Error.Error(),
-			Version: mp.Spec.Version,
+		_, _ = r.policySource().UpdatePolicyStatus(ctx, mp.Spec.PolicyID, saas.UpdatePolicyStatusRequest{
+			Status:       "FAILED",
+			Error:        result.Error.Error(),
+			Version:      mp.Spec.Version,
+			LintFindings: lintFindings,
 		})
+		r.recordRevision(ctx, mp, policy.RevisionFailed)
 
 		return r.updatePolicyStatus(ctx, mp, policyv1alpha1.ManagedPolicyPhaseFailed, result.Error.Error())
 	}
 
-	// Update status to deployed
-	mp.Status.Phase = policyv1alpha1.ManagedPolicyPhaseDeployed
-	mp.Status.DeployedVersion = mp.Spec.Version
-	mp.Status.DeployedResources = result.DeployedResources
-	mp.Status.LastError = ""
-	now := metav1.Now()
-	mp.Status.LastDeployed = &now
-	mp.Status.ObservedGeneration = mp.Generation
+	// Delete the resources no longer targeted, such as those in namespaces
+	// that stopped matching the namespace selector
+	deployed := deployedAfter(mp.Status.DeployedResources, result)
+	if pruned, err := r.deployer.Prune(ctx, mp.Status.DeployedResources, targetResources(result)); err != nil {
+		log.Error(err, "Failed to delete resources no longer targeted")
+		deployed = mergeResources(mp.Status.DeployedResources, deployed)
+	} else if pruned > 0 {
+		log.Info("Deleted resources no longer targeted", "resources", pruned)
+	}
+	targets := targetStatuses(mp.Status.Targets, result.Targets, mp)
+
+	if result.Partial() {
+		return r.reportPartialDeploy(ctx, mp, result, deployed, targets, lintFindings)
+	}
 
-	if err := r.client.Status().Update(ctx, mp); err != nil {
+	// Update status to deployed. The status was updated since mp was read, so
+	// refetch to get the latest resourceVersion.
+	now := metav1.Now()
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &policyv1alpha1.ManagedPolicy{}
+		if err := r.client.Get(ctx, types.NamespacedName{Name: mp.Name, Namespace: mp.Namespace}, fresh); err != nil {
+			return err
+		}
+		fresh.Status.Phase = policyv1alpha1.ManagedPolicyPhaseDeployed
+		fresh.Status.DeployedVersion = mp.Spec.Version
+		fresh.Status.DeployedContentHash = policy.ContentHash(mp.Spec.Content)
+		fresh.Status.DeployedResources = deployed
+		fresh.Status.Namespaces = result.Namespaces
+		fresh.Status.Targets = targets
+		fresh.Status.LastError = ""
+		fresh.Status.LastDeployed = &now
+		fresh.Status.ObservedGeneration = mp.Generation
+		fresh.Status.Rollout = nil
+		fresh.Status.Rollback = nil
+		setDeployedMode(&fresh.Status, mp, now)
+		clearRolledBack(&fresh.Status, mp, "Deployed", fmt.Sprintf("Deployed version %d", mp.Spec.Version), now)
+		if err := r.client.Status().Update(ctx, fresh); err != nil {
+			return err
+		}
+		mp.ResourceVersion = fresh.ResourceVersion
+		fresh.Status.DeepCopyInto(&mp.Status)
+		return nil
+	}); err != nil {
 		return fmt.Errorf("failed to update policy status: %w", err)
 	}
 
+	r.recordRevision(ctx, mp, policy.RevisionDeployed)
+
 	// Report success to SaaS
+	_, err = r.policySource().UpdatePolicyStatus(ctx, mp.Spec.PolicyID, saas.UpdatePolicyStatusRequest{
+		Status:            "DEPLOYED",
+		DeployedResources: toSaaSResources(deployed),
+		Version:           mp.Spec.Version,
+		LintFindings:      lintFindings,
+		Mode:              string(mp.Status.Mode),
+		Targets:           toSaaSTargets(targets),
+	})
+	if err != nil {
+		log.Error(err, "Failed to report deployment status to SaaS")
+	}
+
+	log.Info("Successfully deployed policy",
+		"version", mp.Spec.Version,
+		"resources", len(deployed))
+
+	return nil
+}
+
+// reportPartialDeploy records a deployment where some targets failed. The
+// policy stays PartiallyDeployed, and is deployed again, until every target
+// runs the spec version.
+func (r *Reconciler) reportPartialDeploy(ctx context.Context, mp *policyv1alpha1.ManagedPolicy, result policy.DeployResult, deployed []policyv1alpha1.DeployedResource, targets []policyv1alpha1.TargetStatus, lintFindings []saas.LintFinding) error {
+	log := r.log.WithValues("policy", mp.Name, "policyId", mp.Spec.PolicyID)
+	failed := 0
+	for _, ts := range targets {
+		if ts.Error != "" {
+			failed++
+		}
+	}
+	log.Error(result.Error, "Policy deployment partially failed", "failed", failed, "targets", len(targets))
+
+	if err := r.mutatePolicyStatus(ctx, mp, func(status *policyv1alpha1.ManagedPolicyStatus) {
+		status.Phase = policyv1alpha1.ManagedPolicyPhasePartiallyDeployed
+		status.DeployedResources = deployed
+		status.Namespaces = result.Namespaces
+		status.Targets = targets
+		status.LastError = result.Error.Error()
+		status.ObservedGeneration = mp.Generation
+	}); err != nil {
+		return err
+	}
+	r.recordRevision(ctx, mp, policy.RevisionPartiallyDeployed)
+
+	_, err := r.policySource().UpdatePolicyStatus(ctx, mp.Spec.PolicyID, saas.UpdatePolicyStatusRequest{
+		Status:            "PARTIALLY_DEPLOYED",
+		Error:             result.Error.Error(),
+		DeployedResources: toSaaSResources(deployed),
+		Version:           mp.Spec.Version,
+		LintFindings:      lintFindings,
+		Targets:           toSaaSTargets(targets),
+	})
+	if err != nil {
+		log.Error(err, "Failed to report partial deployment to SaaS")
+	}
+
+	// Retry the failed targets
+	return fmt.Errorf("failed to deploy %d of %d targets: %w", failed, len(targets), result.Error)
+}
+
+// reconcileDrift detects changes made to a deployed policy's resources outside
+// Policy Hub and reverts or reports them according to the policy's drift mode
+func (r *Reconciler) reconcileDrift(ctx context.Context, mp *policyv1alpha1.ManagedPolicy) error {
+	log := r.log.WithValues("policy", mp.Name, "policyId", mp.Spec.PolicyID)
+
+	drifts, err := r.deployer.DetectDrift(ctx, mp)
+	if err != nil {
+		return fmt.Errorf("failed to detect drift: %w", err)
+	}
+
+	if len(drifts) == 0 {
+		// Clear a previously reported drift
+		if meta.IsStatusConditionTrue(mp.Status.Conditions, ConditionTypeDrifted) {
+			return r.setPolicyCondition(ctx, mp, metav1.Condition{
+				Type:               ConditionTypeDrifted,
+				Status:             metav1.ConditionFalse,
+				Reason:             "NoDrift",
+				Message:            "Deployed resources match the policy content",
+				ObservedGeneration: mp.Generation,
+				LastTransitionTime: metav1.Now(),
+			})
+		}
+		return nil
+	}
+
+	messages := make([]string, len(drifts))
+	driftedResources := make([]saas.DeployedResource, len(drifts))
+	for i, drift := range drifts {
+		messages[i] = drift.String()
+		driftedResources[i] = saas.DeployedResource{
+			APIVersion: drift.Resource.APIVersion,
+			Kind:       drift.Resource.Kind,
+			Name:       drift.Resource.Name,
+			Namespace:  drift.Resource.Namespace,
+		}
+	}
+	message := strings.Join(messages, "; ")
+	log.Info("Detected drift in deployed resources", "drift", message, "mode", mp.Spec.DriftMode)
+
+	if mp.Spec.DriftMode == policyv1alpha1.DriftModeReport {
+		// Only report when the drift changes, not on every reconcile
+		if existing := meta.FindStatusCondition(mp.Status.Conditions, ConditionTypeDrifted); existing != nil &&
+			existing.Status == metav1.ConditionTrue && existing.Message == message {
+			return nil
+		}
+
+		_, err := r.policySource().UpdatePolicyStatus(ctx, mp.Spec.PolicyID, saas.UpdatePolicyStatusRequest{
+			Status:           "DRIFTED",
+			Error:            message,
+			Version:          mp.Spec.Version,
+			DriftedResources: driftedResources,
+		})
+		if err != nil {
+			log.Error(err, "Failed to report drift to SaaS")
+		}
+
+		return r.setPolicyCondition(ctx, mp, metav1.Condition{
+			Type:               ConditionTypeDrifted,
+			Status:             metav1.ConditionTrue,
+			Reason:             "DriftDetected",
+			Message:            message,
+			ObservedGeneration: mp.Generation,
+			LastTransitionTime: metav1.Now(),
+		})
+	}
+
+	// Revert by re-applying the policy content, which must still verify
+	if verified, err := r.verifySignature(ctx, mp); !verified || err != nil {
+		return err
+	}
+	result := r.deployer.Deploy(ctx, mp)
+	if !result.Success {
+		log.Error(result.Error, "Failed to revert drift")
+		if err := r.setPolicyCondition(ctx, mp, metav1.Condition{
+			Type:               ConditionTypeDrifted,
+			Status:             metav1.ConditionTrue,
+			Reason:             "RevertFailed",
+			Message:            fmt.Sprintf("%s: %v", message, result.Error),
+			ObservedGeneration: mp.Generation,
+			LastTransitionTime: metav1.Now(),
+		}); err != nil {
+			log.Error(err, "Failed to update drift condition")
+		}
+		return fmt.Errorf("failed to revert drift: %w", result.Error)
+	}
+
 	deployedResources := make([]saas.DeployedResource, len(result.DeployedResources))
 	for i, res := range result.DeployedResources {
 		deployedResources[i] = saas.DeployedResource{
@@ -592,25 +1014,35 @@ func (r *Reconciler) ReconcilePolicy(ctx context.Context, mp *policyv1alpha1.Man
 			Namespace:  res.Namespace,
 		}
 	}
-
-	_, err = r.saasClient.UpdatePolicyStatus(ctx, mp.Spec.PolicyID, saas.UpdatePolicyStatusRequest{
+	_, err = r.policySource().UpdatePolicyStatus(ctx, mp.Spec.PolicyID, saas.UpdatePolicyStatusRequest{
 		Status:            "DEPLOYED",
 		DeployedResources: deployedResources,
 		Version:           mp.Spec.Version,
+		DriftedResources:  driftedResources,
 	})
 	if err != nil {
-		log.Error(err, "Failed to report deployment status to SaaS")
+		log.Error(err, "Failed to report reverted drift to SaaS")
 	}
 
-	log.Info("Successfully deployed policy",
-		"version", mp.Spec.Version,
-		"resources", len(result.DeployedResources))
+	log.Info("Reverted drift in deployed resources", "resources", len(drifts))
 
-	return nil
+	return r.setPolicyCondition(ctx, mp, metav1.Condition{
+		Type:               ConditionTypeDrifted,
+		Status:             metav1.ConditionFalse,
+		Reason:             "DriftReverted",
+		Message:            "Reverted " + message,
+		ObservedGeneration: mp.Generation,
+		LastTransitionTime: metav1.Now(),
+	})
 }
 
-// SyncGatewayAPIResources synchronizes Gateway API resources from the SaaS platform
+// SyncGatewayAPIResources synchronizes Gateway API resources from the SaaS platform.
+// Like policies, the resulting ManagedPolicies are verified against their
+// signature before deploying when policy signing is configured.
 func (r *Reconciler) SyncGatewayAPIResources(ctx context.Context) error {
+	if r.IsOffline() {
+		return nil
+	}
 	r.log.V(1).Info("Starting Gateway API resource sync")
 
 	// Fetch Gateway API resources from SaaS
@@ -660,8 +1092,11 @@ func (r *Reconciler) SyncGatewayAPIResources(ctx context.Context) error {
 
 		existing, found := existingByID[resource.ID]
 		if found {
-			// Check if update needed (using name as version proxy since Gateway API doesn't have versions)
-			if existing.Spec.Content != resource.YAML {
+			// Check if update needed. Resources signed by the SaaS platform carry
+			// their version; unsigned ones are versioned locally.
+			if existing.Spec.Content != resource.YAML ||
+				existing.Spec.Signature != resource.Signature ||
+				(resource.Version > 0 && existing.Spec.Version != resource.Version) {
 				r.log.Info("Updating Gateway API resource",
 					"kind", resource.Kind,
 					"name", resource.Name,
@@ -669,7 +1104,13 @@ func (r *Reconciler) SyncGatewayAPIResources(ctx context.Context) error {
 
 				existing.Spec.Content = resource.YAML
 				existing.Spec.TargetNamespaces = []string{resource.Namespace}
-				existing.Spec.Version = existing.Spec.Version + 1 // Increment version on update
+				existing.Spec.Signature = resource.Signature
+				existing.Spec.SignatureKeyID = resource.SignatureKeyID
+				if resource.Version > 0 {
+					existing.Spec.Version = resource.Version
+				} else {
+					existing.Spec.Version = existing.Spec.Version + 1 // Increment version on update
+				}
 
 				if err := r.client.Update(ctx, existing); err != nil {
 					r.log.Error(err, "Failed to update ManagedPolicy", "name", existing.Name)
@@ -685,8 +1126,9 @@ func (r *Reconciler) SyncGatewayAPIResources(ctx context.Context) error {
 
 			mp := &policyv1alpha1.ManagedPolicy{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      sanitizeName(fmt.Sprintf("gw-%s-%s-%s", resource.Kind, resource.Namespace, resource.Name)),
-					Namespace: r.config.Namespace,
+					Name:       sanitizeName(fmt.Sprintf("gw-%s-%s-%s", resource.Kind, resource.Namespace, resource.Name)),
+					Namespace:  r.config.Namespace,
+					Finalizers: []string{policyv1alpha1.ManagedPolicyFinalizer},
 				},
 				Spec: policyv1alpha1.ManagedPolicySpec{
 					PolicyID:         resource.ID,
@@ -696,8 +1138,13 @@ func (r *Reconciler) SyncGatewayAPIResources(ctx context.Context) error {
 					Content:          resource.YAML,
 					TargetNamespaces: []string{resource.Namespace},
 					Version:          1,
+					Signature:        resource.Signature,
+					SignatureKeyID:   resource.SignatureKeyID,
 				},
 			}
+			if resource.Version > 0 {
+				mp.Spec.Version = resource.Version
+			}
 
 			if err := r.client.Create(ctx, mp); err != nil {
 				r.log.Error(err, "Failed to create ManagedPolicy",
@@ -712,16 +1159,7 @@ func (r *Reconciler) SyncGatewayAPIResources(ctx context.Context) error {
 	for id, existing := range existingByID {
 		if !saasIDs[id] {
 			r.log.Info("Deleting removed Gateway API resource", "name", existing.Name)
-
-			// Delete deployed resources first
-			if err := r.deployer.Delete(ctx, existing); err != nil {
-				r.log.Error(err, "Failed to delete Gateway API resource", "name", existing.Name)
-			}
-
-			// Delete the ManagedPolicy
-			if err := r.client.Delete(ctx, existing); err != nil {
-				r.log.Error(err, "Failed to delete ManagedPolicy", "name", existing.Name)
-			}
+			r.deleteRemovedPolicy(ctx, existing)
 		}
 	}
 
@@ -746,6 +1184,10 @@ func gatewayKindToPolicyType(kind string) policyv1alpha1.PolicyType {
 
 // SendHeartbeat sends a heartbeat to the SaaS platform
 func (r *Reconciler) SendHeartbeat(ctx context.Context) error {
+	if r.IsOffline() {
+		return nil
+	}
+
 	// Get cluster info
 	nodeCount, namespaceCount, k8sVersion := r.getClusterInfo(ctx)
 
@@ -895,6 +1337,71 @@ func (r *Reconciler) updatePolicyStatus(ctx context.Context, mp *policyv1alpha1.
 	})
 }
 
+// mutatePolicyStatus applies mutate to the latest status of a ManagedPolicy
+// with retry on conflict and copies the result into mp
+func (r *Reconciler) mutatePolicyStatus(ctx context.Context, mp *policyv1alpha1.ManagedPolicy, mutate func(*policyv1alpha1.ManagedPolicyStatus)) error {
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &policyv1alpha1.ManagedPolicy{}
+		if err := r.client.Get(ctx, types.NamespacedName{Name: mp.Name, Namespace: mp.Namespace}, fresh); err != nil {
+			return err
+		}
+		mutate(&fresh.Status)
+		if err := r.client.Status().Update(ctx, fresh); err != nil {
+			return err
+		}
+		mp.ResourceVersion = fresh.ResourceVersion
+		fresh.Status.DeepCopyInto(&mp.Status)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to update policy status: %w", err)
+	}
+	return nil
+}
+
+// lintPolicy lints a ManagedPolicy, records the result in its LintPassed
+// condition and returns the findings for reporting to SaaS.
+func (r *Reconciler) lintPolicy(ctx context.Context, mp *policyv1alpha1.ManagedPolicy) []saas.LintFinding {
+	if r.linter == nil {
+		return nil
+	}
+	log := r.log.WithValues("policy", mp.Name, "policyId", mp.Spec.PolicyID)
+
+	findings := r.linter.Lint(ctx, mp)
+	for _, f := range findings {
+		log.Info("Policy lint finding", "severity", f.Severity, "check", f.Check, "finding", f.String())
+	}
+
+	condition := lint.Condition(findings, mp.Generation)
+	setCondition(&mp.Status.Conditions, condition)
+	if err := r.setPolicyCondition(ctx, mp, condition); err != nil {
+		log.Error(err, "Failed to update lint condition")
+	}
+
+	reported := make([]saas.LintFinding, len(findings))
+	for i, f := range findings {
+		reported[i] = saas.LintFinding{
+			Check:    f.Check,
+			Severity: string(f.Severity),
+			Resource: f.Resource,
+			Path:     f.Path,
+			Message:  f.Message,
+		}
+	}
+	return reported
+}
+
+// setPolicyCondition sets a condition on a ManagedPolicy status with retry on conflict
+func (r *Reconciler) setPolicyCondition(ctx context.Context, mp *policyv1alpha1.ManagedPolicy, condition metav1.Condition) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &policyv1alpha1.ManagedPolicy{}
+		if err := r.client.Get(ctx, types.NamespacedName{Name: mp.Name, Namespace: mp.Namespace}, fresh); err != nil {
+			return err
+		}
+		setCondition(&fresh.Status.Conditions, condition)
+		return r.client.Status().Update(ctx, fresh)
+	})
+}
+
 // setCondition sets or updates a condition in the conditions slice
 func setCondition(conditions *[]metav1.Condition, condition metav1.Condition) {
 	for i, c := range *conditions {
@@ -944,6 +1451,21 @@ func (r *Reconciler) IsRegistered() bool {
 	return r.registered
 }
 
+// IsOffline returns true if policies are synced from a directory and nothing
+// is sent to the SaaS platform
+func (r *Reconciler) IsOffline() bool {
+	return r.config != nil && r.config.Offline()
+}
+
+// policySource returns the source policies are synced from and their status
+// reported to
+func (r *Reconciler) policySource() PolicySource {
+	if r.source != nil {
+		return r.source
+	}
+	return r.saasClient
+}
+
 // GetSyncInterval returns the configured sync interval
 func (r *Reconciler) GetSyncInterval() time.Duration {
 	if r.config != nil && r.config.Spec.SyncInterval.Duration > 0 {
@@ -1004,6 +1526,50 @@ func (r *Reconciler) GetAPIToken(ctx context.Context) (string, error) {
 	return "", fmt.Errorf("not bootstrapped and no API token configured")
 }
 
+// SetOutbox makes the SaaS clients queue status reports that fail in the
+// outbox until they are delivered
+func (r *Reconciler) SetOutbox(outbox *saas.Outbox) {
+	r.outbox = outbox
+	if r.saasClient != nil {
+		r.saasClient.SetOutbox(outbox)
+	}
+}
+
+// GetOutboxClient returns the SaaS client when it queues submissions in an
+// outbox, or nil
+func (r *Reconciler) GetOutboxClient() *saas.Client {
+	if r.outbox == nil {
+		return nil
+	}
+	return r.saasClient
+}
+
+// SetEventStream makes the event stream connect with the SaaS client
+func (r *Reconciler) SetEventStream(events *saas.EventStream) {
+	r.events = events
+	if r.saasClient != nil {
+		events.SetClient(r.saasClient)
+	}
+}
+
+// GetEventStream returns the event stream, or nil
+func (r *Reconciler) GetEventStream() *saas.EventStream {
+	return r.events
+}
+
+// newSaaSClient creates a SaaS client using the outbox and event stream, if
+// there are
+func (r *Reconciler) newSaaSClient(endpoint, apiToken, clusterID string) *saas.Client {
+	c := saas.NewClient(endpoint, apiToken, clusterID, r.log)
+	if r.outbox != nil {
+		c.SetOutbox(r.outbox)
+	}
+	if r.events != nil {
+		r.events.SetClient(c)
+	}
+	return c
+}
+
 // GetLogger returns the reconciler's logger
 func (r *Reconciler) GetLogger() logr.Logger {
 	return r.log
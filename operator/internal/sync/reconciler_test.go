@@ -2,21 +2,30 @@ package sync
 
 import (
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
 	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+	"github.com/policy-hub/operator/internal/policy"
 	"github.com/policy-hub/operator/internal/saas"
 )
 
@@ -1078,6 +1087,57 @@ func TestSyncPolicies(t *testing.T) {
 		}
 	})
 
+	t.Run("sync reports policy rejected by admission as failed", func(t *testing.T) {
+		var reported saas.UpdatePolicyStatusRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/operator/policies":
+				json.NewEncoder(w).Encode(saas.FetchPoliciesResponse{
+					Success:  true,
+					Policies: []saas.Policy{{ID: "policy-1", Name: "Test Policy", Type: "CILIUM_NETWORK", Content: "kind: [", Version: 3}},
+					Count:    1,
+				})
+			case "/api/operator/policies/policy-1/status":
+				json.NewDecoder(r.Body).Decode(&reported)
+				json.NewEncoder(w).Encode(saas.UpdatePolicyStatusResponse{Success: true})
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		defer server.Close()
+
+		config := &policyv1alpha1.PolicyHubConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "config",
+				Namespace: "default",
+			},
+		}
+		c := fake.NewClientBuilder().
+			WithScheme(testScheme()).
+			WithObjects(config).
+			WithStatusSubresource(&policyv1alpha1.ManagedPolicy{}, &policyv1alpha1.PolicyHubConfig{}).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					return apierrors.NewInvalid(policyv1alpha1.GroupVersion.WithKind("ManagedPolicy").GroupKind(), obj.GetName(),
+						field.ErrorList{field.Invalid(field.NewPath("spec", "content"), "<content>", "invalid policy content")})
+				},
+			}).
+			Build()
+		r := NewReconciler(c, testLogger())
+		r.config = config
+		r.saasClient = saas.NewClient(server.URL, "test-token", "cluster-id", testLogger())
+
+		if err := r.SyncPolicies(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if reported.Status != "FAILED" || reported.Version != 3 {
+			t.Errorf("Expected FAILED status for version 3, got %+v", reported)
+		}
+		if !strings.Contains(reported.Error, "invalid policy content") {
+			t.Errorf("Expected rejection reason in error, got %q", reported.Error)
+		}
+	})
+
 	t.Run("sync updates existing policy", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if r.URL.Path == "/api/operator/policies" {
@@ -1147,6 +1207,168 @@ func TestSyncPolicies(t *testing.T) {
 			t.Errorf("Expected updated description, got %q", updatedPolicy.Spec.Description)
 		}
 	})
+
+	t.Run("sync fetches conditionally and compares content hashes", func(t *testing.T) {
+		content := "apiVersion: cilium.io/v2\nkind: CiliumNetworkPolicy\nmetadata:\n  name: updated"
+		var ifNoneMatch []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/operator/policies" {
+				w.Write([]byte(`{"success":true}`))
+				return
+			}
+			ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+			if r.Header.Get("If-None-Match") == `"rev-1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"rev-1"`)
+			json.NewEncoder(w).Encode(saas.FetchPoliciesResponse{
+				Success: true,
+				Policies: []saas.Policy{
+					// Content changed without a version bump
+					{ID: "policy-1", Name: "Existing", Type: "CILIUM_NETWORK", Content: content, Version: 1},
+					{ID: "policy-2", Name: "New", Type: "CILIUM_NETWORK", Content: content, Version: 1, ContentHash: policy.ContentHash(content)},
+					{ID: "policy-3", Name: "Corrupted", Type: "CILIUM_NETWORK", Content: content, Version: 1, ContentHash: policy.ContentHash("other")},
+				},
+				Count: 3,
+			})
+		}))
+		defer server.Close()
+
+		existingPolicy := &policyv1alpha1.ManagedPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "default"},
+			Spec: policyv1alpha1.ManagedPolicySpec{
+				PolicyID:   "policy-1",
+				Name:       "Existing",
+				PolicyType: policyv1alpha1.PolicyTypeCiliumNetwork,
+				Content:    "original content",
+				Version:    1,
+			},
+		}
+		config := &policyv1alpha1.PolicyHubConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default"},
+		}
+		c := newFakeClient(config, existingPolicy)
+		r := NewReconciler(c, testLogger())
+		r.config = config
+		r.saasClient = saas.NewClient(server.URL, "test-token", "cluster-id", testLogger())
+		ctx := context.Background()
+
+		summary := func() policyv1alpha1.SyncSummary {
+			t.Helper()
+			fresh := &policyv1alpha1.PolicyHubConfig{}
+			if err := c.Get(ctx, client.ObjectKeyFromObject(config), fresh); err != nil {
+				t.Fatalf("Failed to get config: %v", err)
+			}
+			if fresh.Status.LastSyncSummary == nil {
+				t.Fatal("Expected a sync summary")
+			}
+			return *fresh.Status.LastSyncSummary
+		}
+
+		if err := r.SyncPolicies(ctx); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		want := policyv1alpha1.SyncSummary{Added: 1, Updated: 1, Failed: 1}
+		if got := summary(); got != want {
+			t.Errorf("Expected summary %+v, got %+v", want, got)
+		}
+		updated := &policyv1alpha1.ManagedPolicy{}
+		if err := c.Get(ctx, client.ObjectKeyFromObject(existingPolicy), updated); err != nil {
+			t.Fatalf("Failed to get policy: %v", err)
+		}
+		if updated.Spec.Content != content || updated.Spec.ContentHash != policy.ContentHash(content) {
+			t.Errorf("Expected the content and its hash to be updated, got hash %q", updated.Spec.ContentHash)
+		}
+
+		// Unchanged policies are not fetched again but still checked
+		if err := r.SyncPolicies(ctx); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		want = policyv1alpha1.SyncSummary{Unchanged: 2, Failed: 1, NotModified: true}
+		if got := summary(); got != want {
+			t.Errorf("Expected summary %+v, got %+v", want, got)
+		}
+		if len(ifNoneMatch) != 2 || ifNoneMatch[0] != "" || ifNoneMatch[1] != `"rev-1"` {
+			t.Errorf("Expected the second fetch to send the ETag, got %q", ifNoneMatch)
+		}
+	})
+}
+
+// overlappingSource holds each fetch until another one is in flight, or
+// briefly if syncs are serialized, so concurrent syncs overlap
+type overlappingSource struct {
+	meet chan struct{}
+}
+
+func (s *overlappingSource) FetchPoliciesIfChanged(ctx context.Context, etag string) (*saas.FetchPoliciesResponse, error) {
+	select {
+	case s.meet <- struct{}{}:
+	case <-s.meet:
+	case <-time.After(20 * time.Millisecond):
+	}
+	if etag == `"rev-1"` {
+		return &saas.FetchPoliciesResponse{Success: true, ETag: etag, NotModified: true}, nil
+	}
+	return &saas.FetchPoliciesResponse{
+		Success: true,
+		Policies: []saas.Policy{
+			{ID: "policy-1", Name: "Test Policy", Type: "CILIUM_NETWORK", Content: "apiVersion: cilium.io/v2\nkind: CiliumNetworkPolicy", Version: 1},
+		},
+		Count: 1,
+		ETag:  `"rev-1"`,
+	}, nil
+}
+
+func (s *overlappingSource) UpdatePolicyStatus(ctx context.Context, policyID string, req saas.UpdatePolicyStatusRequest) (*saas.UpdatePolicyStatusResponse, error) {
+	return &saas.UpdatePolicyStatusResponse{Success: true}, nil
+}
+
+func (s *overlappingSource) ReportUndeployStatus(ctx context.Context, policyID string, success bool, errorMsg string) error {
+	return nil
+}
+
+func (s *overlappingSource) ReportSecurityAlert(ctx context.Context, alert saas.SecurityAlert) error {
+	return nil
+}
+
+func TestSyncPolicies_Concurrent(t *testing.T) {
+	config := &policyv1alpha1.PolicyHubConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default"},
+	}
+	c := newFakeClient(config)
+	r := NewReconciler(c, testLogger())
+	r.config = config
+	r.source = &overlappingSource{meet: make(chan struct{})}
+	ctx := context.Background()
+
+	// The config reconcile and the background sync loop sync at once; run
+	// with -race to check the fetched policies are not shared unguarded
+	const callers, syncs = 2, 3
+	errs := make(chan error, callers*syncs)
+	for range callers {
+		go func() {
+			for range syncs {
+				errs <- r.SyncPolicies(ctx)
+			}
+		}()
+	}
+	for range callers * syncs {
+		if err := <-errs; err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+	}
+
+	policies := &policyv1alpha1.ManagedPolicyList{}
+	if err := c.List(ctx, policies, client.InNamespace("default")); err != nil {
+		t.Fatalf("Failed to list policies: %v", err)
+	}
+	if len(policies.Items) != 1 {
+		t.Errorf("Expected 1 ManagedPolicy, got %d", len(policies.Items))
+	}
+	if r.lastPolicies == nil || r.lastPolicies.ETag != `"rev-1"` || r.lastPolicies.Count != 1 {
+		t.Errorf("Expected the fetched policies to be kept, got %+v", r.lastPolicies)
+	}
 }
 
 // --- updatePolicyStatus Tests ---
@@ -1214,6 +1436,322 @@ func TestUpdatePolicyStatus(t *testing.T) {
 	})
 }
 
+// --- lintPolicy Tests ---
+
+func TestLintPolicy(t *testing.T) {
+	policy := &policyv1alpha1.ManagedPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-policy",
+			Namespace:  "default",
+			Generation: 2,
+		},
+		Spec: policyv1alpha1.ManagedPolicySpec{
+			PolicyID:   "policy-1",
+			Name:       "Test Policy",
+			PolicyType: policyv1alpha1.PolicyTypeCiliumNetwork,
+			Content: `apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: egress-world
+spec:
+  endpointSelector: {}
+  egress:
+  - toEntities:
+    - world
+`,
+		},
+	}
+	c := newFakeClient(policy)
+	r := NewReconciler(c, testLogger())
+
+	findings := r.lintPolicy(context.Background(), policy)
+	if len(findings) != 1 {
+		t.Fatalf("Expected 1 finding, got %d: %+v", len(findings), findings)
+	}
+	if findings[0].Check != "broad-rule" || findings[0].Severity != "Warning" {
+		t.Errorf("Expected broad-rule warning, got %+v", findings[0])
+	}
+	if findings[0].Resource != "CiliumNetworkPolicy/egress-world" || findings[0].Path != "spec.egress[0]" {
+		t.Errorf("Unexpected finding location: %+v", findings[0])
+	}
+
+	updatedPolicy := &policyv1alpha1.ManagedPolicy{}
+	if err := c.Get(context.Background(), client.ObjectKey{Name: "test-policy", Namespace: "default"}, updatedPolicy); err != nil {
+		t.Fatalf("Failed to get policy: %v", err)
+	}
+	if len(updatedPolicy.Status.Conditions) != 1 {
+		t.Fatalf("Expected 1 condition, got %d", len(updatedPolicy.Status.Conditions))
+	}
+	cond := updatedPolicy.Status.Conditions[0]
+	if cond.Type != "LintPassed" || cond.Status != metav1.ConditionFalse || cond.Reason != "LintWarnings" {
+		t.Errorf("Unexpected condition: %+v", cond)
+	}
+	if cond.ObservedGeneration != 2 {
+		t.Errorf("Expected observed generation 2, got %d", cond.ObservedGeneration)
+	}
+	if len(policy.Status.Conditions) != 1 {
+		t.Errorf("Expected condition to be set on the in-memory policy")
+	}
+}
+
+// --- ReconcilePolicy Tests ---
+
+func TestReconcilePolicy_ContentChanged(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(saas.UpdatePolicyStatusResponse{Success: true})
+	}))
+	defer server.Close()
+
+	cnp := schema.GroupVersionKind{Group: "cilium.io", Version: "v2", Kind: "CiliumNetworkPolicy"}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(cnp, meta.RESTScopeNamespace)
+	mapper.Add(policyv1alpha1.GroupVersion.WithKind("ManagedPolicy"), meta.RESTScopeNamespace)
+
+	content := func(selector string) string {
+		return "apiVersion: cilium.io/v2\nkind: CiliumNetworkPolicy\nmetadata:\n  name: api\nspec:\n  endpointSelector:\n    matchLabels:\n      app: " + selector + "\n"
+	}
+	mp := &policyv1alpha1.ManagedPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-policy", Namespace: "policy-hub-system"},
+		Spec: policyv1alpha1.ManagedPolicySpec{
+			PolicyID:    "policy-1",
+			Name:        "Test Policy",
+			PolicyType:  policyv1alpha1.PolicyTypeCiliumNetwork,
+			Content:     content("api"),
+			ContentHash: policy.ContentHash(content("api")),
+			Version:     1,
+		},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(testScheme()).
+		WithRESTMapper(mapper).
+		WithObjects(mp).
+		WithStatusSubresource(&policyv1alpha1.ManagedPolicy{}).
+		Build()
+
+	r := NewReconciler(c, testLogger())
+	r.deployer = policy.NewDeployer(c, testLogger())
+	r.saasClient = saas.NewClient(server.URL, "test-token", "cluster-id", testLogger())
+	ctx := context.Background()
+
+	deployedSelector := func() string {
+		t.Helper()
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(cnp)
+		if err := c.Get(ctx, client.ObjectKey{Name: "api", Namespace: "policy-hub-system"}, live); err != nil {
+			t.Fatalf("Failed to get deployed resource: %v", err)
+		}
+		app, _, _ := unstructured.NestedString(live.Object, "spec", "endpointSelector", "matchLabels", "app")
+		return app
+	}
+
+	if err := r.ReconcilePolicy(ctx, mp); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(mp), mp); err != nil {
+		t.Fatalf("Failed to get policy: %v", err)
+	}
+	if mp.Status.DeployedContentHash != mp.Spec.ContentHash {
+		t.Errorf("Expected deployed content hash %s, got %s", mp.Spec.ContentHash, mp.Status.DeployedContentHash)
+	}
+
+	// Change the content without a version bump
+	mp.Spec.Content = content("web")
+	mp.Spec.ContentHash = policy.ContentHash(content("web"))
+	if err := c.Update(ctx, mp); err != nil {
+		t.Fatalf("Failed to update policy: %v", err)
+	}
+	if !mp.NeedsUpdate() {
+		t.Error("Expected a policy whose content changed to need an update")
+	}
+	if err := r.ReconcilePolicy(ctx, mp); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if got := deployedSelector(); got != "web" {
+		t.Errorf("Expected the changed content to be deployed, got selector %q", got)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(mp), mp); err != nil {
+		t.Fatalf("Failed to get policy: %v", err)
+	}
+	if mp.Status.DeployedContentHash != mp.Spec.ContentHash || mp.Status.DeployedVersion != 1 {
+		t.Errorf("Expected version 1 with the new content hash, got version %d hash %s", mp.Status.DeployedVersion, mp.Status.DeployedContentHash)
+	}
+
+	// Content edited in the cluster without its hash is deployed as a change,
+	// not reverted to as drift
+	mp.Spec.Content = content("db")
+	mp.Spec.ContentHash = ""
+	if err := c.Update(ctx, mp); err != nil {
+		t.Fatalf("Failed to update policy: %v", err)
+	}
+	if !mp.ContentChanged() {
+		t.Error("Expected content edited without its hash to be changed")
+	}
+	if err := r.ReconcilePolicy(ctx, mp); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(mp), mp); err != nil {
+		t.Fatalf("Failed to get policy: %v", err)
+	}
+	if mp.Status.DeployedContentHash != policy.ContentHash(content("db")) || deployedSelector() != "db" {
+		t.Errorf("Expected the edited content to be deployed, got hash %s", mp.Status.DeployedContentHash)
+	}
+}
+
+// --- reconcileDrift Tests ---
+
+func TestReconcileDrift(t *testing.T) {
+	content := `apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: api
+spec:
+  endpointSelector:
+    matchLabels:
+      app: api
+`
+	cnp := schema.GroupVersionKind{Group: "cilium.io", Version: "v2", Kind: "CiliumNetworkPolicy"}
+
+	// setup deploys a policy, then changes its deployed resource outside the operator
+	setup := func(t *testing.T, mode policyv1alpha1.DriftMode) (*Reconciler, client.Client, *[]saas.UpdatePolicyStatusRequest) {
+		t.Helper()
+		var reports []saas.UpdatePolicyStatusRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req saas.UpdatePolicyStatusRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			reports = append(reports, req)
+			json.NewEncoder(w).Encode(saas.UpdatePolicyStatusResponse{Success: true})
+		}))
+		t.Cleanup(server.Close)
+
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(cnp, meta.RESTScopeNamespace)
+		mapper.Add(policyv1alpha1.GroupVersion.WithKind("ManagedPolicy"), meta.RESTScopeNamespace)
+
+		mp := &policyv1alpha1.ManagedPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-policy", Namespace: "default"},
+			Spec: policyv1alpha1.ManagedPolicySpec{
+				PolicyID:   "policy-1",
+				Name:       "Test Policy",
+				PolicyType: policyv1alpha1.PolicyTypeCiliumNetwork,
+				Content:    content,
+				Version:    1,
+				DriftMode:  mode,
+			},
+		}
+		c := fake.NewClientBuilder().
+			WithScheme(testScheme()).
+			WithRESTMapper(mapper).
+			WithObjects(mp).
+			WithStatusSubresource(&policyv1alpha1.ManagedPolicy{}).
+			Build()
+
+		r := NewReconciler(c, testLogger())
+		r.deployer = policy.NewDeployer(c, testLogger())
+		r.saasClient = saas.NewClient(server.URL, "test-token", "cluster-id", testLogger())
+
+		if result := r.deployer.Deploy(context.Background(), mp); !result.Success {
+			t.Fatalf("Failed to deploy: %v", result.Error)
+		}
+		mp.Status.Phase = policyv1alpha1.ManagedPolicyPhaseDeployed
+		mp.Status.DeployedVersion = 1
+		if err := c.Status().Update(context.Background(), mp); err != nil {
+			t.Fatalf("Failed to update status: %v", err)
+		}
+
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(cnp)
+		if err := c.Get(context.Background(), client.ObjectKey{Name: "api", Namespace: "default"}, live); err != nil {
+			t.Fatalf("Failed to get deployed resource: %v", err)
+		}
+		_ = unstructured.SetNestedStringMap(live.Object, map[string]string{"app": "web"}, "spec", "endpointSelector", "matchLabels")
+		if err := c.Update(context.Background(), live); err != nil {
+			t.Fatalf("Failed to modify deployed resource: %v", err)
+		}
+		return r, c, &reports
+	}
+
+	getState := func(t *testing.T, c client.Client) (*policyv1alpha1.ManagedPolicy, string) {
+		t.Helper()
+		mp := &policyv1alpha1.ManagedPolicy{}
+		if err := c.Get(context.Background(), client.ObjectKey{Name: "test-policy", Namespace: "default"}, mp); err != nil {
+			t.Fatalf("Failed to get policy: %v", err)
+		}
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(cnp)
+		if err := c.Get(context.Background(), client.ObjectKey{Name: "api", Namespace: "default"}, live); err != nil {
+			t.Fatalf("Failed to get deployed resource: %v", err)
+		}
+		app, _, _ := unstructured.NestedString(live.Object, "spec", "endpointSelector", "matchLabels", "app")
+		return mp, app
+	}
+
+	t.Run("report mode keeps and reports drift once", func(t *testing.T) {
+		r, c, reports := setup(t, policyv1alpha1.DriftModeReport)
+
+		for i := 0; i < 2; i++ {
+			mp, _ := getState(t, c)
+			if err := r.ReconcilePolicy(context.Background(), mp); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+		}
+
+		mp, app := getState(t, c)
+		if app != "web" {
+			t.Errorf("Expected drift to be kept, got app=%q", app)
+		}
+		cond := meta.FindStatusCondition(mp.Status.Conditions, ConditionTypeDrifted)
+		if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != "DriftDetected" {
+			t.Fatalf("Expected Drifted condition, got %+v", cond)
+		}
+		if !strings.Contains(cond.Message, "spec.endpointSelector.matchLabels.app") {
+			t.Errorf("Expected drifted field in message, got %q", cond.Message)
+		}
+		if len(*reports) != 1 || (*reports)[0].Status != "DRIFTED" || len((*reports)[0].DriftedResources) != 1 {
+			t.Errorf("Expected a single DRIFTED report, got %+v", *reports)
+		}
+	})
+
+	t.Run("revert mode re-applies content", func(t *testing.T) {
+		r, c, reports := setup(t, policyv1alpha1.DriftModeRevert)
+
+		mp, _ := getState(t, c)
+		if err := r.ReconcilePolicy(context.Background(), mp); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		mp, app := getState(t, c)
+		if app != "api" {
+			t.Errorf("Expected drift to be reverted, got app=%q", app)
+		}
+		cond := meta.FindStatusCondition(mp.Status.Conditions, ConditionTypeDrifted)
+		if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "DriftReverted" {
+			t.Fatalf("Expected DriftReverted condition, got %+v", cond)
+		}
+		if len(*reports) != 1 || (*reports)[0].Status != "DEPLOYED" || len((*reports)[0].DriftedResources) != 1 {
+			t.Errorf("Expected a DEPLOYED report with reverted resources, got %+v", *reports)
+		}
+	})
+
+	t.Run("revert mode refuses an unsigned policy", func(t *testing.T) {
+		r, c, _ := setup(t, policyv1alpha1.DriftModeRevert)
+		signingKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+		r.config = trustedKeyConfig(signingKey)
+
+		mp, _ := getState(t, c)
+		if err := r.ReconcilePolicy(context.Background(), mp); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		mp, app := getState(t, c)
+		if app != "web" {
+			t.Errorf("Expected the unsigned content not to be re-applied, got app=%q", app)
+		}
+		if mp.Status.Phase != policyv1alpha1.ManagedPolicyPhaseFailed {
+			t.Errorf("Expected phase Failed, got %s", mp.Status.Phase)
+		}
+	})
+}
+
 // --- Constants Tests ---
 
 func TestConstants(t *testing.T) {
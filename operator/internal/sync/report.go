@@ -0,0 +1,160 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/policy-hub/operator/internal/policy"
+	"github.com/policy-hub/operator/internal/saas"
+)
+
+const (
+	// reportDataKey is the key of the report in the report ConfigMap
+	reportDataKey = "report.json"
+
+	// maxReportAlerts bounds the security alerts kept in the report
+	maxReportAlerts = 20
+)
+
+// LocalReport is the status of policies synced from a directory, kept in a
+// ConfigMap since it is not reported to the SaaS platform
+type LocalReport struct {
+	Path string `json:"path"`
+	// Revision is the Git commit of the directory, if it is a Git checkout
+	Revision string    `json:"revision,omitempty"`
+	Policies int       `json:"policies"`
+	Updated  time.Time `json:"updated"`
+	// Status is the last status of each policy by ID. Undeployed policies
+	// are removed.
+	Status map[string]PolicyReport `json:"status,omitempty"`
+	// Alerts are the most recent security alerts
+	Alerts []saas.SecurityAlert `json:"alerts,omitempty"`
+}
+
+// PolicyReport is the last status of a policy, as it would have been
+// reported to the SaaS platform
+type PolicyReport struct {
+	saas.UpdatePolicyStatusRequest
+	Updated time.Time `json:"updated"`
+}
+
+// UpdatePolicyStatus writes the status of a policy to the report
+func (s *DirectorySource) UpdatePolicyStatus(ctx context.Context, policyID string, req saas.UpdatePolicyStatusRequest) (*saas.UpdatePolicyStatusResponse, error) {
+	if err := s.updateReport(ctx, func(report *LocalReport) {
+		if report.Status == nil {
+			report.Status = make(map[string]PolicyReport)
+		}
+		report.Status[policyID] = PolicyReport{UpdatePolicyStatusRequest: req, Updated: time.Now().UTC()}
+	}); err != nil {
+		return nil, fmt.Errorf("failed to update policy report: %w", err)
+	}
+	return &saas.UpdatePolicyStatusResponse{
+		Success:         true,
+		PolicyID:        policyID,
+		Status:          req.Status,
+		DeployedVersion: req.Version,
+	}, nil
+}
+
+// ReportUndeployStatus removes an undeployed policy from the report, or
+// records the failure to undeploy it
+func (s *DirectorySource) ReportUndeployStatus(ctx context.Context, policyID string, success bool, errorMsg string) error {
+	if !success {
+		_, err := s.UpdatePolicyStatus(ctx, policyID, saas.UpdatePolicyStatusRequest{Status: "FAILED", Error: errorMsg})
+		return err
+	}
+	if err := s.updateReport(ctx, func(report *LocalReport) {
+		delete(report.Status, policyID)
+	}); err != nil {
+		return fmt.Errorf("failed to update policy report: %w", err)
+	}
+	return nil
+}
+
+// ReportSecurityAlert adds a security alert to the report
+func (s *DirectorySource) ReportSecurityAlert(ctx context.Context, alert saas.SecurityAlert) error {
+	if alert.Timestamp == "" {
+		alert.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	if err := s.updateReport(ctx, func(report *LocalReport) {
+		report.Alerts = append(report.Alerts, alert)
+		if len(report.Alerts) > maxReportAlerts {
+			report.Alerts = report.Alerts[len(report.Alerts)-maxReportAlerts:]
+		}
+	}); err != nil {
+		return fmt.Errorf("failed to update policy report: %w", err)
+	}
+	return nil
+}
+
+// GetReport returns the report, or an empty report if none was written yet
+func (s *DirectorySource) GetReport(ctx context.Context) (*LocalReport, error) {
+	cm := &corev1.ConfigMap{}
+	if err := s.client.Get(ctx, s.report, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return &LocalReport{Path: s.path}, nil
+		}
+		return nil, err
+	}
+	report := &LocalReport{}
+	if data := cm.Data[reportDataKey]; data != "" {
+		if err := json.Unmarshal([]byte(data), report); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal policy report: %w", err)
+		}
+	}
+	return report, nil
+}
+
+// updateReport applies mutate to the report ConfigMap, creating it if needed
+func (s *DirectorySource) updateReport(ctx context.Context, mutate func(*LocalReport)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm := &corev1.ConfigMap{}
+		err := s.client.Get(ctx, s.report, cm)
+		create := errors.IsNotFound(err)
+		if err != nil && !create {
+			return err
+		}
+
+		report := &LocalReport{Path: s.path}
+		if data := cm.Data[reportDataKey]; data != "" {
+			if err := json.Unmarshal([]byte(data), report); err != nil {
+				// A report that cannot be read is replaced
+				s.log.Error(err, "Replacing unreadable policy report")
+				report = &LocalReport{Path: s.path}
+			}
+		}
+		mutate(report)
+		report.Updated = time.Now().UTC()
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal policy report: %w", err)
+		}
+
+		if create {
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      s.report.Name,
+					Namespace: s.report.Namespace,
+					Labels:    map[string]string{policy.ManagedByLabel: "policy-hub-operator"},
+				},
+				Data: map[string]string{reportDataKey: string(data)},
+			}
+			return s.client.Create(ctx, cm)
+		}
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		cm.Data[reportDataKey] = string(data)
+		return s.client.Update(ctx, cm)
+	})
+}
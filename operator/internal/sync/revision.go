@@ -0,0 +1,214 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+	"github.com/policy-hub/operator/internal/policy"
+	"github.com/policy-hub/operator/internal/saas"
+)
+
+// recordRevision records the policy's current version in its revision
+// history. History is best effort and never fails a reconcile.
+func (r *Reconciler) recordRevision(ctx context.Context, mp *policyv1alpha1.ManagedPolicy, outcome policy.RevisionOutcome) {
+	if err := r.deployer.RecordRevision(ctx, mp, outcome); err != nil {
+		r.log.Error(err, "Failed to record policy revision", "policy", mp.Name, "version", mp.Spec.Version)
+	}
+}
+
+// rolledBack returns true if the spec version was rolled back on request and
+// must not be deployed again
+func rolledBack(mp *policyv1alpha1.ManagedPolicy) bool {
+	return mp.Status.Rollback != nil && mp.Status.Rollback.FromVersion >= mp.Spec.Version
+}
+
+// clearRolledBack sets the RolledBack condition False once a new version is
+// deployed
+func clearRolledBack(status *policyv1alpha1.ManagedPolicyStatus, mp *policyv1alpha1.ManagedPolicy, reason, message string, now metav1.Time) {
+	if !meta.IsStatusConditionTrue(status.Conditions, ConditionTypeRolledBack) {
+		return
+	}
+	setCondition(&status.Conditions, metav1.Condition{
+		Type:               ConditionTypeRolledBack,
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: mp.Generation,
+		LastTransitionTime: now,
+	})
+}
+
+// reconcileRollbackRequest redeploys the revision named by RollbackAnnotation
+// once it verifies, holds it until a newer version arrives and removes the
+// annotation
+func (r *Reconciler) reconcileRollbackRequest(ctx context.Context, mp *policyv1alpha1.ManagedPolicy) error {
+	log := r.log.WithValues("policy", mp.Name, "policyId", mp.Spec.PolicyID)
+	value := mp.Annotations[policyv1alpha1.RollbackAnnotation]
+
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return r.rejectRollbackRequest(ctx, mp, fmt.Sprintf("invalid rollback version %q", value))
+	}
+	if version == mp.Spec.Version {
+		return r.rejectRollbackRequest(ctx, mp, fmt.Sprintf("version %d is the current version", version))
+	}
+	rev, err := r.deployer.GetRevision(ctx, mp, version)
+	if errors.IsNotFound(err) {
+		return r.rejectRollbackRequest(ctx, mp, fmt.Sprintf("no revision recorded for version %d", version))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get revision %d: %w", version, err)
+	}
+
+	if err := r.verifyRevision(mp, rev); err != nil {
+		log.Error(err, "Refusing to roll back to a revision that failed verification", "version", version)
+		if err := r.policySource().ReportSecurityAlert(ctx, saas.SecurityAlert{
+			Type:     saas.AlertPolicySignatureInvalid,
+			Severity: "critical",
+			PolicyID: mp.Spec.PolicyID,
+			Version:  version,
+			Message:  fmt.Sprintf("Refusing to roll back to version %d: %s", version, err.Error()),
+			Details: map[string]string{
+				"reason":      signatureReason(err),
+				"contentHash": rev.ContentHash,
+			},
+		}); err != nil {
+			log.Error(err, "Failed to report security alert to SaaS")
+		}
+		return r.rejectRollbackRequest(ctx, mp, err.Error())
+	}
+
+	log.Info("Rolling back to revision", "from", mp.Spec.Version, "to", version)
+
+	// The revision is deployed with the spec it was recorded, and signed, with
+	target := mp.DeepCopy()
+	if rev.Spec != nil {
+		target.Spec = *rev.Spec.DeepCopy()
+		target.Spec.RevisionHistoryLimit = mp.Spec.RevisionHistoryLimit
+	}
+	target.Spec.Version = rev.Version
+	target.Spec.Content = rev.Content
+	result := r.deployer.Deploy(ctx, target)
+	if !result.Success {
+		log.Error(result.Error, "Failed to deploy revision")
+		_, _ = r.policySource().UpdatePolicyStatus(ctx, mp.Spec.PolicyID, saas.UpdatePolicyStatusRequest{
+			Status:  "FAILED",
+			Error:   fmt.Sprintf("failed to roll back to version %d: %v", version, result.Error),
+			Version: mp.Spec.Version,
+		})
+		return fmt.Errorf("failed to roll back to version %d: %w", version, result.Error)
+	}
+	deployed := result.DeployedResources
+	if _, err := r.deployer.Prune(ctx, mp.Status.DeployedResources, deployed); err != nil {
+		return fmt.Errorf("failed to delete resources not in version %d: %w", version, err)
+	}
+
+	now := metav1.Now()
+	message := fmt.Sprintf("Rolled back from version %d to version %d on request", mp.Spec.Version, version)
+	if err := r.mutatePolicyStatus(ctx, mp, func(status *policyv1alpha1.ManagedPolicyStatus) {
+		status.Phase = policyv1alpha1.ManagedPolicyPhaseDeployed
+		status.DeployedVersion = rev.Version
+		status.DeployedContentHash = rev.ContentHash
+		status.DeployedResources = deployed
+		status.LastError = ""
+		status.LastDeployed = &now
+		status.ObservedGeneration = mp.Generation
+		status.Rollout = nil
+		status.Rollback = &policyv1alpha1.RollbackStatus{
+			FromVersion:  mp.Spec.Version,
+			ToVersion:    rev.Version,
+			ContentHash:  rev.ContentHash,
+			RolledBackAt: &now,
+		}
+		setDeployedMode(status, target, now)
+		setCondition(&status.Conditions, metav1.Condition{
+			Type:               ConditionTypeRolledBack,
+			Status:             metav1.ConditionTrue,
+			Reason:             "RollbackRequested",
+			Message:            message,
+			ObservedGeneration: mp.Generation,
+			LastTransitionTime: now,
+		})
+	}); err != nil {
+		return err
+	}
+
+	if err := r.deployer.SetRevisionOutcome(ctx, mp, mp.Spec.Version, policy.RevisionRolledBack); err != nil {
+		log.Error(err, "Failed to record rolled back revision")
+	}
+	r.recordRevision(ctx, target, policy.RevisionDeployed)
+
+	_, err = r.policySource().UpdatePolicyStatus(ctx, mp.Spec.PolicyID, saas.UpdatePolicyStatusRequest{
+		Status:            "DEPLOYED",
+		DeployedResources: toSaaSResources(deployed),
+		Version:           rev.Version,
+		Mode:              string(mp.Status.Mode),
+		Rollback: &saas.PolicyRollback{
+			FromVersion: mp.Spec.Version,
+			ToVersion:   rev.Version,
+			ContentHash: rev.ContentHash,
+		},
+	})
+	if err != nil {
+		log.Error(err, "Failed to report rollback to SaaS")
+	}
+
+	log.Info(message)
+	return r.clearRollbackRequest(ctx, mp)
+}
+
+// verifyRevision checks a revision read back from its ConfigMap before it is
+// redeployed. Its content must match its content hash and, when policy signing
+// is configured, the spec it was recorded with must verify with its signature.
+func (r *Reconciler) verifyRevision(mp *policyv1alpha1.ManagedPolicy, rev *policy.Revision) error {
+	if policy.ContentHash(rev.Content) != rev.ContentHash {
+		return fmt.Errorf("revision %d content does not match its content hash", rev.Version)
+	}
+	keys := r.trustedKeys()
+	if len(keys) == 0 {
+		return nil
+	}
+	if rev.Spec == nil {
+		return fmt.Errorf("%w: revision %d was recorded without its spec", policy.ErrUnsigned, rev.Version)
+	}
+	if rev.Spec.PolicyID != mp.Spec.PolicyID || rev.Spec.Version != rev.Version {
+		return fmt.Errorf("%w: revision %d was recorded for policy %s version %d",
+			policy.ErrSignatureMismatch, rev.Version, rev.Spec.PolicyID, rev.Spec.Version)
+	}
+
+	signed := &policyv1alpha1.ManagedPolicy{Spec: *rev.Spec}
+	signed.Spec.Content = rev.Content
+	if _, err := policy.VerifySignature(signed, keys); err != nil {
+		return fmt.Errorf("revision %d: %w", rev.Version, err)
+	}
+	return nil
+}
+
+// rejectRollbackRequest records why a rollback request cannot be done and
+// removes it
+func (r *Reconciler) rejectRollbackRequest(ctx context.Context, mp *policyv1alpha1.ManagedPolicy, reason string) error {
+	r.log.Info("Ignoring rollback request", "policy", mp.Name, "reason", reason)
+	if err := r.mutatePolicyStatus(ctx, mp, func(status *policyv1alpha1.ManagedPolicyStatus) {
+		status.LastError = "rollback rejected: " + reason
+	}); err != nil {
+		return err
+	}
+	return r.clearRollbackRequest(ctx, mp)
+}
+
+// clearRollbackRequest removes RollbackAnnotation from a policy
+func (r *Reconciler) clearRollbackRequest(ctx context.Context, mp *policyv1alpha1.ManagedPolicy) error {
+	patch := client.MergeFrom(mp.DeepCopy())
+	delete(mp.Annotations, policyv1alpha1.RollbackAnnotation)
+	if err := r.client.Patch(ctx, mp, patch); err != nil {
+		return fmt.Errorf("failed to remove rollback annotation: %w", err)
+	}
+	return nil
+}
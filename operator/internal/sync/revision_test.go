@@ -0,0 +1,259 @@
+package sync
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+	"github.com/policy-hub/operator/internal/policy"
+	"github.com/policy-hub/operator/internal/saas"
+)
+
+func TestReconcileRollbackRequest(t *testing.T) {
+	var reports []saas.UpdatePolicyStatusRequest
+	var alerts []saas.SecurityAlert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/operator/alerts" {
+			var alert saas.SecurityAlert
+			json.NewDecoder(r.Body).Decode(&alert)
+			alerts = append(alerts, alert)
+			w.Write([]byte(`{"success":true}`))
+			return
+		}
+		var req saas.UpdatePolicyStatusRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		reports = append(reports, req)
+		json.NewEncoder(w).Encode(saas.UpdatePolicyStatusResponse{Success: true})
+	}))
+	defer server.Close()
+
+	contentFor := func(app string) string {
+		return `apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: api
+spec:
+  endpointSelector:
+    matchLabels:
+      app: ` + app + `
+`
+	}
+	cnp := schema.GroupVersionKind{Group: "cilium.io", Version: "v2", Kind: "CiliumNetworkPolicy"}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(cnp, meta.RESTScopeNamespace)
+	mapper.Add(policyv1alpha1.GroupVersion.WithKind("ManagedPolicy"), meta.RESTScopeNamespace)
+
+	mp := &policyv1alpha1.ManagedPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-policy", Namespace: "policy-hub-system"},
+		Spec: policyv1alpha1.ManagedPolicySpec{
+			PolicyID:   "policy-1",
+			Name:       "Test Policy",
+			PolicyType: policyv1alpha1.PolicyTypeCiliumNetwork,
+			Content:    contentFor("v1"),
+			Version:    1,
+		},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(testScheme()).
+		WithRESTMapper(mapper).
+		WithObjects(mp).
+		WithStatusSubresource(&policyv1alpha1.ManagedPolicy{}).
+		Build()
+
+	r := NewReconciler(c, testLogger())
+	r.deployer = policy.NewDeployer(c, testLogger())
+	r.saasClient = saas.NewClient(server.URL, "test-token", "cluster-id", testLogger())
+	ctx := context.Background()
+
+	getPolicy := func() *policyv1alpha1.ManagedPolicy {
+		t.Helper()
+		mp := &policyv1alpha1.ManagedPolicy{}
+		if err := c.Get(ctx, client.ObjectKey{Name: "test-policy", Namespace: "policy-hub-system"}, mp); err != nil {
+			t.Fatalf("Failed to get policy: %v", err)
+		}
+		return mp
+	}
+	reconcile := func() *policyv1alpha1.ManagedPolicy {
+		t.Helper()
+		mp := getPolicy()
+		if err := r.ReconcilePolicy(ctx, mp); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		return mp
+	}
+	update := func(mutate func(mp *policyv1alpha1.ManagedPolicy)) {
+		t.Helper()
+		mp := getPolicy()
+		mutate(mp)
+		if err := c.Update(ctx, mp); err != nil {
+			t.Fatalf("Failed to update policy: %v", err)
+		}
+	}
+	deployedApp := func() string {
+		t.Helper()
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(cnp)
+		if err := c.Get(ctx, client.ObjectKey{Name: "api", Namespace: "policy-hub-system"}, live); err != nil {
+			t.Fatalf("Failed to get deployed resource: %v", err)
+		}
+		app, _, _ := unstructured.NestedString(live.Object, "spec", "endpointSelector", "matchLabels", "app")
+		return app
+	}
+
+	// Deploy versions 1 and 2, recording both
+	reconcile()
+	update(func(mp *policyv1alpha1.ManagedPolicy) {
+		mp.Spec.Version = 2
+		mp.Spec.Content = contentFor("v2")
+	})
+	reconcile()
+	if got := deployedApp(); got != "v2" {
+		t.Fatalf("Expected version 2 to be deployed, got app=%s", got)
+	}
+
+	t.Run("rejects unknown revision", func(t *testing.T) {
+		update(func(mp *policyv1alpha1.ManagedPolicy) {
+			mp.Annotations = map[string]string{policyv1alpha1.RollbackAnnotation: "7"}
+		})
+		mp := reconcile()
+		if !strings.Contains(mp.Status.LastError, "no revision recorded for version 7") {
+			t.Errorf("Expected the request to be rejected, got LastError %q", mp.Status.LastError)
+		}
+		if _, ok := getPolicy().Annotations[policyv1alpha1.RollbackAnnotation]; ok {
+			t.Error("Expected the rollback annotation to be removed")
+		}
+		if got := deployedApp(); got != "v2" {
+			t.Errorf("Expected version 2 to stay deployed, got app=%s", got)
+		}
+	})
+
+	t.Run("redeploys recorded revision", func(t *testing.T) {
+		update(func(mp *policyv1alpha1.ManagedPolicy) {
+			mp.Annotations = map[string]string{policyv1alpha1.RollbackAnnotation: "1"}
+		})
+		mp := reconcile()
+		if got := deployedApp(); got != "v1" {
+			t.Errorf("Expected version 1 to be redeployed, got app=%s", got)
+		}
+		if mp.Status.DeployedVersion != 1 || mp.Status.Rollback == nil ||
+			mp.Status.Rollback.FromVersion != 2 || mp.Status.Rollback.ContentHash != policy.ContentHash(contentFor("v1")) {
+			t.Errorf("Expected rollback from version 2 to 1 in status, got version %d rollback %+v", mp.Status.DeployedVersion, mp.Status.Rollback)
+		}
+		if !meta.IsStatusConditionTrue(mp.Status.Conditions, ConditionTypeRolledBack) {
+			t.Error("Expected RolledBack condition to be True")
+		}
+		if _, ok := getPolicy().Annotations[policyv1alpha1.RollbackAnnotation]; ok {
+			t.Error("Expected the rollback annotation to be removed")
+		}
+		last := reports[len(reports)-1]
+		if last.Status != "DEPLOYED" || last.Version != 1 || last.Rollback == nil || last.Rollback.FromVersion != 2 {
+			t.Errorf("Expected DEPLOYED report of the rollback to version 1, got %+v", last)
+		}
+		if rev, err := r.deployer.GetRevision(ctx, mp, 2); err != nil || rev.Outcome != policy.RevisionRolledBack {
+			t.Errorf("Expected revision 2 to be marked rolled back, got %+v: %v", rev, err)
+		}
+
+		// The rolled back version is held
+		reported := len(reports)
+		reconcile()
+		if got := deployedApp(); got != "v1" || len(reports) != reported {
+			t.Errorf("Expected version 2 not to be redeployed, got app=%s and reports %+v", got, reports[reported:])
+		}
+	})
+
+	t.Run("deploys newer version", func(t *testing.T) {
+		update(func(mp *policyv1alpha1.ManagedPolicy) {
+			mp.Spec.Version = 3
+			mp.Spec.Content = contentFor("v3")
+		})
+		mp := reconcile()
+		if got := deployedApp(); got != "v3" {
+			t.Errorf("Expected version 3 to be deployed, got app=%s", got)
+		}
+		if mp.Status.DeployedVersion != 3 || mp.Status.Rollback != nil {
+			t.Errorf("Expected version 3 without rollback, got version %d rollback %+v", mp.Status.DeployedVersion, mp.Status.Rollback)
+		}
+		if meta.IsStatusConditionTrue(mp.Status.Conditions, ConditionTypeRolledBack) {
+			t.Error("Expected RolledBack condition to be cleared")
+		}
+	})
+
+	t.Run("refuses revisions that fail verification", func(t *testing.T) {
+		signingKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+		r.config = trustedKeyConfig(signingKey)
+		defer func() { r.config = nil }()
+
+		// Deploy signed versions 4 to 6
+		for version := 4; version <= 6; version++ {
+			update(func(mp *policyv1alpha1.ManagedPolicy) {
+				mp.Spec.Version = version
+				mp.Spec.Content = contentFor(fmt.Sprintf("v%d", version))
+				payload := policy.SignedPayload(&mp.Spec)
+				mp.Spec.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(signingKey, payload))
+				mp.Spec.SignatureKeyID = "saas-2026"
+			})
+			reconcile()
+		}
+		if got := deployedApp(); got != "v6" {
+			t.Fatalf("Expected version 6 to be deployed, got app=%s", got)
+		}
+
+		// Content changed in the revision ConfigMap, with a matching hash
+		cm := &corev1.ConfigMap{}
+		key := client.ObjectKey{Name: policy.RevisionName(mp, 4), Namespace: mp.Namespace}
+		if err := c.Get(ctx, key, cm); err != nil {
+			t.Fatalf("Failed to get revision: %v", err)
+		}
+		cm.Data["content"] = contentFor("tampered")
+		cm.Data["contentHash"] = policy.ContentHash(cm.Data["content"])
+		if err := c.Update(ctx, cm); err != nil {
+			t.Fatalf("Failed to tamper with revision: %v", err)
+		}
+
+		tests := []struct {
+			version string
+			reason  string
+		}{
+			{version: "3", reason: "SignatureMissing"},
+			{version: "4", reason: "SignatureMismatch"},
+		}
+		for _, tt := range tests {
+			update(func(mp *policyv1alpha1.ManagedPolicy) {
+				mp.Annotations = map[string]string{policyv1alpha1.RollbackAnnotation: tt.version}
+			})
+			mp := reconcile()
+			if !strings.HasPrefix(mp.Status.LastError, "rollback rejected: ") {
+				t.Errorf("Expected the rollback to %s to be rejected, got LastError %q", tt.version, mp.Status.LastError)
+			}
+			if got := deployedApp(); got != "v6" {
+				t.Errorf("Expected version 6 to stay deployed, got app=%s", got)
+			}
+			if last := alerts[len(alerts)-1]; last.Type != saas.AlertPolicySignatureInvalid || last.Details["reason"] != tt.reason {
+				t.Errorf("Expected a %s alert, got %+v", tt.reason, last)
+			}
+		}
+
+		update(func(mp *policyv1alpha1.ManagedPolicy) {
+			mp.Annotations = map[string]string{policyv1alpha1.RollbackAnnotation: "5"}
+		})
+		mp := reconcile()
+		if got := deployedApp(); got != "v5" || mp.Status.DeployedVersion != 5 {
+			t.Errorf("Expected the signed version 5 to be redeployed, got app=%s version %d", got, mp.Status.DeployedVersion)
+		}
+	})
+}
@@ -0,0 +1,406 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+	"github.com/policy-hub/operator/internal/policy"
+	"github.com/policy-hub/operator/internal/saas"
+)
+
+// Rollout defaults, matching the CRD defaults
+const (
+	defaultBakeTime             = policyv1alpha1.DefaultRolloutBakeTime
+	defaultBlockedFlowThreshold = policyv1alpha1.DefaultRolloutBlockedFlowThreshold
+)
+
+// FlowCounter reports blocked flows, and flows policies in audit mode would
+// have blocked, observed in namespaces. The validation agent implements it
+// from Hubble flows.
+type FlowCounter interface {
+	BlockedFlows(namespaces []string, since, until time.Time) int64
+	AuditedFlows(namespaces []string, since, until time.Time) int64
+}
+
+// SetFlowCounter sets the source of the flow counts staged rollouts are
+// checked against and audit mode promotions report. Without one, rollouts only
+// wait for the bake time of each step.
+func (r *Reconciler) SetFlowCounter(counter FlowCounter) {
+	r.countersMu.Lock()
+	defer r.countersMu.Unlock()
+	r.flowCounts = counter
+}
+
+func (r *Reconciler) flowCounter() FlowCounter {
+	r.countersMu.Lock()
+	defer r.countersMu.Unlock()
+	return r.flowCounts
+}
+
+// rolloutStrategy converts the rollout strategy of a SaaS policy, filling in
+// the defaults the API server would
+func rolloutStrategy(saasPolicy saas.Policy) *policyv1alpha1.RolloutStrategy {
+	if saasPolicy.Rollout == nil {
+		return nil
+	}
+	strategy := &policyv1alpha1.RolloutStrategy{
+		CanaryNamespaces:     saasPolicy.Rollout.CanaryNamespaces,
+		BakeTime:             metav1.Duration{Duration: time.Duration(saasPolicy.Rollout.BakeTimeSeconds) * time.Second},
+		BlockedFlowThreshold: saasPolicy.Rollout.BlockedFlowThreshold,
+	}
+	if strategy.BakeTime.Duration == 0 {
+		strategy.BakeTime.Duration = defaultBakeTime
+	}
+	if strategy.BlockedFlowThreshold == 0 {
+		strategy.BlockedFlowThreshold = defaultBlockedFlowThreshold
+	}
+	return strategy
+}
+
+func bakeTime(strategy *policyv1alpha1.RolloutStrategy) time.Duration {
+	if strategy.BakeTime.Duration <= 0 {
+		return defaultBakeTime
+	}
+	return strategy.BakeTime.Duration
+}
+
+func blockedFlowThreshold(strategy *policyv1alpha1.RolloutStrategy) int {
+	if strategy.BlockedFlowThreshold <= 0 {
+		return defaultBlockedFlowThreshold
+	}
+	return strategy.BlockedFlowThreshold
+}
+
+// rolloutSteps returns the namespaces running the new version after each
+// step: the canary namespaces, then every target namespace
+func rolloutSteps(mp *policyv1alpha1.ManagedPolicy, targets []string) [][]string {
+	if len(targets) <= 1 {
+		return [][]string{targets}
+	}
+
+	isTarget := make(map[string]bool, len(targets))
+	for _, ns := range targets {
+		isTarget[ns] = true
+	}
+	var canary []string
+	for _, ns := range mp.Spec.Rollout.CanaryNamespaces {
+		if isTarget[ns] {
+			canary = append(canary, ns)
+		}
+	}
+	if len(canary) == 0 {
+		canary = targets[:1]
+	}
+	if len(canary) == len(targets) {
+		return [][]string{targets}
+	}
+
+	isCanary := make(map[string]bool, len(canary))
+	all := append([]string{}, canary...)
+	for _, ns := range canary {
+		isCanary[ns] = true
+	}
+	for _, ns := range targets {
+		if !isCanary[ns] {
+			all = append(all, ns)
+		}
+	}
+	return [][]string{canary, all}
+}
+
+// RolloutRequeueAfter returns when a policy in a staged rollout should be
+// reconciled again to finish the current step, or zero
+func (r *Reconciler) RolloutRequeueAfter(mp *policyv1alpha1.ManagedPolicy) time.Duration {
+	rs := mp.Status.Rollout
+	if mp.Spec.Rollout == nil || rs == nil || rs.Version != mp.Spec.Version ||
+		rs.Phase != policyv1alpha1.RolloutPhaseBaking || rs.StepStarted == nil {
+		return 0
+	}
+	remaining := time.Until(rs.StepStarted.Add(bakeTime(mp.Spec.Rollout)))
+	if remaining < time.Second {
+		return time.Second
+	}
+	return remaining
+}
+
+// reconcileRollout deploys a new version in steps: the canary namespaces
+// first, then the remaining target namespaces. After each step it waits for
+// the bake time and compares the blocked flows in the namespaces running the
+// new version with the bake time before the step. When they increased by more
+// than the threshold, the previously deployed resources are restored.
+func (r *Reconciler) reconcileRollout(ctx context.Context, mp *policyv1alpha1.ManagedPolicy, lintFindings []saas.LintFinding) error {
+	log := r.log.WithValues("policy", mp.Name, "policyId", mp.Spec.PolicyID)
+	targets, err := r.deployer.ResolveNamespaces(ctx, mp)
+	if err != nil {
+		return r.updatePolicyStatus(ctx, mp, policyv1alpha1.ManagedPolicyPhaseFailed, err.Error())
+	}
+	steps := rolloutSteps(mp, targets)
+
+	rs := mp.Status.Rollout
+	if rs == nil || rs.Version != mp.Spec.Version {
+		return r.startRollout(ctx, mp, steps, lintFindings)
+	}
+	if rs.Phase != policyv1alpha1.RolloutPhaseBaking || rs.StepStarted == nil {
+		return nil
+	}
+
+	bake := bakeTime(mp.Spec.Rollout)
+	started := rs.StepStarted.Time
+	if time.Since(started) < bake {
+		log.V(1).Info("Rollout step is baking", "step", rs.Step, "remaining", time.Until(started.Add(bake)))
+		return nil
+	}
+
+	rs = rs.DeepCopy()
+	if counter := r.flowCounter(); counter != nil {
+		rs.ObservedBlockedFlows = counter.BlockedFlows(rs.Namespaces, started, started.Add(bake))
+		limit := float64(blockedFlowThreshold(mp.Spec.Rollout)) * bake.Minutes()
+		if increase := rs.ObservedBlockedFlows - rs.BaselineBlockedFlows; float64(increase) > limit {
+			reason := fmt.Sprintf("blocked flows in %s rose from %d to %d during the %s bake time",
+				strings.Join(rs.Namespaces, ", "), rs.BaselineBlockedFlows, rs.ObservedBlockedFlows, bake)
+			return r.rollBack(ctx, mp, rs, "BlockedFlowsIncreased", reason, mp.Status.DeployedResources, len(steps))
+		}
+	}
+
+	if rs.Step+1 < len(steps) {
+		return r.deployRolloutStep(ctx, mp, rs, rs.Step+1, steps, lintFindings)
+	}
+	return r.completeRollout(ctx, mp, rs, len(steps), lintFindings)
+}
+
+// startRollout records what to roll back to and deploys the first step
+func (r *Reconciler) startRollout(ctx context.Context, mp *policyv1alpha1.ManagedPolicy, steps [][]string, lintFindings []saas.LintFinding) error {
+	rs := &policyv1alpha1.RolloutStatus{
+		Version: mp.Spec.Version,
+		Phase:   policyv1alpha1.RolloutPhaseBaking,
+	}
+
+	if previous := mp.Status.Rollout; previous != nil && previous.Phase == policyv1alpha1.RolloutPhaseBaking {
+		// A newer version supersedes a rollout in progress; keep rolling
+		// back to the version deployed before it
+		rs.PreviousVersion = previous.PreviousVersion
+		rs.PreviousContent = previous.PreviousContent
+	} else if mp.Status.DeployedVersion > 0 {
+		snapshot, err := r.deployer.Snapshot(ctx, mp.Status.DeployedResources)
+		if err != nil {
+			return fmt.Errorf("failed to snapshot deployed resources: %w", err)
+		}
+		rs.PreviousVersion = mp.Status.DeployedVersion
+		rs.PreviousContent = snapshot
+	}
+
+	r.log.Info("Starting staged rollout",
+		"policy", mp.Name,
+		"version", mp.Spec.Version,
+		"previousVersion", rs.PreviousVersion,
+		"steps", len(steps))
+
+	return r.deployRolloutStep(ctx, mp, rs, 0, steps, lintFindings)
+}
+
+// deployRolloutStep deploys a step, records the blocked flows of its
+// namespaces before the step as the baseline and starts baking
+func (r *Reconciler) deployRolloutStep(ctx context.Context, mp *policyv1alpha1.ManagedPolicy, rs *policyv1alpha1.RolloutStatus, step int, steps [][]string, lintFindings []saas.LintFinding) error {
+	log := r.log.WithValues("policy", mp.Name, "policyId", mp.Spec.PolicyID)
+	namespaces := steps[step]
+
+	result := r.deployer.DeployToNamespaces(ctx, mp, namespaces)
+	deployed := mergeResources(mp.Status.DeployedResources, result.DeployedResources)
+	if !result.Success {
+		log.Error(result.Error, "Rollout step failed", "step", step)
+		return r.rollBack(ctx, mp, rs, "StepFailed", result.Error.Error(), deployed, len(steps))
+	}
+
+	now := metav1.Now()
+	bake := bakeTime(mp.Spec.Rollout)
+	rs.Step = step
+	rs.Namespaces = namespaces
+	rs.StepStarted = &now
+	rs.BaselineBlockedFlows = 0
+	if counter := r.flowCounter(); counter != nil {
+		rs.BaselineBlockedFlows = counter.BlockedFlows(namespaces, now.Add(-bake), now.Time)
+	}
+	rs.Message = fmt.Sprintf("Deployed version %d to %s, baking for %s", mp.Spec.Version, strings.Join(namespaces, ", "), bake)
+
+	if err := r.mutatePolicyStatus(ctx, mp, func(status *policyv1alpha1.ManagedPolicyStatus) {
+		status.Phase = policyv1alpha1.ManagedPolicyPhaseDeploying
+		status.LastError = ""
+		status.DeployedResources = deployed
+		status.Namespaces = namespaces
+		status.Targets = mergeTargetStatuses(status.Targets, targetStatuses(status.Targets, result.Targets, mp))
+		status.Rollout = rs
+	}); err != nil {
+		return err
+	}
+
+	_, err := r.policySource().UpdatePolicyStatus(ctx, mp.Spec.PolicyID, saas.UpdatePolicyStatusRequest{
+		Status:            "IN_PROGRESS",
+		DeployedResources: toSaaSResources(deployed),
+		Version:           mp.Spec.Version,
+		LintFindings:      lintFindings,
+		Rollout:           rolloutProgress(rs, len(steps)),
+		Targets:           toSaaSTargets(mp.Status.Targets),
+	})
+	if err != nil {
+		log.Error(err, "Failed to report rollout progress to SaaS")
+	}
+
+	log.Info("Deployed rollout step", "step", step, "namespaces", namespaces, "baselineBlockedFlows", rs.BaselineBlockedFlows)
+	return nil
+}
+
+// completeRollout marks the new version as deployed once the last step baked
+func (r *Reconciler) completeRollout(ctx context.Context, mp *policyv1alpha1.ManagedPolicy, rs *policyv1alpha1.RolloutStatus, steps int, lintFindings []saas.LintFinding) error {
+	now := metav1.Now()
+	rs.Phase = policyv1alpha1.RolloutPhaseCompleted
+	rs.PreviousContent = ""
+	rs.Message = fmt.Sprintf("Rolled out version %d to %s", mp.Spec.Version, strings.Join(rs.Namespaces, ", "))
+
+	if err := r.mutatePolicyStatus(ctx, mp, func(status *policyv1alpha1.ManagedPolicyStatus) {
+		status.Phase = policyv1alpha1.ManagedPolicyPhaseDeployed
+		status.DeployedVersion = mp.Spec.Version
+		status.DeployedContentHash = policy.ContentHash(mp.Spec.Content)
+		status.LastError = ""
+		status.LastDeployed = &now
+		status.ObservedGeneration = mp.Generation
+		status.Rollout = rs
+		status.Rollback = nil
+		setDeployedMode(status, mp, now)
+		clearRolledBack(status, mp, "RolloutCompleted", rs.Message, now)
+	}); err != nil {
+		return err
+	}
+	r.recordRevision(ctx, mp, policy.RevisionDeployed)
+
+	_, err := r.policySource().UpdatePolicyStatus(ctx, mp.Spec.PolicyID, saas.UpdatePolicyStatusRequest{
+		Status:            "DEPLOYED",
+		DeployedResources: toSaaSResources(mp.Status.DeployedResources),
+		Version:           mp.Spec.Version,
+		LintFindings:      lintFindings,
+		Rollout:           rolloutProgress(rs, steps),
+		Mode:              string(mp.Status.Mode),
+		Targets:           toSaaSTargets(mp.Status.Targets),
+	})
+	if err != nil {
+		r.log.Error(err, "Failed to report deployment status to SaaS")
+	}
+
+	r.log.Info("Completed staged rollout", "policy", mp.Name, "version", mp.Spec.Version)
+	return nil
+}
+
+// rollBack restores the resources deployed before the rollout and deletes the
+// other resources in current. The rolled back version is not retried.
+func (r *Reconciler) rollBack(ctx context.Context, mp *policyv1alpha1.ManagedPolicy, rs *policyv1alpha1.RolloutStatus, reason, message string, current []policyv1alpha1.DeployedResource, steps int) error {
+	log := r.log.WithValues("policy", mp.Name, "policyId", mp.Spec.PolicyID)
+	log.Info("Rolling back rollout", "version", mp.Spec.Version, "previousVersion", rs.PreviousVersion, "reason", message)
+
+	result := r.deployer.Restore(ctx, rs.PreviousContent, current)
+	if !result.Success {
+		// Keep the rollout baking so the rollback is retried
+		if err := r.mutatePolicyStatus(ctx, mp, func(status *policyv1alpha1.ManagedPolicyStatus) {
+			status.DeployedResources = current
+			status.LastError = fmt.Sprintf("failed to roll back: %v", result.Error)
+		}); err != nil {
+			log.Error(err, "Failed to update policy status")
+		}
+		return fmt.Errorf("failed to roll back version %d: %w", mp.Spec.Version, result.Error)
+	}
+
+	previousHash := ""
+	if rs.PreviousVersion > 0 {
+		previousHash = policy.ContentHash(rs.PreviousContent)
+	}
+	rs.Phase = policyv1alpha1.RolloutPhaseRolledBack
+	rs.PreviousContent = ""
+	rs.Message = fmt.Sprintf("Rolled back to version %d: %s", rs.PreviousVersion, message)
+	if rs.PreviousVersion == 0 {
+		rs.Message = "Removed the first version: " + message
+	}
+
+	now := metav1.Now()
+	if err := r.mutatePolicyStatus(ctx, mp, func(status *policyv1alpha1.ManagedPolicyStatus) {
+		status.Phase = policyv1alpha1.ManagedPolicyPhaseFailed
+		status.DeployedVersion = rs.PreviousVersion
+		status.DeployedContentHash = previousHash
+		status.DeployedResources = result.DeployedResources
+		status.LastError = rs.Message
+		status.ObservedGeneration = mp.Generation
+		status.Rollout = rs
+		setCondition(&status.Conditions, metav1.Condition{
+			Type:               ConditionTypeRolledBack,
+			Status:             metav1.ConditionTrue,
+			Reason:             reason,
+			Message:            rs.Message,
+			ObservedGeneration: mp.Generation,
+			LastTransitionTime: now,
+		})
+	}); err != nil {
+		return err
+	}
+	r.recordRevision(ctx, mp, policy.RevisionRolledBack)
+
+	progress := rolloutProgress(rs, steps)
+	progress.RolledBackToVersion = rs.PreviousVersion
+	_, err := r.policySource().UpdatePolicyStatus(ctx, mp.Spec.PolicyID, saas.UpdatePolicyStatusRequest{
+		Status:            "FAILED",
+		Error:             rs.Message,
+		DeployedResources: toSaaSResources(result.DeployedResources),
+		Version:           mp.Spec.Version,
+		Rollout:           progress,
+	})
+	if err != nil {
+		log.Error(err, "Failed to report rollback to SaaS")
+	}
+	return nil
+}
+
+func rolloutProgress(rs *policyv1alpha1.RolloutStatus, steps int) *saas.RolloutProgress {
+	return &saas.RolloutProgress{
+		Step:                 rs.Step,
+		Steps:                steps,
+		Namespaces:           rs.Namespaces,
+		BaselineBlockedFlows: rs.BaselineBlockedFlows,
+		ObservedBlockedFlows: rs.ObservedBlockedFlows,
+	}
+}
+
+// mergeResources returns the resources of both lists, without duplicates
+func mergeResources(current, added []policyv1alpha1.DeployedResource) []policyv1alpha1.DeployedResource {
+	merged := append([]policyv1alpha1.DeployedResource{}, current...)
+	index := make(map[string]int, len(merged))
+	for i, res := range merged {
+		index[resourceKey(res)] = i
+	}
+	for _, res := range added {
+		if i, ok := index[resourceKey(res)]; ok {
+			merged[i] = res
+			continue
+		}
+		index[resourceKey(res)] = len(merged)
+		merged = append(merged, res)
+	}
+	return merged
+}
+
+func resourceKey(res policyv1alpha1.DeployedResource) string {
+	return res.APIVersion + "/" + res.Kind + "/" + res.Namespace + "/" + res.Name
+}
+
+func toSaaSResources(resources []policyv1alpha1.DeployedResource) []saas.DeployedResource {
+	converted := make([]saas.DeployedResource, len(resources))
+	for i, res := range resources {
+		converted[i] = saas.DeployedResource{
+			APIVersion: res.APIVersion,
+			Kind:       res.Kind,
+			Name:       res.Name,
+			Namespace:  res.Namespace,
+		}
+	}
+	return converted
+}
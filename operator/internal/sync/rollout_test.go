@@ -0,0 +1,311 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+	"github.com/policy-hub/operator/internal/policy"
+	"github.com/policy-hub/operator/internal/saas"
+)
+
+// fakeFlowCounter returns the same counts for every query
+type fakeFlowCounter struct {
+	blocked int64
+	audited int64
+}
+
+func (f *fakeFlowCounter) BlockedFlows(namespaces []string, since, until time.Time) int64 {
+	return f.blocked
+}
+
+func (f *fakeFlowCounter) AuditedFlows(namespaces []string, since, until time.Time) int64 {
+	return f.audited
+}
+
+func TestRolloutSteps(t *testing.T) {
+	tests := []struct {
+		name    string
+		targets []string
+		canary  []string
+		want    [][]string
+	}{
+		{
+			name:    "canary then the rest",
+			targets: []string{"default", "backend", "frontend"},
+			canary:  []string{"backend"},
+			want:    [][]string{{"backend"}, {"backend", "default", "frontend"}},
+		},
+		{
+			name:    "defaults to the first target namespace",
+			targets: []string{"default", "backend"},
+			want:    [][]string{{"default"}, {"default", "backend"}},
+		},
+		{
+			name:    "ignores canaries that are not targets",
+			targets: []string{"default", "backend"},
+			canary:  []string{"staging", "backend"},
+			want:    [][]string{{"backend"}, {"backend", "default"}},
+		},
+		{
+			name:    "single step when every target is a canary",
+			targets: []string{"default", "backend"},
+			canary:  []string{"backend", "default"},
+			want:    [][]string{{"default", "backend"}},
+		},
+		{
+			name: "policy namespace without targets",
+			want: [][]string{{"policy-hub-system"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mp := &policyv1alpha1.ManagedPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-policy", Namespace: "policy-hub-system"},
+				Spec: policyv1alpha1.ManagedPolicySpec{
+					TargetNamespaces: tt.targets,
+					Rollout:          &policyv1alpha1.RolloutStrategy{CanaryNamespaces: tt.canary},
+				},
+			}
+			if got := rolloutSteps(mp, policy.TargetNamespaces(mp)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected steps %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestReconcileRollout(t *testing.T) {
+	contentFor := func(app string) string {
+		return `apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: api
+spec:
+  endpointSelector:
+    matchLabels:
+      app: ` + app + `
+`
+	}
+	cnp := schema.GroupVersionKind{Group: "cilium.io", Version: "v2", Kind: "CiliumNetworkPolicy"}
+	targets := []string{"default", "backend", "frontend"}
+
+	// setup deploys version 1 to every target namespace and updates the
+	// policy to version 2 with a staged rollout
+	setup := func(t *testing.T) (*Reconciler, client.Client, *fakeFlowCounter, *[]saas.UpdatePolicyStatusRequest) {
+		t.Helper()
+		var reports []saas.UpdatePolicyStatusRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req saas.UpdatePolicyStatusRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			reports = append(reports, req)
+			json.NewEncoder(w).Encode(saas.UpdatePolicyStatusResponse{Success: true})
+		}))
+		t.Cleanup(server.Close)
+
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(cnp, meta.RESTScopeNamespace)
+		mapper.Add(policyv1alpha1.GroupVersion.WithKind("ManagedPolicy"), meta.RESTScopeNamespace)
+
+		mp := &policyv1alpha1.ManagedPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-policy", Namespace: "policy-hub-system"},
+			Spec: policyv1alpha1.ManagedPolicySpec{
+				PolicyID:         "policy-1",
+				Name:             "Test Policy",
+				PolicyType:       policyv1alpha1.PolicyTypeCiliumNetwork,
+				Content:          contentFor("v1"),
+				TargetNamespaces: targets,
+				Version:          1,
+			},
+		}
+		c := fake.NewClientBuilder().
+			WithScheme(testScheme()).
+			WithRESTMapper(mapper).
+			WithObjects(mp).
+			WithStatusSubresource(&policyv1alpha1.ManagedPolicy{}).
+			Build()
+
+		counter := &fakeFlowCounter{}
+		r := NewReconciler(c, testLogger())
+		r.deployer = policy.NewDeployer(c, testLogger())
+		r.saasClient = saas.NewClient(server.URL, "test-token", "cluster-id", testLogger())
+		r.SetFlowCounter(counter)
+
+		result := r.deployer.Deploy(context.Background(), mp)
+		if !result.Success {
+			t.Fatalf("Failed to deploy: %v", result.Error)
+		}
+		mp.Status.Phase = policyv1alpha1.ManagedPolicyPhaseDeployed
+		mp.Status.DeployedVersion = 1
+		mp.Status.DeployedResources = result.DeployedResources
+		if err := c.Status().Update(context.Background(), mp); err != nil {
+			t.Fatalf("Failed to update status: %v", err)
+		}
+
+		mp.Spec.Version = 2
+		mp.Spec.Content = contentFor("v2")
+		mp.Spec.Rollout = &policyv1alpha1.RolloutStrategy{
+			CanaryNamespaces:     []string{"backend"},
+			BakeTime:             metav1.Duration{Duration: 5 * time.Minute},
+			BlockedFlowThreshold: 10,
+		}
+		if err := c.Update(context.Background(), mp); err != nil {
+			t.Fatalf("Failed to update policy: %v", err)
+		}
+		return r, c, counter, &reports
+	}
+
+	getPolicy := func(t *testing.T, c client.Client) *policyv1alpha1.ManagedPolicy {
+		t.Helper()
+		mp := &policyv1alpha1.ManagedPolicy{}
+		if err := c.Get(context.Background(), client.ObjectKey{Name: "test-policy", Namespace: "policy-hub-system"}, mp); err != nil {
+			t.Fatalf("Failed to get policy: %v", err)
+		}
+		return mp
+	}
+
+	// deployedApps returns the app selector deployed in each target namespace
+	deployedApps := func(t *testing.T, c client.Client) string {
+		t.Helper()
+		var apps []string
+		for _, ns := range targets {
+			live := &unstructured.Unstructured{}
+			live.SetGroupVersionKind(cnp)
+			if err := c.Get(context.Background(), client.ObjectKey{Name: "api", Namespace: ns}, live); err != nil {
+				t.Fatalf("Failed to get deployed resource in %s: %v", ns, err)
+			}
+			app, _, _ := unstructured.NestedString(live.Object, "spec", "endpointSelector", "matchLabels", "app")
+			apps = append(apps, ns+"="+app)
+		}
+		return strings.Join(apps, ",")
+	}
+
+	reconcile := func(t *testing.T, r *Reconciler, c client.Client) *policyv1alpha1.ManagedPolicy {
+		t.Helper()
+		mp := getPolicy(t, c)
+		if err := r.ReconcilePolicy(context.Background(), mp); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		return mp
+	}
+
+	// finishBake moves the start of the current step past the bake time
+	finishBake := func(t *testing.T, c client.Client) {
+		t.Helper()
+		mp := getPolicy(t, c)
+		started := metav1.NewTime(mp.Status.Rollout.StepStarted.Add(-10 * time.Minute))
+		mp.Status.Rollout.StepStarted = &started
+		if err := c.Status().Update(context.Background(), mp); err != nil {
+			t.Fatalf("Failed to update status: %v", err)
+		}
+	}
+
+	t.Run("promotes canary after bake time", func(t *testing.T) {
+		r, c, counter, reports := setup(t)
+		counter.blocked = 3
+
+		mp := reconcile(t, r, c)
+		if got := deployedApps(t, c); got != "default=v1,backend=v2,frontend=v1" {
+			t.Fatalf("Expected only the canary to run version 2, got %s", got)
+		}
+		if mp.Status.Phase != policyv1alpha1.ManagedPolicyPhaseDeploying || mp.Status.Rollout.Phase != policyv1alpha1.RolloutPhaseBaking {
+			t.Errorf("Expected canary step to bake, got phase %s, rollout %+v", mp.Status.Phase, mp.Status.Rollout)
+		}
+		if after := r.RolloutRequeueAfter(mp); after <= 4*time.Minute || after > 5*time.Minute {
+			t.Errorf("Expected requeue after the bake time, got %s", after)
+		}
+		if len(mp.Status.DeployedResources) != 3 {
+			t.Errorf("Expected resources of both versions to be tracked, got %+v", mp.Status.DeployedResources)
+		}
+
+		// Nothing happens while baking
+		reconcile(t, r, c)
+		if got := deployedApps(t, c); got != "default=v1,backend=v2,frontend=v1" {
+			t.Fatalf("Expected rollout to wait for the bake time, got %s", got)
+		}
+
+		// Blocked flows rose, but less than 10 per minute over 5 minutes
+		counter.blocked = 40
+		finishBake(t, c)
+		mp = reconcile(t, r, c)
+		if got := deployedApps(t, c); got != "default=v2,backend=v2,frontend=v2" {
+			t.Fatalf("Expected every namespace to run version 2, got %s", got)
+		}
+		if mp.Status.Rollout.Step != 1 || mp.Status.Rollout.BaselineBlockedFlows != 40 {
+			t.Errorf("Expected second step with a new baseline, got %+v", mp.Status.Rollout)
+		}
+
+		finishBake(t, c)
+		mp = reconcile(t, r, c)
+		if mp.Status.Phase != policyv1alpha1.ManagedPolicyPhaseDeployed || mp.Status.DeployedVersion != 2 {
+			t.Errorf("Expected version 2 to be deployed, got phase %s, version %d", mp.Status.Phase, mp.Status.DeployedVersion)
+		}
+		if mp.Status.Rollout.Phase != policyv1alpha1.RolloutPhaseCompleted || mp.Status.Rollout.PreviousContent != "" {
+			t.Errorf("Expected completed rollout without previous content, got %+v", mp.Status.Rollout)
+		}
+		if r.RolloutRequeueAfter(mp) != 0 {
+			t.Error("Expected no requeue after the rollout completed")
+		}
+
+		var statuses []string
+		for _, report := range *reports {
+			statuses = append(statuses, report.Status)
+		}
+		if got := strings.Join(statuses, ","); got != "IN_PROGRESS,IN_PROGRESS,DEPLOYED" {
+			t.Errorf("Unexpected reports: %s", got)
+		}
+	})
+
+	t.Run("rolls back when blocked flows spike", func(t *testing.T) {
+		r, c, counter, reports := setup(t)
+
+		reconcile(t, r, c)
+		counter.blocked = 51
+		finishBake(t, c)
+		mp := reconcile(t, r, c)
+
+		if got := deployedApps(t, c); got != "default=v1,backend=v1,frontend=v1" {
+			t.Fatalf("Expected version 1 to be restored, got %s", got)
+		}
+		if mp.Status.Phase != policyv1alpha1.ManagedPolicyPhaseFailed || mp.Status.DeployedVersion != 1 {
+			t.Errorf("Expected failed phase at version 1, got phase %s, version %d", mp.Status.Phase, mp.Status.DeployedVersion)
+		}
+		if mp.Status.Rollout.Phase != policyv1alpha1.RolloutPhaseRolledBack || mp.Status.Rollout.ObservedBlockedFlows != 51 {
+			t.Errorf("Expected rolled back rollout, got %+v", mp.Status.Rollout)
+		}
+		if len(mp.Status.DeployedResources) != 3 {
+			t.Errorf("Expected the restored resources to be tracked, got %+v", mp.Status.DeployedResources)
+		}
+		cond := meta.FindStatusCondition(mp.Status.Conditions, ConditionTypeRolledBack)
+		if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != "BlockedFlowsIncreased" {
+			t.Fatalf("Expected RolledBack condition, got %+v", cond)
+		}
+		if !strings.Contains(cond.Message, "rose from 0 to 51") {
+			t.Errorf("Expected blocked flow counts in message, got %q", cond.Message)
+		}
+
+		last := (*reports)[len(*reports)-1]
+		if last.Status != "FAILED" || last.Rollout == nil || last.Rollout.RolledBackToVersion != 1 {
+			t.Errorf("Expected FAILED report rolled back to version 1, got %+v", last)
+		}
+
+		// The rolled back version is not retried
+		reconcile(t, r, c)
+		if got := deployedApps(t, c); got != "default=v1,backend=v1,frontend=v1" {
+			t.Errorf("Expected version 2 not to be redeployed, got %s", got)
+		}
+	})
+}
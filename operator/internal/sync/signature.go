@@ -0,0 +1,114 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+	"github.com/policy-hub/operator/internal/policy"
+	"github.com/policy-hub/operator/internal/saas"
+)
+
+// trustedKeys returns the keys policies must be signed with, or nil if
+// policy signing is not configured
+func (r *Reconciler) trustedKeys() []policyv1alpha1.TrustedKey {
+	if r.config == nil || r.config.Spec.PolicySigning == nil {
+		return nil
+	}
+	return r.config.Spec.PolicySigning.TrustedKeys
+}
+
+// signatureReason returns the SignatureVerified condition reason of a
+// verification error
+func signatureReason(err error) string {
+	switch {
+	case errors.Is(err, policy.ErrUnsigned):
+		return "SignatureMissing"
+	case errors.Is(err, policy.ErrUnknownKey):
+		return "UnknownSigningKey"
+	case errors.Is(err, policy.ErrSignatureMismatch):
+		return "SignatureMismatch"
+	default:
+		return "VerificationFailed"
+	}
+}
+
+// verifySignature verifies the policy signature when policy signing is
+// configured. A policy that fails verification is marked Failed, reported to
+// the SaaS platform with a security alert, and must not be deployed; false is
+// returned then.
+func (r *Reconciler) verifySignature(ctx context.Context, mp *policyv1alpha1.ManagedPolicy) (bool, error) {
+	keys := r.trustedKeys()
+	if len(keys) == 0 {
+		return true, nil
+	}
+	log := r.log.WithValues("policy", mp.Name, "policyId", mp.Spec.PolicyID)
+
+	keyID, err := policy.VerifySignature(mp, keys)
+	if err == nil {
+		if existing := meta.FindStatusCondition(mp.Status.Conditions, ConditionTypeSignatureVerified); existing != nil &&
+			existing.Status == metav1.ConditionTrue && existing.ObservedGeneration == mp.Generation {
+			return true, nil
+		}
+		return true, r.mutatePolicyStatus(ctx, mp, func(status *policyv1alpha1.ManagedPolicyStatus) {
+			setCondition(&status.Conditions, metav1.Condition{
+				Type:               ConditionTypeSignatureVerified,
+				Status:             metav1.ConditionTrue,
+				Reason:             "Verified",
+				Message:            fmt.Sprintf("Version %d signed with trusted key %s", mp.Spec.Version, keyID),
+				ObservedGeneration: mp.Generation,
+				LastTransitionTime: metav1.Now(),
+			})
+		})
+	}
+
+	// The policy was already refused at this generation and reported
+	if existing := meta.FindStatusCondition(mp.Status.Conditions, ConditionTypeSignatureVerified); existing != nil &&
+		existing.Status == metav1.ConditionFalse && existing.ObservedGeneration == mp.Generation {
+		return false, nil
+	}
+
+	reason := signatureReason(err)
+	message := fmt.Sprintf("Refusing to deploy version %d: %s", mp.Spec.Version, err.Error())
+	log.Error(err, "Policy signature verification failed, refusing to deploy",
+		"version", mp.Spec.Version,
+		"keyId", mp.Spec.SignatureKeyID)
+
+	_, _ = r.policySource().UpdatePolicyStatus(ctx, mp.Spec.PolicyID, saas.UpdatePolicyStatusRequest{
+		Status:  "FAILED",
+		Error:   message,
+		Version: mp.Spec.Version,
+	})
+	if err := r.policySource().ReportSecurityAlert(ctx, saas.SecurityAlert{
+		Type:     saas.AlertPolicySignatureInvalid,
+		Severity: "critical",
+		PolicyID: mp.Spec.PolicyID,
+		Version:  mp.Spec.Version,
+		Message:  message,
+		Details: map[string]string{
+			"reason":      reason,
+			"keyId":       mp.Spec.SignatureKeyID,
+			"contentHash": policy.ContentHash(mp.Spec.Content),
+		},
+	}); err != nil {
+		log.Error(err, "Failed to report security alert to SaaS")
+	}
+
+	return false, r.mutatePolicyStatus(ctx, mp, func(status *policyv1alpha1.ManagedPolicyStatus) {
+		status.Phase = policyv1alpha1.ManagedPolicyPhaseFailed
+		status.LastError = message
+		status.ObservedGeneration = mp.Generation
+		setCondition(&status.Conditions, metav1.Condition{
+			Type:               ConditionTypeSignatureVerified,
+			Status:             metav1.ConditionFalse,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: mp.Generation,
+			LastTransitionTime: metav1.Now(),
+		})
+	})
+}
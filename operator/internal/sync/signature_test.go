@@ -0,0 +1,271 @@
+package sync
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+	"github.com/policy-hub/operator/internal/policy"
+	"github.com/policy-hub/operator/internal/saas"
+)
+
+func TestReconcilePolicy_SignatureVerification(t *testing.T) {
+	var reports []saas.UpdatePolicyStatusRequest
+	var alerts []saas.SecurityAlert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/operator/alerts" {
+			var alert saas.SecurityAlert
+			json.NewDecoder(r.Body).Decode(&alert)
+			alerts = append(alerts, alert)
+			w.Write([]byte(`{"success":true}`))
+			return
+		}
+		var req saas.UpdatePolicyStatusRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		reports = append(reports, req)
+		json.NewEncoder(w).Encode(saas.UpdatePolicyStatusResponse{Success: true})
+	}))
+	defer server.Close()
+
+	cnp := schema.GroupVersionKind{Group: "cilium.io", Version: "v2", Kind: "CiliumNetworkPolicy"}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(cnp, meta.RESTScopeNamespace)
+	mapper.Add(policyv1alpha1.GroupVersion.WithKind("ManagedPolicy"), meta.RESTScopeNamespace)
+
+	signingKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	mp := &policyv1alpha1.ManagedPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-policy", Namespace: "policy-hub-system", Generation: 1},
+		Spec: policyv1alpha1.ManagedPolicySpec{
+			PolicyID:   "policy-1",
+			Name:       "Test Policy",
+			PolicyType: policyv1alpha1.PolicyTypeCiliumNetwork,
+			Content: `apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: api
+spec:
+  endpointSelector: {}
+`,
+			Version: 1,
+		},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(testScheme()).
+		WithRESTMapper(mapper).
+		WithObjects(mp).
+		WithStatusSubresource(&policyv1alpha1.ManagedPolicy{}).
+		Build()
+
+	r := NewReconciler(c, testLogger())
+	r.deployer = policy.NewDeployer(c, testLogger())
+	r.saasClient = saas.NewClient(server.URL, "test-token", "cluster-id", testLogger())
+	r.config = trustedKeyConfig(signingKey)
+	ctx := context.Background()
+
+	deployed := func() bool {
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(cnp)
+		return c.Get(ctx, client.ObjectKey{Name: "api", Namespace: "policy-hub-system"}, live) == nil
+	}
+	// The API server bumps the generation on spec changes; the fake client does not
+	updateSpec := func(mutate func(*policyv1alpha1.ManagedPolicySpec)) {
+		t.Helper()
+		mutate(&mp.Spec)
+		mp.Generation++
+		if err := c.Update(ctx, mp); err != nil {
+			t.Fatalf("Failed to update policy: %v", err)
+		}
+	}
+	sign := func(spec *policyv1alpha1.ManagedPolicySpec) {
+		payload := policy.SignedPayload(spec)
+		spec.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(signingKey, payload))
+		spec.SignatureKeyID = "saas-2026"
+	}
+
+	t.Run("refuses an unsigned policy", func(t *testing.T) {
+		if err := r.ReconcilePolicy(ctx, mp); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if mp.Status.Phase != policyv1alpha1.ManagedPolicyPhaseFailed || deployed() {
+			t.Errorf("Expected phase Failed without deploying, got %s", mp.Status.Phase)
+		}
+		cond := meta.FindStatusCondition(mp.Status.Conditions, ConditionTypeSignatureVerified)
+		if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "SignatureMissing" {
+			t.Errorf("Expected SignatureVerified False with reason SignatureMissing, got %+v", cond)
+		}
+		if len(reports) != 1 || reports[0].Status != "FAILED" {
+			t.Errorf("Expected a FAILED report, got %+v", reports)
+		}
+		if len(alerts) != 1 || alerts[0].Type != saas.AlertPolicySignatureInvalid || alerts[0].PolicyID != "policy-1" {
+			t.Errorf("Expected a signature alert for policy-1, got %+v", alerts)
+		}
+
+		// The refusal is reported once per generation
+		if err := r.ReconcilePolicy(ctx, mp); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if len(reports) != 1 || len(alerts) != 1 {
+			t.Errorf("Expected no further reports, got %d reports and %d alerts", len(reports), len(alerts))
+		}
+	})
+
+	t.Run("refuses tampered content", func(t *testing.T) {
+		updateSpec(func(spec *policyv1alpha1.ManagedPolicySpec) {
+			sign(spec)
+			spec.Content += "  ingress: []\n"
+		})
+		if err := r.ReconcilePolicy(ctx, mp); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		cond := meta.FindStatusCondition(mp.Status.Conditions, ConditionTypeSignatureVerified)
+		if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "SignatureMismatch" {
+			t.Errorf("Expected SignatureVerified False with reason SignatureMismatch, got %+v", cond)
+		}
+		if deployed() || len(alerts) != 2 || alerts[1].Details["reason"] != "SignatureMismatch" {
+			t.Errorf("Expected a mismatch alert without deploying, got %+v", alerts)
+		}
+	})
+
+	t.Run("deploys a signed policy", func(t *testing.T) {
+		updateSpec(sign)
+		if err := r.ReconcilePolicy(ctx, mp); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if mp.Status.Phase != policyv1alpha1.ManagedPolicyPhaseDeployed || !deployed() {
+			t.Errorf("Expected phase Deployed, got %s", mp.Status.Phase)
+		}
+		cond := meta.FindStatusCondition(mp.Status.Conditions, ConditionTypeSignatureVerified)
+		if cond == nil || cond.Status != metav1.ConditionTrue || cond.ObservedGeneration != mp.Generation {
+			t.Errorf("Expected SignatureVerified True at the current generation, got %+v", cond)
+		}
+		if last := reports[len(reports)-1]; last.Status != "DEPLOYED" {
+			t.Errorf("Expected DEPLOYED report, got %+v", last)
+		}
+	})
+}
+
+// trustedKeyConfig returns a config requiring policies to be signed with key
+func trustedKeyConfig(key ed25519.PrivateKey) *policyv1alpha1.PolicyHubConfig {
+	return &policyv1alpha1.PolicyHubConfig{
+		Spec: policyv1alpha1.PolicyHubConfigSpec{
+			PolicySigning: &policyv1alpha1.PolicySigningSpec{
+				TrustedKeys: []policyv1alpha1.TrustedKey{{
+					ID:        "saas-2026",
+					PublicKey: base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+				}},
+			},
+		},
+	}
+}
+
+func TestSyncGatewayAPIResources_Signed(t *testing.T) {
+	signingKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	const route = `apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: web
+  namespace: shop
+spec:
+  parentRefs:
+    - name: gateway
+`
+	// The SaaS platform signs a route as a policy of its route type
+	signed := saas.GatewayAPIResource{ID: "route-1", Kind: "HTTPRoute", Name: "web", Namespace: "shop", YAML: route, Version: 4}
+	spec := policyv1alpha1.ManagedPolicySpec{
+		PolicyID:         signed.ID,
+		PolicyType:       policyv1alpha1.PolicyTypeGatewayHTTPRoute,
+		Content:          signed.YAML,
+		TargetNamespaces: []string{signed.Namespace},
+		Version:          signed.Version,
+	}
+	signed.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(signingKey, policy.SignedPayload(&spec)))
+	signed.SignatureKeyID = "saas-2026"
+	unsigned := saas.GatewayAPIResource{ID: "route-2", Kind: "HTTPRoute", Name: "api", Namespace: "shop",
+		YAML: strings.ReplaceAll(route, "name: web", "name: api")}
+
+	var alerts []saas.SecurityAlert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/operator/gateway-api":
+			json.NewEncoder(w).Encode(saas.FetchGatewayAPIResponse{
+				Success:   true,
+				Resources: []saas.GatewayAPIResource{signed, unsigned},
+				Count:     2,
+			})
+		case "/api/operator/alerts":
+			var alert saas.SecurityAlert
+			json.NewDecoder(r.Body).Decode(&alert)
+			alerts = append(alerts, alert)
+			w.Write([]byte(`{"success":true}`))
+		default:
+			json.NewEncoder(w).Encode(saas.UpdatePolicyStatusResponse{Success: true})
+		}
+	}))
+	defer server.Close()
+
+	httpRoute := schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "HTTPRoute"}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(httpRoute, meta.RESTScopeNamespace)
+	mapper.Add(policyv1alpha1.GroupVersion.WithKind("ManagedPolicy"), meta.RESTScopeNamespace)
+	c := fake.NewClientBuilder().
+		WithScheme(testScheme()).
+		WithRESTMapper(mapper).
+		WithStatusSubresource(&policyv1alpha1.ManagedPolicy{}).
+		Build()
+	ctx := context.Background()
+
+	r := NewReconciler(c, testLogger())
+	r.deployer = policy.NewDeployer(c, testLogger())
+	r.saasClient = saas.NewClient(server.URL, "test-token", "cluster-id", testLogger())
+	r.config = trustedKeyConfig(signingKey)
+	r.config.Namespace = "policy-hub-system"
+
+	if err := r.SyncGatewayAPIResources(ctx); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	policies := &policyv1alpha1.ManagedPolicyList{}
+	if err := c.List(ctx, policies); err != nil {
+		t.Fatalf("Failed to list policies: %v", err)
+	}
+	if len(policies.Items) != 2 {
+		t.Fatalf("Expected 2 Gateway API policies, got %d", len(policies.Items))
+	}
+
+	for i := range policies.Items {
+		mp := &policies.Items[i]
+		if err := r.ReconcilePolicy(ctx, mp); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		cond := meta.FindStatusCondition(mp.Status.Conditions, ConditionTypeSignatureVerified)
+		switch mp.Spec.PolicyID {
+		case signed.ID:
+			if mp.Spec.Version != signed.Version || mp.Status.Phase != policyv1alpha1.ManagedPolicyPhaseDeployed {
+				t.Errorf("Expected the signed route deployed at version %d, got version %d in phase %s (%s)",
+					signed.Version, mp.Spec.Version, mp.Status.Phase, mp.Status.LastError)
+			}
+			if cond == nil || cond.Status != metav1.ConditionTrue {
+				t.Errorf("Expected SignatureVerified True, got %+v", cond)
+			}
+		case unsigned.ID:
+			if mp.Status.Phase != policyv1alpha1.ManagedPolicyPhaseFailed || cond == nil || cond.Reason != "SignatureMissing" {
+				t.Errorf("Expected the unsigned route refused, got phase %s and %+v", mp.Status.Phase, cond)
+			}
+		}
+	}
+	if len(alerts) != 1 || alerts[0].PolicyID != unsigned.ID {
+		t.Errorf("Expected a single alert for the unsigned route, got %+v", alerts)
+	}
+}
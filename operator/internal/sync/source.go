@@ -0,0 +1,217 @@
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/policy-hub/operator/internal/saas"
+)
+
+// PolicySource is where policies are synced from and their status reported
+// to. The SaaS client is the default source.
+type PolicySource interface {
+	// FetchPoliciesIfChanged returns the policies unless they did not change
+	// since the response with the given ETag
+	FetchPoliciesIfChanged(ctx context.Context, etag string) (*saas.FetchPoliciesResponse, error)
+	UpdatePolicyStatus(ctx context.Context, policyID string, req saas.UpdatePolicyStatusRequest) (*saas.UpdatePolicyStatusResponse, error)
+	ReportUndeployStatus(ctx context.Context, policyID string, success bool, errorMsg string) error
+	ReportSecurityAlert(ctx context.Context, alert saas.SecurityAlert) error
+}
+
+var (
+	_ PolicySource = &saas.Client{}
+	_ PolicySource = &DirectorySource{}
+)
+
+// Files of a policy in a DirectorySource
+const (
+	policyMetadataFile = "policy.yaml"
+	policyContentFile  = "content.yaml"
+	// allowEmptyFile marks a directory that is not a Git checkout as
+	// intentionally holding no policies
+	allowEmptyFile = ".allow-empty"
+)
+
+// DirectorySource syncs policies from a local directory, such as a Git
+// checkout kept up to date by a git-sync sidecar, for clusters that cannot
+// reach the SaaS platform. Each policy is a subdirectory:
+//
+//	<path>/
+//	  <policy>/
+//	    policy.yaml   # id, name, description, type, version, targetNamespaces,
+//	                  # namespaceSelector, mode, driftMode, rollout, signature
+//	                  # and signatureKeyId, as sent by the SaaS platform
+//	    content.yaml  # the policy manifests
+//
+// The policy ID defaults to the directory name, the name to the ID and the
+// version to 1; content changes are deployed without a new version.
+// Subdirectories without a policy.yaml, and hidden ones such as .git, are
+// skipped. A directory without policies is only read as empty when it is a
+// Git checkout or holds an .allow-empty file, so a volume that is not yet
+// populated does not delete every policy. Policy status is written to a report ConfigMap instead of being
+// sent to the SaaS platform.
+type DirectorySource struct {
+	path   string
+	client client.Client
+	report types.NamespacedName
+	log    logr.Logger
+
+	mu sync.Mutex // Serializes report updates
+}
+
+// NewDirectorySource creates a source reading policies from path and writing
+// their status to the report ConfigMap
+func NewDirectorySource(path string, c client.Client, report types.NamespacedName, log logr.Logger) *DirectorySource {
+	return &DirectorySource{
+		path:   path,
+		client: c,
+		report: report,
+		log:    log.WithName("directory-source"),
+	}
+}
+
+// FetchPoliciesIfChanged reads the policies in the directory. The ETag is a
+// hash of the policy files, so policies that did not change are reported not
+// modified.
+func (s *DirectorySource) FetchPoliciesIfChanged(ctx context.Context, etag string) (*saas.FetchPoliciesResponse, error) {
+	entries, err := os.ReadDir(s.path)
+	if err != nil {
+		// A missing directory must not delete every policy
+		return nil, fmt.Errorf("failed to read policy directory: %w", err)
+	}
+
+	hash := sha256.New()
+	var policies []saas.Policy
+	ids := make(map[string]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		dir := filepath.Join(s.path, name)
+		// Stat follows symlinks, such as the worktree links of git-sync
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		metadata, err := os.ReadFile(filepath.Join(dir, policyMetadataFile))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filepath.Join(name, policyMetadataFile), err)
+		}
+		content, err := os.ReadFile(filepath.Join(dir, policyContentFile))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read %s: %w", filepath.Join(name, policyContentFile), err)
+		}
+		fmt.Fprintf(hash, "%s\n%d\n%s\n%d\n%s\n", name, len(metadata), metadata, len(content), content)
+
+		p, err := parseDirectoryPolicy(name, metadata, content)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := ids[p.ID]; ok {
+			return nil, fmt.Errorf("policies %s and %s have the same ID %s", other, name, p.ID)
+		}
+		ids[p.ID] = name
+		policies = append(policies, p)
+	}
+
+	revision := gitRevision(s.path)
+	if len(policies) == 0 && revision == "" {
+		if _, err := os.Stat(filepath.Join(s.path, allowEmptyFile)); err != nil {
+			return nil, fmt.Errorf("policy directory %s has no policies and is not a Git checkout: add %s to remove every policy", s.path, allowEmptyFile)
+		}
+	}
+
+	newETag := hex.EncodeToString(hash.Sum(nil))
+	if etag != "" && etag == newETag {
+		return &saas.FetchPoliciesResponse{Success: true, ETag: etag, NotModified: true}, nil
+	}
+
+	s.log.Info("Read policies from directory", "path", s.path, "count", len(policies), "revision", revision)
+	if err := s.updateReport(ctx, func(report *LocalReport) {
+		report.Path = s.path
+		report.Revision = revision
+		report.Policies = len(policies)
+	}); err != nil {
+		s.log.Error(err, "Failed to update policy report")
+	}
+
+	return &saas.FetchPoliciesResponse{
+		Success:  true,
+		Policies: policies,
+		Count:    len(policies),
+		ETag:     newETag,
+	}, nil
+}
+
+// parseDirectoryPolicy parses the files of the policy in directory dir
+func parseDirectoryPolicy(dir string, metadata, content []byte) (saas.Policy, error) {
+	var p saas.Policy
+	if err := yaml.UnmarshalStrict(metadata, &p); err != nil {
+		return p, fmt.Errorf("invalid %s: %w", filepath.Join(dir, policyMetadataFile), err)
+	}
+	if len(content) > 0 {
+		p.Content = string(content)
+	}
+	if strings.TrimSpace(p.Content) == "" {
+		return p, fmt.Errorf("policy %s has no content: add %s", dir, policyContentFile)
+	}
+	if p.ID == "" {
+		p.ID = dir
+	}
+	if p.Name == "" {
+		p.Name = p.ID
+	}
+	if p.Version == 0 {
+		p.Version = 1
+	}
+	if p.Action == "" {
+		p.Action = "DEPLOY"
+	}
+	return p, nil
+}
+
+// gitRevision returns the commit checked out in dir, or "" if dir is not a
+// Git checkout
+func gitRevision(dir string) string {
+	gitDir := filepath.Join(dir, ".git")
+	// A worktree, such as a git-sync checkout, links to its Git directory
+	if data, err := os.ReadFile(gitDir); err == nil {
+		if path, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: "); ok {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			gitDir = path
+		}
+	}
+
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	ref, ok := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: ")
+	if !ok {
+		return strings.TrimSpace(string(head)) // Detached
+	}
+	if commit, err := os.ReadFile(filepath.Join(gitDir, ref)); err == nil {
+		return strings.TrimSpace(string(commit))
+	}
+	packed, _ := os.ReadFile(filepath.Join(gitDir, "packed-refs"))
+	for _, line := range strings.Split(string(packed), "\n") {
+		if commit, name, ok := strings.Cut(line, " "); ok && name == ref {
+			return commit
+		}
+	}
+	return ""
+}
@@ -0,0 +1,368 @@
+package sync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+	"github.com/policy-hub/operator/internal/policy"
+	"github.com/policy-hub/operator/internal/saas"
+)
+
+const testPolicyContent = `apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: api
+spec:
+  endpointSelector: {}
+`
+
+// writeFiles writes files, keyed by slash-separated path, under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func newTestDirectorySource(path string) *DirectorySource {
+	report := types.NamespacedName{Namespace: "default", Name: "policy-hub-report"}
+	return NewDirectorySource(path, newFakeClient(), report, testLogger())
+}
+
+func TestDirectorySource_FetchPoliciesIfChanged(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reads policies with defaults", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{
+			"api/policy.yaml":  "name: API\ntype: CILIUM_NETWORK\ntargetNamespaces: [prod]\nversion: 3\n",
+			"api/content.yaml": testPolicyContent,
+			"db/policy.yaml":   "id: db-policy\ntype: CILIUM_NETWORK\ncontent: |\n  kind: CiliumNetworkPolicy\n",
+			"docs/README.md":   "not a policy",
+			".git/policy.yaml": "name: hidden\ncontent: x\n",
+			"README.md":        "policies",
+		})
+		s := newTestDirectorySource(dir)
+
+		resp, err := s.FetchPoliciesIfChanged(ctx, "")
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if resp.Count != 2 || len(resp.Policies) != 2 || resp.ETag == "" {
+			t.Fatalf("Expected 2 policies with an ETag, got %+v", resp)
+		}
+		api, db := resp.Policies[0], resp.Policies[1]
+		if api.ID != "api" || api.Name != "API" || api.Version != 3 || api.Action != "DEPLOY" ||
+			api.Content != testPolicyContent || len(api.TargetNamespaces) != 1 {
+			t.Errorf("Unexpected api policy: %+v", api)
+		}
+		if db.ID != "db-policy" || db.Name != "db-policy" || db.Version != 1 || db.Content != "kind: CiliumNetworkPolicy\n" {
+			t.Errorf("Unexpected db policy: %+v", db)
+		}
+
+		report, err := s.GetReport(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get report: %v", err)
+		}
+		if report.Path != dir || report.Policies != 2 {
+			t.Errorf("Expected report of 2 policies in %s, got %+v", dir, report)
+		}
+	})
+
+	t.Run("not modified until a file changes", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{
+			"api/policy.yaml":  "type: CILIUM_NETWORK\n",
+			"api/content.yaml": testPolicyContent,
+		})
+		s := newTestDirectorySource(dir)
+
+		first, err := s.FetchPoliciesIfChanged(ctx, "")
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		second, err := s.FetchPoliciesIfChanged(ctx, first.ETag)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if !second.NotModified {
+			t.Error("Expected unchanged policies to be not modified")
+		}
+
+		writeFiles(t, dir, map[string]string{"api/content.yaml": testPolicyContent + "  ingress: []\n"})
+		third, err := s.FetchPoliciesIfChanged(ctx, first.ETag)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if third.NotModified || third.ETag == first.ETag {
+			t.Error("Expected changed content to be modified")
+		}
+	})
+
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:    "unknown field",
+			files:   map[string]string{"api/policy.yaml": "namespaces: [prod]\ncontent: x\n"},
+			wantErr: "invalid api/policy.yaml",
+		},
+		{
+			name:    "no content",
+			files:   map[string]string{"api/policy.yaml": "type: CILIUM_NETWORK\n"},
+			wantErr: "policy api has no content",
+		},
+		{
+			name: "duplicate ID",
+			files: map[string]string{
+				"a/policy.yaml": "id: same\ncontent: x\n",
+				"b/policy.yaml": "id: same\ncontent: x\n",
+			},
+			wantErr: "have the same ID same",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+			_, err := newTestDirectorySource(dir).FetchPoliciesIfChanged(ctx, "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	t.Run("empty directory", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{"docs/README.md": "not a policy"})
+		s := newTestDirectorySource(dir)
+
+		if _, err := s.FetchPoliciesIfChanged(ctx, ""); err == nil || !strings.Contains(err.Error(), "has no policies") {
+			t.Fatalf("Expected an empty directory to be refused, got: %v", err)
+		}
+
+		writeFiles(t, dir, map[string]string{".allow-empty": ""})
+		resp, err := s.FetchPoliciesIfChanged(ctx, "")
+		if err != nil {
+			t.Fatalf("Expected no error with %s, got: %v", allowEmptyFile, err)
+		}
+		if resp.Count != 0 || len(resp.Policies) != 0 {
+			t.Errorf("Expected no policies, got %+v", resp)
+		}
+	})
+
+	t.Run("empty Git checkout", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{".git/HEAD": "0123456789abcdef0123456789abcdef01234567\n"})
+
+		resp, err := newTestDirectorySource(dir).FetchPoliciesIfChanged(ctx, "")
+		if err != nil {
+			t.Fatalf("Expected no error for a Git checkout, got: %v", err)
+		}
+		if resp.Count != 0 {
+			t.Errorf("Expected no policies, got %+v", resp)
+		}
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		_, err := newTestDirectorySource(filepath.Join(t.TempDir(), "missing")).FetchPoliciesIfChanged(ctx, "")
+		if err == nil {
+			t.Error("Expected error for missing directory")
+		}
+	})
+}
+
+func TestGitRevision(t *testing.T) {
+	const commit = "0123456789abcdef0123456789abcdef01234567"
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name:  "branch ref",
+			files: map[string]string{".git/HEAD": "ref: refs/heads/main\n", ".git/refs/heads/main": commit + "\n"},
+			want:  commit,
+		},
+		{
+			name:  "detached",
+			files: map[string]string{".git/HEAD": commit + "\n"},
+			want:  commit,
+		},
+		{
+			name: "packed ref",
+			files: map[string]string{
+				".git/HEAD":        "ref: refs/heads/main\n",
+				".git/packed-refs": "# pack-refs with: peeled\n" + commit + " refs/heads/main\n",
+			},
+			want: commit,
+		},
+		{
+			name: "worktree",
+			files: map[string]string{
+				".git":                       "gitdir: ../repo/worktrees/rev\n",
+				"../repo/worktrees/rev/HEAD": commit + "\n",
+			},
+			want: commit,
+		},
+		{
+			name:  "not a checkout",
+			files: map[string]string{"api/policy.yaml": "content: x\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "policies")
+			writeFiles(t, dir, tt.files)
+			if got := gitRevision(dir); got != tt.want {
+				t.Errorf("gitRevision() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirectorySource_Report(t *testing.T) {
+	ctx := context.Background()
+	s := newTestDirectorySource(t.TempDir())
+
+	if _, err := s.UpdatePolicyStatus(ctx, "policy-1", saas.UpdatePolicyStatusRequest{Status: "DEPLOYED", Version: 2}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if _, err := s.UpdatePolicyStatus(ctx, "policy-2", saas.UpdatePolicyStatusRequest{Status: "DEPLOYED", Version: 1}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if err := s.ReportUndeployStatus(ctx, "policy-2", true, ""); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	for i := 0; i < maxReportAlerts+5; i++ {
+		if err := s.ReportSecurityAlert(ctx, saas.SecurityAlert{Type: saas.AlertPolicySignatureInvalid, Version: i}); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+
+	report, err := s.GetReport(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get report: %v", err)
+	}
+	cm := &corev1.ConfigMap{}
+	if err := s.client.Get(ctx, s.report, cm); err != nil {
+		t.Fatalf("Failed to get report ConfigMap: %v", err)
+	}
+	if cm.Labels[policy.ManagedByLabel] != "policy-hub-operator" {
+		t.Errorf("Expected report ConfigMap to be labeled as managed, got %v", cm.Labels)
+	}
+	if len(report.Status) != 1 || report.Status["policy-1"].Status != "DEPLOYED" || report.Status["policy-1"].Version != 2 {
+		t.Errorf("Expected only policy-1 DEPLOYED at version 2, got %+v", report.Status)
+	}
+	if len(report.Alerts) != maxReportAlerts || report.Alerts[0].Version != 5 {
+		t.Errorf("Expected the %d most recent alerts, got %d starting at version %d",
+			maxReportAlerts, len(report.Alerts), report.Alerts[0].Version)
+	}
+}
+
+func TestSyncPolicies_DirectorySource(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"api/policy.yaml":  "name: API\ntype: CILIUM_NETWORK\n",
+		"api/content.yaml": testPolicyContent,
+	})
+
+	cnp := schema.GroupVersionKind{Group: "cilium.io", Version: "v2", Kind: "CiliumNetworkPolicy"}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(cnp, meta.RESTScopeNamespace)
+	mapper.Add(policyv1alpha1.GroupVersion.WithKind("ManagedPolicy"), meta.RESTScopeNamespace)
+
+	config := &policyv1alpha1.PolicyHubConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "policy-hub-system"},
+		Spec: policyv1alpha1.PolicyHubConfigSpec{
+			PolicySource: &policyv1alpha1.PolicySourceSpec{Type: policyv1alpha1.PolicySourceDirectory, Path: dir},
+		},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(testScheme()).
+		WithRESTMapper(mapper).
+		WithObjects(config).
+		WithStatusSubresource(&policyv1alpha1.ManagedPolicy{}, &policyv1alpha1.PolicyHubConfig{}).
+		Build()
+	ctx := context.Background()
+
+	r := NewReconciler(c, testLogger())
+	if err := r.Initialize(ctx, config); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !r.IsOffline() || !r.IsRegistered() || r.saasClient != nil {
+		t.Fatal("Expected an offline reconciler without a SaaS client")
+	}
+
+	if err := r.SyncPolicies(ctx); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	policies := &policyv1alpha1.ManagedPolicyList{}
+	if err := c.List(ctx, policies); err != nil {
+		t.Fatalf("Failed to list policies: %v", err)
+	}
+	if len(policies.Items) != 1 || policies.Items[0].Spec.PolicyID != "api" {
+		t.Fatalf("Expected ManagedPolicy for api, got %+v", policies.Items)
+	}
+
+	mp := &policies.Items[0]
+	if err := r.ReconcilePolicy(ctx, mp); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if mp.Status.Phase != policyv1alpha1.ManagedPolicyPhaseDeployed {
+		t.Errorf("Expected phase Deployed, got %s", mp.Status.Phase)
+	}
+
+	report, err := r.source.(*DirectorySource).GetReport(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get report: %v", err)
+	}
+	if status := report.Status["api"]; status.Status != "DEPLOYED" || status.Version != 1 {
+		t.Errorf("Expected api DEPLOYED at version 1 in the report, got %+v", report.Status)
+	}
+
+	// Reinitializing keeps the source
+	source := r.source
+	if err := r.Initialize(ctx, config); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if r.source != source {
+		t.Error("Expected the directory source to be kept")
+	}
+
+	// An emptied directory that is not a Git checkout keeps the policies
+	if err := os.RemoveAll(filepath.Join(dir, "api")); err != nil {
+		t.Fatalf("Failed to remove policy: %v", err)
+	}
+	if err := r.SyncPolicies(ctx); err == nil {
+		t.Error("Expected syncing an empty directory to fail")
+	}
+	if err := c.List(ctx, policies); err != nil {
+		t.Fatalf("Failed to list policies: %v", err)
+	}
+	if len(policies.Items) != 1 {
+		t.Errorf("Expected the ManagedPolicy to be kept, got %d", len(policies.Items))
+	}
+}
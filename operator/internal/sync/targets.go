@@ -0,0 +1,132 @@
+package sync
+
+import (
+	"context"
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+	"github.com/policy-hub/operator/internal/policy"
+	"github.com/policy-hub/operator/internal/saas"
+)
+
+// namespaceSelector converts the namespace labels of a SaaS policy to a label selector
+func namespaceSelector(saasPolicy saas.Policy) *metav1.LabelSelector {
+	if len(saasPolicy.NamespaceSelector) == 0 {
+		return nil
+	}
+	return &metav1.LabelSelector{MatchLabels: saasPolicy.NamespaceSelector}
+}
+
+// deployedNamespaces returns the target namespaces of the last deployment,
+// or the static target namespaces of a policy deployed before they were recorded
+func deployedNamespaces(mp *policyv1alpha1.ManagedPolicy) []string {
+	if len(mp.Status.Namespaces) > 0 {
+		return mp.Status.Namespaces
+	}
+	return policy.TargetNamespaces(mp)
+}
+
+// namespacesChanged returns true if namespaces started or stopped matching
+// the namespace selector of a deployed policy
+func (r *Reconciler) namespacesChanged(ctx context.Context, mp *policyv1alpha1.ManagedPolicy) bool {
+	if mp.Spec.NamespaceSelector == nil {
+		return false
+	}
+	namespaces, err := r.deployer.ResolveNamespaces(ctx, mp)
+	if err != nil {
+		r.log.Error(err, "Failed to resolve target namespaces", "policy", mp.Name)
+		return false
+	}
+	return !slices.Equal(namespaces, mp.Status.Namespaces)
+}
+
+// targetStatuses returns the status of each target of a deployment. Targets
+// that failed keep the version and generation they last ran.
+func targetStatuses(previous []policyv1alpha1.TargetStatus, results []policy.TargetResult, mp *policyv1alpha1.ManagedPolicy) []policyv1alpha1.TargetStatus {
+	last := make(map[string]policyv1alpha1.TargetStatus, len(previous))
+	for _, ts := range previous {
+		last[ts.Namespace+"/"+ts.Resource] = ts
+	}
+
+	statuses := make([]policyv1alpha1.TargetStatus, 0, len(results))
+	for _, result := range results {
+		ts := policyv1alpha1.TargetStatus{
+			Namespace: result.Resource.Namespace,
+			Resource:  result.Resource.Kind + "/" + result.Resource.Name,
+		}
+		if result.Error != nil {
+			prev := last[ts.Namespace+"/"+ts.Resource]
+			ts.Version = prev.Version
+			ts.ObservedGeneration = prev.ObservedGeneration
+			ts.Error = result.Error.Error()
+		} else {
+			ts.Version = mp.Spec.Version
+			ts.ObservedGeneration = mp.Generation
+		}
+		statuses = append(statuses, ts)
+	}
+	return statuses
+}
+
+// mergeTargetStatuses replaces the statuses in current with those in updated
+// for the same targets and adds the others
+func mergeTargetStatuses(current, updated []policyv1alpha1.TargetStatus) []policyv1alpha1.TargetStatus {
+	index := make(map[string]int, len(current))
+	merged := append([]policyv1alpha1.TargetStatus{}, current...)
+	for i, ts := range merged {
+		index[ts.Namespace+"/"+ts.Resource] = i
+	}
+	for _, ts := range updated {
+		if i, ok := index[ts.Namespace+"/"+ts.Resource]; ok {
+			merged[i] = ts
+			continue
+		}
+		merged = append(merged, ts)
+	}
+	return merged
+}
+
+// deployedAfter returns the resources running after a deployment: those it
+// applied, and the previously deployed resources of the targets that failed
+func deployedAfter(previous []policyv1alpha1.DeployedResource, result policy.DeployResult) []policyv1alpha1.DeployedResource {
+	failed := make(map[string]bool)
+	for _, target := range result.Targets {
+		if target.Error != nil {
+			failed[resourceKey(target.Resource)] = true
+		}
+	}
+	deployed := append([]policyv1alpha1.DeployedResource{}, result.DeployedResources...)
+	for _, res := range previous {
+		if failed[resourceKey(res)] {
+			deployed = append(deployed, res)
+		}
+	}
+	return deployed
+}
+
+// targetResources returns every resource a deployment targeted, applied or not
+func targetResources(result policy.DeployResult) []policyv1alpha1.DeployedResource {
+	resources := make([]policyv1alpha1.DeployedResource, len(result.Targets))
+	for i, target := range result.Targets {
+		resources[i] = target.Resource
+	}
+	return resources
+}
+
+func toSaaSTargets(targets []policyv1alpha1.TargetStatus) []saas.TargetStatus {
+	if len(targets) == 0 {
+		return nil
+	}
+	result := make([]saas.TargetStatus, len(targets))
+	for i, ts := range targets {
+		result[i] = saas.TargetStatus{
+			Namespace: ts.Namespace,
+			Resource:  ts.Resource,
+			Version:   ts.Version,
+			Error:     ts.Error,
+		}
+	}
+	return result
+}
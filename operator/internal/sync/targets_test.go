@@ -0,0 +1,189 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+	"github.com/policy-hub/operator/internal/policy"
+	"github.com/policy-hub/operator/internal/saas"
+)
+
+func TestReconcilePolicy_NamespaceSelector(t *testing.T) {
+	var reports []saas.UpdatePolicyStatusRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req saas.UpdatePolicyStatusRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		reports = append(reports, req)
+		json.NewEncoder(w).Encode(saas.UpdatePolicyStatusResponse{Success: true})
+	}))
+	defer server.Close()
+
+	cnp := schema.GroupVersionKind{Group: "cilium.io", Version: "v2", Kind: "CiliumNetworkPolicy"}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(cnp, meta.RESTScopeNamespace)
+	mapper.Add(policyv1alpha1.GroupVersion.WithKind("ManagedPolicy"), meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), meta.RESTScopeRoot)
+
+	mp := &policyv1alpha1.ManagedPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-policy", Namespace: "policy-hub-system"},
+		Spec: policyv1alpha1.ManagedPolicySpec{
+			PolicyID:   "policy-1",
+			Name:       "Test Policy",
+			PolicyType: policyv1alpha1.PolicyTypeCiliumNetwork,
+			Content: `apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: api
+spec:
+  endpointSelector: {}
+`,
+			TargetNamespaces:  []string{"default"},
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+			Version:           1,
+		},
+	}
+	payments := func(name string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"team": "payments"}}}
+	}
+
+	// Applying to payments-b fails until failApply is cleared
+	failApply := true
+	c := fake.NewClientBuilder().
+		WithScheme(testScheme()).
+		WithRESTMapper(mapper).
+		WithObjects(mp, payments("payments-a"), payments("payments-b")).
+		WithStatusSubresource(&policyv1alpha1.ManagedPolicy{}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Apply: func(ctx context.Context, c client.WithWatch, obj runtime.ApplyConfiguration, opts ...client.ApplyOption) error {
+				if o, ok := obj.(interface{ GetNamespace() string }); ok && failApply && o.GetNamespace() == "payments-b" {
+					return fmt.Errorf("admission webhook denied the request")
+				}
+				return c.Apply(ctx, obj, opts...)
+			},
+		}).
+		Build()
+
+	r := NewReconciler(c, testLogger())
+	r.deployer = policy.NewDeployer(c, testLogger())
+	r.saasClient = saas.NewClient(server.URL, "test-token", "cluster-id", testLogger())
+	ctx := context.Background()
+	key := client.ObjectKeyFromObject(mp)
+
+	getPolicy := func() *policyv1alpha1.ManagedPolicy {
+		t.Helper()
+		mp := &policyv1alpha1.ManagedPolicy{}
+		if err := c.Get(ctx, key, mp); err != nil {
+			t.Fatalf("Failed to get policy: %v", err)
+		}
+		return mp
+	}
+	deployedIn := func(namespace string) bool {
+		t.Helper()
+		obj := &metav1.PartialObjectMetadata{}
+		obj.SetGroupVersionKind(cnp)
+		err := c.Get(ctx, client.ObjectKey{Name: "api", Namespace: namespace}, obj)
+		if err != nil && !errors.IsNotFound(err) {
+			t.Fatalf("Failed to get deployed resource: %v", err)
+		}
+		return err == nil
+	}
+	targetErrors := func(mp *policyv1alpha1.ManagedPolicy) map[string]string {
+		errs := make(map[string]string)
+		for _, ts := range mp.Status.Targets {
+			errs[ts.Namespace] = ts.Error
+		}
+		return errs
+	}
+
+	t.Run("deploys to the other targets when one fails", func(t *testing.T) {
+		if err := r.ReconcilePolicy(ctx, getPolicy()); err == nil {
+			t.Fatal("Expected an error when a target fails")
+		}
+		mp := getPolicy()
+		if mp.Status.Phase != policyv1alpha1.ManagedPolicyPhasePartiallyDeployed || mp.Status.DeployedVersion != 0 {
+			t.Errorf("Expected phase PartiallyDeployed without a deployed version, got %s version %d", mp.Status.Phase, mp.Status.DeployedVersion)
+		}
+		errs := targetErrors(mp)
+		if len(errs) != 3 || errs["default"] != "" || errs["payments-a"] != "" || errs["payments-b"] == "" {
+			t.Errorf("Expected payments-b to fail and the other targets to deploy, got %+v", mp.Status.Targets)
+		}
+		if !deployedIn("default") || !deployedIn("payments-a") || deployedIn("payments-b") {
+			t.Error("Expected the resource in default and payments-a only")
+		}
+		last := reports[len(reports)-1]
+		if last.Status != "PARTIALLY_DEPLOYED" || len(last.Targets) != 3 {
+			t.Errorf("Expected PARTIALLY_DEPLOYED report with every target, got %+v", last)
+		}
+	})
+
+	t.Run("retries the failed target", func(t *testing.T) {
+		failApply = false
+		if err := r.ReconcilePolicy(ctx, getPolicy()); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		mp := getPolicy()
+		if mp.Status.Phase != policyv1alpha1.ManagedPolicyPhaseDeployed || mp.Status.DeployedVersion != 1 {
+			t.Errorf("Expected phase Deployed at version 1, got %s version %d", mp.Status.Phase, mp.Status.DeployedVersion)
+		}
+		for _, ts := range mp.Status.Targets {
+			if ts.Error != "" || ts.Version != 1 {
+				t.Errorf("Expected every target at version 1, got %+v", ts)
+			}
+		}
+		if len(mp.Status.DeployedResources) != 3 || !deployedIn("payments-b") {
+			t.Errorf("Expected the resource in every target, got %+v", mp.Status.DeployedResources)
+		}
+	})
+
+	t.Run("deploys to a new matching namespace", func(t *testing.T) {
+		if err := c.Create(ctx, payments("payments-c")); err != nil {
+			t.Fatalf("Failed to create namespace: %v", err)
+		}
+		if err := r.ReconcilePolicy(ctx, getPolicy()); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		mp := getPolicy()
+		if !deployedIn("payments-c") {
+			t.Error("Expected the resource in the new namespace")
+		}
+		if len(mp.Status.Namespaces) != 4 || mp.Status.Namespaces[3] != "payments-c" {
+			t.Errorf("Expected the new namespace in status, got %v", mp.Status.Namespaces)
+		}
+	})
+
+	t.Run("deletes from a namespace that stopped matching", func(t *testing.T) {
+		ns := &corev1.Namespace{}
+		if err := c.Get(ctx, client.ObjectKey{Name: "payments-a"}, ns); err != nil {
+			t.Fatalf("Failed to get namespace: %v", err)
+		}
+		ns.Labels = map[string]string{"team": "search"}
+		if err := c.Update(ctx, ns); err != nil {
+			t.Fatalf("Failed to update namespace: %v", err)
+		}
+		if err := r.ReconcilePolicy(ctx, getPolicy()); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		mp := getPolicy()
+		if deployedIn("payments-a") {
+			t.Error("Expected the resource to be deleted from payments-a")
+		}
+		if _, ok := targetErrors(mp)["payments-a"]; ok || len(mp.Status.DeployedResources) != 3 {
+			t.Errorf("Expected payments-a to be removed from status, got %+v", mp.Status.Targets)
+		}
+	})
+}
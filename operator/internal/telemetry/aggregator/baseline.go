@@ -0,0 +1,88 @@
+package aggregator
+
+import (
+	"sort"
+	"time"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+)
+
+// ProcessBaseline is the complete process behaviour of a pod in the current
+// aggregation window. Unlike ProcessEventSummary, which only carries the top
+// binaries for SaaS sync, it lists every binary and sensitive file access so
+// it can back an allowlist.
+type ProcessBaseline struct {
+	WindowStart time.Time
+	WindowEnd   time.Time
+	Namespace   string
+	PodName     string
+	PodLabels   map[string]string
+
+	TotalExecs int64
+	// Binaries holds every executed binary, most frequent first
+	Binaries []models.BinaryCount
+	// SensitiveFiles holds accesses to sensitive paths, most frequent first
+	SensitiveFiles []SensitiveFileCount
+}
+
+// SensitiveFileCount tracks how often a binary accessed a sensitive path.
+type SensitiveFileCount struct {
+	Binary string
+	Path   string
+	Count  int64
+}
+
+// ProcessBaselines returns the process baselines of the current window,
+// ordered by namespace and pod. It does not reset the window.
+func (s *Summarizer) ProcessBaselines() []ProcessBaseline {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	baselines := make([]ProcessBaseline, 0, len(s.procSummaries))
+	for _, agg := range s.procSummaries {
+		baseline := ProcessBaseline{
+			WindowStart: s.windowStart,
+			WindowEnd:   s.windowEnd,
+			Namespace:   agg.Namespace,
+			PodName:     agg.PodName,
+			PodLabels:   agg.PodLabels,
+			TotalExecs:  agg.TotalExecs,
+			Binaries:    topBinaries(agg.BinaryCounts, len(agg.BinaryCounts)),
+		}
+		sort.SliceStable(baseline.Binaries, func(i, j int) bool {
+			a, b := baseline.Binaries[i], baseline.Binaries[j]
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+			return a.Binary < b.Binary
+		})
+
+		for key, count := range agg.SensitiveFileCounts {
+			baseline.SensitiveFiles = append(baseline.SensitiveFiles, SensitiveFileCount{
+				Binary: key.Binary,
+				Path:   key.Path,
+				Count:  count,
+			})
+		}
+		sort.Slice(baseline.SensitiveFiles, func(i, j int) bool {
+			a, b := baseline.SensitiveFiles[i], baseline.SensitiveFiles[j]
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+			if a.Binary != b.Binary {
+				return a.Binary < b.Binary
+			}
+			return a.Path < b.Path
+		})
+
+		baselines = append(baselines, baseline)
+	}
+
+	sort.Slice(baselines, func(i, j int) bool {
+		if baselines[i].Namespace != baselines[j].Namespace {
+			return baselines[i].Namespace < baselines[j].Namespace
+		}
+		return baselines[i].PodName < baselines[j].PodName
+	})
+	return baselines
+}
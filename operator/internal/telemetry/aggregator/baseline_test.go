@@ -0,0 +1,59 @@
+package aggregator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+)
+
+func TestSummarizer_ProcessBaselines(t *testing.T) {
+	summarizer := NewSummarizer(SummarizerConfig{
+		NodeName: "test-node",
+		Logger:   logr.Discard(),
+	})
+
+	labels := map[string]string{"app": "api"}
+	events := []*models.TelemetryEvent{
+		{EventType: models.EventTypeProcessExec, SrcNamespace: "prod", SrcPodName: "api-1", SrcPodLabels: labels, SrcBinary: "/usr/bin/api"},
+		{EventType: models.EventTypeProcessExec, SrcNamespace: "prod", SrcPodName: "api-1", SrcPodLabels: labels, SrcBinary: "/bin/sh"},
+		{EventType: models.EventTypeProcessExec, SrcNamespace: "prod", SrcPodName: "api-1", SrcPodLabels: labels, SrcBinary: "/usr/bin/api"},
+		{EventType: models.EventTypeFileAccess, SrcNamespace: "prod", SrcPodName: "api-1", SrcBinary: "/usr/bin/api", FileOperation: "security_file_open", FilePath: "/etc/passwd"},
+		{EventType: models.EventTypeFileAccess, SrcNamespace: "prod", SrcPodName: "api-1", SrcBinary: "/usr/bin/api", FileOperation: "security_file_open", FilePath: "/tmp/cache"},
+		{EventType: models.EventTypeProcessExec, SrcNamespace: "dev", SrcPodName: "shell", SrcBinary: "/bin/bash"},
+	}
+	for _, event := range events {
+		event.Timestamp = time.Now()
+		summarizer.AddEvent(event)
+	}
+
+	baselines := summarizer.ProcessBaselines()
+	if len(baselines) != 2 {
+		t.Fatalf("len(baselines) = %d, want 2", len(baselines))
+	}
+	if baselines[0].Namespace != "dev" || baselines[1].PodName != "api-1" {
+		t.Errorf("baselines not ordered by namespace and pod: %s/%s, %s/%s",
+			baselines[0].Namespace, baselines[0].PodName, baselines[1].Namespace, baselines[1].PodName)
+	}
+
+	api := baselines[1]
+	if api.PodLabels["app"] != "api" {
+		t.Errorf("PodLabels = %v, want app=api", api.PodLabels)
+	}
+	if api.TotalExecs != 3 {
+		t.Errorf("TotalExecs = %d, want 3", api.TotalExecs)
+	}
+	if len(api.Binaries) != 2 || api.Binaries[0].Binary != "/usr/bin/api" || api.Binaries[0].Count != 2 {
+		t.Errorf("Binaries = %+v, want /usr/bin/api first with 2 execs", api.Binaries)
+	}
+	if len(api.SensitiveFiles) != 1 || api.SensitiveFiles[0].Path != "/etc/passwd" || api.SensitiveFiles[0].Binary != "/usr/bin/api" {
+		t.Errorf("SensitiveFiles = %+v, want only /etc/passwd", api.SensitiveFiles)
+	}
+
+	// Baselines do not reset the window
+	if stats := summarizer.GetStats(); stats.ProcessAggregations != 2 {
+		t.Errorf("ProcessAggregations = %d, want 2", stats.ProcessAggregations)
+	}
+}
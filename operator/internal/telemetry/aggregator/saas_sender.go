@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,9 +13,13 @@ import (
 
 	"github.com/go-logr/logr"
 
+	"github.com/policy-hub/operator/internal/saas"
 	"github.com/policy-hub/operator/internal/telemetry/models"
 )
 
+// aggregatesPath is the SaaS API path aggregates are submitted to.
+const aggregatesPath = "/api/operator/telemetry/aggregates"
+
 // SaaSSender sends aggregated telemetry to the SaaS platform.
 type SaaSSender struct {
 	endpoint    string
@@ -23,6 +28,9 @@ type SaaSSender struct {
 	httpClient  *http.Client
 	log         logr.Logger
 
+	// Client, when set, submits aggregates through its outbox
+	client *saas.Client
+
 	// Summarizer for aggregation
 	summarizer *Summarizer
 
@@ -59,6 +67,9 @@ type SaaSSenderConfig struct {
 	Timeout time.Duration
 	// NodeName for the summarizer
 	NodeName string
+	// Client, when set, submits aggregates instead of the sender. Aggregates
+	// that cannot be sent are kept in the client's outbox until they are.
+	Client *saas.Client
 	// Logger for logging
 	Logger logr.Logger
 }
@@ -92,6 +103,7 @@ func NewSaaSSender(cfg SaaSSenderConfig) *SaaSSender {
 		sendInterval:  sendInterval,
 		maxRetries:    maxRetries,
 		retryInterval: retryInterval,
+		client:        cfg.Client,
 		log:           cfg.Logger.WithName("saas-sender"),
 		httpClient: &http.Client{
 			Timeout: timeout,
@@ -147,6 +159,11 @@ func (s *SaaSSender) sendAggregates(ctx context.Context) {
 	// Set cluster ID
 	aggregates.ClusterID = s.clusterID
 
+	if s.client != nil {
+		s.submit(ctx, aggregates)
+		return
+	}
+
 	// Send with retries
 	var lastErr error
 	for attempt := 0; attempt <= s.maxRetries; attempt++ {
@@ -187,6 +204,35 @@ func (s *SaaSSender) sendAggregates(ctx context.Context) {
 	s.log.Error(lastErr, "Failed to send aggregates after all retries")
 }
 
+// submit sends aggregates through the client, which queues them in its
+// outbox when the SaaS platform cannot be reached.
+func (s *SaaSSender) submit(ctx context.Context, aggregates *models.AggregatedTelemetry) {
+	body, err := json.Marshal(aggregates)
+	if err == nil {
+		_, err = s.client.Submit(ctx, "POST", aggregatesPath, "aggregates", body)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case err == nil:
+		s.totalSent++
+		s.lastSendTime = time.Now()
+		s.lastSendSuccess = true
+		s.log.V(1).Info("Sent aggregates to SaaS",
+			"flowSummaries", len(aggregates.FlowSummaries),
+			"processSummaries", len(aggregates.ProcessSummaries),
+		)
+	case errors.Is(err, saas.ErrQueued):
+		s.lastSendSuccess = false
+		s.log.V(1).Info("Queued aggregates for retry", "reason", err.Error())
+	default:
+		s.totalFailed++
+		s.lastSendSuccess = false
+		s.log.Error(err, "Failed to send aggregates")
+	}
+}
+
 // doSend performs the actual HTTP request.
 func (s *SaaSSender) doSend(ctx context.Context, aggregates *models.AggregatedTelemetry) error {
 	// Marshal to JSON
@@ -8,6 +8,7 @@ import (
 
 	"github.com/go-logr/logr"
 
+	"github.com/policy-hub/operator/internal/telemetry/collector"
 	"github.com/policy-hub/operator/internal/telemetry/models"
 )
 
@@ -52,11 +53,22 @@ type processAggregation struct {
 	Namespace string
 	PodName   string
 
-	TotalExecs     int64
-	BinaryCounts   map[string]int64
-	SyscallCounts  map[string]int64
-	FileOpCounts   map[string]int64
-	ActionCounts   map[string]int64
+	PodLabels map[string]string
+
+	TotalExecs    int64
+	BinaryCounts  map[string]int64
+	SyscallCounts map[string]int64
+	FileOpCounts  map[string]int64
+	ActionCounts  map[string]int64
+
+	// SensitiveFileCounts counts accesses to sensitive paths by binary and path
+	SensitiveFileCounts map[fileAccessKey]int64
+}
+
+// fileAccessKey identifies a file accessed by a binary.
+type fileAccessKey struct {
+	Binary string
+	Path   string
 }
 
 // SummarizerConfig contains configuration for the summarizer.
@@ -132,7 +144,7 @@ func (s *Summarizer) addFlowEvent(event *models.TelemetryEvent) {
 	agg.TotalPackets += event.PacketsTotal
 
 	switch event.Verdict {
-	case models.VerdictAllowed:
+	case models.VerdictAllowed, models.VerdictAudit:
 		agg.AllowedFlows++
 	case models.VerdictDenied:
 		agg.DeniedFlows++
@@ -171,9 +183,14 @@ func (s *Summarizer) addProcessEvent(event *models.TelemetryEvent) {
 			SyscallCounts: make(map[string]int64),
 			FileOpCounts:  make(map[string]int64),
 			ActionCounts:  make(map[string]int64),
+
+			SensitiveFileCounts: make(map[fileAccessKey]int64),
 		}
 		s.procSummaries[key] = agg
 	}
+	if agg.PodLabels == nil && len(event.SrcPodLabels) > 0 {
+		agg.PodLabels = event.SrcPodLabels
+	}
 
 	switch event.EventType {
 	case models.EventTypeProcessExec:
@@ -189,6 +206,9 @@ func (s *Summarizer) addProcessEvent(event *models.TelemetryEvent) {
 		if event.FileOperation != "" {
 			agg.FileOpCounts[event.FileOperation]++
 		}
+		if event.FilePath != "" && collector.IsSensitivePath(event.FilePath) {
+			agg.SensitiveFileCounts[fileAccessKey{Binary: event.SrcBinary, Path: event.FilePath}]++
+		}
 	}
 
 	// Track enforcement actions
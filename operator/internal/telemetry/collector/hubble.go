@@ -355,7 +355,7 @@ func convertVerdict(v flowpb.Verdict) models.Verdict {
 	case flowpb.Verdict_ERROR:
 		return models.VerdictDenied
 	case flowpb.Verdict_AUDIT:
-		return models.VerdictAllowed
+		return models.VerdictAudit
 	case flowpb.Verdict_REDIRECTED:
 		return models.VerdictAllowed
 	case flowpb.Verdict_TRACED:
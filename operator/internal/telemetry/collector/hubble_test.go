@@ -300,7 +300,7 @@ func TestConvertVerdict(t *testing.T) {
 		{flowpb.Verdict_FORWARDED, models.VerdictAllowed},
 		{flowpb.Verdict_DROPPED, models.VerdictDropped},
 		{flowpb.Verdict_ERROR, models.VerdictDenied},
-		{flowpb.Verdict_AUDIT, models.VerdictAllowed},
+		{flowpb.Verdict_AUDIT, models.VerdictAudit},
 		{flowpb.Verdict_REDIRECTED, models.VerdictAllowed},
 		{flowpb.Verdict_TRACED, models.VerdictAllowed},
 		{flowpb.Verdict_TRANSLATED, models.VerdictAllowed},
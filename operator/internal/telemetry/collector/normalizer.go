@@ -98,7 +98,7 @@ func (n *EventNormalizer) EnrichProcessEvent(event *models.TelemetryEvent) {
 func (n *EventNormalizer) enrichProcessExec(event *models.TelemetryEvent) {
 	// Categorize binary type
 	if event.SrcBinary != "" {
-		event.Action = categorizeBinary(event.SrcBinary)
+		event.Action = CategorizeBinary(event.SrcBinary)
 	}
 }
 
@@ -116,7 +116,7 @@ func (n *EventNormalizer) enrichFileAccess(event *models.TelemetryEvent) {
 	// Categorize file access type
 	if event.FilePath != "" {
 		// Check for sensitive paths
-		if isSensitivePath(event.FilePath) {
+		if IsSensitivePath(event.FilePath) {
 			if event.Action == "" {
 				event.Action = "sensitive_access"
 			}
@@ -190,7 +190,9 @@ func inferL7Protocol(port uint32) string {
 	}
 }
 
-func categorizeBinary(binary string) string {
+// CategorizeBinary classifies a binary as shell, package_manager,
+// network_tool, compiler or other.
+func CategorizeBinary(binary string) string {
 	// Shell detection
 	shells := []string{"/bin/sh", "/bin/bash", "/bin/zsh", "/bin/dash", "/bin/ash"}
 	for _, shell := range shells {
@@ -226,19 +228,23 @@ func categorizeBinary(binary string) string {
 	return "other"
 }
 
-func isSensitivePath(path string) bool {
-	sensitivePaths := []string{
-		"/etc/passwd",
-		"/etc/shadow",
-		"/etc/sudoers",
-		"/root/",
-		"/.ssh/",
-		"/var/run/secrets/kubernetes.io/",
-		"/proc/",
-		"/sys/",
-	}
+// SensitivePaths are the files and directories whose access is flagged as
+// sensitive: credentials, service account tokens and kernel interfaces.
+var SensitivePaths = []string{
+	"/etc/passwd",
+	"/etc/shadow",
+	"/etc/sudoers",
+	"/root/",
+	"/.ssh/",
+	"/var/run/secrets/kubernetes.io/",
+	"/proc/",
+	"/sys/",
+}
 
-	for _, sp := range sensitivePaths {
+// IsSensitivePath reports whether a path starts with or contains one of the
+// SensitivePaths.
+func IsSensitivePath(path string) bool {
+	for _, sp := range SensitivePaths {
 		if strings.HasPrefix(path, sp) || strings.Contains(path, sp) {
 			return true
 		}
@@ -265,7 +271,7 @@ func (c *ProcessEventClassifier) ClassifyEvent(event *models.TelemetryEvent) []s
 	switch event.EventType {
 	case models.EventTypeProcessExec:
 		tags = append(tags, "process_exec")
-		if category := categorizeBinary(event.SrcBinary); category != "other" {
+		if category := CategorizeBinary(event.SrcBinary); category != "other" {
 			tags = append(tags, category)
 		}
 
@@ -278,7 +284,7 @@ func (c *ProcessEventClassifier) ClassifyEvent(event *models.TelemetryEvent) []s
 		if event.FileOperation != "" {
 			tags = append(tags, "file_"+strings.ToLower(event.FileOperation))
 		}
-		if isSensitivePath(event.FilePath) {
+		if IsSensitivePath(event.FilePath) {
 			tags = append(tags, "sensitive_file")
 		}
 	}
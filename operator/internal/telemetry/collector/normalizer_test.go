@@ -331,9 +331,9 @@ func TestCategorizeBinary(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		result := categorizeBinary(tt.binary)
+		result := CategorizeBinary(tt.binary)
 		if result != tt.expected {
-			t.Errorf("categorizeBinary(%s) = %s, want %s", tt.binary, result, tt.expected)
+			t.Errorf("CategorizeBinary(%s) = %s, want %s", tt.binary, result, tt.expected)
 		}
 	}
 }
@@ -357,9 +357,9 @@ func TestIsSensitivePath(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		result := isSensitivePath(tt.path)
+		result := IsSensitivePath(tt.path)
 		if result != tt.expected {
-			t.Errorf("isSensitivePath(%s) = %v, want %v", tt.path, result, tt.expected)
+			t.Errorf("IsSensitivePath(%s) = %v, want %v", tt.path, result, tt.expected)
 		}
 	}
 }
@@ -619,9 +619,9 @@ func TestCategorizeBinary_MoreTools(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		result := categorizeBinary(tt.binary)
+		result := CategorizeBinary(tt.binary)
 		if result != tt.expected {
-			t.Errorf("categorizeBinary(%s) = %s, want %s", tt.binary, result, tt.expected)
+			t.Errorf("CategorizeBinary(%s) = %s, want %s", tt.binary, result, tt.expected)
 		}
 	}
 }
@@ -642,9 +642,9 @@ func TestIsSensitivePath_MorePaths(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		result := isSensitivePath(tt.path)
+		result := IsSensitivePath(tt.path)
 		if result != tt.expected {
-			t.Errorf("isSensitivePath(%s) = %v, want %v", tt.path, result, tt.expected)
+			t.Errorf("IsSensitivePath(%s) = %v, want %v", tt.path, result, tt.expected)
 		}
 	}
 }
@@ -29,6 +29,9 @@ const (
 	VerdictDenied Verdict = "DENIED"
 	// VerdictDropped indicates the flow/action was dropped
 	VerdictDropped Verdict = "DROPPED"
+	// VerdictAudit indicates the flow was forwarded but would have been
+	// dropped by a policy in audit mode
+	VerdictAudit Verdict = "AUDIT"
 	// VerdictUnknown indicates the verdict could not be determined
 	VerdictUnknown Verdict = "UNKNOWN"
 )
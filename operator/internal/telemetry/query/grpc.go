@@ -31,6 +31,14 @@ var TelemetryQuery_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SimulatePolicy",
 			Handler:    _TelemetryQuery_SimulatePolicy_Handler,
 		},
+		{
+			MethodName: "RecommendPolicy",
+			Handler:    _TelemetryQuery_RecommendPolicy_Handler,
+		},
+		{
+			MethodName: "RecommendTracingPolicy",
+			Handler:    _TelemetryQuery_RecommendTracingPolicy_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -96,6 +104,42 @@ func _TelemetryQuery_SimulatePolicy_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TelemetryQuery_RecommendPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecommendPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TelemetryQueryServer).RecommendPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + TelemetryQueryServiceName + "/RecommendPolicy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TelemetryQueryServer).RecommendPolicy(ctx, req.(*RecommendPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TelemetryQuery_RecommendTracingPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecommendTracingPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TelemetryQueryServer).RecommendTracingPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + TelemetryQueryServiceName + "/RecommendTracingPolicy",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TelemetryQueryServer).RecommendTracingPolicy(ctx, req.(*RecommendTracingPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TelemetryQuery_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(QueryEventsRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -176,3 +220,21 @@ func (c *telemetryQueryClient) SimulatePolicy(ctx context.Context, in *SimulateP
 	}
 	return out, nil
 }
+
+func (c *telemetryQueryClient) RecommendPolicy(ctx context.Context, in *RecommendPolicyRequest, opts ...grpc.CallOption) (*RecommendPolicyResponse, error) {
+	out := new(RecommendPolicyResponse)
+	err := c.cc.Invoke(ctx, "/"+TelemetryQueryServiceName+"/RecommendPolicy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *telemetryQueryClient) RecommendTracingPolicy(ctx context.Context, in *RecommendTracingPolicyRequest, opts ...grpc.CallOption) (*RecommendTracingPolicyResponse, error) {
+	out := new(RecommendTracingPolicyResponse)
+	err := c.cc.Invoke(ctx, "/"+TelemetryQueryServiceName+"/RecommendTracingPolicy", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
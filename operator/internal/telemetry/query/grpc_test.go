@@ -35,16 +35,18 @@ func TestTelemetryQuery_ServiceDesc(t *testing.T) {
 func TestTelemetryQuery_ServiceDesc_Methods(t *testing.T) {
 	desc := TelemetryQuery_ServiceDesc
 
-	// Should have 3 methods
-	if len(desc.Methods) != 3 {
-		t.Errorf("Methods count = %d, want 3", len(desc.Methods))
+	// Should have 5 methods
+	if len(desc.Methods) != 5 {
+		t.Errorf("Methods count = %d, want 5", len(desc.Methods))
 	}
 
 	// Check expected methods exist
 	expectedMethods := map[string]bool{
-		"QueryEvents":    false,
-		"GetEventCount":  false,
-		"SimulatePolicy": false,
+		"QueryEvents":            false,
+		"GetEventCount":          false,
+		"SimulatePolicy":         false,
+		"RecommendPolicy":        false,
+		"RecommendTracingPolicy": false,
 	}
 
 	for _, method := range desc.Methods {
@@ -126,7 +128,7 @@ func TestRegisterTelemetryQueryServer_ServiceInfo(t *testing.T) {
 		methodNames[method.Name] = true
 	}
 
-	expectedMethods := []string{"QueryEvents", "GetEventCount", "SimulatePolicy", "StreamEvents"}
+	expectedMethods := []string{"QueryEvents", "GetEventCount", "SimulatePolicy", "RecommendPolicy", "RecommendTracingPolicy", "StreamEvents"}
 	for _, expected := range expectedMethods {
 		if !methodNames[expected] {
 			t.Errorf("Method %s not found in service info", expected)
@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/policy-hub/operator/internal/telemetry/models"
+	"github.com/policy-hub/operator/internal/telemetry/recommendation"
 	"github.com/policy-hub/operator/internal/telemetry/simulation"
 	"github.com/policy-hub/operator/internal/telemetry/storage"
 )
@@ -25,10 +27,11 @@ import (
 type Server struct {
 	UnimplementedTelemetryQueryServer
 
-	storageMgr *storage.Manager
-	simEngine  *simulation.Engine
-	log        logr.Logger
-	apiKey     string
+	storageMgr  *storage.Manager
+	simEngine   *simulation.Engine
+	recommender *recommendation.Recommender
+	log         logr.Logger
+	apiKey      string
 
 	// Server state
 	mu         sync.RWMutex
@@ -37,11 +40,12 @@ type Server struct {
 	started    bool
 
 	// Metrics
-	totalQueries      int64
-	totalEvents       int64
-	queryErrors       int64
-	totalSimulations  int64
-	lastQueryTime     time.Time
+	totalQueries         int64
+	totalEvents          int64
+	queryErrors          int64
+	totalSimulations     int64
+	totalRecommendations int64
+	lastQueryTime        time.Time
 }
 
 // ServerConfig contains configuration for the query server.
@@ -57,10 +61,16 @@ type ServerConfig struct {
 // NewServer creates a new query server.
 func NewServer(cfg ServerConfig) *Server {
 	log := cfg.Logger.WithName("query-server")
+	simEngine := simulation.NewEngine(simulation.EngineConfig{
+		StorageManager: cfg.StorageManager,
+		Logger:         cfg.Logger,
+	})
 	return &Server{
 		storageMgr: cfg.StorageManager,
-		simEngine: simulation.NewEngine(simulation.EngineConfig{
+		simEngine:  simEngine,
+		recommender: recommendation.NewRecommender(recommendation.RecommenderConfig{
 			StorageManager: cfg.StorageManager,
+			Engine:         simEngine,
 			Logger:         cfg.Logger,
 		}),
 		apiKey: cfg.APIKey,
@@ -384,6 +394,8 @@ func (s *Server) SimulatePolicy(ctx context.Context, req *SimulatePolicyRequest)
 		BreakdownByNamespace: convertNamespaceBreakdown(result.BreakdownByNamespace),
 		BreakdownByVerdict:   convertVerdictBreakdown(result.BreakdownByVerdict),
 		Details:              convertFlowDetails(result.Details),
+		Connections:          convertConnections(result.Connections),
+		TotalConnections:     result.TotalConnections,
 		Errors:               result.Errors,
 		SimulationTime:       result.SimulationTime,
 		Duration:             result.Duration,
@@ -398,6 +410,132 @@ func (s *Server) SimulatePolicy(ctx context.Context, req *SimulatePolicyRequest)
 	return response, nil
 }
 
+// RecommendPolicy generates a least-privilege policy from historical data.
+func (s *Server) RecommendPolicy(ctx context.Context, req *RecommendPolicyRequest) (*RecommendPolicyResponse, error) {
+	s.mu.Lock()
+	s.totalRecommendations++
+	s.lastQueryTime = time.Now()
+	s.mu.Unlock()
+
+	if req.Namespace == "" {
+		return nil, status.Error(codes.InvalidArgument, "namespace is required")
+	}
+
+	result, err := s.recommender.Recommend(ctx, &recommendation.RecommendationRequest{
+		Namespace:   req.Namespace,
+		PodSelector: req.PodSelector,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+		PolicyName:  req.PolicyName,
+	})
+	if err != nil {
+		s.log.Error(err, "Policy recommendation failed", "namespace", req.Namespace)
+		return nil, status.Errorf(codes.FailedPrecondition, "recommendation failed: %v", err)
+	}
+
+	response := &RecommendPolicyResponse{
+		PolicyContent: result.PolicyContent,
+		PolicyType:    result.PolicyType,
+		PolicyName:    result.PolicyName,
+		Namespace:     result.Namespace,
+		FlowsAnalyzed: result.FlowsAnalyzed,
+		Warnings:      result.Warnings,
+		GeneratedAt:   result.GeneratedAt,
+		Duration:      result.Duration,
+	}
+	for _, rule := range result.Rules {
+		response.Rules = append(response.Rules, &RecommendedRule{
+			Direction: rule.Direction,
+			Peers:     rule.Peers,
+			Ports:     rule.Ports,
+			FlowCount: rule.FlowCount,
+		})
+	}
+	if result.SelfCheck != nil {
+		response.SelfCheck = &SelfCheckResult{
+			TotalFlowsAnalyzed: result.SelfCheck.TotalFlowsAnalyzed,
+			WouldDeny:          result.SelfCheck.WouldDeny,
+			Passed:             result.SelfCheck.Passed,
+			Errors:             result.SelfCheck.Errors,
+		}
+	}
+
+	return response, nil
+}
+
+// RecommendTracingPolicy generates allowlist TracingPolicies from the process
+// baselines of a namespace's workloads.
+func (s *Server) RecommendTracingPolicy(ctx context.Context, req *RecommendTracingPolicyRequest) (*RecommendTracingPolicyResponse, error) {
+	s.mu.Lock()
+	s.totalRecommendations++
+	s.lastQueryTime = time.Now()
+	s.mu.Unlock()
+
+	if req.Namespace == "" {
+		return nil, status.Error(codes.InvalidArgument, "namespace is required")
+	}
+	switch strings.ToLower(req.Mode) {
+	case "", recommendation.TracingModeAudit, recommendation.TracingModeEnforce:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported mode %q", req.Mode)
+	}
+
+	result, err := s.recommender.RecommendTracingPolicies(ctx, &recommendation.TracingPolicyRequest{
+		Namespace:   req.Namespace,
+		PodSelector: req.PodSelector,
+		StartTime:   req.StartTime,
+		EndTime:     req.EndTime,
+		Mode:        req.Mode,
+	})
+	if err != nil {
+		s.log.Error(err, "Tracing policy recommendation failed", "namespace", req.Namespace)
+		return nil, status.Errorf(codes.FailedPrecondition, "recommendation failed: %v", err)
+	}
+
+	response := &RecommendTracingPolicyResponse{
+		EventsAnalyzed: result.EventsAnalyzed,
+		Warnings:       result.Warnings,
+		GeneratedAt:    result.GeneratedAt,
+		Duration:       result.Duration,
+	}
+	for _, policy := range result.Policies {
+		out := &RecommendedTracingPolicy{
+			PolicyContent: policy.PolicyContent,
+			PolicyType:    policy.PolicyType,
+			PolicyName:    policy.PolicyName,
+			Namespace:     policy.Namespace,
+			PodSelector:   policy.PodSelector,
+			Mode:          policy.Mode,
+			Warnings:      policy.Warnings,
+		}
+		if c := policy.Coverage; c != nil {
+			out.Coverage = &BaselineCoverage{
+				Pods:       c.Pods,
+				FirstSeen:  c.FirstSeen,
+				LastSeen:   c.LastSeen,
+				ExecEvents: c.ExecEvents,
+			}
+			for _, b := range c.Binaries {
+				out.Coverage.Binaries = append(out.Coverage.Binaries, BinaryCoverage(b))
+			}
+			for _, f := range c.SensitiveFiles {
+				out.Coverage.SensitiveFiles = append(out.Coverage.SensitiveFiles, SensitiveFileCoverage(f))
+			}
+		}
+		if policy.SelfCheck != nil {
+			out.SelfCheck = &SelfCheckResult{
+				TotalFlowsAnalyzed: policy.SelfCheck.TotalFlowsAnalyzed,
+				WouldDeny:          policy.SelfCheck.WouldDeny,
+				Passed:             policy.SelfCheck.Passed,
+				Errors:             policy.SelfCheck.Errors,
+			}
+		}
+		response.Policies = append(response.Policies, out)
+	}
+
+	return response, nil
+}
+
 // Helper functions for converting simulation types to query types
 
 func convertNamespaceBreakdown(input map[string]*simulation.NamespaceImpact) map[string]*NamespaceImpact {
@@ -460,29 +598,59 @@ func convertFlowDetails(input []*simulation.FlowSimulationResult) []*FlowSimulat
 	return output
 }
 
+// convertConnections converts simulation connections to query connections.
+func convertConnections(input []*simulation.ConnectionImpact) []*ConnectionImpact {
+	if input == nil {
+		return nil
+	}
+	output := make([]*ConnectionImpact, len(input))
+	for i, v := range input {
+		output[i] = &ConnectionImpact{
+			SrcNamespace:     v.SrcNamespace,
+			SrcWorkload:      v.SrcWorkload,
+			DstNamespace:     v.DstNamespace,
+			DstWorkload:      v.DstWorkload,
+			DstPort:          v.DstPort,
+			Protocol:         v.Protocol,
+			L7:               v.L7,
+			OriginalVerdict:  v.OriginalVerdict,
+			SimulatedVerdict: v.SimulatedVerdict,
+			MatchedRule:      v.MatchedRule,
+			MatchReason:      v.MatchReason,
+			Flows:            v.Flows,
+			FirstSeen:        v.FirstSeen,
+			LastSeen:         v.LastSeen,
+			Samples:          convertFlowDetails(v.Samples),
+		}
+	}
+	return output
+}
+
 // GetStats returns server statistics.
 func (s *Server) GetStats() ServerStats {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	return ServerStats{
-		TotalQueries:     s.totalQueries,
-		TotalEvents:      s.totalEvents,
-		QueryErrors:      s.queryErrors,
-		TotalSimulations: s.totalSimulations,
-		LastQueryTime:    s.lastQueryTime,
-		Started:          s.started,
+		TotalQueries:         s.totalQueries,
+		TotalEvents:          s.totalEvents,
+		QueryErrors:          s.queryErrors,
+		TotalSimulations:     s.totalSimulations,
+		LastQueryTime:        s.lastQueryTime,
+		Started:              s.started,
+		TotalRecommendations: s.totalRecommendations,
 	}
 }
 
 // ServerStats contains server statistics.
 type ServerStats struct {
-	TotalQueries     int64
-	TotalSimulations int64
-	TotalEvents   int64
-	QueryErrors   int64
-	LastQueryTime time.Time
-	Started       bool
+	TotalQueries         int64
+	TotalSimulations     int64
+	TotalRecommendations int64
+	TotalEvents          int64
+	QueryErrors          int64
+	LastQueryTime        time.Time
+	Started              bool
 }
 
 // modelEventToProto converts a model event to the proto format.
@@ -9,7 +9,9 @@ import (
 
 	"github.com/go-logr/logr"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/policy-hub/operator/internal/telemetry/models"
 	"github.com/policy-hub/operator/internal/telemetry/simulation"
@@ -751,6 +753,147 @@ func TestServer_SimulatePolicy_InvalidPolicy(t *testing.T) {
 	}
 }
 
+func TestServer_RecommendPolicy(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "query-rec-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cfg := storage.ManagerConfig{
+		BasePath: tmpDir,
+		NodeName: "test-node",
+		Logger:   logr.Discard(),
+	}
+	writer, err := storage.NewManager(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+	now := time.Now().UTC()
+	err = writer.Write([]*models.TelemetryEvent{{
+		ID: "1", EventType: models.EventTypeFlow, Timestamp: now.Add(-10 * time.Minute), NodeName: "test-node",
+		SrcNamespace: "default", SrcPodLabels: map[string]string{"app": "frontend"},
+		DstNamespace: "default", DstPodLabels: map[string]string{"app": "backend"},
+		DstPort: 8080, Protocol: "TCP", Verdict: models.VerdictAllowed,
+	}})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	mgr, err := storage.NewManager(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+	defer mgr.Close()
+
+	server := NewServer(ServerConfig{
+		StorageManager: mgr,
+		Logger:         logr.Discard(),
+	})
+	ctx := context.Background()
+
+	if _, err := server.RecommendPolicy(ctx, &RecommendPolicyRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("RecommendPolicy() without namespace error = %v, want InvalidArgument", err)
+	}
+
+	resp, err := server.RecommendPolicy(ctx, &RecommendPolicyRequest{
+		Namespace:   "default",
+		PodSelector: map[string]string{"app": "backend"},
+		StartTime:   now.Add(-1 * time.Hour),
+		EndTime:     now,
+	})
+	if err != nil {
+		t.Fatalf("RecommendPolicy() error = %v", err)
+	}
+	if resp.PolicyName != "backend-least-privilege" || resp.PolicyContent == "" {
+		t.Errorf("RecommendPolicy() = %+v", resp)
+	}
+	if resp.SelfCheck == nil || !resp.SelfCheck.Passed {
+		t.Errorf("SelfCheck = %+v, want passed", resp.SelfCheck)
+	}
+	if len(resp.Rules) != 1 || resp.Rules[0].Direction != "ingress" || resp.Rules[0].Peers[0] != "default/app=frontend" {
+		t.Errorf("Rules = %+v", resp.Rules)
+	}
+
+	if stats := server.GetStats(); stats.TotalRecommendations != 2 {
+		t.Errorf("TotalRecommendations = %d, want 2", stats.TotalRecommendations)
+	}
+}
+
+func TestServer_RecommendTracingPolicy(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "query-rec-tp-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cfg := storage.ManagerConfig{
+		BasePath: tmpDir,
+		NodeName: "test-node",
+		Logger:   logr.Discard(),
+	}
+	writer, err := storage.NewManager(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+	now := time.Now().UTC()
+	err = writer.Write([]*models.TelemetryEvent{{
+		ID: "1", EventType: models.EventTypeProcessExec, Timestamp: now.Add(-10 * time.Minute), NodeName: "test-node",
+		SrcNamespace: "default", SrcPodName: "backend-1", SrcPodLabels: map[string]string{"app": "backend"},
+		SrcBinary: "/usr/local/bin/backend", Verdict: models.VerdictAllowed,
+	}})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	mgr, err := storage.NewManager(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+	defer mgr.Close()
+
+	server := NewServer(ServerConfig{
+		StorageManager: mgr,
+		Logger:         logr.Discard(),
+	})
+	ctx := context.Background()
+
+	if _, err := server.RecommendTracingPolicy(ctx, &RecommendTracingPolicyRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("RecommendTracingPolicy() without namespace error = %v, want InvalidArgument", err)
+	}
+	if _, err := server.RecommendTracingPolicy(ctx, &RecommendTracingPolicyRequest{Namespace: "default", Mode: "block"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("RecommendTracingPolicy() with unknown mode error = %v, want InvalidArgument", err)
+	}
+
+	resp, err := server.RecommendTracingPolicy(ctx, &RecommendTracingPolicyRequest{
+		Namespace: "default",
+		StartTime: now.Add(-1 * time.Hour),
+		EndTime:   now,
+	})
+	if err != nil {
+		t.Fatalf("RecommendTracingPolicy() error = %v", err)
+	}
+	if len(resp.Policies) != 1 {
+		t.Fatalf("len(Policies) = %d, want 1", len(resp.Policies))
+	}
+	policy := resp.Policies[0]
+	if policy.PolicyName != "backend-process-baseline" || policy.Mode != "audit" || policy.PolicyContent == "" {
+		t.Errorf("RecommendTracingPolicy() = %+v", policy)
+	}
+	if policy.Coverage == nil || len(policy.Coverage.Binaries) != 1 || policy.Coverage.Binaries[0].Binary != "/usr/local/bin/backend" {
+		t.Errorf("Coverage = %+v", policy.Coverage)
+	}
+	if policy.SelfCheck == nil || !policy.SelfCheck.Passed {
+		t.Errorf("SelfCheck = %+v, want passed", policy.SelfCheck)
+	}
+}
+
 func TestConvertNamespaceBreakdown_NonNil(t *testing.T) {
 	input := map[string]*simulation.NamespaceImpact{
 		"default": {
@@ -17,6 +17,10 @@ type TelemetryQueryServer interface {
 	GetEventCount(context.Context, *GetEventCountRequest) (*EventCountResponse, error)
 	// SimulatePolicy evaluates a policy against historical data.
 	SimulatePolicy(context.Context, *SimulatePolicyRequest) (*SimulatePolicyResponse, error)
+	// RecommendPolicy generates a least-privilege policy from historical data.
+	RecommendPolicy(context.Context, *RecommendPolicyRequest) (*RecommendPolicyResponse, error)
+	// RecommendTracingPolicy generates allowlist TracingPolicies from process baselines.
+	RecommendTracingPolicy(context.Context, *RecommendTracingPolicyRequest) (*RecommendTracingPolicyResponse, error)
 	mustEmbedUnimplementedTelemetryQueryServer()
 }
 
@@ -30,6 +34,10 @@ type TelemetryQueryClient interface {
 	GetEventCount(ctx context.Context, in *GetEventCountRequest, opts ...grpc.CallOption) (*EventCountResponse, error)
 	// SimulatePolicy evaluates a policy against historical data.
 	SimulatePolicy(ctx context.Context, in *SimulatePolicyRequest, opts ...grpc.CallOption) (*SimulatePolicyResponse, error)
+	// RecommendPolicy generates a least-privilege policy from historical data.
+	RecommendPolicy(ctx context.Context, in *RecommendPolicyRequest, opts ...grpc.CallOption) (*RecommendPolicyResponse, error)
+	// RecommendTracingPolicy generates allowlist TracingPolicies from process baselines.
+	RecommendTracingPolicy(ctx context.Context, in *RecommendTracingPolicyRequest, opts ...grpc.CallOption) (*RecommendTracingPolicyResponse, error)
 }
 
 // UnimplementedTelemetryQueryServer must be embedded to have forward compatible implementations.
@@ -51,6 +59,14 @@ func (UnimplementedTelemetryQueryServer) SimulatePolicy(context.Context, *Simula
 	return nil, nil
 }
 
+func (UnimplementedTelemetryQueryServer) RecommendPolicy(context.Context, *RecommendPolicyRequest) (*RecommendPolicyResponse, error) {
+	return nil, nil
+}
+
+func (UnimplementedTelemetryQueryServer) RecommendTracingPolicy(context.Context, *RecommendTracingPolicyRequest) (*RecommendTracingPolicyResponse, error) {
+	return nil, nil
+}
+
 func (UnimplementedTelemetryQueryServer) mustEmbedUnimplementedTelemetryQueryServer() {}
 
 // TelemetryQuery_StreamEventsServer is the server stream for StreamEvents.
@@ -164,6 +180,11 @@ type SimulatePolicyResponse struct {
 	BreakdownByVerdict *VerdictBreakdown `json:"breakdownByVerdict,omitempty"`
 	// Details contains sample flows with their simulation results
 	Details []*FlowSimulationResult `json:"details,omitempty"`
+	// Connections aggregates the flows whose verdict would change by
+	// connection, most impacted first
+	Connections []*ConnectionImpact `json:"connections,omitempty"`
+	// TotalConnections is the number of distinct connections before the limit
+	TotalConnections int64 `json:"totalConnections,omitempty"`
 	// Errors encountered during simulation
 	Errors []string `json:"errors,omitempty"`
 
@@ -211,3 +232,142 @@ type FlowSimulationResult struct {
 	MatchedRule      string    `json:"matchedRule,omitempty"`
 	MatchReason      string    `json:"matchReason,omitempty"`
 }
+
+// ConnectionImpact aggregates the changed flows of one connection.
+type ConnectionImpact struct {
+	SrcNamespace     string                  `json:"srcNamespace"`
+	SrcWorkload      string                  `json:"srcWorkload"`
+	DstNamespace     string                  `json:"dstNamespace"`
+	DstWorkload      string                  `json:"dstWorkload"`
+	DstPort          uint32                  `json:"dstPort"`
+	Protocol         string                  `json:"protocol"`
+	L7               string                  `json:"l7,omitempty"`
+	OriginalVerdict  string                  `json:"originalVerdict"`
+	SimulatedVerdict string                  `json:"simulatedVerdict"`
+	MatchedRule      string                  `json:"matchedRule,omitempty"`
+	MatchReason      string                  `json:"matchReason,omitempty"`
+	Flows            int64                   `json:"flows"`
+	FirstSeen        time.Time               `json:"firstSeen"`
+	LastSeen         time.Time               `json:"lastSeen"`
+	Samples          []*FlowSimulationResult `json:"samples,omitempty"`
+}
+
+// RecommendPolicyRequest is the request for generating a least-privilege policy.
+type RecommendPolicyRequest struct {
+	// Namespace of the workload to generate a policy for
+	Namespace string `json:"namespace"`
+	// PodSelector selects the workload's pods (empty = all pods in the namespace)
+	PodSelector map[string]string `json:"podSelector,omitempty"`
+	// TimeRange specifies the training window
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	// PolicyName is the name of the generated policy (optional)
+	PolicyName string `json:"policyName,omitempty"`
+}
+
+// RecommendPolicyResponse contains a generated policy.
+type RecommendPolicyResponse struct {
+	// PolicyContent is the raw YAML content of the generated policy
+	PolicyContent string `json:"policyContent"`
+	// PolicyType is the type of the generated policy (CILIUM_NETWORK)
+	PolicyType string `json:"policyType"`
+	PolicyName string `json:"policyName"`
+	Namespace  string `json:"namespace"`
+	// FlowsAnalyzed is the number of workload flows the policy was generated from
+	FlowsAnalyzed int64 `json:"flowsAnalyzed"`
+	// Rules summarizes the generated rules
+	Rules []*RecommendedRule `json:"rules,omitempty"`
+	// SelfCheck is the simulation of the policy against the training window
+	SelfCheck *SelfCheckResult `json:"selfCheck,omitempty"`
+	// Warnings about traffic the policy could not capture precisely
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Metadata
+	GeneratedAt time.Time     `json:"generatedAt"`
+	Duration    time.Duration `json:"duration"`
+}
+
+// RecommendedRule summarizes a generated ingress or egress rule.
+type RecommendedRule struct {
+	Direction string   `json:"direction"`
+	Peers     []string `json:"peers"`
+	Ports     []string `json:"ports,omitempty"`
+	FlowCount int64    `json:"flowCount"`
+}
+
+// RecommendTracingPolicyRequest is the request for generating allowlist
+// TracingPolicies from process baselines.
+type RecommendTracingPolicyRequest struct {
+	// Namespace of the workloads to generate policies for
+	Namespace string `json:"namespace"`
+	// PodSelector restricts the pods considered (empty = all pods in the namespace)
+	PodSelector map[string]string `json:"podSelector,omitempty"`
+	// TimeRange specifies the baseline window
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	// Mode is audit (default) or enforce
+	Mode string `json:"mode,omitempty"`
+}
+
+// RecommendTracingPolicyResponse contains one generated TracingPolicy per workload.
+type RecommendTracingPolicyResponse struct {
+	Policies []*RecommendedTracingPolicy `json:"policies"`
+	// EventsAnalyzed is the number of process events the baselines were built from
+	EventsAnalyzed int64 `json:"eventsAnalyzed"`
+	// Warnings about pods that could not be covered
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Metadata
+	GeneratedAt time.Time     `json:"generatedAt"`
+	Duration    time.Duration `json:"duration"`
+}
+
+// RecommendedTracingPolicy is a generated TracingPolicyNamespaced for a workload.
+type RecommendedTracingPolicy struct {
+	// PolicyContent is the raw YAML content of the generated policy
+	PolicyContent string            `json:"policyContent"`
+	PolicyType    string            `json:"policyType"`
+	PolicyName    string            `json:"policyName"`
+	Namespace     string            `json:"namespace"`
+	PodSelector   map[string]string `json:"podSelector"`
+	Mode          string            `json:"mode"`
+	// Coverage reports what the workload's baseline covered
+	Coverage *BaselineCoverage `json:"coverage"`
+	// SelfCheck is the simulation of the enforcing policy against the baseline window
+	SelfCheck *SelfCheckResult `json:"selfCheck,omitempty"`
+	Warnings  []string         `json:"warnings,omitempty"`
+}
+
+// BaselineCoverage reports what a process baseline covered.
+type BaselineCoverage struct {
+	Pods           []string                `json:"pods"`
+	FirstSeen      time.Time               `json:"firstSeen"`
+	LastSeen       time.Time               `json:"lastSeen"`
+	ExecEvents     int64                   `json:"execEvents"`
+	Binaries       []BinaryCoverage        `json:"binaries"`
+	SensitiveFiles []SensitiveFileCoverage `json:"sensitiveFiles,omitempty"`
+}
+
+// BinaryCoverage is a permitted binary of a baseline.
+type BinaryCoverage struct {
+	Binary   string `json:"binary"`
+	Category string `json:"category"`
+	Count    int64  `json:"count"`
+}
+
+// SensitiveFileCoverage is a sensitive file access of a baseline.
+type SensitiveFileCoverage struct {
+	Binary  string `json:"binary"`
+	Path    string `json:"path"`
+	Count   int64  `json:"count"`
+	Watched bool   `json:"watched"`
+}
+
+// SelfCheckResult is the outcome of simulating a generated policy against
+// its training window.
+type SelfCheckResult struct {
+	TotalFlowsAnalyzed int64    `json:"totalFlowsAnalyzed"`
+	WouldDeny          int64    `json:"wouldDeny"`
+	Passed             bool     `json:"passed"`
+	Errors             []string `json:"errors,omitempty"`
+}
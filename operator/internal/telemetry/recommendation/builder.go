@@ -0,0 +1,572 @@
+package recommendation
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+)
+
+// Peer kinds, in the order their rules are emitted.
+const (
+	peerKindEndpoints = "endpoints"
+	peerKindDNS       = "dns"
+	peerKindEntities  = "entities"
+	peerKindFQDN      = "fqdn"
+	peerKindCIDR      = "cidr"
+)
+
+var peerKindOrder = map[string]int{
+	peerKindEndpoints: 0,
+	peerKindDNS:       1,
+	peerKindEntities:  2,
+	peerKindFQDN:      3,
+	peerKindCIDR:      4,
+}
+
+// maxCIDRPeers is the number of external peers sharing the same ports above
+// which they are collapsed into the world entity.
+const maxCIDRPeers = 16
+
+// namespaceLabel is the label Cilium attaches to endpoints with their namespace.
+const namespaceLabel = "k8s:io.kubernetes.pod.namespace"
+
+// identityLabels are the labels that identify a workload across pod restarts,
+// in order of preference. A peer carrying one of them is selected by it alone.
+var identityLabels = []string{"app.kubernetes.io/name", "app", "k8s-app", "name"}
+
+// volatileLabels change with every rollout or pod and never go into selectors.
+var volatileLabels = map[string]bool{
+	"pod-template-hash":                        true,
+	"controller-revision-hash":                 true,
+	"pod-template-generation":                  true,
+	"statefulset.kubernetes.io/pod-name":       true,
+	"apps.kubernetes.io/pod-index":             true,
+	"job-name":                                 true,
+	"controller-uid":                           true,
+	"batch.kubernetes.io/job-name":             true,
+	"batch.kubernetes.io/controller-uid":       true,
+	"batch.kubernetes.io/job-completion-index": true,
+}
+
+// Reserved Cilium security identities that map to entities.
+var reservedEntities = map[uint32]string{
+	1: "host",
+	3: "unmanaged",
+	4: "health",
+	5: "init",
+	6: "remote-node",
+	7: "kube-apiserver",
+	8: "ingress",
+}
+
+// peer is a remote endpoint of the workload's traffic, generalized to what a
+// policy rule can select.
+type peer struct {
+	kind      string
+	namespace string            // endpoints
+	labels    map[string]string // endpoints
+	value     string            // entity name, CIDR or DNS name
+}
+
+// key identifies a peer.
+func (p peer) key() string {
+	if p.kind != peerKindEndpoints && p.kind != peerKindDNS {
+		return p.kind + ":" + p.value
+	}
+	keys := make([]string, 0, len(p.labels))
+	for k := range p.labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+p.labels[k])
+	}
+	return p.kind + ":" + p.namespace + "/" + strings.Join(parts, ",")
+}
+
+// portKey is a protocol and port seen in the workload's traffic.
+type portKey struct {
+	protocol string
+	port     uint32
+}
+
+// peerTraffic collects the ports used with one peer.
+type peerTraffic struct {
+	peer    peer
+	ports   map[portKey]int64
+	anyPort int64 // flows without ports (e.g. ICMP)
+}
+
+// flows returns the number of flows recorded for the peer.
+func (t *peerTraffic) flows() int64 {
+	total := t.anyPort
+	for _, n := range t.ports {
+		total += n
+	}
+	return total
+}
+
+// policyBuilder accumulates a workload's flows and turns them into a policy.
+type policyBuilder struct {
+	namespace string
+	traffic   map[string]map[string]*peerTraffic // direction -> peer key -> traffic
+}
+
+// newPolicyBuilder creates a builder for a workload in a namespace.
+func newPolicyBuilder(namespace string) *policyBuilder {
+	return &policyBuilder{
+		namespace: namespace,
+		traffic: map[string]map[string]*peerTraffic{
+			"ingress": {},
+			"egress":  {},
+		},
+	}
+}
+
+// addFlow records a flow of the workload in a direction: ingress when the
+// workload is the destination, egress when it is the source.
+func (b *policyBuilder) addFlow(direction string, event *models.TelemetryEvent) {
+	p := b.peerForFlow(direction, event)
+	key := p.key()
+	t, ok := b.traffic[direction][key]
+	if !ok {
+		t = &peerTraffic{peer: p, ports: make(map[portKey]int64)}
+		b.traffic[direction][key] = t
+	}
+
+	protocol := strings.ToUpper(event.Protocol)
+	switch {
+	case event.DstPort != 0 && (protocol == "TCP" || protocol == "UDP" || protocol == "SCTP"):
+		t.ports[portKey{protocol: protocol, port: event.DstPort}]++
+	default:
+		t.anyPort++
+	}
+}
+
+// peerForFlow generalizes the remote side of a flow.
+func (b *policyBuilder) peerForFlow(direction string, event *models.TelemetryEvent) peer {
+	namespace, labels, ip, identity := event.SrcNamespace, event.SrcPodLabels, event.SrcIP, event.SrcIdentity
+	if direction == "egress" {
+		namespace, labels, ip, identity = event.DstNamespace, event.DstPodLabels, event.DstIP, event.DstIdentity
+	}
+
+	if entity := reservedEntity(identity, labels); entity != "" {
+		return peer{kind: peerKindEntities, value: entity}
+	}
+	if isWorld(namespace, labels, identity) {
+		if direction == "egress" && event.DstDNSName != "" {
+			name, _, _ := strings.Cut(event.DstDNSName, ",")
+			return peer{kind: peerKindFQDN, value: strings.TrimSuffix(strings.ToLower(name), ".")}
+		}
+		if parsed := net.ParseIP(ip); parsed != nil {
+			if parsed.To4() != nil {
+				return peer{kind: peerKindCIDR, value: ip + "/32"}
+			}
+			return peer{kind: peerKindCIDR, value: ip + "/128"}
+		}
+		return peer{kind: peerKindEntities, value: "world"}
+	}
+	if namespace == "" {
+		return peer{kind: peerKindEntities, value: "cluster"}
+	}
+	return peer{kind: peerKindEndpoints, namespace: namespace, labels: stableLabels(labels)}
+}
+
+// reservedEntity returns the Cilium entity of a reserved peer, if any.
+func reservedEntity(identity uint32, labels map[string]string) string {
+	if entity, ok := reservedEntities[identity]; ok {
+		return entity
+	}
+	if identity != 0 {
+		return ""
+	}
+	for _, entity := range []string{"kube-apiserver", "host", "remote-node", "health", "init", "ingress", "unmanaged"} {
+		if _, ok := labels[entity]; ok {
+			return entity
+		}
+	}
+	return ""
+}
+
+// isWorld reports whether a peer lies outside the cluster.
+func isWorld(namespace string, labels map[string]string, identity uint32) bool {
+	switch {
+	case identity == 2 || identity == 9 || identity == 10:
+		return true
+	case identity >= 1<<24:
+		// Node-local identities are allocated for CIDR-derived peers
+		return true
+	case identity != 0:
+		return false
+	}
+	for _, key := range []string{"world", "world-ipv4", "world-ipv6"} {
+		if _, ok := labels[key]; ok {
+			return true
+		}
+	}
+	return namespace == "" && len(labels) == 0
+}
+
+// stableLabels returns the labels that select a peer workload: its identity
+// label if it has one, otherwise all labels except the volatile ones.
+func stableLabels(labels map[string]string) map[string]string {
+	normalized := make(map[string]string, len(labels))
+	for k, v := range labels {
+		normalized[strings.TrimPrefix(k, "k8s:")] = v
+	}
+	for _, key := range identityLabels {
+		if value, ok := normalized[key]; ok {
+			return map[string]string{key: value}
+		}
+	}
+	stable := make(map[string]string)
+	for k, v := range normalized {
+		if volatileLabels[k] || strings.HasPrefix(k, "io.cilium.") || strings.HasPrefix(k, "io.kubernetes.") {
+			continue
+		}
+		stable[k] = v
+	}
+	return stable
+}
+
+// ruleGroup is a set of peers of the same kind sharing the same ports.
+type ruleGroup struct {
+	kind    string
+	ports   []portRange
+	anyPort bool
+	peers   []peer
+	flows   int64
+}
+
+// portRange is a contiguous range of ports of one protocol.
+type portRange struct {
+	protocol string
+	start    uint32
+	end      uint32
+}
+
+// String formats a port range as "port/protocol" or "start-end/protocol".
+func (r portRange) String() string {
+	if r.end > r.start {
+		return fmt.Sprintf("%d-%d/%s", r.start, r.end, r.protocol)
+	}
+	return fmt.Sprintf("%d/%s", r.start, r.protocol)
+}
+
+// collapsePorts merges contiguous ports of the same protocol into ranges.
+func collapsePorts(ports map[portKey]int64) []portRange {
+	keys := make([]portKey, 0, len(ports))
+	for k := range ports {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].protocol != keys[j].protocol {
+			return keys[i].protocol < keys[j].protocol
+		}
+		return keys[i].port < keys[j].port
+	})
+
+	var ranges []portRange
+	for _, k := range keys {
+		if n := len(ranges); n > 0 && ranges[n-1].protocol == k.protocol && ranges[n-1].end+1 == k.port {
+			ranges[n-1].end = k.port
+			continue
+		}
+		ranges = append(ranges, portRange{protocol: k.protocol, start: k.port, end: k.port})
+	}
+	return ranges
+}
+
+// groupRules groups the peers of a direction into rules. Peers of the same
+// kind that use exactly the same ports share a rule.
+func (b *policyBuilder) groupRules(direction string) ([]*ruleGroup, []string) {
+	var warnings []string
+	traffic := b.traffic[direction]
+
+	// FQDN rules only work when DNS lookups go through the DNS proxy, so DNS
+	// traffic to cluster endpoints gets its own rule with a DNS L7 rule
+	hasFQDN := false
+	for _, t := range traffic {
+		if t.peer.kind == peerKindFQDN {
+			hasFQDN = true
+			break
+		}
+	}
+	var entries []*peerTraffic
+	for _, t := range traffic {
+		if !hasFQDN || t.peer.kind != peerKindEndpoints {
+			entries = append(entries, t)
+			continue
+		}
+		dns := &peerTraffic{peer: t.peer, ports: make(map[portKey]int64)}
+		dns.peer.kind = peerKindDNS
+		rest := &peerTraffic{peer: t.peer, ports: make(map[portKey]int64), anyPort: t.anyPort}
+		for k, n := range t.ports {
+			if k.port == 53 && (k.protocol == "UDP" || k.protocol == "TCP") {
+				dns.ports[k] = n
+			} else {
+				rest.ports[k] = n
+			}
+		}
+		if len(dns.ports) > 0 {
+			entries = append(entries, dns)
+		}
+		if len(rest.ports) > 0 || rest.anyPort > 0 {
+			entries = append(entries, rest)
+		}
+	}
+
+	groups := make(map[string]*ruleGroup)
+	for _, t := range entries {
+		ports := collapsePorts(t.ports)
+		signature := make([]string, 0, len(ports)+1)
+		for _, r := range ports {
+			signature = append(signature, r.String())
+		}
+		if t.anyPort > 0 {
+			// Traffic without ports needs an L3-only rule
+			ports, signature = nil, []string{"any"}
+		}
+		key := t.peer.kind + "|" + strings.Join(signature, ",")
+		g, ok := groups[key]
+		if !ok {
+			g = &ruleGroup{kind: t.peer.kind, ports: ports, anyPort: t.anyPort > 0}
+			groups[key] = g
+		}
+		g.peers = append(g.peers, t.peer)
+		g.flows += t.flows()
+	}
+
+	result := make([]*ruleGroup, 0, len(groups))
+	for _, g := range groups {
+		sort.Slice(g.peers, func(i, j int) bool { return g.peers[i].key() < g.peers[j].key() })
+		if g.kind == peerKindCIDR && len(g.peers) > maxCIDRPeers {
+			warnings = append(warnings, fmt.Sprintf("%d external %s peers on %s collapsed into the world entity",
+				len(g.peers), direction, describePorts(g)))
+			g.kind = peerKindEntities
+			g.peers = []peer{{kind: peerKindEntities, value: "world"}}
+		}
+		result = append(result, g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].kind != result[j].kind {
+			return peerKindOrder[result[i].kind] < peerKindOrder[result[j].kind]
+		}
+		return result[i].peers[0].key() < result[j].peers[0].key()
+	})
+	return result, warnings
+}
+
+// describePorts formats the ports of a rule group.
+func describePorts(g *ruleGroup) string {
+	if g.anyPort {
+		return "any port"
+	}
+	parts := make([]string, 0, len(g.ports))
+	for _, r := range g.ports {
+		parts = append(parts, r.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describePeer formats a peer for the rule summary.
+func describePeer(p peer) string {
+	switch p.kind {
+	case peerKindEndpoints, peerKindDNS:
+		keys := make([]string, 0, len(p.labels))
+		for k := range p.labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			parts = append(parts, k+"="+p.labels[k])
+		}
+		if len(parts) == 0 {
+			return p.namespace + "/*"
+		}
+		return p.namespace + "/" + strings.Join(parts, ",")
+	case peerKindEntities:
+		return "entity:" + p.value
+	case peerKindFQDN:
+		return "fqdn:" + p.value
+	default:
+		return "cidr:" + p.value
+	}
+}
+
+// CiliumNetworkPolicy document types. Only the fields the builder emits are
+// modeled; field order follows the Cilium API.
+type cnpDocument struct {
+	APIVersion string      `json:"apiVersion"`
+	Kind       string      `json:"kind"`
+	Metadata   cnpMetadata `json:"metadata"`
+	Spec       cnpSpec     `json:"spec"`
+}
+
+type cnpMetadata struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+type cnpSpec struct {
+	EndpointSelector  cnpSelector     `json:"endpointSelector"`
+	Ingress           []cnpRule       `json:"ingress,omitempty"`
+	Egress            []cnpRule       `json:"egress,omitempty"`
+	EnableDefaultDeny *cnpDefaultDeny `json:"enableDefaultDeny,omitempty"`
+}
+
+type cnpDefaultDeny struct {
+	Ingress *bool `json:"ingress,omitempty"`
+	Egress  *bool `json:"egress,omitempty"`
+}
+
+type cnpSelector struct {
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+}
+
+type cnpRule struct {
+	FromEndpoints []cnpSelector `json:"fromEndpoints,omitempty"`
+	FromCIDR      []string      `json:"fromCIDR,omitempty"`
+	FromEntities  []string      `json:"fromEntities,omitempty"`
+	ToEndpoints   []cnpSelector `json:"toEndpoints,omitempty"`
+	ToCIDR        []string      `json:"toCIDR,omitempty"`
+	ToEntities    []string      `json:"toEntities,omitempty"`
+	ToFQDNs       []cnpFQDN     `json:"toFQDNs,omitempty"`
+	ToPorts       []cnpPortRule `json:"toPorts,omitempty"`
+}
+
+type cnpFQDN struct {
+	MatchName string `json:"matchName"`
+}
+
+type cnpPortRule struct {
+	Ports []cnpPort   `json:"ports"`
+	Rules *cnpL7Rules `json:"rules,omitempty"`
+}
+
+type cnpPort struct {
+	Port     string `json:"port"`
+	EndPort  int32  `json:"endPort,omitempty"`
+	Protocol string `json:"protocol"`
+}
+
+type cnpL7Rules struct {
+	DNS []cnpDNSRule `json:"dns,omitempty"`
+}
+
+type cnpDNSRule struct {
+	MatchPattern string `json:"matchPattern"`
+}
+
+// build renders the collected traffic as a CiliumNetworkPolicy. A direction
+// without traffic is default-denied.
+func (b *policyBuilder) build(name string, podSelector map[string]string) (string, []*RecommendedRule, []string, error) {
+	doc := cnpDocument{
+		APIVersion: "cilium.io/v2",
+		Kind:       "CiliumNetworkPolicy",
+		Metadata:   cnpMetadata{Name: name, Namespace: b.namespace},
+		Spec: cnpSpec{
+			EndpointSelector: cnpSelector{MatchLabels: podSelector},
+		},
+	}
+
+	var summaries []*RecommendedRule
+	var warnings []string
+	for _, direction := range []string{"ingress", "egress"} {
+		groups, groupWarnings := b.groupRules(direction)
+		warnings = append(warnings, groupWarnings...)
+
+		if len(groups) == 0 {
+			enabled := true
+			if doc.Spec.EnableDefaultDeny == nil {
+				doc.Spec.EnableDefaultDeny = &cnpDefaultDeny{}
+			}
+			if direction == "ingress" {
+				doc.Spec.EnableDefaultDeny.Ingress = &enabled
+			} else {
+				doc.Spec.EnableDefaultDeny.Egress = &enabled
+			}
+			warnings = append(warnings, fmt.Sprintf("No allowed %s traffic observed, %s is denied", direction, direction))
+			continue
+		}
+
+		for _, g := range groups {
+			rule := b.renderRule(direction, g)
+			if direction == "ingress" {
+				doc.Spec.Ingress = append(doc.Spec.Ingress, rule)
+			} else {
+				doc.Spec.Egress = append(doc.Spec.Egress, rule)
+			}
+
+			summary := &RecommendedRule{Direction: direction, FlowCount: g.flows}
+			for _, p := range g.peers {
+				summary.Peers = append(summary.Peers, describePeer(p))
+			}
+			for _, r := range g.ports {
+				summary.Ports = append(summary.Ports, r.String())
+			}
+			summaries = append(summaries, summary)
+		}
+	}
+
+	content, err := yaml.Marshal(doc)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to render policy: %w", err)
+	}
+	return string(content), summaries, warnings, nil
+}
+
+// renderRule renders a rule group as a Cilium rule.
+func (b *policyBuilder) renderRule(direction string, g *ruleGroup) cnpRule {
+	var rule cnpRule
+	var endpoints []cnpSelector
+	var cidrs, entities []string
+	for _, p := range g.peers {
+		switch p.kind {
+		case peerKindEndpoints, peerKindDNS:
+			sel := cnpSelector{MatchLabels: make(map[string]string, len(p.labels)+1)}
+			for k, v := range p.labels {
+				sel.MatchLabels[k] = v
+			}
+			// Peers are implicitly scoped to the policy namespace
+			if p.namespace != b.namespace {
+				sel.MatchLabels[namespaceLabel] = p.namespace
+			}
+			endpoints = append(endpoints, sel)
+		case peerKindCIDR:
+			cidrs = append(cidrs, p.value)
+		case peerKindEntities:
+			entities = append(entities, p.value)
+		case peerKindFQDN:
+			rule.ToFQDNs = append(rule.ToFQDNs, cnpFQDN{MatchName: p.value})
+		}
+	}
+	if direction == "ingress" {
+		rule.FromEndpoints, rule.FromCIDR, rule.FromEntities = endpoints, cidrs, entities
+	} else {
+		rule.ToEndpoints, rule.ToCIDR, rule.ToEntities = endpoints, cidrs, entities
+	}
+
+	if len(g.ports) > 0 {
+		portRule := cnpPortRule{}
+		for _, r := range g.ports {
+			port := cnpPort{Port: fmt.Sprintf("%d", r.start), Protocol: r.protocol}
+			if r.end > r.start {
+				port.EndPort = int32(r.end)
+			}
+			portRule.Ports = append(portRule.Ports, port)
+		}
+		if g.kind == peerKindDNS {
+			portRule.Rules = &cnpL7Rules{DNS: []cnpDNSRule{{MatchPattern: "*"}}}
+		}
+		rule.ToPorts = []cnpPortRule{portRule}
+	}
+	return rule
+}
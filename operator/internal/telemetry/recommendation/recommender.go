@@ -0,0 +1,208 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+	"github.com/policy-hub/operator/internal/telemetry/simulation"
+	"github.com/policy-hub/operator/internal/telemetry/storage"
+)
+
+// Recommender generates least-privilege CiliumNetworkPolicies from the flows
+// a workload was observed making.
+type Recommender struct {
+	storageMgr  *storage.Manager
+	engine      *simulation.Engine
+	parallelism int
+	log         logr.Logger
+}
+
+// RecommenderConfig contains configuration for the recommender.
+type RecommenderConfig struct {
+	StorageManager *storage.Manager
+	// Engine simulates generated policies for the self-check (optional)
+	Engine *simulation.Engine
+	// Parallelism is the number of storage files read concurrently
+	// (default: the number of CPUs, at most 4)
+	Parallelism int
+	Logger      logr.Logger
+}
+
+// NewRecommender creates a new policy recommender.
+func NewRecommender(cfg RecommenderConfig) *Recommender {
+	engine := cfg.Engine
+	if engine == nil {
+		engine = simulation.NewEngine(simulation.EngineConfig{
+			StorageManager: cfg.StorageManager,
+			Logger:         cfg.Logger,
+		})
+	}
+	if cfg.Parallelism <= 0 {
+		cfg.Parallelism = min(runtime.NumCPU(), 4)
+	}
+	return &Recommender{
+		storageMgr:  cfg.StorageManager,
+		engine:      engine,
+		parallelism: cfg.Parallelism,
+		log:         cfg.Logger.WithName("recommender"),
+	}
+}
+
+// Recommend generates a policy allowing exactly the traffic the selected
+// workload was allowed to make in the training window, then simulates it
+// against that window to check that none of it would be denied.
+func (r *Recommender) Recommend(ctx context.Context, req *RecommendationRequest) (*RecommendationResponse, error) {
+	startTime := time.Now()
+
+	if req.Namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+	if r.storageMgr == nil {
+		return nil, fmt.Errorf("storage manager not configured")
+	}
+
+	r.log.Info("Generating policy recommendation",
+		"namespace", req.Namespace,
+		"podSelector", req.PodSelector,
+		"startTime", req.StartTime,
+		"endTime", req.EndTime,
+	)
+
+	response := &RecommendationResponse{
+		PolicyType:  "CILIUM_NETWORK",
+		PolicyName:  req.PolicyName,
+		Namespace:   req.Namespace,
+		GeneratedAt: startTime,
+	}
+	if response.PolicyName == "" {
+		response.PolicyName = defaultPolicyName(req.Namespace, req.PodSelector)
+	}
+
+	builder := newPolicyBuilder(req.Namespace)
+	query := models.QueryEventsRequest{
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+		Namespaces: []string{req.Namespace},
+		EventTypes: []string{string(models.EventTypeFlow)},
+	}
+	err := r.streamEvents(ctx, query, req.Progress, func(event *models.TelemetryEvent) {
+		// Only forwarded traffic is learned, including traffic a policy in
+		// audit mode would drop; replies are allowed by connection tracking
+		forwarded := event.Verdict == models.VerdictAllowed || event.Verdict == models.VerdictAudit
+		if !forwarded || event.IsReply {
+			return
+		}
+
+		selected := false
+		if event.DstNamespace == req.Namespace && selectorMatches(event.DstPodLabels, req.PodSelector) {
+			builder.addFlow("ingress", event)
+			selected = true
+		}
+		if event.SrcNamespace == req.Namespace && selectorMatches(event.SrcPodLabels, req.PodSelector) {
+			builder.addFlow("egress", event)
+			selected = true
+		}
+		if selected {
+			response.FlowsAnalyzed++
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if response.FlowsAnalyzed == 0 {
+		return nil, fmt.Errorf("no allowed flows of the workload between %s and %s",
+			req.StartTime.Format(time.RFC3339), req.EndTime.Format(time.RFC3339))
+	}
+
+	content, rules, warnings, err := builder.build(response.PolicyName, req.PodSelector)
+	if err != nil {
+		return nil, err
+	}
+	response.PolicyContent = content
+	response.Rules = rules
+	response.Warnings = warnings
+
+	response.SelfCheck = r.selfCheck(ctx, req, content)
+	response.Duration = time.Since(startTime)
+
+	r.log.Info("Policy recommendation complete",
+		"policy", response.PolicyName,
+		"flows", response.FlowsAnalyzed,
+		"rules", len(response.Rules),
+		"selfCheckPassed", response.SelfCheck.Passed,
+		"duration", response.Duration,
+	)
+
+	return response, nil
+}
+
+// selfCheck simulates a generated policy against its training window.
+func (r *Recommender) selfCheck(ctx context.Context, req *RecommendationRequest, content string) *SelfCheckResult {
+	resp, err := r.engine.Simulate(ctx, &simulation.SimulationRequest{
+		PolicyContent: content,
+		PolicyType:    "CILIUM_NETWORK",
+		StartTime:     req.StartTime,
+		EndTime:       req.EndTime,
+		Namespaces:    []string{req.Namespace},
+	})
+	if err != nil {
+		return &SelfCheckResult{Errors: []string{err.Error()}}
+	}
+
+	check := &SelfCheckResult{
+		TotalFlowsAnalyzed: resp.TotalFlowsAnalyzed,
+		Errors:             resp.Errors,
+	}
+	if resp.BreakdownByVerdict != nil {
+		check.WouldDeny = resp.BreakdownByVerdict.AllowedToDenied
+	}
+	check.Passed = check.WouldDeny == 0 && len(check.Errors) == 0
+	return check
+}
+
+// selectorMatches checks pod labels against a workload selector, accepting
+// both plain and k8s:-prefixed label keys.
+func selectorMatches(labels map[string]string, selector map[string]string) bool {
+	for key, want := range selector {
+		value, ok := labels[key]
+		if !ok {
+			value, ok = labels["k8s:"+key]
+		}
+		if !ok || value != want {
+			return false
+		}
+	}
+	return true
+}
+
+// defaultPolicyName names a policy after the workload it was generated for.
+func defaultPolicyName(namespace string, selector map[string]string) string {
+	return workloadName(namespace, selector) + "-least-privilege"
+}
+
+// workloadName names a workload after its identity label, or its selector
+// values when it has none.
+func workloadName(namespace string, selector map[string]string) string {
+	for _, key := range identityLabels {
+		if value, ok := selector[key]; ok {
+			return strings.ToLower(value)
+		}
+	}
+	if len(selector) == 0 {
+		return strings.ToLower(namespace)
+	}
+	values := make([]string, 0, len(selector))
+	for _, value := range selector {
+		values = append(values, strings.ToLower(value))
+	}
+	sort.Strings(values)
+	return strings.Join(values, "-")
+}
@@ -0,0 +1,316 @@
+package recommendation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+	"github.com/policy-hub/operator/internal/telemetry/simulation"
+	"github.com/policy-hub/operator/internal/telemetry/storage"
+)
+
+// writeEvents stores events and returns a storage manager that can query them.
+func writeEvents(t *testing.T, events []*models.TelemetryEvent) *storage.Manager {
+	t.Helper()
+
+	tmpDir, err := os.MkdirTemp("", "recommendation-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tmpDir) })
+
+	cfg := storage.ManagerConfig{
+		BasePath: tmpDir,
+		NodeName: "test-node",
+		Logger:   logr.Discard(),
+	}
+	writer, err := storage.NewManager(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+	if err := writer.Write(events); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	// Close the manager to ensure all data is flushed and indexed
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	mgr, err := storage.NewManager(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+	t.Cleanup(func() { mgr.Close() })
+	return mgr
+}
+
+func TestRecommender_Recommend(t *testing.T) {
+	api := map[string]string{"app": "api", "pod-template-hash": "7d9f8"}
+	now := time.Now().UTC()
+
+	var events []*models.TelemetryEvent
+	flow := func(e models.TelemetryEvent) {
+		e.ID = fmt.Sprintf("flow-%d", len(events))
+		e.EventType = models.EventTypeFlow
+		e.Timestamp = now.Add(-10 * time.Minute)
+		e.NodeName = "test-node"
+		if e.Verdict == "" {
+			e.Verdict = models.VerdictAllowed
+		}
+		events = append(events, &e)
+	}
+
+	// Ingress from two frontend pods of different revisions
+	for _, hash := range []string{"aaa", "bbb"} {
+		flow(models.TelemetryEvent{
+			SrcNamespace: "prod", SrcPodLabels: map[string]string{"app": "frontend", "pod-template-hash": hash},
+			DstNamespace: "prod", DstPodLabels: api, DstPort: 8080, Protocol: "TCP",
+		})
+	}
+	// Ingress from the world
+	flow(models.TelemetryEvent{
+		SrcIP: "198.51.100.7", SrcIdentity: 2,
+		DstNamespace: "prod", DstPodLabels: api, DstPort: 8443, Protocol: "TCP",
+	})
+	// Egress to a database in another namespace and a cache on a port range
+	flow(models.TelemetryEvent{
+		SrcNamespace: "prod", SrcPodLabels: api,
+		DstNamespace: "data", DstPodLabels: map[string]string{"app.kubernetes.io/name": "postgres", "statefulset.kubernetes.io/pod-name": "postgres-0"},
+		DstPort: 5432, Protocol: "TCP",
+	})
+	for _, port := range []uint32{9000, 9001, 9002} {
+		flow(models.TelemetryEvent{
+			SrcNamespace: "prod", SrcPodLabels: api,
+			DstNamespace: "prod", DstPodLabels: map[string]string{"tier": "cache"},
+			DstPort: port, Protocol: "TCP",
+		})
+	}
+	// DNS lookups and the external API they resolved
+	flow(models.TelemetryEvent{
+		SrcNamespace: "prod", SrcPodLabels: api,
+		DstNamespace: "kube-system", DstPodLabels: map[string]string{"k8s-app": "kube-dns"},
+		DstPort: 53, Protocol: "UDP", L7Type: "REQUEST", DNSQuery: "api.stripe.com.",
+	})
+	flow(models.TelemetryEvent{
+		SrcNamespace: "prod", SrcPodLabels: api,
+		DstIP: "203.0.113.10", DstIdentity: 16777217, DstDNSName: "api.stripe.com",
+		DstPort: 443, Protocol: "TCP",
+	})
+	// The kube-apiserver
+	flow(models.TelemetryEvent{
+		SrcNamespace: "prod", SrcPodLabels: api,
+		DstIP: "10.0.0.1", DstIdentity: 7, DstPort: 6443, Protocol: "TCP",
+	})
+	// Not learned: replies, denied flows and other workloads
+	flow(models.TelemetryEvent{
+		SrcNamespace: "data", SrcPodLabels: map[string]string{"app.kubernetes.io/name": "postgres"},
+		DstNamespace: "prod", DstPodLabels: api, DstPort: 51234, Protocol: "TCP", IsReply: true,
+	})
+	flow(models.TelemetryEvent{
+		SrcNamespace: "prod", SrcPodLabels: api,
+		DstNamespace: "prod", DstPodLabels: map[string]string{"app": "admin"},
+		DstPort: 22, Protocol: "TCP", Verdict: models.VerdictDropped,
+	})
+	flow(models.TelemetryEvent{
+		SrcNamespace: "prod", SrcPodLabels: map[string]string{"app": "frontend"},
+		DstNamespace: "prod", DstPodLabels: map[string]string{"app": "static"},
+		DstPort: 80, Protocol: "TCP",
+	})
+
+	mgr := writeEvents(t, events)
+	recommender := NewRecommender(RecommenderConfig{StorageManager: mgr, Logger: logr.Discard()})
+
+	resp, err := recommender.Recommend(context.Background(), &RecommendationRequest{
+		Namespace:   "prod",
+		PodSelector: map[string]string{"app": "api"},
+		StartTime:   now.Add(-1 * time.Hour),
+		EndTime:     now,
+	})
+	if err != nil {
+		t.Fatalf("Recommend() error = %v", err)
+	}
+
+	if resp.PolicyName != "api-least-privilege" {
+		t.Errorf("PolicyName = %s, want api-least-privilege", resp.PolicyName)
+	}
+	if resp.FlowsAnalyzed != 10 {
+		t.Errorf("FlowsAnalyzed = %d, want 10", resp.FlowsAnalyzed)
+	}
+	if resp.SelfCheck == nil || !resp.SelfCheck.Passed || resp.SelfCheck.WouldDeny != 0 {
+		t.Errorf("SelfCheck = %+v, want passed with no would-deny\n%s", resp.SelfCheck, resp.PolicyContent)
+	}
+
+	for _, want := range []string{"matchName: api.stripe.com", "matchPattern: '*'", "endPort: 9002", "k8s:io.kubernetes.pod.namespace: data", "198.51.100.7/32", "kube-apiserver"} {
+		if !strings.Contains(resp.PolicyContent, want) {
+			t.Errorf("PolicyContent missing %q:\n%s", want, resp.PolicyContent)
+		}
+	}
+	for _, unwanted := range []string{"pod-template-hash", "statefulset.kubernetes.io/pod-name", "admin", "static", "51234"} {
+		if strings.Contains(resp.PolicyContent, unwanted) {
+			t.Errorf("PolicyContent contains %q:\n%s", unwanted, resp.PolicyContent)
+		}
+	}
+
+	// The generated policy is least-privilege: new peers and ports are denied
+	policy, err := simulation.NewPolicyParser().Parse(resp.PolicyContent, "CILIUM_NETWORK")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(policy.IngressRules) != 2 {
+		t.Errorf("len(IngressRules) = %d, want 2 (frontend pods grouped, world CIDR)", len(policy.IngressRules))
+	}
+	if policy.DefaultDenyType != "both" {
+		t.Errorf("DefaultDenyType = %s, want both", policy.DefaultDenyType)
+	}
+}
+
+func TestRecommender_Recommend_NoTraffic(t *testing.T) {
+	now := time.Now().UTC()
+	mgr := writeEvents(t, []*models.TelemetryEvent{{
+		ID: "1", EventType: models.EventTypeFlow, Timestamp: now.Add(-10 * time.Minute), NodeName: "test-node",
+		SrcNamespace: "prod", SrcPodLabels: map[string]string{"app": "other"},
+		DstNamespace: "prod", DstPodLabels: map[string]string{"app": "db"},
+		DstPort: 5432, Protocol: "TCP", Verdict: models.VerdictAllowed,
+	}})
+	recommender := NewRecommender(RecommenderConfig{StorageManager: mgr, Logger: logr.Discard()})
+
+	if _, err := recommender.Recommend(context.Background(), &RecommendationRequest{}); err == nil {
+		t.Error("Recommend() expected error without namespace")
+	}
+	_, err := recommender.Recommend(context.Background(), &RecommendationRequest{
+		Namespace:   "prod",
+		PodSelector: map[string]string{"app": "api"},
+		StartTime:   now.Add(-1 * time.Hour),
+		EndTime:     now,
+	})
+	if err == nil {
+		t.Error("Recommend() expected error without workload traffic")
+	}
+}
+
+func TestRecommender_EgressOnlyDeniesIngress(t *testing.T) {
+	now := time.Now().UTC()
+	mgr := writeEvents(t, []*models.TelemetryEvent{{
+		ID: "1", EventType: models.EventTypeFlow, Timestamp: now.Add(-10 * time.Minute), NodeName: "test-node",
+		SrcNamespace: "batch", SrcPodLabels: map[string]string{"job-name": "report-123", "team": "finance"},
+		DstNamespace: "batch", DstPodLabels: map[string]string{"app": "db"},
+		DstPort: 5432, Protocol: "TCP", Verdict: models.VerdictAllowed,
+	}})
+	recommender := NewRecommender(RecommenderConfig{StorageManager: mgr, Logger: logr.Discard()})
+
+	resp, err := recommender.Recommend(context.Background(), &RecommendationRequest{
+		Namespace:   "batch",
+		PodSelector: map[string]string{"team": "finance"},
+		StartTime:   now.Add(-1 * time.Hour),
+		EndTime:     now,
+		PolicyName:  "reports",
+	})
+	if err != nil {
+		t.Fatalf("Recommend() error = %v", err)
+	}
+	if !strings.Contains(resp.PolicyContent, "enableDefaultDeny:\n    ingress: true") {
+		t.Errorf("PolicyContent should deny ingress:\n%s", resp.PolicyContent)
+	}
+	if len(resp.Warnings) != 1 {
+		t.Errorf("Warnings = %v, want one for ingress", resp.Warnings)
+	}
+	if !resp.SelfCheck.Passed {
+		t.Errorf("SelfCheck = %+v", resp.SelfCheck)
+	}
+}
+
+func TestRecommender_Recommend_Streams(t *testing.T) {
+	now := time.Now().UTC()
+	mgr := writeEvents(t, []*models.TelemetryEvent{{
+		ID: "1", EventType: models.EventTypeFlow, Timestamp: now.Add(-10 * time.Minute), NodeName: "test-node",
+		SrcNamespace: "prod", SrcPodLabels: map[string]string{"app": "api"},
+		DstNamespace: "prod", DstPodLabels: map[string]string{"app": "db"},
+		DstPort: 5432, Protocol: "TCP", Verdict: models.VerdictAllowed,
+	}})
+	recommender := NewRecommender(RecommenderConfig{StorageManager: mgr, Logger: logr.Discard()})
+
+	var last simulation.SimulationProgress
+	req := &RecommendationRequest{
+		Namespace:   "prod",
+		PodSelector: map[string]string{"app": "api"},
+		StartTime:   now.Add(-1 * time.Hour),
+		EndTime:     now,
+		Progress:    func(p simulation.SimulationProgress) { last = p },
+	}
+	if _, err := recommender.Recommend(context.Background(), req); err != nil {
+		t.Fatalf("Recommend() error = %v", err)
+	}
+	if last.FilesTotal == 0 || last.FilesDone != last.FilesTotal || last.EventsScanned != 1 {
+		t.Errorf("Last progress = %+v, want every file read and 1 event scanned", last)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := recommender.Recommend(ctx, req); !errors.Is(err, context.Canceled) {
+		t.Errorf("Recommend() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestCollapsePorts(t *testing.T) {
+	ports := map[portKey]int64{
+		{protocol: "TCP", port: 80}:   1,
+		{protocol: "TCP", port: 8080}: 1,
+		{protocol: "TCP", port: 8081}: 1,
+		{protocol: "TCP", port: 8082}: 1,
+		{protocol: "UDP", port: 8081}: 1,
+	}
+
+	var got []string
+	for _, r := range collapsePorts(ports) {
+		got = append(got, r.String())
+	}
+	want := "80/TCP,8080-8082/TCP,8081/UDP"
+	if strings.Join(got, ",") != want {
+		t.Errorf("collapsePorts() = %v, want %s", got, want)
+	}
+}
+
+func TestStableLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   map[string]string
+	}{
+		{
+			name:   "identity label wins",
+			labels: map[string]string{"app": "web", "version": "v2", "pod-template-hash": "abc"},
+			want:   map[string]string{"app": "web"},
+		},
+		{
+			name:   "preferred identity label",
+			labels: map[string]string{"k8s:app": "web", "app.kubernetes.io/name": "storefront"},
+			want:   map[string]string{"app.kubernetes.io/name": "storefront"},
+		},
+		{
+			name:   "volatile labels dropped",
+			labels: map[string]string{"team": "finance", "job-name": "report-123", "controller-uid": "x"},
+			want:   map[string]string{"team": "finance"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := stableLabels(tt.labels)
+			if len(got) != len(tt.want) {
+				t.Fatalf("stableLabels() = %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("stableLabels()[%s] = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
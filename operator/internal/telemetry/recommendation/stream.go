@@ -0,0 +1,44 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+	"github.com/policy-hub/operator/internal/telemetry/simulation"
+	"github.com/policy-hub/operator/internal/telemetry/storage"
+)
+
+// streamEvents visits the events matching a query without loading them into
+// memory. Files are read concurrently but visit calls are serialized, so the
+// visitor needs no locking; what the recommenders learn from events does not
+// depend on their order. Cancelling the context aborts the read with the
+// context's error.
+func (r *Recommender) streamEvents(ctx context.Context, query models.QueryEventsRequest, progress func(simulation.SimulationProgress), visit func(event *models.TelemetryEvent)) error {
+	files, err := r.storageMgr.ListFiles(query)
+	if err != nil {
+		return fmt.Errorf("failed to query historical data: %w", err)
+	}
+
+	r.log.V(1).Info("Streaming historical events", "files", len(files), "parallelism", r.parallelism)
+
+	opts := storage.IterateOptions{Parallelism: r.parallelism}
+	if progress != nil {
+		opts.Progress = func(p storage.IterateProgress) {
+			progress(simulation.SimulationProgress{
+				FilesTotal:    p.FilesTotal,
+				FilesDone:     p.FilesDone,
+				EventsScanned: p.EventsRead,
+			})
+		}
+	}
+
+	var mu sync.Mutex
+	return r.storageMgr.IterateFiles(ctx, files, query, opts, func(_ int, event *models.TelemetryEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		visit(event)
+		return nil
+	})
+}
@@ -0,0 +1,446 @@
+package recommendation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/policy-hub/operator/internal/telemetry/aggregator"
+	"github.com/policy-hub/operator/internal/telemetry/collector"
+	"github.com/policy-hub/operator/internal/telemetry/models"
+	"github.com/policy-hub/operator/internal/telemetry/simulation"
+)
+
+// riskyBinaryCategories are binary categories that weaken an allowlist: a
+// permitted shell or package manager can run or install anything.
+var riskyBinaryCategories = map[string]bool{
+	"shell":           true,
+	"package_manager": true,
+}
+
+// workloadBaseline merges the process baselines of the pods of a workload.
+type workloadBaseline struct {
+	labels         map[string]string
+	pods           []string
+	firstSeen      time.Time
+	lastSeen       time.Time
+	execs          int64
+	binaries       map[string]int64
+	sensitiveFiles map[[2]string]int64 // binary, path
+}
+
+// RecommendTracingPolicies generates an allowlist TracingPolicyNamespaced
+// per workload from the processes its pods executed and the sensitive files
+// they opened in the baseline window. Policies default to audit mode so they
+// can be deployed before they enforce.
+func (r *Recommender) RecommendTracingPolicies(ctx context.Context, req *TracingPolicyRequest) (*TracingPolicyResponse, error) {
+	startTime := time.Now()
+
+	if req.Namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+	mode := strings.ToLower(req.Mode)
+	if mode == "" {
+		mode = TracingModeAudit
+	}
+	if mode != TracingModeAudit && mode != TracingModeEnforce {
+		return nil, fmt.Errorf("unsupported mode %q, must be %s or %s", req.Mode, TracingModeAudit, TracingModeEnforce)
+	}
+	if r.storageMgr == nil {
+		return nil, fmt.Errorf("storage manager not configured")
+	}
+
+	r.log.Info("Generating tracing policy recommendations",
+		"namespace", req.Namespace,
+		"podSelector", req.PodSelector,
+		"mode", mode,
+		"startTime", req.StartTime,
+		"endTime", req.EndTime,
+	)
+
+	response := &TracingPolicyResponse{GeneratedAt: startTime}
+
+	summarizer := aggregator.NewSummarizer(aggregator.SummarizerConfig{Logger: r.log})
+	seen := make(map[string][2]time.Time)
+	query := models.QueryEventsRequest{
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+		Namespaces: []string{req.Namespace},
+		EventTypes: []string{
+			string(models.EventTypeProcessExec),
+			string(models.EventTypeFileAccess),
+		},
+	}
+	err := r.streamEvents(ctx, query, req.Progress, func(event *models.TelemetryEvent) {
+		// Only permitted behaviour is learned; killed processes stay outside the baseline
+		if event.Verdict != models.VerdictAllowed || event.SrcNamespace != req.Namespace {
+			return
+		}
+		if !selectorMatches(event.SrcPodLabels, req.PodSelector) {
+			return
+		}
+		summarizer.AddEvent(event)
+		response.EventsAnalyzed++
+
+		window, ok := seen[event.SrcPodName]
+		if !ok || event.Timestamp.Before(window[0]) {
+			window[0] = event.Timestamp
+		}
+		if event.Timestamp.After(window[1]) {
+			window[1] = event.Timestamp
+		}
+		seen[event.SrcPodName] = window
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if response.EventsAnalyzed == 0 {
+		return nil, fmt.Errorf("no process events of the namespace between %s and %s",
+			req.StartTime.Format(time.RFC3339), req.EndTime.Format(time.RFC3339))
+	}
+
+	workloads := make(map[string]*workloadBaseline)
+	for _, baseline := range summarizer.ProcessBaselines() {
+		labels := stableLabels(baseline.PodLabels)
+		if len(labels) == 0 {
+			response.Warnings = append(response.Warnings,
+				fmt.Sprintf("Pod %s has no labels to select it by, its processes are not covered", baseline.PodName))
+			continue
+		}
+
+		key := workloadKey(labels)
+		w, ok := workloads[key]
+		if !ok {
+			w = &workloadBaseline{
+				labels:         labels,
+				binaries:       make(map[string]int64),
+				sensitiveFiles: make(map[[2]string]int64),
+			}
+			workloads[key] = w
+		}
+		w.pods = append(w.pods, baseline.PodName)
+		if window := seen[baseline.PodName]; w.firstSeen.IsZero() || window[0].Before(w.firstSeen) {
+			w.firstSeen = window[0]
+		}
+		if window := seen[baseline.PodName]; window[1].After(w.lastSeen) {
+			w.lastSeen = window[1]
+		}
+		w.execs += baseline.TotalExecs
+		for _, b := range baseline.Binaries {
+			w.binaries[b.Binary] += b.Count
+		}
+		for _, f := range baseline.SensitiveFiles {
+			w.sensitiveFiles[[2]string{f.Binary, f.Path}] += f.Count
+		}
+	}
+
+	keys := make([]string, 0, len(workloads))
+	for key := range workloads {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		w := workloads[key]
+		if len(w.binaries) == 0 {
+			response.Warnings = append(response.Warnings,
+				fmt.Sprintf("No process executions observed for workload %s, no policy generated", key))
+			continue
+		}
+
+		policy, err := r.buildTracingPolicy(ctx, req, mode, w)
+		if err != nil {
+			return nil, err
+		}
+		response.Policies = append(response.Policies, policy)
+	}
+
+	response.Duration = time.Since(startTime)
+
+	r.log.Info("Tracing policy recommendation complete",
+		"namespace", req.Namespace,
+		"events", response.EventsAnalyzed,
+		"policies", len(response.Policies),
+		"duration", response.Duration,
+	)
+
+	return response, nil
+}
+
+// buildTracingPolicy renders the policy of a workload with its coverage
+// report, and self-checks its enforcing variant against the baseline window.
+func (r *Recommender) buildTracingPolicy(ctx context.Context, req *TracingPolicyRequest, mode string, w *workloadBaseline) (*RecommendedTracingPolicy, error) {
+	policy := &RecommendedTracingPolicy{
+		PolicyType:  "TETRAGON",
+		PolicyName:  workloadName(req.Namespace, w.labels) + "-process-baseline",
+		Namespace:   req.Namespace,
+		PodSelector: w.labels,
+		Mode:        mode,
+		Coverage: &BaselineCoverage{
+			Pods:       w.pods,
+			FirstSeen:  w.firstSeen,
+			LastSeen:   w.lastSeen,
+			ExecEvents: w.execs,
+		},
+	}
+	sort.Strings(policy.Coverage.Pods)
+
+	for binary, count := range w.binaries {
+		category := collector.CategorizeBinary(binary)
+		policy.Coverage.Binaries = append(policy.Coverage.Binaries, BinaryCoverage{
+			Binary:   binary,
+			Category: category,
+			Count:    count,
+		})
+		if riskyBinaryCategories[category] {
+			policy.Warnings = append(policy.Warnings,
+				fmt.Sprintf("Baseline permits %s %s, which can run arbitrary commands", strings.ReplaceAll(category, "_", " "), binary))
+		}
+	}
+	sort.Slice(policy.Coverage.Binaries, func(i, j int) bool {
+		a, b := policy.Coverage.Binaries[i], policy.Coverage.Binaries[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Binary < b.Binary
+	})
+
+	for access, count := range w.sensitiveFiles {
+		file := SensitiveFileCoverage{Binary: access[0], Path: access[1], Count: count}
+		file.Watched = sensitivePrefix(file.Path) != ""
+		if !file.Watched {
+			policy.Warnings = append(policy.Warnings,
+				fmt.Sprintf("Access to %s by %s is sensitive but not under a watched path prefix", file.Path, file.Binary))
+		}
+		policy.Coverage.SensitiveFiles = append(policy.Coverage.SensitiveFiles, file)
+	}
+	sort.Slice(policy.Coverage.SensitiveFiles, func(i, j int) bool {
+		a, b := policy.Coverage.SensitiveFiles[i], policy.Coverage.SensitiveFiles[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Binary < b.Binary
+	})
+	sort.Strings(policy.Warnings)
+
+	content, err := renderTracingPolicy(policy, mode)
+	if err != nil {
+		return nil, err
+	}
+	policy.PolicyContent = content
+
+	enforcing := content
+	if mode != TracingModeEnforce {
+		if enforcing, err = renderTracingPolicy(policy, TracingModeEnforce); err != nil {
+			return nil, err
+		}
+	}
+	policy.SelfCheck = r.tracingSelfCheck(ctx, req, enforcing)
+
+	return policy, nil
+}
+
+// tracingSelfCheck simulates an enforcing TracingPolicy against the baseline
+// window. A sound allowlist kills none of the processes it was built from.
+func (r *Recommender) tracingSelfCheck(ctx context.Context, req *TracingPolicyRequest, content string) *SelfCheckResult {
+	resp, err := r.engine.Simulate(ctx, &simulation.SimulationRequest{
+		PolicyContent: content,
+		PolicyType:    "TETRAGON",
+		StartTime:     req.StartTime,
+		EndTime:       req.EndTime,
+		Namespaces:    []string{req.Namespace},
+	})
+	if err != nil {
+		return &SelfCheckResult{Errors: []string{err.Error()}}
+	}
+
+	check := &SelfCheckResult{
+		TotalFlowsAnalyzed: resp.TotalFlowsAnalyzed,
+		Errors:             resp.Errors,
+	}
+	if resp.BreakdownByVerdict != nil {
+		check.WouldDeny = resp.BreakdownByVerdict.AllowedToDenied
+	}
+	check.Passed = check.WouldDeny == 0 && len(check.Errors) == 0
+	return check
+}
+
+// watchedPaths returns the sensitive paths the generated policies watch with
+// a Prefix match. The collector also flags paths that merely contain a
+// sensitive directory such as /.ssh/, which a prefix cannot express.
+func watchedPaths() []string {
+	var paths []string
+	for _, path := range collector.SensitivePaths {
+		if !strings.HasPrefix(path, "/.") {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// sensitivePrefix returns the watched path a path falls under, or "" if it
+// is not watched.
+func sensitivePrefix(path string) string {
+	for _, prefix := range watchedPaths() {
+		if strings.HasPrefix(path, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// workloadKey identifies a workload by its selector labels.
+func workloadKey(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// TracingPolicyNamespaced document types. Only the fields the generator emits
+// are modeled.
+type tpDocument struct {
+	APIVersion string      `json:"apiVersion"`
+	Kind       string      `json:"kind"`
+	Metadata   cnpMetadata `json:"metadata"`
+	Spec       tpSpec      `json:"spec"`
+}
+
+type tpSpec struct {
+	PodSelector cnpSelector `json:"podSelector"`
+	KProbes     []tpKProbe  `json:"kprobes"`
+}
+
+type tpKProbe struct {
+	Call      string       `json:"call"`
+	Syscall   bool         `json:"syscall"`
+	Args      []tpArg      `json:"args"`
+	Selectors []tpSelector `json:"selectors"`
+}
+
+type tpArg struct {
+	Index int    `json:"index"`
+	Type  string `json:"type"`
+}
+
+type tpSelector struct {
+	MatchArgs     []tpArgMatch   `json:"matchArgs,omitempty"`
+	MatchBinaries []tpValueMatch `json:"matchBinaries,omitempty"`
+	MatchActions  []tpAction     `json:"matchActions"`
+}
+
+type tpArgMatch struct {
+	Index    int      `json:"index"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values"`
+}
+
+type tpValueMatch struct {
+	Operator string   `json:"operator"`
+	Values   []string `json:"values"`
+}
+
+type tpAction struct {
+	Action string `json:"action"`
+}
+
+// renderTracingPolicy renders a workload's baseline as a
+// TracingPolicyNamespaced. Executions of binaries outside the baseline are
+// caught on security_bprm_check. Opening a sensitive path is caught on
+// security_file_open unless the binary opened a sensitive path in the
+// baseline; paths nobody opened are caught for every binary.
+func renderTracingPolicy(policy *RecommendedTracingPolicy, mode string) (string, error) {
+	action := tpAction{Action: "Post"}
+	if mode == TracingModeEnforce {
+		action = tpAction{Action: "Sigkill"}
+	}
+
+	binaries := make([]string, 0, len(policy.Coverage.Binaries))
+	for _, b := range policy.Coverage.Binaries {
+		binaries = append(binaries, b.Binary)
+	}
+	sort.Strings(binaries)
+
+	accessed := make(map[string]bool)
+	accessors := make(map[string]bool)
+	for _, f := range policy.Coverage.SensitiveFiles {
+		if prefix := sensitivePrefix(f.Path); prefix != "" {
+			accessed[prefix] = true
+			if f.Binary != "" {
+				accessors[f.Binary] = true
+			}
+		}
+	}
+	var accessedPrefixes, otherPrefixes []string
+	for _, prefix := range watchedPaths() {
+		if accessed[prefix] {
+			accessedPrefixes = append(accessedPrefixes, prefix)
+		} else {
+			otherPrefixes = append(otherPrefixes, prefix)
+		}
+	}
+
+	fileOpen := tpKProbe{
+		Call: "security_file_open",
+		Args: []tpArg{{Index: 0, Type: "file"}},
+	}
+	if len(otherPrefixes) > 0 {
+		fileOpen.Selectors = append(fileOpen.Selectors, tpSelector{
+			MatchArgs:    []tpArgMatch{{Index: 0, Operator: "Prefix", Values: otherPrefixes}},
+			MatchActions: []tpAction{action},
+		})
+	}
+	if len(accessedPrefixes) > 0 {
+		sel := tpSelector{
+			MatchArgs:    []tpArgMatch{{Index: 0, Operator: "Prefix", Values: accessedPrefixes}},
+			MatchActions: []tpAction{action},
+		}
+		if len(accessors) > 0 {
+			sel.MatchBinaries = []tpValueMatch{{Operator: "NotIn", Values: sortedKeys(accessors)}}
+		}
+		fileOpen.Selectors = append(fileOpen.Selectors, sel)
+	}
+
+	doc := tpDocument{
+		APIVersion: "cilium.io/v1alpha1",
+		Kind:       "TracingPolicyNamespaced",
+		Metadata:   cnpMetadata{Name: policy.PolicyName, Namespace: policy.Namespace},
+		Spec: tpSpec{
+			PodSelector: cnpSelector{MatchLabels: policy.PodSelector},
+			KProbes: []tpKProbe{
+				{
+					Call: "security_bprm_check",
+					Args: []tpArg{{Index: 0, Type: "linux_binprm"}},
+					Selectors: []tpSelector{{
+						MatchArgs:    []tpArgMatch{{Index: 0, Operator: "NotEqual", Values: binaries}},
+						MatchActions: []tpAction{action},
+					}},
+				},
+				fileOpen,
+			},
+		},
+	}
+
+	content, err := yaml.Marshal(doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to render tracing policy: %w", err)
+	}
+	return string(content), nil
+}
+
+// sortedKeys returns the keys of a set in order.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
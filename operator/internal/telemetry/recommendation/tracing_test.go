@@ -0,0 +1,206 @@
+package recommendation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+	"github.com/policy-hub/operator/internal/telemetry/simulation"
+)
+
+func TestRecommender_RecommendTracingPolicies(t *testing.T) {
+	now := time.Now().UTC()
+	baselineEnd := now.Add(-8 * time.Minute)
+
+	var events []*models.TelemetryEvent
+	add := func(e models.TelemetryEvent) {
+		e.ID = fmt.Sprintf("proc-%d", len(events))
+		e.NodeName = "test-node"
+		e.Source = models.SourceTetragon
+		if e.Timestamp.IsZero() {
+			e.Timestamp = now.Add(-10 * time.Minute)
+		}
+		if e.Verdict == "" {
+			e.Verdict = models.VerdictAllowed
+		}
+		if e.SrcNamespace == "" {
+			e.SrcNamespace = "prod"
+		}
+		events = append(events, &e)
+	}
+	exec := func(pod string, labels map[string]string, binary string) {
+		add(models.TelemetryEvent{EventType: models.EventTypeProcessExec, SrcPodName: pod, SrcPodLabels: labels, SrcBinary: binary})
+	}
+	open := func(pod string, labels map[string]string, binary, path string) {
+		add(models.TelemetryEvent{
+			EventType: models.EventTypeFileAccess, SrcPodName: pod, SrcPodLabels: labels, SrcBinary: binary,
+			Syscall: "security_file_open", FileOperation: "security_file_open",
+			FilePath: path, SyscallArgs: []string{"file:" + path},
+		})
+	}
+
+	api1 := map[string]string{"app": "api", "pod-template-hash": "aaa"}
+	api2 := map[string]string{"app": "api", "pod-template-hash": "bbb"}
+	exec("api-1", api1, "/usr/bin/api")
+	exec("api-2", api2, "/usr/bin/api")
+	exec("api-2", api2, "/bin/sh")
+	open("api-1", api1, "/usr/bin/api", "/etc/passwd")
+	open("api-1", api1, "/usr/bin/api", "/proc/self/status")
+	open("api-2", api2, "/bin/sh", "/home/app/.ssh/id_rsa")
+	exec("worker-1", map[string]string{"app": "worker"}, "/usr/bin/worker")
+	// Not learned: killed processes, unlabeled pods and other namespaces
+	add(models.TelemetryEvent{
+		EventType: models.EventTypeProcessExec, SrcPodName: "api-1", SrcPodLabels: api1,
+		SrcBinary: "/usr/bin/nc", Verdict: models.VerdictDenied, Action: "SIGKILL",
+	})
+	exec("debug", nil, "/bin/bash")
+	add(models.TelemetryEvent{
+		EventType: models.EventTypeProcessExec, SrcNamespace: "staging", SrcPodName: "api-9",
+		SrcPodLabels: map[string]string{"app": "api"}, SrcBinary: "/usr/bin/python3",
+	})
+	// After the baseline window
+	add(models.TelemetryEvent{
+		EventType: models.EventTypeProcessExec, SrcPodName: "api-1", SrcPodLabels: api1,
+		SrcBinary: "/usr/bin/curl", Timestamp: now.Add(-5 * time.Minute),
+	})
+	add(models.TelemetryEvent{
+		EventType: models.EventTypeFileAccess, SrcPodName: "api-2", SrcPodLabels: api2, SrcBinary: "/usr/bin/api",
+		Syscall: "security_file_open", FileOperation: "security_file_open",
+		FilePath: "/etc/shadow", SyscallArgs: []string{"file:/etc/shadow"}, Timestamp: now.Add(-5 * time.Minute),
+	})
+
+	mgr := writeEvents(t, events)
+	recommender := NewRecommender(RecommenderConfig{StorageManager: mgr, Logger: logr.Discard()})
+
+	resp, err := recommender.RecommendTracingPolicies(context.Background(), &TracingPolicyRequest{
+		Namespace: "prod",
+		StartTime: now.Add(-1 * time.Hour),
+		EndTime:   baselineEnd,
+	})
+	if err != nil {
+		t.Fatalf("RecommendTracingPolicies() error = %v", err)
+	}
+
+	if resp.EventsAnalyzed != 8 {
+		t.Errorf("EventsAnalyzed = %d, want 8", resp.EventsAnalyzed)
+	}
+	if len(resp.Warnings) != 1 || !strings.Contains(resp.Warnings[0], "debug") {
+		t.Errorf("Warnings = %v, want one for the unlabeled pod", resp.Warnings)
+	}
+	if len(resp.Policies) != 2 {
+		t.Fatalf("len(Policies) = %d, want 2 (api, worker)", len(resp.Policies))
+	}
+
+	policy := resp.Policies[0]
+	if policy.PolicyName != "api-process-baseline" || policy.Mode != TracingModeAudit {
+		t.Errorf("PolicyName = %s, Mode = %s, want api-process-baseline in audit mode", policy.PolicyName, policy.Mode)
+	}
+	if got := strings.Join(policy.Coverage.Pods, ","); got != "api-1,api-2" {
+		t.Errorf("Coverage.Pods = %s, want api-1,api-2", got)
+	}
+	if len(policy.Coverage.Binaries) != 2 || policy.Coverage.Binaries[0].Binary != "/usr/bin/api" || policy.Coverage.Binaries[0].Count != 2 {
+		t.Errorf("Coverage.Binaries = %+v, want /usr/bin/api twice and /bin/sh", policy.Coverage.Binaries)
+	}
+	if len(policy.Coverage.SensitiveFiles) != 3 {
+		t.Errorf("Coverage.SensitiveFiles = %+v, want 3", policy.Coverage.SensitiveFiles)
+	}
+	for _, f := range policy.Coverage.SensitiveFiles {
+		if f.Watched != !strings.Contains(f.Path, ".ssh") {
+			t.Errorf("SensitiveFile %s Watched = %v", f.Path, f.Watched)
+		}
+	}
+	if len(policy.Warnings) != 2 {
+		t.Errorf("Warnings = %v, want shell and unwatched .ssh access", policy.Warnings)
+	}
+	if policy.SelfCheck == nil || !policy.SelfCheck.Passed {
+		t.Errorf("SelfCheck = %+v, want passed\n%s", policy.SelfCheck, policy.PolicyContent)
+	}
+
+	for _, want := range []string{"kind: TracingPolicyNamespaced", "namespace: prod", "app: api", "action: Post", "security_bprm_check", "/usr/bin/api", "security_file_open", "/etc/shadow"} {
+		if !strings.Contains(policy.PolicyContent, want) {
+			t.Errorf("PolicyContent missing %q:\n%s", want, policy.PolicyContent)
+		}
+	}
+	for _, unwanted := range []string{"pod-template-hash", "/usr/bin/nc", "/bin/bash", "python3", "Sigkill"} {
+		if strings.Contains(policy.PolicyContent, unwanted) {
+			t.Errorf("PolicyContent contains %q:\n%s", unwanted, policy.PolicyContent)
+		}
+	}
+
+	// Enforced, the allowlist kills what the baseline did not cover
+	enforced, err := recommender.RecommendTracingPolicies(context.Background(), &TracingPolicyRequest{
+		Namespace:   "prod",
+		PodSelector: map[string]string{"app": "api"},
+		StartTime:   now.Add(-1 * time.Hour),
+		EndTime:     baselineEnd,
+		Mode:        "Enforce",
+	})
+	if err != nil {
+		t.Fatalf("RecommendTracingPolicies() error = %v", err)
+	}
+	if len(enforced.Policies) != 1 || !strings.Contains(enforced.Policies[0].PolicyContent, "action: Sigkill") {
+		t.Fatalf("Policies = %+v, want one enforcing policy", enforced.Policies)
+	}
+	sim, err := simulation.NewEngine(simulation.EngineConfig{StorageManager: mgr, Logger: logr.Discard()}).Simulate(
+		context.Background(), &simulation.SimulationRequest{
+			PolicyContent: enforced.Policies[0].PolicyContent,
+			PolicyType:    "TETRAGON",
+			StartTime:     now.Add(-1 * time.Hour),
+			EndTime:       now,
+		})
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	// curl outside the baseline and the /etc/shadow read nobody made before
+	if sim.BreakdownByVerdict.AllowedToDenied != 2 {
+		t.Errorf("AllowedToDenied = %d, want 2", sim.BreakdownByVerdict.AllowedToDenied)
+	}
+}
+
+func TestRecommender_RecommendTracingPolicies_InvalidRequest(t *testing.T) {
+	recommender := NewRecommender(RecommenderConfig{StorageManager: writeEvents(t, nil), Logger: logr.Discard()})
+
+	tests := []struct {
+		name string
+		req  *TracingPolicyRequest
+	}{
+		{name: "missing namespace", req: &TracingPolicyRequest{}},
+		{name: "unknown mode", req: &TracingPolicyRequest{Namespace: "prod", Mode: "block"}},
+		{name: "no process events", req: &TracingPolicyRequest{Namespace: "prod", StartTime: time.Now().Add(-time.Hour), EndTime: time.Now()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := recommender.RecommendTracingPolicies(context.Background(), tt.req); err == nil {
+				t.Error("RecommendTracingPolicies() expected error")
+			}
+		})
+	}
+}
+
+func TestRecommender_RecommendTracingPolicies_Cancelled(t *testing.T) {
+	now := time.Now().UTC()
+	mgr := writeEvents(t, []*models.TelemetryEvent{{
+		ID: "1", EventType: models.EventTypeProcessExec, Timestamp: now.Add(-10 * time.Minute), NodeName: "test-node",
+		SrcNamespace: "prod", SrcPodName: "api-1", SrcPodLabels: map[string]string{"app": "api"},
+		SrcBinary: "/usr/bin/api", Verdict: models.VerdictAllowed,
+	}})
+	recommender := NewRecommender(RecommenderConfig{StorageManager: mgr, Logger: logr.Discard()})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := recommender.RecommendTracingPolicies(ctx, &TracingPolicyRequest{
+		Namespace: "prod",
+		StartTime: now.Add(-1 * time.Hour),
+		EndTime:   now,
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("RecommendTracingPolicies() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,153 @@
+// Package recommendation generates least-privilege policies from historical telemetry data.
+package recommendation
+
+import (
+	"time"
+
+	"github.com/policy-hub/operator/internal/telemetry/simulation"
+)
+
+// RecommendationRequest selects the workload and the training window to
+// generate a policy from.
+type RecommendationRequest struct {
+	// Namespace of the workload (required)
+	Namespace string `json:"namespace"`
+	// PodSelector selects the workload's pods (empty = all pods in the namespace)
+	PodSelector map[string]string `json:"podSelector,omitempty"`
+	// TimeRange is the training window
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	// PolicyName is the name of the generated policy (optional)
+	PolicyName string `json:"policyName,omitempty"`
+
+	// Progress is called as the stored flows are read (optional)
+	Progress func(simulation.SimulationProgress) `json:"-"`
+}
+
+// RecommendationResponse contains a generated policy and its self-check.
+type RecommendationResponse struct {
+	// PolicyContent is the generated CiliumNetworkPolicy as YAML
+	PolicyContent string `json:"policyContent"`
+	// PolicyType is the simulation policy type of the generated policy
+	PolicyType string `json:"policyType"`
+	PolicyName string `json:"policyName"`
+	Namespace  string `json:"namespace"`
+
+	// FlowsAnalyzed is the number of flows of the workload the policy was
+	// generated from
+	FlowsAnalyzed int64 `json:"flowsAnalyzed"`
+	// Rules describes the generated rules
+	Rules []*RecommendedRule `json:"rules,omitempty"`
+	// SelfCheck is the simulation of the policy against the training window
+	SelfCheck *SelfCheckResult `json:"selfCheck,omitempty"`
+	// Warnings about parts of the traffic the policy could not capture precisely
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Metadata
+	GeneratedAt time.Time     `json:"generatedAt"`
+	Duration    time.Duration `json:"duration"`
+}
+
+// RecommendedRule summarizes one generated ingress or egress rule.
+type RecommendedRule struct {
+	Direction string   `json:"direction"` // ingress or egress
+	Peers     []string `json:"peers"`
+	Ports     []string `json:"ports,omitempty"`
+	FlowCount int64    `json:"flowCount"`
+}
+
+// SelfCheckResult is the outcome of simulating the generated policy against
+// the window it was trained on. A sound policy denies none of the allowed
+// traffic it was generated from.
+type SelfCheckResult struct {
+	TotalFlowsAnalyzed int64    `json:"totalFlowsAnalyzed"`
+	WouldDeny          int64    `json:"wouldDeny"`
+	Passed             bool     `json:"passed"`
+	Errors             []string `json:"errors,omitempty"`
+}
+
+// Tracing policy modes.
+const (
+	// TracingModeAudit only reports executions and file accesses outside the
+	// baseline
+	TracingModeAudit = "audit"
+	// TracingModeEnforce kills processes that leave the baseline
+	TracingModeEnforce = "enforce"
+)
+
+// TracingPolicyRequest selects the workloads and the baseline window to
+// generate Tetragon TracingPolicies from.
+type TracingPolicyRequest struct {
+	// Namespace of the workloads (required)
+	Namespace string `json:"namespace"`
+	// PodSelector restricts the pods considered (empty = all pods in the namespace)
+	PodSelector map[string]string `json:"podSelector,omitempty"`
+	// TimeRange is the baseline window
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	// Mode is audit (default) or enforce
+	Mode string `json:"mode,omitempty"`
+
+	// Progress is called as the stored process events are read (optional)
+	Progress func(simulation.SimulationProgress) `json:"-"`
+}
+
+// TracingPolicyResponse contains one generated TracingPolicy per workload.
+type TracingPolicyResponse struct {
+	Policies []*RecommendedTracingPolicy `json:"policies"`
+	// EventsAnalyzed is the number of process events the baselines were built from
+	EventsAnalyzed int64 `json:"eventsAnalyzed"`
+	// Warnings about pods that could not be covered
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Metadata
+	GeneratedAt time.Time     `json:"generatedAt"`
+	Duration    time.Duration `json:"duration"`
+}
+
+// RecommendedTracingPolicy is an allowlist TracingPolicyNamespaced for a
+// single workload and the report of what its baseline covered.
+type RecommendedTracingPolicy struct {
+	// PolicyContent is the generated TracingPolicyNamespaced as YAML
+	PolicyContent string            `json:"policyContent"`
+	PolicyType    string            `json:"policyType"`
+	PolicyName    string            `json:"policyName"`
+	Namespace     string            `json:"namespace"`
+	PodSelector   map[string]string `json:"podSelector"`
+	Mode          string            `json:"mode"`
+
+	Coverage *BaselineCoverage `json:"coverage"`
+	// SelfCheck simulates the enforcing variant of the policy against the
+	// baseline window; WouldDeny counts baseline events it would kill
+	SelfCheck *SelfCheckResult `json:"selfCheck,omitempty"`
+	Warnings  []string         `json:"warnings,omitempty"`
+}
+
+// BaselineCoverage reports what the process baseline of a workload covered.
+type BaselineCoverage struct {
+	Pods       []string  `json:"pods"`
+	FirstSeen  time.Time `json:"firstSeen"`
+	LastSeen   time.Time `json:"lastSeen"`
+	ExecEvents int64     `json:"execEvents"`
+	// Binaries are the permitted binaries and how often they were executed
+	Binaries []BinaryCoverage `json:"binaries"`
+	// SensitiveFiles are the observed sensitive file accesses
+	SensitiveFiles []SensitiveFileCoverage `json:"sensitiveFiles,omitempty"`
+}
+
+// BinaryCoverage is a permitted binary of a baseline.
+type BinaryCoverage struct {
+	Binary   string `json:"binary"`
+	Category string `json:"category"`
+	Count    int64  `json:"count"`
+}
+
+// SensitiveFileCoverage is a sensitive file access of a baseline.
+type SensitiveFileCoverage struct {
+	Binary string `json:"binary"`
+	Path   string `json:"path"`
+	Count  int64  `json:"count"`
+	// Watched is false when the generated policy's path prefixes do not
+	// cover the path
+	Watched bool `json:"watched"`
+}
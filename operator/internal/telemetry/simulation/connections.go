@@ -0,0 +1,226 @@
+package simulation
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+)
+
+const (
+	// maxTrackedConnections bounds the number of distinct connections held
+	// while aggregating. Changed flows of further connections are only
+	// counted in UntrackedFlows.
+	maxTrackedConnections = 10000
+
+	// maxConnectionSamples is the number of sample flows kept per connection.
+	maxConnectionSamples = 3
+)
+
+// workloadLabels are the labels that name a workload, in order of preference.
+var workloadLabels = []string{"app.kubernetes.io/name", "app", "k8s-app", "name"}
+
+// Generated name suffixes use Kubernetes' alphabet without vowels, so they
+// do not eat words of the workload name like "-service".
+var (
+	// podHashSuffix matches the random suffix of pods created by a
+	// ReplicaSet, DaemonSet or Job.
+	podHashSuffix = regexp.MustCompile(`-[bcdfghjklmnpqrstvwxz2456789]{5}$`)
+	// replicaSetHashSuffix matches the pod template hash of a ReplicaSet.
+	replicaSetHashSuffix = regexp.MustCompile(`-[bcdfghjklmnpqrstvwxz2456789]{6,10}$`)
+	// statefulSetOrdinal matches the ordinal of a StatefulSet pod.
+	statefulSetOrdinal = regexp.MustCompile(`-[0-9]+$`)
+)
+
+// addConnection aggregates a flow whose verdict changes into the connection
+// it belongs to.
+func addConnection(response *SimulationResponse, event *models.TelemetryEvent, result *FlowSimulationResult) {
+	if response.connections == nil {
+		response.connections = make(map[string]*ConnectionImpact)
+	}
+
+	conn := &ConnectionImpact{
+		SrcNamespace:     event.SrcNamespace,
+		SrcWorkload:      workloadName(event.SrcPodName, event.SrcPodLabels, event.SrcIP, ""),
+		DstNamespace:     event.DstNamespace,
+		DstWorkload:      workloadName(event.DstPodName, event.DstPodLabels, event.DstIP, event.DstDNSName),
+		DstPort:          event.DstPort,
+		Protocol:         event.Protocol,
+		L7:               l7Signature(event),
+		OriginalVerdict:  result.OriginalVerdict,
+		SimulatedVerdict: result.SimulatedVerdict,
+	}
+	key := conn.key()
+
+	existing, ok := response.connections[key]
+	if !ok {
+		if len(response.connections) >= maxTrackedConnections {
+			response.UntrackedFlows++
+			return
+		}
+		conn.MatchedRule = result.MatchedRule
+		conn.MatchReason = result.MatchReason
+		conn.MatchedPolicy = result.MatchedPolicy
+		conn.FirstSeen = result.Timestamp
+		conn.LastSeen = result.Timestamp
+		response.connections[key] = conn
+		existing = conn
+	}
+
+	existing.Flows++
+	if result.Timestamp.Before(existing.FirstSeen) {
+		existing.FirstSeen = result.Timestamp
+	}
+	if result.Timestamp.After(existing.LastSeen) {
+		existing.LastSeen = result.Timestamp
+	}
+	if len(existing.Samples) < maxConnectionSamples {
+		existing.Samples = append(existing.Samples, result)
+	}
+}
+
+// mergeConnections adds the connections of src to dst. Keys are visited in
+// order so the connections dropped at the limit do not depend on map order.
+func mergeConnections(dst, src *SimulationResponse) {
+	dst.UntrackedFlows += src.UntrackedFlows
+	if len(src.connections) == 0 {
+		return
+	}
+	if dst.connections == nil {
+		dst.connections = make(map[string]*ConnectionImpact)
+	}
+
+	keys := make([]string, 0, len(src.connections))
+	for key := range src.connections {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		conn := src.connections[key]
+		existing, ok := dst.connections[key]
+		if !ok {
+			if len(dst.connections) >= maxTrackedConnections {
+				dst.UntrackedFlows += conn.Flows
+				continue
+			}
+			dst.connections[key] = conn
+			continue
+		}
+		existing.Flows += conn.Flows
+		if conn.FirstSeen.Before(existing.FirstSeen) {
+			existing.FirstSeen = conn.FirstSeen
+		}
+		if conn.LastSeen.After(existing.LastSeen) {
+			existing.LastSeen = conn.LastSeen
+		}
+		existing.Samples = appendDetails(existing.Samples, conn.Samples, maxConnectionSamples)
+	}
+}
+
+// finishConnections sorts the aggregated connections by impact into the
+// response, keeping at most limit of them.
+func finishConnections(response *SimulationResponse, limit int) {
+	if response.connections == nil {
+		return
+	}
+
+	connections := make([]*ConnectionImpact, 0, len(response.connections))
+	for _, conn := range response.connections {
+		connections = append(connections, conn)
+	}
+	sortConnections(connections)
+
+	response.TotalConnections = int64(len(connections))
+	if len(connections) > limit {
+		connections = connections[:limit]
+	}
+	response.Connections = connections
+	response.connections = nil
+}
+
+// sortConnections orders connections by impact: connections that would be
+// denied first, then newly allowed ones, each by decreasing flow count. Ties
+// are broken by key so the order is stable across runs.
+func sortConnections(connections []*ConnectionImpact) {
+	sort.Slice(connections, func(i, j int) bool {
+		a, b := connections[i], connections[j]
+		if ra, rb := a.impactRank(), b.impactRank(); ra != rb {
+			return ra < rb
+		}
+		if a.Flows != b.Flows {
+			return a.Flows > b.Flows
+		}
+		return a.key() < b.key()
+	})
+}
+
+// impactRank ranks a connection's verdict change: 0 for connections that
+// would break, 1 for newly allowed ones and 2 for anything else.
+func (c *ConnectionImpact) impactRank() int {
+	switch {
+	case c.OriginalVerdict == "ALLOWED" && c.SimulatedVerdict == "DENIED":
+		return 0
+	case c.OriginalVerdict != "ALLOWED" && c.SimulatedVerdict == "ALLOWED":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// key identifies a connection and its verdict change.
+func (c *ConnectionImpact) key() string {
+	return strings.Join([]string{
+		c.SrcNamespace, c.SrcWorkload,
+		c.DstNamespace, c.DstWorkload,
+		fmt.Sprint(c.DstPort), c.Protocol, c.L7,
+		c.OriginalVerdict, c.SimulatedVerdict,
+	}, "|")
+}
+
+// workloadName names the workload of an endpoint after its identity label, or
+// its pod name without the suffixes Kubernetes generates. Endpoints outside
+// the cluster are named after their DNS name or IP.
+func workloadName(podName string, labels map[string]string, ip, dnsName string) string {
+	for _, key := range workloadLabels {
+		if value, ok := getLabelValue(labels, key); ok && value != "" {
+			return value
+		}
+	}
+	if podName != "" {
+		if podHashSuffix.MatchString(podName) {
+			trimmed := podHashSuffix.ReplaceAllString(podName, "")
+			return replicaSetHashSuffix.ReplaceAllString(trimmed, "")
+		}
+		return statefulSetOrdinal.ReplaceAllString(podName, "")
+	}
+	if dnsName != "" {
+		return strings.TrimSuffix(dnsName, ".")
+	}
+	if ip != "" {
+		return ip
+	}
+	return "unknown"
+}
+
+// l7Signature describes the L7 request of a flow, or returns "" for flows
+// without L7 details. Query strings are dropped from HTTP paths.
+func l7Signature(event *models.TelemetryEvent) string {
+	switch l7Protocol(event) {
+	case "http":
+		_, path, method := l7HTTPRequest(event)
+		path, _, _ = strings.Cut(path, "?")
+		return strings.TrimSpace("HTTP " + method + " " + path)
+	case "grpc":
+		_, path, _ := l7HTTPRequest(event)
+		return "gRPC " + path
+	case "dns":
+		return "DNS " + strings.TrimSuffix(strings.ToLower(event.DNSQuery), ".")
+	case "kafka":
+		return strings.TrimSpace("Kafka " + event.KafkaAPIKey + " " + event.KafkaTopic)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,136 @@
+package simulation
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+)
+
+func TestWorkloadName(t *testing.T) {
+	tests := []struct {
+		name    string
+		podName string
+		labels  map[string]string
+		ip      string
+		dnsName string
+		want    string
+	}{
+		{name: "identity label", podName: "api-7d9c8b6f5-x2kq9", labels: map[string]string{"app": "api"}, want: "api"},
+		{name: "preferred label", labels: map[string]string{"app": "api", "app.kubernetes.io/name": "orders"}, want: "orders"},
+		{name: "cilium prefixed label", labels: map[string]string{"k8s:app": "api"}, want: "api"},
+		{name: "deployment pod", podName: "api-7d9c8b6f5-x2kq9", want: "api"},
+		{name: "daemonset pod", podName: "fluent-bit-x2kq9", want: "fluent-bit"},
+		{name: "deployment with word suffix", podName: "order-service-x2kq9", want: "order-service"},
+		{name: "statefulset pod", podName: "postgres-0", want: "postgres"},
+		{name: "dns name", ip: "203.0.113.10", dnsName: "api.example.com.", want: "api.example.com"},
+		{name: "ip", ip: "203.0.113.10", want: "203.0.113.10"},
+		{name: "nothing known", want: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := workloadName(tt.podName, tt.labels, tt.ip, tt.dnsName); got != tt.want {
+				t.Errorf("workloadName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestL7Signature(t *testing.T) {
+	tests := []struct {
+		name  string
+		event *models.TelemetryEvent
+		want  string
+	}{
+		{name: "l4 flow", event: &models.TelemetryEvent{}, want: ""},
+		{
+			name:  "http drops query string",
+			event: &models.TelemetryEvent{HTTPMethod: "GET", HTTPPath: "http://api:8080/users?id=42"},
+			want:  "HTTP GET /users",
+		},
+		{
+			name:  "grpc",
+			event: &models.TelemetryEvent{GRPCService: "orders.v1.Orders", GRPCMethod: "Get"},
+			want:  "gRPC /orders.v1.Orders/Get",
+		},
+		{name: "dns", event: &models.TelemetryEvent{DNSQuery: "API.example.com."}, want: "DNS api.example.com"},
+		{name: "kafka", event: &models.TelemetryEvent{KafkaAPIKey: "produce", KafkaTopic: "orders"}, want: "Kafka produce orders"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := l7Signature(tt.event); got != tt.want {
+				t.Errorf("l7Signature() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddConnection(t *testing.T) {
+	now := time.Now()
+	flow := func(i int, srcApp string, dstPort uint32) (*models.TelemetryEvent, *FlowSimulationResult) {
+		event := &models.TelemetryEvent{
+			Timestamp:    now.Add(time.Duration(i) * time.Second),
+			SrcNamespace: "default",
+			SrcPodName:   fmt.Sprintf("%s-%d", srcApp, i),
+			SrcPodLabels: map[string]string{"app": srcApp},
+			DstNamespace: "default",
+			DstPodLabels: map[string]string{"app": "backend"},
+			DstPort:      dstPort,
+			Protocol:     "TCP",
+		}
+		result := &FlowSimulationResult{
+			Timestamp:        event.Timestamp,
+			SrcPodName:       event.SrcPodName,
+			OriginalVerdict:  "ALLOWED",
+			SimulatedVerdict: "DENIED",
+			VerdictChanged:   true,
+		}
+		return event, result
+	}
+
+	add := func(response *SimulationResponse, i int, srcApp string) {
+		event, result := flow(i, srcApp, 8080)
+		addConnection(response, event, result)
+	}
+
+	first := &SimulationResponse{}
+	second := &SimulationResponse{}
+	for i := 0; i < 5; i++ {
+		add(first, i, "frontend")
+	}
+	add(second, 5, "frontend")
+	for i := 6; i < 8; i++ {
+		add(second, i, "reports")
+	}
+	// A newly allowed connection ranks below the ones that would break
+	event, result := flow(8, "monitoring", 9090)
+	result.OriginalVerdict, result.SimulatedVerdict = "DENIED", "ALLOWED"
+	addConnection(second, event, result)
+
+	mergeConnections(first, second)
+	finishConnections(first, 2)
+
+	if first.TotalConnections != 3 {
+		t.Errorf("TotalConnections = %d, want 3", first.TotalConnections)
+	}
+	if len(first.Connections) != 2 {
+		t.Fatalf("len(Connections) = %d, want the limit of 2", len(first.Connections))
+	}
+
+	top := first.Connections[0]
+	if top.SrcWorkload != "frontend" || top.DstWorkload != "backend" || top.Flows != 6 {
+		t.Errorf("Connections[0] = %+v, want frontend -> backend with 6 flows", top)
+	}
+	if !top.FirstSeen.Equal(now) || !top.LastSeen.Equal(now.Add(5*time.Second)) {
+		t.Errorf("FirstSeen, LastSeen = %v, %v", top.FirstSeen, top.LastSeen)
+	}
+	if len(top.Samples) != maxConnectionSamples || top.Samples[0].SrcPodName != "frontend-0" {
+		t.Errorf("Samples = %d, want the first %d flows", len(top.Samples), maxConnectionSamples)
+	}
+	if first.Connections[1].SrcWorkload != "reports" {
+		t.Errorf("Connections[1] = %+v, want reports", first.Connections[1])
+	}
+}
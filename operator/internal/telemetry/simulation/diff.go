@@ -0,0 +1,158 @@
+package simulation
+
+import (
+	"context"
+	"time"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+)
+
+// SimulateDiff evaluates historical flows against two versions of a network
+// policy and reports the flows whose simulated verdict differs between them.
+//
+// The response uses the same shape as Simulate, with OriginalVerdict holding
+// the verdict under the base version and SimulatedVerdict the verdict under
+// the target version. Flows that neither version applies to keep their
+// recorded verdict on both sides and never differ. VerdictDiffs holds the
+// differing flows with the result of each version, including the rule that
+// decided it, and BreakdownByRuleChange counts them per pair of rules.
+func (e *Engine) SimulateDiff(ctx context.Context, req *PolicyDiffRequest) (*SimulationResponse, error) {
+	startTime := time.Now()
+
+	failed := func(errs ...string) *SimulationResponse {
+		return &SimulationResponse{
+			Errors:         errs,
+			SimulationTime: startTime,
+			Duration:       time.Since(startTime),
+		}
+	}
+
+	policyType := req.PolicyType
+	if policyType == "" {
+		policyType = "CILIUM_NETWORK"
+	}
+	if policyType != "CILIUM_NETWORK" && policyType != "CILIUM_CLUSTERWIDE" {
+		return failed("Policy diffs are only supported for Cilium network policies, got " + policyType), nil
+	}
+
+	e.log.Info("Starting policy diff simulation",
+		"policyType", policyType,
+		"baseVersion", req.BaseVersion,
+		"targetVersion", req.TargetVersion,
+		"startTime", req.StartTime,
+		"endTime", req.EndTime,
+	)
+
+	base, err := e.parser.Parse(req.BaseContent, policyType)
+	if err != nil {
+		return failed("Base policy: " + err.Error()), nil
+	}
+	target, err := e.parser.Parse(req.TargetContent, policyType)
+	if err != nil {
+		return failed("Target policy: " + err.Error()), nil
+	}
+
+	queryReq := models.QueryEventsRequest{
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+		EventTypes: []string{string(models.EventTypeFlow)},
+		Limit:      0, // Get all matching events
+	}
+	maxDetails := int(req.MaxDetails)
+	if maxDetails == 0 {
+		maxDetails = 100 // Default limit
+	}
+
+	newPartial := func() *SimulationResponse {
+		return &SimulationResponse{
+			BreakdownByNamespace:  make(map[string]*NamespaceImpact),
+			BreakdownByVerdict:    &VerdictBreakdown{},
+			BreakdownByRuleChange: make(map[string]*RuleChangeImpact),
+			VerdictDiffs:          []*FlowVerdictDiff{},
+		}
+	}
+
+	response, err := e.streamEvaluate(ctx, queryReq, req.Progress, maxDetails, startTime, newPartial, func(partial *SimulationResponse, event *models.TelemetryEvent) {
+		baseResult := e.evaluateFlow(event, base)
+		targetResult := e.evaluateFlow(event, target)
+		flowResult := diffFlowResult(baseResult, targetResult)
+		partial.TotalFlowsAnalyzed++
+
+		switch flowResult.SimulatedVerdict {
+		case "ALLOWED":
+			partial.AllowedCount++
+		case "DENIED":
+			partial.DeniedCount++
+		}
+		if flowResult.VerdictChanged {
+			partial.WouldChangeCount++
+			addConnection(partial, event, flowResult)
+			updateRuleChangeBreakdown(partial.BreakdownByRuleChange, baseResult, targetResult)
+			if len(partial.VerdictDiffs) < maxDetails {
+				partial.VerdictDiffs = append(partial.VerdictDiffs, &FlowVerdictDiff{
+					Base:   baseResult,
+					Target: targetResult,
+				})
+			}
+		} else {
+			partial.NoChangeCount++
+		}
+
+		e.updateVerdictBreakdown(partial.BreakdownByVerdict, flowResult)
+		e.updateNamespaceBreakdown(partial.BreakdownByNamespace, event, flowResult)
+	})
+	if err != nil || len(response.Errors) > 0 {
+		return response, err
+	}
+
+	e.log.Info("Policy diff simulation complete",
+		"baseVersion", req.BaseVersion,
+		"targetVersion", req.TargetVersion,
+		"totalFlows", response.TotalFlowsAnalyzed,
+		"wouldChange", response.WouldChangeCount,
+		"duration", response.Duration,
+	)
+
+	return response, nil
+}
+
+// diffFlowResult combines the results of a flow under two policy versions
+// into one result going from the base verdict to the target verdict.
+func diffFlowResult(base, target *FlowSimulationResult) *FlowSimulationResult {
+	result := *target
+	result.OriginalVerdict = base.SimulatedVerdict
+	result.VerdictChanged = base.SimulatedVerdict != target.SimulatedVerdict
+	return &result
+}
+
+// updateRuleChangeBreakdown counts a differing flow against the pair of rules
+// that decided it under each version.
+func updateRuleChangeBreakdown(breakdown map[string]*RuleChangeImpact, base, target *FlowSimulationResult) {
+	baseRule := decidingRule(base)
+	targetRule := decidingRule(target)
+	key := baseRule + " -> " + targetRule
+
+	impact, ok := breakdown[key]
+	if !ok {
+		impact = &RuleChangeImpact{BaseRule: baseRule, TargetRule: targetRule}
+		breakdown[key] = impact
+	}
+
+	impact.Flows++
+	switch target.SimulatedVerdict {
+	case "ALLOWED":
+		impact.NewlyAllowed++
+	case "DENIED":
+		impact.NewlyDenied++
+	}
+}
+
+// decidingRule describes what decided a flow's verdict: the matched rule, or
+// the match reason in parentheses for default denies and flows the policy
+// does not apply to.
+func decidingRule(result *FlowSimulationResult) string {
+	if result.MatchedRule != "" {
+		return result.MatchedRule
+	}
+	return "(" + result.MatchReason + ")"
+}
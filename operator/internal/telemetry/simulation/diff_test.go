@@ -0,0 +1,190 @@
+package simulation
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+	"github.com/policy-hub/operator/internal/telemetry/storage"
+)
+
+func TestEngine_SimulateDiff(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "engine-diff-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cfg := storage.ManagerConfig{
+		BasePath: tmpDir,
+		NodeName: "test-node",
+		Logger:   logr.Discard(),
+	}
+	writer, err := storage.NewManager(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+
+	now := time.Now().UTC()
+	flow := func(id, srcApp, dstApp string, port uint32) *models.TelemetryEvent {
+		return &models.TelemetryEvent{
+			ID:           id,
+			Timestamp:    now.Add(-10 * time.Minute),
+			EventType:    models.EventTypeFlow,
+			NodeName:     "test-node",
+			SrcNamespace: "default",
+			SrcPodName:   id,
+			SrcPodLabels: map[string]string{"app": srcApp},
+			DstNamespace: "default",
+			DstPodLabels: map[string]string{"app": dstApp},
+			DstPort:      port,
+			Protocol:     "TCP",
+			Direction:    "ingress",
+			Verdict:      models.VerdictAllowed,
+		}
+	}
+	events := []*models.TelemetryEvent{
+		flow("frontend-http", "frontend", "backend", 8080),        // allowed by both versions
+		flow("frontend-metrics", "frontend", "backend", 9090),     // v4 restricts frontend to 8080
+		flow("monitoring-metrics", "monitoring", "backend", 9090), // v4 adds a monitoring rule
+		flow("frontend-db", "frontend", "database", 5432),         // neither version applies
+	}
+	if err := writer.Write(events); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	mgr, err := storage.NewManager(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+	defer mgr.Close()
+
+	targetPolicy := `
+apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: backend-ingress
+  namespace: default
+spec:
+  endpointSelector:
+    matchLabels:
+      app: backend
+  ingress:
+    - fromEndpoints:
+        - matchLabels:
+            app: frontend
+      toPorts:
+        - ports:
+            - port: "8080"
+              protocol: TCP
+    - fromEndpoints:
+        - matchLabels:
+            app: monitoring
+      toPorts:
+        - ports:
+            - port: "9090"
+              protocol: TCP
+`
+
+	engine := NewEngine(EngineConfig{StorageManager: mgr, Logger: logr.Discard()})
+	resp, err := engine.SimulateDiff(context.Background(), &PolicyDiffRequest{
+		BaseContent:   backendIngressPolicy,
+		TargetContent: targetPolicy,
+		BaseVersion:   3,
+		TargetVersion: 4,
+		StartTime:     now.Add(-1 * time.Hour),
+		EndTime:       now,
+	})
+	if err != nil {
+		t.Fatalf("SimulateDiff() error = %v", err)
+	}
+	if len(resp.Errors) > 0 {
+		t.Fatalf("SimulateDiff() errors = %v", resp.Errors)
+	}
+
+	if resp.TotalFlowsAnalyzed != 4 || resp.WouldChangeCount != 2 || resp.NoChangeCount != 2 {
+		t.Errorf("total = %d, wouldChange = %d, noChange = %d, want 4, 2, 2",
+			resp.TotalFlowsAnalyzed, resp.WouldChangeCount, resp.NoChangeCount)
+	}
+	if resp.BreakdownByVerdict.AllowedToDenied != 1 || resp.BreakdownByVerdict.DeniedToAllowed != 1 {
+		t.Errorf("BreakdownByVerdict = %+v, want one flow each way", resp.BreakdownByVerdict)
+	}
+
+	// Only the differing flows are returned, each with both sides
+	if len(resp.VerdictDiffs) != 2 {
+		t.Fatalf("len(VerdictDiffs) = %d, want 2", len(resp.VerdictDiffs))
+	}
+	diffs := make(map[string]*FlowVerdictDiff)
+	for _, diff := range resp.VerdictDiffs {
+		diffs[diff.Target.SrcPodName] = diff
+	}
+
+	restricted := diffs["frontend-metrics"]
+	if restricted == nil {
+		t.Fatal("VerdictDiffs missing frontend-metrics")
+	}
+	if restricted.Base.SimulatedVerdict != "ALLOWED" || restricted.Base.MatchedRule == "" {
+		t.Errorf("Base = %+v, want ALLOWED by a rule", restricted.Base)
+	}
+	if restricted.Target.SimulatedVerdict != "DENIED" || restricted.Target.MatchedRule != "" {
+		t.Errorf("Target = %+v, want DENIED by default", restricted.Target)
+	}
+
+	added := diffs["monitoring-metrics"]
+	if added == nil {
+		t.Fatal("VerdictDiffs missing monitoring-metrics")
+	}
+	if added.Base.SimulatedVerdict != "DENIED" || added.Base.MatchedRule != "" {
+		t.Errorf("Base = %+v, want DENIED by default", added.Base)
+	}
+	if added.Target.SimulatedVerdict != "ALLOWED" || !strings.HasSuffix(added.Target.MatchedRule, "TCP/9090") {
+		t.Errorf("Target = %+v, want ALLOWED by the port 9090 rule", added.Target)
+	}
+
+	if len(resp.BreakdownByRuleChange) != 2 {
+		t.Fatalf("BreakdownByRuleChange = %v, want 2 entries", resp.BreakdownByRuleChange)
+	}
+	for _, impact := range resp.BreakdownByRuleChange {
+		if impact.Flows != 1 || impact.NewlyAllowed+impact.NewlyDenied != 1 {
+			t.Errorf("RuleChangeImpact = %+v, want one changed flow", impact)
+		}
+		if impact.TargetRule == added.Target.MatchedRule && impact.BaseRule != "(No matching ingress rule, default deny)" {
+			t.Errorf("BaseRule = %q, want the default deny reason", impact.BaseRule)
+		}
+	}
+
+	t.Run("unsupported policy type", func(t *testing.T) {
+		resp, err := engine.SimulateDiff(context.Background(), &PolicyDiffRequest{
+			PolicyType:    "TETRAGON",
+			BaseContent:   backendIngressPolicy,
+			TargetContent: targetPolicy,
+		})
+		if err != nil {
+			t.Fatalf("SimulateDiff() error = %v", err)
+		}
+		if len(resp.Errors) == 0 {
+			t.Error("SimulateDiff() returned no errors for a Tetragon policy")
+		}
+	})
+
+	t.Run("invalid target", func(t *testing.T) {
+		resp, err := engine.SimulateDiff(context.Background(), &PolicyDiffRequest{
+			BaseContent:   backendIngressPolicy,
+			TargetContent: "not: [valid",
+		})
+		if err != nil {
+			t.Fatalf("SimulateDiff() error = %v", err)
+		}
+		if len(resp.Errors) != 1 || !strings.HasPrefix(resp.Errors[0], "Target policy") {
+			t.Errorf("Errors = %v, want a target policy error", resp.Errors)
+		}
+	})
+}
@@ -3,7 +3,10 @@ package simulation
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/url"
 	"regexp"
+	"runtime"
 	"strings"
 	"time"
 
@@ -15,23 +18,36 @@ import (
 
 // Engine evaluates policies against historical telemetry data.
 type Engine struct {
-	storageMgr *storage.Manager
-	parser     *PolicyParser
-	log        logr.Logger
+	storageMgr   *storage.Manager
+	policySource PolicySetSource
+	parser       *PolicyParser
+	parallelism  int
+	log          logr.Logger
 }
 
 // EngineConfig contains configuration for the simulation engine.
 type EngineConfig struct {
 	StorageManager *storage.Manager
-	Logger         logr.Logger
+	// PolicySource loads the deployed policy set for policy set simulations (optional)
+	PolicySource PolicySetSource
+	// Parallelism is the number of storage files evaluated concurrently
+	// (default: the number of CPUs, at most 4)
+	Parallelism int
+	Logger      logr.Logger
 }
 
 // NewEngine creates a new simulation engine.
 func NewEngine(cfg EngineConfig) *Engine {
+	if cfg.Parallelism <= 0 {
+		cfg.Parallelism = min(runtime.NumCPU(), 4)
+	}
+
 	return &Engine{
-		storageMgr: cfg.StorageManager,
-		parser:     NewPolicyParser(),
-		log:        cfg.Logger.WithName("simulation-engine"),
+		storageMgr:   cfg.StorageManager,
+		policySource: cfg.PolicySource,
+		parser:       NewPolicyParser(),
+		parallelism:  cfg.Parallelism,
+		log:          cfg.Logger.WithName("simulation-engine"),
 	}
 }
 
@@ -56,6 +72,15 @@ func (e *Engine) Simulate(ctx context.Context, req *SimulationRequest) (*Simulat
 		}, nil
 	}
 
+	// Tetragon policies are evaluated against process events instead of flows,
+	// Gateway routes against L7 requests
+	switch req.PolicyType {
+	case "TETRAGON":
+		return e.simulateTracingPolicy(ctx, req, policy, startTime)
+	case "GATEWAY_HTTPROUTE", "GATEWAY_GRPCROUTE":
+		return e.simulateRoute(ctx, req, policy, startTime)
+	}
+
 	// Query historical flows
 	// NOTE: We don't filter by namespace at the storage level because:
 	// 1. Historical data may have empty namespace fields (Hubble GetNamespace() limitation)
@@ -75,64 +100,54 @@ func (e *Engine) Simulate(ctx context.Context, req *SimulationRequest) (*Simulat
 		"targetNamespaces", req.Namespaces, // Log target namespaces for debugging
 	)
 
-	result, err := e.storageMgr.Query(ctx, queryReq)
-	if err != nil {
-		e.log.Error(err, "Storage query failed")
-		return &SimulationResponse{
-			Errors:         []string{"Failed to query historical data: " + err.Error()},
-			SimulationTime: startTime,
-			Duration:       time.Since(startTime),
-		}, nil
-	}
-
-	e.log.Info("Storage query completed", "eventCount", len(result.Events))
-
-	// Initialize response
-	response := &SimulationResponse{
-		TotalFlowsAnalyzed:   int64(len(result.Events)),
-		BreakdownByNamespace: make(map[string]*NamespaceImpact),
-		BreakdownByVerdict:   &VerdictBreakdown{},
-		Details:              []*FlowSimulationResult{},
-		SimulationTime:       startTime,
-	}
-
 	// Evaluate each flow against the policy
 	maxDetails := int(req.MaxDetails)
 	if maxDetails == 0 {
 		maxDetails = 100 // Default limit
 	}
 
-	for _, event := range result.Events {
-		flowResult := e.evaluateFlow(&event, policy)
+	newPartial := func() *SimulationResponse {
+		return &SimulationResponse{
+			BreakdownByNamespace: make(map[string]*NamespaceImpact),
+			BreakdownByVerdict:   &VerdictBreakdown{},
+			Details:              []*FlowSimulationResult{},
+		}
+	}
+
+	response, err := e.streamEvaluate(ctx, queryReq, req.Progress, maxDetails, startTime, newPartial, func(partial *SimulationResponse, event *models.TelemetryEvent) {
+		flowResult := e.evaluateFlow(event, policy)
+		partial.TotalFlowsAnalyzed++
 
 		// Update summary counts
 		switch flowResult.SimulatedVerdict {
 		case "ALLOWED":
-			response.AllowedCount++
+			partial.AllowedCount++
 		case "DENIED":
-			response.DeniedCount++
+			partial.DeniedCount++
 		}
 
 		if flowResult.VerdictChanged {
-			response.WouldChangeCount++
+			partial.WouldChangeCount++
+			addConnection(partial, event, flowResult)
 		} else {
-			response.NoChangeCount++
+			partial.NoChangeCount++
 		}
 
 		// Update verdict breakdown
-		e.updateVerdictBreakdown(response.BreakdownByVerdict, flowResult)
+		e.updateVerdictBreakdown(partial.BreakdownByVerdict, flowResult)
 
 		// Update namespace breakdown
-		e.updateNamespaceBreakdown(response.BreakdownByNamespace, &event, flowResult)
+		e.updateNamespaceBreakdown(partial.BreakdownByNamespace, event, flowResult)
 
 		// Add to details if requested and under limit
-		if req.IncludeDetails && len(response.Details) < maxDetails {
-			response.Details = append(response.Details, flowResult)
+		if req.IncludeDetails && len(partial.Details) < maxDetails {
+			partial.Details = append(partial.Details, flowResult)
 		}
+	})
+	if err != nil || len(response.Errors) > 0 {
+		return response, err
 	}
 
-	response.Duration = time.Since(startTime)
-
 	e.log.Info("Simulation complete",
 		"totalFlows", response.TotalFlowsAnalyzed,
 		"allowed", response.AllowedCount,
@@ -169,27 +184,41 @@ func (e *Engine) evaluateFlow(event *models.TelemetryEvent, policy *ParsedPolicy
 	// - Ingress: if DST matches endpointSelector, check if SRC is allowed by ingress rules
 	// - Egress: if SRC matches endpointSelector, check if DST is allowed by egress rules
 
-	// Check ingress rules (policy applies to destination pod)
-	if len(policy.IngressRules) > 0 || policy.DefaultDenyType == "ingress" || policy.DefaultDenyType == "both" {
-		if e.matchesPodSelectorForDirection(event, policy, "ingress") {
-			// Destination matches policy selector, evaluate ingress rules
-			ingressResult := e.evaluateIngressRules(event, policy, result)
-			if ingressResult != nil {
-				return ingressResult
-			}
-		}
+	// Replies on established connections are allowed by connection tracking
+	if event.IsReply {
+		result.SimulatedVerdict = result.OriginalVerdict
+		result.MatchReason = "Reply traffic is not subject to policy"
+		return result
 	}
 
-	// Check egress rules (policy applies to source pod)
-	if len(policy.EgressRules) > 0 || policy.DefaultDenyType == "egress" || policy.DefaultDenyType == "both" {
-		if e.matchesPodSelectorForDirection(event, policy, "egress") {
-			// Source matches policy selector, evaluate egress rules
-			egressResult := e.evaluateEgressRules(event, policy, result)
-			if egressResult != nil {
-				return egressResult
-			}
+	// A flow has to pass both the egress rules of its source and the ingress
+	// rules of its destination, so a denial in either direction wins.
+	var allowed *FlowSimulationResult
+	for _, direction := range []string{"ingress", "egress"} {
+		if !PolicyCoversDirection(policy, direction) {
+			continue
+		}
+		// Ingress rules apply when the destination matches the policy selector,
+		// egress rules when the source does
+		if !MatchesPodSelectorForDirection(event, policy, direction) {
+			continue
+		}
+
+		candidate := *result
+		dirResult := e.evaluateRules(event, policy, &candidate, direction)
+		if dirResult == nil {
+			continue
+		}
+		if dirResult.SimulatedVerdict == "DENIED" {
+			return dirResult
+		}
+		if allowed == nil {
+			allowed = dirResult
 		}
 	}
+	if allowed != nil {
+		return allowed
+	}
 
 	// Policy doesn't apply to this flow
 	result.SimulatedVerdict = result.OriginalVerdict
@@ -198,42 +227,58 @@ func (e *Engine) evaluateFlow(event *models.TelemetryEvent, policy *ParsedPolicy
 	return result
 }
 
-// evaluateIngressRules evaluates ingress rules where destination matches the policy selector.
-func (e *Engine) evaluateIngressRules(event *models.TelemetryEvent, policy *ParsedPolicy, result *FlowSimulationResult) *FlowSimulationResult {
-	rules := policy.IngressRules
+// evaluateRules evaluates the rules of one direction using Cilium's precedence:
+// a matching deny rule always wins, then any matching allow rule, then the
+// default deny if the policy enables it. Returns nil if the direction does not
+// decide the flow.
+func (e *Engine) evaluateRules(event *models.TelemetryEvent, policy *ParsedPolicy, result *FlowSimulationResult, direction string) *FlowSimulationResult {
+	rules := RulesForDirection(policy, direction)
 
-	// If no ingress rules but default deny is enabled for ingress
-	if len(rules) == 0 {
-		if policy.DefaultDeny && (policy.DefaultDenyType == "ingress" || policy.DefaultDenyType == "both") {
+	// Deny rules take precedence regardless of their position in the policy
+	for i, rule := range rules {
+		if rule.Action != "deny" {
+			continue
+		}
+		if FlowMatchesRuleInDirection(event, &rule, direction) {
 			result.SimulatedVerdict = "DENIED"
-			result.MatchReason = "Default deny ingress, no rules defined"
+			result.MatchedRule = ruleDescription(i, &rule)
+			result.MatchReason = fmt.Sprintf("Matched %s deny rule", direction)
 			result.VerdictChanged = result.SimulatedVerdict != result.OriginalVerdict
 			return result
 		}
-		return nil // No ingress rules and no default deny
 	}
 
-	// Check if any ingress rule allows this flow
+	// Check if any allow rule matches this flow
 	for i, rule := range rules {
-		if e.flowMatchesIngressRule(event, &rule) {
-			if rule.Action == "allow" {
+		if rule.Action == "deny" {
+			continue
+		}
+		if FlowMatchesRuleInDirection(event, &rule, direction) {
+			result.MatchedRule = ruleDescription(i, &rule)
+			if RequiresSatisfied(event, policy, direction) {
 				result.SimulatedVerdict = "ALLOWED"
-				result.MatchedRule = ruleDescription(i, &rule)
-				result.MatchReason = "Matched ingress allow rule"
+				result.MatchReason = fmt.Sprintf("Matched %s allow rule", direction)
 			} else {
 				result.SimulatedVerdict = "DENIED"
-				result.MatchedRule = ruleDescription(i, &rule)
-				result.MatchReason = "Matched ingress deny rule"
+				if direction == "ingress" {
+					result.MatchReason = "Source does not satisfy fromRequires"
+				} else {
+					result.MatchReason = "Destination does not satisfy toRequires"
+				}
 			}
 			result.VerdictChanged = result.SimulatedVerdict != result.OriginalVerdict
 			return result
 		}
 	}
 
-	// No ingress rule matched - apply default deny
-	if policy.DefaultDeny {
+	// No rule matched - apply default deny
+	if DefaultDenies(policy, direction) {
 		result.SimulatedVerdict = "DENIED"
-		result.MatchReason = "No matching ingress rule, default deny"
+		if len(rules) == 0 {
+			result.MatchReason = fmt.Sprintf("Default deny %s, no rules defined", direction)
+		} else {
+			result.MatchReason = fmt.Sprintf("No matching %s rule, default deny", direction)
+		}
 		result.VerdictChanged = result.SimulatedVerdict != result.OriginalVerdict
 		return result
 	}
@@ -241,60 +286,38 @@ func (e *Engine) evaluateIngressRules(event *models.TelemetryEvent, policy *Pars
 	return nil
 }
 
-// evaluateEgressRules evaluates egress rules where source matches the policy selector.
-func (e *Engine) evaluateEgressRules(event *models.TelemetryEvent, policy *ParsedPolicy, result *FlowSimulationResult) *FlowSimulationResult {
-	rules := policy.EgressRules
-
-	// If no egress rules but default deny is enabled for egress
-	if len(rules) == 0 {
-		if policy.DefaultDeny && (policy.DefaultDenyType == "egress" || policy.DefaultDenyType == "both") {
-			result.SimulatedVerdict = "DENIED"
-			result.MatchReason = "Default deny egress, no rules defined"
-			result.VerdictChanged = result.SimulatedVerdict != result.OriginalVerdict
-			return result
-		}
-		return nil // No egress rules and no default deny
-	}
-
-	// Check if any egress rule allows this flow
-	for i, rule := range rules {
-		if e.flowMatchesEgressRule(event, &rule) {
-			if rule.Action == "allow" {
-				result.SimulatedVerdict = "ALLOWED"
-				result.MatchedRule = ruleDescription(i, &rule)
-				result.MatchReason = "Matched egress allow rule"
-			} else {
-				result.SimulatedVerdict = "DENIED"
-				result.MatchedRule = ruleDescription(i, &rule)
-				result.MatchReason = "Matched egress deny rule"
-			}
-			result.VerdictChanged = result.SimulatedVerdict != result.OriginalVerdict
-			return result
-		}
+// RulesForDirection returns the ingress or egress rules of a policy.
+func RulesForDirection(policy *ParsedPolicy, direction string) []PolicyRule {
+	if direction == "ingress" {
+		return policy.IngressRules
 	}
+	return policy.EgressRules
+}
 
-	// No egress rule matched - apply default deny
-	if policy.DefaultDeny {
-		result.SimulatedVerdict = "DENIED"
-		result.MatchReason = "No matching egress rule, default deny"
-		result.VerdictChanged = result.SimulatedVerdict != result.OriginalVerdict
-		return result
+// PolicyCoversDirection reports whether a policy has anything to say about a direction.
+func PolicyCoversDirection(policy *ParsedPolicy, direction string) bool {
+	if direction == "ingress" {
+		return len(policy.IngressRules) > 0 || policy.DefaultDenyType == "ingress" || policy.DefaultDenyType == "both"
 	}
+	return len(policy.EgressRules) > 0 || policy.DefaultDenyType == "egress" || policy.DefaultDenyType == "both"
+}
 
-	return nil
+// DefaultDenies reports whether unmatched traffic is denied in a direction.
+func DefaultDenies(policy *ParsedPolicy, direction string) bool {
+	return policy.DefaultDeny && (policy.DefaultDenyType == direction || policy.DefaultDenyType == "both")
 }
 
 // matchesPodSelector checks if an event's source matches the policy's pod selector.
 // This is a legacy method that checks source labels only.
-// For proper direction-aware matching, use matchesPodSelectorForDirection.
+// For proper direction-aware matching, use MatchesPodSelectorForDirection.
 func (e *Engine) matchesPodSelector(event *models.TelemetryEvent, policy *ParsedPolicy) bool {
-	return e.matchesPodSelectorForDirection(event, policy, "egress")
+	return MatchesPodSelectorForDirection(event, policy, "egress")
 }
 
-// matchesPodSelectorForDirection checks if the appropriate pod matches the policy selector.
+// MatchesPodSelectorForDirection checks if the appropriate pod matches the policy selector.
 // For ingress: checks destination pod (policy applies to destination)
 // For egress: checks source pod (policy applies to source)
-func (e *Engine) matchesPodSelectorForDirection(event *models.TelemetryEvent, policy *ParsedPolicy, direction string) bool {
+func MatchesPodSelectorForDirection(event *models.TelemetryEvent, policy *ParsedPolicy, direction string) bool {
 	var namespace string
 	var labels map[string]string
 
@@ -309,7 +332,7 @@ func (e *Engine) matchesPodSelectorForDirection(event *models.TelemetryEvent, po
 	}
 
 	// Empty selector matches all pods
-	if len(policy.PodSelector) == 0 {
+	if len(policy.PodSelector) == 0 && len(policy.PodSelectorExpressions) == 0 {
 		// But still need to match namespace if specified
 		if policy.Namespace != "" && namespace != policy.Namespace {
 			return false
@@ -323,130 +346,55 @@ func (e *Engine) matchesPodSelectorForDirection(event *models.TelemetryEvent, po
 	}
 
 	// Check pod labels using the labelsMatch helper that handles k8s: prefix
-	return labelsMatch(labels, policy.PodSelector)
-}
-
-// flowMatchesIngressRule checks if a flow matches an ingress rule.
-// For ingress rules, we check if the SOURCE pod/namespace matches the rule's fromEndpoints selector.
-func (e *Engine) flowMatchesIngressRule(event *models.TelemetryEvent, rule *PolicyRule) bool {
-	// Check fromEndpoints selector (matches source pod for ingress)
-	// Use labelsMatch to handle both normalized and k8s:-prefixed labels
-	if len(rule.PodSelector) > 0 {
-		if !labelsMatch(event.SrcPodLabels, rule.PodSelector) {
-			return false
-		}
-	}
-
-	// Check namespace selector for source
-	if len(rule.NamespaceSelector) > 0 {
-		if nsName, ok := rule.NamespaceSelector["name"]; ok {
-			if event.SrcNamespace != nsName {
-				return false
-			}
-		}
-	}
-
-	// Check ports (destination ports for ingress)
-	if len(rule.ToPorts) > 0 {
-		portMatched := false
-		for _, portRule := range rule.ToPorts {
-			if e.portMatches(event, &portRule) {
-				portMatched = true
-				break
-			}
-		}
-		if !portMatched {
-			return false
-		}
-	}
-
-	// Check L7 rules if present
-	if len(rule.L7Rules) > 0 {
-		l7Matched := false
-		for _, l7Rule := range rule.L7Rules {
-			if e.l7Matches(event, &l7Rule) {
-				l7Matched = true
-				break
-			}
-		}
-		if !l7Matched {
-			return false
-		}
-	}
-
-	// Check fromCIDR rules
-	if len(rule.FromCIDRs) > 0 {
-		// For CIDR matching, we'd need to check if source IP is in the CIDR range
-		// Simplified: skip for now
+	if !labelsMatch(labels, policy.PodSelector) {
+		return false
 	}
-
-	return true
+	return expressionsMatch(labels, namespace, policy.PodSelectorExpressions)
 }
 
-// flowMatchesEgressRule checks if a flow matches an egress rule.
-// For egress rules, we check if the DESTINATION pod/namespace matches the rule's toEndpoints selector.
-func (e *Engine) flowMatchesEgressRule(event *models.TelemetryEvent, rule *PolicyRule) bool {
-	// Check toEndpoints selector (matches destination pod for egress)
-	// Use labelsMatch to handle both normalized and k8s:-prefixed labels
-	if len(rule.PodSelector) > 0 {
-		if !labelsMatch(event.DstPodLabels, rule.PodSelector) {
-			return false
-		}
-	}
-
-	// Check namespace selector for destination
-	if len(rule.NamespaceSelector) > 0 {
-		if nsName, ok := rule.NamespaceSelector["name"]; ok {
-			if event.DstNamespace != nsName {
-				return false
-			}
-		}
+// flowMatchesRule checks if a flow matches a specific rule, using the rule's direction.
+func (e *Engine) flowMatchesRule(event *models.TelemetryEvent, rule *PolicyRule) bool {
+	direction := rule.Direction
+	if direction != "ingress" {
+		direction = "egress"
 	}
+	return FlowMatchesRuleInDirection(event, rule, direction)
+}
 
-	// Check ports (destination ports for egress)
-	if len(rule.ToPorts) > 0 {
-		portMatched := false
-		for _, portRule := range rule.ToPorts {
-			if e.portMatches(event, &portRule) {
-				portMatched = true
-				break
-			}
-		}
-		if !portMatched {
-			return false
-		}
+// FlowMatchesRuleInDirection checks the peer (L3), L4 and L7 constraints of a rule.
+func FlowMatchesRuleInDirection(event *models.TelemetryEvent, rule *PolicyRule, direction string) bool {
+	// Check peer selectors (endpoints, CIDRs, entities)
+	if !peerMatchesRule(peerForDirection(event, direction), rule, direction) {
+		return false
 	}
 
-	// Check L7 rules if present
-	if len(rule.L7Rules) > 0 {
-		l7Matched := false
-		for _, l7Rule := range rule.L7Rules {
-			if e.l7Matches(event, &l7Rule) {
-				l7Matched = true
+	// Check toServices (egress only)
+	if len(rule.ToServices) > 0 {
+		svcMatched := false
+		for i := range rule.ToServices {
+			if serviceMatches(event, &rule.ToServices[i]) {
+				svcMatched = true
 				break
 			}
 		}
-		if !l7Matched {
+		if !svcMatched {
 			return false
 		}
 	}
 
-	// Check toCIDR rules
-	if len(rule.ToCIDRs) > 0 {
-		// For CIDR matching, we'd need to check if dest IP is in the CIDR range
-		// Simplified: skip for now
-	}
-
 	// Check FQDN rules
 	if len(rule.ToFQDNs) > 0 {
 		if event.DstDNSName == "" {
 			return false
 		}
+		// Hubble lists every name the destination IP was resolved from
 		fqdnMatched := false
-		for _, pattern := range rule.ToFQDNs {
-			if matchFQDN(event.DstDNSName, pattern) {
-				fqdnMatched = true
-				break
+		for _, name := range strings.Split(event.DstDNSName, ",") {
+			for _, pattern := range rule.ToFQDNs {
+				if matchFQDN(name, pattern) {
+					fqdnMatched = true
+					break
+				}
 			}
 		}
 		if !fqdnMatched {
@@ -454,50 +402,20 @@ func (e *Engine) flowMatchesEgressRule(event *models.TelemetryEvent, rule *Polic
 		}
 	}
 
-	return true
-}
-
-// flowMatchesRule checks if a flow matches a specific rule.
-func (e *Engine) flowMatchesRule(event *models.TelemetryEvent, rule *PolicyRule) bool {
-	// Check endpoint selectors
-	if len(rule.PodSelector) > 0 {
-		targetLabels := event.DstPodLabels
-		if rule.Direction == "ingress" {
-			targetLabels = event.SrcPodLabels
-		}
-		if targetLabels == nil {
-			return false
-		}
-		for key, value := range rule.PodSelector {
-			if targetLabels[key] != value {
-				return false
-			}
-		}
-	}
-
-	// Check namespace selector
-	if len(rule.NamespaceSelector) > 0 {
-		targetNS := event.DstNamespace
-		if rule.Direction == "ingress" {
-			targetNS = event.SrcNamespace
-		}
-		if nsName, ok := rule.NamespaceSelector["name"]; ok {
-			if targetNS != nsName {
-				return false
-			}
-		}
-	}
-
-	// Check ports
-	if len(rule.ToPorts) > 0 {
-		portMatched := false
+	// Check ports and ICMP types (destination ports in both directions).
+	// A rule with both toPorts and icmps matches either of them.
+	if len(rule.ToPorts) > 0 || len(rule.ICMPs) > 0 {
+		l4Matched := false
 		for _, portRule := range rule.ToPorts {
-			if e.portMatches(event, &portRule) {
-				portMatched = true
+			if portMatches(event, &portRule) {
+				l4Matched = true
 				break
 			}
 		}
-		if !portMatched {
+		for i := 0; !l4Matched && i < len(rule.ICMPs); i++ {
+			l4Matched = icmpMatches(event, &rule.ICMPs[i])
+		}
+		if !l4Matched {
 			return false
 		}
 	}
@@ -506,7 +424,7 @@ func (e *Engine) flowMatchesRule(event *models.TelemetryEvent, rule *PolicyRule)
 	if len(rule.L7Rules) > 0 {
 		l7Matched := false
 		for _, l7Rule := range rule.L7Rules {
-			if e.l7Matches(event, &l7Rule) {
+			if l7Matches(event, &l7Rule) {
 				l7Matched = true
 				break
 			}
@@ -516,36 +434,28 @@ func (e *Engine) flowMatchesRule(event *models.TelemetryEvent, rule *PolicyRule)
 		}
 	}
 
-	// Check CIDR rules
-	if len(rule.ToCIDRs) > 0 || len(rule.FromCIDRs) > 0 {
-		// For CIDR matching, we'd need to check if the IP is in the CIDR range
-		// For now, we'll skip detailed CIDR matching
-	}
+	return true
+}
 
-	// Check FQDN rules
-	if len(rule.ToFQDNs) > 0 {
-		if event.DstDNSName == "" {
-			return false
-		}
-		fqdnMatched := false
-		for _, pattern := range rule.ToFQDNs {
-			if matchFQDN(event.DstDNSName, pattern) {
-				fqdnMatched = true
-				break
-			}
-		}
-		if !fqdnMatched {
+// RequiresSatisfied checks fromRequires/toRequires selectors for a direction.
+func RequiresSatisfied(event *models.TelemetryEvent, policy *ParsedPolicy, direction string) bool {
+	requires := policy.IngressRequires
+	if direction == "egress" {
+		requires = policy.EgressRequires
+	}
+	peer := peerForDirection(event, direction)
+	for _, sel := range requires {
+		if !selectorMatches(peer, sel) {
 			return false
 		}
 	}
-
 	return true
 }
 
 // portMatches checks if a flow matches a port rule.
-func (e *Engine) portMatches(event *models.TelemetryEvent, rule *PortRule) bool {
-	// Check protocol
-	if rule.Protocol != "" && !strings.EqualFold(event.Protocol, rule.Protocol) {
+func portMatches(event *models.TelemetryEvent, rule *PortRule) bool {
+	// Check protocol (empty or ANY matches every protocol)
+	if rule.Protocol != "" && !strings.EqualFold(rule.Protocol, "ANY") && !strings.EqualFold(event.Protocol, rule.Protocol) {
 		return false
 	}
 
@@ -567,38 +477,73 @@ func (e *Engine) portMatches(event *models.TelemetryEvent, rule *PortRule) bool
 	return true
 }
 
-// l7Matches checks if a flow matches an L7 rule.
-func (e *Engine) l7Matches(event *models.TelemetryEvent, rule *L7Rule) bool {
+// l7Matches checks if a flow matches an L7 rule. Flows without L7 details
+// are the L4 connections redirected to the proxy and only need to match L4.
+func l7Matches(event *models.TelemetryEvent, rule *L7Rule) bool {
+	protocol := l7Protocol(event)
+	if protocol == "" {
+		return true
+	}
+
 	switch rule.Type {
 	case "http":
-		if event.L7Type != "HTTP" {
+		if protocol != "http" && protocol != "grpc" {
 			return false
 		}
-		if rule.Method != "" && !strings.EqualFold(event.HTTPMethod, rule.Method) {
+		host, path, method := l7HTTPRequest(event)
+		if rule.Method != "" && !strings.EqualFold(method, rule.Method) {
 			return false
 		}
 		if rule.Path != "" {
 			// Support regex matching for paths
 			if strings.HasPrefix(rule.Path, "^") || strings.HasSuffix(rule.Path, "$") {
-				matched, _ := regexp.MatchString(rule.Path, event.HTTPPath)
+				matched, _ := regexp.MatchString(rule.Path, path)
 				if !matched {
 					return false
 				}
-			} else if !strings.HasPrefix(event.HTTPPath, rule.Path) {
+			} else if !strings.HasPrefix(path, rule.Path) {
 				return false
 			}
 		}
-		if rule.Host != "" && event.HTTPHost != rule.Host {
+		if rule.Host != "" && !strings.EqualFold(host, rule.Host) {
 			return false
 		}
+		if len(rule.Headers) > 0 {
+			headers := parseHTTPHeaders(event.HTTPHeaders)
+			for _, header := range rule.Headers {
+				value, ok := headers[strings.ToLower(header.Name)]
+				if !ok || (header.Value != "" && value != header.Value) {
+					return false
+				}
+			}
+		}
 		return true
 
 	case "dns":
-		if event.L7Type != "DNS" {
+		if protocol != "dns" {
 			return false
 		}
 		if rule.Host != "" {
-			return matchFQDN(event.DNSQuery, rule.Host)
+			query := strings.TrimSuffix(strings.ToLower(event.DNSQuery), ".")
+			return matchFQDN(query, strings.ToLower(rule.Host))
+		}
+		return true
+
+	case "kafka":
+		if protocol != "kafka" {
+			return false
+		}
+		if rule.Topic != "" && event.KafkaTopic != rule.Topic {
+			return false
+		}
+		if len(rule.APIKeys) > 0 {
+			apiKey := strings.ToLower(strings.ReplaceAll(event.KafkaAPIKey, "_", ""))
+			for _, allowed := range rule.APIKeys {
+				if apiKey == allowed {
+					return true
+				}
+			}
+			return false
 		}
 		return true
 
@@ -607,6 +552,57 @@ func (e *Engine) l7Matches(event *models.TelemetryEvent, rule *L7Rule) bool {
 	}
 }
 
+// l7Protocol returns the L7 protocol of a flow: http, grpc, dns or kafka, or
+// "" for flows without L7 details. Hubble records the L7 flow type (REQUEST,
+// RESPONSE) rather than the protocol, so it falls back to the populated fields.
+func l7Protocol(event *models.TelemetryEvent) string {
+	switch strings.ToUpper(event.L7Type) {
+	case "HTTP":
+		return "http"
+	case "GRPC":
+		return "grpc"
+	case "DNS":
+		return "dns"
+	case "KAFKA":
+		return "kafka"
+	}
+	switch {
+	case event.GRPCService != "":
+		return "grpc"
+	case event.HTTPMethod != "" || event.HTTPPath != "":
+		return "http"
+	case event.DNSQuery != "":
+		return "dns"
+	case event.KafkaTopic != "" || event.KafkaAPIKey != "":
+		return "kafka"
+	default:
+		return ""
+	}
+}
+
+// l7HTTPRequest returns the host, path and method of an HTTP or gRPC
+// request. Hubble records the full URL as the path, and gRPC calls are
+// POSTs to "/package.Service/Method".
+func l7HTTPRequest(event *models.TelemetryEvent) (string, string, string) {
+	host, path, method := event.HTTPHost, event.HTTPPath, event.HTTPMethod
+	if u, err := url.Parse(event.HTTPPath); err == nil && u.Host != "" {
+		path = u.Path
+		if host == "" {
+			host = u.Host
+		}
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if event.GRPCService != "" {
+		path = "/" + event.GRPCService + "/" + event.GRPCMethod
+		if method == "" {
+			method = "POST"
+		}
+	}
+	return host, path, method
+}
+
 // matchFQDN matches a hostname against an FQDN pattern.
 func matchFQDN(hostname, pattern string) bool {
 	// Handle wildcard patterns like *.example.com
@@ -651,6 +647,27 @@ func ruleDescription(index int, rule *PolicyRule) string {
 	var parts []string
 	parts = append(parts, rule.Direction)
 
+	if len(rule.Entities) > 0 {
+		parts = append(parts, "entities:"+strings.Join(rule.Entities, ","))
+	}
+	cidrs := append(append([]string{}, rule.FromCIDRs...), rule.ToCIDRs...)
+	for _, cr := range append(append([]CIDRRule{}, rule.FromCIDRSet...), rule.ToCIDRSet...) {
+		cidrs = append(cidrs, cr.CIDR)
+	}
+	if len(cidrs) > 0 {
+		parts = append(parts, "cidr:"+strings.Join(cidrs, ","))
+	}
+	for _, svc := range rule.ToServices {
+		if svc.Name != "" {
+			parts = append(parts, "service:"+svc.Namespace+"/"+svc.Name)
+		} else {
+			parts = append(parts, "service-selector:"+svc.Namespace)
+		}
+	}
+	if len(rule.ToFQDNs) > 0 {
+		parts = append(parts, "fqdn:"+strings.Join(rule.ToFQDNs, ","))
+	}
+
 	if len(rule.ToPorts) > 0 {
 		for _, p := range rule.ToPorts {
 			parts = append(parts, fmt.Sprintf("%s:%s/%d", strings.ToLower(rule.Direction), p.Protocol, p.Port))
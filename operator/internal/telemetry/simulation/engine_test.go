@@ -353,8 +353,6 @@ func TestEngine_EvaluateFlow_L7Rules(t *testing.T) {
 }
 
 func TestEngine_PortMatches(t *testing.T) {
-	engine := &Engine{}
-
 	tests := []struct {
 		name  string
 		event models.TelemetryEvent
@@ -413,7 +411,7 @@ func TestEngine_PortMatches(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := engine.portMatches(&tt.event, &tt.rule)
+			got := portMatches(&tt.event, &tt.rule)
 			if got != tt.want {
 				t.Errorf("portMatches() = %v, want %v", got, tt.want)
 			}
@@ -422,8 +420,6 @@ func TestEngine_PortMatches(t *testing.T) {
 }
 
 func TestEngine_L7Matches(t *testing.T) {
-	engine := &Engine{}
-
 	tests := []struct {
 		name  string
 		event models.TelemetryEvent
@@ -497,11 +493,128 @@ func TestEngine_L7Matches(t *testing.T) {
 			rule: L7Rule{Type: "http", Method: "GET"},
 			want: false,
 		},
+		{
+			name: "Hubble request with full URL",
+			event: models.TelemetryEvent{
+				L7Type:     "REQUEST",
+				HTTPMethod: "GET",
+				HTTPPath:   "http://api.prod:8080/api/users",
+			},
+			rule: L7Rule{Type: "http", Method: "GET", Path: "/api/", Host: "api.prod"},
+			want: true,
+		},
+		{
+			name: "HTTP header value match",
+			event: models.TelemetryEvent{
+				L7Type:      "HTTP",
+				HTTPMethod:  "GET",
+				HTTPPath:    "/api/users",
+				HTTPHeaders: "X-Tenant=acme;X-Request-Id=42",
+			},
+			rule: L7Rule{Type: "http", Headers: []HeaderMatch{{Name: "x-tenant", Value: "acme"}, {Name: "X-Request-Id"}}},
+			want: true,
+		},
+		{
+			name: "HTTP header value mismatch",
+			event: models.TelemetryEvent{
+				L7Type:      "HTTP",
+				HTTPMethod:  "GET",
+				HTTPPath:    "/api/users",
+				HTTPHeaders: "X-Tenant=other",
+			},
+			rule: L7Rule{Type: "http", Headers: []HeaderMatch{{Name: "X-Tenant", Value: "acme"}}},
+			want: false,
+		},
+		{
+			name: "HTTP header missing",
+			event: models.TelemetryEvent{
+				L7Type:     "HTTP",
+				HTTPMethod: "GET",
+				HTTPPath:   "/api/users",
+			},
+			rule: L7Rule{Type: "http", Headers: []HeaderMatch{{Name: "X-Request-Id"}}},
+			want: false,
+		},
+		{
+			name: "gRPC method matched by path",
+			event: models.TelemetryEvent{
+				L7Type:      "REQUEST",
+				GRPCService: "payments.v1.Payments",
+				GRPCMethod:  "Charge",
+			},
+			rule: L7Rule{Type: "http", Method: "POST", Path: "^/payments.v1.Payments/(Charge|Refund)$"},
+			want: true,
+		},
+		{
+			name: "gRPC method mismatch",
+			event: models.TelemetryEvent{
+				L7Type:      "REQUEST",
+				GRPCService: "payments.v1.Payments",
+				GRPCMethod:  "Delete",
+			},
+			rule: L7Rule{Type: "http", Path: "^/payments.v1.Payments/(Charge|Refund)$"},
+			want: false,
+		},
+		{
+			name: "DNS query with trailing dot",
+			event: models.TelemetryEvent{
+				L7Type:   "REQUEST",
+				DNSQuery: "API.example.com.",
+			},
+			rule: L7Rule{Type: "dns", Host: "*.example.com"},
+			want: true,
+		},
+		{
+			name: "Kafka produce role",
+			event: models.TelemetryEvent{
+				L7Type:      "REQUEST",
+				KafkaTopic:  "orders",
+				KafkaAPIKey: "produce",
+			},
+			rule: L7Rule{Type: "kafka", Topic: "orders", APIKeys: []string{"produce", "metadata", "apiversions"}},
+			want: true,
+		},
+		{
+			name: "Kafka API key not allowed by role",
+			event: models.TelemetryEvent{
+				L7Type:      "REQUEST",
+				KafkaTopic:  "orders",
+				KafkaAPIKey: "fetch",
+			},
+			rule: L7Rule{Type: "kafka", Topic: "orders", APIKeys: []string{"produce", "metadata", "apiversions"}},
+			want: false,
+		},
+		{
+			name: "Kafka topic mismatch",
+			event: models.TelemetryEvent{
+				L7Type:      "KAFKA",
+				KafkaTopic:  "payments",
+				KafkaAPIKey: "produce",
+			},
+			rule: L7Rule{Type: "kafka", Topic: "orders"},
+			want: false,
+		},
+		{
+			name: "Kafka rule against HTTP request",
+			event: models.TelemetryEvent{
+				L7Type:     "REQUEST",
+				HTTPMethod: "GET",
+				HTTPPath:   "/orders",
+			},
+			rule: L7Rule{Type: "kafka", Topic: "orders"},
+			want: false,
+		},
+		{
+			name:  "L4 flow redirected to the proxy",
+			event: models.TelemetryEvent{Protocol: "TCP", DstPort: 8080},
+			rule:  L7Rule{Type: "http", Method: "GET"},
+			want:  true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := engine.l7Matches(&tt.event, &tt.rule)
+			got := l7Matches(&tt.event, &tt.rule)
 			if got != tt.want {
 				t.Errorf("l7Matches() = %v, want %v", got, tt.want)
 			}
@@ -1038,6 +1151,20 @@ func TestEngine_FlowMatchesRule_WithFQDN(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "FQDN match on one of several names",
+			event: models.TelemetryEvent{
+				DstDNSName: "cdn.other.com,api.example.com",
+				DstPort:    443,
+				Protocol:   "TCP",
+			},
+			rule: PolicyRule{
+				Direction: "egress",
+				Action:    "allow",
+				ToFQDNs:   []string{"*.example.com"},
+			},
+			want: true,
+		},
 		{
 			name: "no DNS name with FQDN rule",
 			event: models.TelemetryEvent{
@@ -1122,3 +1249,462 @@ func TestRuleDescription(t *testing.T) {
 		})
 	}
 }
+
+func TestEngine_EvaluateFlow_CiliumSelectors(t *testing.T) {
+	engine := &Engine{
+		parser: NewPolicyParser(),
+		log:    logr.Discard(),
+	}
+
+	const tieredIngress = `
+apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: backend-ingress
+  namespace: shop
+spec:
+  endpointSelector:
+    matchExpressions:
+      - key: tier
+        operator: In
+        values: [backend]
+  ingress:
+    - fromEndpoints:
+        - matchExpressions:
+            - key: app
+              operator: NotIn
+              values: [debug]
+            - key: role
+              operator: Exists
+      toPorts:
+        - ports:
+            - port: "8080"
+              protocol: TCP
+    - fromEndpoints:
+        - matchLabels:
+            k8s:io.kubernetes.pod.namespace: monitoring
+            app: prometheus
+    - fromEntities:
+        - host
+        - remote-node
+`
+
+	const worldEgress = `
+apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: api-egress
+  namespace: shop
+spec:
+  endpointSelector:
+    matchLabels:
+      app: api
+  egress:
+    - toEndpoints:
+        - {}
+    - toCIDRSet:
+        - cidr: 203.0.113.0/24
+          except:
+            - 203.0.113.128/25
+      toPorts:
+        - ports:
+            - port: "443"
+    - toEntities:
+        - kube-apiserver
+    - toServices:
+        - k8sService:
+            serviceName: payments
+            namespace: billing
+    - icmps:
+        - fields:
+            - type: 8
+`
+
+	const requiresIngress = `
+apiVersion: cilium.io/v2
+kind: CiliumClusterwideNetworkPolicy
+metadata:
+  name: prod-isolation
+spec:
+  endpointSelector:
+    matchLabels:
+      env: prod
+  ingress:
+    - fromRequires:
+        - matchLabels:
+            env: prod
+    - fromEntities:
+        - cluster
+`
+
+	tests := []struct {
+		name        string
+		policy      string
+		policyType  string
+		event       models.TelemetryEvent
+		wantVerdict string
+	}{
+		{
+			name:   "matchExpressions allow labelled peer",
+			policy: tieredIngress,
+			event: models.TelemetryEvent{
+				SrcNamespace: "shop", SrcPodLabels: map[string]string{"app": "web", "role": "client"},
+				DstNamespace: "shop", DstPodLabels: map[string]string{"tier": "backend"},
+				DstPort: 8080, Protocol: "TCP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "ALLOWED",
+		},
+		{
+			name:   "matchExpressions NotIn rejects debug pod",
+			policy: tieredIngress,
+			event: models.TelemetryEvent{
+				SrcNamespace: "shop", SrcPodLabels: map[string]string{"app": "debug", "role": "client"},
+				DstNamespace: "shop", DstPodLabels: map[string]string{"tier": "backend"},
+				DstPort: 8080, Protocol: "TCP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "DENIED",
+		},
+		{
+			name:   "implicit namespace rejects same labels in other namespace",
+			policy: tieredIngress,
+			event: models.TelemetryEvent{
+				SrcNamespace: "dev", SrcPodLabels: map[string]string{"app": "web", "role": "client"},
+				DstNamespace: "shop", DstPodLabels: map[string]string{"tier": "backend"},
+				DstPort: 8080, Protocol: "TCP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "DENIED",
+		},
+		{
+			name:   "explicit namespace label selects other namespace",
+			policy: tieredIngress,
+			event: models.TelemetryEvent{
+				SrcNamespace: "monitoring", SrcPodLabels: map[string]string{"app": "prometheus"},
+				DstNamespace: "shop", DstPodLabels: map[string]string{"tier": "backend"},
+				DstPort: 9090, Protocol: "TCP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "ALLOWED",
+		},
+		{
+			name:   "fromEntities host by identity",
+			policy: tieredIngress,
+			event: models.TelemetryEvent{
+				SrcIdentity: identityHost, SrcIP: "10.0.0.1",
+				DstNamespace: "shop", DstPodLabels: map[string]string{"tier": "backend"},
+				DstPort: 10250, Protocol: "TCP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "ALLOWED",
+		},
+		{
+			name:   "endpointSelector expression does not select frontend",
+			policy: tieredIngress,
+			event: models.TelemetryEvent{
+				SrcIdentity: identityWorld, SrcIP: "198.51.100.7",
+				DstNamespace: "shop", DstPodLabels: map[string]string{"tier": "frontend"},
+				DstPort: 80, Protocol: "TCP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "ALLOWED",
+		},
+		{
+			name:   "empty toEndpoints allows pods in the namespace",
+			policy: worldEgress,
+			event: models.TelemetryEvent{
+				SrcNamespace: "shop", SrcPodLabels: map[string]string{"app": "api"},
+				DstNamespace: "shop", DstPodLabels: map[string]string{"app": "db"},
+				DstPort: 5432, Protocol: "TCP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "ALLOWED",
+		},
+		{
+			name:   "empty toEndpoints does not select the world",
+			policy: worldEgress,
+			event: models.TelemetryEvent{
+				SrcNamespace: "shop", SrcPodLabels: map[string]string{"app": "api"},
+				DstIP: "198.51.100.7", DstIdentity: identityWorld,
+				DstPort: 5432, Protocol: "TCP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "DENIED",
+		},
+		{
+			name:   "toCIDRSet allows address outside except",
+			policy: worldEgress,
+			event: models.TelemetryEvent{
+				SrcNamespace: "shop", SrcPodLabels: map[string]string{"app": "api"},
+				DstIP: "203.0.113.10", DstIdentity: localIdentityFlag + 5,
+				DstPort: 443, Protocol: "TCP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "ALLOWED",
+		},
+		{
+			name:   "toCIDRSet except denies address",
+			policy: worldEgress,
+			event: models.TelemetryEvent{
+				SrcNamespace: "shop", SrcPodLabels: map[string]string{"app": "api"},
+				DstIP: "203.0.113.200", DstIdentity: identityWorld,
+				DstPort: 443, Protocol: "TCP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "DENIED",
+		},
+		{
+			name:   "toEntities kube-apiserver",
+			policy: worldEgress,
+			event: models.TelemetryEvent{
+				SrcNamespace: "shop", SrcPodLabels: map[string]string{"app": "api"},
+				DstIP: "172.20.0.1", DstPodLabels: map[string]string{"kube-apiserver": ""},
+				DstPort: 443, Protocol: "TCP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "ALLOWED",
+		},
+		{
+			name:   "toServices by service DNS name",
+			policy: worldEgress,
+			event: models.TelemetryEvent{
+				SrcNamespace: "shop", SrcPodLabels: map[string]string{"app": "api"},
+				DstIP: "192.0.2.10", DstIdentity: identityWorld,
+				DstDNSName: "payments.billing.svc.cluster.local",
+				DstPort: 8443, Protocol: "TCP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "ALLOWED",
+		},
+		{
+			name:   "icmps allows ICMPv4 to the world",
+			policy: worldEgress,
+			event: models.TelemetryEvent{
+				SrcNamespace: "shop", SrcPodLabels: map[string]string{"app": "api"},
+				DstIP: "198.51.100.7", DstIdentity: identityWorld,
+				Protocol: "ICMPv4", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "ALLOWED",
+		},
+		{
+			name:   "icmps does not allow ICMPv6",
+			policy: worldEgress,
+			event: models.TelemetryEvent{
+				SrcNamespace: "shop", SrcPodLabels: map[string]string{"app": "api"},
+				DstIP: "2001:db8::1", DstIdentity: identityWorldIPv6,
+				Protocol: "ICMPv6", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "DENIED",
+		},
+		{
+			name:       "fromRequires allows prod peer",
+			policy:     requiresIngress,
+			policyType: "CILIUM_CLUSTERWIDE",
+			event: models.TelemetryEvent{
+				SrcNamespace: "a", SrcPodLabels: map[string]string{"env": "prod"},
+				DstNamespace: "b", DstPodLabels: map[string]string{"env": "prod"},
+				DstPort: 80, Protocol: "TCP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "ALLOWED",
+		},
+		{
+			name:       "fromRequires denies non-prod cluster peer",
+			policy:     requiresIngress,
+			policyType: "CILIUM_CLUSTERWIDE",
+			event: models.TelemetryEvent{
+				SrcNamespace: "a", SrcPodLabels: map[string]string{"env": "staging"},
+				DstNamespace: "b", DstPodLabels: map[string]string{"env": "prod"},
+				DstPort: 80, Protocol: "TCP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "DENIED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policyType := tt.policyType
+			if policyType == "" {
+				policyType = "CILIUM_NETWORK"
+			}
+			policy, err := engine.parser.Parse(tt.policy, policyType)
+			if err != nil {
+				t.Fatalf("Parse() error: %v", err)
+			}
+
+			result := engine.evaluateFlow(&tt.event, policy)
+			if result.SimulatedVerdict != tt.wantVerdict {
+				t.Errorf("evaluateFlow() verdict = %v, want %v (reason: %s, rule: %s)",
+					result.SimulatedVerdict, tt.wantVerdict, result.MatchReason, result.MatchedRule)
+			}
+		})
+	}
+}
+
+func TestEngine_EvaluateFlow_DenyPrecedence(t *testing.T) {
+	engine := &Engine{
+		parser: NewPolicyParser(),
+		log:    logr.Discard(),
+	}
+
+	// The allow-all rule comes first: deny rules must still win.
+	const lockdown = `
+apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: lockdown
+  namespace: prod
+spec:
+  endpointSelector:
+    matchLabels:
+      app: api
+  ingress:
+    - fromEntities:
+        - all
+  ingressDeny:
+    - fromEndpoints:
+        - matchLabels:
+            app: legacy
+    - fromEntities:
+        - world
+      toPorts:
+        - ports:
+            - port: "22"
+              protocol: TCP
+  egress:
+    - toEntities:
+        - all
+  egressDeny:
+    - toCIDR:
+        - 169.254.169.254/32
+    - toEndpoints:
+        - matchLabels:
+            role: admin
+`
+
+	const denyOnly = `
+apiVersion: cilium.io/v2
+kind: CiliumClusterwideNetworkPolicy
+metadata:
+  name: block-metadata
+spec:
+  endpointSelector: {}
+  enableDefaultDeny:
+    egress: false
+  egressDeny:
+    - toCIDR:
+        - 169.254.169.254/32
+`
+
+	tests := []struct {
+		name        string
+		policy      string
+		event       models.TelemetryEvent
+		wantVerdict string
+		wantReason  string
+	}{
+		{
+			name:   "allow-all rule allows ordinary peer",
+			policy: lockdown,
+			event: models.TelemetryEvent{
+				SrcNamespace: "prod", SrcPodLabels: map[string]string{"app": "web"},
+				DstNamespace: "prod", DstPodLabels: map[string]string{"app": "api"},
+				DstPort: 8080, Protocol: "TCP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "ALLOWED",
+			wantReason:  "Matched ingress allow rule",
+		},
+		{
+			name:   "deny endpoint beats earlier allow-all",
+			policy: lockdown,
+			event: models.TelemetryEvent{
+				SrcNamespace: "prod", SrcPodLabels: map[string]string{"app": "legacy"},
+				DstNamespace: "prod", DstPodLabels: map[string]string{"app": "api"},
+				DstPort: 8080, Protocol: "TCP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "DENIED",
+			wantReason:  "Matched ingress deny rule",
+		},
+		{
+			name:   "replies are not subject to policy",
+			policy: lockdown,
+			event: models.TelemetryEvent{
+				SrcNamespace: "prod", SrcPodLabels: map[string]string{"app": "legacy"},
+				DstNamespace: "prod", DstPodLabels: map[string]string{"app": "api"},
+				DstPort: 41234, Protocol: "TCP", Verdict: models.VerdictAllowed, IsReply: true,
+			},
+			wantVerdict: "ALLOWED",
+			wantReason:  "Reply traffic is not subject to policy",
+		},
+		{
+			name:   "deny only applies to its ports",
+			policy: lockdown,
+			event: models.TelemetryEvent{
+				SrcIP: "198.51.100.1", SrcIdentity: identityWorld,
+				DstNamespace: "prod", DstPodLabels: map[string]string{"app": "api"},
+				DstPort: 443, Protocol: "TCP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "ALLOWED",
+		},
+		{
+			name:   "world SSH denied",
+			policy: lockdown,
+			event: models.TelemetryEvent{
+				SrcIP: "198.51.100.1", SrcIdentity: identityWorld,
+				DstNamespace: "prod", DstPodLabels: map[string]string{"app": "api"},
+				DstPort: 22, Protocol: "TCP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "DENIED",
+			wantReason:  "Matched ingress deny rule",
+		},
+		{
+			name:   "egress deny to metadata service",
+			policy: lockdown,
+			event: models.TelemetryEvent{
+				SrcNamespace: "prod", SrcPodLabels: map[string]string{"app": "api"},
+				DstIP: "169.254.169.254", DstIdentity: identityWorld,
+				DstPort: 80, Protocol: "TCP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "DENIED",
+			wantReason:  "Matched egress deny rule",
+		},
+		{
+			name:   "source egress deny wins over destination ingress allow",
+			policy: lockdown,
+			event: models.TelemetryEvent{
+				SrcNamespace: "prod", SrcPodLabels: map[string]string{"app": "api"},
+				DstNamespace: "prod", DstPodLabels: map[string]string{"app": "api", "role": "admin"},
+				DstPort: 8080, Protocol: "TCP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "DENIED",
+			wantReason:  "Matched egress deny rule",
+		},
+		{
+			name:   "deny-only policy without default deny denies matches",
+			policy: denyOnly,
+			event: models.TelemetryEvent{
+				SrcNamespace: "prod", SrcPodLabels: map[string]string{"app": "api"},
+				DstIP: "169.254.169.254", DstIdentity: identityWorld,
+				DstPort: 80, Protocol: "TCP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "DENIED",
+		},
+		{
+			name:   "deny-only policy without default deny leaves other traffic alone",
+			policy: denyOnly,
+			event: models.TelemetryEvent{
+				SrcNamespace: "prod", SrcPodLabels: map[string]string{"app": "api"},
+				DstIP: "8.8.8.8", DstIdentity: identityWorld,
+				DstPort: 53, Protocol: "UDP", Verdict: models.VerdictAllowed,
+			},
+			wantVerdict: "ALLOWED",
+			wantReason:  "Policy does not apply to this flow",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := engine.parser.Parse(tt.policy, "CILIUM_NETWORK")
+			if err != nil {
+				t.Fatalf("Parse() error: %v", err)
+			}
+
+			result := engine.evaluateFlow(&tt.event, policy)
+			if result.SimulatedVerdict != tt.wantVerdict {
+				t.Errorf("evaluateFlow() verdict = %v, want %v (reason: %s)",
+					result.SimulatedVerdict, tt.wantVerdict, result.MatchReason)
+			}
+			if tt.wantReason != "" && result.MatchReason != tt.wantReason {
+				t.Errorf("evaluateFlow() reason = %q, want %q", result.MatchReason, tt.wantReason)
+			}
+		})
+	}
+}
@@ -18,18 +18,27 @@ func NewPolicyParser() *PolicyParser {
 
 // Parse parses a policy YAML string into a ParsedPolicy.
 func (p *PolicyParser) Parse(content string, policyType string) (*ParsedPolicy, error) {
+	return p.ParseInNamespace(content, policyType, "")
+}
+
+// ParseInNamespace parses a policy like Parse, placing namespaced policies
+// without a metadata namespace in the given namespace, as the API server does
+// on create.
+func (p *PolicyParser) ParseInNamespace(content string, policyType string, namespace string) (*ParsedPolicy, error) {
 	switch policyType {
 	case "CILIUM_NETWORK", "CILIUM_CLUSTERWIDE":
-		return p.parseCiliumPolicy(content)
+		return p.parseCiliumPolicy(content, namespace)
 	case "TETRAGON":
 		return p.parseTetragonPolicy(content)
+	case "GATEWAY_HTTPROUTE", "GATEWAY_GRPCROUTE":
+		return p.parseGatewayRoute(content, policyType, namespace)
 	default:
 		return nil, fmt.Errorf("unsupported policy type: %s", policyType)
 	}
 }
 
 // parseCiliumPolicy parses a Cilium NetworkPolicy or CiliumNetworkPolicy.
-func (p *PolicyParser) parseCiliumPolicy(content string) (*ParsedPolicy, error) {
+func (p *PolicyParser) parseCiliumPolicy(content string, defaultNamespace string) (*ParsedPolicy, error) {
 	// Parse YAML into generic map
 	var raw map[string]interface{}
 	if err := yaml.Unmarshal([]byte(content), &raw); err != nil {
@@ -37,6 +46,7 @@ func (p *PolicyParser) parseCiliumPolicy(content string) (*ParsedPolicy, error)
 	}
 
 	policy := &ParsedPolicy{
+		Namespace:    defaultNamespace,
 		PodSelector:  make(map[string]string),
 		IngressRules: []PolicyRule{},
 		EgressRules:  []PolicyRule{},
@@ -47,7 +57,7 @@ func (p *PolicyParser) parseCiliumPolicy(content string) (*ParsedPolicy, error)
 		if name, ok := metadata["name"].(string); ok {
 			policy.Name = name
 		}
-		if ns, ok := metadata["namespace"].(string); ok {
+		if ns, ok := metadata["namespace"].(string); ok && ns != "" {
 			policy.Namespace = ns
 		}
 	}
@@ -65,21 +75,27 @@ func (p *PolicyParser) parseCiliumPolicy(content string) (*ParsedPolicy, error)
 
 	// Parse endpoint selector (pod selector)
 	if endpointSelector, ok := spec["endpointSelector"].(map[string]interface{}); ok {
-		if matchLabels, ok := endpointSelector["matchLabels"].(map[string]interface{}); ok {
-			for k, v := range matchLabels {
-				if vs, ok := v.(string); ok {
-					policy.PodSelector[k] = vs
-				}
-			}
+		sel := parseEndpointSelector(endpointSelector)
+		for k, v := range sel.MatchLabels {
+			policy.PodSelector[k] = v
 		}
+		policy.PodSelectorExpressions = sel.MatchExpressions
 	}
 
+	// Peer endpoint selectors of a namespaced policy are implicitly scoped to
+	// the policy's namespace; clusterwide policies have no such scope.
+	if policy.Type == "CiliumClusterwideNetworkPolicy" {
+		policy.Namespace = ""
+	}
+	scope := policy.Namespace
+
 	// Parse ingress rules
 	if ingress, ok := spec["ingress"].([]interface{}); ok {
 		for _, rule := range ingress {
 			if ruleMap, ok := rule.(map[string]interface{}); ok {
-				parsed := p.parseCiliumRule(ruleMap, "ingress")
+				parsed, requires := p.parseCiliumRule(ruleMap, "ingress", scope)
 				policy.IngressRules = append(policy.IngressRules, parsed...)
+				policy.IngressRequires = append(policy.IngressRequires, requires...)
 			}
 		}
 	}
@@ -88,19 +104,56 @@ func (p *PolicyParser) parseCiliumPolicy(content string) (*ParsedPolicy, error)
 	if egress, ok := spec["egress"].([]interface{}); ok {
 		for _, rule := range egress {
 			if ruleMap, ok := rule.(map[string]interface{}); ok {
-				parsed := p.parseCiliumRule(ruleMap, "egress")
+				parsed, requires := p.parseCiliumRule(ruleMap, "egress", scope)
+				policy.EgressRules = append(policy.EgressRules, parsed...)
+				policy.EgressRequires = append(policy.EgressRequires, requires...)
+			}
+		}
+	}
+
+	// Parse ingressDeny/egressDeny rules. Cilium does not support L7 rules or
+	// requirements on deny rules, so those parts are dropped.
+	for _, direction := range []string{"ingress", "egress"} {
+		denyRules, ok := spec[direction+"Deny"].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, rule := range denyRules {
+			ruleMap, ok := rule.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			parsed, _ := p.parseCiliumRule(ruleMap, direction, scope)
+			for i := range parsed {
+				parsed[i].Action = "deny"
+				parsed[i].L7Rules = nil
+			}
+			if direction == "ingress" {
+				policy.IngressRules = append(policy.IngressRules, parsed...)
+			} else {
 				policy.EgressRules = append(policy.EgressRules, parsed...)
 			}
 		}
 	}
 
 	// Check for default deny
-	// In Cilium, having ingress/egress sections implies default deny for that direction
-	if len(policy.IngressRules) > 0 || spec["ingress"] != nil {
+	// In Cilium, having ingress/egress (or ingressDeny/egressDeny) sections implies
+	// default deny for that direction, unless overridden through enableDefaultDeny
+	ingressDefaultDeny := len(policy.IngressRules) > 0 || spec["ingress"] != nil || spec["ingressDeny"] != nil
+	egressDefaultDeny := len(policy.EgressRules) > 0 || spec["egress"] != nil || spec["egressDeny"] != nil
+	if enableDefaultDeny, ok := spec["enableDefaultDeny"].(map[string]interface{}); ok {
+		if enabled, ok := enableDefaultDeny["ingress"].(bool); ok {
+			ingressDefaultDeny = enabled
+		}
+		if enabled, ok := enableDefaultDeny["egress"].(bool); ok {
+			egressDefaultDeny = enabled
+		}
+	}
+	if ingressDefaultDeny {
 		policy.DefaultDeny = true
 		policy.DefaultDenyType = "ingress"
 	}
-	if len(policy.EgressRules) > 0 || spec["egress"] != nil {
+	if egressDefaultDeny {
 		policy.DefaultDeny = true
 		if policy.DefaultDenyType == "ingress" {
 			policy.DefaultDenyType = "both"
@@ -113,7 +166,13 @@ func (p *PolicyParser) parseCiliumPolicy(content string) (*ParsedPolicy, error)
 }
 
 // parseCiliumRule parses a single Cilium ingress/egress rule.
-func (p *PolicyParser) parseCiliumRule(rule map[string]interface{}, direction string) []PolicyRule {
+//
+// A Cilium rule may list several kinds of peers (endpoints, CIDRs, entities,
+// services, FQDNs); traffic matches if it matches any of them. Each peer
+// selector is therefore expanded into its own PolicyRule carrying the
+// shared L4/L7 constraints. fromRequires/toRequires selectors are returned
+// separately because Cilium applies them to the whole policy direction.
+func (p *PolicyParser) parseCiliumRule(rule map[string]interface{}, direction string, namespace string) ([]PolicyRule, []EndpointSelector) {
 	var rules []PolicyRule
 
 	baseRule := PolicyRule{
@@ -121,115 +180,113 @@ func (p *PolicyParser) parseCiliumRule(rule map[string]interface{}, direction st
 		Action:    "allow", // Cilium policies are allow rules
 	}
 
-	// Parse fromEndpoints/toEndpoints
-	endpointKey := "fromEndpoints"
+	prefix := "from"
 	if direction == "egress" {
-		endpointKey = "toEndpoints"
+		prefix = "to"
 	}
 
-	if endpoints, ok := rule[endpointKey].([]interface{}); ok {
+	// Parse fromEndpoints/toEndpoints
+	if endpoints, ok := rule[prefix+"Endpoints"].([]interface{}); ok {
 		for _, ep := range endpoints {
 			if epMap, ok := ep.(map[string]interface{}); ok {
 				r := baseRule
+				r.Endpoints = true
 				r.PodSelector = make(map[string]string)
 				r.NamespaceSelector = make(map[string]string)
 
-				if matchLabels, ok := epMap["matchLabels"].(map[string]interface{}); ok {
-					for k, v := range matchLabels {
-						if vs, ok := v.(string); ok {
-							// Cilium uses k8s: prefix for namespace labels
-							if strings.HasPrefix(k, "k8s:io.kubernetes.pod.namespace") {
-								r.NamespaceSelector["name"] = vs
-							} else {
-								r.PodSelector[k] = vs
-							}
-						}
+				sel := parseEndpointSelector(epMap)
+				for k, v := range sel.MatchLabels {
+					// Cilium uses the pod namespace label to select namespaces
+					if k == namespaceLabel {
+						r.NamespaceSelector["name"] = v
+					} else {
+						r.PodSelector[k] = v
 					}
 				}
+				r.MatchExpressions = sel.MatchExpressions
+
+				// Selectors on reserved labels (e.g. reserved:host) are never namespaced
+				if namespace != "" && !selectsNamespace(sel) && !hasReservedKey(epMap) {
+					r.NamespaceSelector["name"] = namespace
+				}
 				rules = append(rules, r)
 			}
 		}
 	}
 
-	// Parse toPorts
-	if toPorts, ok := rule["toPorts"].([]interface{}); ok {
-		for _, tp := range toPorts {
-			if tpMap, ok := tp.(map[string]interface{}); ok {
-				if ports, ok := tpMap["ports"].([]interface{}); ok {
-					for _, port := range ports {
-						if portMap, ok := port.(map[string]interface{}); ok {
-							pr := PortRule{
-								Protocol: "TCP", // default
-							}
-							if portVal, ok := portMap["port"].(string); ok {
-								if p, err := strconv.ParseUint(portVal, 10, 32); err == nil {
-									pr.Port = uint32(p)
-								}
-							}
-							if proto, ok := portMap["protocol"].(string); ok {
-								pr.Protocol = strings.ToUpper(proto)
-							}
-
-							// Add port rule to all existing rules or create new one
-							if len(rules) == 0 {
-								r := baseRule
-								r.ToPorts = []PortRule{pr}
-								rules = append(rules, r)
-							} else {
-								for i := range rules {
-									rules[i].ToPorts = append(rules[i].ToPorts, pr)
-								}
-							}
-						}
+	// Parse fromCIDR/toCIDR and fromCIDRSet/toCIDRSet
+	var cidrList []string
+	if cidrs, ok := rule[prefix+"CIDR"].([]interface{}); ok {
+		for _, cidr := range cidrs {
+			if cidrStr, ok := cidr.(string); ok {
+				cidrList = append(cidrList, cidrStr)
+			}
+		}
+	}
+	var cidrSet []CIDRRule
+	if set, ok := rule[prefix+"CIDRSet"].([]interface{}); ok {
+		for _, entry := range set {
+			entryMap, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			cidr, ok := entryMap["cidr"].(string)
+			if !ok {
+				continue
+			}
+			cr := CIDRRule{CIDR: cidr}
+			if except, ok := entryMap["except"].([]interface{}); ok {
+				for _, ex := range except {
+					if exStr, ok := ex.(string); ok {
+						cr.Except = append(cr.Except, exStr)
 					}
 				}
+			}
+			cidrSet = append(cidrSet, cr)
+		}
+	}
+	if len(cidrList) > 0 || len(cidrSet) > 0 {
+		r := baseRule
+		if direction == "egress" {
+			r.ToCIDRs = cidrList
+			r.ToCIDRSet = cidrSet
+		} else {
+			r.FromCIDRs = cidrList
+			r.FromCIDRSet = cidrSet
+		}
+		rules = append(rules, r)
+	}
 
-				// Parse L7 rules
-				if l7Rules, ok := tpMap["rules"].(map[string]interface{}); ok {
-					l7Parsed := p.parseL7Rules(l7Rules)
-					if len(rules) == 0 {
-						r := baseRule
-						r.L7Rules = l7Parsed
-						rules = append(rules, r)
-					} else {
-						for i := range rules {
-							rules[i].L7Rules = append(rules[i].L7Rules, l7Parsed...)
-						}
-					}
-				}
+	// Parse fromEntities/toEntities
+	if entities, ok := rule[prefix+"Entities"].([]interface{}); ok {
+		var entityList []string
+		for _, entity := range entities {
+			if entityStr, ok := entity.(string); ok {
+				entityList = append(entityList, strings.ToLower(entityStr))
 			}
 		}
+		if len(entityList) > 0 {
+			r := baseRule
+			r.Entities = entityList
+			rules = append(rules, r)
+		}
 	}
 
-	// Parse toCIDR/fromCIDR
-	cidrKey := "fromCIDR"
+	// Parse toServices
 	if direction == "egress" {
-		cidrKey = "toCIDR"
-	}
-	if cidrs, ok := rule[cidrKey].([]interface{}); ok {
-		var cidrList []string
-		for _, cidr := range cidrs {
-			if cidrStr, ok := cidr.(string); ok {
-				cidrList = append(cidrList, cidrStr)
+		if services, ok := rule["toServices"].([]interface{}); ok {
+			var serviceList []ServiceRule
+			for _, svc := range services {
+				if svcMap, ok := svc.(map[string]interface{}); ok {
+					if sr, ok := parseServiceRule(svcMap, namespace); ok {
+						serviceList = append(serviceList, sr)
+					}
+				}
 			}
-		}
-		if len(cidrList) > 0 {
-			if len(rules) == 0 {
+			if len(serviceList) > 0 {
 				r := baseRule
-				if direction == "egress" {
-					r.ToCIDRs = cidrList
-				} else {
-					r.FromCIDRs = cidrList
-				}
+				r.ToServices = serviceList
 				rules = append(rules, r)
-			} else {
-				for i := range rules {
-					if direction == "egress" {
-						rules[i].ToCIDRs = append(rules[i].ToCIDRs, cidrList...)
-					} else {
-						rules[i].FromCIDRs = append(rules[i].FromCIDRs, cidrList...)
-					}
-				}
 			}
 		}
 	}
@@ -248,24 +305,259 @@ func (p *PolicyParser) parseCiliumRule(rule map[string]interface{}, direction st
 			}
 		}
 		if len(fqdnList) > 0 {
-			if len(rules) == 0 {
-				r := baseRule
-				r.ToFQDNs = fqdnList
-				rules = append(rules, r)
-			} else {
-				for i := range rules {
-					rules[i].ToFQDNs = append(rules[i].ToFQDNs, fqdnList...)
+			r := baseRule
+			r.ToFQDNs = fqdnList
+			rules = append(rules, r)
+		}
+	}
+
+	// Parse fromRequires/toRequires
+	var requires []EndpointSelector
+	if reqs, ok := rule[prefix+"Requires"].([]interface{}); ok {
+		for _, req := range reqs {
+			if reqMap, ok := req.(map[string]interface{}); ok {
+				sel := parseEndpointSelector(reqMap)
+				if namespace != "" && !selectsNamespace(sel) {
+					if sel.MatchLabels == nil {
+						sel.MatchLabels = make(map[string]string)
+					}
+					sel.MatchLabels[namespaceLabel] = namespace
+				}
+				requires = append(requires, sel)
+			}
+		}
+	}
+
+	// Parse toPorts and icmps (L4), which apply to every peer selector above
+	var ports []PortRule
+	var l7 []L7Rule
+	if toPorts, ok := rule["toPorts"].([]interface{}); ok {
+		for _, tp := range toPorts {
+			if tpMap, ok := tp.(map[string]interface{}); ok {
+				if portList, ok := tpMap["ports"].([]interface{}); ok {
+					for _, port := range portList {
+						if portMap, ok := port.(map[string]interface{}); ok {
+							ports = append(ports, parsePortRule(portMap))
+						}
+					}
+				}
+
+				// Parse L7 rules
+				if l7Rules, ok := tpMap["rules"].(map[string]interface{}); ok {
+					l7 = append(l7, p.parseL7Rules(l7Rules)...)
+				}
+			}
+		}
+	}
+	var icmps []ICMPRule
+	if icmpList, ok := rule["icmps"].([]interface{}); ok {
+		for _, entry := range icmpList {
+			entryMap, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			fields, ok := entryMap["fields"].([]interface{})
+			if !ok {
+				continue
+			}
+			for _, field := range fields {
+				if fieldMap, ok := field.(map[string]interface{}); ok {
+					ir := ICMPRule{Family: "IPv4"}
+					if family, ok := fieldMap["family"].(string); ok && family != "" {
+						ir.Family = family
+					}
+					if t, ok := stringValue(fieldMap["type"]); ok {
+						ir.Type = t
+					}
+					icmps = append(icmps, ir)
 				}
 			}
 		}
 	}
 
-	// If no specific rules were parsed, add the base rule (allows all matching traffic)
+	// A rule that only carries requirements selects no peers by itself
+	if len(rules) == 0 && len(requires) > 0 && len(ports) == 0 && len(icmps) == 0 {
+		return nil, requires
+	}
+
+	// If no peer selector was parsed, the base rule allows all peers
 	if len(rules) == 0 {
 		rules = append(rules, baseRule)
 	}
 
-	return rules
+	for i := range rules {
+		if len(ports) > 0 {
+			rules[i].ToPorts = append([]PortRule(nil), ports...)
+		}
+		if len(l7) > 0 {
+			rules[i].L7Rules = append([]L7Rule(nil), l7...)
+		}
+		if len(icmps) > 0 {
+			rules[i].ICMPs = append([]ICMPRule(nil), icmps...)
+		}
+	}
+
+	return rules, requires
+}
+
+// parseEndpointSelector parses a label selector with matchLabels and
+// matchExpressions. Label keys are normalized by stripping Cilium source
+// prefixes (k8s:, any:, ...), matching the labels recorded on flows.
+func parseEndpointSelector(sel map[string]interface{}) EndpointSelector {
+	result := EndpointSelector{}
+
+	if matchLabels, ok := sel["matchLabels"].(map[string]interface{}); ok {
+		result.MatchLabels = make(map[string]string, len(matchLabels))
+		for k, v := range matchLabels {
+			if vs, ok := stringValue(v); ok {
+				result.MatchLabels[normalizeLabelKey(k)] = vs
+			}
+		}
+	}
+
+	if exprs, ok := sel["matchExpressions"].([]interface{}); ok {
+		for _, expr := range exprs {
+			exprMap, ok := expr.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			key, _ := exprMap["key"].(string)
+			op, _ := exprMap["operator"].(string)
+			if key == "" || op == "" {
+				continue
+			}
+			req := SelectorRequirement{Key: normalizeLabelKey(key), Operator: op}
+			if values, ok := exprMap["values"].([]interface{}); ok {
+				for _, v := range values {
+					if vs, ok := stringValue(v); ok {
+						req.Values = append(req.Values, vs)
+					}
+				}
+			}
+			result.MatchExpressions = append(result.MatchExpressions, req)
+		}
+	}
+
+	return result
+}
+
+// parseServiceRule parses a toServices entry (k8sService or k8sServiceSelector).
+func parseServiceRule(svc map[string]interface{}, namespace string) (ServiceRule, bool) {
+	if k8sSvc, ok := svc["k8sService"].(map[string]interface{}); ok {
+		sr := ServiceRule{Namespace: namespace}
+		sr.Name, _ = k8sSvc["serviceName"].(string)
+		if ns, ok := k8sSvc["namespace"].(string); ok && ns != "" {
+			sr.Namespace = ns
+		}
+		return sr, sr.Name != ""
+	}
+
+	if k8sSel, ok := svc["k8sServiceSelector"].(map[string]interface{}); ok {
+		sr := ServiceRule{Namespace: namespace, Selector: make(map[string]string)}
+		if ns, ok := k8sSel["namespace"].(string); ok && ns != "" {
+			sr.Namespace = ns
+		}
+		if selMap, ok := k8sSel["selector"].(map[string]interface{}); ok {
+			sel := parseEndpointSelector(selMap)
+			for k, v := range sel.MatchLabels {
+				sr.Selector[k] = v
+			}
+		}
+		return sr, true
+	}
+
+	return ServiceRule{}, false
+}
+
+// parsePortRule parses a single toPorts port entry. Ports may be given as
+// strings or numbers; an omitted protocol means ANY, as in Cilium.
+func parsePortRule(portMap map[string]interface{}) PortRule {
+	pr := PortRule{
+		Protocol: "ANY",
+	}
+	if portVal, ok := stringValue(portMap["port"]); ok {
+		if p, err := strconv.ParseUint(portVal, 10, 32); err == nil {
+			pr.Port = uint32(p)
+		}
+	}
+	if endPortVal, ok := stringValue(portMap["endPort"]); ok {
+		if p, err := strconv.ParseUint(endPortVal, 10, 32); err == nil {
+			pr.EndPort = uint32(p)
+		}
+	}
+	if proto, ok := portMap["protocol"].(string); ok && proto != "" {
+		pr.Protocol = strings.ToUpper(proto)
+	}
+	return pr
+}
+
+// namespaceLabel is the label Cilium attaches to endpoints with their namespace.
+const namespaceLabel = "io.kubernetes.pod.namespace"
+
+// normalizeLabelKey strips Cilium label source prefixes from a selector key.
+func normalizeLabelKey(key string) string {
+	for _, prefix := range []string{"any:", "k8s:", "reserved:", "container:"} {
+		if strings.HasPrefix(key, prefix) {
+			return strings.TrimPrefix(key, prefix)
+		}
+	}
+	return key
+}
+
+// selectsNamespace reports whether a selector constrains the pod namespace label.
+func selectsNamespace(sel EndpointSelector) bool {
+	if _, ok := sel.MatchLabels[namespaceLabel]; ok {
+		return true
+	}
+	for _, expr := range sel.MatchExpressions {
+		if expr.Key == namespaceLabel {
+			return true
+		}
+	}
+	return false
+}
+
+// hasReservedKey reports whether a raw selector uses reserved: labels.
+func hasReservedKey(sel map[string]interface{}) bool {
+	if matchLabels, ok := sel["matchLabels"].(map[string]interface{}); ok {
+		for k := range matchLabels {
+			if strings.HasPrefix(k, "reserved:") {
+				return true
+			}
+		}
+	}
+	if exprs, ok := sel["matchExpressions"].([]interface{}); ok {
+		for _, expr := range exprs {
+			if exprMap, ok := expr.(map[string]interface{}); ok {
+				if key, ok := exprMap["key"].(string); ok && strings.HasPrefix(key, "reserved:") {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// stringValue converts a scalar YAML value to a string. YAML is decoded via
+// JSON, so unquoted numbers arrive as float64 and booleans as bool.
+func stringValue(v interface{}) (string, bool) {
+	switch val := v.(type) {
+	case string:
+		return val, true
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(val), true
+	default:
+		return "", false
+	}
+}
+
+// kafkaRoleAPIKeys lists the Kafka API keys allowed by each Cilium role.
+var kafkaRoleAPIKeys = map[string][]string{
+	"produce": {"produce", "metadata", "apiversions"},
+	"consume": {"fetch", "offsets", "metadata", "offsetcommit", "offsetfetch",
+		"findcoordinator", "joingroup", "heartbeat", "leavegroup", "syncgroup", "apiversions"},
 }
 
 // parseL7Rules parses L7-specific rules.
@@ -286,6 +578,7 @@ func (p *PolicyParser) parseL7Rules(rules map[string]interface{}) []L7Rule {
 				if host, ok := hrMap["host"].(string); ok {
 					l7.Host = host
 				}
+				l7.Headers = parseHeaderMatches(hrMap)
 				l7Rules = append(l7Rules, l7)
 			}
 		}
@@ -307,12 +600,65 @@ func (p *PolicyParser) parseL7Rules(rules map[string]interface{}) []L7Rule {
 		}
 	}
 
+	// Kafka rules
+	if kafkaRules, ok := rules["kafka"].([]interface{}); ok {
+		for _, kr := range kafkaRules {
+			if krMap, ok := kr.(map[string]interface{}); ok {
+				l7 := L7Rule{Type: "kafka"}
+				if topic, ok := krMap["topic"].(string); ok {
+					l7.Topic = topic
+				}
+				if role, ok := krMap["role"].(string); ok && role != "" {
+					l7.APIKeys = kafkaRoleAPIKeys[strings.ToLower(role)]
+				} else if apiKey, ok := krMap["apiKey"].(string); ok && apiKey != "" {
+					l7.APIKeys = []string{strings.ToLower(apiKey)}
+				}
+				if version, ok := stringValue(krMap["apiVersion"]); ok {
+					l7.APIVersion = version
+				}
+				if clientID, ok := krMap["clientID"].(string); ok {
+					l7.ClientID = clientID
+				}
+				l7Rules = append(l7Rules, l7)
+			}
+		}
+	}
+
 	return l7Rules
 }
 
-// parseTetragonPolicy parses a Tetragon TracingPolicy.
+// parseHeaderMatches parses the headers and headerMatches of an HTTP rule.
+// headers entries are "Name" or "Name: value". headerMatches entries with a
+// mismatch action never reject a request and are skipped; secret values
+// cannot be read, so those only require the header to be present.
+func parseHeaderMatches(rule map[string]interface{}) []HeaderMatch {
+	var headers []HeaderMatch
+	for _, h := range stringList(rule["headers"]) {
+		name, value, _ := strings.Cut(h, ":")
+		headers = append(headers, HeaderMatch{
+			Name:  strings.TrimSpace(name),
+			Value: strings.TrimSpace(value),
+		})
+	}
+	for _, hm := range mapList(rule["headerMatches"]) {
+		if mismatch, ok := hm["mismatch"].(string); ok && mismatch != "" {
+			continue
+		}
+		name, ok := hm["name"].(string)
+		if !ok || name == "" {
+			continue
+		}
+		header := HeaderMatch{Name: name}
+		if _, secret := hm["secret"]; !secret {
+			header.Value, _ = hm["value"].(string)
+		}
+		headers = append(headers, header)
+	}
+	return headers
+}
+
+// parseTetragonPolicy parses a Tetragon TracingPolicy or TracingPolicyNamespaced.
 func (p *PolicyParser) parseTetragonPolicy(content string) (*ParsedPolicy, error) {
-	// For now, return a basic parsed policy for Tetragon
 	// Tetragon policies are about process/syscall tracing, not network
 	var raw map[string]interface{}
 	if err := yaml.Unmarshal([]byte(content), &raw); err != nil {
@@ -323,15 +669,316 @@ func (p *PolicyParser) parseTetragonPolicy(content string) (*ParsedPolicy, error
 		PodSelector: make(map[string]string),
 	}
 
+	if kind, ok := raw["kind"].(string); ok {
+		policy.Type = kind
+	}
+
 	if metadata, ok := raw["metadata"].(map[string]interface{}); ok {
 		if name, ok := metadata["name"].(string); ok {
 			policy.Name = name
 		}
+		// Only namespaced tracing policies are scoped to their namespace
+		if ns, ok := metadata["namespace"].(string); ok && policy.Type == "TracingPolicyNamespaced" {
+			policy.Namespace = ns
+		}
+	}
+
+	spec, ok := raw["spec"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("missing spec in policy")
+	}
+
+	if podSelector, ok := spec["podSelector"].(map[string]interface{}); ok {
+		sel := parseEndpointSelector(podSelector)
+		for k, v := range sel.MatchLabels {
+			policy.PodSelector[k] = v
+		}
+		policy.PodSelectorExpressions = sel.MatchExpressions
+	}
+
+	if kprobes, ok := spec["kprobes"].([]interface{}); ok {
+		for _, kp := range kprobes {
+			kpMap, ok := kp.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			call, _ := kpMap["call"].(string)
+			if call == "" {
+				continue
+			}
+			hook := parseTracingHook(kpMap)
+			hook.Type = "kprobe"
+			hook.Call = call
+			hook.Syscall, _ = kpMap["syscall"].(bool)
+			policy.Hooks = append(policy.Hooks, hook)
+		}
+	}
+
+	if tracepoints, ok := spec["tracepoints"].([]interface{}); ok {
+		for _, tp := range tracepoints {
+			tpMap, ok := tp.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			subsystem, _ := tpMap["subsystem"].(string)
+			event, _ := tpMap["event"].(string)
+			if subsystem == "" || event == "" {
+				continue
+			}
+			hook := parseTracingHook(tpMap)
+			hook.Type = "tracepoint"
+			hook.Call = subsystem + "/" + event
+			policy.Hooks = append(policy.Hooks, hook)
+		}
+	}
+
+	if len(policy.Hooks) == 0 {
+		return nil, fmt.Errorf("tracing policy has no kprobes or tracepoints")
+	}
+
+	return policy, nil
+}
+
+// parseTracingHook parses the args and selectors shared by kprobes and tracepoints.
+func parseTracingHook(hookMap map[string]interface{}) TracingHook {
+	var hook TracingHook
+
+	if args, ok := hookMap["args"].([]interface{}); ok {
+		for _, a := range args {
+			argMap, ok := a.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			index, _ := argMap["index"].(float64)
+			argType, _ := argMap["type"].(string)
+			hook.Args = append(hook.Args, TracingArg{Index: int(index), Type: argType})
+		}
+	}
+
+	selectors, _ := hookMap["selectors"].([]interface{})
+	for _, s := range selectors {
+		selMap, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var sel TracingSelector
+
+		for _, m := range mapList(selMap["matchBinaries"]) {
+			op, _ := m["operator"].(string)
+			sel.MatchBinaries = append(sel.MatchBinaries, TracingValueMatch{
+				Operator: op,
+				Values:   stringList(m["values"]),
+			})
+		}
+		for _, m := range mapList(selMap["matchArgs"]) {
+			index, _ := m["index"].(float64)
+			op, _ := m["operator"].(string)
+			sel.MatchArgs = append(sel.MatchArgs, TracingArgMatch{
+				Index:    int(index),
+				Operator: op,
+				Values:   stringList(m["values"]),
+			})
+		}
+		for _, m := range mapList(selMap["matchNamespaces"]) {
+			ns, _ := m["namespace"].(string)
+			op, _ := m["operator"].(string)
+			sel.MatchNamespaces = append(sel.MatchNamespaces, TracingNamespaceMatch{
+				Namespace: ns,
+				Operator:  op,
+				Values:    stringList(m["values"]),
+			})
+		}
+		for _, m := range mapList(selMap["matchActions"]) {
+			action, _ := m["action"].(string)
+			argError, _ := m["argError"].(float64)
+			argSig, _ := m["argSig"].(float64)
+			sel.MatchActions = append(sel.MatchActions, TracingAction{
+				Action:   action,
+				ArgError: int32(argError),
+				ArgSig:   int32(argSig),
+			})
+		}
+
+		hook.Selectors = append(hook.Selectors, sel)
+	}
+
+	return hook
+}
+
+// parseGatewayRoute parses a Gateway API HTTPRoute or GRPCRoute.
+func (p *PolicyParser) parseGatewayRoute(content string, policyType string, defaultNamespace string) (*ParsedPolicy, error) {
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal([]byte(content), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	policy := &ParsedPolicy{
+		Namespace:   defaultNamespace,
+		PodSelector: make(map[string]string),
 	}
 
 	if kind, ok := raw["kind"].(string); ok {
 		policy.Type = kind
 	}
+	wantKind := "HTTPRoute"
+	if policyType == "GATEWAY_GRPCROUTE" {
+		wantKind = "GRPCRoute"
+	}
+	if policy.Type != "" && policy.Type != wantKind {
+		return nil, fmt.Errorf("policy type %s expects kind %s, got %s", policyType, wantKind, policy.Type)
+	}
+	policy.Type = wantKind
+
+	if metadata, ok := raw["metadata"].(map[string]interface{}); ok {
+		if name, ok := metadata["name"].(string); ok {
+			policy.Name = name
+		}
+		if ns, ok := metadata["namespace"].(string); ok && ns != "" {
+			policy.Namespace = ns
+		}
+	}
+
+	spec, ok := raw["spec"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("missing spec in policy")
+	}
+
+	for _, ref := range mapList(spec["parentRefs"]) {
+		parent := RouteParentRef{Kind: "Gateway", Namespace: policy.Namespace}
+		if kind, ok := ref["kind"].(string); ok && kind != "" {
+			parent.Kind = kind
+		}
+		parent.Name, _ = ref["name"].(string)
+		if ns, ok := ref["namespace"].(string); ok && ns != "" {
+			parent.Namespace = ns
+		}
+		if port, ok := ref["port"].(float64); ok {
+			parent.Port = int32(port)
+		}
+		if parent.Name != "" {
+			policy.ParentRefs = append(policy.ParentRefs, parent)
+		}
+	}
+	policy.Hostnames = stringList(spec["hostnames"])
+
+	for _, ruleMap := range mapList(spec["rules"]) {
+		var rule RouteRule
+
+		for _, m := range mapList(ruleMap["matches"]) {
+			if wantKind == "GRPCRoute" {
+				rule.Matches = append(rule.Matches, parseGRPCRouteMatch(m))
+			} else {
+				rule.Matches = append(rule.Matches, parseHTTPRouteMatch(m))
+			}
+		}
+
+		for _, ref := range mapList(ruleMap["backendRefs"]) {
+			backend := RouteBackend{Weight: 1, Namespace: policy.Namespace}
+			backend.Kind, _ = ref["kind"].(string)
+			backend.Name, _ = ref["name"].(string)
+			if ns, ok := ref["namespace"].(string); ok && ns != "" {
+				backend.Namespace = ns
+			}
+			if port, ok := ref["port"].(float64); ok {
+				backend.Port = int32(port)
+			}
+			if weight, ok := ref["weight"].(float64); ok {
+				backend.Weight = int32(weight)
+			}
+			rule.Backends = append(rule.Backends, backend)
+		}
+
+		for _, filter := range mapList(ruleMap["filters"]) {
+			if filter["type"] != "RequestRedirect" {
+				continue
+			}
+			rule.RedirectStatus = 302
+			if redirect, ok := filter["requestRedirect"].(map[string]interface{}); ok {
+				if code, ok := redirect["statusCode"].(float64); ok {
+					rule.RedirectStatus = int32(code)
+				}
+			}
+		}
+
+		policy.RouteRules = append(policy.RouteRules, rule)
+	}
+
+	if len(policy.RouteRules) == 0 {
+		return nil, fmt.Errorf("route has no rules")
+	}
 
 	return policy, nil
 }
+
+// parseHTTPRouteMatch parses an HTTPRoute match, applying the API defaults.
+func parseHTTPRouteMatch(m map[string]interface{}) RouteMatch {
+	match := RouteMatch{PathType: "PathPrefix", PathValue: "/"}
+	if path, ok := m["path"].(map[string]interface{}); ok {
+		if t, ok := path["type"].(string); ok && t != "" {
+			match.PathType = t
+		}
+		if v, ok := path["value"].(string); ok && v != "" {
+			match.PathValue = v
+		}
+	}
+	match.Method, _ = m["method"].(string)
+	match.Headers = parseRouteValueMatches(m["headers"])
+	match.QueryParams = parseRouteValueMatches(m["queryParams"])
+	return match
+}
+
+// parseGRPCRouteMatch parses a GRPCRoute match, applying the API defaults.
+func parseGRPCRouteMatch(m map[string]interface{}) RouteMatch {
+	match := RouteMatch{}
+	if method, ok := m["method"].(map[string]interface{}); ok {
+		match.GRPCMatchType = "Exact"
+		if t, ok := method["type"].(string); ok && t != "" {
+			match.GRPCMatchType = t
+		}
+		match.GRPCService, _ = method["service"].(string)
+		match.GRPCMethod, _ = method["method"].(string)
+	}
+	match.Headers = parseRouteValueMatches(m["headers"])
+	return match
+}
+
+// parseRouteValueMatches parses header or query parameter matches.
+func parseRouteValueMatches(v interface{}) []RouteValueMatch {
+	var matches []RouteValueMatch
+	for _, m := range mapList(v) {
+		vm := RouteValueMatch{Type: "Exact"}
+		if t, ok := m["type"].(string); ok && t != "" {
+			vm.Type = t
+		}
+		vm.Name, _ = m["name"].(string)
+		vm.Value, _ = stringValue(m["value"])
+		if vm.Name != "" {
+			matches = append(matches, vm)
+		}
+	}
+	return matches
+}
+
+// mapList returns the map entries of a YAML list.
+func mapList(v interface{}) []map[string]interface{} {
+	list, _ := v.([]interface{})
+	var result []map[string]interface{}
+	for _, item := range list {
+		if m, ok := item.(map[string]interface{}); ok {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// stringList returns the scalar entries of a YAML list as strings.
+func stringList(v interface{}) []string {
+	list, _ := v.([]interface{})
+	var result []string
+	for _, item := range list {
+		if s, ok := stringValue(item); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
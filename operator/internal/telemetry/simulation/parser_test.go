@@ -296,6 +296,82 @@ spec:
 	}
 }
 
+func TestPolicyParser_ParseKafkaAndHeaderRules(t *testing.T) {
+	parser := NewPolicyParser()
+
+	content := `
+apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: l7-kafka
+spec:
+  endpointSelector:
+    matchLabels:
+      app: broker
+  ingress:
+    - toPorts:
+        - ports:
+            - port: "9092"
+          rules:
+            kafka:
+              - role: produce
+                topic: orders
+              - apiKey: Fetch
+                topic: audit
+                apiVersion: 4
+    - toPorts:
+        - ports:
+            - port: "8080"
+          rules:
+            http:
+              - method: GET
+                headers:
+                  - "X-Tenant: acme"
+                  - X-Request-Id
+                headerMatches:
+                  - name: Authorization
+                    secret:
+                      name: token
+                  - name: X-Trace
+                    value: "on"
+                    mismatch: ADD
+`
+
+	policy, err := parser.Parse(content, "CILIUM_NETWORK")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if len(policy.IngressRules) != 2 {
+		t.Fatalf("Expected 2 ingress rules, got %d", len(policy.IngressRules))
+	}
+
+	kafka := policy.IngressRules[0].L7Rules
+	if len(kafka) != 2 {
+		t.Fatalf("Expected 2 Kafka rules, got %d", len(kafka))
+	}
+	if kafka[0].Type != "kafka" || kafka[0].Topic != "orders" || len(kafka[0].APIKeys) != 3 {
+		t.Errorf("produce role rule = %+v", kafka[0])
+	}
+	if len(kafka[1].APIKeys) != 1 || kafka[1].APIKeys[0] != "fetch" || kafka[1].APIVersion != "4" {
+		t.Errorf("apiKey rule = %+v", kafka[1])
+	}
+
+	want := []HeaderMatch{
+		{Name: "X-Tenant", Value: "acme"},
+		{Name: "X-Request-Id"},
+		{Name: "Authorization"},
+	}
+	headers := policy.IngressRules[1].L7Rules[0].Headers
+	if len(headers) != len(want) {
+		t.Fatalf("Headers = %+v, want %+v", headers, want)
+	}
+	for i := range want {
+		if headers[i] != want[i] {
+			t.Errorf("Headers[%d] = %+v, want %+v", i, headers[i], want[i])
+		}
+	}
+}
+
 func TestPolicyParser_ParseTetragonPolicy(t *testing.T) {
 	parser := NewPolicyParser()
 
@@ -322,6 +398,114 @@ spec:
 	}
 }
 
+func TestPolicyParser_ParseTracingSelectors(t *testing.T) {
+	parser := NewPolicyParser()
+
+	policy, err := parser.Parse(blockSensitiveFiles, "TETRAGON")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	if policy.PodSelector["app"] != "web" {
+		t.Errorf("PodSelector = %v, want app=web", policy.PodSelector)
+	}
+	if policy.Namespace != "" {
+		t.Errorf("Namespace = %q, want empty for TracingPolicy", policy.Namespace)
+	}
+	if len(policy.Hooks) != 2 {
+		t.Fatalf("len(Hooks) = %d, want 2", len(policy.Hooks))
+	}
+
+	kprobe := policy.Hooks[0]
+	if kprobe.Type != "kprobe" || kprobe.Call != "security_file_open" {
+		t.Errorf("kprobe = %s:%s", kprobe.Type, kprobe.Call)
+	}
+	if len(kprobe.Args) != 1 || kprobe.Args[0].Type != "file" {
+		t.Errorf("Args = %+v", kprobe.Args)
+	}
+	if len(kprobe.Selectors) != 3 {
+		t.Fatalf("len(Selectors) = %d, want 3", len(kprobe.Selectors))
+	}
+	sel := kprobe.Selectors[1]
+	if len(sel.MatchBinaries) != 1 || sel.MatchBinaries[0].Operator != "NotIn" {
+		t.Errorf("MatchBinaries = %+v", sel.MatchBinaries)
+	}
+	if len(sel.MatchActions) != 1 || sel.MatchActions[0].Action != "Override" || sel.MatchActions[0].ArgError != -1 {
+		t.Errorf("MatchActions = %+v", sel.MatchActions)
+	}
+
+	tracepoint := policy.Hooks[1]
+	if tracepoint.Type != "tracepoint" || tracepoint.Call != "syscalls/sys_enter_connect" {
+		t.Errorf("tracepoint = %s:%s", tracepoint.Type, tracepoint.Call)
+	}
+	if ns := tracepoint.Selectors[0].MatchNamespaces; len(ns) != 1 || ns[0].Namespace != "Net" || ns[0].Values[0] != "host_ns" {
+		t.Errorf("MatchNamespaces = %+v", ns)
+	}
+
+	namespaced, err := parser.Parse(blockShellExec, "TETRAGON")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if namespaced.Namespace != "prod" {
+		t.Errorf("Namespace = %q, want prod", namespaced.Namespace)
+	}
+
+	if _, err := parser.Parse("kind: TracingPolicy\nspec: {}\n", "TETRAGON"); err == nil {
+		t.Error("Parse() expected error for policy without hooks")
+	}
+}
+
+func TestPolicyParser_ParseGatewayRoutes(t *testing.T) {
+	parser := NewPolicyParser()
+
+	policy, err := parser.Parse(storeRoute, "GATEWAY_HTTPROUTE")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if policy.Type != "HTTPRoute" || policy.Namespace != "shop" {
+		t.Errorf("Type/Namespace = %s/%s", policy.Type, policy.Namespace)
+	}
+	if len(policy.Hostnames) != 2 {
+		t.Errorf("Hostnames = %v", policy.Hostnames)
+	}
+	if len(policy.RouteRules) != 4 {
+		t.Fatalf("len(RouteRules) = %d, want 4", len(policy.RouteRules))
+	}
+
+	split := policy.RouteRules[0]
+	if len(split.Backends) != 2 || split.Backends[0].Weight != 90 || split.Backends[0].Namespace != "shop" || split.Backends[0].Port != 8080 {
+		t.Errorf("Backends = %+v", split.Backends)
+	}
+	admin := policy.RouteRules[1]
+	if admin.Matches[0].Method != "POST" || admin.Matches[1].PathType != "Exact" {
+		t.Errorf("Matches = %+v", admin.Matches)
+	}
+	if h := admin.Matches[1].Headers; len(h) != 1 || h[0].Type != "Exact" || h[0].Name != "X-Debug" {
+		t.Errorf("Headers = %+v", h)
+	}
+	if admin.Backends[0].Weight != 1 {
+		t.Errorf("Weight = %d, want default 1", admin.Backends[0].Weight)
+	}
+	if policy.RouteRules[2].RedirectStatus != 301 {
+		t.Errorf("RedirectStatus = %d, want 301", policy.RouteRules[2].RedirectStatus)
+	}
+
+	grpc, err := parser.Parse(paymentsGRPCRoute, "GATEWAY_GRPCROUTE")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if m := grpc.RouteRules[1].Matches[0]; m.GRPCMatchType != "Exact" || m.GRPCService != "payments.v1.Payments" || m.GRPCMethod != "Refund" {
+		t.Errorf("gRPC match = %+v", m)
+	}
+
+	if _, err := parser.Parse(storeRoute, "GATEWAY_GRPCROUTE"); err == nil {
+		t.Error("Parse() expected error for mismatched kind")
+	}
+	if _, err := parser.Parse("kind: HTTPRoute\nspec: {}\n", "GATEWAY_HTTPROUTE"); err == nil {
+		t.Error("Parse() expected error for route without rules")
+	}
+}
+
 func TestMatchFQDN(t *testing.T) {
 	tests := []struct {
 		hostname string
@@ -345,3 +529,365 @@ func TestMatchFQDN(t *testing.T) {
 		})
 	}
 }
+
+func TestPolicyParser_ParseCiliumSelectors(t *testing.T) {
+	parser := NewPolicyParser()
+
+	tests := []struct {
+		name    string
+		content string
+		check   func(t *testing.T, policy *ParsedPolicy)
+	}{
+		{
+			name: "endpointSelector and fromEndpoints matchExpressions",
+			content: `
+apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: tiers
+  namespace: shop
+spec:
+  endpointSelector:
+    matchExpressions:
+      - key: tier
+        operator: In
+        values: [backend, cache]
+  ingress:
+    - fromEndpoints:
+        - matchLabels:
+            k8s:app: frontend
+          matchExpressions:
+            - key: k8s:io.kubernetes.pod.namespace
+              operator: In
+              values: [shop, web]
+`,
+			check: func(t *testing.T, policy *ParsedPolicy) {
+				if len(policy.PodSelectorExpressions) != 1 || policy.PodSelectorExpressions[0].Key != "tier" {
+					t.Fatalf("PodSelectorExpressions = %+v", policy.PodSelectorExpressions)
+				}
+				if len(policy.IngressRules) != 1 {
+					t.Fatalf("Expected 1 ingress rule, got %d", len(policy.IngressRules))
+				}
+				rule := policy.IngressRules[0]
+				if rule.PodSelector["app"] != "frontend" {
+					t.Errorf("Expected normalized label key app, got %v", rule.PodSelector)
+				}
+				if len(rule.MatchExpressions) != 1 || rule.MatchExpressions[0].Key != namespaceLabel {
+					t.Errorf("MatchExpressions = %+v", rule.MatchExpressions)
+				}
+				if _, ok := rule.NamespaceSelector["name"]; ok {
+					t.Errorf("Namespace expression should suppress the implicit namespace, got %v", rule.NamespaceSelector)
+				}
+			},
+		},
+		{
+			name: "implicit namespace on CNP peers",
+			content: `
+apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: same-ns
+  namespace: shop
+spec:
+  endpointSelector: {}
+  ingress:
+    - fromEndpoints:
+        - {}
+        - matchLabels:
+            reserved:host: ""
+`,
+			check: func(t *testing.T, policy *ParsedPolicy) {
+				if len(policy.IngressRules) != 2 {
+					t.Fatalf("Expected 2 ingress rules, got %d", len(policy.IngressRules))
+				}
+				if policy.IngressRules[0].NamespaceSelector["name"] != "shop" {
+					t.Errorf("Expected implicit namespace shop, got %v", policy.IngressRules[0].NamespaceSelector)
+				}
+				if _, ok := policy.IngressRules[1].NamespaceSelector["name"]; ok {
+					t.Errorf("Reserved selectors must not be namespaced, got %v", policy.IngressRules[1].NamespaceSelector)
+				}
+			},
+		},
+		{
+			name: "clusterwide peers are not namespaced",
+			content: `
+apiVersion: cilium.io/v2
+kind: CiliumClusterwideNetworkPolicy
+metadata:
+  name: cluster
+spec:
+  endpointSelector: {}
+  egress:
+    - toEndpoints:
+        - matchLabels:
+            app: db
+`,
+			check: func(t *testing.T, policy *ParsedPolicy) {
+				if len(policy.EgressRules) != 1 {
+					t.Fatalf("Expected 1 egress rule, got %d", len(policy.EgressRules))
+				}
+				if len(policy.EgressRules[0].NamespaceSelector) != 0 {
+					t.Errorf("Expected no namespace selector, got %v", policy.EgressRules[0].NamespaceSelector)
+				}
+			},
+		},
+		{
+			name: "peer alternatives expand into separate rules sharing ports",
+			content: `
+apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: egress-mix
+  namespace: shop
+spec:
+  endpointSelector:
+    matchLabels:
+      app: api
+  egress:
+    - toEndpoints:
+        - matchLabels:
+            app: db
+      toEntities:
+        - kube-apiserver
+      toCIDRSet:
+        - cidr: 10.0.0.0/8
+          except:
+            - 10.96.0.0/12
+      toServices:
+        - k8sService:
+            serviceName: payments
+            namespace: billing
+        - k8sServiceSelector:
+            selector:
+              matchLabels:
+                app: ledger
+      toPorts:
+        - ports:
+            - port: 443
+            - port: "8000"
+              endPort: 8100
+              protocol: TCP
+`,
+			check: func(t *testing.T, policy *ParsedPolicy) {
+				if len(policy.EgressRules) != 4 {
+					t.Fatalf("Expected 4 egress rules, got %d", len(policy.EgressRules))
+				}
+				for _, rule := range policy.EgressRules {
+					if len(rule.ToPorts) != 2 {
+						t.Errorf("Expected 2 ports on every rule, got %+v", rule.ToPorts)
+					}
+				}
+				ports := policy.EgressRules[0].ToPorts
+				if ports[0].Port != 443 || ports[0].Protocol != "ANY" {
+					t.Errorf("Expected numeric port 443/ANY, got %+v", ports[0])
+				}
+				if ports[1].Port != 8000 || ports[1].EndPort != 8100 || ports[1].Protocol != "TCP" {
+					t.Errorf("Expected port range 8000-8100/TCP, got %+v", ports[1])
+				}
+				cidrRule := policy.EgressRules[1]
+				if len(cidrRule.ToCIDRSet) != 1 || len(cidrRule.ToCIDRSet[0].Except) != 1 {
+					t.Errorf("ToCIDRSet = %+v", cidrRule.ToCIDRSet)
+				}
+				if entities := policy.EgressRules[2].Entities; len(entities) != 1 || entities[0] != "kube-apiserver" {
+					t.Errorf("Entities = %v", entities)
+				}
+				services := policy.EgressRules[3].ToServices
+				if len(services) != 2 {
+					t.Fatalf("Expected 2 services, got %+v", services)
+				}
+				if services[0].Name != "payments" || services[0].Namespace != "billing" {
+					t.Errorf("services[0] = %+v", services[0])
+				}
+				if services[1].Selector["app"] != "ledger" || services[1].Namespace != "shop" {
+					t.Errorf("services[1] = %+v", services[1])
+				}
+			},
+		},
+		{
+			name: "fromRequires and icmps",
+			content: `
+apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: requires
+  namespace: prod
+spec:
+  endpointSelector:
+    matchLabels:
+      env: prod
+  ingress:
+    - fromRequires:
+        - matchLabels:
+            env: prod
+    - fromEntities:
+        - cluster
+      icmps:
+        - fields:
+            - type: 8
+              family: IPv4
+            - type: EchoRequest
+              family: IPv6
+`,
+			check: func(t *testing.T, policy *ParsedPolicy) {
+				if len(policy.IngressRequires) != 1 {
+					t.Fatalf("Expected 1 requirement, got %d", len(policy.IngressRequires))
+				}
+				req := policy.IngressRequires[0]
+				if req.MatchLabels["env"] != "prod" || req.MatchLabels[namespaceLabel] != "prod" {
+					t.Errorf("IngressRequires[0] = %+v", req)
+				}
+				if len(policy.IngressRules) != 1 {
+					t.Fatalf("Requires-only rules should not allow traffic, got %d rules", len(policy.IngressRules))
+				}
+				icmps := policy.IngressRules[0].ICMPs
+				if len(icmps) != 2 || icmps[0].Type != "8" || icmps[1].Family != "IPv6" {
+					t.Errorf("ICMPs = %+v", icmps)
+				}
+				if !policy.DefaultDeny || policy.DefaultDenyType != "ingress" {
+					t.Errorf("Expected ingress default deny, got %v/%s", policy.DefaultDeny, policy.DefaultDenyType)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := parser.Parse(tt.content, "CILIUM_NETWORK")
+			if err != nil {
+				t.Fatalf("Parse() error: %v", err)
+			}
+			tt.check(t, policy)
+		})
+	}
+}
+
+func TestPolicyParser_ParseDenyRules(t *testing.T) {
+	parser := NewPolicyParser()
+
+	tests := []struct {
+		name            string
+		content         string
+		wantIngressDeny int
+		wantEgressDeny  int
+		wantDefaultDeny bool
+		wantDenyType    string
+	}{
+		{
+			name: "ingressDeny alongside allow",
+			content: `
+apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: lockdown
+  namespace: prod
+spec:
+  endpointSelector: {}
+  ingressDeny:
+    - fromEntities:
+        - world
+    - fromEndpoints:
+        - matchLabels:
+            app: legacy
+      toPorts:
+        - ports:
+            - port: "22"
+              protocol: TCP
+          rules:
+            http:
+              - method: GET
+  ingress:
+    - fromEntities:
+        - all
+`,
+			wantIngressDeny: 2,
+			wantDefaultDeny: true,
+			wantDenyType:    "ingress",
+		},
+		{
+			name: "deny-only policy enables default deny",
+			content: `
+apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: deny-metadata
+  namespace: prod
+spec:
+  endpointSelector: {}
+  egressDeny:
+    - toCIDR:
+        - 169.254.169.254/32
+`,
+			wantEgressDeny:  1,
+			wantDefaultDeny: true,
+			wantDenyType:    "egress",
+		},
+		{
+			name: "deny-only policy with enableDefaultDeny disabled",
+			content: `
+apiVersion: cilium.io/v2
+kind: CiliumClusterwideNetworkPolicy
+metadata:
+  name: deny-metadata
+spec:
+  endpointSelector: {}
+  enableDefaultDeny:
+    egress: false
+  egressDeny:
+    - toCIDR:
+        - 169.254.169.254/32
+`,
+			wantEgressDeny:  1,
+			wantDefaultDeny: false,
+		},
+		{
+			name: "enableDefaultDeny without rules",
+			content: `
+apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: lockdown
+  namespace: prod
+spec:
+  endpointSelector: {}
+  enableDefaultDeny:
+    ingress: true
+`,
+			wantDefaultDeny: true,
+			wantDenyType:    "ingress",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := parser.Parse(tt.content, "CILIUM_NETWORK")
+			if err != nil {
+				t.Fatalf("Parse() error: %v", err)
+			}
+
+			countDeny := func(rules []PolicyRule) int {
+				n := 0
+				for _, r := range rules {
+					if r.Action == "deny" {
+						n++
+						if len(r.L7Rules) > 0 {
+							t.Errorf("Deny rule should not carry L7 rules: %+v", r.L7Rules)
+						}
+					}
+				}
+				return n
+			}
+			if got := countDeny(policy.IngressRules); got != tt.wantIngressDeny {
+				t.Errorf("ingress deny rules = %d, want %d", got, tt.wantIngressDeny)
+			}
+			if got := countDeny(policy.EgressRules); got != tt.wantEgressDeny {
+				t.Errorf("egress deny rules = %d, want %d", got, tt.wantEgressDeny)
+			}
+			if policy.DefaultDeny != tt.wantDefaultDeny {
+				t.Errorf("DefaultDeny = %v, want %v", policy.DefaultDeny, tt.wantDefaultDeny)
+			}
+			if policy.DefaultDenyType != tt.wantDenyType {
+				t.Errorf("DefaultDenyType = %q, want %q", policy.DefaultDenyType, tt.wantDenyType)
+			}
+		})
+	}
+}
@@ -0,0 +1,373 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+)
+
+// Policy set change operations.
+const (
+	PolicyChangeAdd    = "add"
+	PolicyChangeModify = "modify"
+	PolicyChangeDelete = "delete"
+)
+
+// PolicySetSource loads the policies currently deployed in the cluster.
+type PolicySetSource interface {
+	CurrentPolicies(ctx context.Context) ([]PolicyDocument, error)
+}
+
+// policySetEntry is a parsed member of a policy set.
+type policySetEntry struct {
+	key    string
+	policy *ParsedPolicy
+}
+
+// setVerdict is the effective verdict of a flow under a whole policy set.
+type setVerdict struct {
+	verdict string
+	policy  string
+	rule    string
+	reason  string
+}
+
+// SimulatePolicySet evaluates historical flows against the current policy set
+// and against the set with the proposed changes applied. Each flow gets the
+// verdict Cilium would compute from the union of all policies selecting its
+// endpoints, before and after the change.
+//
+// The response uses the same shape as Simulate, with OriginalVerdict holding
+// the verdict under the current set rather than the recorded Hubble verdict.
+// Details only contain flows whose verdict changes.
+func (e *Engine) SimulatePolicySet(ctx context.Context, req *PolicySetSimulationRequest) (*SimulationResponse, error) {
+	startTime := time.Now()
+
+	failed := func(errs ...string) *SimulationResponse {
+		return &SimulationResponse{
+			Errors:         errs,
+			SimulationTime: startTime,
+			Duration:       time.Since(startTime),
+		}
+	}
+
+	current := req.CurrentPolicies
+	if current == nil {
+		if e.policySource == nil {
+			return failed("No current policies provided and no policy source configured"), nil
+		}
+		docs, err := e.policySource.CurrentPolicies(ctx)
+		if err != nil {
+			return failed("Failed to load current policies: " + err.Error()), nil
+		}
+		current = docs
+	}
+
+	e.log.Info("Starting policy set simulation",
+		"currentPolicies", len(current),
+		"changes", len(req.Changes),
+		"startTime", req.StartTime,
+		"endTime", req.EndTime,
+	)
+
+	before, errs := e.parsePolicySet(current)
+	if len(errs) > 0 {
+		return failed(errs...), nil
+	}
+	after, err := e.applyPolicyChanges(before, req.Changes)
+	if err != nil {
+		return failed(err.Error()), nil
+	}
+
+	queryReq := models.QueryEventsRequest{
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+		EventTypes: []string{string(models.EventTypeFlow)},
+		Limit:      0, // Get all matching events
+	}
+	maxDetails := int(req.MaxDetails)
+	if maxDetails == 0 {
+		maxDetails = 100 // Default limit
+	}
+
+	newPartial := func() *SimulationResponse {
+		return &SimulationResponse{
+			BreakdownByNamespace: make(map[string]*NamespaceImpact),
+			BreakdownByVerdict:   &VerdictBreakdown{},
+			Details:              []*FlowSimulationResult{},
+		}
+	}
+
+	response, err := e.streamEvaluate(ctx, queryReq, req.Progress, maxDetails, startTime, newPartial, func(partial *SimulationResponse, event *models.TelemetryEvent) {
+		flowResult := e.comparePolicySets(event, before, after)
+		partial.TotalFlowsAnalyzed++
+
+		switch flowResult.SimulatedVerdict {
+		case "ALLOWED":
+			partial.AllowedCount++
+		case "DENIED":
+			partial.DeniedCount++
+		}
+		if flowResult.VerdictChanged {
+			partial.WouldChangeCount++
+			addConnection(partial, event, flowResult)
+			if req.IncludeDetails && len(partial.Details) < maxDetails {
+				partial.Details = append(partial.Details, flowResult)
+			}
+		} else {
+			partial.NoChangeCount++
+		}
+
+		e.updateVerdictBreakdown(partial.BreakdownByVerdict, flowResult)
+		e.updateNamespaceBreakdown(partial.BreakdownByNamespace, event, flowResult)
+	})
+	if err != nil || len(response.Errors) > 0 {
+		return response, err
+	}
+
+	e.log.Info("Policy set simulation complete",
+		"totalFlows", response.TotalFlowsAnalyzed,
+		"allowed", response.AllowedCount,
+		"denied", response.DeniedCount,
+		"wouldChange", response.WouldChangeCount,
+		"duration", response.Duration,
+	)
+
+	return response, nil
+}
+
+// parsePolicySet parses every document of a policy set. Documents that fail
+// to parse are reported rather than skipped, since a silently missing policy
+// would skew both sides of the comparison.
+func (e *Engine) parsePolicySet(docs []PolicyDocument) ([]*policySetEntry, []string) {
+	var entries []*policySetEntry
+	var errs []string
+	seen := make(map[string]bool)
+
+	for _, doc := range docs {
+		entry, err := e.parsePolicyDocument(doc)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if seen[entry.key] {
+			errs = append(errs, fmt.Sprintf("duplicate policy %s", entry.key))
+			continue
+		}
+		seen[entry.key] = true
+		entries = append(entries, entry)
+	}
+	return entries, errs
+}
+
+// parsePolicyDocument parses a single policy set member.
+func (e *Engine) parsePolicyDocument(doc PolicyDocument) (*policySetEntry, error) {
+	policyType := doc.PolicyType
+	if policyType == "" {
+		policyType = "CILIUM_NETWORK"
+	}
+	if policyType != "CILIUM_NETWORK" && policyType != "CILIUM_CLUSTERWIDE" {
+		return nil, fmt.Errorf("policy %s: unsupported policy type in policy set: %s", policyDocumentKey(doc), policyType)
+	}
+
+	parsed, err := e.parser.ParseInNamespace(doc.Content, policyType, doc.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("policy %s: %w", policyDocumentKey(doc), err)
+	}
+	if parsed.Name == "" {
+		parsed.Name = doc.Name
+	}
+	if parsed.Name == "" {
+		return nil, fmt.Errorf("policy %s: missing name", policyDocumentKey(doc))
+	}
+
+	return &policySetEntry{key: policyKey(parsed.Namespace, parsed.Name), policy: parsed}, nil
+}
+
+// applyPolicyChanges returns a copy of a policy set with a diff applied.
+func (e *Engine) applyPolicyChanges(current []*policySetEntry, changes []PolicyChange) ([]*policySetEntry, error) {
+	byKey := make(map[string]*policySetEntry, len(current))
+	for _, entry := range current {
+		byKey[entry.key] = entry
+	}
+
+	for i, change := range changes {
+		switch change.Operation {
+		case PolicyChangeAdd, PolicyChangeModify:
+			entry, err := e.parsePolicyDocument(change.Policy)
+			if err != nil {
+				return nil, fmt.Errorf("change %d: %w", i, err)
+			}
+			_, exists := byKey[entry.key]
+			if change.Operation == PolicyChangeAdd && exists {
+				return nil, fmt.Errorf("change %d: policy %s already exists", i, entry.key)
+			}
+			if change.Operation == PolicyChangeModify && !exists {
+				return nil, fmt.Errorf("change %d: policy %s does not exist", i, entry.key)
+			}
+			byKey[entry.key] = entry
+		case PolicyChangeDelete:
+			key := policyKey(change.Policy.Namespace, change.Policy.Name)
+			if change.Policy.Name == "" && change.Policy.Content != "" {
+				entry, err := e.parsePolicyDocument(change.Policy)
+				if err != nil {
+					return nil, fmt.Errorf("change %d: %w", i, err)
+				}
+				key = entry.key
+			}
+			if _, exists := byKey[key]; !exists {
+				return nil, fmt.Errorf("change %d: policy %s does not exist", i, key)
+			}
+			delete(byKey, key)
+		default:
+			return nil, fmt.Errorf("change %d: unsupported operation: %s", i, change.Operation)
+		}
+	}
+
+	// Keep evaluation order deterministic
+	result := make([]*policySetEntry, 0, len(byKey))
+	for _, entry := range byKey {
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].key < result[j].key })
+	return result, nil
+}
+
+// comparePolicySets evaluates a flow against two policy sets. The verdict
+// under the first set is reported as the original verdict.
+func (e *Engine) comparePolicySets(event *models.TelemetryEvent, before, after []*policySetEntry) *FlowSimulationResult {
+	beforeVerdict := e.evaluatePolicySet(event, before)
+	afterVerdict := e.evaluatePolicySet(event, after)
+
+	return &FlowSimulationResult{
+		Timestamp:        event.Timestamp,
+		SrcNamespace:     event.SrcNamespace,
+		SrcPodName:       event.SrcPodName,
+		DstNamespace:     event.DstNamespace,
+		DstPodName:       event.DstPodName,
+		DstPort:          event.DstPort,
+		Protocol:         event.Protocol,
+		L7Type:           event.L7Type,
+		HTTPMethod:       event.HTTPMethod,
+		HTTPPath:         event.HTTPPath,
+		OriginalVerdict:  beforeVerdict.verdict,
+		SimulatedVerdict: afterVerdict.verdict,
+		VerdictChanged:   beforeVerdict.verdict != afterVerdict.verdict,
+		MatchedPolicy:    afterVerdict.policy,
+		MatchedRule:      afterVerdict.rule,
+		MatchReason:      afterVerdict.reason,
+	}
+}
+
+// evaluatePolicySet computes the verdict of a flow under a set of policies.
+// The flow must pass the egress policies of its source and the ingress
+// policies of its destination; endpoints selected by no enforcing policy
+// allow all traffic.
+func (e *Engine) evaluatePolicySet(event *models.TelemetryEvent, policies []*policySetEntry) setVerdict {
+	if event.IsReply {
+		return setVerdict{verdict: "ALLOWED", reason: "Reply traffic is not subject to policy"}
+	}
+
+	var allowed *setVerdict
+	for _, direction := range []string{"ingress", "egress"} {
+		v := e.evaluatePolicySetDirection(event, policies, direction)
+		if v == nil {
+			continue
+		}
+		if v.verdict == "DENIED" {
+			return *v
+		}
+		if allowed == nil {
+			allowed = v
+		}
+	}
+	if allowed != nil {
+		return *allowed
+	}
+	return setVerdict{verdict: "ALLOWED", reason: "No policy enforces on this flow"}
+}
+
+// evaluatePolicySetDirection applies Cilium's union semantics for one
+// direction: a deny rule in any selecting policy wins, then an allow rule in
+// any selecting policy, and unmatched traffic is denied if any selecting
+// policy enables default deny. Requirements (fromRequires/toRequires) of all
+// selecting policies apply to every allow rule. Returns nil if no policy
+// decides the flow.
+func (e *Engine) evaluatePolicySetDirection(event *models.TelemetryEvent, policies []*policySetEntry, direction string) *setVerdict {
+	var selecting []*policySetEntry
+	for _, entry := range policies {
+		if PolicyCoversDirection(entry.policy, direction) && MatchesPodSelectorForDirection(event, entry.policy, direction) {
+			selecting = append(selecting, entry)
+		}
+	}
+	if len(selecting) == 0 {
+		return nil
+	}
+
+	for _, entry := range selecting {
+		for i, rule := range RulesForDirection(entry.policy, direction) {
+			if rule.Action == "deny" && FlowMatchesRuleInDirection(event, &rule, direction) {
+				return &setVerdict{
+					verdict: "DENIED",
+					policy:  entry.key,
+					rule:    ruleDescription(i, &rule),
+					reason:  fmt.Sprintf("Matched %s deny rule", direction),
+				}
+			}
+		}
+	}
+
+	for _, entry := range selecting {
+		for i, rule := range RulesForDirection(entry.policy, direction) {
+			if rule.Action == "deny" || !FlowMatchesRuleInDirection(event, &rule, direction) {
+				continue
+			}
+			for _, other := range selecting {
+				if RequiresSatisfied(event, other.policy, direction) {
+					continue
+				}
+				reason := "Destination does not satisfy toRequires"
+				if direction == "ingress" {
+					reason = "Source does not satisfy fromRequires"
+				}
+				return &setVerdict{verdict: "DENIED", policy: other.key, reason: reason}
+			}
+			return &setVerdict{
+				verdict: "ALLOWED",
+				policy:  entry.key,
+				rule:    ruleDescription(i, &rule),
+				reason:  fmt.Sprintf("Matched %s allow rule", direction),
+			}
+		}
+	}
+
+	for _, entry := range selecting {
+		if DefaultDenies(entry.policy, direction) {
+			return &setVerdict{
+				verdict: "DENIED",
+				policy:  entry.key,
+				reason:  fmt.Sprintf("No policy allows %s traffic, default deny", direction),
+			}
+		}
+	}
+	return nil
+}
+
+// policyDocumentKey identifies a policy document in error messages.
+func policyDocumentKey(doc PolicyDocument) string {
+	if doc.Name == "" {
+		return "<unnamed>"
+	}
+	return policyKey(doc.Namespace, doc.Name)
+}
+
+// policyKey identifies a policy within a policy set.
+func policyKey(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
@@ -0,0 +1,422 @@
+package simulation
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+	"github.com/policy-hub/operator/internal/telemetry/storage"
+)
+
+const (
+	backendAllowFrontend = `
+apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: backend-allow-frontend
+  namespace: default
+spec:
+  endpointSelector:
+    matchLabels:
+      app: backend
+  ingress:
+    - fromEndpoints:
+        - matchLabels:
+            app: frontend
+      toPorts:
+        - ports:
+            - port: "8080"
+              protocol: TCP
+`
+	backendAllowMonitoring = `
+apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: backend-allow-monitoring
+  namespace: default
+spec:
+  endpointSelector:
+    matchLabels:
+      app: backend
+  ingress:
+    - fromEndpoints:
+        - matchLabels:
+            app: prometheus
+`
+	denyBackendFromLegacy = `
+apiVersion: cilium.io/v2
+kind: CiliumClusterwideNetworkPolicy
+metadata:
+  name: deny-legacy
+spec:
+  endpointSelector:
+    matchLabels:
+      app: backend
+  ingressDeny:
+    - fromEndpoints:
+        - matchLabels:
+            tier: legacy
+`
+)
+
+type staticPolicySource struct {
+	docs []PolicyDocument
+	err  error
+}
+
+func (s *staticPolicySource) CurrentPolicies(ctx context.Context) ([]PolicyDocument, error) {
+	return s.docs, s.err
+}
+
+func policySetEvent(srcLabels map[string]string, port uint32) *models.TelemetryEvent {
+	return &models.TelemetryEvent{
+		EventType:    models.EventTypeFlow,
+		SrcNamespace: "default",
+		SrcPodName:   "client",
+		SrcPodLabels: srcLabels,
+		DstNamespace: "default",
+		DstPodName:   "backend-1",
+		DstPodLabels: map[string]string{"app": "backend"},
+		DstPort:      port,
+		Protocol:     "TCP",
+		Verdict:      models.VerdictAllowed,
+	}
+}
+
+func TestEngine_EvaluatePolicySet(t *testing.T) {
+	engine := &Engine{parser: NewPolicyParser(), log: logr.Discard()}
+
+	tests := []struct {
+		name        string
+		docs        []PolicyDocument
+		event       *models.TelemetryEvent
+		wantVerdict string
+		wantPolicy  string
+	}{
+		{
+			name:        "no policies allows everything",
+			event:       policySetEvent(map[string]string{"app": "other"}, 8080),
+			wantVerdict: "ALLOWED",
+		},
+		{
+			name:        "single policy default denies unmatched peer",
+			docs:        []PolicyDocument{{Content: backendAllowFrontend}},
+			event:       policySetEvent(map[string]string{"app": "prometheus"}, 9090),
+			wantVerdict: "DENIED",
+			wantPolicy:  "default/backend-allow-frontend",
+		},
+		{
+			name: "allows are a union across policies",
+			docs: []PolicyDocument{
+				{Content: backendAllowFrontend},
+				{Content: backendAllowMonitoring},
+			},
+			event:       policySetEvent(map[string]string{"app": "prometheus"}, 9090),
+			wantVerdict: "ALLOWED",
+			wantPolicy:  "default/backend-allow-monitoring",
+		},
+		{
+			name: "deny in another policy wins over allow",
+			docs: []PolicyDocument{
+				{Content: backendAllowFrontend},
+				{Content: denyBackendFromLegacy, PolicyType: "CILIUM_CLUSTERWIDE"},
+			},
+			event:       policySetEvent(map[string]string{"app": "frontend", "tier": "legacy"}, 8080),
+			wantVerdict: "DENIED",
+			wantPolicy:  "deny-legacy",
+		},
+		{
+			name:        "policy selecting other endpoints does not apply",
+			docs:        []PolicyDocument{{Content: strings.Replace(backendAllowFrontend, "app: backend", "app: database", 1)}},
+			event:       policySetEvent(map[string]string{"app": "other"}, 8080),
+			wantVerdict: "ALLOWED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, errs := engine.parsePolicySet(tt.docs)
+			if len(errs) > 0 {
+				t.Fatalf("parsePolicySet() errors = %v", errs)
+			}
+
+			got := engine.evaluatePolicySet(tt.event, entries)
+			if got.verdict != tt.wantVerdict {
+				t.Errorf("verdict = %q, want %q (reason: %s)", got.verdict, tt.wantVerdict, got.reason)
+			}
+			if got.policy != tt.wantPolicy {
+				t.Errorf("policy = %q, want %q", got.policy, tt.wantPolicy)
+			}
+		})
+	}
+}
+
+func TestEngine_ApplyPolicyChanges(t *testing.T) {
+	engine := &Engine{parser: NewPolicyParser(), log: logr.Discard()}
+
+	current, errs := engine.parsePolicySet([]PolicyDocument{{Content: backendAllowFrontend}})
+	if len(errs) > 0 {
+		t.Fatalf("parsePolicySet() errors = %v", errs)
+	}
+
+	tests := []struct {
+		name     string
+		changes  []PolicyChange
+		wantKeys []string
+		wantErr  string
+	}{
+		{
+			name:     "no changes",
+			wantKeys: []string{"default/backend-allow-frontend"},
+		},
+		{
+			name: "add policy",
+			changes: []PolicyChange{
+				{Operation: PolicyChangeAdd, Policy: PolicyDocument{Content: backendAllowMonitoring}},
+			},
+			wantKeys: []string{"default/backend-allow-frontend", "default/backend-allow-monitoring"},
+		},
+		{
+			name: "add existing policy",
+			changes: []PolicyChange{
+				{Operation: PolicyChangeAdd, Policy: PolicyDocument{Content: backendAllowFrontend}},
+			},
+			wantErr: "already exists",
+		},
+		{
+			name: "modify policy",
+			changes: []PolicyChange{
+				{Operation: PolicyChangeModify, Policy: PolicyDocument{Content: backendAllowFrontend}},
+			},
+			wantKeys: []string{"default/backend-allow-frontend"},
+		},
+		{
+			name: "modify missing policy",
+			changes: []PolicyChange{
+				{Operation: PolicyChangeModify, Policy: PolicyDocument{Content: backendAllowMonitoring}},
+			},
+			wantErr: "does not exist",
+		},
+		{
+			name: "delete by name",
+			changes: []PolicyChange{
+				{Operation: PolicyChangeDelete, Policy: PolicyDocument{Name: "backend-allow-frontend", Namespace: "default"}},
+			},
+			wantKeys: []string{},
+		},
+		{
+			name: "delete missing policy",
+			changes: []PolicyChange{
+				{Operation: PolicyChangeDelete, Policy: PolicyDocument{Name: "backend-allow-frontend"}},
+			},
+			wantErr: "does not exist",
+		},
+		{
+			name: "unsupported operation",
+			changes: []PolicyChange{
+				{Operation: "replace", Policy: PolicyDocument{Content: backendAllowFrontend}},
+			},
+			wantErr: "unsupported operation",
+		},
+		{
+			name: "name taken from document when metadata has none",
+			changes: []PolicyChange{
+				{Operation: PolicyChangeAdd, Policy: PolicyDocument{
+					Name:      "unnamed",
+					Namespace: "prod",
+					Content:   "kind: CiliumNetworkPolicy\nspec:\n  endpointSelector: {}\n",
+				}},
+			},
+			wantKeys: []string{"default/backend-allow-frontend", "prod/unnamed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := engine.applyPolicyChanges(current, tt.changes)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("applyPolicyChanges() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyPolicyChanges() error = %v", err)
+			}
+
+			keys := make([]string, len(got))
+			for i, entry := range got {
+				keys[i] = entry.key
+			}
+			if strings.Join(keys, ",") != strings.Join(tt.wantKeys, ",") {
+				t.Errorf("keys = %v, want %v", keys, tt.wantKeys)
+			}
+		})
+	}
+
+	// The current set must not be modified
+	if len(current) != 1 {
+		t.Errorf("current set modified, len = %d", len(current))
+	}
+}
+
+func TestEngine_SimulatePolicySet(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "engine-policyset-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cfg := storage.ManagerConfig{
+		BasePath: tmpDir,
+		NodeName: "test-node",
+		Logger:   logr.Discard(),
+	}
+	writer, err := storage.NewManager(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+
+	now := time.Now().UTC()
+	var events []*models.TelemetryEvent
+	for _, app := range []string{"frontend", "prometheus", "other"} {
+		event := policySetEvent(map[string]string{"app": app}, 8080)
+		event.ID = "flow-" + app
+		event.Timestamp = now.Add(-10 * time.Minute)
+		event.NodeName = "test-node"
+		events = append(events, event)
+	}
+	if err := writer.Write(events); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	// Close the manager to ensure all data is flushed and indexed
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	mgr, err := storage.NewManager(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+	defer mgr.Close()
+
+	ctx := context.Background()
+	baseReq := PolicySetSimulationRequest{
+		StartTime:      now.Add(-1 * time.Hour),
+		EndTime:        now,
+		IncludeDetails: true,
+	}
+
+	t.Run("reports only changed flows", func(t *testing.T) {
+		engine := NewEngine(EngineConfig{
+			StorageManager: mgr,
+			PolicySource:   &staticPolicySource{docs: []PolicyDocument{{Content: backendAllowFrontend}}},
+			Logger:         logr.Discard(),
+		})
+
+		req := baseReq
+		req.Changes = []PolicyChange{
+			{Operation: PolicyChangeAdd, Policy: PolicyDocument{Content: backendAllowMonitoring}},
+		}
+		resp, err := engine.SimulatePolicySet(ctx, &req)
+		if err != nil {
+			t.Fatalf("SimulatePolicySet() error = %v", err)
+		}
+		if len(resp.Errors) > 0 {
+			t.Fatalf("SimulatePolicySet() errors = %v", resp.Errors)
+		}
+		if resp.TotalFlowsAnalyzed != 3 {
+			t.Fatalf("TotalFlowsAnalyzed = %d, want 3", resp.TotalFlowsAnalyzed)
+		}
+		if resp.WouldChangeCount != 1 || resp.NoChangeCount != 2 {
+			t.Errorf("WouldChange/NoChange = %d/%d, want 1/2", resp.WouldChangeCount, resp.NoChangeCount)
+		}
+		if resp.AllowedCount != 2 || resp.DeniedCount != 1 {
+			t.Errorf("Allowed/Denied = %d/%d, want 2/1", resp.AllowedCount, resp.DeniedCount)
+		}
+		if len(resp.Details) != 1 {
+			t.Fatalf("len(Details) = %d, want 1", len(resp.Details))
+		}
+		detail := resp.Details[0]
+		if detail.OriginalVerdict != "DENIED" || detail.SimulatedVerdict != "ALLOWED" {
+			t.Errorf("verdicts = %s -> %s, want DENIED -> ALLOWED", detail.OriginalVerdict, detail.SimulatedVerdict)
+		}
+		if detail.MatchedPolicy != "default/backend-allow-monitoring" {
+			t.Errorf("MatchedPolicy = %q", detail.MatchedPolicy)
+		}
+	})
+
+	t.Run("deleting the last policy opens traffic", func(t *testing.T) {
+		engine := NewEngine(EngineConfig{StorageManager: mgr, Logger: logr.Discard()})
+
+		req := baseReq
+		req.CurrentPolicies = []PolicyDocument{{Content: backendAllowFrontend}}
+		req.Changes = []PolicyChange{
+			{Operation: PolicyChangeDelete, Policy: PolicyDocument{Name: "backend-allow-frontend", Namespace: "default"}},
+		}
+		resp, err := engine.SimulatePolicySet(ctx, &req)
+		if err != nil {
+			t.Fatalf("SimulatePolicySet() error = %v", err)
+		}
+		if resp.WouldChangeCount != 2 {
+			t.Errorf("WouldChangeCount = %d, want 2", resp.WouldChangeCount)
+		}
+		if resp.BreakdownByVerdict.DeniedToAllowed != 2 {
+			t.Errorf("DeniedToAllowed = %d, want 2", resp.BreakdownByVerdict.DeniedToAllowed)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			source  PolicySetSource
+			current []PolicyDocument
+			changes []PolicyChange
+			wantErr string
+		}{
+			{
+				name:    "no policy source",
+				wantErr: "no policy source configured",
+			},
+			{
+				name:    "policy source fails",
+				source:  &staticPolicySource{err: errors.New("forbidden")},
+				wantErr: "forbidden",
+			},
+			{
+				name:    "invalid current policy",
+				current: []PolicyDocument{{Name: "broken", Content: "not: [valid"}},
+				wantErr: "broken",
+			},
+			{
+				name:    "invalid change",
+				current: []PolicyDocument{},
+				changes: []PolicyChange{{Operation: PolicyChangeModify, Policy: PolicyDocument{Content: backendAllowFrontend}}},
+				wantErr: "does not exist",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				engine := NewEngine(EngineConfig{StorageManager: mgr, PolicySource: tt.source, Logger: logr.Discard()})
+
+				req := baseReq
+				req.CurrentPolicies = tt.current
+				req.Changes = tt.changes
+				resp, err := engine.SimulatePolicySet(ctx, &req)
+				if err != nil {
+					t.Fatalf("SimulatePolicySet() error = %v", err)
+				}
+				if len(resp.Errors) == 0 || !strings.Contains(resp.Errors[0], tt.wantErr) {
+					t.Errorf("Errors = %v, want %q", resp.Errors, tt.wantErr)
+				}
+			})
+		}
+	})
+}
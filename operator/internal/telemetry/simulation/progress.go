@@ -0,0 +1,114 @@
+package simulation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/policy-hub/operator/internal/saas"
+)
+
+// progressTracker keeps the latest progress of a running simulation for the
+// periodic reports to SaaS.
+type progressTracker struct {
+	simulationID string
+	startedAt    time.Time
+
+	mu     sync.Mutex
+	latest SimulationProgress
+}
+
+// newProgressTracker creates a tracker for a simulation started at startedAt.
+func newProgressTracker(simulationID string, startedAt time.Time) *progressTracker {
+	return &progressTracker{
+		simulationID: simulationID,
+		startedAt:    startedAt,
+	}
+}
+
+// update records the latest progress reported by the engine.
+func (p *progressTracker) update(progress SimulationProgress) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.latest = progress
+}
+
+// report builds a progress report. The ETA extrapolates the time spent on
+// the files scanned so far to the remaining ones.
+func (p *progressTracker) report(now time.Time) *saas.SimulationProgress {
+	p.mu.Lock()
+	latest := p.latest
+	p.mu.Unlock()
+
+	report := &saas.SimulationProgress{
+		SimulationID:   p.simulationID,
+		Status:         saas.SimulationStatusRunning,
+		StartedAt:      p.startedAt,
+		FilesTotal:     latest.FilesTotal,
+		FilesScanned:   latest.FilesDone,
+		FlowsEvaluated: latest.EventsScanned,
+	}
+	if latest.FilesDone > 0 && latest.FilesDone < latest.FilesTotal {
+		elapsed := now.Sub(p.startedAt)
+		remaining := elapsed * time.Duration(latest.FilesTotal-latest.FilesDone) / time.Duration(latest.FilesDone)
+		report.ETASeconds = int64(remaining.Round(time.Second) / time.Second)
+	}
+	return report
+}
+
+// reportProgressUntilDone reports the progress of a simulation right away
+// and then every progress interval, and calls cancel once SaaS answers that
+// the simulation has been cancelled. The returned function stops the reports
+// and waits for the last one to finish.
+func (w *Worker) reportProgressUntilDone(ctx context.Context, progress *progressTracker, cancel context.CancelFunc) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(w.progressInterval)
+		defer ticker.Stop()
+
+		for {
+			if w.reportProgress(ctx, progress.report(time.Now())) {
+				w.log.Info("Simulation cancelled in SaaS", "simulationId", progress.simulationID)
+				cancel()
+				return
+			}
+
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// reportProgress sends a progress report to SaaS and returns whether the
+// simulation has been cancelled there. Reports are best effort: failures are
+// logged and do not affect the simulation.
+func (w *Worker) reportProgress(ctx context.Context, progress *saas.SimulationProgress) bool {
+	if w.saasClient == nil {
+		return false
+	}
+
+	progress.NodeName = w.saasClient.GetNodeName()
+	resp, err := w.saasClient.ReportSimulationProgress(ctx, progress)
+	if err != nil {
+		w.log.V(1).Info("Failed to report simulation progress",
+			"simulationId", progress.SimulationID,
+			"error", err.Error(),
+		)
+		return false
+	}
+	return resp.Cancelled
+}
@@ -0,0 +1,70 @@
+package simulation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/policy-hub/operator/internal/saas"
+)
+
+func TestProgressTracker_Report(t *testing.T) {
+	startedAt := time.Now()
+	tracker := newProgressTracker("sim-1", startedAt)
+
+	tests := []struct {
+		name     string
+		progress SimulationProgress
+		wantETA  int64
+	}{
+		{name: "no file scanned yet", progress: SimulationProgress{FilesTotal: 6}, wantETA: 0},
+		{name: "a third done", progress: SimulationProgress{FilesTotal: 6, FilesDone: 2, EventsScanned: 500}, wantETA: 20},
+		{name: "all files done", progress: SimulationProgress{FilesTotal: 6, FilesDone: 6, EventsScanned: 1500}, wantETA: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker.update(tt.progress)
+			report := tracker.report(startedAt.Add(10 * time.Second))
+
+			if report.SimulationID != "sim-1" || report.Status != saas.SimulationStatusRunning {
+				t.Errorf("report = %+v, want sim-1 running", report)
+			}
+			if report.FilesScanned != tt.progress.FilesDone || report.FlowsEvaluated != tt.progress.EventsScanned {
+				t.Errorf("report = %+v, want %+v", report, tt.progress)
+			}
+			if report.ETASeconds != tt.wantETA {
+				t.Errorf("ETASeconds = %d, want %d", report.ETASeconds, tt.wantETA)
+			}
+		})
+	}
+}
+
+func TestWorker_ReportProgressUntilDone_Cancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(saas.ReportSimulationProgressResponse{Success: true, Cancelled: true})
+	}))
+	defer server.Close()
+
+	worker := NewWorker(WorkerConfig{
+		SaaSClient:       saas.NewClient(server.URL, "test-token", "test-cluster", logr.Discard()),
+		ProgressInterval: 10 * time.Millisecond,
+		Logger:           logr.Discard(),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stop := worker.reportProgressUntilDone(ctx, newProgressTracker("sim-1", time.Now()), cancel)
+	defer stop()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("simulation not cancelled after SaaS reported the cancellation")
+	}
+}
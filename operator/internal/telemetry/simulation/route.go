@@ -0,0 +1,452 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+)
+
+// Outcomes of routing a request through a Gateway API route.
+const (
+	routeOutcomeRouted     = "ROUTED"
+	routeOutcomeRedirected = "REDIRECTED"
+	routeOutcomeNoBackend  = "NO_BACKEND"
+	routeOutcomeNotFound   = "NOT_FOUND"
+)
+
+// routeRequest is the part of a recorded L7 request a route matches on.
+type routeRequest struct {
+	method      string
+	host        string
+	path        string
+	query       url.Values
+	headers     map[string]string
+	grpcService string
+	grpcMethod  string
+}
+
+// simulateRoute evaluates a Gateway API HTTPRoute or GRPCRoute against
+// recorded L7 requests.
+func (e *Engine) simulateRoute(ctx context.Context, req *SimulationRequest, policy *ParsedPolicy, startTime time.Time) (*SimulationResponse, error) {
+	queryReq := models.QueryEventsRequest{
+		StartTime:  req.StartTime,
+		EndTime:    req.EndTime,
+		EventTypes: []string{string(models.EventTypeFlow)},
+		Limit:      0, // Get all matching events
+	}
+
+	maxDetails := int(req.MaxDetails)
+	if maxDetails == 0 {
+		maxDetails = 100 // Default limit
+	}
+
+	// Every partial lists all rules, so rules no request matched still show
+	newPartial := func() *SimulationResponse {
+		partial := &SimulationResponse{
+			BreakdownByRouteRule: make(map[string]*RouteRuleImpact),
+			BreakdownByBackend:   make(map[string]*BackendImpact),
+			RouteDetails:         []*RouteSimulationResult{},
+		}
+		for i := range policy.RouteRules {
+			rule := &policy.RouteRules[i]
+			impact := &RouteRuleImpact{Rule: routeRuleName(i)}
+			for _, backend := range rule.Backends {
+				impact.Backends = append(impact.Backends, routeBackendName(backend))
+			}
+			partial.BreakdownByRouteRule[impact.Rule] = impact
+		}
+		return partial
+	}
+
+	grpc := policy.Type == "GRPCRoute"
+	response, err := e.streamEvaluate(ctx, queryReq, req.Progress, maxDetails, startTime, newPartial, func(partial *SimulationResponse, event *models.TelemetryEvent) {
+		request, ok := routeRequestFromEvent(event, grpc)
+		if !ok || !routeAccepts(event, &request, policy) {
+			return
+		}
+		partial.TotalFlowsAnalyzed++
+
+		routeResult, rule := evaluateRoute(event, &request, policy)
+		if routeResult.Outcome == routeOutcomeNotFound {
+			partial.NotFoundCount++
+		} else {
+			partial.RoutedCount++
+			partial.BreakdownByRouteRule[routeResult.MatchedRule].Requests++
+		}
+		if routeResult.Outcome == routeOutcomeRouted {
+			updateBackendBreakdown(partial.BreakdownByBackend, rule)
+		}
+
+		if req.IncludeDetails && len(partial.RouteDetails) < maxDetails {
+			partial.RouteDetails = append(partial.RouteDetails, routeResult)
+		}
+	})
+	if err != nil || len(response.Errors) > 0 {
+		return response, err
+	}
+
+	e.log.Info("Route simulation complete",
+		"totalRequests", response.TotalFlowsAnalyzed,
+		"routed", response.RoutedCount,
+		"notFound", response.NotFoundCount,
+		"duration", response.Duration,
+	)
+
+	return response, nil
+}
+
+// routeAccepts checks whether a request reaches a route at all: it must be
+// addressed to one of the route's parents and carry one of its hostnames.
+// Other requests are handled by other routes and are not counted.
+func routeAccepts(event *models.TelemetryEvent, request *routeRequest, policy *ParsedPolicy) bool {
+	return parentRefMatches(event, policy.ParentRefs) && hostnameMatches(request.host, policy.Hostnames)
+}
+
+// evaluateRoute routes a single request accepted by a route through its
+// rules. It also returns the rule that handled the request, if any.
+func evaluateRoute(event *models.TelemetryEvent, request *routeRequest, policy *ParsedPolicy) (*RouteSimulationResult, *RouteRule) {
+	result := &RouteSimulationResult{
+		Timestamp:    event.Timestamp,
+		SrcNamespace: event.SrcNamespace,
+		SrcPodName:   event.SrcPodName,
+		DstNamespace: event.DstNamespace,
+		DstPodName:   event.DstPodName,
+		Method:       request.method,
+		Host:         request.host,
+		Path:         request.path,
+	}
+
+	ruleIndex, matchIndex := selectRouteRule(request, policy)
+	if ruleIndex < 0 {
+		result.Outcome = routeOutcomeNotFound
+		result.StatusCode = 404
+		result.MatchReason = "No rule matches the request"
+		return result, nil
+	}
+
+	rule := &policy.RouteRules[ruleIndex]
+	result.MatchedRule = routeRuleName(ruleIndex)
+	if matchIndex >= 0 {
+		result.MatchReason = fmt.Sprintf("Matched %s match[%d]", result.MatchedRule, matchIndex)
+	} else {
+		result.MatchReason = fmt.Sprintf("Matched %s without matches", result.MatchedRule)
+	}
+
+	if rule.RedirectStatus != 0 {
+		result.Outcome = routeOutcomeRedirected
+		result.StatusCode = rule.RedirectStatus
+		return result, rule
+	}
+
+	for _, backend := range rule.Backends {
+		if backend.Weight > 0 {
+			result.Backends = append(result.Backends, routeBackendName(backend))
+		}
+	}
+	if len(result.Backends) == 0 {
+		// Gateway API requires a 500 when a rule has no usable backend
+		result.Outcome = routeOutcomeNoBackend
+		result.StatusCode = 500
+		return result, rule
+	}
+	result.Outcome = routeOutcomeRouted
+	return result, rule
+}
+
+// selectRouteRule returns the rule and match that handle a request, using
+// the Gateway API precedence between matching rules. Ties go to the earlier
+// rule. Returns -1 if no rule matches; the match index is -1 for a rule
+// without matches.
+func selectRouteRule(request *routeRequest, policy *ParsedPolicy) (int, int) {
+	bestRule, bestMatch := -1, -1
+	var bestRank []int
+
+	// A rule without matches has a single implicit match: PathPrefix / for
+	// HTTPRoutes and any method for GRPCRoutes
+	implicit := RouteMatch{PathType: "PathPrefix", PathValue: "/"}
+	if policy.Type == "GRPCRoute" {
+		implicit = RouteMatch{}
+	}
+
+	for i := range policy.RouteRules {
+		rule := &policy.RouteRules[i]
+		if len(rule.Matches) == 0 {
+			rank := routeMatchRank(&implicit)
+			if bestRule < 0 || rankGreater(rank, bestRank) {
+				bestRule, bestMatch, bestRank = i, -1, rank
+			}
+			continue
+		}
+		for j := range rule.Matches {
+			match := &rule.Matches[j]
+			if !routeMatches(request, match) {
+				continue
+			}
+			rank := routeMatchRank(match)
+			if bestRule < 0 || rankGreater(rank, bestRank) {
+				bestRule, bestMatch, bestRank = i, j, rank
+			}
+		}
+	}
+	return bestRule, bestMatch
+}
+
+// routeMatchRank orders matches by the Gateway API precedence rules.
+func routeMatchRank(match *RouteMatch) []int {
+	if match.GRPCService != "" || match.GRPCMethod != "" || match.PathType == "" {
+		// GRPCRoute: service characters, method characters, header matches
+		return []int{len(match.GRPCService), len(match.GRPCMethod), len(match.Headers)}
+	}
+
+	// HTTPRoute: exact path, longest prefix, method, header and query matches
+	pathRank := 0
+	switch match.PathType {
+	case "Exact":
+		pathRank = 2
+	case "PathPrefix":
+		pathRank = 1
+	}
+	method := 0
+	if match.Method != "" {
+		method = 1
+	}
+	return []int{pathRank, len(match.PathValue), method, len(match.Headers), len(match.QueryParams)}
+}
+
+// rankGreater compares two ranks lexicographically.
+func rankGreater(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] > b[i]
+		}
+	}
+	return len(a) > len(b)
+}
+
+// routeMatches checks all conditions of a route match.
+func routeMatches(request *routeRequest, match *RouteMatch) bool {
+	if match.PathType != "" && !routePathMatches(match.PathType, match.PathValue, request.path) {
+		return false
+	}
+	if match.Method != "" && !strings.EqualFold(match.Method, request.method) {
+		return false
+	}
+	if match.GRPCService != "" && !routeValueMatches(match.GRPCMatchType, match.GRPCService, request.grpcService) {
+		return false
+	}
+	if match.GRPCMethod != "" && !routeValueMatches(match.GRPCMatchType, match.GRPCMethod, request.grpcMethod) {
+		return false
+	}
+	for _, h := range match.Headers {
+		value, ok := request.headers[strings.ToLower(h.Name)]
+		if !ok || !routeValueMatches(h.Type, h.Value, value) {
+			return false
+		}
+	}
+	for _, q := range match.QueryParams {
+		values, ok := request.query[q.Name]
+		if !ok || len(values) == 0 || !routeValueMatches(q.Type, q.Value, values[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+// routePathMatches evaluates an HTTPRoute path match. Prefixes match whole
+// path elements, so /foo matches /foo/bar but not /foobar.
+func routePathMatches(pathType, value, path string) bool {
+	switch pathType {
+	case "Exact":
+		return path == value
+	case "PathPrefix":
+		prefix := strings.TrimSuffix(value, "/")
+		return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+	case "RegularExpression":
+		return routeValueMatches("RegularExpression", value, path)
+	default:
+		return false
+	}
+}
+
+// routeValueMatches compares a value exactly or against a fully anchored
+// regular expression.
+func routeValueMatches(matchType, want, value string) bool {
+	if matchType == "RegularExpression" {
+		re, err := regexp.Compile("^(?:" + want + ")$")
+		return err == nil && re.MatchString(value)
+	}
+	return want == value
+}
+
+// gatewayNameLabel is set by Gateway API implementations on the pods of a
+// Gateway.
+const gatewayNameLabel = "gateway.networking.k8s.io/gateway-name"
+
+// parentRefMatches checks whether a request is addressed to one of a route's
+// parents. A Gateway is recognised by the label on its pods or by its
+// Service name, which Cilium prefixes with cilium-gateway-; a mesh Service by
+// its DNS name. Routes without parentRefs accept every request.
+func parentRefMatches(event *models.TelemetryEvent, parents []RouteParentRef) bool {
+	if len(parents) == 0 {
+		return true
+	}
+	dnsName := strings.TrimSuffix(strings.ToLower(event.DstDNSName), ".")
+	for _, parent := range parents {
+		if parent.Port != 0 && event.DstPort != uint32(parent.Port) {
+			continue
+		}
+		serviceDNS := func(name string) bool {
+			service := name + "." + parent.Namespace + ".svc"
+			return dnsName == service || strings.HasPrefix(dnsName, service+".")
+		}
+		switch parent.Kind {
+		case "Gateway":
+			if event.DstNamespace == parent.Namespace && event.DstPodLabels[gatewayNameLabel] == parent.Name {
+				return true
+			}
+			if serviceDNS(parent.Name) || serviceDNS("cilium-gateway-"+parent.Name) {
+				return true
+			}
+		case "Service":
+			if serviceDNS(parent.Name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hostnameMatches checks a request host against route hostnames. Routes
+// without hostnames accept every host. Requests whose host was not recorded
+// are assumed to match, since only the route's rules can be checked.
+func hostnameMatches(host string, hostnames []string) bool {
+	if len(hostnames) == 0 || host == "" {
+		return true
+	}
+	for _, hostname := range hostnames {
+		if matchFQDN(host, strings.ToLower(hostname)) {
+			return true
+		}
+	}
+	return false
+}
+
+// routeRequestFromEvent extracts the routed request from an L7 flow. Only
+// HTTP requests are considered; for GRPCRoutes the service and method come
+// from the gRPC fields or the /service/method request path.
+func routeRequestFromEvent(event *models.TelemetryEvent, grpc bool) (routeRequest, bool) {
+	if strings.EqualFold(event.L7Type, "RESPONSE") {
+		return routeRequest{}, false
+	}
+	if event.HTTPMethod == "" && event.HTTPPath == "" && event.GRPCService == "" {
+		return routeRequest{}, false
+	}
+
+	request := routeRequest{
+		method:      event.HTTPMethod,
+		path:        "/",
+		headers:     parseHTTPHeaders(event.HTTPHeaders),
+		grpcService: event.GRPCService,
+		grpcMethod:  event.GRPCMethod,
+	}
+
+	// Hubble records the full request URL
+	if u, err := url.Parse(event.HTTPPath); err == nil {
+		if u.Path != "" {
+			request.path = u.Path
+		}
+		request.query = u.Query()
+		request.host = u.Host
+	}
+	if event.HTTPHost != "" {
+		request.host = event.HTTPHost
+	}
+	if request.host == "" {
+		request.host = request.headers[":authority"]
+		if request.host == "" {
+			request.host = request.headers["host"]
+		}
+	}
+	if h, _, err := net.SplitHostPort(request.host); err == nil {
+		request.host = h
+	}
+	request.host = strings.TrimSuffix(strings.ToLower(request.host), ".")
+
+	if grpc {
+		if request.grpcService == "" {
+			parts := strings.Split(strings.TrimPrefix(request.path, "/"), "/")
+			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return routeRequest{}, false
+			}
+			request.grpcService, request.grpcMethod = parts[0], parts[1]
+		}
+		request.path = "/" + request.grpcService + "/" + request.grpcMethod
+	}
+
+	return request, true
+}
+
+// parseHTTPHeaders parses headers recorded as key=value pairs separated by
+// semicolons. Keys are lowercased, as header names are case-insensitive.
+func parseHTTPHeaders(raw string) map[string]string {
+	headers := make(map[string]string)
+	if raw == "" {
+		return headers
+	}
+	for _, pair := range strings.Split(raw, ";") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if _, exists := headers[key]; !exists {
+			headers[key] = strings.TrimSpace(value)
+		}
+	}
+	return headers
+}
+
+// updateBackendBreakdown credits the backends of the rule a request was
+// routed by, splitting the expected traffic by backend weight.
+func updateBackendBreakdown(breakdown map[string]*BackendImpact, rule *RouteRule) {
+	var totalWeight int32
+	for _, backend := range rule.Backends {
+		totalWeight += backend.Weight
+	}
+	for _, backend := range rule.Backends {
+		if backend.Weight <= 0 {
+			continue
+		}
+		name := routeBackendName(backend)
+		impact, ok := breakdown[name]
+		if !ok {
+			impact = &BackendImpact{Backend: name}
+			breakdown[name] = impact
+		}
+		impact.Requests++
+		impact.ExpectedRequests += float64(backend.Weight) / float64(totalWeight)
+	}
+}
+
+// routeRuleName identifies a route rule in results.
+func routeRuleName(index int) string {
+	return fmt.Sprintf("rule[%d]", index)
+}
+
+// routeBackendName identifies a backendRef in results.
+func routeBackendName(backend RouteBackend) string {
+	name := policyKey(backend.Namespace, backend.Name)
+	if backend.Kind != "" && backend.Kind != "Service" {
+		name = backend.Kind + "/" + name
+	}
+	if backend.Port != 0 {
+		name = fmt.Sprintf("%s:%d", name, backend.Port)
+	}
+	return name
+}
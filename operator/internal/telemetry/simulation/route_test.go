@@ -0,0 +1,477 @@
+package simulation
+
+import (
+	"context"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+	"github.com/policy-hub/operator/internal/telemetry/storage"
+)
+
+// storeRoute splits /api traffic between two versions, sends /api/admin to an
+// admin backend and redirects /old.
+const storeRoute = `
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: store
+  namespace: shop
+spec:
+  hostnames:
+    - store.example.com
+    - "*.store.example.com"
+  rules:
+    - matches:
+        - path:
+            type: PathPrefix
+            value: /api
+      backendRefs:
+        - name: api-v1
+          port: 8080
+          weight: 90
+        - name: api-v2
+          port: 8080
+          weight: 10
+    - matches:
+        - path:
+            type: PathPrefix
+            value: /api/admin
+          method: POST
+        - path:
+            type: Exact
+            value: /api/admin/debug
+          headers:
+            - name: X-Debug
+              value: "true"
+      backendRefs:
+        - name: admin
+          port: 9090
+    - matches:
+        - path:
+            type: Exact
+            value: /old
+      filters:
+        - type: RequestRedirect
+          requestRedirect:
+            statusCode: 301
+    - matches:
+        - path:
+            type: PathPrefix
+            value: /disabled
+      backendRefs:
+        - name: legacy
+          weight: 0
+`
+
+const paymentsGRPCRoute = `
+apiVersion: gateway.networking.k8s.io/v1
+kind: GRPCRoute
+metadata:
+  name: payments
+  namespace: shop
+spec:
+  rules:
+    - matches:
+        - method:
+            service: payments.v1.Payments
+      backendRefs:
+        - name: payments
+          port: 50051
+    - matches:
+        - method:
+            service: payments.v1.Payments
+            method: Refund
+      backendRefs:
+        - name: refunds
+          port: 50051
+`
+
+func routeEvent(method, url, headers string) *models.TelemetryEvent {
+	return &models.TelemetryEvent{
+		EventType:    models.EventTypeFlow,
+		SrcNamespace: "shop",
+		SrcPodName:   "frontend-1",
+		DstNamespace: "shop",
+		L7Type:       "REQUEST",
+		HTTPMethod:   method,
+		HTTPPath:     url,
+		HTTPHeaders:  headers,
+	}
+}
+
+func TestEvaluateRoute_HTTPRoute(t *testing.T) {
+	parser := NewPolicyParser()
+	policy, err := parser.Parse(storeRoute, "GATEWAY_HTTPROUTE")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		event       *models.TelemetryEvent
+		wantOutcome string
+		wantStatus  int32
+		wantRule    string
+		wantBackend string
+	}{
+		{
+			name:        "prefix match",
+			event:       routeEvent("GET", "http://store.example.com/api/items?id=1", ""),
+			wantOutcome: routeOutcomeRouted,
+			wantRule:    "rule[0]",
+			wantBackend: "shop/api-v1:8080",
+		},
+		{
+			name:        "prefix matches whole path elements only",
+			event:       routeEvent("GET", "http://store.example.com/apis", ""),
+			wantOutcome: routeOutcomeNotFound,
+			wantStatus:  404,
+		},
+		{
+			name:        "longer prefix with method wins",
+			event:       routeEvent("POST", "http://store.example.com/api/admin/users", ""),
+			wantOutcome: routeOutcomeRouted,
+			wantRule:    "rule[1]",
+			wantBackend: "shop/admin:9090",
+		},
+		{
+			name:        "method mismatch falls back to shorter prefix",
+			event:       routeEvent("GET", "http://store.example.com/api/admin/users", ""),
+			wantOutcome: routeOutcomeRouted,
+			wantRule:    "rule[0]",
+			wantBackend: "shop/api-v1:8080",
+		},
+		{
+			name:        "exact path with header",
+			event:       routeEvent("GET", "http://store.example.com/api/admin/debug", "X-Debug=true"),
+			wantOutcome: routeOutcomeRouted,
+			wantRule:    "rule[1]",
+			wantBackend: "shop/admin:9090",
+		},
+		{
+			name:        "header mismatch",
+			event:       routeEvent("GET", "http://store.example.com/api/admin/debug", "X-Debug=false"),
+			wantOutcome: routeOutcomeRouted,
+			wantRule:    "rule[0]",
+			wantBackend: "shop/api-v1:8080",
+		},
+		{
+			name:        "wildcard hostname",
+			event:       routeEvent("GET", "http://eu.store.example.com:8443/api", ""),
+			wantOutcome: routeOutcomeRouted,
+			wantRule:    "rule[0]",
+			wantBackend: "shop/api-v1:8080",
+		},
+		{
+			name:        "redirect",
+			event:       routeEvent("GET", "http://store.example.com/old", ""),
+			wantOutcome: routeOutcomeRedirected,
+			wantStatus:  301,
+			wantRule:    "rule[2]",
+		},
+		{
+			name:        "rule without usable backend",
+			event:       routeEvent("GET", "http://store.example.com/disabled", ""),
+			wantOutcome: routeOutcomeNoBackend,
+			wantStatus:  500,
+			wantRule:    "rule[3]",
+		},
+		{
+			name:        "no rule matches",
+			event:       routeEvent("GET", "http://store.example.com/", ""),
+			wantOutcome: routeOutcomeNotFound,
+			wantStatus:  404,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, ok := routeRequestFromEvent(tt.event, false)
+			if !ok {
+				t.Fatal("routeRequestFromEvent() ok = false")
+			}
+			result, _ := evaluateRoute(tt.event, &request, policy)
+			if result.Outcome != tt.wantOutcome {
+				t.Errorf("Outcome = %s, want %s (%s)", result.Outcome, tt.wantOutcome, result.MatchReason)
+			}
+			if result.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", result.StatusCode, tt.wantStatus)
+			}
+			if result.MatchedRule != tt.wantRule {
+				t.Errorf("MatchedRule = %q, want %q", result.MatchedRule, tt.wantRule)
+			}
+			if tt.wantBackend != "" && (len(result.Backends) == 0 || result.Backends[0] != tt.wantBackend) {
+				t.Errorf("Backends = %v, want first %s", result.Backends, tt.wantBackend)
+			}
+		})
+	}
+}
+
+func TestEvaluateRoute_GRPCRoute(t *testing.T) {
+	parser := NewPolicyParser()
+	policy, err := parser.Parse(paymentsGRPCRoute, "GATEWAY_GRPCROUTE")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		event       *models.TelemetryEvent
+		wantOutcome string
+		wantRule    string
+	}{
+		{
+			name:        "service match",
+			event:       routeEvent("POST", "http://payments:50051/payments.v1.Payments/Charge", ""),
+			wantOutcome: routeOutcomeRouted,
+			wantRule:    "rule[0]",
+		},
+		{
+			name:        "service and method match wins",
+			event:       routeEvent("POST", "http://payments:50051/payments.v1.Payments/Refund", ""),
+			wantOutcome: routeOutcomeRouted,
+			wantRule:    "rule[1]",
+		},
+		{
+			name:        "unknown service",
+			event:       routeEvent("POST", "http://payments:50051/grpc.health.v1.Health/Check", ""),
+			wantOutcome: routeOutcomeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, ok := routeRequestFromEvent(tt.event, true)
+			if !ok {
+				t.Fatal("routeRequestFromEvent() ok = false")
+			}
+			result, _ := evaluateRoute(tt.event, &request, policy)
+			if result.Outcome != tt.wantOutcome {
+				t.Errorf("Outcome = %s, want %s (%s)", result.Outcome, tt.wantOutcome, result.MatchReason)
+			}
+			if result.MatchedRule != tt.wantRule {
+				t.Errorf("MatchedRule = %q, want %q", result.MatchedRule, tt.wantRule)
+			}
+		})
+	}
+
+	// Non-gRPC paths are not requests a GRPCRoute could see
+	if _, ok := routeRequestFromEvent(routeEvent("GET", "http://payments/healthz", ""), true); ok {
+		t.Error("routeRequestFromEvent() ok = true for non-gRPC path")
+	}
+}
+
+func TestEvaluateRoute_RuleWithoutMatches(t *testing.T) {
+	parser := NewPolicyParser()
+	policy, err := parser.Parse(`
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: site
+  namespace: shop
+spec:
+  rules:
+    - backendRefs:
+        - name: web
+    - matches:
+        - path:
+            type: PathPrefix
+            value: /api
+      backendRefs:
+        - name: api
+    - matches:
+        - path:
+            type: PathPrefix
+            value: /
+          method: GET
+      backendRefs:
+        - name: cache
+    - matches:
+        - path:
+            type: PathPrefix
+            value: /
+      backendRefs:
+        - name: fallback
+`, "GATEWAY_HTTPROUTE")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		event    *models.TelemetryEvent
+		wantRule string
+	}{
+		{name: "longer prefix beats implicit match", event: routeEvent("POST", "http://shop/api/orders", ""), wantRule: "rule[1]"},
+		{name: "method beats implicit match", event: routeEvent("GET", "http://shop/index.html", ""), wantRule: "rule[2]"},
+		{name: "implicit match ties with a later root prefix", event: routeEvent("POST", "http://shop/login", ""), wantRule: "rule[0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, ok := routeRequestFromEvent(tt.event, false)
+			if !ok {
+				t.Fatal("routeRequestFromEvent() ok = false")
+			}
+			result, _ := evaluateRoute(tt.event, &request, policy)
+			if result.MatchedRule != tt.wantRule {
+				t.Errorf("MatchedRule = %q, want %q (%s)", result.MatchedRule, tt.wantRule, result.MatchReason)
+			}
+		})
+	}
+}
+
+func TestRouteAccepts(t *testing.T) {
+	parser := NewPolicyParser()
+	policy, err := parser.Parse(`
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: store
+  namespace: shop
+spec:
+  parentRefs:
+    - name: public
+      namespace: infra
+  hostnames:
+    - store.example.com
+  rules:
+    - backendRefs:
+        - name: web
+`, "GATEWAY_HTTPROUTE")
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+
+	toGateway := func(url string) *models.TelemetryEvent {
+		event := routeEvent("GET", url, "")
+		event.DstNamespace = "infra"
+		event.DstPodLabels = map[string]string{gatewayNameLabel: "public"}
+		return event
+	}
+	toService := func(dnsName string) *models.TelemetryEvent {
+		event := routeEvent("GET", "http://store.example.com/", "")
+		event.DstNamespace = "infra"
+		event.DstDNSName = dnsName
+		return event
+	}
+	otherGateway := toGateway("http://store.example.com/")
+	otherGateway.DstPodLabels[gatewayNameLabel] = "internal"
+
+	tests := []struct {
+		name  string
+		event *models.TelemetryEvent
+		want  bool
+	}{
+		{name: "gateway pod and hostname", event: toGateway("http://store.example.com/"), want: true},
+		{name: "hostname mismatch", event: toGateway("http://blog.example.com/"), want: false},
+		{name: "other gateway", event: otherGateway, want: false},
+		{name: "gateway service", event: toService("public.infra.svc.cluster.local"), want: true},
+		{name: "cilium gateway service", event: toService("cilium-gateway-public.infra.svc.cluster.local."), want: true},
+		{name: "other service", event: toService("public-api.infra.svc.cluster.local"), want: false},
+		{name: "pod to pod", event: routeEvent("GET", "http://store.example.com/", ""), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, ok := routeRequestFromEvent(tt.event, false)
+			if !ok {
+				t.Fatal("routeRequestFromEvent() ok = false")
+			}
+			if got := routeAccepts(tt.event, &request, policy); got != tt.want {
+				t.Errorf("routeAccepts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngine_Simulate_HTTPRoute(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "engine-route-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cfg := storage.ManagerConfig{
+		BasePath: tmpDir,
+		NodeName: "test-node",
+		Logger:   logr.Discard(),
+	}
+	writer, err := storage.NewManager(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+
+	now := time.Now().UTC()
+	events := []*models.TelemetryEvent{
+		routeEvent("GET", "http://store.example.com/api/items", ""),
+		routeEvent("GET", "http://store.example.com/api/items/7", ""),
+		routeEvent("POST", "http://store.example.com/api/admin/users", ""),
+		routeEvent("GET", "http://store.example.com/old", ""),
+		routeEvent("GET", "http://store.example.com/favicon.ico", ""),
+		routeEvent("GET", "http://blog.example.com/api/items", ""),
+		{EventType: models.EventTypeFlow, SrcNamespace: "shop", L7Type: "RESPONSE", HTTPPath: "http://store.example.com/api/items"},
+		{EventType: models.EventTypeFlow, SrcNamespace: "shop", DstPort: 5432},
+	}
+	for i, event := range events {
+		event.ID = string(rune('a' + i))
+		event.Timestamp = now.Add(-10 * time.Minute)
+		event.NodeName = "test-node"
+		event.Verdict = models.VerdictAllowed
+	}
+	if err := writer.Write(events); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	// Close the manager to ensure all data is flushed and indexed
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	mgr, err := storage.NewManager(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+	defer mgr.Close()
+
+	engine := NewEngine(EngineConfig{StorageManager: mgr, Logger: logr.Discard()})
+	resp, err := engine.Simulate(context.Background(), &SimulationRequest{
+		PolicyContent:  storeRoute,
+		PolicyType:     "GATEWAY_HTTPROUTE",
+		StartTime:      now.Add(-1 * time.Hour),
+		EndTime:        now,
+		IncludeDetails: true,
+	})
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(resp.Errors) > 0 {
+		t.Fatalf("Simulate() errors = %v", resp.Errors)
+	}
+
+	if resp.TotalFlowsAnalyzed != 5 {
+		t.Errorf("TotalFlowsAnalyzed = %d, want 5 requests", resp.TotalFlowsAnalyzed)
+	}
+	if resp.RoutedCount != 4 || resp.NotFoundCount != 1 {
+		t.Errorf("Routed/NotFound = %d/%d, want 4/1", resp.RoutedCount, resp.NotFoundCount)
+	}
+	if got := resp.BreakdownByRouteRule["rule[0]"]; got == nil || got.Requests != 2 || len(got.Backends) != 2 {
+		t.Errorf("BreakdownByRouteRule[rule[0]] = %+v", got)
+	}
+	if got := resp.BreakdownByRouteRule["rule[3]"]; got == nil || got.Requests != 0 {
+		t.Errorf("BreakdownByRouteRule[rule[3]] = %+v, want unused rule reported", got)
+	}
+	if got := resp.BreakdownByBackend["shop/api-v2:8080"]; got == nil || got.Requests != 2 || math.Abs(got.ExpectedRequests-0.2) > 1e-9 {
+		t.Errorf("BreakdownByBackend[shop/api-v2:8080] = %+v", got)
+	}
+	if got := resp.BreakdownByBackend["shop/admin:9090"]; got == nil || got.Requests != 1 || got.ExpectedRequests != 1 {
+		t.Errorf("BreakdownByBackend[shop/admin:9090] = %+v", got)
+	}
+	if len(resp.RouteDetails) != 5 {
+		t.Errorf("len(RouteDetails) = %d, want 5", len(resp.RouteDetails))
+	}
+}
@@ -0,0 +1,328 @@
+package simulation
+
+import (
+	"net"
+	"strings"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+)
+
+// Reserved Cilium security identities (see pkg/identity in Cilium).
+const (
+	identityHost          uint32 = 1
+	identityWorld         uint32 = 2
+	identityUnmanaged     uint32 = 3
+	identityHealth        uint32 = 4
+	identityInit          uint32 = 5
+	identityRemoteNode    uint32 = 6
+	identityKubeAPIServer uint32 = 7
+	identityIngress       uint32 = 8
+	identityWorldIPv4     uint32 = 9
+	identityWorldIPv6     uint32 = 10
+
+	// localIdentityFlag marks node-local identities, which Cilium allocates
+	// for CIDR-derived (i.e. world) peers.
+	localIdentityFlag uint32 = 1 << 24
+)
+
+// flowPeer is the remote side of a flow from the point of view of the
+// endpoint a policy is applied to: the source for ingress, the destination
+// for egress.
+type flowPeer struct {
+	namespace string
+	labels    map[string]string
+	ip        string
+	identity  uint32
+}
+
+// peerForDirection returns the peer that ingress/egress rules select.
+func peerForDirection(event *models.TelemetryEvent, direction string) flowPeer {
+	if direction == "ingress" {
+		return flowPeer{
+			namespace: event.SrcNamespace,
+			labels:    event.SrcPodLabels,
+			ip:        event.SrcIP,
+			identity:  event.SrcIdentity,
+		}
+	}
+	return flowPeer{
+		namespace: event.DstNamespace,
+		labels:    event.DstPodLabels,
+		ip:        event.DstIP,
+		identity:  event.DstIdentity,
+	}
+}
+
+// peerMatchesRule checks the L3 peer selectors of a rule. Selector kinds that
+// are not set on the rule are ignored.
+func peerMatchesRule(peer flowPeer, rule *PolicyRule, direction string) bool {
+	// Endpoint selectors (fromEndpoints/toEndpoints)
+	// Use labelsMatch to handle both normalized and k8s:-prefixed labels
+	if len(rule.PodSelector) > 0 {
+		if !labelsMatch(peer.labels, rule.PodSelector) {
+			return false
+		}
+	}
+	if nsName, ok := rule.NamespaceSelector["name"]; ok {
+		if peer.namespace != nsName {
+			return false
+		}
+	}
+	if len(rule.MatchExpressions) > 0 {
+		if !expressionsMatch(peer.labels, peer.namespace, rule.MatchExpressions) {
+			return false
+		}
+	}
+	if rule.Endpoints && len(rule.PodSelector) == 0 && len(rule.MatchExpressions) == 0 && isWorldPeer(peer) {
+		// An empty endpoint selector selects all cluster endpoints, never the world
+		return false
+	}
+
+	// CIDR selectors
+	cidrs, cidrSet := rule.FromCIDRs, rule.FromCIDRSet
+	if direction == "egress" {
+		cidrs, cidrSet = rule.ToCIDRs, rule.ToCIDRSet
+	}
+	if len(cidrs) > 0 || len(cidrSet) > 0 {
+		if !cidrMatches(peer, cidrs, cidrSet) {
+			return false
+		}
+	}
+
+	// Entity selectors
+	if len(rule.Entities) > 0 {
+		matched := false
+		for _, entity := range rule.Entities {
+			if entityMatches(entity, peer) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// selectorMatches checks whether a peer matches an EndpointSelector.
+func selectorMatches(peer flowPeer, sel EndpointSelector) bool {
+	for key, value := range sel.MatchLabels {
+		if key == namespaceLabel {
+			if peer.namespace != value {
+				return false
+			}
+			continue
+		}
+		if v, ok := getLabelValue(peer.labels, key); !ok || v != value {
+			return false
+		}
+	}
+	return expressionsMatch(peer.labels, peer.namespace, sel.MatchExpressions)
+}
+
+// expressionsMatch evaluates matchExpressions against a label set. The
+// namespace is exposed under Cilium's pod namespace label, since flow labels
+// do not carry it.
+func expressionsMatch(labels map[string]string, namespace string, exprs []SelectorRequirement) bool {
+	for _, expr := range exprs {
+		var value string
+		var exists bool
+		if expr.Key == namespaceLabel {
+			value, exists = namespace, namespace != ""
+		} else {
+			value, exists = getLabelValue(labels, expr.Key)
+		}
+
+		switch expr.Operator {
+		case "In":
+			if !exists || !containsString(expr.Values, value) {
+				return false
+			}
+		case "NotIn":
+			if exists && containsString(expr.Values, value) {
+				return false
+			}
+		case "Exists":
+			if !exists {
+				return false
+			}
+		case "DoesNotExist":
+			if exists {
+				return false
+			}
+		default:
+			// Unknown operators never match, as in the Kubernetes selector semantics
+			return false
+		}
+	}
+	return true
+}
+
+// isWorldPeer reports whether a peer lies outside the cluster.
+func isWorldPeer(peer flowPeer) bool {
+	switch {
+	case peer.identity == identityWorld || peer.identity == identityWorldIPv4 || peer.identity == identityWorldIPv6:
+		return true
+	case peer.identity >= localIdentityFlag:
+		return true
+	case peer.identity != 0:
+		return false
+	}
+
+	for _, key := range []string{"world", "world-ipv4", "world-ipv6"} {
+		if _, ok := peer.labels[key]; ok {
+			return true
+		}
+	}
+
+	// Without an identity, a peer with no namespace and no labels is external
+	return peer.namespace == "" && len(peer.labels) == 0
+}
+
+// entityMatches checks whether a peer belongs to a Cilium entity.
+func entityMatches(entity string, peer flowPeer) bool {
+	hasLabel := func(key string) bool {
+		_, ok := peer.labels[key]
+		return ok
+	}
+
+	switch entity {
+	case "all":
+		return true
+	case "world":
+		return isWorldPeer(peer)
+	case "world-ipv4":
+		return peer.identity == identityWorldIPv4 ||
+			(isWorldPeer(peer) && peer.identity != identityWorldIPv6 && !isIPv6(peer.ip))
+	case "world-ipv6":
+		return peer.identity == identityWorldIPv6 ||
+			(isWorldPeer(peer) && peer.identity != identityWorldIPv4 && isIPv6(peer.ip))
+	case "cluster":
+		return !isWorldPeer(peer)
+	case "host":
+		return peer.identity == identityHost || hasLabel("host")
+	case "remote-node":
+		return peer.identity == identityRemoteNode || hasLabel("remote-node")
+	case "kube-apiserver":
+		return peer.identity == identityKubeAPIServer || hasLabel("kube-apiserver")
+	case "health":
+		return peer.identity == identityHealth || hasLabel("health")
+	case "init":
+		return peer.identity == identityInit || hasLabel("init")
+	case "ingress":
+		return peer.identity == identityIngress || hasLabel("ingress")
+	case "unmanaged":
+		return peer.identity == identityUnmanaged || hasLabel("unmanaged")
+	default:
+		return false
+	}
+}
+
+// cidrMatches checks a peer against CIDR and CIDRSet selectors. Like Cilium,
+// CIDR selectors only select peers outside the cluster.
+func cidrMatches(peer flowPeer, cidrs []string, cidrSet []CIDRRule) bool {
+	if !isWorldPeer(peer) {
+		return false
+	}
+	ip := net.ParseIP(peer.ip)
+	if ip == nil {
+		return false
+	}
+
+	for _, cidr := range cidrs {
+		if ipInCIDR(ip, cidr) {
+			return true
+		}
+	}
+	for _, cr := range cidrSet {
+		if !ipInCIDR(ip, cr.CIDR) {
+			continue
+		}
+		excluded := false
+		for _, except := range cr.Except {
+			if ipInCIDR(ip, except) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			return true
+		}
+	}
+	return false
+}
+
+// serviceMatches checks whether a flow's destination is a toServices target.
+// Flows do not record the service they were addressed to, so name-based
+// rules match the requested DNS/HTTP host, and selector-based rules match
+// backend pods carrying the selected labels in the service namespace.
+func serviceMatches(event *models.TelemetryEvent, svc *ServiceRule) bool {
+	if svc.Name != "" {
+		for _, host := range []string{event.DstDNSName, event.HTTPHost} {
+			host = strings.TrimSuffix(strings.ToLower(host), ".")
+			if i := strings.LastIndex(host, ":"); i >= 0 {
+				host = host[:i]
+			}
+			if host == "" {
+				continue
+			}
+			if host == svc.Name && (svc.Namespace == "" || event.DstNamespace == svc.Namespace) {
+				return true
+			}
+			if svc.Namespace != "" && (host == svc.Name+"."+svc.Namespace ||
+				strings.HasPrefix(host, svc.Name+"."+svc.Namespace+".svc")) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if svc.Namespace != "" && event.DstNamespace != svc.Namespace {
+		return false
+	}
+	return labelsMatch(event.DstPodLabels, svc.Selector)
+}
+
+// icmpMatches checks a flow against icmps rules. Flows record only the ICMP
+// family, so the ICMP type cannot be checked.
+func icmpMatches(event *models.TelemetryEvent, rule *ICMPRule) bool {
+	switch strings.ToUpper(rule.Family) {
+	case "IPV6":
+		return strings.EqualFold(event.Protocol, "ICMPv6")
+	default:
+		return strings.EqualFold(event.Protocol, "ICMPv4")
+	}
+}
+
+// ipInCIDR reports whether ip lies within cidr. A bare IP is treated as a host prefix.
+func ipInCIDR(ip net.IP, cidr string) bool {
+	if !strings.Contains(cidr, "/") {
+		if other := net.ParseIP(cidr); other != nil {
+			return other.Equal(ip)
+		}
+		return false
+	}
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return false
+	}
+	return network.Contains(ip)
+}
+
+// isIPv6 reports whether s is an IPv6 address.
+func isIPv6(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && ip.To4() == nil
+}
+
+// containsString reports whether values contains s.
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package simulation
+
+import (
+	"testing"
+)
+
+func TestExpressionsMatch(t *testing.T) {
+	labels := map[string]string{"app": "web", "k8s:tier": "frontend"}
+
+	tests := []struct {
+		name  string
+		exprs []SelectorRequirement
+		want  bool
+	}{
+		{"In match", []SelectorRequirement{{Key: "app", Operator: "In", Values: []string{"web", "api"}}}, true},
+		{"In miss", []SelectorRequirement{{Key: "app", Operator: "In", Values: []string{"api"}}}, false},
+		{"In on missing key", []SelectorRequirement{{Key: "env", Operator: "In", Values: []string{"prod"}}}, false},
+		{"NotIn match", []SelectorRequirement{{Key: "app", Operator: "NotIn", Values: []string{"api"}}}, true},
+		{"NotIn on missing key", []SelectorRequirement{{Key: "env", Operator: "NotIn", Values: []string{"prod"}}}, true},
+		{"NotIn miss", []SelectorRequirement{{Key: "app", Operator: "NotIn", Values: []string{"web"}}}, false},
+		{"Exists with k8s prefix", []SelectorRequirement{{Key: "tier", Operator: "Exists"}}, true},
+		{"DoesNotExist", []SelectorRequirement{{Key: "env", Operator: "DoesNotExist"}}, true},
+		{"DoesNotExist miss", []SelectorRequirement{{Key: "app", Operator: "DoesNotExist"}}, false},
+		{"namespace key", []SelectorRequirement{{Key: namespaceLabel, Operator: "In", Values: []string{"shop"}}}, true},
+		{"unknown operator", []SelectorRequirement{{Key: "app", Operator: "Gt", Values: []string{"1"}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expressionsMatch(labels, "shop", tt.exprs); got != tt.want {
+				t.Errorf("expressionsMatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntityMatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		entity string
+		peer   flowPeer
+		want   bool
+	}{
+		{"all", "all", flowPeer{namespace: "default"}, true},
+		{"world by identity", "world", flowPeer{identity: identityWorld}, true},
+		{"world by CIDR identity", "world", flowPeer{identity: localIdentityFlag | 3}, true},
+		{"world by label", "world", flowPeer{labels: map[string]string{"world": ""}}, true},
+		{"world without identity", "world", flowPeer{ip: "8.8.8.8"}, true},
+		{"pod is not world", "world", flowPeer{namespace: "default", identity: 12345}, false},
+		{"pod is cluster", "cluster", flowPeer{namespace: "default", labels: map[string]string{"app": "x"}}, true},
+		{"world is not cluster", "cluster", flowPeer{identity: identityWorld}, false},
+		{"host by identity", "host", flowPeer{identity: identityHost}, true},
+		{"host by label", "host", flowPeer{labels: map[string]string{"host": ""}}, true},
+		{"remote-node", "remote-node", flowPeer{identity: identityRemoteNode}, true},
+		{"kube-apiserver", "kube-apiserver", flowPeer{identity: identityKubeAPIServer}, true},
+		{"world-ipv4", "world-ipv4", flowPeer{identity: identityWorld, ip: "1.1.1.1"}, true},
+		{"world-ipv6 rejects ipv4", "world-ipv6", flowPeer{identity: identityWorld, ip: "1.1.1.1"}, false},
+		{"world-ipv6", "world-ipv6", flowPeer{identity: identityWorldIPv6}, true},
+		{"unknown entity", "galaxy", flowPeer{identity: identityWorld}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := entityMatches(tt.entity, tt.peer); got != tt.want {
+				t.Errorf("entityMatches(%s) = %v, want %v", tt.entity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCIDRMatches(t *testing.T) {
+	set := []CIDRRule{{CIDR: "10.0.0.0/8", Except: []string{"10.1.0.0/16"}}}
+
+	tests := []struct {
+		name  string
+		peer  flowPeer
+		cidrs []string
+		want  bool
+	}{
+		{"plain CIDR", flowPeer{ip: "192.168.1.5", identity: identityWorld}, []string{"192.168.0.0/16"}, true},
+		{"bare IP", flowPeer{ip: "192.168.1.5", identity: identityWorld}, []string{"192.168.1.5"}, true},
+		{"CIDR set", flowPeer{ip: "10.2.3.4", identity: identityWorld}, nil, true},
+		{"CIDR set except", flowPeer{ip: "10.1.3.4", identity: identityWorld}, nil, false},
+		{"outside", flowPeer{ip: "172.16.0.1", identity: identityWorld}, nil, false},
+		{"cluster pod in range", flowPeer{ip: "10.2.3.4", namespace: "default", identity: 4242}, nil, false},
+		{"no IP", flowPeer{identity: identityWorld}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cidrMatches(tt.peer, tt.cidrs, set); got != tt.want {
+				t.Errorf("cidrMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package simulation
+
+import (
+	"context"
+	"time"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+	"github.com/policy-hub/operator/internal/telemetry/storage"
+)
+
+// eventEvaluator folds a single event into a partial simulation response.
+type eventEvaluator func(partial *SimulationResponse, event *models.TelemetryEvent)
+
+// streamEvaluate evaluates the events matching a query without loading them
+// into memory. Files are read concurrently, each into its own partial
+// response created by newPartial; partials are merged in file order as soon
+// as the files before them are done, so results do not depend on scheduling
+// and only the partials of files still being read are held in memory.
+//
+// Storage errors are reported in the response's Errors, like the other
+// simulation failures. Cancelling the context aborts the simulation with the
+// context's error.
+func (e *Engine) streamEvaluate(ctx context.Context, query models.QueryEventsRequest, progress func(SimulationProgress), maxDetails int, startTime time.Time, newPartial func() *SimulationResponse, evaluate eventEvaluator) (*SimulationResponse, error) {
+	files, err := e.storageMgr.ListFiles(query)
+	if err != nil {
+		e.log.Error(err, "Storage query failed")
+		return &SimulationResponse{
+			Errors:         []string{"Failed to query historical data: " + err.Error()},
+			SimulationTime: startTime,
+			Duration:       time.Since(startTime),
+		}, nil
+	}
+
+	e.log.Info("Streaming historical events", "files", len(files), "parallelism", e.parallelism)
+
+	response := newPartial()
+	response.SimulationTime = startTime
+
+	partials := make([]*SimulationResponse, len(files))
+	done := make([]bool, len(files))
+	next := 0
+
+	opts := storage.IterateOptions{
+		Parallelism: e.parallelism,
+		// Progress calls are serialized, so merging needs no locking
+		Progress: func(p storage.IterateProgress) {
+			done[p.File] = true
+			for next < len(files) && done[next] {
+				if partials[next] != nil {
+					mergeResponse(response, partials[next], maxDetails)
+					partials[next] = nil
+				}
+				next++
+			}
+			if progress != nil {
+				progress(SimulationProgress{
+					FilesTotal:    p.FilesTotal,
+					FilesDone:     p.FilesDone,
+					EventsScanned: p.EventsRead,
+				})
+			}
+		},
+	}
+
+	err = e.storageMgr.IterateFiles(ctx, files, query, opts, func(file int, event *models.TelemetryEvent) error {
+		partial := partials[file]
+		if partial == nil {
+			partial = newPartial()
+			partials[file] = partial
+		}
+		evaluate(partial, event)
+		return nil
+	})
+	if err != nil {
+		e.log.Info("Simulation cancelled", "filesDone", next, "files", len(files), "reason", err.Error())
+		return nil, err
+	}
+
+	finishConnections(response, maxDetails)
+	response.Duration = time.Since(startTime)
+	return response, nil
+}
+
+// mergeResponse adds the counts, breakdowns and details of src to dst,
+// keeping at most maxDetails details of each kind.
+func mergeResponse(dst, src *SimulationResponse, maxDetails int) {
+	dst.TotalFlowsAnalyzed += src.TotalFlowsAnalyzed
+	dst.AllowedCount += src.AllowedCount
+	dst.DeniedCount += src.DeniedCount
+	dst.NoChangeCount += src.NoChangeCount
+	dst.WouldChangeCount += src.WouldChangeCount
+	dst.KilledCount += src.KilledCount
+	dst.BlockedCount += src.BlockedCount
+	dst.RoutedCount += src.RoutedCount
+	dst.NotFoundCount += src.NotFoundCount
+
+	if src.BreakdownByVerdict != nil {
+		if dst.BreakdownByVerdict == nil {
+			dst.BreakdownByVerdict = &VerdictBreakdown{}
+		}
+		mergeVerdictBreakdown(dst.BreakdownByVerdict, src.BreakdownByVerdict)
+	}
+
+	for ns, impact := range src.BreakdownByNamespace {
+		if dst.BreakdownByNamespace == nil {
+			dst.BreakdownByNamespace = make(map[string]*NamespaceImpact)
+		}
+		existing, ok := dst.BreakdownByNamespace[ns]
+		if !ok {
+			dst.BreakdownByNamespace[ns] = impact
+			continue
+		}
+		existing.TotalFlows += impact.TotalFlows
+		existing.AllowedCount += impact.AllowedCount
+		existing.DeniedCount += impact.DeniedCount
+		existing.WouldDeny += impact.WouldDeny
+		existing.WouldAllow += impact.WouldAllow
+		existing.NoChange += impact.NoChange
+		existing.Killed += impact.Killed
+		existing.Blocked += impact.Blocked
+	}
+
+	for binary, impact := range src.BreakdownByBinary {
+		if dst.BreakdownByBinary == nil {
+			dst.BreakdownByBinary = make(map[string]*BinaryImpact)
+		}
+		existing, ok := dst.BreakdownByBinary[binary]
+		if !ok {
+			dst.BreakdownByBinary[binary] = impact
+			continue
+		}
+		existing.TotalEvents += impact.TotalEvents
+		existing.Killed += impact.Killed
+		existing.Blocked += impact.Blocked
+		existing.Audited += impact.Audited
+	}
+
+	for rule, impact := range src.BreakdownByRouteRule {
+		if dst.BreakdownByRouteRule == nil {
+			dst.BreakdownByRouteRule = make(map[string]*RouteRuleImpact)
+		}
+		existing, ok := dst.BreakdownByRouteRule[rule]
+		if !ok {
+			dst.BreakdownByRouteRule[rule] = impact
+			continue
+		}
+		existing.Requests += impact.Requests
+	}
+
+	for backend, impact := range src.BreakdownByBackend {
+		if dst.BreakdownByBackend == nil {
+			dst.BreakdownByBackend = make(map[string]*BackendImpact)
+		}
+		existing, ok := dst.BreakdownByBackend[backend]
+		if !ok {
+			dst.BreakdownByBackend[backend] = impact
+			continue
+		}
+		existing.Requests += impact.Requests
+		existing.ExpectedRequests += impact.ExpectedRequests
+	}
+
+	for key, impact := range src.BreakdownByRuleChange {
+		if dst.BreakdownByRuleChange == nil {
+			dst.BreakdownByRuleChange = make(map[string]*RuleChangeImpact)
+		}
+		existing, ok := dst.BreakdownByRuleChange[key]
+		if !ok {
+			dst.BreakdownByRuleChange[key] = impact
+			continue
+		}
+		existing.Flows += impact.Flows
+		existing.NewlyAllowed += impact.NewlyAllowed
+		existing.NewlyDenied += impact.NewlyDenied
+	}
+
+	mergeConnections(dst, src)
+
+	dst.Details = appendDetails(dst.Details, src.Details, maxDetails)
+	dst.ProcessDetails = appendDetails(dst.ProcessDetails, src.ProcessDetails, maxDetails)
+	dst.RouteDetails = appendDetails(dst.RouteDetails, src.RouteDetails, maxDetails)
+	dst.VerdictDiffs = appendDetails(dst.VerdictDiffs, src.VerdictDiffs, maxDetails)
+	dst.Errors = append(dst.Errors, src.Errors...)
+}
+
+// mergeVerdictBreakdown adds the counts of src to dst.
+func mergeVerdictBreakdown(dst, src *VerdictBreakdown) {
+	dst.AllowedToAllowed += src.AllowedToAllowed
+	dst.AllowedToDenied += src.AllowedToDenied
+	dst.DeniedToAllowed += src.DeniedToAllowed
+	dst.DeniedToDenied += src.DeniedToDenied
+	dst.DroppedToAllowed += src.DroppedToAllowed
+	dst.DroppedToDenied += src.DroppedToDenied
+}
+
+// appendDetails appends src to dst up to limit entries.
+func appendDetails[T any](dst, src []T, limit int) []T {
+	room := limit - len(dst)
+	if room <= 0 {
+		return dst
+	}
+	if room < len(src) {
+		src = src[:room]
+	}
+	return append(dst, src...)
+}
@@ -0,0 +1,205 @@
+package simulation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+	"github.com/policy-hub/operator/internal/telemetry/storage"
+)
+
+const backendIngressPolicy = `
+apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: backend-ingress
+  namespace: default
+spec:
+  endpointSelector:
+    matchLabels:
+      app: backend
+  ingress:
+    - fromEndpoints:
+        - matchLabels:
+            app: frontend
+`
+
+func TestEngine_Simulate_Streaming(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "engine-stream-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// One Parquet file per node, each with its own mix of flows
+	now := time.Now().UTC()
+	for node := 0; node < 3; node++ {
+		cfg := storage.ManagerConfig{
+			BasePath: tmpDir,
+			NodeName: fmt.Sprintf("node-%d", node),
+			Logger:   logr.Discard(),
+		}
+		writer, err := storage.NewManager(cfg)
+		if err != nil {
+			t.Fatalf("Failed to create storage manager: %v", err)
+		}
+
+		var events []*models.TelemetryEvent
+		for i := 0; i < 4+node; i++ {
+			app := "frontend"
+			if i%2 == 1 {
+				app = "other"
+			}
+			events = append(events, &models.TelemetryEvent{
+				ID:           fmt.Sprintf("flow-%d-%d", node, i),
+				Timestamp:    now.Add(-10 * time.Minute),
+				EventType:    models.EventTypeFlow,
+				NodeName:     cfg.NodeName,
+				SrcNamespace: "default",
+				SrcPodName:   fmt.Sprintf("%s-%d", cfg.NodeName, i),
+				SrcPodLabels: map[string]string{"app": app},
+				DstNamespace: "default",
+				DstPodLabels: map[string]string{"app": "backend"},
+				DstPort:      8080,
+				Protocol:     "TCP",
+				Direction:    "ingress",
+				Verdict:      models.VerdictAllowed,
+			})
+		}
+		if err := writer.Write(events); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		// Close the manager to ensure all data is flushed and indexed
+		if err := writer.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+
+	mgr, err := storage.NewManager(storage.ManagerConfig{
+		BasePath: tmpDir,
+		NodeName: "test-node",
+		Logger:   logr.Discard(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+	defer mgr.Close()
+
+	newRequest := func() *SimulationRequest {
+		return &SimulationRequest{
+			PolicyContent:  backendIngressPolicy,
+			PolicyType:     "CILIUM_NETWORK",
+			StartTime:      now.Add(-1 * time.Hour),
+			EndTime:        now,
+			IncludeDetails: true,
+			MaxDetails:     6,
+		}
+	}
+	simulate := func(parallelism int, req *SimulationRequest) *SimulationResponse {
+		t.Helper()
+		engine := NewEngine(EngineConfig{StorageManager: mgr, Parallelism: parallelism, Logger: logr.Discard()})
+		resp, err := engine.Simulate(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Simulate() error = %v", err)
+		}
+		if len(resp.Errors) > 0 {
+			t.Fatalf("Simulate() errors = %v", resp.Errors)
+		}
+		resp.SimulationTime = time.Time{}
+		resp.Duration = 0
+		return resp
+	}
+
+	var progress []SimulationProgress
+	req := newRequest()
+	req.Progress = func(p SimulationProgress) { progress = append(progress, p) }
+	sequential := simulate(1, req)
+
+	if sequential.TotalFlowsAnalyzed != 15 {
+		t.Errorf("TotalFlowsAnalyzed = %d, want 15", sequential.TotalFlowsAnalyzed)
+	}
+	if sequential.BreakdownByVerdict.AllowedToDenied != 7 || sequential.AllowedCount != 8 {
+		t.Errorf("AllowedToDenied = %d, AllowedCount = %d, want 7 and 8",
+			sequential.BreakdownByVerdict.AllowedToDenied, sequential.AllowedCount)
+	}
+	if got := sequential.BreakdownByNamespace["default"]; got == nil || got.TotalFlows != 15 || got.WouldDeny != 7 {
+		t.Errorf("BreakdownByNamespace[default] = %+v, want 15 flows, 7 would deny", got)
+	}
+	// Details are capped and taken in file order
+	if len(sequential.Details) != 6 || sequential.Details[4].SrcPodName != "node-1-0" {
+		t.Errorf("len(Details) = %d, want 6 with the first 4 from node-0", len(sequential.Details))
+	}
+
+	// The changed flows all belong to the same connection
+	if len(sequential.Connections) != 1 || sequential.Connections[0].Flows != 7 || sequential.Connections[0].SrcWorkload != "other" {
+		t.Errorf("Connections = %+v, want one connection from other with 7 flows", sequential.Connections)
+	}
+
+	if len(progress) != 3 {
+		t.Fatalf("Progress called %d times, want once per file", len(progress))
+	}
+	if last := progress[2]; last.FilesDone != 3 || last.FilesTotal != 3 || last.EventsScanned != 15 {
+		t.Errorf("last progress = %+v, want 3/3 files and 15 events", last)
+	}
+
+	// Parallel evaluation merges partials in file order: same result
+	if parallel := simulate(3, newRequest()); !reflect.DeepEqual(parallel, sequential) {
+		t.Errorf("parallel response differs from sequential:\n%+v\n%+v", parallel, sequential)
+	}
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		engine := NewEngine(EngineConfig{StorageManager: mgr, Logger: logr.Discard()})
+		if _, err := engine.Simulate(ctx, newRequest()); !errors.Is(err, context.Canceled) {
+			t.Errorf("Simulate() error = %v, want context.Canceled", err)
+		}
+	})
+}
+
+func TestMergeResponse(t *testing.T) {
+	dst := &SimulationResponse{
+		TotalFlowsAnalyzed:   2,
+		BreakdownByNamespace: map[string]*NamespaceImpact{"a": {Namespace: "a", TotalFlows: 2, WouldDeny: 1}},
+		BreakdownByVerdict:   &VerdictBreakdown{AllowedToDenied: 1},
+		Details:              []*FlowSimulationResult{{SrcPodName: "1"}, {SrcPodName: "2"}},
+	}
+	src := &SimulationResponse{
+		TotalFlowsAnalyzed: 3,
+		BreakdownByNamespace: map[string]*NamespaceImpact{
+			"a": {Namespace: "a", TotalFlows: 1, WouldDeny: 1},
+			"b": {Namespace: "b", TotalFlows: 2},
+		},
+		BreakdownByVerdict: &VerdictBreakdown{AllowedToDenied: 2},
+		BreakdownByBackend: map[string]*BackendImpact{"svc": {Backend: "svc", Requests: 1, ExpectedRequests: 0.5}},
+		Details:            []*FlowSimulationResult{{SrcPodName: "3"}, {SrcPodName: "4"}},
+	}
+
+	mergeResponse(dst, src, 3)
+
+	if dst.TotalFlowsAnalyzed != 5 {
+		t.Errorf("TotalFlowsAnalyzed = %d, want 5", dst.TotalFlowsAnalyzed)
+	}
+	if got := dst.BreakdownByNamespace["a"]; got.TotalFlows != 3 || got.WouldDeny != 2 {
+		t.Errorf("BreakdownByNamespace[a] = %+v, want 3 flows, 2 would deny", got)
+	}
+	if got := dst.BreakdownByNamespace["b"]; got == nil || got.TotalFlows != 2 {
+		t.Errorf("BreakdownByNamespace[b] = %+v, want 2 flows", got)
+	}
+	if dst.BreakdownByVerdict.AllowedToDenied != 3 {
+		t.Errorf("AllowedToDenied = %d, want 3", dst.BreakdownByVerdict.AllowedToDenied)
+	}
+	if got := dst.BreakdownByBackend["svc"]; got == nil || got.ExpectedRequests != 0.5 {
+		t.Errorf("BreakdownByBackend[svc] = %+v", got)
+	}
+	if len(dst.Details) != 3 || dst.Details[2].SrcPodName != "3" {
+		t.Errorf("Details = %d entries, want the first 3", len(dst.Details))
+	}
+}
@@ -0,0 +1,511 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+)
+
+// Enforcement outcomes of a TracingPolicy on a process event.
+const (
+	tracingActionKill  = "SIGKILL"
+	tracingActionBlock = "OVERRIDE"
+	tracingActionPost  = "POST"
+)
+
+// execHooks are the hooks that fire on process execution, which the collector
+// records as PROCESS_EXEC events rather than as SYSCALL events.
+var execHooks = map[string]bool{
+	"sys_execve":                     true,
+	"sys_execveat":                   true,
+	"bprm_execve":                    true,
+	"security_bprm_check":            true,
+	"security_bprm_creds_for_exec":   true,
+	"security_bprm_committing_creds": true,
+	"security_bprm_committed_creds":  true,
+	"sched/sched_process_exec":       true,
+}
+
+// pathArgTypes are the Tetragon argument types whose recorded value is a path.
+var pathArgTypes = map[string]bool{
+	"string":       true,
+	"char_buf":     true,
+	"fd":           true,
+	"file":         true,
+	"filename":     true,
+	"path":         true,
+	"dentry":       true,
+	"linux_binprm": true,
+}
+
+// tracingMatch is the outcome of a TracingPolicy on a single event.
+type tracingMatch struct {
+	action string
+	hook   string
+	reason string
+}
+
+// simulateTracingPolicy evaluates a Tetragon TracingPolicy against recorded
+// process, syscall and file access events.
+func (e *Engine) simulateTracingPolicy(ctx context.Context, req *SimulationRequest, policy *ParsedPolicy, startTime time.Time) (*SimulationResponse, error) {
+	queryReq := models.QueryEventsRequest{
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		EventTypes: []string{
+			string(models.EventTypeProcessExec),
+			string(models.EventTypeSyscall),
+			string(models.EventTypeFileAccess),
+		},
+		Limit: 0, // Get all matching events
+	}
+
+	maxDetails := int(req.MaxDetails)
+	if maxDetails == 0 {
+		maxDetails = 100 // Default limit
+	}
+
+	newPartial := func() *SimulationResponse {
+		return &SimulationResponse{
+			BreakdownByNamespace: make(map[string]*NamespaceImpact),
+			BreakdownByVerdict:   &VerdictBreakdown{},
+			BreakdownByBinary:    make(map[string]*BinaryImpact),
+			ProcessDetails:       []*ProcessSimulationResult{},
+		}
+	}
+
+	response, err := e.streamEvaluate(ctx, queryReq, req.Progress, maxDetails, startTime, newPartial, func(partial *SimulationResponse, event *models.TelemetryEvent) {
+		processResult := e.evaluateProcessEvent(event, policy)
+		partial.TotalFlowsAnalyzed++
+
+		switch processResult.SimulatedVerdict {
+		case "ALLOWED":
+			partial.AllowedCount++
+		case "DENIED":
+			partial.DeniedCount++
+		}
+		if processResult.VerdictChanged {
+			partial.WouldChangeCount++
+		} else {
+			partial.NoChangeCount++
+		}
+
+		// The verdict and namespace breakdowns only look at verdicts
+		verdicts := &FlowSimulationResult{
+			OriginalVerdict:  processResult.OriginalVerdict,
+			SimulatedVerdict: processResult.SimulatedVerdict,
+			VerdictChanged:   processResult.VerdictChanged,
+		}
+		e.updateVerdictBreakdown(partial.BreakdownByVerdict, verdicts)
+		e.updateNamespaceBreakdown(partial.BreakdownByNamespace, event, verdicts)
+
+		nsImpact := partial.BreakdownByNamespace[processResult.Namespace]
+		if nsImpact == nil {
+			nsImpact = partial.BreakdownByNamespace["unknown"]
+		}
+
+		binary := processResult.Binary
+		if binary == "" {
+			binary = "unknown"
+		}
+		binImpact, ok := partial.BreakdownByBinary[binary]
+		if !ok {
+			binImpact = &BinaryImpact{Binary: binary}
+			partial.BreakdownByBinary[binary] = binImpact
+		}
+		binImpact.TotalEvents++
+
+		switch processResult.Action {
+		case tracingActionKill:
+			partial.KilledCount++
+			nsImpact.Killed++
+			binImpact.Killed++
+		case tracingActionBlock:
+			partial.BlockedCount++
+			nsImpact.Blocked++
+			binImpact.Blocked++
+		case tracingActionPost:
+			binImpact.Audited++
+		}
+
+		// Only events the policy acts on are interesting as details
+		if req.IncludeDetails && processResult.Action != "" && len(partial.ProcessDetails) < maxDetails {
+			partial.ProcessDetails = append(partial.ProcessDetails, processResult)
+		}
+	})
+	if err != nil || len(response.Errors) > 0 {
+		return response, err
+	}
+
+	e.log.Info("Tracing policy simulation complete",
+		"totalEvents", response.TotalFlowsAnalyzed,
+		"killed", response.KilledCount,
+		"blocked", response.BlockedCount,
+		"wouldChange", response.WouldChangeCount,
+		"duration", response.Duration,
+	)
+
+	return response, nil
+}
+
+// evaluateProcessEvent evaluates a single process event against a TracingPolicy.
+func (e *Engine) evaluateProcessEvent(event *models.TelemetryEvent, policy *ParsedPolicy) *ProcessSimulationResult {
+	result := &ProcessSimulationResult{
+		Timestamp:       event.Timestamp,
+		EventType:       string(event.EventType),
+		Namespace:       event.SrcNamespace,
+		PodName:         event.SrcPodName,
+		Binary:          event.SrcBinary,
+		Arguments:       event.SrcArguments,
+		Syscall:         event.Syscall,
+		FilePath:        event.FilePath,
+		OriginalVerdict: string(event.Verdict),
+	}
+
+	// The policy applies to the process's pod (egress = source side)
+	var match *tracingMatch
+	if MatchesPodSelectorForDirection(event, policy, "egress") {
+		match = tracingPolicyMatch(event, policy)
+	}
+	if match == nil {
+		result.SimulatedVerdict = result.OriginalVerdict
+		result.MatchReason = "Policy does not apply to this event"
+		return result
+	}
+
+	result.Action = match.action
+	result.MatchedHook = match.hook
+	result.MatchReason = match.reason
+	if match.action == tracingActionPost {
+		result.SimulatedVerdict = "ALLOWED"
+	} else {
+		result.SimulatedVerdict = "DENIED"
+	}
+	result.VerdictChanged = result.SimulatedVerdict != result.OriginalVerdict
+	return result
+}
+
+// tracingPolicyMatch returns the strongest outcome of the policy's hooks on
+// an event, or nil if no hook selects it.
+func tracingPolicyMatch(event *models.TelemetryEvent, policy *ParsedPolicy) *tracingMatch {
+	var best *tracingMatch
+	for i := range policy.Hooks {
+		hook := &policy.Hooks[i]
+		if !hookMatchesEvent(hook, event) {
+			continue
+		}
+		match := hookSelectorMatch(hook, event)
+		if match == nil {
+			continue
+		}
+		if best == nil || tracingActionRank(match.action) > tracingActionRank(best.action) {
+			best = match
+		}
+	}
+	return best
+}
+
+// hookMatchesEvent reports whether a hook fires for the recorded event.
+func hookMatchesEvent(hook *TracingHook, event *models.TelemetryEvent) bool {
+	call := normalizeHookCall(hook)
+	switch event.EventType {
+	case models.EventTypeProcessExec:
+		return execHooks[call]
+	case models.EventTypeSyscall, models.EventTypeFileAccess:
+		return event.Syscall != "" && normalizeCallName(event.Syscall) == call
+	default:
+		return false
+	}
+}
+
+// hookSelectorMatch applies a hook's selectors to an event. Like Tetragon,
+// selectors are evaluated in order and the first matching one decides the
+// actions. A hook without selectors matches every event it fires for.
+func hookSelectorMatch(hook *TracingHook, event *models.TelemetryEvent) *tracingMatch {
+	name := fmt.Sprintf("%s:%s", hook.Type, hook.Call)
+	if len(hook.Selectors) == 0 {
+		return &tracingMatch{action: tracingActionPost, hook: name, reason: "Matched hook without selectors"}
+	}
+
+	for i := range hook.Selectors {
+		sel := &hook.Selectors[i]
+		if !selectorMatchesEvent(hook, sel, event) {
+			continue
+		}
+		action := selectorAction(sel)
+		return &tracingMatch{
+			action: action,
+			hook:   fmt.Sprintf("%s selector[%d]", name, i),
+			reason: fmt.Sprintf("Matched %s selector, action %s", hook.Type, action),
+		}
+	}
+	return nil
+}
+
+// selectorMatchesEvent checks all filters of a selector.
+func selectorMatchesEvent(hook *TracingHook, sel *TracingSelector, event *models.TelemetryEvent) bool {
+	for _, m := range sel.MatchBinaries {
+		if !binaryMatches(event.SrcBinary, m) {
+			return false
+		}
+	}
+	for _, m := range sel.MatchArgs {
+		value, ok := hookArgValue(hook, event, m.Index)
+		if !ok || !argMatches(value, m) {
+			return false
+		}
+	}
+	for _, m := range sel.MatchNamespaces {
+		if !linuxNamespaceMatches(event, m) {
+			return false
+		}
+	}
+	return true
+}
+
+// selectorAction returns the enforcement outcome of a selector's actions.
+func selectorAction(sel *TracingSelector) string {
+	action := tracingActionPost
+	for _, a := range sel.MatchActions {
+		var outcome string
+		switch strings.ToLower(a.Action) {
+		case "sigkill":
+			outcome = tracingActionKill
+		case "signal", "notifyenforcer":
+			// Signal 9 kills; the enforcer otherwise fails the call
+			if a.ArgSig == 9 {
+				outcome = tracingActionKill
+			} else if strings.EqualFold(a.Action, "notifyenforcer") {
+				outcome = tracingActionBlock
+			}
+		case "override":
+			outcome = tracingActionBlock
+		}
+		if tracingActionRank(outcome) > tracingActionRank(action) {
+			action = outcome
+		}
+	}
+	return action
+}
+
+// tracingActionRank orders outcomes by severity.
+func tracingActionRank(action string) int {
+	switch action {
+	case tracingActionKill:
+		return 3
+	case tracingActionBlock:
+		return 2
+	case tracingActionPost:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// normalizeHookCall returns the call name a hook is compared on. Syscall
+// tracepoints map onto the syscall they trace.
+func normalizeHookCall(hook *TracingHook) string {
+	if hook.Type == "tracepoint" {
+		if event, ok := strings.CutPrefix(hook.Call, "syscalls/"); ok {
+			for _, prefix := range []string{"sys_enter_", "sys_exit_"} {
+				if name, ok := strings.CutPrefix(event, prefix); ok {
+					return "sys_" + name
+				}
+			}
+		}
+		return hook.Call
+	}
+
+	call := normalizeCallName(hook.Call)
+	if hook.Syscall && !strings.HasPrefix(call, "sys_") {
+		call = "sys_" + call
+	}
+	return call
+}
+
+// normalizeCallName strips architecture-specific syscall prefixes.
+func normalizeCallName(call string) string {
+	for _, prefix := range []string{"__x64_", "__ia32_", "__arm64_"} {
+		call = strings.TrimPrefix(call, prefix)
+	}
+	return call
+}
+
+// hookArgValue returns the recorded value of the hook argument with the given
+// index. The collector records arguments in the order the policy declares
+// them. Exec events carry no arguments, so path arguments resolve to the
+// executed binary.
+func hookArgValue(hook *TracingHook, event *models.TelemetryEvent, index int) (string, bool) {
+	for pos, arg := range hook.Args {
+		if arg.Index != index {
+			continue
+		}
+		if event.EventType == models.EventTypeProcessExec {
+			if pathArgTypes[arg.Type] && event.SrcBinary != "" {
+				return event.SrcBinary, true
+			}
+			return "", false
+		}
+		if pos < len(event.SyscallArgs) {
+			return stripArgType(event.SyscallArgs[pos]), true
+		}
+		if pathArgTypes[arg.Type] && event.FilePath != "" {
+			return event.FilePath, true
+		}
+		return "", false
+	}
+	return "", false
+}
+
+// stripArgType removes the type prefix the collector adds to recorded arguments.
+func stripArgType(value string) string {
+	for _, prefix := range []string{"str:", "file:", "int:", "uint:", "bytes:", "sock:", "skb:"} {
+		if v, ok := strings.CutPrefix(value, prefix); ok {
+			return v
+		}
+	}
+	return value
+}
+
+// binaryMatches evaluates a matchBinaries entry.
+func binaryMatches(binary string, m TracingValueMatch) bool {
+	switch m.Operator {
+	case "In":
+		return containsString(m.Values, binary)
+	case "NotIn":
+		return !containsString(m.Values, binary)
+	case "Prefix":
+		return anyValue(m.Values, func(v string) bool { return strings.HasPrefix(binary, v) })
+	case "NotPrefix":
+		return !anyValue(m.Values, func(v string) bool { return strings.HasPrefix(binary, v) })
+	case "Postfix":
+		return anyValue(m.Values, func(v string) bool { return strings.HasSuffix(binary, v) })
+	case "NotPostfix":
+		return !anyValue(m.Values, func(v string) bool { return strings.HasSuffix(binary, v) })
+	default:
+		return false
+	}
+}
+
+// argMatches evaluates a matchArgs entry against a recorded argument value.
+// Unsupported operators never match.
+func argMatches(value string, m TracingArgMatch) bool {
+	switch m.Operator {
+	case "Equal":
+		return anyValue(m.Values, func(v string) bool { return argEqual(value, v) })
+	case "NotEqual":
+		return !anyValue(m.Values, func(v string) bool { return argEqual(value, v) })
+	case "Prefix":
+		return anyValue(m.Values, func(v string) bool { return strings.HasPrefix(value, v) })
+	case "NotPrefix":
+		return !anyValue(m.Values, func(v string) bool { return strings.HasPrefix(value, v) })
+	case "Postfix":
+		return anyValue(m.Values, func(v string) bool { return strings.HasSuffix(value, v) })
+	case "NotPostfix":
+		return !anyValue(m.Values, func(v string) bool { return strings.HasSuffix(value, v) })
+	case "GT", "LT", "Mask":
+		n, err := strconv.ParseInt(value, 0, 64)
+		if err != nil {
+			return false
+		}
+		return anyValue(m.Values, func(v string) bool {
+			want, err := strconv.ParseInt(v, 0, 64)
+			if err != nil {
+				return false
+			}
+			switch m.Operator {
+			case "GT":
+				return n > want
+			case "LT":
+				return n < want
+			default:
+				return n&want != 0
+			}
+		})
+	case "SPort", "DPort", "NotSPort", "NotDPort":
+		port, ok := sockField(value, strings.TrimPrefix(m.Operator, "Not"))
+		if !ok {
+			return false
+		}
+		matched := containsString(m.Values, port)
+		if strings.HasPrefix(m.Operator, "Not") {
+			return !matched
+		}
+		return matched
+	case "SAddr", "DAddr", "NotSAddr", "NotDAddr":
+		addr, ok := sockField(value, strings.TrimPrefix(m.Operator, "Not"))
+		if !ok {
+			return false
+		}
+		ip := net.ParseIP(addr)
+		matched := ip != nil && anyValue(m.Values, func(v string) bool { return ipInCIDR(ip, v) })
+		if strings.HasPrefix(m.Operator, "Not") {
+			return !matched
+		}
+		return matched
+	default:
+		return false
+	}
+}
+
+// argEqual compares argument values, numerically when both are numbers.
+func argEqual(value, want string) bool {
+	if value == want {
+		return true
+	}
+	a, errA := strconv.ParseInt(value, 0, 64)
+	b, errB := strconv.ParseInt(want, 0, 64)
+	return errA == nil && errB == nil && a == b
+}
+
+// sockField extracts a field from a recorded socket argument of the form
+// saddr:sport->daddr:dport.
+func sockField(value, field string) (string, bool) {
+	src, dst, ok := strings.Cut(value, "->")
+	if !ok {
+		return "", false
+	}
+	side := src
+	if field == "DAddr" || field == "DPort" {
+		side = dst
+	}
+	i := strings.LastIndex(side, ":")
+	if i < 0 {
+		return "", false
+	}
+	if strings.HasSuffix(field, "Port") {
+		return side[i+1:], true
+	}
+	return side[:i], true
+}
+
+// linuxNamespaceMatches evaluates a matchNamespaces entry. Events do not
+// record Linux namespace inodes, so only the host_ns value can be checked:
+// processes outside any pod are taken to run in the host namespaces. Inode
+// values never match.
+func linuxNamespaceMatches(event *models.TelemetryEvent, m TracingNamespaceMatch) bool {
+	isHost := event.SrcNamespace == "" && event.SrcPodName == ""
+	matched := isHost && containsString(m.Values, "host_ns")
+	switch m.Operator {
+	case "In":
+		return matched
+	case "NotIn":
+		return !matched
+	default:
+		return false
+	}
+}
+
+// anyValue reports whether pred holds for any value.
+func anyValue(values []string, pred func(string) bool) bool {
+	for _, v := range values {
+		if pred(v) {
+			return true
+		}
+	}
+	return false
+}
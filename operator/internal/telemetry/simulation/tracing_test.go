@@ -0,0 +1,335 @@
+package simulation
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+	"github.com/policy-hub/operator/internal/telemetry/storage"
+)
+
+const blockSensitiveFiles = `
+apiVersion: cilium.io/v1alpha1
+kind: TracingPolicy
+metadata:
+  name: block-sensitive-files
+spec:
+  podSelector:
+    matchLabels:
+      app: web
+  kprobes:
+    - call: security_file_open
+      syscall: false
+      args:
+        - index: 0
+          type: file
+      selectors:
+        - matchArgs:
+            - index: 0
+              operator: Prefix
+              values:
+                - /etc/shadow
+          matchActions:
+            - action: Sigkill
+        - matchBinaries:
+            - operator: NotIn
+              values:
+                - /usr/sbin/nginx
+          matchArgs:
+            - index: 0
+              operator: Prefix
+              values:
+                - /etc/
+          matchActions:
+            - action: Override
+              argError: -1
+        - matchArgs:
+            - index: 0
+              operator: Prefix
+              values:
+                - /var/log/
+  tracepoints:
+    - subsystem: syscalls
+      event: sys_enter_connect
+      selectors:
+        - matchNamespaces:
+            - namespace: Net
+              operator: In
+              values:
+                - host_ns
+          matchActions:
+            - action: Sigkill
+`
+
+const blockShellExec = `
+apiVersion: cilium.io/v1alpha1
+kind: TracingPolicyNamespaced
+metadata:
+  name: block-shells
+  namespace: prod
+spec:
+  kprobes:
+    - call: security_bprm_check
+      args:
+        - index: 0
+          type: linux_binprm
+      selectors:
+        - matchArgs:
+            - index: 0
+              operator: Postfix
+              values:
+                - /sh
+                - /bash
+          matchActions:
+            - action: Sigkill
+`
+
+func TestEngine_EvaluateProcessEvent(t *testing.T) {
+	engine := &Engine{parser: NewPolicyParser(), log: logr.Discard()}
+
+	fileEvent := func(binary, path string) *models.TelemetryEvent {
+		return &models.TelemetryEvent{
+			EventType:     models.EventTypeFileAccess,
+			SrcNamespace:  "default",
+			SrcPodName:    "web-1",
+			SrcPodLabels:  map[string]string{"app": "web"},
+			SrcBinary:     binary,
+			Syscall:       "security_file_open",
+			SyscallArgs:   []string{"file:" + path},
+			FilePath:      path,
+			FileOperation: "security_file_open",
+			Verdict:       models.VerdictAllowed,
+		}
+	}
+
+	tests := []struct {
+		name        string
+		policy      string
+		event       *models.TelemetryEvent
+		wantAction  string
+		wantVerdict string
+	}{
+		{
+			name:        "first matching selector kills",
+			policy:      blockSensitiveFiles,
+			event:       fileEvent("/usr/sbin/nginx", "/etc/shadow"),
+			wantAction:  tracingActionKill,
+			wantVerdict: "DENIED",
+		},
+		{
+			name:        "override blocks other binaries",
+			policy:      blockSensitiveFiles,
+			event:       fileEvent("/bin/cat", "/etc/passwd"),
+			wantAction:  tracingActionBlock,
+			wantVerdict: "DENIED",
+		},
+		{
+			name:        "excluded binary is not blocked",
+			policy:      blockSensitiveFiles,
+			event:       fileEvent("/usr/sbin/nginx", "/etc/nginx/nginx.conf"),
+			wantVerdict: "ALLOWED",
+		},
+		{
+			name:        "selector without actions only audits",
+			policy:      blockSensitiveFiles,
+			event:       fileEvent("/bin/cat", "/var/log/app.log"),
+			wantAction:  tracingActionPost,
+			wantVerdict: "ALLOWED",
+		},
+		{
+			name:   "pod selector does not match",
+			policy: blockSensitiveFiles,
+			event: func() *models.TelemetryEvent {
+				e := fileEvent("/bin/cat", "/etc/shadow")
+				e.SrcPodLabels = map[string]string{"app": "db"}
+				return e
+			}(),
+			wantVerdict: "ALLOWED",
+		},
+		{
+			name:   "architecture prefixed syscall matches tracepoint",
+			policy: blockSensitiveFiles,
+			event: &models.TelemetryEvent{
+				EventType:    models.EventTypeSyscall,
+				SrcPodLabels: map[string]string{"app": "web"},
+				SrcBinary:    "/usr/bin/curl",
+				Syscall:      "__x64_sys_connect",
+				Verdict:      models.VerdictAllowed,
+			},
+			wantAction:  tracingActionKill,
+			wantVerdict: "DENIED",
+		},
+		{
+			name:   "host namespace selector skips pod processes",
+			policy: blockSensitiveFiles,
+			event: &models.TelemetryEvent{
+				EventType:    models.EventTypeSyscall,
+				SrcNamespace: "default",
+				SrcPodName:   "web-1",
+				SrcPodLabels: map[string]string{"app": "web"},
+				SrcBinary:    "/usr/bin/curl",
+				Syscall:      "sys_connect",
+				Verdict:      models.VerdictAllowed,
+			},
+			wantVerdict: "ALLOWED",
+		},
+		{
+			name:   "exec hook kills shell in namespace",
+			policy: blockShellExec,
+			event: &models.TelemetryEvent{
+				EventType:    models.EventTypeProcessExec,
+				SrcNamespace: "prod",
+				SrcPodName:   "api-1",
+				SrcBinary:    "/bin/bash",
+				Verdict:      models.VerdictAllowed,
+			},
+			wantAction:  tracingActionKill,
+			wantVerdict: "DENIED",
+		},
+		{
+			name:   "namespaced policy ignores other namespaces",
+			policy: blockShellExec,
+			event: &models.TelemetryEvent{
+				EventType:    models.EventTypeProcessExec,
+				SrcNamespace: "dev",
+				SrcPodName:   "api-1",
+				SrcBinary:    "/bin/bash",
+				Verdict:      models.VerdictAllowed,
+			},
+			wantVerdict: "ALLOWED",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := engine.parser.Parse(tt.policy, "TETRAGON")
+			if err != nil {
+				t.Fatalf("Parse() error: %v", err)
+			}
+
+			result := engine.evaluateProcessEvent(tt.event, policy)
+			if result.Action != tt.wantAction {
+				t.Errorf("Action = %q, want %q (reason: %s)", result.Action, tt.wantAction, result.MatchReason)
+			}
+			if result.SimulatedVerdict != tt.wantVerdict {
+				t.Errorf("SimulatedVerdict = %q, want %q", result.SimulatedVerdict, tt.wantVerdict)
+			}
+		})
+	}
+}
+
+func TestArgMatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		match TracingArgMatch
+		want  bool
+	}{
+		{"equal string", "/etc/passwd", TracingArgMatch{Operator: "Equal", Values: []string{"/etc/passwd"}}, true},
+		{"equal numeric", "0x10", TracingArgMatch{Operator: "Equal", Values: []string{"16"}}, true},
+		{"not equal", "/tmp/x", TracingArgMatch{Operator: "NotEqual", Values: []string{"/etc/passwd"}}, true},
+		{"postfix", "/usr/bin/bash", TracingArgMatch{Operator: "Postfix", Values: []string{"/bash"}}, true},
+		{"greater than", "100", TracingArgMatch{Operator: "GT", Values: []string{"50"}}, true},
+		{"mask", "6", TracingArgMatch{Operator: "Mask", Values: []string{"2"}}, true},
+		{"mask no bits", "4", TracingArgMatch{Operator: "Mask", Values: []string{"2"}}, false},
+		{"dport", "10.0.0.1:40000->10.0.0.2:443", TracingArgMatch{Operator: "DPort", Values: []string{"443"}}, true},
+		{"not dport", "10.0.0.1:40000->10.0.0.2:443", TracingArgMatch{Operator: "NotDPort", Values: []string{"443"}}, false},
+		{"daddr cidr", "10.0.0.1:40000->192.168.1.5:443", TracingArgMatch{Operator: "DAddr", Values: []string{"192.168.0.0/16"}}, true},
+		{"saddr cidr", "10.0.0.1:40000->192.168.1.5:443", TracingArgMatch{Operator: "SAddr", Values: []string{"192.168.0.0/16"}}, false},
+		{"unsupported operator", "x", TracingArgMatch{Operator: "InMap", Values: []string{"x"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := argMatches(tt.value, tt.match); got != tt.want {
+				t.Errorf("argMatches(%q, %+v) = %v, want %v", tt.value, tt.match, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngine_Simulate_TracingPolicy(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "engine-tracing-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cfg := storage.ManagerConfig{
+		BasePath: tmpDir,
+		NodeName: "test-node",
+		Logger:   logr.Discard(),
+	}
+	writer, err := storage.NewManager(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+
+	now := time.Now().UTC()
+	events := []*models.TelemetryEvent{
+		{ID: "1", EventType: models.EventTypeProcessExec, SrcNamespace: "prod", SrcPodName: "api-1", SrcBinary: "/bin/bash"},
+		{ID: "2", EventType: models.EventTypeProcessExec, SrcNamespace: "prod", SrcPodName: "api-1", SrcBinary: "/bin/bash"},
+		{ID: "3", EventType: models.EventTypeProcessExec, SrcNamespace: "prod", SrcPodName: "api-1", SrcBinary: "/usr/bin/python3"},
+		{ID: "4", EventType: models.EventTypeProcessExec, SrcNamespace: "dev", SrcPodName: "api-1", SrcBinary: "/bin/sh"},
+		{ID: "5", EventType: models.EventTypeFlow, SrcNamespace: "prod", SrcPodName: "api-1"},
+	}
+	for _, event := range events {
+		event.Timestamp = now.Add(-10 * time.Minute)
+		event.NodeName = "test-node"
+		event.Verdict = models.VerdictAllowed
+	}
+	if err := writer.Write(events); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	// Close the manager to ensure all data is flushed and indexed
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	mgr, err := storage.NewManager(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+	defer mgr.Close()
+
+	engine := NewEngine(EngineConfig{StorageManager: mgr, Logger: logr.Discard()})
+	resp, err := engine.Simulate(context.Background(), &SimulationRequest{
+		PolicyContent:  blockShellExec,
+		PolicyType:     "TETRAGON",
+		StartTime:      now.Add(-1 * time.Hour),
+		EndTime:        now,
+		IncludeDetails: true,
+	})
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(resp.Errors) > 0 {
+		t.Fatalf("Simulate() errors = %v", resp.Errors)
+	}
+
+	if resp.TotalFlowsAnalyzed != 4 {
+		t.Errorf("TotalFlowsAnalyzed = %d, want 4 process events", resp.TotalFlowsAnalyzed)
+	}
+	if resp.KilledCount != 2 || resp.BlockedCount != 0 {
+		t.Errorf("Killed/Blocked = %d/%d, want 2/0", resp.KilledCount, resp.BlockedCount)
+	}
+	if got := resp.BreakdownByBinary["/bin/bash"]; got == nil || got.Killed != 2 || got.TotalEvents != 2 {
+		t.Errorf("BreakdownByBinary[/bin/bash] = %+v", got)
+	}
+	if got := resp.BreakdownByBinary["/usr/bin/python3"]; got == nil || got.Killed != 0 {
+		t.Errorf("BreakdownByBinary[/usr/bin/python3] = %+v", got)
+	}
+	if got := resp.BreakdownByNamespace["prod"]; got == nil || got.Killed != 2 || got.WouldDeny != 2 {
+		t.Errorf("BreakdownByNamespace[prod] = %+v", got)
+	}
+	if got := resp.BreakdownByNamespace["dev"]; got == nil || got.Killed != 0 {
+		t.Errorf("BreakdownByNamespace[dev] = %+v", got)
+	}
+	if len(resp.ProcessDetails) != 2 {
+		t.Errorf("len(ProcessDetails) = %d, want 2", len(resp.ProcessDetails))
+	}
+}
@@ -27,6 +27,17 @@ type SimulationRequest struct {
 
 	// MaxDetails limits the number of detailed results returned
 	MaxDetails int32 `json:"maxDetails,omitempty"`
+
+	// Progress is called as the stored events are evaluated (optional)
+	Progress func(SimulationProgress) `json:"-"`
+}
+
+// SimulationProgress reports how far a simulation has got through the
+// stored events of its time window.
+type SimulationProgress struct {
+	FilesTotal    int   `json:"filesTotal"`
+	FilesDone     int   `json:"filesDone"`
+	EventsScanned int64 `json:"eventsScanned"`
 }
 
 // SimulationResponse contains the results of a policy simulation.
@@ -49,6 +60,37 @@ type SimulationResponse struct {
 	// Details contains sample flows with their simulation results
 	Details []*FlowSimulationResult `json:"details,omitempty"`
 
+	// Connections aggregates the flows whose verdict would change by
+	// connection, most impacted first. TotalConnections is the number of
+	// distinct connections before the MaxDetails limit; UntrackedFlows counts
+	// changed flows of connections beyond the aggregation limit.
+	Connections      []*ConnectionImpact `json:"connections,omitempty"`
+	TotalConnections int64               `json:"totalConnections,omitempty"`
+	UntrackedFlows   int64               `json:"untrackedFlows,omitempty"`
+
+	// connections accumulates Connections while flows are evaluated
+	connections map[string]*ConnectionImpact
+
+	// Tetragon simulations: enforcement counts, impact per binary and
+	// sample process events
+	KilledCount       int64                      `json:"killedCount,omitempty"`
+	BlockedCount      int64                      `json:"blockedCount,omitempty"`
+	BreakdownByBinary map[string]*BinaryImpact   `json:"breakdownByBinary,omitempty"`
+	ProcessDetails    []*ProcessSimulationResult `json:"processDetails,omitempty"`
+
+	// Gateway route simulations: requests handled by a rule or falling
+	// through to 404, impact per rule and backend, and sample requests
+	RoutedCount          int64                       `json:"routedCount,omitempty"`
+	NotFoundCount        int64                       `json:"notFoundCount,omitempty"`
+	BreakdownByRouteRule map[string]*RouteRuleImpact `json:"breakdownByRouteRule,omitempty"`
+	BreakdownByBackend   map[string]*BackendImpact   `json:"breakdownByBackend,omitempty"`
+	RouteDetails         []*RouteSimulationResult    `json:"routeDetails,omitempty"`
+
+	// Policy diffs: flows whose verdict differs between the two versions,
+	// with the result under each, and changed flows per pair of rules
+	VerdictDiffs          []*FlowVerdictDiff           `json:"verdictDiffs,omitempty"`
+	BreakdownByRuleChange map[string]*RuleChangeImpact `json:"breakdownByRuleChange,omitempty"`
+
 	// Errors encountered during simulation
 	Errors []string `json:"errors,omitempty"`
 
@@ -66,6 +108,17 @@ type NamespaceImpact struct {
 	WouldDeny      int64  `json:"wouldDeny"`      // Currently allowed, would be denied
 	WouldAllow     int64  `json:"wouldAllow"`     // Currently denied, would be allowed
 	NoChange       int64  `json:"noChange"`
+	Killed         int64  `json:"killed,omitempty"`  // Tetragon: process would be killed
+	Blocked        int64  `json:"blocked,omitempty"` // Tetragon: call would fail
+}
+
+// BinaryImpact shows the impact of a Tetragon simulation on a binary.
+type BinaryImpact struct {
+	Binary      string `json:"binary"`
+	TotalEvents int64  `json:"totalEvents"`
+	Killed      int64  `json:"killed"`
+	Blocked     int64  `json:"blocked"`
+	Audited     int64  `json:"audited"` // Matched without enforcement
 }
 
 // VerdictBreakdown shows the breakdown of verdict changes.
@@ -100,24 +153,222 @@ type FlowSimulationResult struct {
 	VerdictChanged   bool   `json:"verdictChanged"`
 
 	// Matching rule info
-	MatchedRule string `json:"matchedRule,omitempty"`
+	MatchedRule   string `json:"matchedRule,omitempty"`
+	MatchReason   string `json:"matchReason,omitempty"`
+	MatchedPolicy string `json:"matchedPolicy,omitempty"` // Set only by policy set simulations
+}
+
+// ConnectionImpact aggregates the flows of one connection, from a source
+// workload to a destination workload, port and L7 request, whose verdict
+// would change the same way.
+type ConnectionImpact struct {
+	SrcNamespace string `json:"srcNamespace"`
+	SrcWorkload  string `json:"srcWorkload"`
+	DstNamespace string `json:"dstNamespace"`
+	DstWorkload  string `json:"dstWorkload"`
+	DstPort      uint32 `json:"dstPort"`
+	Protocol     string `json:"protocol"`
+	// L7 describes the request, e.g. "HTTP GET /api/users" (empty for L4 flows)
+	L7 string `json:"l7,omitempty"`
+
+	OriginalVerdict  string `json:"originalVerdict"`
+	SimulatedVerdict string `json:"simulatedVerdict"`
+
+	// Matching rule info of the first flow seen
+	MatchedRule   string `json:"matchedRule,omitempty"`
+	MatchReason   string `json:"matchReason,omitempty"`
+	MatchedPolicy string `json:"matchedPolicy,omitempty"`
+
+	Flows     int64     `json:"flows"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+
+	// Samples are representative flows of the connection
+	Samples []*FlowSimulationResult `json:"samples,omitempty"`
+}
+
+// RouteRuleImpact shows how many requests a route rule would match.
+type RouteRuleImpact struct {
+	Rule     string   `json:"rule"`
+	Backends []string `json:"backends,omitempty"`
+	Requests int64    `json:"requests"`
+}
+
+// BackendImpact shows the traffic a backend would receive. Requests counts
+// requests matched by rules referencing the backend; ExpectedRequests
+// weighs them by the backend's share of each rule's weights.
+type BackendImpact struct {
+	Backend          string  `json:"backend"`
+	Requests         int64   `json:"requests"`
+	ExpectedRequests float64 `json:"expectedRequests"`
+}
+
+// RouteSimulationResult contains the route simulation result for a single request.
+type RouteSimulationResult struct {
+	// Request identification
+	Timestamp    time.Time `json:"timestamp"`
+	SrcNamespace string    `json:"srcNamespace"`
+	SrcPodName   string    `json:"srcPodName,omitempty"`
+	DstNamespace string    `json:"dstNamespace"`
+	DstPodName   string    `json:"dstPodName,omitempty"`
+	Method       string    `json:"method,omitempty"`
+	Host         string    `json:"host,omitempty"`
+	Path         string    `json:"path"`
+
+	// Outcome is ROUTED, REDIRECTED, NO_BACKEND (500) or NOT_FOUND (404)
+	Outcome     string   `json:"outcome"`
+	StatusCode  int32    `json:"statusCode,omitempty"`
+	MatchedRule string   `json:"matchedRule,omitempty"`
+	Backends    []string `json:"backends,omitempty"`
+	MatchReason string   `json:"matchReason,omitempty"`
+}
+
+// ProcessSimulationResult contains the simulation result for a single process event.
+type ProcessSimulationResult struct {
+	// Event identification
+	Timestamp time.Time `json:"timestamp"`
+	EventType string    `json:"eventType"`
+	Namespace string    `json:"namespace"`
+	PodName   string    `json:"podName,omitempty"`
+	Binary    string    `json:"binary"`
+	Arguments string    `json:"arguments,omitempty"`
+	Syscall   string    `json:"syscall,omitempty"`
+	FilePath  string    `json:"filePath,omitempty"`
+
+	// Verdicts
+	OriginalVerdict  string `json:"originalVerdict"`
+	SimulatedVerdict string `json:"simulatedVerdict"`
+	VerdictChanged   bool   `json:"verdictChanged"`
+
+	// Action is the enforcement action that would apply (SIGKILL, OVERRIDE)
+	// or POST for a match without enforcement
+	Action      string `json:"action,omitempty"`
+	MatchedHook string `json:"matchedHook,omitempty"`
 	MatchReason string `json:"matchReason,omitempty"`
 }
 
+// PolicySetSimulationRequest describes a "what-if" simulation of a change to
+// the set of policies deployed in the cluster.
+type PolicySetSimulationRequest struct {
+	// CurrentPolicies is the policy set the changes are applied to. When nil,
+	// the policies are loaded from the engine's PolicySetSource.
+	CurrentPolicies []PolicyDocument `json:"currentPolicies,omitempty"`
+
+	// Changes is the proposed diff, applied in order
+	Changes []PolicyChange `json:"changes"`
+
+	// TimeRange specifies the historical data window
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+
+	// IncludeDetails controls whether to return the flows whose verdict changes
+	IncludeDetails bool `json:"includeDetails,omitempty"`
+
+	// MaxDetails limits the number of detailed results returned
+	MaxDetails int32 `json:"maxDetails,omitempty"`
+
+	// Progress is called as the stored events are evaluated (optional)
+	Progress func(SimulationProgress) `json:"-"`
+}
+
+// PolicyDocument is a single policy of a policy set.
+type PolicyDocument struct {
+	// Name and Namespace identify the policy when its metadata does not.
+	// Namespace is ignored for clusterwide policies.
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+
+	// PolicyType is CILIUM_NETWORK or CILIUM_CLUSTERWIDE (default CILIUM_NETWORK)
+	PolicyType string `json:"policyType,omitempty"`
+
+	// Content is the raw YAML content of the policy
+	Content string `json:"content,omitempty"`
+}
+
+// PolicyChange is a single entry of a proposed policy set diff.
+type PolicyChange struct {
+	// Operation is one of add, modify or delete
+	Operation string `json:"operation"`
+
+	// Policy is the policy to add or the new version of the policy to modify.
+	// Deletions only need Name and Namespace.
+	Policy PolicyDocument `json:"policy"`
+}
+
+// PolicyDiffRequest compares two versions of a network policy against
+// historical flows.
+type PolicyDiffRequest struct {
+	// PolicyType is CILIUM_NETWORK or CILIUM_CLUSTERWIDE (default CILIUM_NETWORK)
+	PolicyType string `json:"policyType,omitempty"`
+
+	// BaseContent and TargetContent are the raw YAML of the two versions
+	BaseContent   string `json:"baseContent"`
+	TargetContent string `json:"targetContent"`
+
+	// BaseVersion and TargetVersion label the two versions in logs (optional)
+	BaseVersion   int `json:"baseVersion,omitempty"`
+	TargetVersion int `json:"targetVersion,omitempty"`
+
+	// TimeRange specifies the historical data window
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+
+	// MaxDetails limits the number of differing flows returned
+	MaxDetails int32 `json:"maxDetails,omitempty"`
+
+	// Progress is called as the stored events are evaluated (optional)
+	Progress func(SimulationProgress) `json:"-"`
+}
+
+// FlowVerdictDiff is a flow whose simulated verdict differs between two
+// versions of a policy. Base and Target carry the verdict, matched rule and
+// reason under each version.
+type FlowVerdictDiff struct {
+	Base   *FlowSimulationResult `json:"base"`
+	Target *FlowSimulationResult `json:"target"`
+}
+
+// RuleChangeImpact counts the flows whose verdict changes from a rule of the
+// base version to a rule of the target version. A side that matched no rule
+// is described by its match reason in parentheses.
+type RuleChangeImpact struct {
+	BaseRule     string `json:"baseRule"`
+	TargetRule   string `json:"targetRule"`
+	Flows        int64  `json:"flows"`
+	NewlyAllowed int64  `json:"newlyAllowed"`
+	NewlyDenied  int64  `json:"newlyDenied"`
+}
+
 // PolicyRule represents a parsed rule from a network policy.
+//
+// Each rule carries at most one kind of peer selector (endpoints, CIDRs,
+// entities, services or FQDNs) so that the alternatives listed in a single
+// Cilium rule keep their OR semantics once expanded. L4/L7 constraints are
+// shared by every rule expanded from the same Cilium rule.
 type PolicyRule struct {
 	// Direction: ingress or egress
 	Direction string `json:"direction"`
 
 	// Selectors
-	PodSelector       map[string]string `json:"podSelector,omitempty"`
-	NamespaceSelector map[string]string `json:"namespaceSelector,omitempty"`
+	PodSelector       map[string]string     `json:"podSelector,omitempty"`
+	NamespaceSelector map[string]string     `json:"namespaceSelector,omitempty"`
+	MatchExpressions  []SelectorRequirement `json:"matchExpressions,omitempty"`
+
+	// Endpoints is set when the rule came from fromEndpoints/toEndpoints.
+	// Endpoint selectors only ever select cluster-managed peers, so an empty
+	// selector still excludes the world.
+	Endpoints bool `json:"endpoints,omitempty"`
 
 	// Endpoint matching
-	ToPorts   []PortRule   `json:"toPorts,omitempty"`
-	FromCIDRs []string     `json:"fromCIDRs,omitempty"`
-	ToCIDRs   []string     `json:"toCIDRs,omitempty"`
-	ToFQDNs   []string     `json:"toFQDNs,omitempty"`
+	ToPorts     []PortRule    `json:"toPorts,omitempty"`
+	FromCIDRs   []string      `json:"fromCIDRs,omitempty"`
+	ToCIDRs     []string      `json:"toCIDRs,omitempty"`
+	FromCIDRSet []CIDRRule    `json:"fromCIDRSet,omitempty"`
+	ToCIDRSet   []CIDRRule    `json:"toCIDRSet,omitempty"`
+	ToFQDNs     []string      `json:"toFQDNs,omitempty"`
+	Entities    []string      `json:"entities,omitempty"`
+	ToServices  []ServiceRule `json:"toServices,omitempty"`
+	ICMPs       []ICMPRule    `json:"icmps,omitempty"`
 
 	// L7 rules
 	L7Rules []L7Rule `json:"l7Rules,omitempty"`
@@ -126,6 +377,39 @@ type PolicyRule struct {
 	Action string `json:"action"`
 }
 
+// SelectorRequirement is a single matchExpressions entry of a label selector.
+type SelectorRequirement struct {
+	Key      string   `json:"key"`
+	Operator string   `json:"operator"` // In, NotIn, Exists, DoesNotExist
+	Values   []string `json:"values,omitempty"`
+}
+
+// EndpointSelector is a Kubernetes-style label selector as used by Cilium.
+type EndpointSelector struct {
+	MatchLabels      map[string]string     `json:"matchLabels,omitempty"`
+	MatchExpressions []SelectorRequirement `json:"matchExpressions,omitempty"`
+}
+
+// CIDRRule is a fromCIDRSet/toCIDRSet entry: a prefix minus its exceptions.
+type CIDRRule struct {
+	CIDR   string   `json:"cidr"`
+	Except []string `json:"except,omitempty"`
+}
+
+// ServiceRule selects a Kubernetes service in a toServices rule, either by
+// name or by label selector.
+type ServiceRule struct {
+	Name      string            `json:"name,omitempty"`
+	Namespace string            `json:"namespace,omitempty"`
+	Selector  map[string]string `json:"selector,omitempty"`
+}
+
+// ICMPRule represents an icmps field entry.
+type ICMPRule struct {
+	Family string `json:"family"` // IPv4 or IPv6
+	Type   string `json:"type"`
+}
+
 // PortRule represents a port/protocol rule.
 type PortRule struct {
 	Port     uint32 `json:"port"`
@@ -133,22 +417,158 @@ type PortRule struct {
 	Protocol string `json:"protocol"`
 }
 
-// L7Rule represents an L7 (application layer) rule.
+// L7Rule represents an L7 (application layer) rule. gRPC is enforced by
+// Cilium as HTTP, so gRPC requests are matched by http rules on their
+// "/package.Service/Method" path.
 type L7Rule struct {
-	Type   string `json:"type"` // http, dns, kafka, etc.
-	Method string `json:"method,omitempty"`
-	Path   string `json:"path,omitempty"`
-	Host   string `json:"host,omitempty"`
+	Type    string        `json:"type"` // http, dns, kafka
+	Method  string        `json:"method,omitempty"`
+	Path    string        `json:"path,omitempty"`
+	Host    string        `json:"host,omitempty"`
+	Headers []HeaderMatch `json:"headers,omitempty"`
+
+	// Kafka: a role is expanded to the API keys it allows. Hubble does not
+	// record API versions or client IDs, so those are kept for display only.
+	Topic      string   `json:"topic,omitempty"`
+	APIKeys    []string `json:"apiKeys,omitempty"`
+	APIVersion string   `json:"apiVersion,omitempty"`
+	ClientID   string   `json:"clientID,omitempty"`
+}
+
+// HeaderMatch is an HTTP header a request must carry. An empty value only
+// requires the header to be present.
+type HeaderMatch struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
 }
 
 // ParsedPolicy represents a fully parsed network policy.
 type ParsedPolicy struct {
-	Name            string            `json:"name"`
-	Namespace       string            `json:"namespace"`
-	Type            string            `json:"type"`
-	PodSelector     map[string]string `json:"podSelector"`
-	IngressRules    []PolicyRule      `json:"ingressRules,omitempty"`
-	EgressRules     []PolicyRule      `json:"egressRules,omitempty"`
-	DefaultDeny     bool              `json:"defaultDeny"`
-	DefaultDenyType string            `json:"defaultDenyType,omitempty"` // ingress, egress, or both
+	Name                   string                `json:"name"`
+	Namespace              string                `json:"namespace"`
+	Type                   string                `json:"type"`
+	PodSelector            map[string]string     `json:"podSelector"`
+	PodSelectorExpressions []SelectorRequirement `json:"podSelectorExpressions,omitempty"`
+	IngressRules           []PolicyRule          `json:"ingressRules,omitempty"`
+	EgressRules            []PolicyRule          `json:"egressRules,omitempty"`
+	DefaultDeny            bool                  `json:"defaultDeny"`
+	DefaultDenyType        string                `json:"defaultDenyType,omitempty"` // ingress, egress, or both
+
+	// IngressRequires/EgressRequires hold fromRequires/toRequires selectors.
+	// Cilium applies them to every rule of the policy in that direction: a
+	// peer must match all of them in addition to an allow rule.
+	IngressRequires []EndpointSelector `json:"ingressRequires,omitempty"`
+	EgressRequires  []EndpointSelector `json:"egressRequires,omitempty"`
+
+	// Hooks holds the kprobes and tracepoints of a Tetragon TracingPolicy
+	Hooks []TracingHook `json:"hooks,omitempty"`
+
+	// ParentRefs, Hostnames and RouteRules hold a Gateway API HTTPRoute or
+	// GRPCRoute
+	ParentRefs []RouteParentRef `json:"parentRefs,omitempty"`
+	Hostnames  []string         `json:"hostnames,omitempty"`
+	RouteRules []RouteRule      `json:"routeRules,omitempty"`
+}
+
+// RouteParentRef is a Gateway, or a Service for mesh routes, that a route
+// attaches to.
+type RouteParentRef struct {
+	Kind      string `json:"kind"` // Gateway or Service
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Port      int32  `json:"port,omitempty"`
+}
+
+// RouteRule is a rule of a Gateway API route. Matches are alternatives; a
+// rule without matches matches every request.
+type RouteRule struct {
+	Matches  []RouteMatch   `json:"matches,omitempty"`
+	Backends []RouteBackend `json:"backends,omitempty"`
+
+	// RedirectStatus is set when the rule has a RequestRedirect filter
+	RedirectStatus int32 `json:"redirectStatus,omitempty"`
+}
+
+// RouteMatch is a single HTTPRoute or GRPCRoute match. All of its conditions
+// must hold.
+type RouteMatch struct {
+	// HTTPRoute path and method
+	PathType  string `json:"pathType,omitempty"` // Exact, PathPrefix or RegularExpression
+	PathValue string `json:"pathValue,omitempty"`
+	Method    string `json:"method,omitempty"`
+
+	// GRPCRoute method
+	GRPCMatchType string `json:"grpcMatchType,omitempty"` // Exact or RegularExpression
+	GRPCService   string `json:"grpcService,omitempty"`
+	GRPCMethod    string `json:"grpcMethod,omitempty"`
+
+	Headers     []RouteValueMatch `json:"headers,omitempty"`
+	QueryParams []RouteValueMatch `json:"queryParams,omitempty"`
+}
+
+// RouteValueMatch matches a header or query parameter.
+type RouteValueMatch struct {
+	Type  string `json:"type,omitempty"` // Exact or RegularExpression
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// RouteBackend is a backendRef of a route rule.
+type RouteBackend struct {
+	Kind      string `json:"kind,omitempty"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Port      int32  `json:"port,omitempty"`
+	Weight    int32  `json:"weight"`
+}
+
+// TracingHook is a kprobe or tracepoint of a Tetragon TracingPolicy.
+type TracingHook struct {
+	Type      string            `json:"type"` // kprobe or tracepoint
+	Call      string            `json:"call"` // kprobe function or tracepoint subsystem/event
+	Syscall   bool              `json:"syscall,omitempty"`
+	Args      []TracingArg      `json:"args,omitempty"`
+	Selectors []TracingSelector `json:"selectors,omitempty"`
+}
+
+// TracingArg declares a hook argument that Tetragon records.
+type TracingArg struct {
+	Index int    `json:"index"`
+	Type  string `json:"type"`
+}
+
+// TracingSelector is an entry of a hook's selectors. All of its filters must
+// match for its actions to apply.
+type TracingSelector struct {
+	MatchBinaries   []TracingValueMatch     `json:"matchBinaries,omitempty"`
+	MatchArgs       []TracingArgMatch       `json:"matchArgs,omitempty"`
+	MatchNamespaces []TracingNamespaceMatch `json:"matchNamespaces,omitempty"`
+	MatchActions    []TracingAction         `json:"matchActions,omitempty"`
+}
+
+// TracingValueMatch compares a value against a list using a Tetragon operator.
+type TracingValueMatch struct {
+	Operator string   `json:"operator"`
+	Values   []string `json:"values,omitempty"`
+}
+
+// TracingArgMatch is a matchArgs entry.
+type TracingArgMatch struct {
+	Index    int      `json:"index"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values,omitempty"`
+}
+
+// TracingNamespaceMatch is a matchNamespaces entry on a Linux namespace.
+type TracingNamespaceMatch struct {
+	Namespace string   `json:"namespace"` // Pid, Mnt, Net, ...
+	Operator  string   `json:"operator"`
+	Values    []string `json:"values,omitempty"`
+}
+
+// TracingAction is a matchActions entry.
+type TracingAction struct {
+	Action   string `json:"action"`
+	ArgError int32  `json:"argError,omitempty"`
+	ArgSig   int32  `json:"argSig,omitempty"`
 }
@@ -12,22 +12,41 @@ import (
 )
 
 // Worker processes pending simulations from SaaS and reports results.
+//
+// Pending simulations are queued and run at most MaxConcurrent at a time per
+// node, including those started through RunOnce and RunAndReport. Running
+// simulations report their progress to SaaS periodically and stop early when
+// SaaS answers that they have been cancelled.
 type Worker struct {
 	engine     *Engine
 	saasClient *saas.Client
+	events     *saas.EventStream
 	log        logr.Logger
 
 	// Configuration
-	pollInterval time.Duration
+	pollInterval     time.Duration
+	progressInterval time.Duration
+	queueSize        int
+
+	// slots limits the number of simulations running at once
+	slots chan struct{}
+	// wake tells the drainer that simulations were queued
+	wake chan struct{}
 
 	// State
 	mu      sync.RWMutex
 	running bool
 	cancel  context.CancelFunc
+	queue   []*saas.PendingSimulation
+	// known holds the IDs of queued and running simulations, which stay
+	// pending in SaaS until their result is submitted
+	known  map[string]bool
+	active map[string]context.CancelFunc
 
 	// Metrics
 	totalProcessed int64
 	totalErrors    int64
+	totalCancelled int64
 }
 
 // WorkerConfig contains configuration for the simulation worker.
@@ -35,7 +54,18 @@ type WorkerConfig struct {
 	Engine       *Engine
 	SaaSClient   *saas.Client
 	PollInterval time.Duration
-	Logger       logr.Logger
+	// MaxConcurrent is the number of simulations run at once (default: 1)
+	MaxConcurrent int
+	// QueueSize is the number of pending simulations queued on this node;
+	// further ones are left pending in SaaS until the next poll (default: 10)
+	QueueSize int
+	// ProgressInterval is how often running simulations report progress
+	// (default: 10s)
+	ProgressInterval time.Duration
+	// Events notifies of new simulations; while it is connected the worker
+	// only polls every saas.StreamResyncInterval (optional)
+	Events *saas.EventStream
+	Logger logr.Logger
 }
 
 // NewWorker creates a new simulation worker.
@@ -44,12 +74,31 @@ func NewWorker(cfg WorkerConfig) *Worker {
 	if pollInterval == 0 {
 		pollInterval = 30 * time.Second
 	}
+	maxConcurrent := cfg.MaxConcurrent
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = 10
+	}
+	progressInterval := cfg.ProgressInterval
+	if progressInterval == 0 {
+		progressInterval = 10 * time.Second
+	}
 
 	return &Worker{
-		engine:       cfg.Engine,
-		saasClient:   cfg.SaaSClient,
-		pollInterval: pollInterval,
-		log:          cfg.Logger.WithName("simulation-worker"),
+		engine:           cfg.Engine,
+		saasClient:       cfg.SaaSClient,
+		events:           cfg.Events,
+		pollInterval:     pollInterval,
+		progressInterval: progressInterval,
+		queueSize:        queueSize,
+		slots:            make(chan struct{}, maxConcurrent),
+		wake:             make(chan struct{}, 1),
+		known:            make(map[string]bool),
+		active:           make(map[string]context.CancelFunc),
+		log:              cfg.Logger.WithName("simulation-worker"),
 	}
 }
 
@@ -66,7 +115,11 @@ func (w *Worker) Start(ctx context.Context) error {
 	w.running = true
 	w.mu.Unlock()
 
-	w.log.Info("Starting simulation worker", "pollInterval", w.pollInterval)
+	w.log.Info("Starting simulation worker",
+		"pollInterval", w.pollInterval,
+		"maxConcurrent", cap(w.slots),
+		"queueSize", w.queueSize,
+	)
 
 	go w.run(ctx)
 
@@ -85,25 +138,63 @@ func (w *Worker) Stop() {
 	w.log.Info("Simulation worker stopped")
 }
 
-// run is the main processing loop.
+// Cancel stops a queued or running simulation. It returns false if the
+// simulation is unknown to this worker.
+func (w *Worker) Cancel(simulationID string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if cancel, ok := w.active[simulationID]; ok {
+		cancel()
+		return true
+	}
+	for i, pending := range w.queue {
+		if pending.SimulationID == simulationID {
+			w.queue = append(w.queue[:i], w.queue[i+1:]...)
+			delete(w.known, simulationID)
+			w.totalCancelled++
+			return true
+		}
+	}
+	return false
+}
+
+// run is the main processing loop. Queued simulations are run by a separate
+// drainer, so polling goes on while they run and new simulations are reported
+// queued right away.
 func (w *Worker) run(ctx context.Context) {
+	go w.drain(ctx)
+
 	ticker := time.NewTicker(w.pollInterval)
 	defer ticker.Stop()
 
+	var notifications <-chan struct{}
+	if w.events != nil {
+		notifications = w.events.Subscribe(saas.EventSimulations)
+	}
+
 	// Process immediately on start
 	w.processPendingSimulations(ctx)
+	lastPoll := time.Now()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			w.processPendingSimulations(ctx)
+			if w.events.Connected() && time.Since(lastPoll) < saas.StreamResyncInterval {
+				continue
+			}
+		case <-notifications:
+			w.log.V(1).Info("Simulations changed in SaaS, fetching")
 		}
+		w.processPendingSimulations(ctx)
+		lastPoll = time.Now()
 	}
 }
 
-// processPendingSimulations fetches and processes pending simulations.
+// processPendingSimulations fetches pending simulations and queues the new
+// ones for the drainer.
 func (w *Worker) processPendingSimulations(ctx context.Context) {
 	if w.saasClient == nil {
 		return
@@ -120,17 +211,133 @@ func (w *Worker) processPendingSimulations(ctx context.Context) {
 		return
 	}
 
-	w.log.Info("Processing pending simulations", "count", len(resp.Simulations))
+	queued := w.enqueue(ctx, resp.Simulations)
+	w.log.Info("Processing pending simulations", "count", len(resp.Simulations), "queued", queued)
+}
+
+// enqueue adds the simulations that are not queued or running yet to the
+// queue, up to its size, and reports them as queued. It returns the number
+// of simulations added.
+func (w *Worker) enqueue(ctx context.Context, simulations []saas.PendingSimulation) int {
+	var added []*saas.PendingSimulation
+	w.mu.Lock()
+	for i := range simulations {
+		pending := &simulations[i]
+		if w.known[pending.SimulationID] {
+			continue
+		}
+		if len(w.queue) >= w.queueSize {
+			w.log.V(1).Info("Simulation queue full, leaving simulation pending", "simulationId", pending.SimulationID)
+			continue
+		}
+		w.queue = append(w.queue, pending)
+		w.known[pending.SimulationID] = true
+		added = append(added, pending)
+	}
+	position := len(w.queue) - len(added)
+	w.mu.Unlock()
+
+	if len(added) > 0 {
+		select {
+		case w.wake <- struct{}{}:
+		default: // The drainer is already woken
+		}
+	}
+
+	for _, pending := range added {
+		position++
+		cancelled := w.reportProgress(ctx, &saas.SimulationProgress{
+			SimulationID:  pending.SimulationID,
+			Status:        saas.SimulationStatusQueued,
+			QueuePosition: position,
+		})
+		if cancelled {
+			w.log.Info("Simulation cancelled while queued", "simulationId", pending.SimulationID)
+			w.Cancel(pending.SimulationID)
+		}
+	}
+	return len(added)
+}
 
-	for _, pending := range resp.Simulations {
-		if ctx.Err() != nil {
+// drain runs queued simulations as slots free up until the worker stops,
+// waiting for enqueue to wake it when the queue is empty.
+func (w *Worker) drain(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		for w.startNext(ctx, &wg) {
+		}
+		select {
+		case <-ctx.Done():
+			w.dropQueue()
 			return
+		case <-w.wake:
 		}
+	}
+}
+
+// startNext waits for a free slot and starts the next queued simulation in
+// it. It returns false if the queue is empty or the worker is stopping.
+func (w *Worker) startNext(ctx context.Context, wg *sync.WaitGroup) bool {
+	if err := w.acquire(ctx); err != nil {
+		w.dropQueue()
+		return false
+	}
+
+	w.mu.Lock()
+	if len(w.queue) == 0 {
+		w.mu.Unlock()
+		w.release()
+		return false
+	}
+	pending := w.queue[0]
+	w.queue = w.queue[1:]
+	w.mu.Unlock()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer w.release()
+		defer w.forget(pending.SimulationID)
+		w.processSimulation(ctx, pending)
+	}()
+	return true
+}
 
-		w.processSimulation(ctx, &pending)
+// dropQueue empties the queue when the worker stops: the simulations stay
+// pending in SaaS.
+func (w *Worker) dropQueue() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, pending := range w.queue {
+		delete(w.known, pending.SimulationID)
+	}
+	w.queue = nil
+}
+
+// acquire waits for a free simulation slot.
+func (w *Worker) acquire(ctx context.Context) error {
+	select {
+	case w.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
+// release frees a simulation slot.
+func (w *Worker) release() {
+	<-w.slots
+}
+
+// forget drops a finished simulation from the known simulations.
+func (w *Worker) forget(simulationID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.known, simulationID)
+}
+
 // processSimulation runs a single simulation and reports results.
 func (w *Worker) processSimulation(ctx context.Context, pending *saas.PendingSimulation) {
 	startTime := time.Now()
@@ -141,19 +348,44 @@ func (w *Worker) processSimulation(ctx context.Context, pending *saas.PendingSim
 		"endTime", pending.EndTime,
 	)
 
-	// Convert SaaS request to simulation request
-	simReq := &SimulationRequest{
-		PolicyContent:  pending.PolicyContent,
-		PolicyType:     pending.PolicyType,
-		StartTime:      pending.StartTime,
-		EndTime:        pending.EndTime,
-		Namespaces:     pending.Namespaces,
-		IncludeDetails: pending.IncludeDetails,
-		MaxDetails:     pending.MaxDetails,
+	simCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w.mu.Lock()
+	w.active[pending.SimulationID] = cancel
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		delete(w.active, pending.SimulationID)
+		w.mu.Unlock()
+	}()
+
+	// Run the simulation, reporting progress until it returns
+	progress := newProgressTracker(pending.SimulationID, startTime)
+	stopReports := w.reportProgressUntilDone(simCtx, progress, cancel)
+	simResp, err := w.runSimulation(simCtx, pending, progress.update)
+	stopReports()
+
+	if err != nil && ctx.Err() != nil {
+		// The worker is stopping: leave the simulation pending in SaaS
+		w.log.Info("Simulation interrupted", "simulationId", pending.SimulationID)
+		return
 	}
+	if err != nil && simCtx.Err() != nil {
+		w.mu.Lock()
+		w.totalCancelled++
+		w.mu.Unlock()
+
+		w.log.Info("Simulation cancelled", "simulationId", pending.SimulationID)
 
-	// Run the simulation
-	simResp, err := w.engine.Simulate(ctx, simReq)
+		// Report the cancellation so SaaS stops waiting for this node
+		w.reportCancelled(ctx, pending.SimulationID, &SimulationResponse{
+			Errors:         []string{"Simulation cancelled"},
+			SimulationTime: startTime,
+			Duration:       time.Since(startTime),
+		}, pending)
+		return
+	}
 	if err != nil {
 		w.mu.Lock()
 		w.totalErrors++
@@ -185,13 +417,79 @@ func (w *Worker) processSimulation(ctx context.Context, pending *saas.PendingSim
 	)
 }
 
+// runSimulation converts a SaaS request into a single-policy or policy set
+// simulation and runs it.
+func (w *Worker) runSimulation(ctx context.Context, pending *saas.PendingSimulation, progress func(SimulationProgress)) (*SimulationResponse, error) {
+	if len(pending.PolicyChanges) > 0 {
+		setReq := &PolicySetSimulationRequest{
+			StartTime:      pending.StartTime,
+			EndTime:        pending.EndTime,
+			IncludeDetails: pending.IncludeDetails,
+			MaxDetails:     pending.MaxDetails,
+			Progress:       progress,
+		}
+		for _, change := range pending.PolicyChanges {
+			setReq.Changes = append(setReq.Changes, PolicyChange{
+				Operation: change.Operation,
+				Policy: PolicyDocument{
+					Name:       change.Name,
+					Namespace:  change.Namespace,
+					PolicyType: change.PolicyType,
+					Content:    change.PolicyContent,
+				},
+			})
+		}
+		return w.engine.SimulatePolicySet(ctx, setReq)
+	}
+
+	if pending.BasePolicyContent != "" {
+		return w.engine.SimulateDiff(ctx, &PolicyDiffRequest{
+			PolicyType:    pending.PolicyType,
+			BaseContent:   pending.BasePolicyContent,
+			TargetContent: pending.PolicyContent,
+			BaseVersion:   pending.BaseVersion,
+			TargetVersion: pending.PolicyVersion,
+			StartTime:     pending.StartTime,
+			EndTime:       pending.EndTime,
+			MaxDetails:    pending.MaxDetails,
+			Progress:      progress,
+		})
+	}
+
+	simReq := &SimulationRequest{
+		PolicyContent:  pending.PolicyContent,
+		PolicyType:     pending.PolicyType,
+		StartTime:      pending.StartTime,
+		EndTime:        pending.EndTime,
+		Namespaces:     pending.Namespaces,
+		IncludeDetails: pending.IncludeDetails,
+		MaxDetails:     pending.MaxDetails,
+		Progress:       progress,
+	}
+	return w.engine.Simulate(ctx, simReq)
+}
+
 // reportResult sends simulation results back to SaaS.
 func (w *Worker) reportResult(ctx context.Context, simulationID string, resp *SimulationResponse, pending *saas.PendingSimulation) {
 	if w.saasClient == nil {
 		return
 	}
+	w.submitResult(ctx, w.simulationResult(simulationID, resp, pending))
+}
 
-	// Convert simulation response to SaaS result format
+// reportCancelled tells SaaS that this node stopped a cancelled simulation,
+// so it stops waiting for the node's result.
+func (w *Worker) reportCancelled(ctx context.Context, simulationID string, resp *SimulationResponse, pending *saas.PendingSimulation) {
+	if w.saasClient == nil {
+		return
+	}
+	result := w.simulationResult(simulationID, resp, pending)
+	result.Cancelled = true
+	w.submitResult(ctx, result)
+}
+
+// simulationResult converts a simulation response to the SaaS result format.
+func (w *Worker) simulationResult(simulationID string, resp *SimulationResponse, pending *saas.PendingSimulation) *saas.SimulationResult {
 	result := &saas.SimulationResult{
 		SimulationID:       simulationID,
 		NodeName:           w.saasClient.GetNodeName(), // For multi-node aggregation
@@ -205,6 +503,8 @@ func (w *Worker) reportResult(ctx context.Context, simulationID string, resp *Si
 		DeniedCount:        resp.DeniedCount,
 		NoChangeCount:      resp.NoChangeCount,
 		WouldChangeCount:   resp.WouldChangeCount,
+		KilledCount:        resp.KilledCount,
+		BlockedCount:       resp.BlockedCount,
 		Errors:             resp.Errors,
 		SimulationTime:     resp.SimulationTime,
 		Duration:           resp.Duration,
@@ -222,6 +522,8 @@ func (w *Worker) reportResult(ctx context.Context, simulationID string, resp *Si
 				WouldDeny:    impact.WouldDeny,
 				WouldAllow:   impact.WouldAllow,
 				NoChange:     impact.NoChange,
+				Killed:       impact.Killed,
+				Blocked:      impact.Blocked,
 			}
 		}
 	}
@@ -242,27 +544,149 @@ func (w *Worker) reportResult(ctx context.Context, simulationID string, resp *Si
 	if len(resp.Details) > 0 {
 		result.SampleFlows = make([]saas.SimulatedFlow, len(resp.Details))
 		for i, detail := range resp.Details {
-			result.SampleFlows[i] = saas.SimulatedFlow{
+			result.SampleFlows[i] = simulatedFlow(detail)
+		}
+	}
+
+	// Convert aggregated connections
+	result.TotalConnections = resp.TotalConnections
+	if len(resp.Connections) > 0 {
+		result.Connections = make([]saas.SimulatedConnection, len(resp.Connections))
+		for i, conn := range resp.Connections {
+			result.Connections[i] = saas.SimulatedConnection{
+				SrcNamespace:     conn.SrcNamespace,
+				SrcWorkload:      conn.SrcWorkload,
+				DstNamespace:     conn.DstNamespace,
+				DstWorkload:      conn.DstWorkload,
+				DstPort:          conn.DstPort,
+				Protocol:         conn.Protocol,
+				L7:               conn.L7,
+				OriginalVerdict:  conn.OriginalVerdict,
+				SimulatedVerdict: conn.SimulatedVerdict,
+				MatchedRule:      conn.MatchedRule,
+				MatchReason:      conn.MatchReason,
+				MatchedPolicy:    conn.MatchedPolicy,
+				Flows:            conn.Flows,
+				FirstSeen:        conn.FirstSeen,
+				LastSeen:         conn.LastSeen,
+			}
+			for _, sample := range conn.Samples {
+				result.Connections[i].Samples = append(result.Connections[i].Samples, simulatedFlow(sample))
+			}
+		}
+	}
+
+	// Convert policy diffs
+	if resp.BreakdownByRuleChange != nil {
+		result.BaseVersion = pending.BaseVersion
+		result.PolicyVersion = pending.PolicyVersion
+		result.BreakdownByRuleChange = make(map[string]*saas.RuleChangeImpact)
+		for key, impact := range resp.BreakdownByRuleChange {
+			result.BreakdownByRuleChange[key] = &saas.RuleChangeImpact{
+				BaseRule:     impact.BaseRule,
+				TargetRule:   impact.TargetRule,
+				Flows:        impact.Flows,
+				NewlyAllowed: impact.NewlyAllowed,
+				NewlyDenied:  impact.NewlyDenied,
+			}
+		}
+	}
+	if len(resp.VerdictDiffs) > 0 {
+		result.VerdictDiffs = make([]saas.SimulatedFlowDiff, len(resp.VerdictDiffs))
+		for i, diff := range resp.VerdictDiffs {
+			result.VerdictDiffs[i] = saas.SimulatedFlowDiff{
+				Base:   simulatedFlow(diff.Base),
+				Target: simulatedFlow(diff.Target),
+			}
+		}
+	}
+
+	// Convert Tetragon binary breakdown and sample process events
+	if resp.BreakdownByBinary != nil {
+		result.BreakdownByBinary = make(map[string]*saas.BinaryImpact)
+		for binary, impact := range resp.BreakdownByBinary {
+			result.BreakdownByBinary[binary] = &saas.BinaryImpact{
+				Binary:      impact.Binary,
+				TotalEvents: impact.TotalEvents,
+				Killed:      impact.Killed,
+				Blocked:     impact.Blocked,
+				Audited:     impact.Audited,
+			}
+		}
+	}
+	if len(resp.ProcessDetails) > 0 {
+		result.SampleProcesses = make([]saas.SimulatedProcess, len(resp.ProcessDetails))
+		for i, detail := range resp.ProcessDetails {
+			result.SampleProcesses[i] = saas.SimulatedProcess{
 				Timestamp:        detail.Timestamp,
-				SrcNamespace:     detail.SrcNamespace,
-				SrcPodName:       detail.SrcPodName,
-				DstNamespace:     detail.DstNamespace,
-				DstPodName:       detail.DstPodName,
-				DstPort:          detail.DstPort,
-				Protocol:         detail.Protocol,
+				EventType:        detail.EventType,
+				Namespace:        detail.Namespace,
+				PodName:          detail.PodName,
+				Binary:           detail.Binary,
+				Arguments:        detail.Arguments,
+				Syscall:          detail.Syscall,
+				FilePath:         detail.FilePath,
 				OriginalVerdict:  detail.OriginalVerdict,
 				SimulatedVerdict: detail.SimulatedVerdict,
-				VerdictChanged:   detail.VerdictChanged,
-				MatchedRule:      detail.MatchedRule,
+				Action:           detail.Action,
+				MatchedHook:      detail.MatchedHook,
 				MatchReason:      detail.MatchReason,
 			}
 		}
 	}
 
-	// Submit to SaaS
+	// Convert Gateway route breakdowns and sample requests
+	result.RoutedCount = resp.RoutedCount
+	result.NotFoundCount = resp.NotFoundCount
+	if resp.BreakdownByRouteRule != nil {
+		result.BreakdownByRule = make(map[string]*saas.RouteRuleImpact)
+		for rule, impact := range resp.BreakdownByRouteRule {
+			result.BreakdownByRule[rule] = &saas.RouteRuleImpact{
+				Rule:     impact.Rule,
+				Backends: impact.Backends,
+				Requests: impact.Requests,
+			}
+		}
+	}
+	if resp.BreakdownByBackend != nil {
+		result.BreakdownByBackend = make(map[string]*saas.BackendImpact)
+		for backend, impact := range resp.BreakdownByBackend {
+			result.BreakdownByBackend[backend] = &saas.BackendImpact{
+				Backend:          impact.Backend,
+				Requests:         impact.Requests,
+				ExpectedRequests: impact.ExpectedRequests,
+			}
+		}
+	}
+	if len(resp.RouteDetails) > 0 {
+		result.SampleRequests = make([]saas.SimulatedRequest, len(resp.RouteDetails))
+		for i, detail := range resp.RouteDetails {
+			result.SampleRequests[i] = saas.SimulatedRequest{
+				Timestamp:    detail.Timestamp,
+				SrcNamespace: detail.SrcNamespace,
+				SrcPodName:   detail.SrcPodName,
+				DstNamespace: detail.DstNamespace,
+				DstPodName:   detail.DstPodName,
+				Method:       detail.Method,
+				Host:         detail.Host,
+				Path:         detail.Path,
+				Outcome:      detail.Outcome,
+				StatusCode:   detail.StatusCode,
+				MatchedRule:  detail.MatchedRule,
+				Backends:     detail.Backends,
+				MatchReason:  detail.MatchReason,
+			}
+		}
+	}
+
+	return result
+}
+
+// submitResult sends a simulation result to SaaS.
+func (w *Worker) submitResult(ctx context.Context, result *saas.SimulationResult) {
 	_, err := w.saasClient.SubmitSimulationResult(ctx, result)
 	if err != nil {
-		w.log.Error(err, "Failed to report simulation result", "simulationId", simulationID)
+		w.log.Error(err, "Failed to report simulation result", "simulationId", result.SimulationID)
 	}
 }
 
@@ -273,20 +697,33 @@ func (w *Worker) GetStats() WorkerStats {
 
 	return WorkerStats{
 		Running:        w.running,
+		Queued:         len(w.queue),
+		Active:         len(w.active),
 		TotalProcessed: w.totalProcessed,
 		TotalErrors:    w.totalErrors,
+		TotalCancelled: w.totalCancelled,
 	}
 }
 
 // WorkerStats contains worker statistics.
 type WorkerStats struct {
 	Running        bool
+	Queued         int
+	Active         int
 	TotalProcessed int64
 	TotalErrors    int64
+	TotalCancelled int64
 }
 
 // RunOnce runs a single simulation and returns the result (for direct gRPC calls).
+// It waits for a free slot when the node is already running MaxConcurrent
+// simulations.
 func (w *Worker) RunOnce(ctx context.Context, req *SimulationRequest) (*SimulationResponse, error) {
+	if err := w.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer w.release()
+
 	return w.engine.Simulate(ctx, req)
 }
 
@@ -296,7 +733,7 @@ func (w *Worker) RunAndReport(ctx context.Context, req *SimulationRequest) (*Sim
 	simID := uuid.New().String()
 
 	// Run simulation
-	resp, err := w.engine.Simulate(ctx, req)
+	resp, err := w.RunOnce(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -316,3 +753,22 @@ func (w *Worker) RunAndReport(ctx context.Context, req *SimulationRequest) (*Sim
 
 	return resp, nil
 }
+
+// simulatedFlow converts a flow simulation result to the SaaS format.
+func simulatedFlow(detail *FlowSimulationResult) saas.SimulatedFlow {
+	return saas.SimulatedFlow{
+		Timestamp:        detail.Timestamp,
+		SrcNamespace:     detail.SrcNamespace,
+		SrcPodName:       detail.SrcPodName,
+		DstNamespace:     detail.DstNamespace,
+		DstPodName:       detail.DstPodName,
+		DstPort:          detail.DstPort,
+		Protocol:         detail.Protocol,
+		OriginalVerdict:  detail.OriginalVerdict,
+		SimulatedVerdict: detail.SimulatedVerdict,
+		VerdictChanged:   detail.VerdictChanged,
+		MatchedRule:      detail.MatchedRule,
+		MatchReason:      detail.MatchReason,
+		MatchedPolicy:    detail.MatchedPolicy,
+	}
+}
@@ -3,9 +3,12 @@ package simulation
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -362,6 +365,7 @@ spec:
 
 	ctx := context.Background()
 	worker.processPendingSimulations(ctx)
+	drainQueue(ctx, worker)
 
 	// Check that simulation was processed
 	stats := worker.GetStats()
@@ -665,6 +669,55 @@ func TestWorker_ReportResult_WithBreakdowns(t *testing.T) {
 	}
 }
 
+func TestWorker_SimulationResult_PolicyDiff(t *testing.T) {
+	client := saas.NewClient("http://localhost", "test-token", "test-cluster", logr.Discard())
+	worker := NewWorker(WorkerConfig{
+		SaaSClient: client,
+		Logger:     logr.Discard(),
+	})
+
+	pending := &saas.PendingSimulation{
+		SimulationID:      "sim-diff",
+		PolicyContent:     "v4",
+		BasePolicyContent: "v3",
+		BaseVersion:       3,
+		PolicyVersion:     4,
+		PolicyType:        "CILIUM_NETWORK",
+	}
+	resp := &SimulationResponse{
+		TotalFlowsAnalyzed: 10,
+		WouldChangeCount:   1,
+		VerdictDiffs: []*FlowVerdictDiff{{
+			Base:   &FlowSimulationResult{SrcPodName: "frontend", SimulatedVerdict: "ALLOWED", MatchedRule: "ingress ingress:TCP/9090"},
+			Target: &FlowSimulationResult{SrcPodName: "frontend", SimulatedVerdict: "DENIED", MatchReason: "No matching ingress rule, default deny"},
+		}},
+		BreakdownByRuleChange: map[string]*RuleChangeImpact{
+			"ingress ingress:TCP/9090 -> (No matching ingress rule, default deny)": {
+				BaseRule:    "ingress ingress:TCP/9090",
+				TargetRule:  "(No matching ingress rule, default deny)",
+				Flows:       1,
+				NewlyDenied: 1,
+			},
+		},
+	}
+
+	result := worker.simulationResult("sim-diff", resp, pending)
+
+	if result.BaseVersion != 3 || result.PolicyVersion != 4 {
+		t.Errorf("versions = %d, %d, want 3, 4", result.BaseVersion, result.PolicyVersion)
+	}
+	if len(result.VerdictDiffs) != 1 {
+		t.Fatalf("len(VerdictDiffs) = %d, want 1", len(result.VerdictDiffs))
+	}
+	diff := result.VerdictDiffs[0]
+	if diff.Base.MatchedRule != "ingress ingress:TCP/9090" || diff.Target.SimulatedVerdict != "DENIED" {
+		t.Errorf("VerdictDiffs[0] = %+v", diff)
+	}
+	if len(result.BreakdownByRuleChange) != 1 {
+		t.Errorf("BreakdownByRuleChange length = %d, want 1", len(result.BreakdownByRuleChange))
+	}
+}
+
 func TestWorker_RunAndReport_WithSaaSClient(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "worker-test-*")
 	if err != nil {
@@ -740,3 +793,314 @@ spec:
 		t.Error("Expected result to be submitted to SaaS")
 	}
 }
+
+// drainQueue runs the queued simulations and waits for them to complete,
+// like the worker's drainer does while it runs
+func drainQueue(ctx context.Context, w *Worker) {
+	var wg sync.WaitGroup
+	for w.startNext(ctx, &wg) {
+	}
+	wg.Wait()
+}
+
+func TestWorker_ProcessPendingSimulations_Queue(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "worker-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	mgr, err := storage.NewManager(storage.ManagerConfig{
+		BasePath: tmpDir,
+		NodeName: "test-node",
+		Logger:   logr.Discard(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+	defer mgr.Close()
+
+	engine := NewEngine(EngineConfig{
+		StorageManager: mgr,
+		Logger:         logr.Discard(),
+	})
+
+	// SaaS keeps simulations pending until a result is submitted or they
+	// are cancelled; sim-2 is cancelled as soon as it is queued
+	var mu sync.Mutex
+	done := make(map[string]bool)
+	queuedAt := make(map[string]int)
+	var results []saas.SimulationResult
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/api/operator/simulation/pending":
+			resp := saas.FetchPendingSimulationsResponse{Success: true}
+			for _, id := range []string{"sim-1", "sim-2", "sim-3"} {
+				if done[id] {
+					continue
+				}
+				resp.Simulations = append(resp.Simulations, saas.PendingSimulation{
+					SimulationID:  id,
+					PolicyContent: "apiVersion: cilium.io/v2\nkind: CiliumNetworkPolicy\nmetadata:\n  name: test\nspec:\n  endpointSelector: {}\n",
+					PolicyType:    "CILIUM_NETWORK",
+					StartTime:     time.Now().Add(-1 * time.Hour),
+					EndTime:       time.Now(),
+				})
+			}
+			json.NewEncoder(w).Encode(resp)
+		case strings.HasSuffix(r.URL.Path, "/progress"):
+			var progress saas.SimulationProgress
+			json.NewDecoder(r.Body).Decode(&progress)
+			resp := saas.ReportSimulationProgressResponse{Success: true}
+			if progress.Status == saas.SimulationStatusQueued {
+				queuedAt[progress.SimulationID] = progress.QueuePosition
+				if progress.SimulationID == "sim-2" {
+					done["sim-2"] = true
+					resp.Cancelled = true
+				}
+			}
+			json.NewEncoder(w).Encode(resp)
+		case r.URL.Path == "/api/operator/simulation/results":
+			var result saas.SimulationResult
+			json.NewDecoder(r.Body).Decode(&result)
+			results = append(results, result)
+			done[result.SimulationID] = true
+			json.NewEncoder(w).Encode(saas.SubmitSimulationResultResponse{Success: true, SimulationID: result.SimulationID})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := saas.NewClient(server.URL, "test-token", "test-cluster", logr.Discard())
+
+	worker := NewWorker(WorkerConfig{
+		Engine:        engine,
+		SaaSClient:    client,
+		PollInterval:  100 * time.Millisecond,
+		MaxConcurrent: 2,
+		QueueSize:     2,
+		Logger:        logr.Discard(),
+	})
+
+	ctx := context.Background()
+
+	// The queue only takes two of the three simulations
+	worker.processPendingSimulations(ctx)
+	drainQueue(ctx, worker)
+
+	mu.Lock()
+	if queuedAt["sim-1"] != 1 || queuedAt["sim-2"] != 2 || len(queuedAt) != 2 {
+		t.Errorf("queue positions = %v, want sim-1 at 1 and sim-2 at 2", queuedAt)
+	}
+	if len(results) != 1 || results[0].SimulationID != "sim-1" {
+		t.Errorf("results = %+v, want only sim-1", results)
+	}
+	mu.Unlock()
+
+	stats := worker.GetStats()
+	if stats.TotalProcessed != 1 || stats.TotalCancelled != 1 || stats.Queued != 0 || stats.Active != 0 {
+		t.Errorf("stats = %+v, want 1 processed, 1 cancelled, nothing queued or active", stats)
+	}
+
+	// The next poll picks up the simulation left pending
+	worker.processPendingSimulations(ctx)
+	drainQueue(ctx, worker)
+
+	mu.Lock()
+	if len(results) != 2 || results[1].SimulationID != "sim-3" {
+		t.Errorf("results = %+v, want sim-3 second", results)
+	}
+	mu.Unlock()
+}
+
+func TestWorker_QueuesWhileRunning(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "worker-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	mgr, err := storage.NewManager(storage.ManagerConfig{
+		BasePath: tmpDir,
+		NodeName: "test-node",
+		Logger:   logr.Discard(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create storage manager: %v", err)
+	}
+	defer mgr.Close()
+
+	// sim-2 arrives after sim-1 started. sim-1 does not complete, holding the
+	// only slot, until sim-2 has been reported queued.
+	var mu sync.Mutex
+	fetches := 0
+	done := make(map[string]bool)
+	sim2Queued := make(chan struct{})
+	results := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/operator/simulation/pending":
+			mu.Lock()
+			fetches++
+			ids := []string{"sim-1"}
+			if fetches > 1 {
+				ids = append(ids, "sim-2")
+			}
+			resp := saas.FetchPendingSimulationsResponse{Success: true}
+			for _, id := range ids {
+				if done[id] {
+					continue
+				}
+				resp.Simulations = append(resp.Simulations, saas.PendingSimulation{
+					SimulationID:  id,
+					PolicyContent: "apiVersion: cilium.io/v2\nkind: CiliumNetworkPolicy\nmetadata:\n  name: test\nspec:\n  endpointSelector: {}\n",
+					PolicyType:    "CILIUM_NETWORK",
+					StartTime:     time.Now().Add(-1 * time.Hour),
+					EndTime:       time.Now(),
+				})
+			}
+			mu.Unlock()
+			json.NewEncoder(w).Encode(resp)
+		case strings.HasSuffix(r.URL.Path, "/progress"):
+			var progress saas.SimulationProgress
+			json.NewDecoder(r.Body).Decode(&progress)
+			if progress.SimulationID == "sim-2" && progress.Status == saas.SimulationStatusQueued {
+				close(sim2Queued)
+			}
+			json.NewEncoder(w).Encode(saas.ReportSimulationProgressResponse{Success: true})
+		case r.URL.Path == "/api/operator/simulation/results":
+			var result saas.SimulationResult
+			json.NewDecoder(r.Body).Decode(&result)
+			if result.SimulationID == "sim-1" {
+				select {
+				case <-sim2Queued:
+				case <-time.After(5 * time.Second):
+					t.Error("sim-2 was not reported queued while sim-1 was running")
+				}
+			}
+			mu.Lock()
+			done[result.SimulationID] = true
+			mu.Unlock()
+			results <- result.SimulationID
+			json.NewEncoder(w).Encode(saas.SubmitSimulationResultResponse{Success: true, SimulationID: result.SimulationID})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	worker := NewWorker(WorkerConfig{
+		Engine:        NewEngine(EngineConfig{StorageManager: mgr, Logger: logr.Discard()}),
+		SaaSClient:    saas.NewClient(server.URL, "test-token", "test-cluster", logr.Discard()),
+		PollInterval:  20 * time.Millisecond,
+		MaxConcurrent: 1,
+		Logger:        logr.Discard(),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := worker.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer worker.Stop()
+
+	for _, want := range []string{"sim-1", "sim-2"} {
+		select {
+		case id := <-results:
+			if id != want {
+				t.Errorf("result for %s, want %s", id, want)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("Expected a result for %s", want)
+		}
+	}
+}
+
+func TestWorker_Cancel(t *testing.T) {
+	worker := NewWorker(WorkerConfig{Logger: logr.Discard()})
+
+	pending := []saas.PendingSimulation{{SimulationID: "sim-1"}, {SimulationID: "sim-2"}}
+	if added := worker.enqueue(context.Background(), pending); added != 2 {
+		t.Fatalf("enqueue() = %d, want 2", added)
+	}
+	// Simulations already queued are not queued again
+	if added := worker.enqueue(context.Background(), pending); added != 0 {
+		t.Errorf("enqueue() = %d, want 0 for known simulations", added)
+	}
+
+	if !worker.Cancel("sim-1") {
+		t.Error("Cancel(sim-1) = false, want true")
+	}
+	if worker.Cancel("unknown") {
+		t.Error("Cancel(unknown) = true, want false")
+	}
+
+	stats := worker.GetStats()
+	if stats.Queued != 1 || stats.TotalCancelled != 1 {
+		t.Errorf("stats = %+v, want 1 queued, 1 cancelled", stats)
+	}
+}
+
+func TestWorker_RunOnce_ConcurrencyLimit(t *testing.T) {
+	worker := NewWorker(WorkerConfig{MaxConcurrent: 1, Logger: logr.Discard()})
+
+	// Occupy the only slot
+	if err := worker.acquire(context.Background()); err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := worker.RunOnce(ctx, &SimulationRequest{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("RunOnce() error = %v, want context.DeadlineExceeded while the node is busy", err)
+	}
+}
+
+func TestWorker_FetchesOnEvent(t *testing.T) {
+	fetches := make(chan struct{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/events") {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Write([]byte("id: 1\nevent: simulations\ndata: {}\n\n"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+			return
+		}
+		fetches <- struct{}{}
+		json.NewEncoder(w).Encode(saas.FetchPendingSimulationsResponse{Success: true})
+	}))
+	defer server.Close()
+
+	events := saas.NewEventStream(logr.Discard())
+	worker := NewWorker(WorkerConfig{
+		SaaSClient:   saas.NewClient(server.URL, "test-token", "test-cluster", logr.Discard()),
+		PollInterval: time.Hour,
+		Events:       events,
+		Logger:       logr.Discard(),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := worker.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer worker.Stop()
+
+	waitForFetch := func(reason string) {
+		t.Helper()
+		select {
+		case <-fetches:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Expected a fetch %s", reason)
+		}
+	}
+	waitForFetch("on start")
+
+	events.SetClient(saas.NewClient(server.URL, "test-token", "test-cluster", logr.Discard()))
+	go events.Start(ctx)
+	waitForFetch("when notified of simulations")
+}
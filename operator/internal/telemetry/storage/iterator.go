@@ -0,0 +1,303 @@
+package storage
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+)
+
+// iterateBatchSize is the number of rows decoded at a time while iterating.
+const iterateBatchSize = 1000
+
+// EventVisitor receives the events of a streaming read one at a time.
+// Returning an error stops the read.
+type EventVisitor func(event *models.TelemetryEvent) error
+
+// IterateOptions controls a streaming read across files.
+type IterateOptions struct {
+	// Parallelism is the number of files read concurrently (default: 1)
+	Parallelism int
+	// Progress is called each time a file has been read (optional). Calls
+	// are serialized.
+	Progress func(IterateProgress)
+}
+
+// IterateProgress reports the progress of a streaming read.
+type IterateProgress struct {
+	// File is the index of the file that was just read
+	File       int
+	FilesDone  int
+	FilesTotal int
+	// EventsRead is the number of matching events read so far
+	EventsRead int64
+}
+
+// ListFiles returns the Parquet files in the date directories covering the
+// query's time range, in date and name order. Files that are still being
+// written are skipped.
+func (pr *ParquetReader) ListFiles(req models.QueryEventsRequest) ([]string, error) {
+	var skipFiles []string
+	if pr.skipFilesFunc != nil {
+		skipFiles = pr.skipFilesFunc()
+	}
+	skipFilesMap := make(map[string]bool, len(skipFiles))
+	for _, sf := range skipFiles {
+		skipFilesMap[sf] = true
+	}
+
+	var files []string
+	for _, dateStr := range dateRange(req.StartTime, req.EndTime) {
+		dateDir := filepath.Join(pr.basePath, dateStr)
+		entries, err := os.ReadDir(dateDir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory %s: %w", dateDir, err)
+		}
+
+		var dateFiles []string
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != ".parquet" {
+				continue
+			}
+			filePath := filepath.Join(dateDir, entry.Name())
+			if skipFilesMap[filePath] {
+				pr.log.V(1).Info("ListFiles: skipping file being written", "path", filePath)
+				continue
+			}
+			dateFiles = append(dateFiles, filePath)
+		}
+		sort.Strings(dateFiles)
+		files = append(files, dateFiles...)
+	}
+
+	return files, nil
+}
+
+// dateRange returns the dates from start to end, capped at 100 days.
+func dateRange(start, end time.Time) []string {
+	var dates []string
+	current := start.UTC().Truncate(24 * time.Hour)
+	for !current.After(end.UTC()) && len(dates) < 100 {
+		dates = append(dates, current.Format("2006-01-02"))
+		current = current.Add(24 * time.Hour)
+	}
+	return dates
+}
+
+// IterateFile reads a Parquet file one row group at a time and passes the
+// events matching the query to visit. Row groups whose timestamp statistics
+// lie outside the query's time range are skipped without being decoded, and
+// at most one batch of rows is held in memory regardless of the file size.
+func (pr *ParquetReader) IterateFile(ctx context.Context, filePath string, req models.QueryEventsRequest, visit EventVisitor) error {
+	_, err := pr.scanParquetFile(ctx, filePath, req, 0, visit)
+	return err
+}
+
+// scanParquetFile iterates a Parquet file, stopping after maxRows rows when
+// maxRows is positive. It returns the number of rows read.
+func (pr *ParquetReader) scanParquetFile(ctx context.Context, filePath string, req models.QueryEventsRequest, maxRows int64, visit EventVisitor) (int64, error) {
+	fr, err := local.NewLocalFileReader(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer fr.Close()
+
+	pqReader, err := reader.NewParquetReader(fr, new(ParquetEvent), int64(4))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create reader: %w", err)
+	}
+	defer pqReader.ReadStop()
+
+	startMicros := req.StartTime.UnixMicro()
+	endMicros := req.EndTime.UnixMicro()
+
+	var rowsRead int64
+	for _, rowGroup := range pqReader.Footer.RowGroups {
+		if maxRows > 0 && rowsRead >= maxRows {
+			break
+		}
+		numRows := rowGroup.NumRows
+
+		if minTS, maxTS, ok := rowGroupTimeRange(rowGroup); ok && (maxTS < startMicros || minTS > endMicros) {
+			if err := pqReader.SkipRows(numRows); err != nil {
+				return rowsRead, fmt.Errorf("failed to skip row group: %w", err)
+			}
+			continue
+		}
+
+		for done := int64(0); done < numRows; {
+			if err := ctx.Err(); err != nil {
+				return rowsRead, err
+			}
+
+			toRead := min(int64(iterateBatchSize), numRows-done)
+			if maxRows > 0 {
+				toRead = min(toRead, maxRows-rowsRead)
+			}
+			if toRead <= 0 {
+				break
+			}
+
+			pqEvents := make([]ParquetEvent, toRead)
+			if err := pqReader.Read(&pqEvents); err != nil {
+				return rowsRead, fmt.Errorf("failed to read events: %w", err)
+			}
+			done += toRead
+			rowsRead += toRead
+
+			for i := range pqEvents {
+				event := convertFromParquetEvent(&pqEvents[i])
+				if !pr.matchesFilters(event, req) {
+					continue
+				}
+				if err := visit(event); err != nil {
+					return rowsRead, err
+				}
+			}
+		}
+	}
+
+	return rowsRead, nil
+}
+
+// rowGroupTimeRange returns the minimum and maximum timestamps of a row group
+// from its column statistics, if it has them.
+func rowGroupTimeRange(rowGroup *parquet.RowGroup) (int64, int64, bool) {
+	for _, column := range rowGroup.Columns {
+		if column.MetaData == nil || len(column.MetaData.PathInSchema) == 0 {
+			continue
+		}
+		path := column.MetaData.PathInSchema
+		if !strings.EqualFold(path[len(path)-1], "timestamp") {
+			continue
+		}
+		stats := column.MetaData.Statistics
+		if stats == nil || len(stats.MinValue) != 8 || len(stats.MaxValue) != 8 {
+			return 0, 0, false
+		}
+		minTS := int64(binary.LittleEndian.Uint64(stats.MinValue))
+		maxTS := int64(binary.LittleEndian.Uint64(stats.MaxValue))
+		return minTS, maxTS, true
+	}
+	return 0, 0, false
+}
+
+// visitError carries an error returned by a visitor through IterateFile, to
+// tell it apart from read errors.
+type visitError struct {
+	err error
+}
+
+func (e *visitError) Error() string { return e.err.Error() }
+
+// ListFiles returns the Parquet files that may hold events of the query.
+func (m *Manager) ListFiles(req models.QueryEventsRequest) ([]string, error) {
+	return m.reader.ListFiles(req)
+}
+
+// IterateFiles streams the events of the given files that match the query
+// without loading them into memory. visit receives the index of the file an
+// event was read from: calls for the same file are sequential, calls for
+// different files run concurrently when Parallelism is above one. Files that
+// cannot be read are logged and skipped, like in Query. The read stops at the
+// first error returned by visit or when the context is cancelled.
+func (m *Manager) IterateFiles(ctx context.Context, files []string, req models.QueryEventsRequest, opts IterateOptions, visit func(file int, event *models.TelemetryEvent) error) error {
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu         sync.Mutex
+		firstErr   error
+		filesDone  int
+		eventsRead int64
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				var read int64
+				err := m.reader.IterateFile(ctx, files[i], req, func(event *models.TelemetryEvent) error {
+					read++
+					if err := visit(i, event); err != nil {
+						return &visitError{err: err}
+					}
+					return nil
+				})
+				var vErr *visitError
+				switch {
+				case err == nil:
+				case errors.As(err, &vErr):
+					fail(vErr.err)
+					return
+				case ctx.Err() != nil:
+					fail(ctx.Err())
+					return
+				default:
+					m.log.Error(err, "Error reading Parquet file", "path", files[i])
+				}
+
+				mu.Lock()
+				filesDone++
+				eventsRead += read
+				if opts.Progress != nil && firstErr == nil {
+					opts.Progress(IterateProgress{
+						File:       i,
+						FilesDone:  filesDone,
+						FilesTotal: len(files),
+						EventsRead: eventsRead,
+					})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for i := range files {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	// Cancellation of the parent context while feeding
+	return ctx.Err()
+}
@@ -0,0 +1,271 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/reader"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+)
+
+// writeTestFiles writes one Parquet file per batch of events into the
+// Parquet directory of a new storage base path, which it returns.
+func writeTestFiles(t *testing.T, batches ...[]*models.TelemetryEvent) string {
+	t.Helper()
+
+	tmpDir, err := os.MkdirTemp("", "iterator-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tmpDir) })
+
+	for i, events := range batches {
+		pw, err := NewParquetWriter(ParquetWriterConfig{
+			BasePath: filepath.Join(tmpDir, "parquet"),
+			NodeName: fmt.Sprintf("node-%d", i),
+			Logger:   logr.Discard(),
+		})
+		if err != nil {
+			t.Fatalf("NewParquetWriter() error = %v", err)
+		}
+		if err := pw.Write(events); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		pw.Close()
+	}
+	return tmpDir
+}
+
+func TestParquetReader_ListFiles(t *testing.T) {
+	tmpDir := writeTestFiles(t, createTestEvents(2), createTestEvents(3))
+	pr := NewParquetReader(filepath.Join(tmpDir, "parquet"), logr.Discard())
+
+	req := models.QueryEventsRequest{
+		StartTime: time.Now().Add(-1 * time.Hour),
+		EndTime:   time.Now().Add(1 * time.Hour),
+	}
+	files, err := pr.ListFiles(req)
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("ListFiles() returned %d files, want 2", len(files))
+	}
+	if files[0] > files[1] {
+		t.Errorf("ListFiles() not sorted: %v", files)
+	}
+
+	pr.SetSkipFilesFunc(func() []string { return files[:1] })
+	skipped, err := pr.ListFiles(req)
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	if len(skipped) != 1 || skipped[0] != files[1] {
+		t.Errorf("ListFiles() = %v, want only %s", skipped, files[1])
+	}
+
+	// Date directories outside the range are not listed
+	old, err := pr.ListFiles(models.QueryEventsRequest{
+		StartTime: time.Now().Add(-72 * time.Hour),
+		EndTime:   time.Now().Add(-48 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	if len(old) != 0 {
+		t.Errorf("ListFiles() returned %d files for an old range, want 0", len(old))
+	}
+}
+
+func TestParquetReader_IterateFile(t *testing.T) {
+	events := createTestEvents(2500)
+	tmpDir := writeTestFiles(t, events)
+	pr := NewParquetReader(filepath.Join(tmpDir, "parquet"), logr.Discard())
+
+	req := models.QueryEventsRequest{
+		StartTime: time.Now().Add(-1 * time.Hour),
+		EndTime:   time.Now().Add(1 * time.Hour),
+	}
+	files, err := pr.ListFiles(req)
+	if err != nil || len(files) != 1 {
+		t.Fatalf("ListFiles() = %v, %v, want one file", files, err)
+	}
+
+	tests := []struct {
+		name      string
+		req       models.QueryEventsRequest
+		wantCount int
+	}{
+		{name: "all events", req: req, wantCount: 2500},
+		{
+			name: "namespace filter",
+			req: models.QueryEventsRequest{
+				StartTime:  req.StartTime,
+				EndTime:    req.EndTime,
+				Namespaces: []string{"other"},
+			},
+			wantCount: 0,
+		},
+		{
+			name: "outside time range",
+			req: models.QueryEventsRequest{
+				StartTime: time.Now().Add(-2 * time.Hour),
+				EndTime:   time.Now().Add(-1 * time.Hour),
+			},
+			wantCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count := 0
+			err := pr.IterateFile(context.Background(), files[0], tt.req, func(event *models.TelemetryEvent) error {
+				if count == 0 && event.ID != "event-0" {
+					t.Errorf("first event ID = %s, want event-0", event.ID)
+				}
+				count++
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("IterateFile() error = %v", err)
+			}
+			if count != tt.wantCount {
+				t.Errorf("IterateFile() visited %d events, want %d", count, tt.wantCount)
+			}
+		})
+	}
+
+	t.Run("visitor error stops the read", func(t *testing.T) {
+		stop := errors.New("stop")
+		count := 0
+		err := pr.IterateFile(context.Background(), files[0], req, func(event *models.TelemetryEvent) error {
+			count++
+			if count == 10 {
+				return stop
+			}
+			return nil
+		})
+		if !errors.Is(err, stop) {
+			t.Errorf("IterateFile() error = %v, want %v", err, stop)
+		}
+		if count != 10 {
+			t.Errorf("IterateFile() visited %d events after the error, want 10", count)
+		}
+	})
+}
+
+func TestRowGroupTimeRange(t *testing.T) {
+	events := createTestEvents(5)
+	tmpDir := writeTestFiles(t, events)
+	pr := NewParquetReader(filepath.Join(tmpDir, "parquet"), logr.Discard())
+
+	files, err := pr.ListFiles(models.QueryEventsRequest{StartTime: time.Now(), EndTime: time.Now()})
+	if err != nil || len(files) != 1 {
+		t.Fatalf("ListFiles() = %v, %v, want one file", files, err)
+	}
+
+	fr, err := local.NewLocalFileReader(files[0])
+	if err != nil {
+		t.Fatalf("NewLocalFileReader() error = %v", err)
+	}
+	defer fr.Close()
+	pqReader, err := reader.NewParquetReader(fr, new(ParquetEvent), 1)
+	if err != nil {
+		t.Fatalf("NewParquetReader() error = %v", err)
+	}
+	defer pqReader.ReadStop()
+
+	minTS, maxTS, ok := rowGroupTimeRange(pqReader.Footer.RowGroups[0])
+	if !ok {
+		t.Fatal("rowGroupTimeRange() found no timestamp statistics")
+	}
+	if minTS != events[0].Timestamp.UnixMicro() || maxTS != events[4].Timestamp.UnixMicro() {
+		t.Errorf("rowGroupTimeRange() = %d, %d, want %d, %d",
+			minTS, maxTS, events[0].Timestamp.UnixMicro(), events[4].Timestamp.UnixMicro())
+	}
+}
+
+func TestManager_IterateFiles(t *testing.T) {
+	tmpDir := writeTestFiles(t, createTestEvents(3), createTestEvents(4), createTestEvents(5))
+	mgr, err := NewManager(ManagerConfig{
+		BasePath: tmpDir,
+		NodeName: "test-node",
+		Logger:   logr.Discard(),
+	})
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	defer mgr.Close()
+
+	req := models.QueryEventsRequest{
+		StartTime: time.Now().Add(-1 * time.Hour),
+		EndTime:   time.Now().Add(1 * time.Hour),
+	}
+	files, err := mgr.ListFiles(req)
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("ListFiles() returned %d files, want 3", len(files))
+	}
+
+	t.Run("parallel read with progress", func(t *testing.T) {
+		var mu sync.Mutex
+		perFile := make(map[int]int)
+		var progress []IterateProgress
+
+		err := mgr.IterateFiles(context.Background(), files, req, IterateOptions{
+			Parallelism: 2,
+			Progress:    func(p IterateProgress) { progress = append(progress, p) },
+		}, func(file int, event *models.TelemetryEvent) error {
+			mu.Lock()
+			defer mu.Unlock()
+			perFile[file]++
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("IterateFiles() error = %v", err)
+		}
+
+		if perFile[0]+perFile[1]+perFile[2] != 12 {
+			t.Errorf("IterateFiles() visited %v, want 12 events in total", perFile)
+		}
+		if len(progress) != 3 {
+			t.Fatalf("Progress called %d times, want 3", len(progress))
+		}
+		last := progress[len(progress)-1]
+		if last.FilesDone != 3 || last.FilesTotal != 3 || last.EventsRead != 12 {
+			t.Errorf("last progress = %+v, want 3/3 files and 12 events", last)
+		}
+	})
+
+	t.Run("visitor error", func(t *testing.T) {
+		stop := errors.New("stop")
+		err := mgr.IterateFiles(context.Background(), files, req, IterateOptions{Parallelism: 2}, func(file int, event *models.TelemetryEvent) error {
+			return stop
+		})
+		if !errors.Is(err, stop) {
+			t.Errorf("IterateFiles() error = %v, want %v", err, stop)
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := mgr.IterateFiles(ctx, files, req, IterateOptions{}, func(file int, event *models.TelemetryEvent) error {
+			return nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("IterateFiles() error = %v, want context.Canceled", err)
+		}
+	})
+}
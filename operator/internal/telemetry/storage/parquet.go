@@ -13,7 +13,6 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/xitongsys/parquet-go-source/local"
 	"github.com/xitongsys/parquet-go/parquet"
-	"github.com/xitongsys/parquet-go/reader"
 	"github.com/xitongsys/parquet-go/writer"
 
 	"github.com/policy-hub/operator/internal/telemetry/models"
@@ -472,6 +471,9 @@ func jsonEncode(v interface{}) string {
 	return string(data)
 }
 
+// maxRowsPerFile caps the rows ReadEvents loads from a single file.
+const maxRowsPerFile = 100000
+
 // ParquetReader reads telemetry events from Parquet files.
 type ParquetReader struct {
 	basePath       string
@@ -494,46 +496,42 @@ func (pr *ParquetReader) SetSkipFilesFunc(fn func() []string) {
 }
 
 // ReadEvents reads events from Parquet files within the given time range.
+// Matching events are loaded into memory; use IterateFile to stream them.
 func (pr *ParquetReader) ReadEvents(ctx context.Context, req models.QueryEventsRequest) (*models.QueryEventsResponse, error) {
-	var allEvents []*models.TelemetryEvent
-
-	// Calculate date range
-	startDate := req.StartTime.UTC().Format("2006-01-02")
-	endDate := req.EndTime.UTC().Format("2006-01-02")
+	files, err := pr.ListFiles(req)
+	if err != nil {
+		return nil, err
+	}
 
 	pr.log.Info("ReadEvents: starting",
-		"startDate", startDate,
-		"endDate", endDate,
-		"basePath", pr.basePath,
+		"startTime", req.StartTime,
+		"endTime", req.EndTime,
+		"files", len(files),
 	)
 
-	// Iterate through date directories
-	current := req.StartTime.UTC()
-	iterations := 0
-	for !current.After(req.EndTime.UTC()) {
-		iterations++
-		if iterations > 100 {
-			pr.log.Error(nil, "ReadEvents: too many iterations, breaking loop")
+	var allEvents []*models.TelemetryEvent
+	for _, filePath := range files {
+		if err := ctx.Err(); err != nil {
 			break
 		}
 
-		dateStr := current.Format("2006-01-02")
-		if dateStr >= startDate && dateStr <= endDate {
-			dateDir := filepath.Join(pr.basePath, dateStr)
-			pr.log.Info("ReadEvents: processing date directory", "date", dateStr, "dir", dateDir)
-
-			events, err := pr.readDateDirectory(ctx, dateDir, req)
-			if err != nil {
-				pr.log.Error(err, "Error reading date directory", "date", dateStr)
-			} else {
-				pr.log.Info("ReadEvents: got events from directory", "date", dateStr, "count", len(events))
-				allEvents = append(allEvents, events...)
-			}
+		// Limit max rows per file to prevent memory issues with large files
+		var fileEvents []*models.TelemetryEvent
+		rows, err := pr.scanParquetFile(ctx, filePath, req, maxRowsPerFile, func(event *models.TelemetryEvent) error {
+			fileEvents = append(fileEvents, event)
+			return nil
+		})
+		if err != nil {
+			pr.log.Error(err, "Error reading Parquet file", "path", filePath)
+			continue
+		}
+		if rows >= maxRowsPerFile {
+			pr.log.Info("ReadEvents: limited rows read from file", "path", filePath, "limit", maxRowsPerFile)
 		}
-		current = current.Add(24 * time.Hour)
+		allEvents = append(allEvents, fileEvents...)
 	}
 
-	pr.log.Info("ReadEvents: finished iterating directories", "iterations", iterations, "totalEvents", len(allEvents))
+	pr.log.Info("ReadEvents: finished reading files", "totalEvents", len(allEvents))
 
 	// Apply limit and offset
 	totalCount := int64(len(allEvents))
@@ -561,129 +559,6 @@ func (pr *ParquetReader) ReadEvents(ctx context.Context, req models.QueryEventsR
 	}, nil
 }
 
-// readDateDirectory reads all Parquet files in a date directory.
-func (pr *ParquetReader) readDateDirectory(ctx context.Context, dateDir string, req models.QueryEventsRequest) ([]*models.TelemetryEvent, error) {
-	pr.log.Info("readDateDirectory: starting", "dir", dateDir)
-
-	entries, err := os.ReadDir(dateDir)
-	if os.IsNotExist(err) {
-		pr.log.Info("readDateDirectory: directory does not exist", "dir", dateDir)
-		return nil, nil
-	}
-	if err != nil {
-		return nil, fmt.Errorf("failed to read directory: %w", err)
-	}
-
-	pr.log.Info("readDateDirectory: found entries", "count", len(entries))
-
-	// Get files to skip (currently being written)
-	var skipFiles []string
-	if pr.skipFilesFunc != nil {
-		skipFiles = pr.skipFilesFunc()
-	}
-	skipFilesMap := make(map[string]bool)
-	for _, sf := range skipFiles {
-		skipFilesMap[sf] = true
-	}
-
-	var events []*models.TelemetryEvent
-	for i, entry := range entries {
-		pr.log.V(1).Info("readDateDirectory: processing entry", "index", i, "name", entry.Name())
-
-		select {
-		case <-ctx.Done():
-			return events, ctx.Err()
-		default:
-		}
-
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".parquet" {
-			continue
-		}
-
-		filePath := filepath.Join(dateDir, entry.Name())
-
-		// Skip files that are currently being written
-		if skipFilesMap[filePath] {
-			pr.log.Info("readDateDirectory: skipping file being written", "path", filePath)
-			continue
-		}
-
-		fileEvents, err := pr.readParquetFile(ctx, filePath, req)
-		if err != nil {
-			pr.log.Error(err, "Error reading Parquet file", "path", filePath)
-			continue
-		}
-		events = append(events, fileEvents...)
-	}
-
-	return events, nil
-}
-
-// readParquetFile reads events from a single Parquet file.
-func (pr *ParquetReader) readParquetFile(ctx context.Context, filePath string, req models.QueryEventsRequest) ([]*models.TelemetryEvent, error) {
-	pr.log.Info("readParquetFile: opening file", "path", filePath)
-
-	fr, err := local.NewLocalFileReader(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
-	}
-	defer fr.Close()
-
-	pr.log.Info("readParquetFile: creating parquet reader")
-
-	pqReader, err := reader.NewParquetReader(fr, new(ParquetEvent), int64(4))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create reader: %w", err)
-	}
-	defer pqReader.ReadStop()
-
-	numRows := int(pqReader.GetNumRows())
-	pr.log.Info("readParquetFile: file info", "numRows", numRows)
-
-	// Limit max rows to prevent memory issues with large files
-	maxRows := 100000
-	if numRows > maxRows {
-		pr.log.Info("readParquetFile: limiting rows", "original", numRows, "limited", maxRows)
-		numRows = maxRows
-	}
-
-	var events []*models.TelemetryEvent
-
-	// Read in batches
-	batchSize := 1000
-	for i := 0; i < numRows; i += batchSize {
-		select {
-		case <-ctx.Done():
-			return events, ctx.Err()
-		default:
-		}
-
-		toRead := batchSize
-		if i+toRead > numRows {
-			toRead = numRows - i
-		}
-
-		pqEvents := make([]ParquetEvent, toRead)
-		if err := pqReader.Read(&pqEvents); err != nil {
-			return nil, fmt.Errorf("failed to read events: %w", err)
-		}
-
-		for _, pqEvent := range pqEvents {
-			event := convertFromParquetEvent(&pqEvent)
-
-			// Apply filters
-			if !pr.matchesFilters(event, req) {
-				continue
-			}
-
-			events = append(events, event)
-		}
-	}
-
-	pr.log.Info("readParquetFile: complete", "matchedEvents", len(events))
-	return events, nil
-}
-
 // matchesFilters checks if an event matches the query filters.
 func (pr *ParquetReader) matchesFilters(event *models.TelemetryEvent, req models.QueryEventsRequest) bool {
 	// Time range filter
@@ -8,6 +8,7 @@ import (
 	"github.com/go-logr/logr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/policy-hub/operator/internal/saas"
 	"github.com/policy-hub/operator/internal/telemetry/models"
 )
 
@@ -32,6 +33,13 @@ type Agent struct {
 	totalAllowed    int64
 	totalBlocked    int64
 	totalNoPolicy   int64
+
+	totalAudited    int64
+
+	// Blocked and audited flows per namespace, for rollout regression checks
+	// and audit mode reports
+	blocked *flowCounter
+	audited *flowCounter
 }
 
 // AgentOptions contains options for creating an agent
@@ -45,6 +53,8 @@ type AgentOptions struct {
 	EventBufferSize int
 	EventSampleRate int
 	Logger          logr.Logger
+	// SaaSClient, when set, submits validation data through its outbox
+	SaaSClient *saas.Client
 }
 
 // NewAgent creates a new validation agent
@@ -71,6 +81,7 @@ func NewAgent(opts AgentOptions) *Agent {
 		MaxEvents:  100,
 		SampleRate: opts.EventSampleRate,
 		Logger:     opts.Logger,
+		SaaSClient: opts.SaaSClient,
 	})
 
 	return &Agent{
@@ -82,6 +93,8 @@ func NewAgent(opts AgentOptions) *Agent {
 		policyRefresh:    opts.PolicyRefresh,
 		eventsCh:         make(chan *models.TelemetryEvent, opts.EventBufferSize),
 		stopCh:           make(chan struct{}),
+		blocked:          newFlowCounter(),
+		audited:          newFlowCounter(),
 	}
 }
 
@@ -184,7 +197,21 @@ func (a *Agent) validateEvent(event *models.TelemetryEvent) {
 		}
 	}
 
+	// Hubble reports flows a policy in audit mode would have dropped as AUDIT
+	if event.Verdict == models.VerdictAudit {
+		result.Verdict = VerdictAudited
+		result.Reason = "Would be dropped by Cilium policy in audit mode (Hubble verdict)"
+	}
+
 	a.reporter.Record(result)
+	// Rollouts are rolled back on what Cilium did, so only flows Hubble
+	// reports dropped count as blocked, not flows the matcher predicts blocked
+	switch event.Verdict {
+	case models.VerdictDropped:
+		a.blocked.record(time.Now(), event.SrcNamespace, event.DstNamespace)
+	case models.VerdictAudit:
+		a.audited.record(time.Now(), event.SrcNamespace, event.DstNamespace)
+	}
 
 	// Update stats
 	a.mu.Lock()
@@ -196,6 +223,8 @@ func (a *Agent) validateEvent(event *models.TelemetryEvent) {
 		a.totalBlocked++
 	case VerdictNoPolicy:
 		a.totalNoPolicy++
+	case VerdictAudited:
+		a.totalAudited++
 	}
 	a.mu.Unlock()
 }
@@ -277,6 +306,7 @@ type AgentStats struct {
 	TotalAllowed   int64
 	TotalBlocked   int64
 	TotalNoPolicy  int64
+	TotalAudited   int64
 	ReportsSent    int64
 	ReportsFailed  int64
 }
@@ -289,6 +319,7 @@ func (a *Agent) GetStats() AgentStats {
 		TotalAllowed:   a.totalAllowed,
 		TotalBlocked:   a.totalBlocked,
 		TotalNoPolicy:  a.totalNoPolicy,
+		TotalAudited:   a.totalAudited,
 	}
 	a.mu.Unlock()
 
@@ -303,6 +334,19 @@ func (a *Agent) GetStats() AgentStats {
 	return stats
 }
 
+// BlockedFlows returns the number of flows from or to the namespaces that
+// Hubble reported dropped between since and until, at one-minute resolution.
+// Flows between two of the namespaces count once for each.
+func (a *Agent) BlockedFlows(namespaces []string, since, until time.Time) int64 {
+	return a.blocked.count(namespaces, since, until)
+}
+
+// AuditedFlows returns the number of flows from or to the namespaces that
+// policies in audit mode would have dropped between since and until
+func (a *Agent) AuditedFlows(namespaces []string, since, until time.Time) int64 {
+	return a.audited.count(namespaces, since, until)
+}
+
 // IsRunning returns whether the agent is running
 func (a *Agent) IsRunning() bool {
 	a.runningMu.Lock()
@@ -0,0 +1,57 @@
+package validation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+)
+
+func TestAgent_BlockedFlowsCountsHubbleDrops(t *testing.T) {
+	a := NewAgent(AgentOptions{Logger: logr.Discard()})
+	// Web may only reach db, so the matcher predicts flows to cache blocked
+	a.matcher = newTestMatcher(t, `apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: web-egress
+  namespace: shop
+spec:
+  endpointSelector:
+    matchLabels:
+      app: web
+  egress:
+    - toEndpoints:
+        - matchLabels:
+            app: db
+`)
+	toCache := func(verdict models.Verdict) *models.TelemetryEvent {
+		return &models.TelemetryEvent{
+			Timestamp:    time.Now(),
+			EventType:    models.EventTypeFlow,
+			SrcNamespace: "shop", SrcPodLabels: map[string]string{"app": "web"},
+			DstNamespace: "shop", DstPodLabels: map[string]string{"app": "cache"},
+			DstPort: 6379, Protocol: "TCP",
+			Verdict: verdict,
+		}
+	}
+	count := func() int64 {
+		now := time.Now()
+		return a.BlockedFlows([]string{"shop"}, now.Add(-time.Minute), now)
+	}
+
+	forwarded := toCache(models.VerdictAllowed) // Hubble FORWARDED
+	if result := a.matcher.Match(forwarded); result.Verdict != VerdictBlocked {
+		t.Fatalf("Expected the matcher to predict the flow blocked, got %v", result.Verdict)
+	}
+	a.validateEvent(forwarded)
+	if got := count(); got != 0 {
+		t.Errorf("Expected a forwarded flow not to count as blocked, got %d", got)
+	}
+
+	a.validateEvent(toCache(models.VerdictDropped))
+	if got := count(); got != 1 {
+		t.Errorf("Expected the dropped flow to count as blocked, got %d", got)
+	}
+}
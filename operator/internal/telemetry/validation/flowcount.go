@@ -0,0 +1,72 @@
+package validation
+
+import (
+	"sync"
+	"time"
+)
+
+// flowCountRetention is how long flow counts are kept. It bounds the
+// windows BlockedFlows and AuditedFlows can answer for.
+const flowCountRetention = 3 * time.Hour
+
+// flowCounter counts flows per namespace in one-minute buckets
+type flowCounter struct {
+	mu      sync.Mutex
+	buckets map[int64]map[string]int64 // unix minute -> namespace -> count
+}
+
+func newFlowCounter() *flowCounter {
+	return &flowCounter{buckets: make(map[int64]map[string]int64)}
+}
+
+// record counts a flow once for each distinct namespace
+func (c *flowCounter) record(ts time.Time, namespaces ...string) {
+	minute := ts.Unix() / 60
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	bucket, ok := c.buckets[minute]
+	if !ok {
+		bucket = make(map[string]int64)
+		c.buckets[minute] = bucket
+		c.prune(ts)
+	}
+	seen := make(map[string]bool, len(namespaces))
+	for _, ns := range namespaces {
+		if ns == "" || seen[ns] {
+			continue
+		}
+		seen[ns] = true
+		bucket[ns]++
+	}
+}
+
+// prune drops buckets older than the retention. Callers hold c.mu.
+func (c *flowCounter) prune(now time.Time) {
+	oldest := now.Add(-flowCountRetention).Unix() / 60
+	for minute := range c.buckets {
+		if minute < oldest {
+			delete(c.buckets, minute)
+		}
+	}
+}
+
+// count sums the flows of the namespaces in the minutes from since to until
+func (c *flowCounter) count(namespaces []string, since, until time.Time) int64 {
+	from, to := since.Unix()/60, until.Unix()/60
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var total int64
+	for minute, bucket := range c.buckets {
+		if minute < from || minute > to {
+			continue
+		}
+		for _, ns := range namespaces {
+			total += bucket[ns]
+		}
+	}
+	return total
+}
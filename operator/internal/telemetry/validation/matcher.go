@@ -2,7 +2,7 @@ package validation
 
 import (
 	"context"
-	"strings"
+	"fmt"
 	"sync"
 
 	"github.com/go-logr/logr"
@@ -47,11 +47,44 @@ func NewPolicyMatcher(c client.Client, log logr.Logger) *PolicyMatcher {
 func (m *PolicyMatcher) RefreshPolicies(ctx context.Context) error {
 	m.log.V(1).Info("Refreshing policies from cluster")
 
+	docs, err := m.CurrentPolicies(ctx)
+	if err != nil {
+		return err
+	}
+
+	var parsedPolicies []*ParsedPolicyCache
+
+	// Parse policies using the simulation parser
+	for _, doc := range docs {
+		parsed, err := m.parser.Parse(doc.Content, doc.PolicyType)
+		if err != nil {
+			m.log.V(1).Info("Failed to parse policy", "name", doc.Name, "namespace", doc.Namespace, "error", err)
+			continue
+		}
+		parsedPolicies = append(parsedPolicies, &ParsedPolicyCache{
+			Name:      doc.Name,
+			Namespace: doc.Namespace,
+			Parsed:    parsed,
+		})
+	}
+
+	m.policiesMu.Lock()
+	m.policies = parsedPolicies
+	m.policiesMu.Unlock()
+
+	m.log.Info("Policies refreshed", "count", len(parsedPolicies))
+
+	return nil
+}
+
+// CurrentPolicies lists the CiliumNetworkPolicies and CiliumClusterwideNetworkPolicies
+// deployed in the cluster as YAML documents. It implements simulation.PolicySetSource.
+func (m *PolicyMatcher) CurrentPolicies(ctx context.Context) ([]simulation.PolicyDocument, error) {
 	// Fetch CiliumNetworkPolicies
 	var cnpList ciliumv2.CiliumNetworkPolicyList
 	if err := m.client.List(ctx, &cnpList); err != nil {
 		m.log.Error(err, "Failed to list CiliumNetworkPolicies")
-		return err
+		return nil, err
 	}
 
 	// Fetch CiliumClusterwideNetworkPolicies
@@ -61,72 +94,49 @@ func (m *PolicyMatcher) RefreshPolicies(ctx context.Context) error {
 		// Continue without clusterwide policies
 	}
 
-	var parsedPolicies []*ParsedPolicyCache
+	var docs []simulation.PolicyDocument
 
-	// Parse CNPs by converting to YAML and using the simulation parser
+	// Convert CNPs to YAML so the simulation parser handles all the complexity
 	for i := range cnpList.Items {
 		cnp := &cnpList.Items[i]
-		parsed, err := m.parseCNP(cnp)
+		yamlBytes, err := yaml.Marshal(cnp)
 		if err != nil {
-			m.log.V(1).Info("Failed to parse CNP", "name", cnp.Name, "namespace", cnp.Namespace, "error", err)
+			m.log.V(1).Info("Failed to marshal CNP", "name", cnp.Name, "namespace", cnp.Namespace, "error", err)
 			continue
 		}
-		parsedPolicies = append(parsedPolicies, &ParsedPolicyCache{
-			Name:      cnp.Name,
-			Namespace: cnp.Namespace,
-			Parsed:    parsed,
+		docs = append(docs, simulation.PolicyDocument{
+			Name:       cnp.Name,
+			Namespace:  cnp.Namespace,
+			PolicyType: "CILIUM_NETWORK",
+			Content:    string(yamlBytes),
 		})
 	}
 
-	// Parse CCNPs
+	// Convert CCNPs
 	for i := range ccnpList.Items {
 		ccnp := &ccnpList.Items[i]
-		parsed, err := m.parseCCNP(ccnp)
+		yamlBytes, err := yaml.Marshal(ccnp)
 		if err != nil {
-			m.log.V(1).Info("Failed to parse CCNP", "name", ccnp.Name, "error", err)
+			m.log.V(1).Info("Failed to marshal CCNP", "name", ccnp.Name, "error", err)
 			continue
 		}
-		parsedPolicies = append(parsedPolicies, &ParsedPolicyCache{
-			Name:      ccnp.Name,
-			Namespace: "", // Clusterwide
-			Parsed:    parsed,
+		docs = append(docs, simulation.PolicyDocument{
+			Name:       ccnp.Name,
+			Namespace:  "", // Clusterwide
+			PolicyType: "CILIUM_CLUSTERWIDE",
+			Content:    string(yamlBytes),
 		})
 	}
 
-	m.policiesMu.Lock()
-	m.policies = parsedPolicies
-	m.policiesMu.Unlock()
-
-	m.log.Info("Policies refreshed", "count", len(parsedPolicies))
-
-	return nil
-}
-
-// parseCNP parses a CiliumNetworkPolicy by converting it to YAML
-func (m *PolicyMatcher) parseCNP(cnp *ciliumv2.CiliumNetworkPolicy) (*simulation.ParsedPolicy, error) {
-	// Convert to YAML
-	yamlBytes, err := yaml.Marshal(cnp)
-	if err != nil {
-		return nil, err
-	}
-
-	// Use the simulation parser which handles all the complexity
-	return m.parser.Parse(string(yamlBytes), "CILIUM_NETWORK")
+	return docs, nil
 }
 
-// parseCCNP parses a CiliumClusterwideNetworkPolicy
-func (m *PolicyMatcher) parseCCNP(ccnp *ciliumv2.CiliumClusterwideNetworkPolicy) (*simulation.ParsedPolicy, error) {
-	// Convert to YAML
-	yamlBytes, err := yaml.Marshal(ccnp)
-	if err != nil {
-		return nil, err
-	}
-
-	// Use the simulation parser
-	return m.parser.Parse(string(yamlBytes), "CILIUM_CLUSTERWIDE")
-}
-
-// Match evaluates a flow against all policies and returns a validation result
+// Match evaluates a flow against all policies and returns a validation result.
+// Rules are matched with the simulation engine's semantics. The flow must pass
+// the ingress policies of its destination and the egress policies of its
+// source: a deny rule in any selecting policy wins, then an allow rule whose
+// policies' requirements hold, and unmatched traffic is blocked if a selecting
+// policy enables default deny.
 func (m *PolicyMatcher) Match(event *models.TelemetryEvent) *ValidationResult {
 	result := &ValidationResult{
 		Timestamp:    event.Timestamp,
@@ -144,65 +154,21 @@ func (m *PolicyMatcher) Match(event *models.TelemetryEvent) *ValidationResult {
 	policies := m.policies
 	m.policiesMu.RUnlock()
 
-	// Find policies that apply to this flow
-	var applicablePolicy *ParsedPolicyCache
-	var matchedRule bool
-
-	for _, pc := range policies {
-		policy := pc.Parsed
-
-		// Check if policy applies to destination (for ingress rules)
-		if len(policy.IngressRules) > 0 || policy.DefaultDenyType == "ingress" || policy.DefaultDenyType == "both" {
-			if m.matchesEndpointSelector(event.DstNamespace, event.DstPodLabels, policy) {
-				applicablePolicy = pc
-				// Check if any ingress rule allows the source
-				for _, rule := range policy.IngressRules {
-					if m.flowMatchesIngressRule(event, &rule) {
-						result.Verdict = VerdictAllowed
-						result.MatchedPolicy = pc.Name
-						result.Reason = "Matched ingress allow rule"
-						matchedRule = true
-						break
-					}
-				}
-				if !matchedRule && applicablePolicy != nil {
-					// Policy applies but no rule matched = blocked
-					result.Verdict = VerdictBlocked
-					result.MatchedPolicy = pc.Name
-					result.Reason = "No matching ingress rule, default deny"
-					return result
-				}
-				if matchedRule {
-					return result
-				}
-			}
+	var allowed *ValidationResult
+	for _, direction := range []string{"ingress", "egress"} {
+		decided := matchDirection(event, policies, direction, result)
+		if decided == nil {
+			continue
 		}
-
-		// Check if policy applies to source (for egress rules)
-		if len(policy.EgressRules) > 0 || policy.DefaultDenyType == "egress" || policy.DefaultDenyType == "both" {
-			if m.matchesEndpointSelector(event.SrcNamespace, event.SrcPodLabels, policy) {
-				applicablePolicy = pc
-				// Check if any egress rule allows the destination
-				for _, rule := range policy.EgressRules {
-					if m.flowMatchesEgressRule(event, &rule) {
-						result.Verdict = VerdictAllowed
-						result.MatchedPolicy = pc.Name
-						result.Reason = "Matched egress allow rule"
-						matchedRule = true
-						break
-					}
-				}
-				if !matchedRule && applicablePolicy != nil {
-					result.Verdict = VerdictBlocked
-					result.MatchedPolicy = pc.Name
-					result.Reason = "No matching egress rule, default deny"
-					return result
-				}
-				if matchedRule {
-					return result
-				}
-			}
+		if decided.Verdict == VerdictBlocked {
+			return decided
 		}
+		if allowed == nil {
+			allowed = decided
+		}
+	}
+	if allowed != nil {
+		return allowed
 	}
 
 	// No policy governs this flow
@@ -211,212 +177,54 @@ func (m *PolicyMatcher) Match(event *models.TelemetryEvent) *ValidationResult {
 	return result
 }
 
-// matchesEndpointSelector checks if a pod matches the policy's endpoint selector
-func (m *PolicyMatcher) matchesEndpointSelector(namespace string, labels map[string]string, policy *simulation.ParsedPolicy) bool {
-	// Check namespace match for namespaced policies
-	if policy.Namespace != "" && namespace != policy.Namespace {
-		return false
-	}
-
-	// Empty selector matches all pods
-	if len(policy.PodSelector) == 0 {
-		return true
-	}
-
-	// Check labels match
-	return labelsMatch(labels, policy.PodSelector)
-}
-
-// flowMatchesIngressRule checks if a flow matches an ingress rule (source matches rule)
-func (m *PolicyMatcher) flowMatchesIngressRule(event *models.TelemetryEvent, rule *simulation.PolicyRule) bool {
-	// Check source labels
-	if len(rule.PodSelector) > 0 {
-		if !labelsMatch(event.SrcPodLabels, rule.PodSelector) {
-			return false
-		}
-	}
-
-	// Check source namespace
-	if len(rule.NamespaceSelector) > 0 {
-		if nsName, ok := rule.NamespaceSelector["name"]; ok {
-			if event.SrcNamespace != nsName {
-				return false
-			}
-		}
-	}
-
-	// Check ports
-	if len(rule.ToPorts) > 0 {
-		portMatched := false
-		for _, pr := range rule.ToPorts {
-			if m.portMatches(event, &pr) {
-				portMatched = true
-				break
-			}
-		}
-		if !portMatched {
-			return false
+// matchDirection decides a flow by the policies selecting its endpoint in one
+// direction: the destination for ingress, the source for egress. Returns nil
+// if no selecting policy decides the flow.
+func matchDirection(event *models.TelemetryEvent, policies []*ParsedPolicyCache, direction string, base *ValidationResult) *ValidationResult {
+	var selecting []*ParsedPolicyCache
+	for _, pc := range policies {
+		if simulation.PolicyCoversDirection(pc.Parsed, direction) &&
+			simulation.MatchesPodSelectorForDirection(event, pc.Parsed, direction) {
+			selecting = append(selecting, pc)
 		}
 	}
 
-	return true
-}
-
-// flowMatchesEgressRule checks if a flow matches an egress rule (destination matches rule)
-func (m *PolicyMatcher) flowMatchesEgressRule(event *models.TelemetryEvent, rule *simulation.PolicyRule) bool {
-	// Check destination labels
-	if len(rule.PodSelector) > 0 {
-		if !labelsMatch(event.DstPodLabels, rule.PodSelector) {
-			return false
-		}
+	decide := func(verdict Verdict, pc *ParsedPolicyCache, reason string) *ValidationResult {
+		result := *base
+		result.Verdict = verdict
+		result.MatchedPolicy = pc.Name
+		result.Reason = reason
+		return &result
 	}
 
-	// Check destination namespace
-	if len(rule.NamespaceSelector) > 0 {
-		if nsName, ok := rule.NamespaceSelector["name"]; ok {
-			if event.DstNamespace != nsName {
-				return false
+	// Deny rules take precedence over allow rules
+	for _, pc := range selecting {
+		for _, rule := range simulation.RulesForDirection(pc.Parsed, direction) {
+			if rule.Action == "deny" && simulation.FlowMatchesRuleInDirection(event, &rule, direction) {
+				return decide(VerdictBlocked, pc, fmt.Sprintf("Matched %s deny rule", direction))
 			}
 		}
 	}
 
-	// Check ports
-	if len(rule.ToPorts) > 0 {
-		portMatched := false
-		for _, pr := range rule.ToPorts {
-			if m.portMatches(event, &pr) {
-				portMatched = true
-				break
+	for _, pc := range selecting {
+		for _, rule := range simulation.RulesForDirection(pc.Parsed, direction) {
+			if rule.Action == "deny" || !simulation.FlowMatchesRuleInDirection(event, &rule, direction) {
+				continue
 			}
-		}
-		if !portMatched {
-			return false
-		}
-	}
-
-	// Check toFQDNs (DNS query patterns)
-	if len(rule.ToFQDNs) > 0 {
-		// This rule only applies to DNS traffic
-		if event.DstPort != 53 && event.DNSQuery == "" {
-			return false
-		}
-		// If there's a DNS query, check if it matches any allowed pattern
-		if event.DNSQuery != "" {
-			fqdnMatched := false
-			for _, pattern := range rule.ToFQDNs {
-				if m.fqdnMatches(event.DNSQuery, pattern) {
-					fqdnMatched = true
-					break
+			for _, other := range selecting {
+				if !simulation.RequiresSatisfied(event, other.Parsed, direction) {
+					return decide(VerdictBlocked, other, fmt.Sprintf("Peer does not satisfy %s requirements", direction))
 				}
 			}
-			if !fqdnMatched {
-				return false
-			}
-		}
-	}
-
-	return true
-}
-
-// fqdnMatches checks if a DNS query matches an FQDN pattern (supports wildcards)
-func (m *PolicyMatcher) fqdnMatches(query, pattern string) bool {
-	// Normalize: remove trailing dots
-	query = strings.TrimSuffix(strings.ToLower(query), ".")
-	pattern = strings.TrimSuffix(strings.ToLower(pattern), ".")
-
-	// Exact match
-	if query == pattern {
-		return true
-	}
-
-	// Wildcard matching: *.example.com matches foo.example.com, bar.example.com
-	if strings.HasPrefix(pattern, "*.") {
-		suffix := pattern[1:] // ".example.com"
-		return strings.HasSuffix(query, suffix)
-	}
-
-	// Wildcard matching: *example.com matches fooexample.com (less common but valid)
-	if strings.HasPrefix(pattern, "*") {
-		suffix := pattern[1:]
-		return strings.HasSuffix(query, suffix)
-	}
-
-	return false
-}
-
-// portMatches checks if a flow matches a port rule
-func (m *PolicyMatcher) portMatches(event *models.TelemetryEvent, rule *simulation.PortRule) bool {
-	// Check protocol
-	if rule.Protocol != "" && event.Protocol != rule.Protocol {
-		return false
-	}
-
-	// Check port
-	if rule.Port > 0 {
-		if rule.EndPort > 0 {
-			// Port range
-			if event.DstPort < rule.Port || event.DstPort > rule.EndPort {
-				return false
-			}
-		} else {
-			// Single port
-			if event.DstPort != rule.Port {
-				return false
-			}
+			return decide(VerdictAllowed, pc, fmt.Sprintf("Matched %s allow rule", direction))
 		}
 	}
 
-	return true
-}
-
-// labelsMatch checks if a label map contains all required key-value pairs
-func labelsMatch(labels map[string]string, required map[string]string) bool {
-	if labels == nil && len(required) > 0 {
-		return false
-	}
-	for key, reqValue := range required {
-		// Strip Cilium label prefixes from required keys (any:, k8s:, etc.)
-		cleanKey := stripLabelPrefix(key)
-		value, ok := getLabelValue(labels, cleanKey)
-		if !ok || value != reqValue {
-			return false
-		}
-	}
-	return true
-}
-
-// stripLabelPrefix removes Cilium label prefixes like any:, k8s:, reserved:
-func stripLabelPrefix(key string) string {
-	prefixes := []string{"any:", "k8s:", "reserved:", "container:"}
-	for _, prefix := range prefixes {
-		if strings.HasPrefix(key, prefix) {
-			return strings.TrimPrefix(key, prefix)
+	// Policy applies but no rule matched
+	for _, pc := range selecting {
+		if simulation.DefaultDenies(pc.Parsed, direction) {
+			return decide(VerdictBlocked, pc, fmt.Sprintf("No matching %s rule, default deny", direction))
 		}
 	}
-	return key
-}
-
-// getLabelValue retrieves a label value, handling k8s: prefix
-func getLabelValue(labels map[string]string, key string) (string, bool) {
-	if value, ok := labels[key]; ok {
-		return value, true
-	}
-	if value, ok := labels["k8s:"+key]; ok {
-		return value, true
-	}
-	return "", false
-}
-
-// containsLabel checks if labels contain a specific key-value pair
-func containsLabel(labels map[string]string, key, value string) bool {
-	if labels == nil {
-		return false
-	}
-	if v, ok := labels[key]; ok && v == value {
-		return true
-	}
-	if v, ok := labels["k8s:"+key]; ok && v == value {
-		return true
-	}
-	return false
+	return nil
 }
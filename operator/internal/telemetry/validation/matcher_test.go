@@ -0,0 +1,292 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/policy-hub/operator/internal/telemetry/models"
+	"github.com/policy-hub/operator/internal/telemetry/simulation"
+)
+
+// newTestMatcher returns a matcher holding the given CiliumNetworkPolicies
+func newTestMatcher(t *testing.T, policies ...string) *PolicyMatcher {
+	t.Helper()
+	m := NewPolicyMatcher(nil, logr.Discard())
+	parser := simulation.NewPolicyParser()
+	for _, content := range policies {
+		parsed, err := parser.Parse(content, "CILIUM_NETWORK")
+		if err != nil {
+			t.Fatalf("Failed to parse policy: %v", err)
+		}
+		m.policies = append(m.policies, &ParsedPolicyCache{
+			Name:      parsed.Name,
+			Namespace: parsed.Namespace,
+			Parsed:    parsed,
+		})
+	}
+	return m
+}
+
+func TestPolicyMatcher_Match(t *testing.T) {
+	web := map[string]string{"app": "web"}
+	db := map[string]string{"app": "db"}
+
+	tests := []struct {
+		name        string
+		policy      string
+		event       models.TelemetryEvent
+		wantVerdict Verdict
+	}{
+		{
+			name: "endpoint selector with only matchExpressions does not select other pods",
+			policy: `apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: web-egress
+  namespace: shop
+spec:
+  endpointSelector:
+    matchExpressions:
+      - key: app
+        operator: In
+        values: [web]
+  egress:
+    - toEndpoints:
+        - matchLabels:
+            app: db
+`,
+			event: models.TelemetryEvent{
+				SrcNamespace: "shop", SrcPodLabels: map[string]string{"app": "api"},
+				DstNamespace: "shop", DstPodLabels: map[string]string{"app": "cache"},
+				DstPort: 6379, Protocol: "TCP",
+			},
+			wantVerdict: VerdictNoPolicy,
+		},
+		{
+			name: "CIDR-only allow rule does not allow other destinations",
+			policy: `apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: web-egress
+  namespace: shop
+spec:
+  endpointSelector:
+    matchLabels:
+      app: web
+  egress:
+    - toCIDR:
+        - 10.0.0.0/8
+`,
+			event: models.TelemetryEvent{
+				SrcNamespace: "shop", SrcPodLabels: web,
+				DstIP: "8.8.8.8", DstIdentity: 2, DstPort: 443, Protocol: "TCP",
+			},
+			wantVerdict: VerdictBlocked,
+		},
+		{
+			name: "CIDR-only allow rule allows its prefix",
+			policy: `apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: web-egress
+  namespace: shop
+spec:
+  endpointSelector:
+    matchLabels:
+      app: web
+  egress:
+    - toCIDR:
+        - 10.0.0.0/8
+`,
+			event: models.TelemetryEvent{
+				SrcNamespace: "shop", SrcPodLabels: web,
+				DstIP: "10.1.2.3", DstIdentity: 2, DstPort: 443, Protocol: "TCP",
+			},
+			wantVerdict: VerdictAllowed,
+		},
+		{
+			name: "entity-only allow rule does not allow pods",
+			policy: `apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: web-egress
+  namespace: shop
+spec:
+  endpointSelector:
+    matchLabels:
+      app: web
+  egress:
+    - toEntities:
+        - kube-apiserver
+`,
+			event: models.TelemetryEvent{
+				SrcNamespace: "shop", SrcPodLabels: web,
+				DstNamespace: "shop", DstPodLabels: db, DstPort: 5432, Protocol: "TCP",
+			},
+			wantVerdict: VerdictBlocked,
+		},
+		{
+			name: "entity-only allow rule allows its entity",
+			policy: `apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: web-egress
+  namespace: shop
+spec:
+  endpointSelector:
+    matchLabels:
+      app: web
+  egress:
+    - toEntities:
+        - kube-apiserver
+`,
+			event: models.TelemetryEvent{
+				SrcNamespace: "shop", SrcPodLabels: web,
+				DstIP: "10.96.0.1", DstIdentity: 7, DstPort: 443, Protocol: "TCP",
+			},
+			wantVerdict: VerdictAllowed,
+		},
+		{
+			name: "service-only allow rule does not allow other services",
+			policy: `apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: web-egress
+  namespace: shop
+spec:
+  endpointSelector:
+    matchLabels:
+      app: web
+  egress:
+    - toServices:
+        - k8sService:
+            serviceName: payments
+            namespace: billing
+`,
+			event: models.TelemetryEvent{
+				SrcNamespace: "shop", SrcPodLabels: web,
+				DstNamespace: "shop", DstPodLabels: db, DstDNSName: "db.shop.svc.cluster.local",
+				DstPort: 5432, Protocol: "TCP",
+			},
+			wantVerdict: VerdictBlocked,
+		},
+		{
+			name: "service-only allow rule allows its service",
+			policy: `apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: web-egress
+  namespace: shop
+spec:
+  endpointSelector:
+    matchLabels:
+      app: web
+  egress:
+    - toServices:
+        - k8sService:
+            serviceName: payments
+            namespace: billing
+`,
+			event: models.TelemetryEvent{
+				SrcNamespace: "shop", SrcPodLabels: web,
+				DstNamespace: "billing", DstPodLabels: map[string]string{"app": "payments"},
+				DstDNSName: "payments.billing.svc.cluster.local", DstPort: 443, Protocol: "TCP",
+			},
+			wantVerdict: VerdictAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestMatcher(t, tt.policy)
+			result := m.Match(&tt.event)
+			if result.Verdict != tt.wantVerdict {
+				t.Errorf("Match() verdict = %v, want %v (reason: %s)", result.Verdict, tt.wantVerdict, result.Reason)
+			}
+		})
+	}
+}
+
+func TestPolicyMatcher_Match_DenyRules(t *testing.T) {
+	web := map[string]string{"app": "web"}
+	db := map[string]string{"app": "db"}
+	// Each policy allows web to reach db, payments and the world, and denies
+	// one kind of peer
+	policyWithDeny := func(deny string) string {
+		return `apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: web-egress
+  namespace: shop
+spec:
+  endpointSelector:
+    matchLabels:
+      app: web
+  egress:
+    - toEndpoints:
+        - matchLabels:
+            app: db
+        - matchLabels:
+            app: payments
+            k8s:io.kubernetes.pod.namespace: billing
+    - toCIDR:
+        - 0.0.0.0/0
+  egressDeny:
+` + deny
+	}
+	toDB := models.TelemetryEvent{
+		SrcNamespace: "shop", SrcPodLabels: web,
+		DstNamespace: "shop", DstPodLabels: db, DstDNSName: "db.shop.svc.cluster.local",
+		DstPort: 5432, Protocol: "TCP",
+	}
+	toWorld := models.TelemetryEvent{
+		SrcNamespace: "shop", SrcPodLabels: web,
+		DstIP: "203.0.113.10", DstIdentity: 2, DstPort: 443, Protocol: "TCP",
+	}
+	toPayments := models.TelemetryEvent{
+		SrcNamespace: "shop", SrcPodLabels: web,
+		DstNamespace: "billing", DstPodLabels: map[string]string{"app": "payments"},
+		DstDNSName: "payments.billing.svc.cluster.local", DstPort: 443, Protocol: "TCP",
+	}
+
+	entityDeny := policyWithDeny(`    - toEntities:
+        - world
+`)
+	cidrDeny := policyWithDeny(`    - toCIDRSet:
+        - cidr: 203.0.113.0/24
+`)
+	serviceDeny := policyWithDeny(`    - toServices:
+        - k8sService:
+            serviceName: payments
+            namespace: billing
+`)
+
+	tests := []struct {
+		name        string
+		policy      string
+		event       models.TelemetryEvent
+		wantVerdict Verdict
+	}{
+		{name: "entity deny keeps in-cluster allow", policy: entityDeny, event: toDB, wantVerdict: VerdictAllowed},
+		{name: "entity deny blocks its entity", policy: entityDeny, event: toWorld, wantVerdict: VerdictBlocked},
+		{name: "CIDR deny keeps in-cluster allow", policy: cidrDeny, event: toDB, wantVerdict: VerdictAllowed},
+		{name: "CIDR deny blocks its prefix", policy: cidrDeny, event: toWorld, wantVerdict: VerdictBlocked},
+		{name: "service deny keeps other allows", policy: serviceDeny, event: toDB, wantVerdict: VerdictAllowed},
+		{name: "payments allowed without a deny", policy: entityDeny, event: toPayments, wantVerdict: VerdictAllowed},
+		{name: "service deny blocks its service", policy: serviceDeny, event: toPayments, wantVerdict: VerdictBlocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestMatcher(t, tt.policy)
+			result := m.Match(&tt.event)
+			if result.Verdict != tt.wantVerdict {
+				t.Errorf("Match() verdict = %v, want %v (reason: %s)", result.Verdict, tt.wantVerdict, result.Reason)
+			}
+			if tt.wantVerdict == VerdictBlocked && result.Reason != "Matched egress deny rule" {
+				t.Errorf("Match() reason = %q, want the deny rule", result.Reason)
+			}
+		})
+	}
+}
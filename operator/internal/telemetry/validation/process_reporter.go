@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/go-logr/logr"
 
+	"github.com/policy-hub/operator/internal/saas"
 	"github.com/policy-hub/operator/internal/telemetry/models"
 )
 
@@ -20,6 +22,7 @@ type ProcessValidationReporter struct {
 	apiKey     string
 	clusterID  string
 	httpClient *http.Client
+	saasClient *saas.Client
 	log        logr.Logger
 
 	// Aggregation state
@@ -48,6 +51,9 @@ type ProcessValidationReporterConfig struct {
 	MaxEvents  int
 	SampleRate int
 	Logger     logr.Logger
+	// SaaSClient, when set, submits the data instead of the reporter. Data
+	// that cannot be sent is kept in the client's outbox until it is.
+	SaaSClient *saas.Client
 }
 
 // ProcessCoverageGap represents a process with no governing policy
@@ -114,6 +120,7 @@ func NewProcessValidationReporter(cfg ProcessValidationReporterConfig) *ProcessV
 		apiKey:       cfg.APIKey,
 		clusterID:    cfg.ClusterID,
 		httpClient:   &http.Client{Timeout: 30 * time.Second},
+		saasClient:   cfg.SaaSClient,
 		log:          cfg.Logger.WithName("process-validation-reporter"),
 		currentHour:  truncateToHour(time.Now()),
 		coverageGaps: make(map[string]*ProcessCoverageGap),
@@ -378,6 +385,15 @@ func (r *ProcessValidationReporter) send(ctx context.Context, payload ProcessVal
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
+	if r.saasClient != nil {
+		_, err := r.saasClient.Submit(ctx, "POST", "/api/operator/process-validation", "process-validation", body)
+		if errors.Is(err, saas.ErrQueued) {
+			r.log.V(1).Info("Queued process validation data for retry", "reason", err.Error())
+			return nil
+		}
+		return err
+	}
+
 	req, err := http.NewRequestWithContext(ctx, "POST", r.endpoint, bytes.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
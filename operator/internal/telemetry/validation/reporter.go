@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
+
+	"github.com/policy-hub/operator/internal/saas"
 )
 
 // Reporter sends validation data to the SaaS platform
@@ -18,6 +21,7 @@ type Reporter struct {
 	apiKey       string
 	clusterID    string
 	httpClient   *http.Client
+	saasClient   *saas.Client
 	log          logr.Logger
 
 	// Aggregation state
@@ -26,8 +30,10 @@ type Reporter struct {
 	allowedCount  int64
 	blockedCount  int64
 	noPolicyCount int64
+	auditedCount  int64
 	coverageGaps  map[string]*CoverageGap  // key: src/dst/port
 	topBlocked    map[string]*BlockedFlow  // key: src/dst/policy
+	topAudited    map[string]*BlockedFlow  // key: src/dst/policy
 	recentEvents  []ValidationEvent        // Sample of recent events
 	maxEvents     int
 	eventCounter  int64
@@ -49,6 +55,9 @@ type ReporterConfig struct {
 	MaxEvents   int
 	SampleRate  int
 	Logger      logr.Logger
+	// SaaSClient, when set, submits the data instead of the reporter. Data
+	// that cannot be sent is kept in the client's outbox until it is.
+	SaaSClient *saas.Client
 }
 
 // NewReporter creates a new validation reporter
@@ -65,10 +74,12 @@ func NewReporter(cfg ReporterConfig) *Reporter {
 		apiKey:       cfg.APIKey,
 		clusterID:    cfg.ClusterID,
 		httpClient:   &http.Client{Timeout: 30 * time.Second},
+		saasClient:   cfg.SaaSClient,
 		log:          cfg.Logger.WithName("validation-reporter"),
 		currentHour:  truncateToHour(time.Now()),
 		coverageGaps: make(map[string]*CoverageGap),
 		topBlocked:   make(map[string]*BlockedFlow),
+		topAudited:   make(map[string]*BlockedFlow),
 		recentEvents: make([]ValidationEvent, 0, cfg.MaxEvents),
 		maxEvents:    cfg.MaxEvents,
 		sampleRate:   cfg.SampleRate,
@@ -101,20 +112,10 @@ func (r *Reporter) Record(result *ValidationResult) {
 			"policy", result.MatchedPolicy,
 			"reason", result.Reason)
 		// Track top blocked
-		key := fmt.Sprintf("%s/%s/%s/%s", result.SrcNamespace, result.SrcPodName, result.DstNamespace, result.MatchedPolicy)
-		if bf, ok := r.topBlocked[key]; ok {
-			bf.Count++
-		} else {
-			r.topBlocked[key] = &BlockedFlow{
-				SrcNamespace: result.SrcNamespace,
-				SrcPodName:   result.SrcPodName,
-				DstNamespace: result.DstNamespace,
-				DstPodName:   result.DstPodName,
-				DstPort:      int(result.DstPort),
-				Policy:       result.MatchedPolicy,
-				Count:        1,
-			}
-		}
+		countFlow(r.topBlocked, result)
+	case VerdictAudited:
+		r.auditedCount++
+		countFlow(r.topAudited, result)
 	case VerdictNoPolicy:
 		r.noPolicyCount++
 		// Track coverage gaps
@@ -188,7 +189,7 @@ func (r *Reporter) Flush(ctx context.Context) error {
 	r.mu.Lock()
 
 	// Nothing to send
-	if r.allowedCount == 0 && r.blockedCount == 0 && r.noPolicyCount == 0 && r.gatewayValidation == nil {
+	if r.allowedCount == 0 && r.blockedCount == 0 && r.noPolicyCount == 0 && r.auditedCount == 0 && r.gatewayValidation == nil {
 		r.mu.Unlock()
 		return nil
 	}
@@ -199,6 +200,7 @@ func (r *Reporter) Flush(ctx context.Context) error {
 		AllowedCount:  r.allowedCount,
 		BlockedCount:  r.blockedCount,
 		NoPolicyCount: r.noPolicyCount,
+		AuditedCount:  r.auditedCount,
 	}
 
 	// Add top coverage gaps (limit to 20)
@@ -217,6 +219,14 @@ func (r *Reporter) Flush(ctx context.Context) error {
 		}
 	}
 
+	// Add top audited flows (limit to 20)
+	for _, af := range r.topAudited {
+		summary.TopAudited = append(summary.TopAudited, *af)
+		if len(summary.TopAudited) >= 20 {
+			break
+		}
+	}
+
 	// Copy events
 	events := make([]ValidationEvent, len(r.recentEvents))
 	copy(events, r.recentEvents)
@@ -228,8 +238,10 @@ func (r *Reporter) Flush(ctx context.Context) error {
 	r.allowedCount = 0
 	r.blockedCount = 0
 	r.noPolicyCount = 0
+	r.auditedCount = 0
 	r.coverageGaps = make(map[string]*CoverageGap)
 	r.topBlocked = make(map[string]*BlockedFlow)
+	r.topAudited = make(map[string]*BlockedFlow)
 	r.recentEvents = r.recentEvents[:0]
 	r.gatewayValidation = nil
 
@@ -272,6 +284,15 @@ func (r *Reporter) send(ctx context.Context, payload ValidationIngestion) error
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
+	if r.saasClient != nil {
+		_, err := r.saasClient.Submit(ctx, "POST", "/api/operator/validation", "validation", body)
+		if errors.Is(err, saas.ErrQueued) {
+			r.log.V(1).Info("Queued validation data for retry", "reason", err.Error())
+			return nil
+		}
+		return err
+	}
+
 	req, err := http.NewRequestWithContext(ctx, "POST", r.endpoint, bytes.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
@@ -333,6 +354,24 @@ func (r *Reporter) GetStats() (sent, failed int64) {
 	return r.totalSent, r.totalFailed
 }
 
+// countFlow counts a flow by source, destination and matched policy
+func countFlow(flows map[string]*BlockedFlow, result *ValidationResult) {
+	key := fmt.Sprintf("%s/%s/%s/%s", result.SrcNamespace, result.SrcPodName, result.DstNamespace, result.MatchedPolicy)
+	if bf, ok := flows[key]; ok {
+		bf.Count++
+		return
+	}
+	flows[key] = &BlockedFlow{
+		SrcNamespace: result.SrcNamespace,
+		SrcPodName:   result.SrcPodName,
+		DstNamespace: result.DstNamespace,
+		DstPodName:   result.DstPodName,
+		DstPort:      int(result.DstPort),
+		Policy:       result.MatchedPolicy,
+		Count:        1,
+	}
+}
+
 // truncateToHour truncates a time to the start of the hour
 func truncateToHour(t time.Time) time.Time {
 	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
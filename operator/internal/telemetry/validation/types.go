@@ -17,6 +17,9 @@ const (
 	VerdictBlocked Verdict = "BLOCKED"
 	// VerdictNoPolicy indicates no policy governs this flow (coverage gap)
 	VerdictNoPolicy Verdict = "NO_POLICY"
+	// VerdictAudited indicates the flow was forwarded because its policy is in
+	// audit mode, and would be blocked once the policy is enforced
+	VerdictAudited Verdict = "AUDITED"
 )
 
 // ValidationResult ties a flow to its policy verdict
@@ -41,8 +44,10 @@ type ValidationSummary struct {
 	AllowedCount  int64          `json:"allowedCount"`
 	BlockedCount  int64          `json:"blockedCount"`
 	NoPolicyCount int64          `json:"noPolicyCount"`
+	AuditedCount  int64          `json:"auditedCount"`
 	CoverageGaps  []CoverageGap  `json:"coverageGaps,omitempty"`
 	TopBlocked    []BlockedFlow  `json:"topBlocked,omitempty"`
+	TopAudited    []BlockedFlow  `json:"topAudited,omitempty"` // Flows that would be blocked when enforced
 }
 
 // CoverageGap represents a source/destination pair without policy coverage
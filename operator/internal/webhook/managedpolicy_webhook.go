@@ -0,0 +1,183 @@
+// Package webhook implements the admission webhooks validating and defaulting
+// the operator's custom resources before they are stored.
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+	"github.com/policy-hub/operator/internal/policy"
+)
+
+// Bounds of the rollout bake time. Blocked flows are counted per minute and
+// kept long enough to cover the baseline and bake windows of a step.
+const (
+	minBakeTime = time.Minute
+	maxBakeTime = time.Hour
+)
+
+// +kubebuilder:webhook:path=/validate-policyhub-io-v1alpha1-managedpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=policyhub.io,resources=managedpolicies,verbs=create;update,versions=v1alpha1,name=vmanagedpolicy.policyhub.io,admissionReviewVersions=v1
+
+// ManagedPolicyValidator rejects ManagedPolicies the deployer could not deploy
+type ManagedPolicyValidator struct {
+	Deployer *policy.Deployer
+	Log      logr.Logger
+}
+
+var _ admission.CustomValidator = &ManagedPolicyValidator{}
+
+// SetupManagedPolicyWebhookWithManager registers the ManagedPolicy webhook with the manager
+func SetupManagedPolicyWebhookWithManager(mgr ctrl.Manager, deployer *policy.Deployer, log logr.Logger) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&policyv1alpha1.ManagedPolicy{}).
+		WithValidator(&ManagedPolicyValidator{Deployer: deployer, Log: log}).
+		Complete()
+}
+
+// ValidateCreate validates a new ManagedPolicy
+func (v *ManagedPolicyValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	mp, ok := obj.(*policyv1alpha1.ManagedPolicy)
+	if !ok {
+		return nil, fmt.Errorf("expected a ManagedPolicy but got %T", obj)
+	}
+	return v.validate(ctx, mp)
+}
+
+// ValidateUpdate validates a ManagedPolicy update. Updates that leave the
+// spec unchanged, such as label or finalizer changes, are always allowed so
+// policies created before the webhook can still be cleaned up.
+func (v *ManagedPolicyValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldMP, ok := oldObj.(*policyv1alpha1.ManagedPolicy)
+	if !ok {
+		return nil, fmt.Errorf("expected a ManagedPolicy but got %T", oldObj)
+	}
+	mp, ok := newObj.(*policyv1alpha1.ManagedPolicy)
+	if !ok {
+		return nil, fmt.Errorf("expected a ManagedPolicy but got %T", newObj)
+	}
+	if mp.DeletionTimestamp != nil || equality.Semantic.DeepEqual(oldMP.Spec, mp.Spec) {
+		return nil, nil
+	}
+	return v.validate(ctx, mp)
+}
+
+// ValidateDelete allows every deletion
+func (v *ManagedPolicyValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate checks the content with the deployer's parsing and the target namespaces
+func (v *ManagedPolicyValidator) validate(ctx context.Context, mp *policyv1alpha1.ManagedPolicy) (admission.Warnings, error) {
+	var warnings admission.Warnings
+	specPath := field.NewPath("spec")
+
+	allErrs := validateNamespaces(specPath.Child("targetNamespaces"), mp.Spec.TargetNamespaces)
+	if len(mp.Spec.TargetNamespaces) > 0 && mp.Spec.PolicyType == policyv1alpha1.PolicyTypeCiliumClusterwide {
+		warnings = append(warnings, "spec.targetNamespaces is ignored for cluster-wide policies")
+	}
+	if selector := mp.Spec.NamespaceSelector; selector != nil {
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(selector,
+			metav1validation.LabelSelectorValidationOptions{}, specPath.Child("namespaceSelector"))...)
+		if mp.Spec.PolicyType == policyv1alpha1.PolicyTypeCiliumClusterwide {
+			warnings = append(warnings, "spec.namespaceSelector is ignored for cluster-wide policies")
+		}
+	}
+
+	if mp.Spec.Rollout != nil {
+		allErrs = append(allErrs, validateRollout(specPath.Child("rollout"), mp)...)
+		if mp.Spec.PolicyType == policyv1alpha1.PolicyTypeCiliumClusterwide {
+			warnings = append(warnings, "cluster-wide policies cannot be staged by namespace and roll out in a single step")
+		}
+	}
+
+	if value, ok := mp.Annotations[policyv1alpha1.RollbackAnnotation]; ok {
+		if version, err := strconv.Atoi(value); err != nil || version < 1 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "annotations").Key(policyv1alpha1.RollbackAnnotation),
+				value, "must be a policy version"))
+		}
+	}
+
+	switch mp.Spec.PolicyType {
+	case policyv1alpha1.PolicyTypeTetragon:
+	case policyv1alpha1.PolicyTypeCiliumNetwork, policyv1alpha1.PolicyTypeCiliumClusterwide:
+		// Audit mode is refused by ValidatePolicy unless Cilium audits every
+		// network policy
+		if !mp.IsAudited() && v.Deployer.CiliumAuditMode(ctx) {
+			warnings = append(warnings, "Cilium runs in policy audit mode: this policy is audited, not enforced, until it is disabled")
+		}
+	default:
+		if mp.IsAudited() {
+			warnings = append(warnings, "audit mode only applies to Tetragon and Cilium network policies; this policy is enforced")
+		}
+	}
+
+	if err := v.Deployer.ValidatePolicy(ctx, mp); err != nil {
+		if errors.Is(err, policy.ErrAuditModeUnsupported) {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("mode"), mp.Spec.Mode, err.Error()))
+		} else {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("content"), "<content>", err.Error()))
+		}
+	}
+	if mp.Spec.ContentHash != "" && mp.Spec.ContentHash != policy.ContentHash(mp.Spec.Content) {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("contentHash"), mp.Spec.ContentHash,
+			"does not match the SHA-256 of spec.content"))
+	}
+
+	if len(allErrs) == 0 {
+		return warnings, nil
+	}
+	v.Log.V(1).Info("Rejected ManagedPolicy", "name", mp.Name, "namespace", mp.Namespace, "errors", allErrs.ToAggregate().Error())
+	return warnings, apierrors.NewInvalid(policyv1alpha1.GroupVersion.WithKind("ManagedPolicy").GroupKind(), mp.Name, allErrs)
+}
+
+// validateNamespaces checks a list of namespace names for invalid or duplicate entries
+func validateNamespaces(path *field.Path, namespaces []string) field.ErrorList {
+	var allErrs field.ErrorList
+	seen := make(map[string]bool, len(namespaces))
+	for i, ns := range namespaces {
+		for _, msg := range validation.IsDNS1123Label(ns) {
+			allErrs = append(allErrs, field.Invalid(path.Index(i), ns, msg))
+		}
+		if seen[ns] {
+			allErrs = append(allErrs, field.Duplicate(path.Index(i), ns))
+		}
+		seen[ns] = true
+	}
+	return allErrs
+}
+
+// validateRollout checks that canary namespaces are target namespaces and the
+// bake time is within bounds. Canary namespaces of a policy with a namespace
+// selector may be namespaces it matches, which are only known at deploy time.
+func validateRollout(path *field.Path, mp *policyv1alpha1.ManagedPolicy) field.ErrorList {
+	rollout := mp.Spec.Rollout
+	canaryPath := path.Child("canaryNamespaces")
+	allErrs := validateNamespaces(canaryPath, rollout.CanaryNamespaces)
+
+	targets := policy.TargetNamespaces(mp)
+	isTarget := make(map[string]bool, len(targets))
+	for _, ns := range targets {
+		isTarget[ns] = true
+	}
+	for i, ns := range rollout.CanaryNamespaces {
+		if !isTarget[ns] && mp.Spec.NamespaceSelector == nil {
+			allErrs = append(allErrs, field.NotSupported(canaryPath.Index(i), ns, targets))
+		}
+	}
+
+	return append(allErrs, validateInterval(path.Child("bakeTime"), rollout.BakeTime, minBakeTime, maxBakeTime)...)
+}
@@ -0,0 +1,268 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+	"github.com/policy-hub/operator/internal/policy"
+)
+
+const ciliumPolicyContent = `apiVersion: cilium.io/v2
+kind: CiliumNetworkPolicy
+metadata:
+  name: api
+spec:
+  endpointSelector: {}
+`
+
+const httpRouteContent = `apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: api
+spec:
+  parentRefs:
+  - name: gateway
+`
+
+func testManagedPolicyValidator() *ManagedPolicyValidator {
+	return &ManagedPolicyValidator{
+		Deployer: policy.NewDeployer(nil, logr.Discard()),
+		Log:      logr.Discard(),
+	}
+}
+
+func newManagedPolicy(policyType policyv1alpha1.PolicyType, content string, namespaces ...string) *policyv1alpha1.ManagedPolicy {
+	return &policyv1alpha1.ManagedPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-policy", Namespace: "default"},
+		Spec: policyv1alpha1.ManagedPolicySpec{
+			PolicyID:         "policy-1",
+			Name:             "Test Policy",
+			PolicyType:       policyType,
+			Content:          content,
+			TargetNamespaces: namespaces,
+			Version:          1,
+		},
+	}
+}
+
+func withRollout(mp *policyv1alpha1.ManagedPolicy, bakeTime time.Duration, canary ...string) *policyv1alpha1.ManagedPolicy {
+	mp.Spec.Rollout = &policyv1alpha1.RolloutStrategy{
+		CanaryNamespaces: canary,
+		BakeTime:         metav1.Duration{Duration: bakeTime},
+	}
+	return mp
+}
+
+func withMode(mp *policyv1alpha1.ManagedPolicy, mode policyv1alpha1.PolicyMode) *policyv1alpha1.ManagedPolicy {
+	mp.Spec.Mode = mode
+	return mp
+}
+
+func withAnnotation(mp *policyv1alpha1.ManagedPolicy, key, value string) *policyv1alpha1.ManagedPolicy {
+	mp.Annotations = map[string]string{key: value}
+	return mp
+}
+
+func withNamespaceSelector(mp *policyv1alpha1.ManagedPolicy, labels map[string]string) *policyv1alpha1.ManagedPolicy {
+	mp.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: labels}
+	return mp
+}
+
+func withContentHash(mp *policyv1alpha1.ManagedPolicy, hash string) *policyv1alpha1.ManagedPolicy {
+	mp.Spec.ContentHash = hash
+	return mp
+}
+
+func TestManagedPolicyValidator_ValidateCreate(t *testing.T) {
+	tests := []struct {
+		name      string
+		mp        *policyv1alpha1.ManagedPolicy
+		wantErr   string
+		wantWarns int
+	}{
+		{
+			name: "valid policy",
+			mp:   newManagedPolicy(policyv1alpha1.PolicyTypeCiliumNetwork, ciliumPolicyContent, "default", "backend"),
+		},
+		{
+			name:    "empty content",
+			mp:      newManagedPolicy(policyv1alpha1.PolicyTypeCiliumNetwork, ""),
+			wantErr: "policy content is empty",
+		},
+		{
+			name:    "unparseable content",
+			mp:      newManagedPolicy(policyv1alpha1.PolicyTypeCiliumNetwork, "kind: [unclosed"),
+			wantErr: "invalid policy content",
+		},
+		{
+			name:    "mismatched policy type",
+			mp:      newManagedPolicy(policyv1alpha1.PolicyTypeTetragon, ciliumPolicyContent),
+			wantErr: "requires TracingPolicy kind",
+		},
+		{
+			name:    "invalid target namespace",
+			mp:      newManagedPolicy(policyv1alpha1.PolicyTypeCiliumNetwork, ciliumPolicyContent, "Not_A_Namespace"),
+			wantErr: "spec.targetNamespaces[0]",
+		},
+		{
+			name:    "duplicate target namespace",
+			mp:      newManagedPolicy(policyv1alpha1.PolicyTypeCiliumNetwork, ciliumPolicyContent, "default", "default"),
+			wantErr: "spec.targetNamespaces[1]: Duplicate value",
+		},
+		{
+			name: "target namespaces on cluster-wide policy",
+			mp: newManagedPolicy(policyv1alpha1.PolicyTypeCiliumClusterwide, `apiVersion: cilium.io/v2
+kind: CiliumClusterwideNetworkPolicy
+metadata:
+  name: cluster
+spec:
+  endpointSelector: {}
+`, "default"),
+			wantWarns: 1,
+		},
+		{
+			name: "valid rollout",
+			mp:   withRollout(newManagedPolicy(policyv1alpha1.PolicyTypeCiliumNetwork, ciliumPolicyContent, "default", "backend"), 10*time.Minute, "backend"),
+		},
+		{
+			name:    "canary namespace not targeted",
+			mp:      withRollout(newManagedPolicy(policyv1alpha1.PolicyTypeCiliumNetwork, ciliumPolicyContent, "default", "backend"), 0, "staging"),
+			wantErr: "spec.rollout.canaryNamespaces[0]: Unsupported value: \"staging\"",
+		},
+		{
+			name:    "bake time too long",
+			mp:      withRollout(newManagedPolicy(policyv1alpha1.PolicyTypeCiliumNetwork, ciliumPolicyContent, "default", "backend"), 2*time.Hour),
+			wantErr: "spec.rollout.bakeTime",
+		},
+		{
+			name:      "audit mode on gateway route",
+			mp:        withMode(newManagedPolicy(policyv1alpha1.PolicyTypeGatewayHTTPRoute, httpRouteContent), policyv1alpha1.PolicyModeAudit),
+			wantWarns: 1,
+		},
+		{
+			name: "rollback to recorded version",
+			mp: withAnnotation(newManagedPolicy(policyv1alpha1.PolicyTypeCiliumNetwork, ciliumPolicyContent),
+				policyv1alpha1.RollbackAnnotation, "1"),
+		},
+		{
+			name: "rollback to invalid version",
+			mp: withAnnotation(newManagedPolicy(policyv1alpha1.PolicyTypeCiliumNetwork, ciliumPolicyContent),
+				policyv1alpha1.RollbackAnnotation, "latest"),
+			wantErr: "metadata.annotations[policyhub.io/rollback-to-version]",
+		},
+		{
+			name: "canary namespace matched by selector",
+			mp: withRollout(withNamespaceSelector(newManagedPolicy(policyv1alpha1.PolicyTypeCiliumNetwork, ciliumPolicyContent),
+				map[string]string{"team": "payments"}), 0, "payments-staging"),
+		},
+		{
+			name: "invalid namespace selector",
+			mp: withNamespaceSelector(newManagedPolicy(policyv1alpha1.PolicyTypeCiliumNetwork, ciliumPolicyContent),
+				map[string]string{"team": "not a label value"}),
+			wantErr: "spec.namespaceSelector.matchLabels",
+		},
+		{
+			name:    "audit mode on cilium policy",
+			mp:      withMode(newManagedPolicy(policyv1alpha1.PolicyTypeCiliumNetwork, ciliumPolicyContent), policyv1alpha1.PolicyModeAudit),
+			wantErr: "spec.mode: Invalid value: \"audit\": audit mode is not supported for network policies",
+		},
+		{
+			name: "matching content hash",
+			mp:   withContentHash(newManagedPolicy(policyv1alpha1.PolicyTypeCiliumNetwork, ciliumPolicyContent), policy.ContentHash(ciliumPolicyContent)),
+		},
+		{
+			name:    "mismatched content hash",
+			mp:      withContentHash(newManagedPolicy(policyv1alpha1.PolicyTypeCiliumNetwork, ciliumPolicyContent), policy.ContentHash("other")),
+			wantErr: "spec.contentHash",
+		},
+	}
+
+	v := testManagedPolicyValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := v.ValidateCreate(context.Background(), tt.mp)
+			if len(warnings) != tt.wantWarns {
+				t.Errorf("Expected %d warnings, got %v", tt.wantWarns, warnings)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected error containing %q, got nil", tt.wantErr)
+			}
+			if !apierrors.IsInvalid(err) {
+				t.Errorf("Expected an Invalid error, got: %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestManagedPolicyValidator_ValidateUpdate(t *testing.T) {
+	v := testManagedPolicyValidator()
+	invalid := newManagedPolicy(policyv1alpha1.PolicyTypeTetragon, ciliumPolicyContent)
+
+	t.Run("allows metadata changes to an invalid policy", func(t *testing.T) {
+		updated := invalid.DeepCopy()
+		updated.Finalizers = nil
+		updated.Labels = map[string]string{"team": "platform"}
+		if _, err := v.ValidateUpdate(context.Background(), invalid, updated); err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+	})
+
+	t.Run("allows updates to a deleting policy", func(t *testing.T) {
+		updated := invalid.DeepCopy()
+		now := metav1.Now()
+		updated.DeletionTimestamp = &now
+		updated.Spec.Version = 2
+		if _, err := v.ValidateUpdate(context.Background(), invalid, updated); err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+	})
+
+	t.Run("rejects invalid spec changes", func(t *testing.T) {
+		valid := newManagedPolicy(policyv1alpha1.PolicyTypeCiliumNetwork, ciliumPolicyContent)
+		updated := valid.DeepCopy()
+		updated.Spec.Content = "kind: [unclosed"
+		if _, err := v.ValidateUpdate(context.Background(), valid, updated); err == nil {
+			t.Error("Expected error for invalid content")
+		}
+	})
+}
+
+func TestManagedPolicyValidator_CiliumAuditMode(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: policy.CiliumConfig.Namespace, Name: policy.CiliumConfig.Name},
+		Data:       map[string]string{"policy-audit-mode": "true"},
+	}).Build()
+	v := &ManagedPolicyValidator{Deployer: policy.NewDeployer(c, logr.Discard()), Log: logr.Discard()}
+
+	audited := withMode(newManagedPolicy(policyv1alpha1.PolicyTypeCiliumNetwork, ciliumPolicyContent), policyv1alpha1.PolicyModeAudit)
+	if warnings, err := v.ValidateCreate(context.Background(), audited); err != nil || len(warnings) != 0 {
+		t.Errorf("Expected an audited network policy to be allowed, got %v: %v", warnings, err)
+	}
+
+	// Cilium audits enforced network policies too
+	enforced := newManagedPolicy(policyv1alpha1.PolicyTypeCiliumNetwork, ciliumPolicyContent)
+	if warnings, err := v.ValidateCreate(context.Background(), enforced); err != nil || len(warnings) != 1 {
+		t.Errorf("Expected a warning that the policy is not enforced, got %v: %v", warnings, err)
+	}
+}
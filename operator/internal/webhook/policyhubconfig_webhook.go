@@ -0,0 +1,216 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+	"github.com/policy-hub/operator/internal/policy"
+)
+
+// Defaults applied to PolicyHubConfig, matching the CRD defaults
+const (
+	DefaultSyncInterval      = 30 * time.Second
+	DefaultHeartbeatInterval = 60 * time.Second
+	DefaultFlushInterval     = 10 * time.Second
+	DefaultHubbleAddress     = "hubble-relay.kube-system.svc.cluster.local:4245"
+	DefaultFlowBatchSize     = 500
+	DefaultReportConfigMap   = "policy-hub-report"
+)
+
+// Bounds of the PolicyHubConfig intervals. Shorter intervals would flood the
+// SaaS platform; longer ones would make the cluster look disconnected.
+const (
+	minSyncInterval      = 5 * time.Second
+	maxSyncInterval      = 24 * time.Hour
+	minHeartbeatInterval = 10 * time.Second
+	maxHeartbeatInterval = time.Hour
+	minFlushInterval     = time.Second
+	maxFlushInterval     = time.Hour
+)
+
+// +kubebuilder:webhook:path=/mutate-policyhub-io-v1alpha1-policyhubconfig,mutating=true,failurePolicy=fail,sideEffects=None,groups=policyhub.io,resources=policyhubconfigs,verbs=create;update,versions=v1alpha1,name=mpolicyhubconfig.policyhub.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-policyhub-io-v1alpha1-policyhubconfig,mutating=false,failurePolicy=fail,sideEffects=None,groups=policyhub.io,resources=policyhubconfigs,verbs=create;update,versions=v1alpha1,name=vpolicyhubconfig.policyhub.io,admissionReviewVersions=v1
+
+// PolicyHubConfigWebhook defaults and validates PolicyHubConfigs
+type PolicyHubConfigWebhook struct {
+	Log logr.Logger
+}
+
+var (
+	_ admission.CustomDefaulter = &PolicyHubConfigWebhook{}
+	_ admission.CustomValidator = &PolicyHubConfigWebhook{}
+)
+
+// SetupPolicyHubConfigWebhookWithManager registers the PolicyHubConfig webhooks with the manager
+func SetupPolicyHubConfigWebhookWithManager(mgr ctrl.Manager, log logr.Logger) error {
+	w := &PolicyHubConfigWebhook{Log: log}
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&policyv1alpha1.PolicyHubConfig{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+// Default sets unset intervals, flow collection and policy source settings
+func (w *PolicyHubConfigWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	config, ok := obj.(*policyv1alpha1.PolicyHubConfig)
+	if !ok {
+		return fmt.Errorf("expected a PolicyHubConfig but got %T", obj)
+	}
+
+	spec := &config.Spec
+	if spec.SyncInterval.Duration == 0 {
+		spec.SyncInterval = metav1.Duration{Duration: DefaultSyncInterval}
+	}
+	if spec.HeartbeatInterval.Duration == 0 {
+		spec.HeartbeatInterval = metav1.Duration{Duration: DefaultHeartbeatInterval}
+	}
+	if fc := spec.FlowCollection; fc != nil {
+		if fc.HubbleAddress == "" {
+			fc.HubbleAddress = DefaultHubbleAddress
+		}
+		if fc.BatchSize == 0 {
+			fc.BatchSize = DefaultFlowBatchSize
+		}
+		if fc.FlushInterval.Duration == 0 {
+			fc.FlushInterval = metav1.Duration{Duration: DefaultFlushInterval}
+		}
+	}
+	if ps := spec.PolicySource; ps != nil {
+		if ps.Type == "" {
+			ps.Type = policyv1alpha1.PolicySourceSaaS
+		}
+		if ps.ReportConfigMap == "" {
+			ps.ReportConfigMap = DefaultReportConfigMap
+		}
+	}
+	return nil
+}
+
+// ValidateCreate validates a new PolicyHubConfig
+func (w *PolicyHubConfigWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	config, ok := obj.(*policyv1alpha1.PolicyHubConfig)
+	if !ok {
+		return nil, fmt.Errorf("expected a PolicyHubConfig but got %T", obj)
+	}
+	return nil, w.validate(config)
+}
+
+// ValidateUpdate validates a PolicyHubConfig update
+func (w *PolicyHubConfigWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	config, ok := newObj.(*policyv1alpha1.PolicyHubConfig)
+	if !ok {
+		return nil, fmt.Errorf("expected a PolicyHubConfig but got %T", newObj)
+	}
+	if config.DeletionTimestamp != nil {
+		return nil, nil
+	}
+	return nil, w.validate(config)
+}
+
+// ValidateDelete allows every deletion
+func (w *PolicyHubConfigWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate checks the intervals, target namespaces, trusted keys and policy
+// source
+func (w *PolicyHubConfigWebhook) validate(config *policyv1alpha1.PolicyHubConfig) error {
+	specPath := field.NewPath("spec")
+
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateInterval(specPath.Child("syncInterval"), config.Spec.SyncInterval, minSyncInterval, maxSyncInterval)...)
+	allErrs = append(allErrs, validateInterval(specPath.Child("heartbeatInterval"), config.Spec.HeartbeatInterval, minHeartbeatInterval, maxHeartbeatInterval)...)
+	if fc := config.Spec.FlowCollection; fc != nil {
+		allErrs = append(allErrs, validateInterval(specPath.Child("flowCollection", "flushInterval"), fc.FlushInterval, minFlushInterval, maxFlushInterval)...)
+	}
+	allErrs = append(allErrs, validateNamespaces(specPath.Child("targetNamespaces"), config.Spec.TargetNamespaces)...)
+	if ps := config.Spec.PolicySigning; ps != nil {
+		allErrs = append(allErrs, validateTrustedKeys(specPath.Child("policySigning", "trustedKeys"), ps.TrustedKeys)...)
+	}
+	allErrs = append(allErrs, validatePolicySource(specPath, &config.Spec)...)
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	w.Log.V(1).Info("Rejected PolicyHubConfig", "name", config.Name, "namespace", config.Namespace, "errors", allErrs.ToAggregate().Error())
+	return apierrors.NewInvalid(policyv1alpha1.GroupVersion.WithKind("PolicyHubConfig").GroupKind(), config.Name, allErrs)
+}
+
+// validateInterval checks that a set interval is within bounds. Zero means
+// unset and is replaced by the default.
+func validateInterval(path *field.Path, interval metav1.Duration, minimum, maximum time.Duration) field.ErrorList {
+	d := interval.Duration
+	switch {
+	case d == 0:
+		return nil
+	case d < 0:
+		return field.ErrorList{field.Invalid(path, d.String(), "must not be negative")}
+	case d < minimum:
+		return field.ErrorList{field.Invalid(path, d.String(), fmt.Sprintf("must be at least %s", minimum))}
+	case d > maximum:
+		return field.ErrorList{field.Invalid(path, d.String(), fmt.Sprintf("must be at most %s", maximum))}
+	}
+	return nil
+}
+
+// validateTrustedKeys checks that the trusted keys have unique IDs and are
+// Ed25519 public keys
+func validateTrustedKeys(path *field.Path, keys []policyv1alpha1.TrustedKey) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(keys) == 0 {
+		allErrs = append(allErrs, field.Required(path, "at least one trusted key is required"))
+	}
+	seen := make(map[string]bool)
+	for i, key := range keys {
+		if key.ID == "" {
+			allErrs = append(allErrs, field.Required(path.Index(i).Child("id"), ""))
+		} else if seen[key.ID] {
+			allErrs = append(allErrs, field.Duplicate(path.Index(i).Child("id"), key.ID))
+		}
+		seen[key.ID] = true
+		if _, err := policy.ParsePublicKey(key.PublicKey); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Index(i).Child("publicKey"), "<public key>", err.Error()))
+		}
+	}
+	return allErrs
+}
+
+// validatePolicySource checks that the SaaS endpoint is set unless policies
+// are synced from a directory, which must be an absolute path
+func validatePolicySource(specPath *field.Path, spec *policyv1alpha1.PolicyHubConfigSpec) field.ErrorList {
+	ps := spec.PolicySource
+	if ps == nil || ps.Type != policyv1alpha1.PolicySourceDirectory {
+		if spec.SaaSEndpoint == "" {
+			return field.ErrorList{field.Required(specPath.Child("saasEndpoint"), "required unless policies are synced from a directory")}
+		}
+		return nil
+	}
+
+	path := specPath.Child("policySource")
+	var allErrs field.ErrorList
+	switch {
+	case ps.Path == "":
+		allErrs = append(allErrs, field.Required(path.Child("path"), "required for the Directory policy source"))
+	case !strings.HasPrefix(ps.Path, "/"):
+		allErrs = append(allErrs, field.Invalid(path.Child("path"), ps.Path, "must be an absolute path"))
+	}
+	if ps.ReportConfigMap != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(ps.ReportConfigMap) {
+			allErrs = append(allErrs, field.Invalid(path.Child("reportConfigMap"), ps.ReportConfigMap, msg))
+		}
+	}
+	return allErrs
+}
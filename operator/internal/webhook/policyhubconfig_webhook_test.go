@@ -0,0 +1,215 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	policyv1alpha1 "github.com/policy-hub/operator/api/v1alpha1"
+)
+
+func newPolicyHubConfig(spec policyv1alpha1.PolicyHubConfigSpec) *policyv1alpha1.PolicyHubConfig {
+	if spec.SaaSEndpoint == "" && spec.PolicySource == nil {
+		spec.SaaSEndpoint = "https://policyhub.example.com"
+	}
+	return &policyv1alpha1.PolicyHubConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "policy-hub-system"},
+		Spec:       spec,
+	}
+}
+
+func TestPolicyHubConfigWebhook_Default(t *testing.T) {
+	w := &PolicyHubConfigWebhook{Log: logr.Discard()}
+
+	t.Run("defaults unset intervals", func(t *testing.T) {
+		config := newPolicyHubConfig(policyv1alpha1.PolicyHubConfigSpec{
+			FlowCollection: &policyv1alpha1.FlowCollectionSpec{Enabled: true},
+		})
+		if err := w.Default(context.Background(), config); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if config.Spec.SyncInterval.Duration != DefaultSyncInterval {
+			t.Errorf("Expected sync interval %s, got %s", DefaultSyncInterval, config.Spec.SyncInterval.Duration)
+		}
+		if config.Spec.HeartbeatInterval.Duration != DefaultHeartbeatInterval {
+			t.Errorf("Expected heartbeat interval %s, got %s", DefaultHeartbeatInterval, config.Spec.HeartbeatInterval.Duration)
+		}
+		fc := config.Spec.FlowCollection
+		if fc.FlushInterval.Duration != DefaultFlushInterval || fc.BatchSize != DefaultFlowBatchSize || fc.HubbleAddress != DefaultHubbleAddress {
+			t.Errorf("Unexpected flow collection defaults: %+v", fc)
+		}
+	})
+
+	t.Run("keeps set values", func(t *testing.T) {
+		config := newPolicyHubConfig(policyv1alpha1.PolicyHubConfigSpec{
+			SyncInterval:      metav1.Duration{Duration: time.Minute},
+			HeartbeatInterval: metav1.Duration{Duration: 2 * time.Minute},
+		})
+		if err := w.Default(context.Background(), config); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if config.Spec.SyncInterval.Duration != time.Minute || config.Spec.HeartbeatInterval.Duration != 2*time.Minute {
+			t.Errorf("Expected set intervals to be kept, got %+v", config.Spec)
+		}
+		if config.Spec.FlowCollection != nil {
+			t.Error("Expected flow collection to stay unset")
+		}
+	})
+
+	t.Run("defaults the policy source", func(t *testing.T) {
+		config := newPolicyHubConfig(policyv1alpha1.PolicyHubConfigSpec{
+			PolicySource: &policyv1alpha1.PolicySourceSpec{},
+		})
+		if err := w.Default(context.Background(), config); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		ps := config.Spec.PolicySource
+		if ps.Type != policyv1alpha1.PolicySourceSaaS || ps.ReportConfigMap != DefaultReportConfigMap {
+			t.Errorf("Unexpected policy source defaults: %+v", ps)
+		}
+	})
+}
+
+// Ed25519 public key of the seed 32 zero bytes, raw and PEM-encoded
+const (
+	testPublicKey    = "O2onvM62pC1io6jQKm8Nc2UyFXcd4kOmOsBIoYtZ2ik="
+	testPublicKeyPEM = `-----BEGIN PUBLIC KEY-----
+MCowBQYDK2VwAyEAO2onvM62pC1io6jQKm8Nc2UyFXcd4kOmOsBIoYtZ2ik=
+-----END PUBLIC KEY-----`
+)
+
+func TestPolicyHubConfigWebhook_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    policyv1alpha1.PolicyHubConfigSpec
+		wantErr string
+	}{
+		{
+			name: "valid config",
+			spec: policyv1alpha1.PolicyHubConfigSpec{
+				SyncInterval:     metav1.Duration{Duration: 30 * time.Second},
+				TargetNamespaces: []string{"default", "backend"},
+			},
+		},
+		{
+			name: "unset intervals",
+		},
+		{
+			name:    "negative sync interval",
+			spec:    policyv1alpha1.PolicyHubConfigSpec{SyncInterval: metav1.Duration{Duration: -time.Second}},
+			wantErr: "spec.syncInterval: Invalid value: \"-1s\": must not be negative",
+		},
+		{
+			name:    "heartbeat interval too short",
+			spec:    policyv1alpha1.PolicyHubConfigSpec{HeartbeatInterval: metav1.Duration{Duration: time.Second}},
+			wantErr: "spec.heartbeatInterval",
+		},
+		{
+			name:    "sync interval too long",
+			spec:    policyv1alpha1.PolicyHubConfigSpec{SyncInterval: metav1.Duration{Duration: 48 * time.Hour}},
+			wantErr: "must be at most 24h0m0s",
+		},
+		{
+			name: "flush interval too short",
+			spec: policyv1alpha1.PolicyHubConfigSpec{
+				FlowCollection: &policyv1alpha1.FlowCollectionSpec{FlushInterval: metav1.Duration{Duration: time.Millisecond}},
+			},
+			wantErr: "spec.flowCollection.flushInterval",
+		},
+		{
+			name:    "invalid target namespace",
+			spec:    policyv1alpha1.PolicyHubConfigSpec{TargetNamespaces: []string{"-invalid"}},
+			wantErr: "spec.targetNamespaces[0]",
+		},
+		{
+			name: "trusted keys",
+			spec: policyv1alpha1.PolicyHubConfigSpec{PolicySigning: &policyv1alpha1.PolicySigningSpec{
+				TrustedKeys: []policyv1alpha1.TrustedKey{
+					{ID: "raw", PublicKey: testPublicKey},
+					{ID: "pem", PublicKey: testPublicKeyPEM},
+				},
+			}},
+		},
+		{
+			name: "duplicate trusted key",
+			spec: policyv1alpha1.PolicyHubConfigSpec{PolicySigning: &policyv1alpha1.PolicySigningSpec{
+				TrustedKeys: []policyv1alpha1.TrustedKey{
+					{ID: "saas", PublicKey: testPublicKey},
+					{ID: "saas", PublicKey: testPublicKey},
+				},
+			}},
+			wantErr: "spec.policySigning.trustedKeys[1].id: Duplicate value",
+		},
+		{
+			name: "invalid trusted key",
+			spec: policyv1alpha1.PolicyHubConfigSpec{PolicySigning: &policyv1alpha1.PolicySigningSpec{
+				TrustedKeys: []policyv1alpha1.TrustedKey{{ID: "saas", PublicKey: "c2hvcnQ="}},
+			}},
+			wantErr: "spec.policySigning.trustedKeys[0].publicKey",
+		},
+		{
+			name:    "no trusted keys",
+			spec:    policyv1alpha1.PolicyHubConfigSpec{PolicySigning: &policyv1alpha1.PolicySigningSpec{}},
+			wantErr: "spec.policySigning.trustedKeys: Required value",
+		},
+		{
+			name: "directory source without SaaS endpoint",
+			spec: policyv1alpha1.PolicyHubConfigSpec{PolicySource: &policyv1alpha1.PolicySourceSpec{
+				Type: policyv1alpha1.PolicySourceDirectory,
+				Path: "/etc/policyhub/policies",
+			}},
+		},
+		{
+			name:    "SaaS source without SaaS endpoint",
+			spec:    policyv1alpha1.PolicyHubConfigSpec{PolicySource: &policyv1alpha1.PolicySourceSpec{Type: policyv1alpha1.PolicySourceSaaS}},
+			wantErr: "spec.saasEndpoint: Required value",
+		},
+		{
+			name:    "directory source without path",
+			spec:    policyv1alpha1.PolicyHubConfigSpec{PolicySource: &policyv1alpha1.PolicySourceSpec{Type: policyv1alpha1.PolicySourceDirectory}},
+			wantErr: "spec.policySource.path: Required value",
+		},
+		{
+			name: "relative directory path",
+			spec: policyv1alpha1.PolicyHubConfigSpec{PolicySource: &policyv1alpha1.PolicySourceSpec{
+				Type: policyv1alpha1.PolicySourceDirectory,
+				Path: "policies",
+			}},
+			wantErr: "spec.policySource.path: Invalid value",
+		},
+		{
+			name: "invalid report ConfigMap",
+			spec: policyv1alpha1.PolicyHubConfigSpec{PolicySource: &policyv1alpha1.PolicySourceSpec{
+				Type:            policyv1alpha1.PolicySourceDirectory,
+				Path:            "/policies",
+				ReportConfigMap: "Report",
+			}},
+			wantErr: "spec.policySource.reportConfigMap",
+		},
+	}
+
+	w := &PolicyHubConfigWebhook{Log: logr.Discard()}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newPolicyHubConfig(tt.spec)
+			_, createErr := w.ValidateCreate(context.Background(), config)
+			_, updateErr := w.ValidateUpdate(context.Background(), newPolicyHubConfig(policyv1alpha1.PolicyHubConfigSpec{}), config)
+
+			for _, err := range []error{createErr, updateErr} {
+				if tt.wantErr == "" {
+					if err != nil {
+						t.Errorf("Expected no error, got: %v", err)
+					}
+					continue
+				}
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got: %v", tt.wantErr, err)
+				}
+			}
+		})
+	}
+}